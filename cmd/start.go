@@ -36,6 +36,10 @@ var (
 
 	proto3Host     = "localhost"
 	proto3Port     = "5432"
+	proto3Auth     = server.SCRAMSHA256Auth
+	tlsCert        = ""
+	tlsKey         = ""
+	requireTLS     = false
 	sshServer      = false
 	sshPort        = "localhost:8241"
 	authorizedKeys = ""
@@ -69,6 +73,23 @@ func init() {
 		"`port` used to serve PostgreSQL wire protocol v3")
 	cfgVars["port"] = fs.Lookup("port")
 
+	fs.StringVar(&proto3Auth, "auth", proto3Auth,
+		"`method` used to authenticate PostgreSQL wire protocol v3 clients: password, md5, "+
+			"or scram-sha-256")
+	cfgVars["auth"] = fs.Lookup("auth")
+
+	fs.StringVar(&tlsCert, "tls-cert", tlsCert,
+		"`file` containing a TLS certificate for PostgreSQL wire protocol v3")
+	cfgVars["tls-cert"] = fs.Lookup("tls-cert")
+
+	fs.StringVar(&tlsKey, "tls-key", tlsKey,
+		"`file` containing a TLS private key for PostgreSQL wire protocol v3")
+	cfgVars["tls-key"] = fs.Lookup("tls-key")
+
+	fs.BoolVar(&requireTLS, "require-tls", requireTLS,
+		"`flag` to require TLS for PostgreSQL wire protocol v3")
+	cfgVars["require-tls"] = fs.Lookup("require-tls")
+
 	fs.BoolVar(&sshServer, "ssh", sshServer, "`flag` to control serving SSH")
 	cfgVars["ssh"] = fs.Lookup("ssh")
 
@@ -237,17 +258,43 @@ func startRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	switch proto3Auth {
+	case server.PasswordAuth, server.MD5Auth, server.SCRAMSHA256Auth:
+	default:
+		return fmt.Errorf("maho: got %s for auth; want password, md5, or scram-sha-256",
+			proto3Auth)
+	}
+
+	userPasswords := userAccounts()
+
 	p3Cfg := server.Proto3Config{
-		Address: fmt.Sprintf("%s:%s", proto3Host, proto3Port),
+		Address:    fmt.Sprintf("%s:%s", proto3Host, proto3Port),
+		AuthMethod: proto3Auth,
 	}
+	if len(userPasswords) > 0 {
+		p3Cfg.LookupPassword = func(user string) (string, bool) {
+			pw, ok := userPasswords[user]
+			return pw, ok
+		}
+	}
+
+	if tlsCert != "" || tlsKey != "" {
+		p3Cfg.CertBytes, err = ioutil.ReadFile(tlsCert)
+		if err != nil {
+			return fmt.Errorf("maho: tls cert: %s", err)
+		}
+		p3Cfg.KeyBytes, err = ioutil.ReadFile(tlsKey)
+		if err != nil {
+			return fmt.Errorf("maho: tls key: %s", err)
+		}
+	}
+	p3Cfg.RequireTLS = requireTLS
 
 	go func() {
 		fmt.Fprintf(os.Stderr, "maho: %s\n", svr.ListenAndServeProto3(p3Cfg))
 	}()
 
 	if sshServer {
-		userPasswords := userAccounts()
-
 		sshCfg := server.SSHConfig{
 			Address: sshPort,
 		}
@@ -15,6 +15,12 @@ type Engine struct {
 	flgs             flags.Flags
 	systemInfoTables map[sql.Identifier]sql.MakeVirtual
 	metadataTables   map[sql.Identifier]sql.MakeVirtual
+
+	seqMutex sync.Mutex
+	// The most recent value of each sequence used by each session.
+	seqValues map[uint64]map[sql.TableName]int64
+	// The values of each sequence which have been allocated, but not yet used.
+	seqCaches map[sql.TableName]*sequenceCache
 }
 
 func NewEngine(st store, flgs flags.Flags) sql.Engine {
@@ -23,6 +29,8 @@ func NewEngine(st store, flgs flags.Flags) sql.Engine {
 		flgs:             flgs,
 		systemInfoTables: map[sql.Identifier]sql.MakeVirtual{},
 		metadataTables:   map[sql.Identifier]sql.MakeVirtual{},
+		seqValues:        map[uint64]map[sql.TableName]int64{},
+		seqCaches:        map[sql.TableName]*sequenceCache{},
 	}
 
 	e.CreateSystemInfoTable(sql.DATABASES, e.makeDatabasesTable)
@@ -80,7 +88,28 @@ func (e *Engine) DropDatabase(dbname sql.Identifier, ifExists bool,
 	if dbname == sql.SYSTEM {
 		return fmt.Errorf("engine: database %s may not be dropped", dbname)
 	}
-	return e.st.DropDatabase(dbname, ifExists, options)
+	err := e.st.DropDatabase(dbname, ifExists, options)
+	if err != nil {
+		return err
+	}
+
+	e.seqMutex.Lock()
+	defer e.seqMutex.Unlock()
+
+	for tn := range e.seqCaches {
+		if tn.Database == dbname {
+			delete(e.seqCaches, tn)
+		}
+	}
+	return nil
+}
+
+// EndSession forgets the state kept for session sesid.
+func (e *Engine) EndSession(sesid uint64) {
+	e.seqMutex.Lock()
+	defer e.seqMutex.Unlock()
+
+	delete(e.seqValues, sesid)
 }
 
 func (e *Engine) lookupVirtualTable(ctx context.Context, tx sql.Transaction,
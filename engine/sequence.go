@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/leftmike/maho/sql"
+)
+
+type SequenceType struct {
+	seq      sql.Sequence
+	owner    sql.TableName // A table and column which own the sequence, if any.
+	ownerCol sql.Identifier
+}
+
+// sequenceCache holds values of a sequence which have been allocated, but not yet used.
+type sequenceCache struct {
+	seq       sql.Sequence
+	sqt       *SequenceType
+	next      int64
+	remaining int64
+}
+
+func makeSequenceType(seq sql.Sequence, owner sql.TableName,
+	ownerCol sql.Identifier) *SequenceType {
+
+	return &SequenceType{
+		seq:      seq,
+		owner:    owner,
+		ownerCol: ownerCol,
+	}
+}
+
+func (st *SequenceType) Sequence() sql.Sequence {
+	return st.seq
+}
+
+func (st *SequenceType) ownedBy(tn sql.TableName, col sql.Identifier) bool {
+	return st.owner == tn && (col == 0 || st.ownerCol == col)
+}
+
+// NextValue returns the value which follows cur. If called is false, cur has not yet been
+// returned, and it is the next value.
+func (st *SequenceType) NextValue(tn sql.TableName, cur int64, called bool) (int64, error) {
+	if !called {
+		return cur, nil
+	}
+
+	seq := st.seq
+	next := cur + seq.Increment
+	if seq.Increment > 0 {
+		if next > cur && next <= seq.MaxValue {
+			return next, nil
+		} else if seq.Cycle {
+			return seq.MinValue, nil
+		}
+		return 0, fmt.Errorf("engine: sequence %s: reached maximum value %d", tn, seq.MaxValue)
+	}
+
+	if next < cur && next >= seq.MinValue {
+		return next, nil
+	} else if seq.Cycle {
+		return seq.MaxValue, nil
+	}
+	return 0, fmt.Errorf("engine: sequence %s: reached minimum value %d", tn, seq.MinValue)
+}
+
+// NextValues returns the first of up to n values which follow cur, the last of them, and how
+// many there are. There are fewer than n values if the sequence reaches its maximum or minimum
+// value.
+func (st *SequenceType) NextValues(tn sql.TableName, cur int64, called bool,
+	n int64) (int64, int64, int64, error) {
+
+	first, err := st.NextValue(tn, cur, called)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	last := first
+	num := int64(1)
+	for num < n {
+		next, err := st.NextValue(tn, last, true)
+		if err != nil {
+			break
+		}
+		last = next
+		num += 1
+	}
+	return first, last, num, nil
+}
+
+// CheckValue returns an error if val is not within the minimum and maximum values.
+func (st *SequenceType) CheckValue(tn sql.TableName, val int64) error {
+	if val < st.seq.MinValue || val > st.seq.MaxValue {
+		return fmt.Errorf("engine: sequence %s: value %d is out of bounds (%d, %d)", tn, val,
+			st.seq.MinValue, st.seq.MaxValue)
+	}
+	return nil
+}
+
+func (st *SequenceType) Encode() ([]byte, error) {
+	md := SequenceMetadata{
+		Increment: st.seq.Increment,
+		MinValue:  st.seq.MinValue,
+		MaxValue:  st.seq.MaxValue,
+		Start:     st.seq.Start,
+		Cache:     st.seq.Cache,
+		Cycle:     st.seq.Cycle,
+	}
+	if st.ownerCol != 0 {
+		md.Owner = &TableName{
+			Database: st.owner.Database.String(),
+			Schema:   st.owner.Schema.String(),
+			Table:    st.owner.Table.String(),
+		}
+		md.OwnerColumn = st.ownerCol.String()
+	}
+
+	return proto.Marshal(&md)
+}
+
+func DecodeSequenceType(tn sql.TableName, buf []byte) (*SequenceType, error) {
+	var md SequenceMetadata
+	err := proto.Unmarshal(buf, &md)
+	if err != nil {
+		return nil, fmt.Errorf("engine: sequence %s: %s", tn, err)
+	}
+
+	var owner sql.TableName
+	var ownerCol sql.Identifier
+	if md.Owner != nil {
+		owner = sql.TableName{
+			Database: sql.QuotedID(md.Owner.Database),
+			Schema:   sql.QuotedID(md.Owner.Schema),
+			Table:    sql.QuotedID(md.Owner.Table),
+		}
+		ownerCol = sql.QuotedID(md.OwnerColumn)
+	}
+
+	return makeSequenceType(
+		sql.Sequence{
+			Increment: md.Increment,
+			MinValue:  md.MinValue,
+			MaxValue:  md.MaxValue,
+			Start:     md.Start,
+			Cache:     md.Cache,
+			Cycle:     md.Cycle,
+		}, owner, ownerCol), nil
+}
@@ -49,7 +49,30 @@ type store interface {
 	CreateTable(ctx context.Context, tx Transaction, tn sql.TableName, tt *TableType,
 		ifNotExists bool) error
 	DropTable(ctx context.Context, tx Transaction, tn sql.TableName) error
+	TruncateTable(ctx context.Context, tx Transaction, tn sql.TableName) error
+	RebuildTable(ctx context.Context, tx Transaction, tn sql.TableName, tt *TableType,
+		copyRows func(otbl, ntbl Table) error) error
+	RenameTable(ctx context.Context, tx Transaction, tn, ntn sql.TableName) error
 	UpdateType(ctx context.Context, tx Transaction, tn sql.TableName, tt *TableType) error
+	AddColumn(ctx context.Context, tx Transaction, tn sql.TableName, tt *TableType,
+		val sql.Value) error
+
+	LookupView(ctx context.Context, tx Transaction, tn sql.TableName) (*ViewType, error)
+	CreateView(ctx context.Context, tx Transaction, tn sql.TableName, vt *ViewType,
+		replace bool) error
+	DropView(ctx context.Context, tx Transaction, tn sql.TableName) error
+
+	LookupSequence(ctx context.Context, tx Transaction, tn sql.TableName) (*SequenceType,
+		error)
+	CreateSequence(ctx context.Context, tx Transaction, tn sql.TableName, sqt *SequenceType,
+		ifNotExists bool) error
+	UpdateSequence(ctx context.Context, tx Transaction, tn sql.TableName,
+		sqt *SequenceType) error
+	DropSequence(ctx context.Context, tx Transaction, tn sql.TableName) error
+	NextSequenceValues(ctx context.Context, tx Transaction, sesid uint64,
+		tn sql.TableName) (*SequenceType, int64, int64, error)
+	SetSequenceValue(ctx context.Context, tx Transaction, sesid uint64, tn sql.TableName,
+		val int64, called bool) error
 
 	AddIndex(ctx context.Context, tx Transaction, tn sql.TableName, tt *TableType,
 		it sql.IndexType) error
@@ -63,6 +86,10 @@ type store interface {
 		error)
 	ListTables(ctx context.Context, tx Transaction, sn sql.SchemaName) ([]sql.Identifier,
 		error)
+	ListViews(ctx context.Context, tx Transaction, sn sql.SchemaName) ([]sql.Identifier,
+		error)
+	ListSequences(ctx context.Context, tx Transaction, sn sql.SchemaName) ([]sql.Identifier,
+		error)
 
 	Begin(sesid uint64) Transaction
 }
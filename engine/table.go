@@ -73,13 +73,29 @@ func convertValue(ct sql.ColumnType, n sql.Identifier, v sql.Value) (sql.Value,
 		return nil, nil
 	}
 
-	cv, err := sql.ConvertValue(ct.Type, v)
+	cv, err := sql.ConvertColumnValue(ct, v)
 	if err != nil {
 		return nil, fmt.Errorf("column %s: %s", n, err)
 	}
 	return cv, nil
 }
 
+type compileContext struct {
+	cols     []sql.Identifier
+	colTypes []sql.ColumnType
+}
+
+func (cc compileContext) CompileRef(r []sql.Identifier) (int, int, sql.ColumnType, error) {
+	if len(r) == 1 {
+		for idx, col := range cc.cols {
+			if col == r[0] {
+				return idx, 0, cc.colTypes[idx], nil
+			}
+		}
+	}
+	return -1, -1, sql.ColumnType{}, fmt.Errorf("engine: reference %s not found", r)
+}
+
 type rowContext []sql.Value
 
 func (rc rowContext) EvalRef(idx, nest int) sql.Value {
@@ -89,29 +105,40 @@ func (rc rowContext) EvalRef(idx, nest int) sql.Value {
 	return rc[idx]
 }
 
-func (tbl *table) Insert(ctx context.Context, rows [][]sql.Value) error {
+// checkRow converts the values of row to the types of the columns, and checks the NOT NULL and
+// check constraints.
+func (tbl *table) checkRow(ctx context.Context, row []sql.Value) error {
 	cols := tbl.tt.cols
 
-	for _, row := range rows {
-		for rdx, ct := range tbl.tt.colTypes {
-			var err error
-			row[rdx], err = convertValue(ct, cols[rdx], row[rdx])
-			if err != nil {
-				return fmt.Errorf("engine: table %s: %s", tbl.tn, err)
-			}
+	for rdx, ct := range tbl.tt.colTypes {
+		var err error
+		row[rdx], err = convertValue(ct, cols[rdx], row[rdx])
+		if err != nil {
+			return fmt.Errorf("engine: table %s: %s", tbl.tn, err)
 		}
+	}
 
-		for _, chk := range tbl.tt.checks {
-			val, err := chk.check.Eval(ctx, tbl.tx, rowContext(row))
-			if err != nil {
-				return fmt.Errorf("engine: table %s: constraint: %s: %s", tbl.tn, chk.name, err)
-			}
-			if val != nil {
-				if b, ok := val.(sql.BoolValue); ok && b == sql.BoolValue(false) {
-					return fmt.Errorf("engine: table %s: check: %s: failed", tbl.tn, chk.name)
-				}
+	for _, chk := range tbl.tt.checks {
+		val, err := chk.check.Eval(ctx, tbl.tx, rowContext(row))
+		if err != nil {
+			return fmt.Errorf("engine: table %s: constraint: %s: %s", tbl.tn, chk.name, err)
+		}
+		if val != nil {
+			if b, ok := val.(sql.BoolValue); ok && b == sql.BoolValue(false) {
+				return fmt.Errorf("engine: table %s: check: %s: failed", tbl.tn, chk.name)
 			}
 		}
+	}
+
+	return nil
+}
+
+func (tbl *table) Insert(ctx context.Context, rows [][]sql.Value) error {
+	for _, row := range rows {
+		err := tbl.checkRow(ctx, row)
+		if err != nil {
+			return err
+		}
 
 		if tbl.tt.events&sql.InsertEvent != 0 {
 			tbl.insertedRows = append(tbl.insertedRows, row)
@@ -132,11 +159,11 @@ func (tbl *table) updateRow(ctx context.Context, ufn updateRow, updates []sql.Co
 
 	cols := tbl.tt.cols
 	colTypes := tbl.tt.colTypes
-	for _, up := range updates {
+	for udx, up := range updates {
 		ct := colTypes[up.Column]
 
 		var err error
-		up.Value, err = convertValue(ct, cols[up.Column], up.Value)
+		updates[udx].Value, err = convertValue(ct, cols[up.Column], up.Value)
 		if err != nil {
 			return fmt.Errorf("engine: table %s: %s", tbl.tn, err)
 		}
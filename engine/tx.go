@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/leftmike/maho/evaluate"
 	"github.com/leftmike/maho/evaluate/expr"
@@ -14,18 +16,22 @@ import (
 
 type transaction struct {
 	e          *Engine
+	sesid      uint64
 	tx         Transaction
 	tables     map[sql.TableName]*table
 	tableTypes map[sql.TableName]sql.TableType
 	modified   []*table
+	start      time.Time
 }
 
 func (e *Engine) Begin(sesid uint64) sql.Transaction {
 	return &transaction{
 		e:          e,
+		sesid:      sesid,
 		tx:         e.st.Begin(sesid),
 		tables:     map[sql.TableName]*table{},
 		tableTypes: map[sql.TableName]sql.TableType{},
+		start:      time.Now(),
 	}
 }
 
@@ -57,6 +63,10 @@ func (tx *transaction) NextStmt(ctx context.Context) error {
 	return nil
 }
 
+func (tx *transaction) StartTime() time.Time {
+	return tx.start
+}
+
 func (tx *transaction) CreateSchema(ctx context.Context, sn sql.SchemaName) error {
 	if sn.Database == sql.SYSTEM {
 		return fmt.Errorf("engine: database %s may not be modified", sn.Database)
@@ -67,13 +77,58 @@ func (tx *transaction) CreateSchema(ctx context.Context, sn sql.SchemaName) erro
 	return tx.e.st.CreateSchema(ctx, tx.tx, sn)
 }
 
-func (tx *transaction) DropSchema(ctx context.Context, sn sql.SchemaName, ifExists bool) error {
+func (tx *transaction) DropSchema(ctx context.Context, sn sql.SchemaName, ifExists,
+	cascade bool) error {
+
 	if sn.Database == sql.SYSTEM {
 		return fmt.Errorf("engine: database %s may not be modified", sn.Database)
 	}
 	if sn.Schema == sql.METADATA {
 		return fmt.Errorf("engine: schema %s may not be dropped", sn)
 	}
+
+	if cascade {
+		vwnames, err := tx.e.st.ListViews(ctx, tx.tx, sn)
+		if err != nil {
+			return err
+		}
+		for _, vwname := range vwnames {
+			// The view might already have been dropped by an earlier cascade.
+			err = tx.DropView(ctx, sql.TableName{sn.Database, sn.Schema, vwname}, true, true)
+			if err != nil {
+				return err
+			}
+			tx.tx.NextStmt()
+		}
+
+		tblnames, err := tx.e.st.ListTables(ctx, tx.tx, sn)
+		if err != nil {
+			return err
+		}
+		for _, tblname := range tblnames {
+			err = tx.DropTable(ctx, sql.TableName{sn.Database, sn.Schema, tblname}, false,
+				true)
+			if err != nil {
+				return err
+			}
+			tx.tx.NextStmt()
+		}
+
+		seqnames, err := tx.e.st.ListSequences(ctx, tx.tx, sn)
+		if err != nil {
+			return err
+		}
+		for _, seqname := range seqnames {
+			seqtn := sql.TableName{sn.Database, sn.Schema, seqname}
+			err = tx.e.st.DropSequence(ctx, tx.tx, seqtn)
+			if err != nil {
+				return err
+			}
+			tx.e.forgetSequence(seqtn)
+			tx.tx.NextStmt()
+		}
+	}
+
 	return tx.e.st.DropSchema(ctx, tx.tx, sn, ifExists)
 }
 
@@ -268,6 +323,115 @@ func (tx *transaction) dropForeignKey(ctx context.Context, con sql.Identifier,
 	return nil
 }
 
+// detachedForeignKey is a foreign key which was dropped so that one of its tables could be
+// changed; it is added back by attachForeignKeys.
+type detachedForeignKey struct {
+	con    sql.Identifier
+	fktn   sql.TableName
+	fkCols []int
+	rtn    sql.TableName
+	ridx   sql.Identifier
+	onDel  sql.RefAction
+	onUpd  sql.RefAction
+}
+
+// usesColumn returns true if the foreign key uses the column of table tn, either as one of its
+// columns or as part of the referenced index.
+func (dfk detachedForeignKey) usesColumn(tn sql.TableName, tt *TableType, colNum int) bool {
+	if dfk.fktn == tn && hasColumn(colNum, dfk.fkCols) {
+		return true
+	}
+	if dfk.rtn == tn {
+		for _, ck := range tt.lookupIndex(tn, dfk.ridx) {
+			if ck.Column() == colNum {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (tx *transaction) foreignKeys(ctx context.Context, tn sql.TableName,
+	tt *TableType) ([]detachedForeignKey, error) {
+
+	var dfks []detachedForeignKey
+	for _, fk := range tt.foreignKeys {
+		dfks = append(dfks,
+			detachedForeignKey{
+				con:    fk.name,
+				fktn:   tn,
+				fkCols: fk.keyCols,
+				rtn:    fk.refTable,
+				ridx:   fk.refIndex,
+				onDel:  fk.onDelete,
+				onUpd:  fk.onUpdate,
+			})
+	}
+
+	for _, fr := range tt.foreignRefs {
+		if fr.tn == tn {
+			// Self references are also foreign keys of the table.
+			continue
+		}
+
+		fktt, err := tx.e.st.LookupTableType(ctx, tx.tx, fr.tn)
+		if err != nil {
+			return nil, err
+		}
+		fk, ok := fktt.lookupForeignKey(fr.name)
+		if !ok {
+			panic(fmt.Sprintf("table %s: missing foreign key %s for reference from table %s",
+				tn, fr.name, fr.tn))
+		}
+		dfks = append(dfks,
+			detachedForeignKey{
+				con:    fk.name,
+				fktn:   fr.tn,
+				fkCols: fk.keyCols,
+				rtn:    tn,
+				ridx:   fk.refIndex,
+				onDel:  fk.onDelete,
+				onUpd:  fk.onUpdate,
+			})
+	}
+
+	return dfks, nil
+}
+
+func (tx *transaction) detachForeignKeys(ctx context.Context,
+	dfks []detachedForeignKey) error {
+
+	for _, dfk := range dfks {
+		err := tx.dropForeignRef(ctx, dfk.con, dfk.fktn, dfk.rtn)
+		if err != nil {
+			return err
+		}
+
+		err = tx.dropForeignKey(ctx, dfk.con, dfk.fktn, dfk.rtn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tx *transaction) attachForeignKeys(ctx context.Context,
+	dfks []detachedForeignKey) error {
+
+	for _, dfk := range dfks {
+		err := tx.AddForeignKey(ctx, dfk.con, dfk.fktn, dfk.fkCols, dfk.rtn, dfk.ridx,
+			dfk.onDel, dfk.onUpd, false)
+		if err != nil {
+			return err
+		}
+
+		tx.tx.NextStmt()
+	}
+
+	return nil
+}
+
 func (tx *transaction) DropTable(ctx context.Context, tn sql.TableName, ifExists,
 	cascade bool) error {
 
@@ -278,6 +442,19 @@ func (tx *transaction) DropTable(ctx context.Context, tn sql.TableName, ifExists
 		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
 	}
 
+	vt, err := tx.e.st.LookupView(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	} else if vt != nil {
+		return fmt.Errorf("engine: %s is a view", tn)
+	}
+	sqt, err := tx.e.st.LookupSequence(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	} else if sqt != nil {
+		return fmt.Errorf("engine: %s is a sequence", tn)
+	}
+
 	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
 	if err != nil {
 		if ifExists {
@@ -286,6 +463,14 @@ func (tx *transaction) DropTable(ctx context.Context, tn sql.TableName, ifExists
 		return err
 	}
 
+	if len(tt.foreignRefs) > 0 && !cascade {
+		return fmt.Errorf("engine: table %s: existing foreign references", tn)
+	}
+	err = tx.dropDependentViews(ctx, tn, cascade)
+	if err != nil {
+		return err
+	}
+
 	for _, fk := range tt.foreignKeys {
 		err = tx.dropForeignRef(ctx, fk.name, tn, fk.refTable)
 		if err != nil {
@@ -294,9 +479,6 @@ func (tx *transaction) DropTable(ctx context.Context, tn sql.TableName, ifExists
 	}
 
 	if len(tt.foreignRefs) > 0 {
-		if !cascade {
-			return fmt.Errorf("engine: table %s: existing foreign references", tn)
-		}
 
 		for _, fr := range tt.foreignRefs {
 			err = tx.dropForeignKey(ctx, fr.name, fr.tn, tn)
@@ -312,9 +494,133 @@ func (tx *transaction) DropTable(ctx context.Context, tn sql.TableName, ifExists
 	}
 	delete(tx.tables, tn)
 	delete(tx.tableTypes, tn)
+
+	return tx.dropOwnedSequences(ctx, tn, 0)
+}
+
+func (tx *transaction) TruncateTables(ctx context.Context, tns []sql.TableName,
+	cascade bool) error {
+
+	var truncate []sql.TableName
+	tableTypes := map[sql.TableName]*TableType{}
+	addTable := func(tn sql.TableName) error {
+		if _, ok := tableTypes[tn]; ok {
+			return nil
+		}
+
+		if tn.Database == sql.SYSTEM {
+			return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+		}
+		if tn.Schema == sql.METADATA {
+			return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+		}
+
+		tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
+		if err != nil {
+			return err
+		}
+		truncate = append(truncate, tn)
+		tableTypes[tn] = tt
+		return nil
+	}
+
+	for _, tn := range tns {
+		err := addTable(tn)
+		if err != nil {
+			return err
+		}
+	}
+
+	for tdx := 0; tdx < len(truncate); tdx += 1 {
+		tn := truncate[tdx]
+		for _, fr := range tableTypes[tn].foreignRefs {
+			if _, ok := tableTypes[fr.tn]; ok {
+				continue
+			}
+			if !cascade {
+				return fmt.Errorf("engine: table %s: existing foreign references from table %s",
+					tn, fr.tn)
+			}
+
+			err := addTable(fr.tn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, tn := range truncate {
+		err := tx.e.st.TruncateTable(ctx, tx.tx, tn)
+		if err != nil {
+			return err
+		}
+		delete(tx.tables, tn)
+		delete(tx.tableTypes, tn)
+
+		tx.tx.NextStmt()
+	}
 	return nil
 }
 
+func (tx *transaction) RenameTable(ctx context.Context, tn sql.TableName,
+	nam sql.Identifier) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	vtns, err := tx.dependentViews(ctx, tn)
+	if err != nil {
+		return err
+	}
+	if len(vtns) > 0 {
+		return fmt.Errorf("engine: table %s: existing dependent view %s", tn, vtns[0])
+	}
+
+	dfks, err := tx.foreignKeys(ctx, tn, tt)
+	if err != nil {
+		return err
+	}
+	err = tx.detachForeignKeys(ctx, dfks)
+	if err != nil {
+		return err
+	}
+
+	ntn := sql.TableName{tn.Database, tn.Schema, nam}
+	err = tx.e.st.RenameTable(ctx, tx.tx, tn, ntn)
+	if err != nil {
+		return err
+	}
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+	delete(tx.tables, ntn)
+	delete(tx.tableTypes, ntn)
+
+	tx.tx.NextStmt()
+	err = tx.changeSequenceOwner(ctx, tn, 0, ntn, 0)
+	if err != nil {
+		return err
+	}
+
+	for ddx := range dfks {
+		if dfks[ddx].fktn == tn {
+			dfks[ddx].fktn = ntn
+		}
+		if dfks[ddx].rtn == tn {
+			dfks[ddx].rtn = ntn
+		}
+	}
+	return tx.attachForeignKeys(ctx, dfks)
+}
+
 func (tx *transaction) AddForeignKey(ctx context.Context, con sql.Identifier, fktn sql.TableName,
 	fkCols []int, rtn sql.TableName, ridx sql.Identifier, onDel, onUpd sql.RefAction,
 	check bool) error {
@@ -476,8 +782,8 @@ func (tx *transaction) DropConstraint(ctx context.Context, tn sql.TableName, con
 	return nil
 }
 
-func (tx *transaction) CreateIndex(ctx context.Context, idxname sql.Identifier, tn sql.TableName,
-	unique bool, key []sql.ColumnKey, ifNotExists bool) error {
+func (tx *transaction) AddColumn(ctx context.Context, tn sql.TableName, col sql.Identifier,
+	ct sql.ColumnType, cd sql.ColumnDefault, cons []sql.Constraint, ifNotExists bool) error {
 
 	if tn.Database == sql.SYSTEM {
 		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
@@ -485,47 +791,131 @@ func (tx *transaction) CreateIndex(ctx context.Context, idxname sql.Identifier,
 	if tn.Schema == sql.METADATA {
 		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
 	}
-	if idxname == sql.PRIMARY_QUOTED {
-		return errors.New("engine: primary not allowed as index name")
-	}
 
 	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
 	if err != nil {
 		return err
 	}
 
-	tt, it, iidx, found := tt.AddIndex(idxname, unique, key)
-	if found {
+	if _, ok := tt.columnNumber(col); ok {
 		if ifNotExists {
 			return nil
 		}
-		return fmt.Errorf("engine: table %s: index %s already exists", tn, idxname)
+		return fmt.Errorf("engine: table %s: column %s already exists", tn, col)
 	}
-	err = tx.e.st.AddIndex(ctx, tx.tx, tn, tt, it)
+
+	// Existing rows don't need to be rewritten if the default is constant: the value will be
+	// filled in by the store.
+	var val sql.Value
+	constant := cd.Default == nil || expr.Constant(cd.Default)
+	if constant {
+		if cd.Default != nil {
+			val, err = cd.Default.Eval(ctx, tx, nil)
+			if err != nil {
+				return err
+			}
+			if val != nil {
+				val, err = sql.ConvertColumnValue(ct, val)
+				if err != nil {
+					return fmt.Errorf("engine: table %s: column %s: %s", tn, col, err)
+				}
+			}
+		}
+
+		if val == nil && ct.NotNull {
+			empty, err := tx.emptyTable(ctx, tn)
+			if err != nil {
+				return err
+			} else if !empty {
+				return fmt.Errorf("engine: table %s: column %s contains null values", tn, col)
+			}
+		}
+	}
+
+	colNum := len(tt.cols)
+	tt = tt.AddColumn(col, ct, cd)
+	for _, con := range cons {
+		if tt.duplicateConstraint(con.Name) {
+			return fmt.Errorf("engine: table: %s: duplicate constraint name: %s", tn, con.Name)
+		}
+		tt.constraints = append(tt.constraints,
+			constraint{
+				name:   con.Name,
+				typ:    con.Type,
+				colNum: colNum,
+			})
+	}
+
+	err = tx.e.st.AddColumn(ctx, tx.tx, tn, tt, val)
 	if err != nil {
 		return err
 	}
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+
+	if constant {
+		return nil
+	}
 
 	tx.tx.NextStmt()
-	err = tx.e.st.FillIndex(ctx, tx.tx, tn, tt, iidx)
+	tbl, err := tx.LookupTable(ctx, tn, tt.Version())
 	if err != nil {
 		return err
 	}
-
-	tx.tx.NextStmt()
-	tt = tt.ShowIndex(idxname)
-	err = tx.e.st.UpdateType(ctx, tx.tx, tn, tt)
+	rows, err := tbl.Rows(ctx, nil, nil)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
+
+	dest := make([]sql.Value, rows.NumColumns())
+	for {
+		err = rows.Next(ctx, dest)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		val, err := cd.Default.Eval(ctx, tx, nil)
+		if err != nil {
+			return err
+		}
+		if val != nil {
+			val, err = sql.ConvertColumnValue(ct, val)
+			if err != nil {
+				return fmt.Errorf("engine: table %s: column %s: %s", tn, col, err)
+			}
+		}
+		err = rows.Update(ctx, []sql.ColumnUpdate{{Column: colNum, Value: val}})
+		if err != nil {
+			return err
+		}
+	}
 
-	delete(tx.tables, tn)
-	delete(tx.tableTypes, tn)
 	return nil
 }
 
-func (tx *transaction) DropIndex(ctx context.Context, idxname sql.Identifier, tn sql.TableName,
-	ifExists bool) error {
+func (tx *transaction) emptyTable(ctx context.Context, tn sql.TableName) (bool, error) {
+	tbl, _, err := tx.e.st.LookupTable(ctx, tx.tx, tn)
+	if err != nil {
+		return false, err
+	}
+	rows, err := tbl.Rows(ctx, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	_, err = rows.Next(ctx)
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
+
+func (tx *transaction) DropColumn(ctx context.Context, tn sql.TableName, col sql.Identifier,
+	ifExists, cascade bool) error {
 
 	if tn.Database == sql.SYSTEM {
 		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
@@ -539,19 +929,840 @@ func (tx *transaction) DropIndex(ctx context.Context, idxname sql.Identifier, tn
 		return err
 	}
 
-	tt, iidx := tt.RemoveIndex(idxname)
-	if tt == nil {
+	colNum, ok := tt.columnNumber(col)
+	if !ok {
 		if ifExists {
 			return nil
 		}
-		return fmt.Errorf("engine: table %s: index %s not found", tn, idxname)
+		return fmt.Errorf("engine: table %s: column not found: %s", tn, col)
+	}
+	if tt.primaryColumn(colNum) {
+		return fmt.Errorf("engine: table %s: primary key column %s may not be dropped", tn, col)
 	}
 
-	err = tx.e.st.RemoveIndex(ctx, tx.tx, tn, tt, iidx)
+	dfks, err := tx.foreignKeys(ctx, tn, tt)
+	if err != nil {
+		return err
+	}
+
+	// Foreign keys from the table which use the column, and foreign keys to an index of the
+	// table which uses the column, are dropped along with the column.
+	var keep []detachedForeignKey
+	for _, dfk := range dfks {
+		if !dfk.usesColumn(tn, tt, colNum) {
+			keep = append(keep, dfk)
+		} else if dfk.fktn != tn {
+			return fmt.Errorf("engine: table %s: column %s is used by foreign key %s on table %s",
+				tn, col, dfk.con, dfk.fktn)
+		}
+	}
+
+	// The dependencies of views are on tables rather than columns, so any view which uses the
+	// table might be using the column.
+	err = tx.dropDependentViews(ctx, tn, cascade)
+	if err != nil {
+		return err
+	}
+
+	err = tx.detachForeignKeys(ctx, dfks)
+	if err != nil {
+		return err
+	}
+
+	tt, err = tx.e.st.LookupTableType(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	colMap := dropColumnMap(len(tt.cols), colNum)
+	err = tt.dropColumn(tn, colNum)
+	if err != nil {
+		return err
+	}
+	err = tx.e.st.RebuildTable(ctx, tx.tx, tn, tt,
+		func(otbl, ntbl Table) error {
+			return copyRows(ctx, otbl, ntbl,
+				func(row []sql.Value) ([]sql.Value, error) {
+					return append(row[:colNum:colNum], row[colNum+1:]...), nil
+				})
+		})
 	if err != nil {
 		return err
 	}
 	delete(tx.tables, tn)
 	delete(tx.tableTypes, tn)
+
+	tx.tx.NextStmt()
+	err = tx.dropOwnedSequences(ctx, tn, col)
+	if err != nil {
+		return err
+	}
+
+	for kdx := range keep {
+		if keep[kdx].fktn == tn {
+			keep[kdx].fkCols, _ = mapColumns(keep[kdx].fkCols, colMap)
+		}
+	}
+	return tx.attachForeignKeys(ctx, keep)
+}
+
+func (tx *transaction) SetColumnDefault(ctx context.Context, tn sql.TableName,
+	col sql.Identifier, cd sql.ColumnDefault) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	colNum, ok := tt.columnNumber(col)
+	if !ok {
+		return fmt.Errorf("engine: table %s: column not found: %s", tn, col)
+	}
+	tt.colDefaults[colNum] = cd
+	tt.ver += 1
+
+	err = tx.e.st.UpdateType(ctx, tx.tx, tn, tt)
+	if err != nil {
+		return err
+	}
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+
+	return nil
+}
+
+func (tx *transaction) SetNotNull(ctx context.Context, tn sql.TableName,
+	col sql.Identifier) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	tbl, tt, err := tx.e.st.LookupTable(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	colNum, ok := tt.columnNumber(col)
+	if !ok {
+		return fmt.Errorf("engine: table %s: column not found: %s", tn, col)
+	}
+	if tt.colTypes[colNum].NotNull {
+		return nil
+	}
+
+	rows, err := tbl.Rows(ctx, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for {
+		row, err := rows.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if row[colNum] == nil {
+			return fmt.Errorf("engine: table %s: column %s contains null values", tn, col)
+		}
+	}
+
+	tt.colTypes[colNum].NotNull = true
+	tt.ver += 1
+
+	err = tx.e.st.UpdateType(ctx, tx.tx, tn, tt)
+	if err != nil {
+		return err
+	}
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+
+	return nil
+}
+
+// AlterColumnType rewrites every row of the table, converting the column to the new type, and
+// then rebuilds the table and all of its indexes. If using is not nil, it is evaluated against
+// the existing row to get the new value of the column.
+func (tx *transaction) AlterColumnType(ctx context.Context, tn sql.TableName,
+	col sql.Identifier, ct sql.ColumnType, using sql.CExpr, cascade bool) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	colNum, ok := tt.columnNumber(col)
+	if !ok {
+		return fmt.Errorf("engine: table %s: column not found: %s", tn, col)
+	}
+
+	dfks, err := tx.foreignKeys(ctx, tn, tt)
+	if err != nil {
+		return err
+	}
+	for _, dfk := range dfks {
+		if dfk.usesColumn(tn, tt, colNum) {
+			return fmt.Errorf("engine: table %s: column %s is used by foreign key %s on table %s",
+				tn, col, dfk.con, dfk.fktn)
+		}
+	}
+
+	// The columns of views have the types of the columns of the tables they were created from.
+	err = tx.dropDependentViews(ctx, tn, cascade)
+	if err != nil {
+		return err
+	}
+
+	ct.NotNull = tt.colTypes[colNum].NotNull
+	tt.colTypes[colNum] = ct
+	tt.ver += 1
+
+	// The check constraints were compiled using the old type of the column.
+	cctx := compileContext{
+		cols:     tt.cols,
+		colTypes: tt.colTypes,
+	}
+	for cdx, chk := range tt.checks {
+		p := parser.NewParser(strings.NewReader(chk.checkExpr), chk.checkExpr)
+		e, err := p.ParseExpr()
+		if err != nil {
+			return fmt.Errorf("engine: table %s: check constraint %s: %s", tn, chk.name, err)
+		}
+		ce, ct, err := expr.Compile(ctx, planContext{tx.e}, tx, cctx, e)
+		if err != nil {
+			return fmt.Errorf("engine: table %s: check constraint %s: %s", tn, chk.name, err)
+		} else if ct.Type != sql.BooleanType {
+			return fmt.Errorf("engine: table %s: check constraint %s: must be boolean expression",
+				tn, chk.name)
+		}
+		tt.checks[cdx].check = ce
+	}
+
+	err = tx.e.st.RebuildTable(ctx, tx.tx, tn, tt,
+		func(otbl, ntbl Table) error {
+			tbl := makeTable(tx, tn, ntbl, tt)
+			return copyRows(ctx, otbl, ntbl,
+				func(row []sql.Value) ([]sql.Value, error) {
+					if using != nil {
+						var err error
+						row[colNum], err = using.Eval(ctx, tx, rowContext(row))
+						if err != nil {
+							return nil, err
+						}
+					}
+					return row, tbl.checkRow(ctx, row)
+				})
+		})
+	if err != nil {
+		return err
+	}
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+
+	tx.tx.NextStmt()
+	return nil
+}
+
+// copyRows inserts each row of otbl into ntbl, after converting it using convert.
+func copyRows(ctx context.Context, otbl, ntbl Table,
+	convert func(row []sql.Value) ([]sql.Value, error)) error {
+
+	rows, err := otbl.Rows(ctx, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for {
+		row, err := rows.Next(ctx)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		row, err = convert(row)
+		if err != nil {
+			return err
+		}
+		err = ntbl.Insert(ctx, [][]sql.Value{row})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (tx *transaction) RenameColumn(ctx context.Context, tn sql.TableName, col,
+	ncol sql.Identifier, cascade bool) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	colNum, ok := tt.columnNumber(col)
+	if !ok {
+		return fmt.Errorf("engine: table %s: column not found: %s", tn, col)
+	}
+	err = tt.renameColumn(tn, colNum, ncol)
+	if err != nil {
+		return err
+	}
+
+	// The queries of views refer to columns by name.
+	err = tx.dropDependentViews(ctx, tn, cascade)
+	if err != nil {
+		return err
+	}
+
+	// The foreign key triggers use the column names, so the foreign keys are added back after
+	// the column has been renamed.
+	dfks, err := tx.foreignKeys(ctx, tn, tt)
+	if err != nil {
+		return err
+	}
+	if len(dfks) > 0 {
+		err = tx.detachForeignKeys(ctx, dfks)
+		if err != nil {
+			return err
+		}
+
+		tt, err = tx.e.st.LookupTableType(ctx, tx.tx, tn)
+		if err != nil {
+			return err
+		}
+		err = tt.renameColumn(tn, colNum, ncol)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.e.st.UpdateType(ctx, tx.tx, tn, tt)
+	if err != nil {
+		return err
+	}
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+
+	tx.tx.NextStmt()
+	err = tx.changeSequenceOwner(ctx, tn, col, tn, ncol)
+	if err != nil {
+		return err
+	}
+	return tx.attachForeignKeys(ctx, dfks)
+}
+
+func (tx *transaction) CreateIndex(ctx context.Context, idxname sql.Identifier, tn sql.TableName,
+	unique bool, key []sql.ColumnKey, ifNotExists bool) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+	if idxname == sql.PRIMARY_QUOTED {
+		return errors.New("engine: primary not allowed as index name")
+	}
+
+	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	tt, it, iidx, found := tt.AddIndex(idxname, unique, key)
+	if found {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("engine: table %s: index %s already exists", tn, idxname)
+	}
+	err = tx.e.st.AddIndex(ctx, tx.tx, tn, tt, it)
+	if err != nil {
+		return err
+	}
+
+	tx.tx.NextStmt()
+	err = tx.e.st.FillIndex(ctx, tx.tx, tn, tt, iidx)
+	if err != nil {
+		return err
+	}
+
+	tx.tx.NextStmt()
+	tt = tt.ShowIndex(idxname)
+	err = tx.e.st.UpdateType(ctx, tx.tx, tn, tt)
+	if err != nil {
+		return err
+	}
+
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+	return nil
+}
+
+func (tx *transaction) DropIndex(ctx context.Context, idxname sql.Identifier, tn sql.TableName,
+	ifExists bool) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	tt, err := tx.e.st.LookupTableType(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+
+	tt, iidx := tt.RemoveIndex(idxname)
+	if tt == nil {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("engine: table %s: index %s not found", tn, idxname)
+	}
+
+	err = tx.e.st.RemoveIndex(ctx, tx.tx, tn, tt, iidx)
+	if err != nil {
+		return err
+	}
+	delete(tx.tables, tn)
+	delete(tx.tableTypes, tn)
+	return nil
+}
+
+func (tx *transaction) LookupView(ctx context.Context, tn sql.TableName) (sql.ViewType, error) {
+	if tn.Schema == sql.METADATA || (tn.Database == sql.SYSTEM && tn.Schema == sql.INFO) {
+		return nil, nil
+	}
+	if _, ok := tx.tableTypes[tn]; ok {
+		return nil, nil
+	}
+
+	vt, err := tx.e.st.LookupView(ctx, tx.tx, tn)
+	if err != nil || vt == nil {
+		return nil, err
+	}
+	return vt, nil
+}
+
+func (tx *transaction) CreateView(ctx context.Context, tn sql.TableName, cols []sql.Identifier,
+	query string, sn sql.SchemaName, deps []sql.TableName, replace bool) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	for _, dep := range deps {
+		if dep == tn {
+			return fmt.Errorf("engine: view %s: recursive definition", tn)
+		}
+	}
+
+	if replace {
+		vt, err := tx.e.st.LookupView(ctx, tx.tx, tn)
+		if err != nil {
+			return err
+		}
+		if vt != nil {
+			if len(cols) < len(vt.cols) {
+				return fmt.Errorf("engine: view %s: columns may not be dropped", tn)
+			}
+			for cdx, col := range vt.cols {
+				if cols[cdx] != col {
+					return fmt.Errorf("engine: view %s: column %s may not be changed to %s",
+						tn, col, cols[cdx])
+				}
+			}
+		}
+	}
+
+	return tx.e.st.CreateView(ctx, tx.tx, tn, makeViewType(cols, query, sn, deps), replace)
+}
+
+func (tx *transaction) DropView(ctx context.Context, tn sql.TableName, ifExists,
+	cascade bool) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	vt, err := tx.e.st.LookupView(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+	if vt == nil {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("engine: view %s not found", tn)
+	}
+
+	err = tx.dropDependentViews(ctx, tn, cascade)
+	if err != nil {
+		return err
+	}
+	return tx.e.st.DropView(ctx, tx.tx, tn)
+}
+
+// dependentViews returns the views, in any database, whose queries reference tn.
+func (tx *transaction) dependentViews(ctx context.Context, tn sql.TableName) ([]sql.TableName,
+	error) {
+
+	var vtns []sql.TableName
+	dbnames, err := tx.e.st.ListDatabases(ctx, tx.tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, dbname := range dbnames {
+		scnames, err := tx.e.st.ListSchemas(ctx, tx.tx, dbname)
+		if err != nil {
+			return nil, err
+		}
+		for _, scname := range scnames {
+			vwnames, err := tx.e.st.ListViews(ctx, tx.tx, sql.SchemaName{dbname, scname})
+			if err != nil {
+				return nil, err
+			}
+			for _, vwname := range vwnames {
+				vtn := sql.TableName{dbname, scname, vwname}
+				vt, err := tx.e.st.LookupView(ctx, tx.tx, vtn)
+				if err != nil {
+					return nil, err
+				}
+				if vt != nil && vt.dependsOn(tn) {
+					vtns = append(vtns, vtn)
+				}
+			}
+		}
+	}
+	return vtns, nil
+}
+
+func (tx *transaction) dropDependentViews(ctx context.Context, tn sql.TableName,
+	cascade bool) error {
+
+	vtns, err := tx.dependentViews(ctx, tn)
+	if err != nil {
+		return err
+	}
+	if len(vtns) > 0 && !cascade {
+		return fmt.Errorf("engine: %s: existing dependent view %s", tn, vtns[0])
+	}
+
+	for _, vtn := range vtns {
+		// The view might already have been dropped by an earlier cascade.
+		err = tx.DropView(ctx, vtn, true, true)
+		if err != nil {
+			return err
+		}
+		tx.tx.NextStmt()
+	}
+	return nil
+}
+
+func (tx *transaction) LookupSequence(ctx context.Context, tn sql.TableName) (sql.Sequence,
+	error) {
+
+	sqt, err := tx.e.st.LookupSequence(ctx, tx.tx, tn)
+	if err != nil {
+		return sql.Sequence{}, err
+	} else if sqt == nil {
+		return sql.Sequence{}, fmt.Errorf("engine: sequence %s not found", tn)
+	}
+	return sqt.seq, nil
+}
+
+func checkSequence(tn sql.TableName, seq sql.Sequence) error {
+	if seq.Increment == 0 {
+		return fmt.Errorf("engine: sequence %s: increment must not be zero", tn)
+	}
+	if seq.MinValue >= seq.MaxValue {
+		return fmt.Errorf("engine: sequence %s: minimum value %d must be less than maximum %d", tn,
+			seq.MinValue, seq.MaxValue)
+	}
+	if seq.Start < seq.MinValue || seq.Start > seq.MaxValue {
+		return fmt.Errorf("engine: sequence %s: start value %d is out of bounds (%d, %d)", tn,
+			seq.Start, seq.MinValue, seq.MaxValue)
+	}
+	if seq.Cache < 1 {
+		return fmt.Errorf("engine: sequence %s: cache must be at least 1", tn)
+	}
+	return nil
+}
+
+func (tx *transaction) CreateSequence(ctx context.Context, tn sql.TableName, seq sql.Sequence,
+	owner sql.TableName, col sql.Identifier, ifNotExists bool) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	err := checkSequence(tn, seq)
+	if err != nil {
+		return err
+	}
+	err = tx.e.st.CreateSequence(ctx, tx.tx, tn, makeSequenceType(seq, owner, col),
+		ifNotExists)
+	if err != nil {
+		return err
+	}
+	tx.e.forgetSequence(tn)
+	return nil
+}
+
+func (tx *transaction) AlterSequence(ctx context.Context, tn sql.TableName,
+	seq sql.Sequence) error {
+
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	sqt, err := tx.e.st.LookupSequence(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	} else if sqt == nil {
+		return fmt.Errorf("engine: sequence %s not found", tn)
+	}
+
+	err = checkSequence(tn, seq)
+	if err != nil {
+		return err
+	}
+	return tx.e.st.UpdateSequence(ctx, tx.tx, tn, makeSequenceType(seq, sqt.owner, sqt.ownerCol))
+}
+
+func (tx *transaction) DropSequence(ctx context.Context, tn sql.TableName, ifExists bool) error {
+	if tn.Database == sql.SYSTEM {
+		return fmt.Errorf("engine: database %s may not be modified", tn.Database)
+	}
+	if tn.Schema == sql.METADATA {
+		return fmt.Errorf("engine: schema %s may not be modified", tn.Schema)
+	}
+
+	sqt, err := tx.e.st.LookupSequence(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	} else if sqt == nil {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("engine: sequence %s not found", tn)
+	}
+	if sqt.ownerCol != 0 {
+		return fmt.Errorf("engine: sequence %s: used by table %s column %s", tn, sqt.owner,
+			sqt.ownerCol)
+	}
+	err = tx.e.st.DropSequence(ctx, tx.tx, tn)
+	if err != nil {
+		return err
+	}
+	tx.e.forgetSequence(tn)
+	return nil
+}
+
+// ownedSequences returns the sequences owned by column col of table tn, or by any of the
+// columns of tn if col is 0. Owned sequences are always in the same schema as the table.
+func (tx *transaction) ownedSequences(ctx context.Context, tn sql.TableName,
+	col sql.Identifier) ([]sql.TableName, []*SequenceType, error) {
+
+	seqnames, err := tx.e.st.ListSequences(ctx, tx.tx, tn.SchemaName())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var seqtns []sql.TableName
+	var sqts []*SequenceType
+	for _, seqname := range seqnames {
+		seqtn := sql.TableName{tn.Database, tn.Schema, seqname}
+		sqt, err := tx.e.st.LookupSequence(ctx, tx.tx, seqtn)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sqt != nil && sqt.ownedBy(tn, col) {
+			seqtns = append(seqtns, seqtn)
+			sqts = append(sqts, sqt)
+		}
+	}
+	return seqtns, sqts, nil
+}
+
+func (tx *transaction) dropOwnedSequences(ctx context.Context, tn sql.TableName,
+	col sql.Identifier) error {
+
+	seqtns, _, err := tx.ownedSequences(ctx, tn, col)
+	if err != nil {
+		return err
+	}
+	for _, seqtn := range seqtns {
+		tx.tx.NextStmt()
+		err = tx.e.st.DropSequence(ctx, tx.tx, seqtn)
+		if err != nil {
+			return err
+		}
+		tx.e.forgetSequence(seqtn)
+		tx.tx.NextStmt()
+	}
+	return nil
+}
+
+// changeSequenceOwner changes the owner of the sequences owned by column col of table tn to
+// table ntn and, unless ncol is 0, column ncol.
+func (tx *transaction) changeSequenceOwner(ctx context.Context, tn sql.TableName,
+	col sql.Identifier, ntn sql.TableName, ncol sql.Identifier) error {
+
+	seqtns, sqts, err := tx.ownedSequences(ctx, tn, col)
+	if err != nil {
+		return err
+	}
+	for sdx, sqt := range sqts {
+		sqt.owner = ntn
+		if ncol != 0 {
+			sqt.ownerCol = ncol
+		}
+		err = tx.e.st.UpdateSequence(ctx, tx.tx, seqtns[sdx], sqt)
+		if err != nil {
+			return err
+		}
+		tx.tx.NextStmt()
+	}
+	return nil
+}
+
+func (e *Engine) setSequenceValue(sesid uint64, tn sql.TableName, val int64) {
+	vals, ok := e.seqValues[sesid]
+	if !ok {
+		vals = map[sql.TableName]int64{}
+		e.seqValues[sesid] = vals
+	}
+	vals[tn] = val
+}
+
+// forgetSequence discards the unused values allocated for sequence tn.
+func (e *Engine) forgetSequence(tn sql.TableName) {
+	e.seqMutex.Lock()
+	defer e.seqMutex.Unlock()
+
+	delete(e.seqCaches, tn)
+}
+
+// NextSequenceValue returns the next value of sequence tn. Sequences are not transactional:
+// a value is never returned twice, even if the transaction which used it rolls back. Values
+// are allocated by the store in blocks of the cache size of the sequence.
+func (tx *transaction) NextSequenceValue(ctx context.Context, tn sql.TableName) (int64, error) {
+	sqt, err := tx.e.st.LookupSequence(ctx, tx.tx, tn)
+	if err != nil {
+		return 0, err
+	} else if sqt == nil {
+		return 0, fmt.Errorf("engine: sequence %s not found", tn)
+	}
+
+	tx.e.seqMutex.Lock()
+	defer tx.e.seqMutex.Unlock()
+
+	sc, ok := tx.e.seqCaches[tn]
+	if !ok || sc.remaining == 0 || sc.seq != sqt.seq {
+		sqt, first, num, err := tx.e.st.NextSequenceValues(ctx, tx.tx, tx.sesid, tn)
+		if err != nil {
+			return 0, err
+		}
+		sc = &sequenceCache{
+			seq:       sqt.seq,
+			sqt:       sqt,
+			next:      first,
+			remaining: num,
+		}
+		tx.e.seqCaches[tn] = sc
+	}
+
+	val := sc.next
+	sc.remaining -= 1
+	if sc.remaining > 0 {
+		sc.next, err = sc.sqt.NextValue(tn, val, true)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		delete(tx.e.seqCaches, tn)
+	}
+
+	tx.e.setSequenceValue(tx.sesid, tn, val)
+	return val, nil
+}
+
+// CurrentSequenceValue returns the value most recently returned by NextSequenceValue for tn in
+// this session.
+func (tx *transaction) CurrentSequenceValue(ctx context.Context, tn sql.TableName) (int64,
+	error) {
+
+	_, err := tx.LookupSequence(ctx, tn)
+	if err != nil {
+		return 0, err
+	}
+
+	tx.e.seqMutex.Lock()
+	defer tx.e.seqMutex.Unlock()
+
+	val, ok := tx.e.seqValues[tx.sesid][tn]
+	if !ok {
+		return 0, fmt.Errorf("engine: sequence %s: currval is not yet defined in this session", tn)
+	}
+	return val, nil
+}
+
+func (tx *transaction) SetSequenceValue(ctx context.Context, tn sql.TableName, val int64,
+	called bool) error {
+
+	err := tx.e.st.SetSequenceValue(ctx, tx.tx, tx.sesid, tn, val, called)
+	if err != nil {
+		return err
+	}
+
+	tx.e.seqMutex.Lock()
+	defer tx.e.seqMutex.Unlock()
+
+	delete(tx.e.seqCaches, tn)
+	if called {
+		tx.e.setSequenceValue(tx.sesid, tn, val)
+	}
 	return nil
 }
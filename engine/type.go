@@ -4,10 +4,12 @@ package engine
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
 
 	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/parser"
 	"github.com/leftmike/maho/sql"
 )
 
@@ -215,6 +217,148 @@ func (tt *TableType) primaryColumn(colNum int) bool {
 	return false
 }
 
+func (tt *TableType) columnNumber(col sql.Identifier) (int, bool) {
+	for cdx, nam := range tt.cols {
+		if nam == col {
+			return cdx, true
+		}
+	}
+	return -1, false
+}
+
+func (tt *TableType) AddColumn(col sql.Identifier, ct sql.ColumnType,
+	cd sql.ColumnDefault) *TableType {
+
+	tt.cols = append(tt.cols, col)
+	tt.colTypes = append(tt.colTypes, ct)
+	tt.colDefaults = append(tt.colDefaults, cd)
+	tt.ver += 1
+	return tt
+}
+
+func mapColumnKey(key []sql.ColumnKey, colMap []int) ([]sql.ColumnKey, bool) {
+	if key == nil {
+		return nil, true
+	}
+
+	mkey := make([]sql.ColumnKey, 0, len(key))
+	for _, ck := range key {
+		col := colMap[ck.Column()]
+		if col < 0 {
+			return nil, false
+		}
+		mkey = append(mkey, sql.MakeColumnKey(col, ck.Reverse()))
+	}
+	return mkey, true
+}
+
+func mapColumns(cols []int, colMap []int) ([]int, bool) {
+	mcols := make([]int, 0, len(cols))
+	for _, col := range cols {
+		if colMap[col] < 0 {
+			return nil, false
+		}
+		mcols = append(mcols, colMap[col])
+	}
+	return mcols, true
+}
+
+func dropColumnMap(numCols, colNum int) []int {
+	colMap := make([]int, numCols)
+	for cdx := range colMap {
+		if cdx < colNum {
+			colMap[cdx] = cdx
+		} else if cdx == colNum {
+			colMap[cdx] = -1
+		} else {
+			colMap[cdx] = cdx - 1
+		}
+	}
+	return colMap
+}
+
+// dropColumn removes the column, along with any indexes and constraints which use it. Foreign
+// keys from and to the table must already have been dropped.
+func (tt *TableType) dropColumn(tn sql.TableName, colNum int) error {
+	if tt.primaryColumn(colNum) {
+		return fmt.Errorf("engine: table %s: primary key column %s may not be dropped", tn,
+			tt.cols[colNum])
+	}
+	if len(tt.foreignKeys) > 0 || len(tt.foreignRefs) > 0 {
+		panic(fmt.Sprintf("table %s: foreign keys must be dropped before dropping column", tn))
+	}
+
+	colMap := dropColumnMap(len(tt.cols), colNum)
+	tt.primary, _ = mapColumnKey(tt.primary, colMap)
+
+	var indexes []sql.IndexType
+	for _, it := range tt.indexes {
+		key, ok := mapColumnKey(it.Key, colMap)
+		if !ok {
+			continue
+		}
+		it.Key = key
+		it.Columns, ok = mapColumns(it.Columns, colMap)
+		if !ok {
+			panic(fmt.Sprintf("table %s: index %s: column %d not in key", tn, it.Name, colNum))
+		}
+		indexes = append(indexes, it)
+	}
+	tt.indexes = indexes
+
+	var constraints []constraint
+	for _, con := range tt.constraints {
+		if con.colNum != colNum {
+			if con.colNum > colNum {
+				con.colNum -= 1
+			}
+			constraints = append(constraints, con)
+		}
+	}
+	tt.constraints = constraints
+
+	var checks []checkConstraint
+	for _, chk := range tt.checks {
+		check, ok := expr.MapColumns(chk.check, colMap)
+		if ok {
+			chk.check = check
+			checks = append(checks, chk)
+		}
+	}
+	tt.checks = checks
+
+	tt.cols = append(tt.cols[:colNum:colNum], tt.cols[colNum+1:]...)
+	tt.colTypes = append(tt.colTypes[:colNum:colNum], tt.colTypes[colNum+1:]...)
+	tt.colDefaults = append(tt.colDefaults[:colNum:colNum], tt.colDefaults[colNum+1:]...)
+	tt.ver += 1
+	return nil
+}
+
+func (tt *TableType) renameColumn(tn sql.TableName, colNum int, ncol sql.Identifier) error {
+	if _, ok := tt.columnNumber(ncol); ok {
+		return fmt.Errorf("engine: table %s: column %s already exists", tn, ncol)
+	}
+
+	// The compiled check constraints refer to columns by number, but the source of each check
+	// constraint must be changed to use the new name of the column.
+	checkExprs := make([]string, len(tt.checks))
+	for cdx, chk := range tt.checks {
+		p := parser.NewParser(strings.NewReader(chk.checkExpr), chk.checkExpr)
+		e, err := p.ParseExpr()
+		if err != nil {
+			return fmt.Errorf("engine: table %s: check constraint %s: %s", tn, chk.name, err)
+		}
+		checkExprs[cdx] = expr.RenameRef(e, tt.cols[colNum], ncol).String()
+	}
+	for cdx := range tt.checks {
+		tt.checks[cdx].checkExpr = checkExprs[cdx]
+	}
+
+	tt.cols[colNum] = ncol
+	tt.ver += 1
+	return nil
+}
+
 func (tt *TableType) dropColumnConstraint(tn sql.TableName, cn sql.Identifier) error {
 	var constraints []constraint
 	for _, con := range tt.constraints {
@@ -226,8 +370,7 @@ func (tt *TableType) dropColumnConstraint(tn sql.TableName, cn sql.Identifier) e
 					panic(fmt.Sprintf(
 						"table %s: mismatch between constraint and column default", tn))
 				}
-				tt.colDefaults[con.colNum].Default = nil
-				tt.colDefaults[con.colNum].DefaultExpr = ""
+				tt.colDefaults[con.colNum] = sql.ColumnDefault{}
 			case sql.NotNullConstraint:
 				if !tt.colTypes[con.colNum].NotNull {
 					panic(fmt.Sprintf(
@@ -303,8 +446,7 @@ func (tt *TableType) dropConstraint(tn sql.TableName, cn, col sql.Identifier,
 			return true,
 				fmt.Errorf("engine: table %s: column %s: missing default constraint", tn, col)
 		}
-		tt.colDefaults[colNum].Default = nil
-		tt.colDefaults[colNum].DefaultExpr = ""
+		tt.colDefaults[colNum] = sql.ColumnDefault{}
 	case sql.NotNullConstraint:
 		if !tt.colTypes[colNum].NotNull {
 			return true,
@@ -399,13 +541,17 @@ func generateCheckSQL(fktn sql.TableName, fkCols []int, fktt *TableType, rtn sql
 			rtt.cols[rkey[cdx].Column()])
 	}
 	s += " WHERE"
+	for _, col := range fkCols {
+		s += fmt.Sprintf(" (%s.%s IS NOT NULL) AND", fktn.Table, fktt.cols[col])
+	}
+	s += " ("
 	for cdx, ck := range rkey {
 		if cdx > 0 {
 			s += " OR"
 		}
 		s += fmt.Sprintf(" (%s.%s IS NULL)", rtn.Table, rtt.cols[ck.Column()])
 	}
-	return s
+	return s + ")"
 }
 
 func generateTableName(tn sql.TableName) string {
@@ -747,9 +893,12 @@ func (tt *TableType) Encode() ([]byte, error) {
 				Type:        DataType(colTypes[cdx].Type),
 				Size:        colTypes[cdx].Size,
 				Fixed:       colTypes[cdx].Fixed,
+				Scale:       colTypes[cdx].Scale,
+				Array:       colTypes[cdx].Array,
 				NotNull:     colTypes[cdx].NotNull,
 				Default:     expr.Encode(colDefaults[cdx].Default),
 				DefaultExpr: colDefaults[cdx].DefaultExpr,
+				Always:      colDefaults[cdx].Always,
 			})
 	}
 
@@ -871,6 +1020,8 @@ func DecodeTableType(tn sql.TableName, buf []byte) (*TableType, error) {
 				Type:    sql.DataType(md.Columns[cdx].Type),
 				Size:    md.Columns[cdx].Size,
 				Fixed:   md.Columns[cdx].Fixed,
+				Scale:   md.Columns[cdx].Scale,
+				Array:   md.Columns[cdx].Array,
 				NotNull: md.Columns[cdx].NotNull,
 			})
 		dflt, err := expr.Decode(md.Columns[cdx].Default)
@@ -881,6 +1032,7 @@ func DecodeTableType(tn sql.TableName, buf []byte) (*TableType, error) {
 			sql.ColumnDefault{
 				Default:     dflt,
 				DefaultExpr: md.Columns[cdx].DefaultExpr,
+				Always:      md.Columns[cdx].Always,
 			})
 	}
 
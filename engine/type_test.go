@@ -109,3 +109,38 @@ func TestEncodeDecode(t *testing.T) {
 		t.Errorf("DecodeTableType() got %#v want %#v", tt2, tt)
 	}
 }
+
+func TestRenameColumn(t *testing.T) {
+	tn := sql.TableName{sql.DATABASE, sql.SCHEMA, sql.TABLE}
+	tt := &TableType{
+		cols:     []sql.Identifier{sql.ID("c1"), sql.ID("c2")},
+		colTypes: []sql.ColumnType{sql.Int64ColType, sql.Int64ColType},
+		checks: []checkConstraint{
+			{
+				name:      sql.ID("check_1"),
+				checkExpr: "c1 > 1",
+			},
+			{
+				name:      sql.ID("check_2"),
+				checkExpr: "c1 >",
+			},
+		},
+	}
+
+	err := tt.renameColumn(tn, 0, sql.ID("c3"))
+	if err == nil {
+		t.Errorf("renameColumn() did not fail with unparsable check constraint")
+	}
+	if tt.cols[0] != sql.ID("c1") || tt.checks[0].checkExpr != "c1 > 1" {
+		t.Errorf("renameColumn() failed but changed the table type")
+	}
+
+	tt.checks = tt.checks[:1]
+	err = tt.renameColumn(tn, 0, sql.ID("c3"))
+	if err != nil {
+		t.Errorf("renameColumn() failed with %s", err)
+	}
+	if tt.cols[0] != sql.ID("c3") || tt.checks[0].checkExpr != "(c3 > 1)" {
+		t.Errorf("renameColumn() got %s and %q", tt.cols[0], tt.checks[0].checkExpr)
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/leftmike/maho/sql"
+)
+
+type ViewType struct {
+	cols  []sql.Identifier
+	query string
+	sn    sql.SchemaName // Used to resolve table names in query.
+	deps  []sql.TableName
+}
+
+func makeViewType(cols []sql.Identifier, query string, sn sql.SchemaName,
+	deps []sql.TableName) *ViewType {
+
+	return &ViewType{
+		cols:  cols,
+		query: query,
+		sn:    sn,
+		deps:  deps,
+	}
+}
+
+func (vt *ViewType) Columns() []sql.Identifier {
+	return vt.cols
+}
+
+func (vt *ViewType) Query() string {
+	return vt.query
+}
+
+func (vt *ViewType) SchemaName() sql.SchemaName {
+	return vt.sn
+}
+
+func (vt *ViewType) dependsOn(tn sql.TableName) bool {
+	for _, dep := range vt.deps {
+		if dep == tn {
+			return true
+		}
+	}
+	return false
+}
+
+func (vt *ViewType) Encode() ([]byte, error) {
+	var md ViewTypeMetadata
+	md.Columns = make([]string, 0, len(vt.cols))
+	for _, col := range vt.cols {
+		md.Columns = append(md.Columns, col.String())
+	}
+	md.Query = vt.query
+	md.Database = vt.sn.Database.String()
+	md.Schema = vt.sn.Schema.String()
+	md.Dependencies = make([]*TableName, 0, len(vt.deps))
+	for _, dep := range vt.deps {
+		md.Dependencies = append(md.Dependencies,
+			&TableName{
+				Database: dep.Database.String(),
+				Schema:   dep.Schema.String(),
+				Table:    dep.Table.String(),
+			})
+	}
+
+	return proto.Marshal(&md)
+}
+
+func DecodeViewType(tn sql.TableName, buf []byte) (*ViewType, error) {
+	var md ViewTypeMetadata
+	err := proto.Unmarshal(buf, &md)
+	if err != nil {
+		return nil, fmt.Errorf("engine: view %s: %s", tn, err)
+	}
+
+	cols := make([]sql.Identifier, 0, len(md.Columns))
+	for _, col := range md.Columns {
+		cols = append(cols, sql.QuotedID(col))
+	}
+	deps := make([]sql.TableName, 0, len(md.Dependencies))
+	for _, dep := range md.Dependencies {
+		deps = append(deps,
+			sql.TableName{
+				Database: sql.QuotedID(dep.Database),
+				Schema:   sql.QuotedID(dep.Schema),
+				Table:    sql.QuotedID(dep.Table),
+			})
+	}
+
+	return makeViewType(cols, md.Query,
+		sql.SchemaName{sql.QuotedID(md.Database), sql.QuotedID(md.Schema)}, deps), nil
+}
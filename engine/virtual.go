@@ -190,13 +190,46 @@ func (e *Engine) makeTablesTable(ctx context.Context, tx sql.Transaction,
 				sql.StringValue(tn.Database.String()),
 				sql.StringValue(scname.String()),
 				sql.StringValue(tblname.String()),
+				sql.StringValue("table"),
+			})
+		}
+
+		if scname == sql.METADATA || (tn.Database == sql.SYSTEM && scname == sql.INFO) {
+			continue
+		}
+		vwnames, err := e.st.ListViews(ctx, tx.(*transaction).tx,
+			sql.SchemaName{tn.Database, scname})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, vwname := range vwnames {
+			values = append(values, []sql.Value{
+				sql.StringValue(tn.Database.String()),
+				sql.StringValue(scname.String()),
+				sql.StringValue(vwname.String()),
+				sql.StringValue("view"),
+			})
+		}
+
+		seqnames, err := e.st.ListSequences(ctx, tx.(*transaction).tx,
+			sql.SchemaName{tn.Database, scname})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, seqname := range seqnames {
+			values = append(values, []sql.Value{
+				sql.StringValue(tn.Database.String()),
+				sql.StringValue(scname.String()),
+				sql.StringValue(seqname.String()),
+				sql.StringValue("sequence"),
 			})
 		}
 	}
 
 	return MakeVirtualTable(tn,
-		[]sql.Identifier{sql.ID("database_name"), sql.ID("schema_name"), sql.ID("table_name")},
-		[]sql.ColumnType{sql.IdColType, sql.IdColType, sql.IdColType}, values)
+		[]sql.Identifier{sql.ID("database_name"), sql.ID("schema_name"), sql.ID("table_name"),
+			sql.ID("table_type")},
+		[]sql.ColumnType{sql.IdColType, sql.IdColType, sql.IdColType, sql.IdColType}, values)
 }
 
 var (
@@ -3,8 +3,10 @@ package datadef
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
 	"github.com/leftmike/maho/sql"
 )
 
@@ -26,6 +28,49 @@ type DropConstraint struct {
 	Type     sql.ConstraintType
 }
 
+type SetDefault struct {
+	Column        sql.Identifier
+	Default       expr.Expr
+	columnDefault sql.ColumnDefault
+}
+
+type SetNotNull struct {
+	Column sql.Identifier
+}
+
+type AlterType struct {
+	Column     sql.Identifier
+	ColumnType sql.ColumnType
+	Using      expr.Expr
+	using      sql.CExpr
+	Cascade    bool
+}
+
+type AddColumn struct {
+	Column        sql.Identifier
+	ColumnType    sql.ColumnType
+	Default       expr.Expr
+	columnDefault sql.ColumnDefault
+	IfNotExists   bool
+	Constraints   []Constraint // Named DEFAULT and NOT NULL constraints
+}
+
+type DropColumn struct {
+	Column   sql.Identifier
+	IfExists bool
+	Cascade  bool
+}
+
+type RenameColumn struct {
+	Column  sql.Identifier
+	NewName sql.Identifier
+	Cascade bool
+}
+
+type RenameTable struct {
+	NewName sql.Identifier
+}
+
 type AlterTable struct {
 	Table   sql.TableName
 	Actions []AlterAction
@@ -59,6 +104,190 @@ func (dc *DropConstraint) execute(ctx context.Context, tx sql.Transaction, tn sq
 	return tx.DropConstraint(ctx, tn, dc.Name, dc.IfExists, dc.Column, dc.Type)
 }
 
+func (sd SetDefault) String() string {
+	return fmt.Sprintf("ALTER %s SET DEFAULT %s", sd.Column, sd.Default)
+}
+
+func (sd *SetDefault) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName) error {
+
+	dflt, _, err := expr.Compile(ctx, pctx, tx, nil, sd.Default)
+	if err != nil {
+		return err
+	}
+	sd.columnDefault = sql.ColumnDefault{
+		Default:     dflt,
+		DefaultExpr: sd.Default.String(),
+	}
+	return nil
+}
+
+func (sd *SetDefault) execute(ctx context.Context, tx sql.Transaction, tn sql.TableName,
+	check bool) error {
+
+	return tx.SetColumnDefault(ctx, tn, sd.Column, sd.columnDefault)
+}
+
+func (snn SetNotNull) String() string {
+	return fmt.Sprintf("ALTER %s SET NOT NULL", snn.Column)
+}
+
+func (snn *SetNotNull) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName) error {
+
+	return nil
+}
+
+func (snn *SetNotNull) execute(ctx context.Context, tx sql.Transaction, tn sql.TableName,
+	check bool) error {
+
+	return tx.SetNotNull(ctx, tn, snn.Column)
+}
+
+func (at AlterType) String() string {
+	s := fmt.Sprintf("ALTER %s TYPE %s", at.Column,
+		sql.ColumnDataType(at.ColumnType))
+	if at.Using != nil {
+		s += fmt.Sprintf(" USING %s", at.Using)
+	}
+	if at.Cascade {
+		s += " CASCADE"
+	}
+	return s
+}
+
+func (at *AlterType) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName) error {
+
+	if at.Using == nil {
+		return nil
+	}
+
+	tt, err := tx.LookupTableType(ctx, tn)
+	if err != nil {
+		return err
+	}
+	cctx := tableCheck{
+		cols:     tt.Columns(),
+		colTypes: tt.ColumnTypes(),
+	}
+	at.using, _, err = expr.Compile(ctx, pctx, tx, cctx, at.Using)
+	return err
+}
+
+func (at *AlterType) execute(ctx context.Context, tx sql.Transaction, tn sql.TableName,
+	check bool) error {
+
+	return tx.AlterColumnType(ctx, tn, at.Column, at.ColumnType, at.using, at.Cascade)
+}
+
+func (ac AddColumn) String() string {
+	s := "ADD COLUMN"
+	if ac.IfNotExists {
+		s += " IF NOT EXISTS"
+	}
+	s += fmt.Sprintf(" %s %s", ac.Column,
+		sql.ColumnDataType(ac.ColumnType))
+	if ac.ColumnType.NotNull {
+		s += " NOT NULL"
+	}
+	if ac.Default != nil {
+		s += fmt.Sprintf(" DEFAULT %s", ac.Default)
+	}
+	return s
+}
+
+func (ac *AddColumn) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName) error {
+
+	if ac.Default != nil {
+		dflt, _, err := expr.Compile(ctx, pctx, tx, nil, ac.Default)
+		if err != nil {
+			return err
+		}
+		ac.columnDefault = sql.ColumnDefault{
+			Default:     dflt,
+			DefaultExpr: ac.Default.String(),
+		}
+	}
+	return nil
+}
+
+func (ac *AddColumn) execute(ctx context.Context, tx sql.Transaction, tn sql.TableName,
+	check bool) error {
+
+	var cons []sql.Constraint
+	for _, con := range ac.Constraints {
+		cons = append(cons,
+			sql.Constraint{
+				Type: con.Type,
+				Name: con.Name,
+			})
+	}
+	return tx.AddColumn(ctx, tn, ac.Column, ac.ColumnType, ac.columnDefault, cons,
+		ac.IfNotExists)
+}
+
+func (dc DropColumn) String() string {
+	s := "DROP COLUMN"
+	if dc.IfExists {
+		s += " IF EXISTS"
+	}
+	s = fmt.Sprintf("%s %s", s, dc.Column)
+	if dc.Cascade {
+		s += " CASCADE"
+	}
+	return s
+}
+
+func (dc *DropColumn) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName) error {
+
+	return nil
+}
+
+func (dc *DropColumn) execute(ctx context.Context, tx sql.Transaction, tn sql.TableName,
+	check bool) error {
+
+	return tx.DropColumn(ctx, tn, dc.Column, dc.IfExists, dc.Cascade)
+}
+
+func (rc RenameColumn) String() string {
+	s := fmt.Sprintf("RENAME COLUMN %s TO %s", rc.Column, rc.NewName)
+	if rc.Cascade {
+		s += " CASCADE"
+	}
+	return s
+}
+
+func (rc *RenameColumn) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName) error {
+
+	return nil
+}
+
+func (rc *RenameColumn) execute(ctx context.Context, tx sql.Transaction, tn sql.TableName,
+	check bool) error {
+
+	return tx.RenameColumn(ctx, tn, rc.Column, rc.NewName, rc.Cascade)
+}
+
+func (rt RenameTable) String() string {
+	return fmt.Sprintf("RENAME TO %s", rt.NewName)
+}
+
+func (rt *RenameTable) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName) error {
+
+	return nil
+}
+
+func (rt *RenameTable) execute(ctx context.Context, tx sql.Transaction, tn sql.TableName,
+	check bool) error {
+
+	return tx.RenameTable(ctx, tn, rt.NewName)
+}
+
 func (stmt *AlterTable) String() string {
 	s := fmt.Sprintf("ALTER TABLE %s ", stmt.Table)
 	for adx, act := range stmt.Actions {
@@ -103,3 +332,53 @@ func (stmt *AlterTable) Execute(ctx context.Context, tx sql.Transaction) (int64,
 
 	return -1, nil
 }
+
+type AlterSequence struct {
+	Sequence sql.TableName
+	Options  SequenceOptions
+}
+
+func (stmt *AlterSequence) String() string {
+	return fmt.Sprintf("ALTER SEQUENCE %s%s", stmt.Sequence, stmt.Options)
+}
+
+func (stmt *AlterSequence) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	stmt.Sequence = pctx.ResolveTableName(stmt.Sequence)
+	return stmt, nil
+}
+
+func (_ *AlterSequence) Tag() string {
+	return "ALTER SEQUENCE"
+}
+
+func (stmt *AlterSequence) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	seq, err := tx.LookupSequence(ctx, stmt.Sequence)
+	if err != nil {
+		return -1, err
+	}
+
+	seq = stmt.Options.sequence(&seq, math.MaxInt64)
+	err = tx.AlterSequence(ctx, stmt.Sequence, seq)
+	if err != nil {
+		return -1, err
+	}
+
+	if stmt.Options.Restart {
+		err = tx.NextStmt(ctx)
+		if err != nil {
+			return -1, err
+		}
+
+		val := seq.Start
+		if stmt.Options.RestartWith != nil {
+			val = *stmt.Options.RestartWith
+		}
+		err = tx.SetSequenceValue(ctx, stmt.Sequence, val, false)
+		if err != nil {
+			return -1, err
+		}
+	}
+	return -1, nil
+}
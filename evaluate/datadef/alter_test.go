@@ -0,0 +1,90 @@
+package datadef_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/evaluate/datadef"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+func TestAlterTable(t *testing.T) {
+	cases := []struct {
+		s datadef.AlterTable
+		r string
+	}{
+		{
+			datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.AddColumn{
+						Column:     sql.ID("c1"),
+						ColumnType: sql.ColumnType{Type: sql.IntegerType, Size: 4, NotNull: true},
+						Default:    expr.Int64Literal(10),
+					},
+					&datadef.AddColumn{
+						Column:      sql.ID("c2"),
+						ColumnType:  sql.ColumnType{Type: sql.StringType, Size: sql.MaxColumnSize},
+						IfNotExists: true,
+					},
+				},
+			},
+			"ALTER TABLE tbl ADD COLUMN c1 INT NOT NULL DEFAULT 10, " +
+				"ADD COLUMN IF NOT EXISTS c2 TEXT",
+		},
+		{
+			datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.DropColumn{Column: sql.ID("c1")},
+					&datadef.DropColumn{Column: sql.ID("c2"), IfExists: true},
+				},
+			},
+			"ALTER TABLE tbl DROP COLUMN c1, DROP COLUMN IF EXISTS c2",
+		},
+		{
+			datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.RenameColumn{Column: sql.ID("c1"), NewName: sql.ID("c2")},
+				},
+			},
+			"ALTER TABLE tbl RENAME COLUMN c1 TO c2",
+		},
+		{
+			datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.SetDefault{Column: sql.ID("c1"), Default: expr.Int64Literal(1)},
+					&datadef.SetNotNull{Column: sql.ID("c2")},
+					&datadef.AlterType{
+						Column:     sql.ID("c3"),
+						ColumnType: sql.ColumnType{Type: sql.IntegerType, Size: 8},
+					},
+					&datadef.AlterType{
+						Column:     sql.ID("c4"),
+						ColumnType: sql.ColumnType{Type: sql.StringType, Size: sql.MaxColumnSize},
+						Using:      expr.Ref{sql.ID("c5")},
+					},
+				},
+			},
+			"ALTER TABLE tbl ALTER c1 SET DEFAULT 1, ALTER c2 SET NOT NULL, " +
+				"ALTER c3 TYPE BIGINT, ALTER c4 TYPE TEXT USING c5",
+		},
+		{
+			datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.RenameTable{NewName: sql.ID("tbl2")},
+				},
+			},
+			"ALTER TABLE tbl RENAME TO tbl2",
+		},
+	}
+
+	for _, c := range cases {
+		if c.s.String() != c.r {
+			t.Errorf("AlterTable{%v}.String() got %s want %s", c.s, c.s.String(), c.r)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package datadef
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"github.com/leftmike/maho/evaluate"
 	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/evaluate/query"
 	"github.com/leftmike/maho/sql"
 )
 
@@ -92,6 +94,130 @@ func (c Constraint) String() string {
 	return ""
 }
 
+type SequenceOptions struct {
+	Increment   *int64
+	MinValue    *int64
+	NoMinValue  bool
+	MaxValue    *int64
+	NoMaxValue  bool
+	Start       *int64
+	Cache       *int64
+	Cycle       *bool
+	Restart     bool // ALTER SEQUENCE only
+	RestartWith *int64
+}
+
+func (so SequenceOptions) String() string {
+	var s string
+	if so.Increment != nil {
+		s += fmt.Sprintf(" INCREMENT BY %d", *so.Increment)
+	}
+	if so.MinValue != nil {
+		s += fmt.Sprintf(" MINVALUE %d", *so.MinValue)
+	} else if so.NoMinValue {
+		s += " NO MINVALUE"
+	}
+	if so.MaxValue != nil {
+		s += fmt.Sprintf(" MAXVALUE %d", *so.MaxValue)
+	} else if so.NoMaxValue {
+		s += " NO MAXVALUE"
+	}
+	if so.Start != nil {
+		s += fmt.Sprintf(" START WITH %d", *so.Start)
+	}
+	if so.Restart {
+		s += " RESTART"
+		if so.RestartWith != nil {
+			s += fmt.Sprintf(" WITH %d", *so.RestartWith)
+		}
+	}
+	if so.Cache != nil {
+		s += fmt.Sprintf(" CACHE %d", *so.Cache)
+	}
+	if so.Cycle != nil {
+		if *so.Cycle {
+			s += " CYCLE"
+		} else {
+			s += " NO CYCLE"
+		}
+	}
+	return s
+}
+
+// sequence applies the options to seq. When a sequence is being created, seq is nil, and the
+// options which were not specified get defaults based on max, the largest value allowed.
+func (so SequenceOptions) sequence(seq *sql.Sequence, max int64) sql.Sequence {
+	var s sql.Sequence
+	if seq != nil {
+		s = *seq
+	} else {
+		s.Increment = 1
+		s.Cache = 1
+	}
+
+	if so.Increment != nil {
+		s.Increment = *so.Increment
+	}
+	if so.Cache != nil {
+		s.Cache = *so.Cache
+	}
+	if so.Cycle != nil {
+		s.Cycle = *so.Cycle
+	}
+	if so.MinValue != nil {
+		s.MinValue = *so.MinValue
+	} else if seq == nil || so.NoMinValue {
+		if s.Increment > 0 {
+			s.MinValue = 1
+		} else {
+			s.MinValue = -max - 1
+		}
+	}
+	if so.MaxValue != nil {
+		s.MaxValue = *so.MaxValue
+	} else if seq == nil || so.NoMaxValue {
+		if s.Increment > 0 {
+			s.MaxValue = max
+		} else {
+			s.MaxValue = -1
+		}
+	}
+	if so.Start != nil {
+		s.Start = *so.Start
+	} else if seq == nil {
+		if s.Increment > 0 {
+			s.Start = s.MinValue
+		} else {
+			s.Start = s.MaxValue
+		}
+	}
+	return s
+}
+
+// Identity is a SERIAL or GENERATED AS IDENTITY column; its values come from a sequence owned
+// by the column.
+type Identity struct {
+	ColNum  int
+	Always  bool
+	Options SequenceOptions
+}
+
+type identitySequence struct {
+	sequence sql.TableName
+	seq      sql.Sequence
+	col      sql.Identifier
+}
+
+func identityMaxValue(ct sql.ColumnType) int64 {
+	switch ct.Size {
+	case 2:
+		return math.MaxInt16
+	case 4:
+		return math.MaxInt32
+	}
+	return math.MaxInt64
+}
+
 type CreateTable struct {
 	Table          sql.TableName
 	Columns        []sql.Identifier
@@ -102,6 +228,17 @@ type CreateTable struct {
 	Constraints    []Constraint
 	constraints    []sql.Constraint
 	ForeignKeys    []*ForeignKey
+	Identities     []Identity
+	sequences      []identitySequence
+}
+
+func (stmt *CreateTable) identity(colNum int) (Identity, bool) {
+	for _, id := range stmt.Identities {
+		if id.ColNum == colNum {
+			return id, true
+		}
+	}
+	return Identity{}, false
 }
 
 func (stmt *CreateTable) String() string {
@@ -115,7 +252,7 @@ func (stmt *CreateTable) String() string {
 		if i > 0 {
 			s += ", "
 		}
-		s += fmt.Sprintf("%s %s", stmt.Columns[i], sql.ColumnDataType(ct.Type, ct.Size, ct.Fixed))
+		s += fmt.Sprintf("%s %s", stmt.Columns[i], sql.ColumnDataType(ct))
 		if ct.NotNull {
 			s += " NOT NULL"
 		}
@@ -123,6 +260,16 @@ func (stmt *CreateTable) String() string {
 		if cd != nil {
 			s += fmt.Sprintf(" DEFAULT %s", cd)
 		}
+		if id, ok := stmt.identity(i); ok {
+			if id.Always {
+				s += " GENERATED ALWAYS AS IDENTITY"
+			} else {
+				s += " GENERATED BY DEFAULT AS IDENTITY"
+			}
+			if opts := id.Options.String(); opts != "" {
+				s += fmt.Sprintf(" (%s)", opts[1:])
+			}
+		}
 	}
 	for _, c := range stmt.Constraints {
 		s += c.String()
@@ -230,9 +377,39 @@ func (stmt *CreateTable) Plan(ctx context.Context, pctx evaluate.PlanContext,
 			})
 	}
 
-	for _, cd := range stmt.ColumnDefaults {
+	for cdx, cd := range stmt.ColumnDefaults {
 		var dflt sql.CExpr
 		var dfltExpr string
+		var always bool
+		if id, ok := stmt.identity(cdx); ok {
+			col := stmt.Columns[cdx]
+			ct := stmt.ColumnTypes[cdx]
+			if ct.Type != sql.IntegerType {
+				return nil, fmt.Errorf("engine: table %s: identity column %s must be an integer",
+					stmt.Table, col)
+			} else if cd != nil {
+				return nil, fmt.Errorf("engine: table %s: identity column %s has a default",
+					stmt.Table, col)
+			}
+
+			sqn := sql.TableName{
+				Database: stmt.Table.Database,
+				Schema:   stmt.Table.Schema,
+				Table:    sql.QuotedID(fmt.Sprintf("%s_%s_seq", stmt.Table.Table, col)),
+			}
+			stmt.sequences = append(stmt.sequences,
+				identitySequence{
+					sequence: sqn,
+					seq:      id.Options.sequence(nil, identityMaxValue(ct)),
+					col:      col,
+				})
+			stmt.ColumnTypes[cdx].NotNull = true
+			always = id.Always
+			cd = &expr.Call{
+				Name: sql.ID("nextval"),
+				Args: []expr.Expr{expr.StringLiteral(expr.FormatSequenceName(sqn))},
+			}
+		}
 		if cd != nil {
 			var err error
 			dflt, _, err = expr.Compile(ctx, pctx, tx, nil, cd)
@@ -245,6 +422,7 @@ func (stmt *CreateTable) Plan(ctx context.Context, pctx evaluate.PlanContext,
 			sql.ColumnDefault{
 				Default:     dflt,
 				DefaultExpr: dfltExpr,
+				Always:      always,
 			})
 	}
 
@@ -256,6 +434,26 @@ func (_ *CreateTable) Tag() string {
 }
 
 func (stmt *CreateTable) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	if len(stmt.sequences) > 0 {
+		if stmt.IfNotExists {
+			_, err := tx.LookupTableType(ctx, stmt.Table)
+			if err == nil {
+				return -1, nil
+			}
+		}
+
+		for _, is := range stmt.sequences {
+			err := tx.CreateSequence(ctx, is.sequence, is.seq, stmt.Table, is.col, false)
+			if err != nil {
+				return -1, err
+			}
+			err = tx.NextStmt(ctx)
+			if err != nil {
+				return -1, err
+			}
+		}
+	}
+
 	err := tx.CreateTable(ctx, stmt.Table, stmt.Columns, stmt.ColumnTypes, stmt.columnDefaults,
 		stmt.constraints, stmt.IfNotExists)
 	if err != nil {
@@ -377,3 +575,142 @@ func (_ *CreateSchema) Tag() string {
 func (stmt *CreateSchema) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
 	return -1, tx.CreateSchema(ctx, stmt.Schema)
 }
+
+type CreateView struct {
+	View    sql.TableName
+	Columns []sql.Identifier
+	Stmt    evaluate.Stmt
+	Replace bool
+
+	cols []sql.Identifier
+	sn   sql.SchemaName
+	deps []sql.TableName
+}
+
+func (stmt *CreateView) String() string {
+	s := "CREATE "
+	if stmt.Replace {
+		s += "OR REPLACE "
+	}
+	s += fmt.Sprintf("VIEW %s ", stmt.View)
+	if stmt.Columns != nil {
+		s += "("
+		for i, col := range stmt.Columns {
+			if i > 0 {
+				s += ", "
+			}
+			s += col.String()
+		}
+		s += ") "
+	}
+	return fmt.Sprintf("%sAS %s", s, stmt.Stmt)
+}
+
+// viewTransaction records the tables and views referenced while planning the query of a view.
+type viewTransaction struct {
+	sql.Transaction
+	deps []sql.TableName
+}
+
+func (vtx *viewTransaction) addDependency(tn sql.TableName) {
+	for _, dep := range vtx.deps {
+		if dep == tn {
+			return
+		}
+	}
+	vtx.deps = append(vtx.deps, tn)
+}
+
+func (vtx *viewTransaction) LookupTableType(ctx context.Context,
+	tn sql.TableName) (sql.TableType, error) {
+
+	vtx.addDependency(tn)
+	return vtx.Transaction.LookupTableType(ctx, tn)
+}
+
+func (vtx *viewTransaction) LookupView(ctx context.Context, tn sql.TableName) (sql.ViewType,
+	error) {
+
+	vt, err := vtx.Transaction.LookupView(ctx, tn)
+	if vt != nil {
+		vtx.addDependency(tn)
+	}
+	return vt, err
+}
+
+func (stmt *CreateView) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	stmt.View = pctx.ResolveTableName(stmt.View)
+	stmt.sn = pctx.ResolveTableName(sql.TableName{}).SchemaName()
+
+	vtx := &viewTransaction{Transaction: tx}
+	err := query.ExpandResults(ctx, pctx, vtx, stmt.Stmt)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := stmt.Stmt.Plan(ctx, pctx, vtx, nil)
+	if err != nil {
+		return nil, err
+	}
+	rowsPlan, ok := plan.(evaluate.RowsPlan)
+	if !ok {
+		return nil, fmt.Errorf("engine: view %s: expected rows: %s", stmt.View, stmt.Stmt)
+	}
+
+	cols := rowsPlan.Columns()
+	if stmt.Columns != nil {
+		if len(stmt.Columns) != len(cols) {
+			return nil, fmt.Errorf("engine: view %s: wrong number of column aliases", stmt.View)
+		}
+		cols = stmt.Columns
+	}
+	for cdx, col := range cols {
+		if num, _ := columnNumber(col, cols); num != cdx {
+			return nil, fmt.Errorf("engine: view %s: duplicate column %s", stmt.View, col)
+		}
+	}
+
+	stmt.cols = cols
+	stmt.deps = vtx.deps
+	return stmt, nil
+}
+
+func (_ *CreateView) Tag() string {
+	return "CREATE VIEW"
+}
+
+func (stmt *CreateView) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	return -1, tx.CreateView(ctx, stmt.View, stmt.cols, stmt.Stmt.String(), stmt.sn, stmt.deps,
+		stmt.Replace)
+}
+
+type CreateSequence struct {
+	Sequence    sql.TableName
+	Options     SequenceOptions
+	IfNotExists bool
+}
+
+func (stmt *CreateSequence) String() string {
+	s := "CREATE SEQUENCE"
+	if stmt.IfNotExists {
+		s += " IF NOT EXISTS"
+	}
+	return fmt.Sprintf("%s %s%s", s, stmt.Sequence, stmt.Options)
+}
+
+func (stmt *CreateSequence) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	stmt.Sequence = pctx.ResolveTableName(stmt.Sequence)
+	return stmt, nil
+}
+
+func (_ *CreateSequence) Tag() string {
+	return "CREATE SEQUENCE"
+}
+
+func (stmt *CreateSequence) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	return -1, tx.CreateSequence(ctx, stmt.Sequence, stmt.Options.sequence(nil, math.MaxInt64),
+		sql.TableName{}, 0, stmt.IfNotExists)
+}
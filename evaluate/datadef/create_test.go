@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/leftmike/maho/evaluate"
 	"github.com/leftmike/maho/evaluate/datadef"
 	"github.com/leftmike/maho/evaluate/expr"
 	"github.com/leftmike/maho/evaluate/test"
@@ -13,6 +14,7 @@ import (
 )
 
 func TestCreateTableString(t *testing.T) {
+	start := int64(10)
 	cases := []struct {
 		stmt datadef.CreateTable
 		sql  string
@@ -61,6 +63,25 @@ func TestCreateTableString(t *testing.T) {
 			},
 			sql: "CREATE TABLE t (c1 INT, c2 INT, c3 INT, c4 INT NOT NULL, CONSTRAINT foreign_2 FOREIGN KEY (c1, c2) REFERENCES t2, CONSTRAINT fkey FOREIGN KEY (c3, c4, c2) REFERENCES t3 (p1, p2, p3))",
 		},
+		{
+			stmt: datadef.CreateTable{
+				Table:   sql.TableName{Table: sql.ID("t")},
+				Columns: []sql.Identifier{sql.ID("c1"), sql.ID("c2")},
+				ColumnTypes: []sql.ColumnType{
+					{Type: sql.IntegerType, Size: 4},
+					{Type: sql.IntegerType, Size: 8},
+				},
+				ColumnDefaults: make([]expr.Expr, 2),
+				Identities: []datadef.Identity{
+					{ColNum: 0, Always: true},
+					{
+						ColNum:  1,
+						Options: datadef.SequenceOptions{Start: &start, NoMaxValue: true},
+					},
+				},
+			},
+			sql: "CREATE TABLE t (c1 INT GENERATED ALWAYS AS IDENTITY, c2 BIGINT GENERATED BY DEFAULT AS IDENTITY (NO MAXVALUE START WITH 10))",
+		},
 	}
 
 	for _, c := range cases {
@@ -119,3 +140,75 @@ func TestCreateTablePlan(t *testing.T) {
 		tx.Rollback()
 	}
 }
+
+func TestCreateViewPlan(t *testing.T) {
+	cases := []struct {
+		sql  string
+		fail bool
+	}{
+		{
+			sql: "create view v as select c1, c2 from t",
+		},
+		{
+			sql: "create view v (a, b) as select * from t",
+		},
+		{
+			sql:  "create view v (a) as select c1, c2 from t",
+			fail: true,
+		},
+		{
+			sql:  "create view v as select c1, c1 from t",
+			fail: true,
+		},
+		{
+			sql:  "create view v as select c3 from t",
+			fail: true,
+		},
+		{
+			sql:  "create view v as select * from missing",
+			fail: true,
+		},
+	}
+
+	ctx := context.Background()
+	e, ses := test.StartSession(t)
+
+	p := parser.NewParser(strings.NewReader("create table t (c1 int primary key, c2 int)"),
+		"test")
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := e.Begin(0)
+	plan, err := stmt.Plan(ctx, ses, tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = plan.(evaluate.StmtPlan).Execute(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range cases {
+		p := parser.NewParser(strings.NewReader(c.sql), "test")
+		stmt, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := e.Begin(0)
+
+		_, err = stmt.Plan(ctx, ses, tx, nil)
+		if err == nil {
+			if c.fail {
+				t.Errorf("Plan(%q) did not fail", c.sql)
+			}
+		} else if !c.fail {
+			t.Errorf("Plan(%q) failed with %s", c.sql, err)
+		}
+		tx.Rollback()
+	}
+}
@@ -45,7 +45,14 @@ func (_ *DropTable) Tag() string {
 }
 
 func (stmt *DropTable) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
-	for _, tn := range stmt.Tables {
+	for idx, tn := range stmt.Tables {
+		if idx > 0 {
+			err := tx.NextStmt(ctx)
+			if err != nil {
+				return -1, err
+			}
+		}
+
 		err := tx.DropTable(ctx, tn, stmt.IfExists, stmt.Cascade)
 		if err != nil {
 			return -1, err
@@ -54,6 +61,101 @@ func (stmt *DropTable) Execute(ctx context.Context, tx sql.Transaction) (int64,
 	return -1, nil
 }
 
+type DropView struct {
+	IfExists bool
+	Cascade  bool
+	Views    []sql.TableName
+}
+
+func (stmt *DropView) String() string {
+	s := "DROP VIEW "
+	if stmt.IfExists {
+		s += "IF EXISTS "
+	}
+	for i, vw := range stmt.Views {
+		if i > 0 {
+			s += ", "
+		}
+		s += vw.String()
+	}
+	if stmt.Cascade {
+		s += " CASCADE"
+	}
+	return s
+}
+
+func (stmt *DropView) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	for idx, tn := range stmt.Views {
+		stmt.Views[idx] = pctx.ResolveTableName(tn)
+	}
+	return stmt, nil
+}
+
+func (_ *DropView) Tag() string {
+	return "DROP VIEW"
+}
+
+func (stmt *DropView) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	for _, tn := range stmt.Views {
+		err := tx.DropView(ctx, tn, stmt.IfExists, stmt.Cascade)
+		if err != nil {
+			return -1, err
+		}
+	}
+	return -1, nil
+}
+
+type DropSequence struct {
+	IfExists  bool
+	Sequences []sql.TableName
+}
+
+func (stmt *DropSequence) String() string {
+	s := "DROP SEQUENCE "
+	if stmt.IfExists {
+		s += "IF EXISTS "
+	}
+	for i, seq := range stmt.Sequences {
+		if i > 0 {
+			s += ", "
+		}
+		s += seq.String()
+	}
+	return s
+}
+
+func (stmt *DropSequence) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	for idx, tn := range stmt.Sequences {
+		stmt.Sequences[idx] = pctx.ResolveTableName(tn)
+	}
+	return stmt, nil
+}
+
+func (_ *DropSequence) Tag() string {
+	return "DROP SEQUENCE"
+}
+
+func (stmt *DropSequence) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	for idx, tn := range stmt.Sequences {
+		if idx > 0 {
+			err := tx.NextStmt(ctx)
+			if err != nil {
+				return -1, err
+			}
+		}
+
+		err := tx.DropSequence(ctx, tn, stmt.IfExists)
+		if err != nil {
+			return -1, err
+		}
+	}
+	return -1, nil
+}
+
 type DropIndex struct {
 	Table    sql.TableName
 	Index    sql.Identifier
@@ -123,6 +225,7 @@ func (stmt *DropDatabase) Command(ctx context.Context, ses *evaluate.Session, e
 
 type DropSchema struct {
 	IfExists bool
+	Cascade  bool
 	Schema   sql.SchemaName
 }
 
@@ -131,7 +234,11 @@ func (stmt *DropSchema) String() string {
 	if stmt.IfExists {
 		s += "IF EXISTS "
 	}
-	return s + stmt.Schema.String()
+	s += stmt.Schema.String()
+	if stmt.Cascade {
+		s += " CASCADE"
+	}
+	return s
 }
 
 func (stmt *DropSchema) Plan(ctx context.Context, pctx evaluate.PlanContext,
@@ -146,5 +253,5 @@ func (_ *DropSchema) Tag() string {
 }
 
 func (stmt *DropSchema) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
-	return -1, tx.DropSchema(ctx, stmt.Schema, stmt.IfExists)
+	return -1, tx.DropSchema(ctx, stmt.Schema, stmt.IfExists, stmt.Cascade)
 }
@@ -0,0 +1,44 @@
+package datadef
+
+import (
+	"context"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/sql"
+)
+
+type TruncateTable struct {
+	Cascade bool
+	Tables  []sql.TableName
+}
+
+func (stmt *TruncateTable) String() string {
+	s := "TRUNCATE TABLE "
+	for i, tbl := range stmt.Tables {
+		if i > 0 {
+			s += ", "
+		}
+		s += tbl.String()
+	}
+	if stmt.Cascade {
+		s += " CASCADE"
+	}
+	return s
+}
+
+func (stmt *TruncateTable) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	for idx, tn := range stmt.Tables {
+		stmt.Tables[idx] = pctx.ResolveTableName(tn)
+	}
+	return stmt, nil
+}
+
+func (_ *TruncateTable) Tag() string {
+	return "TRUNCATE TABLE"
+}
+
+func (stmt *TruncateTable) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	return -1, tx.TruncateTables(ctx, stmt.Tables, stmt.Cascade)
+}
@@ -0,0 +1,40 @@
+package datadef_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/evaluate/datadef"
+	"github.com/leftmike/maho/sql"
+)
+
+func TestTruncateTable(t *testing.T) {
+	cases := []struct {
+		s datadef.TruncateTable
+		r string
+	}{
+		{
+			datadef.TruncateTable{
+				Tables: []sql.TableName{
+					{Database: sql.ID("abc"), Schema: sql.ID("def"), Table: sql.ID("ghijk")},
+				},
+			},
+			"TRUNCATE TABLE abc.def.ghijk",
+		},
+		{
+			datadef.TruncateTable{
+				Cascade: true,
+				Tables: []sql.TableName{
+					{Database: sql.ID("abc"), Schema: sql.ID("def"), Table: sql.ID("ghijk")},
+					{Table: sql.ID("jkl")},
+				},
+			},
+			"TRUNCATE TABLE abc.def.ghijk, jkl CASCADE",
+		},
+	}
+
+	for _, c := range cases {
+		if c.s.String() != c.r {
+			t.Errorf("TruncateTable{%v}.String() got %s want %s", c.s, c.s.String(), c.r)
+		}
+	}
+}
@@ -20,7 +20,7 @@ type avgAggregator struct {
 
 func (aa *avgAggregator) Accumulate(vals []sql.Value) error {
 	switch vals[0].(type) {
-	case sql.Float64Value, sql.Int64Value:
+	case sql.Float64Value, sql.Int64Value, sql.NumericValue:
 		aa.count += 1
 	}
 	return aa.sumAggregator.Accumulate(vals)
@@ -36,6 +36,12 @@ func (aa *avgAggregator) Total() (sql.Value, error) {
 				return s / aa.count, nil
 			}
 			return sql.Float64Value(s) / sql.Float64Value(aa.count), nil
+		case sql.NumericValue:
+			n, err := s.Divide(sql.NumericFromInt64(int64(aa.count)))
+			if err != nil {
+				return nil, fmt.Errorf("engine: avg aggregator: %s", err)
+			}
+			return n, nil
 		}
 	}
 	return nil, nil
@@ -45,6 +51,32 @@ func makeAvgAggregator() Aggregator {
 	return &avgAggregator{}
 }
 
+type distinctAggregator struct {
+	agg  Aggregator
+	seen map[string]struct{}
+}
+
+func (da *distinctAggregator) Accumulate(vals []sql.Value) error {
+	key := sql.HashKey(vals)
+	if _, ok := da.seen[key]; ok {
+		return nil
+	}
+	da.seen[key] = struct{}{}
+	return da.agg.Accumulate(vals)
+}
+
+func (da *distinctAggregator) Total() (sql.Value, error) {
+	return da.agg.Total()
+}
+
+// Distinct returns a maker of aggregators which only accumulate each distinct set of values
+// once.
+func Distinct(maker MakeAggregator) MakeAggregator {
+	return func() Aggregator {
+		return &distinctAggregator{agg: maker(), seen: map[string]struct{}{}}
+	}
+}
+
 type countAggregator struct {
 	count int64
 }
@@ -81,6 +113,26 @@ func makeCountAllAggregator() Aggregator {
 	return &countAllAggregator{}
 }
 
+type jsonAggAggregator struct {
+	elems []sql.JSONValue
+}
+
+func (ja *jsonAggAggregator) Accumulate(vals []sql.Value) error {
+	ja.elems = append(ja.elems, valueToJSON(vals[0]))
+	return nil
+}
+
+func (ja *jsonAggAggregator) Total() (sql.Value, error) {
+	if len(ja.elems) > 0 {
+		return sql.JSONArrayValue(ja.elems), nil
+	}
+	return nil, nil
+}
+
+func makeJSONAggAggregator() Aggregator {
+	return &jsonAggAggregator{}
+}
+
 type maxAggregator struct {
 	max     sql.Value
 	nonNull bool
@@ -94,7 +146,9 @@ func (ma *maxAggregator) Accumulate(vals []sql.Value) error {
 		}
 	} else {
 		switch vals[0].(type) {
-		case sql.Float64Value, sql.Int64Value:
+		case sql.Float64Value, sql.Int64Value, sql.NumericValue, sql.DateValue, sql.TimeValue,
+			sql.TimestampValue, sql.TimestampTZValue, sql.IntervalValue:
+
 			ma.max = vals[0]
 			ma.nonNull = true
 		}
@@ -109,6 +163,13 @@ func (ma *maxAggregator) Total() (sql.Value, error) {
 	return nil, nil
 }
 
+func extremeType(args []sql.ColumnType) sql.ColumnType {
+	if isTemporalType(args[0].Type) {
+		return firstArgType(args)
+	}
+	return numType(args)
+}
+
 func makeMaxAggregator() Aggregator {
 	return &maxAggregator{}
 }
@@ -126,7 +187,9 @@ func (ma *minAggregator) Accumulate(vals []sql.Value) error {
 		}
 	} else {
 		switch vals[0].(type) {
-		case sql.Float64Value, sql.Int64Value:
+		case sql.Float64Value, sql.Int64Value, sql.NumericValue, sql.DateValue, sql.TimeValue,
+			sql.TimestampValue, sql.TimestampTZValue, sql.IntervalValue:
+
 			ma.min = vals[0]
 			ma.nonNull = true
 		}
@@ -164,6 +227,9 @@ func (sa *sumAggregator) add(v2 sql.Value) error {
 		case sql.Float64Value:
 			sa.sum = sql.Float64Value(v1) + v2
 			return nil
+		case sql.NumericValue:
+			sa.sum = sql.NumericFromInt64(int64(v1)).Add(v2)
+			return nil
 		}
 	case sql.Float64Value:
 		switch v2 := v2.(type) {
@@ -173,6 +239,21 @@ func (sa *sumAggregator) add(v2 sql.Value) error {
 		case sql.Float64Value:
 			sa.sum = v1 + v2
 			return nil
+		case sql.NumericValue:
+			sa.sum = v1 + sql.Float64Value(v2.Float64())
+			return nil
+		}
+	case sql.NumericValue:
+		switch v2 := v2.(type) {
+		case sql.Int64Value:
+			sa.sum = v1.Add(sql.NumericFromInt64(int64(v2)))
+			return nil
+		case sql.Float64Value:
+			sa.sum = sql.Float64Value(v1.Float64()) + v2
+			return nil
+		case sql.NumericValue:
+			sa.sum = v1.Add(v2)
+			return nil
 		}
 	default:
 		panic(fmt.Sprintf("sql.Value must be a number: %T: %v", v1, v1))
@@ -185,7 +266,7 @@ func (sa *sumAggregator) Accumulate(vals []sql.Value) error {
 		return sa.add(vals[0])
 	} else {
 		switch vals[0].(type) {
-		case sql.Float64Value, sql.Int64Value:
+		case sql.Float64Value, sql.Int64Value, sql.NumericValue:
 			sa.sum = vals[0]
 			sa.nonNull = true
 		}
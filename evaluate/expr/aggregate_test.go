@@ -190,6 +190,42 @@ func TestAggregate(t *testing.T) {
 			},
 			result: nil,
 		},
+		{
+			maker: makeArrayAggAggregator,
+			rows: [][]sql.Value{
+				{sql.Int64Value(1)},
+				{nil},
+				{sql.Int64Value(3)},
+			},
+			result: sql.ArrayValue{sql.Int64Value(1), nil, sql.Int64Value(3)},
+		},
+		{
+			maker:  makeArrayAggAggregator,
+			rows:   [][]sql.Value{},
+			result: nil,
+		},
+		{
+			maker: Distinct(makeCountAggregator),
+			rows: [][]sql.Value{
+				{sql.Int64Value(1)},
+				{sql.Int64Value(2)},
+				{nil},
+				{sql.Int64Value(1)},
+				{sql.StringValue("1")},
+				{nil},
+			},
+			result: sql.Int64Value(3),
+		},
+		{
+			maker: Distinct(makeSumAggregator),
+			rows: [][]sql.Value{
+				{sql.Int64Value(10)},
+				{sql.Int64Value(20)},
+				{sql.Int64Value(10)},
+				{sql.Int64Value(20)},
+			},
+			result: sql.Int64Value(30),
+		},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,543 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/sql"
+)
+
+// Array is an ARRAY[...] constructor.
+type Array struct {
+	Elems []Expr
+}
+
+func (a *Array) String() string {
+	s := "ARRAY["
+	for i, e := range a.Elems {
+		if i > 0 {
+			s += ", "
+		}
+		s += e.String()
+	}
+	s += "]"
+	return s
+}
+
+func (a *Array) Equal(e Expr) bool {
+	a2, ok := e.(*Array)
+	if !ok || len(a.Elems) != len(a2.Elems) {
+		return false
+	}
+	for i := range a.Elems {
+		if !a.Elems[i].Equal(a2.Elems[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Array) HasRef() bool {
+	for _, e := range a.Elems {
+		if e.HasRef() {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscript is expr[index]; arrays are indexed starting at one.
+type Subscript struct {
+	Expr  Expr
+	Index Expr
+}
+
+func (s *Subscript) String() string {
+	return fmt.Sprintf("%s[%s]", s.Expr, s.Index)
+}
+
+func (s *Subscript) Equal(e Expr) bool {
+	s2, ok := e.(*Subscript)
+	if !ok {
+		return false
+	}
+	return s.Expr.Equal(s2.Expr) && s.Index.Equal(s2.Index)
+}
+
+func (s *Subscript) HasRef() bool {
+	return s.Expr.HasRef() || s.Index.HasRef()
+}
+
+// ArrayCompare is expr op ANY(array) or expr op ALL(array).
+type ArrayCompare struct {
+	Op     SubqueryOp
+	ExprOp Op
+	Expr   Expr
+	Array  Expr
+}
+
+func (ac *ArrayCompare) String() string {
+	if ac.Op == All {
+		return fmt.Sprintf("%s %s ALL(%s)", ac.Expr, ac.ExprOp, ac.Array)
+	}
+	return fmt.Sprintf("%s %s ANY(%s)", ac.Expr, ac.ExprOp, ac.Array)
+}
+
+func (ac *ArrayCompare) Equal(e Expr) bool {
+	ac2, ok := e.(*ArrayCompare)
+	if !ok {
+		return false
+	}
+	return ac.Op == ac2.Op && ac.ExprOp == ac2.ExprOp && ac.Expr.Equal(ac2.Expr) &&
+		ac.Array.Equal(ac2.Array)
+}
+
+func (ac *ArrayCompare) HasRef() bool {
+	return ac.Expr.HasRef() || ac.Array.HasRef()
+}
+
+var (
+	arrayFunc     = &callFunc{fn: arrayCall, name: "array", handleNull: true}
+	subscriptFunc = &callFunc{fn: subscriptCall, name: "subscript"}
+
+	arrayCatFunc      = &callFunc{fn: arrayCatCall, name: "array_cat", handleNull: true}
+	arrayAppendFunc   = &callFunc{fn: arrayAppendCall, name: "array_append", handleNull: true}
+	arrayPrependFunc  = &callFunc{fn: arrayPrependCall, name: "array_prepend", handleNull: true}
+	arrayContainsFunc = &callFunc{fn: arrayContainsCall, name: "array_contains"}
+
+	anyFuncs = map[Op]*callFunc{}
+	allFuncs = map[Op]*callFunc{}
+)
+
+func elementType(ct sql.ColumnType) sql.ColumnType {
+	ct.Array = false
+	ct.NotNull = false
+	return ct
+}
+
+func arrayLiteralType(a sql.ArrayValue) sql.ColumnType {
+	ct := sql.ColumnType{Type: sql.UnknownType}
+	for _, v := range a {
+		if v != nil {
+			ct = literalType(v)
+			break
+		}
+	}
+	ct.Array = true
+	ct.NotNull = true
+	return ct
+}
+
+func compileArray(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, a *Array, agg bool) (sql.CExpr, sql.ColumnType, error) {
+
+	ct := sql.ColumnType{Type: sql.UnknownType}
+	args := make([]sql.CExpr, len(a.Elems))
+	argTypes := make([]sql.ColumnType, len(a.Elems))
+	for i, e := range a.Elems {
+		var err error
+		args[i], argTypes[i], err = compile(ctx, pctx, tx, cctx, e, agg)
+		if err != nil {
+			return nil, ct, err
+		}
+		if argTypes[i].Array {
+			return nil, ct, fmt.Errorf("engine: multidimensional arrays are not supported")
+		}
+	}
+
+	if len(args) > 0 {
+		err := coerceArgs(args, argTypes)
+		if err != nil {
+			return nil, ct, err
+		}
+		ct, err = matchTypes("ARRAY", argTypes...)
+		if err != nil {
+			return nil, ct, err
+		}
+	}
+	ct = elementType(ct)
+	ct.Array = true
+	ct.NotNull = true
+	return &call{arrayFunc, args}, ct, nil
+}
+
+func compileSubscript(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, s *Subscript, agg bool) (sql.CExpr, sql.ColumnType, error) {
+
+	ae, act, err := compile(ctx, pctx, tx, cctx, s.Expr, agg)
+	if err != nil {
+		return nil, act, err
+	}
+	if !act.Array && act.Type != sql.UnknownType {
+		return nil, act, fmt.Errorf("engine: cannot subscript type %s", act.Type)
+	}
+	ie, ict, err := compile(ctx, pctx, tx, cctx, s.Index, agg)
+	if err != nil {
+		return nil, ict, err
+	}
+	if p, ok := s.Index.(Param); ok {
+		evaluate.SetParameterType(pctx, p.Num, intType)
+	} else if ict.Type != sql.IntegerType && ict.Type != sql.UnknownType {
+		return nil, ict, fmt.Errorf("engine: array subscript must be an integer, got %s",
+			ict.Type)
+	}
+	return &call{subscriptFunc, []sql.CExpr{ae, ie}}, elementType(act), nil
+}
+
+func compileArrayCompare(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, ac *ArrayCompare, agg bool) (sql.CExpr, sql.ColumnType, error) {
+
+	var cf *callFunc
+	if ac.Op == All {
+		cf = allFuncs[ac.ExprOp]
+	} else {
+		cf = anyFuncs[ac.ExprOp]
+	}
+	if cf == nil {
+		return nil, boolType, fmt.Errorf("engine: unexpected operator for array: %s", ac.ExprOp)
+	}
+
+	ee, ect, err := compile(ctx, pctx, tx, cctx, ac.Expr, agg)
+	if err != nil {
+		return nil, boolType, err
+	}
+	ae, act, err := compile(ctx, pctx, tx, cctx, ac.Array, agg)
+	if err != nil {
+		return nil, boolType, err
+	}
+
+	if !act.Array && act.Type == sql.StringType && ect.Type != sql.UnknownType {
+		at := elementType(ect)
+		at.Array = true
+		ae, act, err = coerceArrayLiteral(ae, act, at)
+		if err != nil {
+			return nil, boolType, err
+		}
+	}
+	if !act.Array && act.Type != sql.UnknownType {
+		return nil, boolType, fmt.Errorf("engine: %s: want array got %s", ac, act.Type)
+	}
+	if act.Array && isCoercedType(act.Type) && ect.Type == sql.StringType {
+		ee, ect, err = coerceLiteral(ee, ect, act.Type)
+		if err != nil {
+			return nil, boolType, err
+		}
+	}
+	if p, ok := ac.Expr.(Param); ok && act.Array {
+		evaluate.SetParameterType(pctx, p.Num, elementType(act))
+	}
+	return &call{cf, []sql.CExpr{ee, ae}}, boolType, nil
+}
+
+// coerceArrayLiteral converts a string literal, in the text format of an array, to an array
+// of type act.
+func coerceArrayLiteral(ce sql.CExpr, ct sql.ColumnType, act sql.ColumnType) (sql.CExpr,
+	sql.ColumnType, error) {
+
+	l, ok := ce.(*Literal)
+	if !ok {
+		return ce, ct, nil
+	}
+	s, ok := l.Value.(sql.StringValue)
+	if !ok {
+		return ce, ct, nil
+	}
+
+	v, err := sql.ConvertArray(act, s)
+	if err != nil {
+		return nil, ct, fmt.Errorf("engine: %s", err)
+	}
+	act.NotNull = true
+	return &Literal{v}, act, nil
+}
+
+// coerceArrayOperands converts a string literal operand of a binary operator to an array when
+// the other operand is an array.
+func coerceArrayOperands(a1 sql.CExpr, ct1 sql.ColumnType, a2 sql.CExpr,
+	ct2 sql.ColumnType) (sql.CExpr, sql.ColumnType, sql.CExpr, sql.ColumnType, error) {
+
+	var err error
+	if ct1.Array && !ct2.Array && ct2.Type == sql.StringType {
+		a2, ct2, err = coerceArrayLiteral(a2, ct2, ct1)
+	} else if ct2.Array && !ct1.Array && ct1.Type == sql.StringType {
+		a1, ct1, err = coerceArrayLiteral(a1, ct1, ct2)
+	}
+	return a1, ct1, a2, ct2, err
+}
+
+// compileArrayOp compiles || and @> when either operand is an array: || concatenates two
+// arrays or adds an element to the start or end of an array, and @> is true if the first array
+// contains every element of the second array.
+func compileArrayOp(op Op, a1 sql.CExpr, ct1 sql.ColumnType, a2 sql.CExpr,
+	ct2 sql.ColumnType) (sql.CExpr, sql.ColumnType, error) {
+
+	a1, ct1, a2, ct2, err := coerceArrayOperands(a1, ct1, a2, ct2)
+	if err != nil {
+		return nil, ct1, err
+	}
+	if ct1.Type == sql.UnknownType && !ct1.Array {
+		ct1.Array = ct2.Array
+	}
+	if ct2.Type == sql.UnknownType && !ct2.Array {
+		ct2.Array = ct1.Array
+	}
+
+	if op == JSONContainsOp {
+		if !ct1.Array || !ct2.Array {
+			return nil, boolType, fmt.Errorf("engine: %s: want arrays got %s and %s", op,
+				arrayTypeString(ct1), arrayTypeString(ct2))
+		}
+		_, err = matchTypes(op.String(), elementType(ct1), elementType(ct2))
+		if err != nil {
+			return nil, boolType, err
+		}
+		return &call{arrayContainsFunc, []sql.CExpr{a1, a2}}, boolType, nil
+	}
+
+	ct, err := matchTypes(op.String(), elementType(ct1), elementType(ct2))
+	if err != nil {
+		return nil, ct, err
+	}
+	ct = elementType(ct)
+	ct.Array = true
+
+	cf := arrayCatFunc
+	if !ct1.Array {
+		if isCoercedType(ct2.Type) && ct1.Type == sql.StringType {
+			a1, _, err = coerceLiteral(a1, ct1, ct2.Type)
+		}
+		cf = arrayPrependFunc
+	} else if !ct2.Array {
+		if isCoercedType(ct1.Type) && ct2.Type == sql.StringType {
+			a2, _, err = coerceLiteral(a2, ct2, ct1.Type)
+		}
+		cf = arrayAppendFunc
+	}
+	if err != nil {
+		return nil, ct, err
+	}
+	return &call{cf, []sql.CExpr{a1, a2}}, ct, nil
+}
+
+func arrayTypeString(ct sql.ColumnType) string {
+	if ct.Array {
+		return ct.Type.String() + "[]"
+	}
+	return ct.Type.String()
+}
+
+func arrayCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	a := make(sql.ArrayValue, len(args))
+	copy(a, args)
+	return a, nil
+}
+
+func subscriptCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	a, ok := args[0].(sql.ArrayValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: subscript: want array got %v", args[0])
+	}
+	idx, ok := args[1].(sql.Int64Value)
+	if !ok {
+		return nil, fmt.Errorf("engine: subscript: want integer got %v", args[1])
+	}
+	if idx < 1 || int64(idx) > int64(len(a)) {
+		return nil, nil
+	}
+	return a[idx-1], nil
+}
+
+func arrayCatCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if args[0] == nil {
+		return args[1], nil
+	} else if args[1] == nil {
+		return args[0], nil
+	}
+	a0, ok := args[0].(sql.ArrayValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: ||: want array got %v", args[0])
+	}
+	a1, ok := args[1].(sql.ArrayValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: ||: want array got %v", args[1])
+	}
+	a := make(sql.ArrayValue, 0, len(a0)+len(a1))
+	a = append(a, a0...)
+	return append(a, a1...), nil
+}
+
+func arrayAppendCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	var a0 sql.ArrayValue
+	if args[0] != nil {
+		var ok bool
+		a0, ok = args[0].(sql.ArrayValue)
+		if !ok {
+			return nil, fmt.Errorf("engine: ||: want array got %v", args[0])
+		}
+	}
+	a := make(sql.ArrayValue, 0, len(a0)+1)
+	a = append(a, a0...)
+	return append(a, args[1]), nil
+}
+
+func arrayPrependCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	var a1 sql.ArrayValue
+	if args[1] != nil {
+		var ok bool
+		a1, ok = args[1].(sql.ArrayValue)
+		if !ok {
+			return nil, fmt.Errorf("engine: ||: want array got %v", args[1])
+		}
+	}
+	a := make(sql.ArrayValue, 0, len(a1)+1)
+	a = append(a, args[0])
+	return append(a, a1...), nil
+}
+
+// arrayContainsCall returns true if every element of the second array is equal to some element
+// of the first array; null elements are never equal.
+func arrayContainsCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	a0, ok := args[0].(sql.ArrayValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: @>: want array got %v", args[0])
+	}
+	a1, ok := args[1].(sql.ArrayValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: @>: want array got %v", args[1])
+	}
+
+	for _, v1 := range a1 {
+		if v1 == nil {
+			return sql.BoolValue(false), nil
+		}
+		found := false
+		for _, v0 := range a0 {
+			if v0 == nil {
+				continue
+			}
+			cmp, err := v0.Compare(v1)
+			if err != nil {
+				return nil, err
+			}
+			if cmp == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return sql.BoolValue(false), nil
+		}
+	}
+	return sql.BoolValue(true), nil
+}
+
+func addArrayCompareFuncs(op Op, cf *callFunc) {
+	anyFuncs[op] = &callFunc{fn: makeArrayCompareCall(cf, false), typ: boolType, minArgs: 2,
+		maxArgs: 2, name: "any " + cf.name, handleNull: true}
+	allFuncs[op] = &callFunc{fn: makeArrayCompareCall(cf, true), typ: boolType, minArgs: 2,
+		maxArgs: 2, name: "all " + cf.name, handleNull: true}
+
+	for _, acf := range []*callFunc{anyFuncs[op], allFuncs[op]} {
+		if _, ok := funcs[acf.name]; ok {
+			panic(fmt.Sprintf("duplicate function name: %s", acf.name))
+		}
+		funcs[acf.name] = acf
+	}
+}
+
+// makeArrayCompareCall returns a function which compares a value to each element of an array:
+// for ANY, the result is true if any comparison is true; for ALL, the result is false if any
+// comparison is false. Otherwise, the result is NULL if any comparison is NULL.
+func makeArrayCompareCall(cf *callFunc, all bool) func(ctx sql.EvalContext,
+	args []sql.Value) (sql.Value, error) {
+
+	return func(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+		if args[1] == nil {
+			return nil, nil
+		}
+		a, ok := args[1].(sql.ArrayValue)
+		if !ok {
+			return nil, fmt.Errorf("engine: %s: want array got %v", cf.name, args[1])
+		}
+		if len(a) == 0 {
+			return sql.BoolValue(all), nil
+		} else if args[0] == nil {
+			return nil, nil
+		}
+
+		null := false
+		for _, e := range a {
+			if e == nil {
+				null = true
+				continue
+			}
+			v, err := cf.fn(ctx, []sql.Value{args[0], e})
+			if err != nil {
+				return nil, err
+			}
+			b, ok := v.(sql.BoolValue)
+			if !ok {
+				null = true
+			} else if bool(b) != all {
+				return b, nil
+			}
+		}
+		if null {
+			return nil, nil
+		}
+		return sql.BoolValue(all), nil
+	}
+}
+
+func arrayLengthCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	a, ok := args[0].(sql.ArrayValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: array_length: want array got %v", args[0])
+	}
+	dim, ok := args[1].(sql.Int64Value)
+	if !ok {
+		return nil, fmt.Errorf("engine: array_length: want integer got %v", args[1])
+	}
+	if dim != 1 || len(a) == 0 {
+		return nil, nil
+	}
+	return sql.Int64Value(len(a)), nil
+}
+
+func arrayLengthType(nam string, args []sql.ColumnType) (sql.ColumnType, error) {
+	if !args[0].Array && args[0].Type != sql.UnknownType {
+		return intType, fmt.Errorf("engine: %s: want array got %s", nam, args[0].Type)
+	}
+	return intType, nil
+}
+
+func arrayAggType(nam string, args []sql.ColumnType) (sql.ColumnType, error) {
+	if args[0].Array {
+		return args[0], fmt.Errorf("engine: %s: multidimensional arrays are not supported",
+			nam)
+	}
+	ct := elementType(args[0])
+	ct.Array = true
+	return ct, nil
+}
+
+type arrayAggAggregator struct {
+	elems sql.ArrayValue
+}
+
+func (aa *arrayAggAggregator) Accumulate(vals []sql.Value) error {
+	aa.elems = append(aa.elems, vals[0])
+	return nil
+}
+
+func (aa *arrayAggAggregator) Total() (sql.Value, error) {
+	if len(aa.elems) > 0 {
+		return aa.elems, nil
+	}
+	return nil, nil
+}
+
+func makeArrayAggAggregator() Aggregator {
+	return &arrayAggAggregator{}
+}
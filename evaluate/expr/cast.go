@@ -0,0 +1,443 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/sql"
+)
+
+type Cast struct {
+	Expr Expr
+	Type sql.ColumnType
+}
+
+func typeName(ct sql.ColumnType) string {
+	return sql.ColumnDataType(ct)
+}
+
+func (c *Cast) String() string {
+	return fmt.Sprintf("CAST(%s AS %s)", c.Expr, typeName(c.Type))
+}
+
+func (c *Cast) Equal(e Expr) bool {
+	c2, ok := e.(*Cast)
+	if !ok {
+		return false
+	}
+	return c.Type.Type == c2.Type.Type && c.Type.Size == c2.Type.Size &&
+		c.Type.Fixed == c2.Type.Fixed && c.Type.Scale == c2.Type.Scale &&
+		c.Type.Array == c2.Type.Array && c.Expr.Equal(c2.Expr)
+}
+
+func (c *Cast) HasRef() bool {
+	return c.Expr.HasRef()
+}
+
+var casts = map[sql.DataType][]sql.DataType{
+	sql.BooleanType: {sql.IntegerType, sql.StringType},
+	sql.StringType: {sql.BooleanType, sql.BytesType, sql.FloatType, sql.IntegerType,
+		sql.StringType, sql.DateType, sql.TimeType, sql.TimestampType, sql.TimestampTZType,
+		sql.IntervalType, sql.NumericType, sql.JSONType, sql.UUIDType, sql.JSONBType},
+	sql.BytesType: {sql.BytesType, sql.StringType, sql.UUIDType},
+	sql.FloatType: {sql.FloatType, sql.IntegerType, sql.StringType, sql.NumericType},
+	sql.IntegerType: {sql.BooleanType, sql.FloatType, sql.IntegerType, sql.StringType,
+		sql.NumericType},
+	sql.DateType: {sql.DateType, sql.StringType, sql.TimestampType, sql.TimestampTZType},
+	sql.TimeType: {sql.TimeType, sql.StringType, sql.IntervalType},
+	sql.TimestampType: {sql.DateType, sql.StringType, sql.TimeType, sql.TimestampType,
+		sql.TimestampTZType},
+	sql.TimestampTZType: {sql.DateType, sql.StringType, sql.TimeType, sql.TimestampType,
+		sql.TimestampTZType},
+	sql.IntervalType: {sql.IntervalType, sql.StringType, sql.TimeType},
+	sql.NumericType:  {sql.FloatType, sql.IntegerType, sql.StringType, sql.NumericType},
+	sql.JSONType: {sql.BooleanType, sql.FloatType, sql.IntegerType, sql.StringType,
+		sql.NumericType, sql.JSONType, sql.JSONBType},
+	sql.UUIDType: {sql.BytesType, sql.StringType, sql.UUIDType},
+	sql.JSONBType: {sql.BooleanType, sql.FloatType, sql.IntegerType, sql.StringType,
+		sql.NumericType, sql.JSONType, sql.JSONBType},
+}
+
+func canCast(from, to sql.DataType) bool {
+	if from == sql.UnknownType || from == to {
+		return true
+	}
+	for _, dt := range casts[from] {
+		if dt == to {
+			return true
+		}
+	}
+	return false
+}
+
+// canCastColumn is canCast extended to arrays: an array may be cast to an array of a type that
+// its elements can be cast to or to a string, and a string may be cast to an array.
+func canCastColumn(from, to sql.ColumnType) bool {
+	if from.Type == sql.UnknownType {
+		return true
+	} else if from.Array {
+		if to.Array {
+			return canCast(from.Type, to.Type)
+		}
+		return to.Type == sql.StringType
+	} else if to.Array {
+		return from.Type == sql.StringType
+	}
+	return canCast(from.Type, to.Type)
+}
+
+func compileCast(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, c *Cast, agg bool) (sql.CExpr, sql.ColumnType, error) {
+
+	ce, ct, err := compile(ctx, pctx, tx, cctx, c.Expr, agg)
+	if err != nil {
+		return nil, ct, err
+	}
+	if p, ok := c.Expr.(Param); ok {
+		evaluate.SetParameterType(pctx, p.Num, c.Type)
+	}
+	if !canCastColumn(ct, c.Type) {
+		from := ct.Type.String()
+		if ct.Array {
+			from = typeName(ct)
+		}
+		return nil, ct, fmt.Errorf("engine: cannot cast type %s to %s", from, typeName(c.Type))
+	}
+
+	rct := c.Type
+	rct.NotNull = ct.NotNull
+	return &castExpr{expr: ce, ct: c.Type}, rct, nil
+}
+
+// castExpr converts the value of expr to the column type ct: the value must be valid for the
+// type, and fit in the size of the type, except that strings are truncated.
+type castExpr struct {
+	expr sql.CExpr
+	ct   sql.ColumnType
+}
+
+func (ce *castExpr) String() string {
+	return fmt.Sprintf("CAST(%s AS %s)", ce.expr, typeName(ce.ct))
+}
+
+func (ce *castExpr) Eval(ctx context.Context, tx sql.Transaction,
+	ectx sql.EvalContext) (sql.Value, error) {
+
+	val, err := ce.expr.Eval(ctx, tx, ectx)
+	if err != nil || val == nil {
+		return nil, err
+	}
+	return castValue(ce.ct, val)
+}
+
+func intRange(size uint32) (int64, int64) {
+	switch size {
+	case 2:
+		return math.MinInt16, math.MaxInt16
+	case 4:
+		return math.MinInt32, math.MaxInt32
+	}
+	return math.MinInt64, math.MaxInt64
+}
+
+func castToInt(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	var i int64
+	switch v := v.(type) {
+	case sql.BoolValue:
+		if v {
+			i = 1
+		}
+	case sql.Int64Value:
+		i = int64(v)
+	case sql.Float64Value:
+		f := math.Round(float64(v))
+		if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, fmt.Errorf("engine: value out of range for type %s: %s", typeName(ct),
+				v)
+		}
+		i = int64(f)
+	case sql.NumericValue:
+		var ok bool
+		i, ok = v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("engine: value out of range for type %s: %s", typeName(ct),
+				v)
+		}
+	case sql.StringValue:
+		var err error
+		i, err = strconv.ParseInt(strings.TrimSpace(string(v)), 10, 64)
+		if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+			return nil, fmt.Errorf("engine: value out of range for type %s: %s", typeName(ct),
+				v)
+		} else if err != nil {
+			return nil, fmt.Errorf("engine: invalid input for type %s: %s", typeName(ct), v)
+		}
+	default:
+		return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+	}
+
+	min, max := intRange(ct.Size)
+	if i < min || i > max {
+		return nil, fmt.Errorf("engine: value out of range for type %s: %s", typeName(ct), v)
+	}
+	return sql.Int64Value(i), nil
+}
+
+func castToFloat(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	var f float64
+	switch v := v.(type) {
+	case sql.Int64Value:
+		f = float64(v)
+	case sql.Float64Value:
+		f = float64(v)
+	case sql.NumericValue:
+		f = v.Float64()
+	case sql.StringValue:
+		var err error
+		f, err = strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+		if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+			return nil, fmt.Errorf("engine: value out of range for type %s: %s", typeName(ct),
+				v)
+		} else if err != nil {
+			return nil, fmt.Errorf("engine: invalid input for type %s: %s", typeName(ct), v)
+		}
+	default:
+		return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+	}
+
+	if ct.Size == 4 {
+		if !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+			return nil, fmt.Errorf("engine: value out of range for type %s: %s", typeName(ct),
+				v)
+		}
+		f = float64(float32(f))
+	}
+	return sql.Float64Value(f), nil
+}
+
+// truncateString returns s truncated to size characters: unlike assigning a value to a column,
+// an explicit cast to a character type silently truncates the value.
+func truncateString(ct sql.ColumnType, s string) string {
+	if ct.Size == 0 || ct.Size == sql.MaxColumnSize ||
+		uint32(utf8.RuneCountInString(s)) <= ct.Size {
+
+		return s
+	}
+	return string([]rune(s)[:ct.Size])
+}
+
+func castToString(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	var s string
+	switch v := v.(type) {
+	case sql.BoolValue:
+		if v {
+			s = "true"
+		} else {
+			s = "false"
+		}
+	case sql.Int64Value:
+		s = strconv.FormatInt(int64(v), 10)
+	case sql.Float64Value:
+		s = strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case sql.StringValue:
+		s = string(v)
+	case sql.BytesValue:
+		if !utf8.Valid([]byte(v)) {
+			return nil, fmt.Errorf("engine: invalid input for type %s: %s", typeName(ct), v)
+		}
+		s = string(v)
+	case sql.DateValue, sql.TimeValue, sql.TimestampValue, sql.TimestampTZValue,
+		sql.IntervalValue, sql.NumericValue, sql.JSONValue, sql.UUIDValue, sql.ArrayValue:
+
+		s = v.String()
+	default:
+		return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+	}
+
+	return sql.StringValue(truncateString(ct, s)), nil
+}
+
+func castToBytes(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	var b []byte
+	switch v := v.(type) {
+	case sql.StringValue:
+		b = []byte(v)
+	case sql.BytesValue:
+		b = []byte(v)
+	case sql.UUIDValue:
+		b = v[:]
+	default:
+		return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+	}
+
+	if ct.Size > 0 && ct.Size < sql.MaxColumnSize && uint32(len(b)) > ct.Size {
+		return nil, fmt.Errorf("engine: value too long for type %s: %s", typeName(ct), v)
+	}
+	return sql.BytesValue(b), nil
+}
+
+func castToNumeric(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	var n sql.NumericValue
+	switch v := v.(type) {
+	case sql.Int64Value:
+		n = sql.NumericFromInt64(int64(v))
+	case sql.Float64Value, sql.StringValue:
+		nv, err := sql.ConvertNumeric(v)
+		if err != nil {
+			return nil, fmt.Errorf("engine: invalid input for type %s: %s", typeName(ct), v)
+		}
+		n = nv.(sql.NumericValue)
+	case sql.NumericValue:
+		n = v
+	default:
+		return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+	}
+
+	n, err := n.Fit(int(ct.Size), int(ct.Scale))
+	if err != nil {
+		return nil, fmt.Errorf("engine: %s", err)
+	}
+	return n, nil
+}
+
+func castToBool(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	switch v := v.(type) {
+	case sql.BoolValue:
+		return v, nil
+	case sql.Int64Value:
+		return sql.BoolValue(v != 0), nil
+	case sql.StringValue:
+		switch strings.ToLower(strings.TrimSpace(string(v))) {
+		case "t", "true", "y", "yes", "on", "1":
+			return sql.BoolValue(true), nil
+		case "f", "false", "n", "no", "off", "0":
+			return sql.BoolValue(false), nil
+		}
+		return nil, fmt.Errorf("engine: invalid input for type %s: %s", typeName(ct), v)
+	}
+	return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+}
+
+func castToJSON(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	switch v := v.(type) {
+	case sql.JSONValue:
+		if ct.Type == sql.JSONBType {
+			return v.JSONB(), nil
+		}
+		return v, nil
+	case sql.StringValue:
+		var j sql.JSONValue
+		var err error
+		if ct.Type == sql.JSONBType {
+			j, err = sql.ParseJSON(string(v))
+		} else {
+			j, err = sql.ParseJSONText(string(v))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("engine: %s", err)
+		}
+		return j, nil
+	}
+	return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+}
+
+func castToUUID(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	switch v := v.(type) {
+	case sql.UUIDValue:
+		return v, nil
+	case sql.StringValue, sql.BytesValue:
+		u, err := sql.ConvertUUID(v)
+		if err != nil {
+			return nil, fmt.Errorf("engine: invalid input for type %s: %s", typeName(ct), v)
+		}
+		return u, nil
+	}
+	return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+}
+
+func castToArray(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	var a sql.ArrayValue
+	switch v := v.(type) {
+	case sql.ArrayValue:
+		a = v
+	case sql.StringValue:
+		var err error
+		a, err = sql.ParseArray(string(v))
+		if err != nil {
+			return nil, fmt.Errorf("engine: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+	}
+
+	ect := ct
+	ect.Array = false
+	ra := make(sql.ArrayValue, len(a))
+	for i, e := range a {
+		if e != nil {
+			var err error
+			ra[i], err = castValue(ect, e)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ra, nil
+}
+
+// jsonScalar returns the value of a json number or boolean so that it can be cast to a
+// numeric or boolean type.
+func jsonScalar(ct sql.ColumnType, j sql.JSONValue) (sql.Value, error) {
+	switch j.Kind() {
+	case sql.JSONNumber:
+		return j.Number(), nil
+	case sql.JSONBoolean:
+		return sql.BoolValue(j.Bool()), nil
+	}
+	return nil, fmt.Errorf("engine: cannot cast json %s to %s", j.Kind(), typeName(ct))
+}
+
+// CastValue converts v to the column type ct, as CAST(v AS ct) would.
+func CastValue(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	return castValue(ct, v)
+}
+
+func castValue(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	if ct.Array {
+		return castToArray(ct, v)
+	}
+	if j, ok := v.(sql.JSONValue); ok && ct.Type != sql.StringType && !isJSONType(ct.Type) {
+		var err error
+		v, err = jsonScalar(ct, j)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch ct.Type {
+	case sql.BooleanType:
+		return castToBool(ct, v)
+	case sql.StringType:
+		return castToString(ct, v)
+	case sql.BytesType:
+		return castToBytes(ct, v)
+	case sql.FloatType:
+		return castToFloat(ct, v)
+	case sql.IntegerType:
+		return castToInt(ct, v)
+	case sql.DateType, sql.TimeType, sql.TimestampType, sql.TimestampTZType, sql.IntervalType:
+		return castToTemporal(ct, v)
+	case sql.NumericType:
+		return castToNumeric(ct, v)
+	case sql.JSONType, sql.JSONBType:
+		return castToJSON(ct, v)
+	case sql.UUIDType:
+		return castToUUID(ct, v)
+	}
+	panic(fmt.Sprintf("unexpected column type; got %#v", ct))
+}
@@ -12,7 +12,7 @@ import (
 
 type aggregatorContext interface {
 	MaybeRefExpr(e Expr) (int, sql.ColumnType, bool)
-	CompileAggregator(maker MakeAggregator, args []sql.CExpr) int
+	CompileAggregator(maker MakeAggregator, args []sql.CExpr, distinct bool) int
 	ArgContext() sql.CompileContext
 }
 
@@ -36,6 +36,40 @@ func CompileAggregator(ctx context.Context, pctx evaluate.PlanContext, tx sql.Tr
 	return compile(ctx, pctx, tx, cctx, e, true)
 }
 
+func literalType(v sql.Value) sql.ColumnType {
+	switch v := v.(type) {
+	case sql.BoolValue:
+		return sql.ColumnType{Type: sql.BooleanType, NotNull: true}
+	case sql.Float64Value:
+		return sql.ColumnType{Type: sql.FloatType, Size: 8, NotNull: true}
+	case sql.Int64Value:
+		return sql.ColumnType{Type: sql.IntegerType, Size: 8, NotNull: true}
+	case sql.StringValue:
+		return sql.ColumnType{Type: sql.StringType, NotNull: true}
+	case sql.BytesValue:
+		return sql.ColumnType{Type: sql.BytesType, NotNull: true}
+	case sql.NumericValue:
+		return sql.ColumnType{Type: sql.NumericType, NotNull: true}
+	case sql.JSONValue:
+		if _, ok := v.Text(); ok {
+			return sql.ColumnType{Type: sql.JSONType, NotNull: true}
+		}
+		return sql.ColumnType{Type: sql.JSONBType, NotNull: true}
+	case sql.UUIDValue:
+		return sql.ColumnType{Type: sql.UUIDType, Size: 16, NotNull: true}
+	case sql.DateValue, sql.TimeValue, sql.TimestampValue, sql.TimestampTZValue,
+		sql.IntervalValue:
+
+		ct := temporalType(valueDataType(v))
+		ct.NotNull = true
+		return ct
+	case sql.ArrayValue:
+		return arrayLiteralType(v)
+	default:
+		panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", v, v))
+	}
+}
+
 func compile(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 	cctx sql.CompileContext, e Expr, agg bool) (sql.CExpr, sql.ColumnType, error) {
 
@@ -53,20 +87,7 @@ func compile(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 			return e, sql.ColumnType{Type: sql.UnknownType}, nil
 		}
 
-		switch e.Value.(type) {
-		case sql.BoolValue:
-			return e, sql.ColumnType{Type: sql.BooleanType, NotNull: true}, nil
-		case sql.Float64Value:
-			return e, sql.ColumnType{Type: sql.FloatType, Size: 8, NotNull: true}, nil
-		case sql.Int64Value:
-			return e, sql.ColumnType{Type: sql.IntegerType, Size: 8, NotNull: true}, nil
-		case sql.StringValue:
-			return e, sql.ColumnType{Type: sql.StringType, NotNull: true}, nil
-		case sql.BytesValue:
-			return e, sql.ColumnType{Type: sql.BytesType, NotNull: true}, nil
-		default:
-			panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", e.Value, e.Value))
-		}
+		return e, literalType(e.Value), nil
 	case *Unary:
 		if e.Op == NoOp {
 			return compile(ctx, pctx, tx, cctx, e.Expr, agg)
@@ -92,6 +113,29 @@ func compile(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 		if err != nil {
 			return nil, ct, err
 		}
+		if p, ok := e.Left.(Param); ok {
+			evaluate.SetParameterType(pctx, p.Num, ct2)
+		}
+		if p, ok := e.Right.(Param); ok {
+			evaluate.SetParameterType(pctx, p.Num, ct1)
+		}
+		if _, ok := matchOps[e.Op]; ok {
+			return compileMatch(e.Op, a1, ct1, a2, ct2)
+		} else if (e.Op == ConcatOp || e.Op == JSONContainsOp) && (ct1.Array || ct2.Array) {
+			return compileArrayOp(e.Op, a1, ct1, a2, ct2)
+		}
+		a1, ct1, a2, ct2, err = coerceJSONOperands(e.Op, a1, ct1, a2, ct2)
+		if err != nil {
+			return nil, ct, err
+		}
+		a1, ct1, a2, ct2, err = coerceOperands(e.Op, a1, ct1, a2, ct2)
+		if err != nil {
+			return nil, ct, err
+		}
+		a1, ct1, a2, ct2, err = coerceArrayOperands(a1, ct1, a2, ct2)
+		if err != nil {
+			return nil, ct, err
+		}
 		if cf.tfn != nil {
 			ct = cf.tfn([]sql.ColumnType{ct1, ct2})
 		} else {
@@ -123,7 +167,19 @@ func compile(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 					cf.maxArgs, len(e.Args))
 		}
 
+		if e.Over != nil {
+			return compileWindow(ctx, pctx, tx, cctx, e, cf)
+		} else if cf.windowFunc != nil {
+			return nil, ct,
+				fmt.Errorf("engine: window function \"%s\" requires an OVER clause", e.Name)
+		}
+
 		var actx sql.CompileContext
+		if e.Distinct && cf.makeAggregator == nil {
+			return nil, ct,
+				fmt.Errorf("engine: DISTINCT specified, but \"%s\" is not an aggregate function",
+					e.Name)
+		}
 		if cf.makeAggregator != nil {
 			if !agg {
 				return nil, ct, &ContextError{e.Name}
@@ -142,7 +198,31 @@ func compile(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 				return nil, ct, err
 			}
 		}
-		if cf.tfn != nil {
+		if cf.sequence {
+			var err error
+			args[0], err = resolveSequenceName(pctx, args[0])
+			if err != nil {
+				return nil, ct, err
+			}
+		}
+		if cf.typeCheck != nil {
+			err := coerceArgs(args, argTypes)
+			if err != nil {
+				return nil, ct, err
+			}
+			ct, err = cf.typeCheck(cf.name, argTypes)
+			if err != nil {
+				return nil, ct, err
+			}
+			if mct, err := matchTypes(cf.name, argTypes...); err == nil {
+				setParameterTypes(pctx, e.Args, mct)
+			}
+			if cf.castArgs {
+				for adx := range args {
+					args[adx] = castResult(args[adx], argTypes[adx], ct)
+				}
+			}
+		} else if cf.tfn != nil {
 			ct = cf.tfn(argTypes)
 		} else {
 			ct = cf.typ
@@ -151,9 +231,20 @@ func compile(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 		if cf.makeAggregator == nil {
 			return &call{cf, args}, ct, nil
 		} else {
-			idx := cctx.(aggregatorContext).CompileAggregator(cf.makeAggregator, args)
+			idx := cctx.(aggregatorContext).CompileAggregator(cf.makeAggregator, args,
+				e.Distinct)
 			return &colRef{idx: idx, ref: Ref{e.Name}}, ct, nil
 		}
+	case *Case:
+		return compileCase(ctx, pctx, tx, cctx, e, agg)
+	case *Cast:
+		return compileCast(ctx, pctx, tx, cctx, e, agg)
+	case *Array:
+		return compileArray(ctx, pctx, tx, cctx, e, agg)
+	case *Subscript:
+		return compileSubscript(ctx, pctx, tx, cctx, e, agg)
+	case *ArrayCompare:
+		return compileArrayCompare(ctx, pctx, tx, cctx, e, agg)
 	case Subquery:
 		if pctx == nil {
 			return nil, ct, fmt.Errorf("engine: expression statements not allowed here: %s", e.Stmt)
@@ -210,72 +301,175 @@ func compile(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 	}
 }
 
+// setParameterTypes sets the type of each of exprs which is a parameter to ct.
+func setParameterTypes(pctx evaluate.PlanContext, exprs []Expr, ct sql.ColumnType) {
+	ct.NotNull = false
+	for _, e := range exprs {
+		if p, ok := e.(Param); ok {
+			evaluate.SetParameterType(pctx, p.Num, ct)
+		}
+	}
+}
+
 type callFunc struct {
-	fn             func(ectx sql.EvalContext, args []sql.Value) (sql.Value, error)
+	fn     func(ectx sql.EvalContext, args []sql.Value) (sql.Value, error)
+	lazyFn func(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+		args []sql.CExpr) (sql.Value, error)
 	tfn            func(args []sql.ColumnType) sql.ColumnType
+	typeCheck      func(nam string, args []sql.ColumnType) (sql.ColumnType, error)
 	typ            sql.ColumnType
 	minArgs        int16
 	maxArgs        int16
 	name           string
 	handleNull     bool
+	volatile       bool
+	sequence       bool // The first argument is the name of a sequence.
+	castArgs       bool // The arguments are cast to the type of the result.
 	makeAggregator MakeAggregator
+	windowFunc     WindowFunc
 }
 
 var (
-	intType    = sql.ColumnType{Type: sql.IntegerType, Size: 8}
-	boolType   = sql.ColumnType{Type: sql.BooleanType}
-	stringType = sql.ColumnType{Type: sql.StringType}
+	intType     = sql.ColumnType{Type: sql.IntegerType, Size: 8}
+	boolType    = sql.ColumnType{Type: sql.BooleanType}
+	stringType  = sql.ColumnType{Type: sql.StringType}
+	numericType = sql.ColumnType{Type: sql.NumericType}
+	uuidType    = sql.ColumnType{Type: sql.UUIDType, Size: 16}
 
 	opFuncs = map[Op]*callFunc{
-		AddOp:       {fn: addCall, tfn: numType, minArgs: 2, maxArgs: 2},
+		AddOp:       {fn: addCall, tfn: addType, minArgs: 2, maxArgs: 2},
 		AndOp:       {fn: andCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		BinaryAndOp: {fn: binaryAndCall, typ: intType, minArgs: 2, maxArgs: 2},
 		BinaryOrOp:  {fn: binaryOrCall, typ: intType, minArgs: 2, maxArgs: 2},
-		ConcatOp: {fn: concatCall, typ: stringType, minArgs: 2, maxArgs: 2,
+		ConcatOp: {fn: concatOpCall, tfn: concatType, minArgs: 2, maxArgs: 2,
 			handleNull: true},
-		DivideOp:       {fn: divideCall, tfn: numType, minArgs: 2, maxArgs: 2},
+		DivideOp:       {fn: divideCall, tfn: intervalNumType, minArgs: 2, maxArgs: 2},
 		EqualOp:        {fn: equalCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		GreaterEqualOp: {fn: greaterEqualCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		GreaterThanOp:  {fn: greaterThanCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		LessEqualOp:    {fn: lessEqualCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		LessThanOp:     {fn: lessThanCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		LShiftOp:       {fn: lShiftCall, typ: intType, minArgs: 2, maxArgs: 2},
-		ModuloOp:       {fn: moduloCall, typ: intType, minArgs: 2, maxArgs: 2},
-		MultiplyOp:     {fn: multiplyCall, tfn: numType, minArgs: 2, maxArgs: 2},
-		NegateOp:       {fn: negateCall, tfn: numType, minArgs: 1, maxArgs: 1},
+		ModuloOp:       {fn: moduloCall, tfn: moduloType, minArgs: 2, maxArgs: 2},
+		MultiplyOp:     {fn: multiplyCall, tfn: intervalNumType, minArgs: 2, maxArgs: 2},
+		NegateOp:       {fn: negateCall, tfn: intervalNumType, minArgs: 1, maxArgs: 1},
 		NotEqualOp:     {fn: notEqualCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		NotOp:          {fn: notCall, typ: boolType, minArgs: 1, maxArgs: 1},
 		OrOp:           {fn: orCall, typ: boolType, minArgs: 2, maxArgs: 2},
 		RShiftOp:       {fn: rShiftCall, typ: intType, minArgs: 2, maxArgs: 2},
-		SubtractOp:     {fn: subtractCall, tfn: numType, minArgs: 2, maxArgs: 2},
+		SubtractOp:     {fn: subtractCall, tfn: subtractType, minArgs: 2, maxArgs: 2},
+
+		JSONContainsOp: {fn: jsonContainsCall, typ: boolType, minArgs: 2, maxArgs: 2},
+		JSONGetOp:      {fn: jsonGetCall, tfn: jsonGetType, minArgs: 2, maxArgs: 2},
+		JSONGetTextOp:  {fn: jsonGetTextCall, typ: stringType, minArgs: 2, maxArgs: 2},
+		JSONHasKeyOp:   {fn: jsonHasKeyCall, typ: boolType, minArgs: 2, maxArgs: 2},
+		JSONPathOp:     {fn: jsonPathCall, tfn: jsonGetType, minArgs: 2, maxArgs: 2},
+		JSONPathTextOp: {fn: jsonPathTextCall, typ: stringType, minArgs: 2, maxArgs: 2},
 	}
 
 	idFuncs = map[sql.Identifier]*callFunc{
 		// Scalar functions
 		sql.ID("abs"): {fn: absCall, tfn: numType, minArgs: 1, maxArgs: 1},
+		sql.ID("array_length"): {fn: arrayLengthCall, typeCheck: arrayLengthType, minArgs: 2,
+			maxArgs: 2},
+		sql.ID("between"): {fn: betweenCall, typeCheck: compareType, minArgs: 3, maxArgs: 3,
+			handleNull: true},
+		sql.ID("coalesce"): {lazyFn: coalesceCall, typeCheck: commonType, minArgs: 1,
+			maxArgs: math.MaxInt16, castArgs: true},
 		sql.ID("concat"): {fn: concatCall, typ: stringType, minArgs: 2, maxArgs: math.MaxInt16,
 			handleNull: true},
+		sql.ID("current_date"): {lazyFn: currentDateCall, typ: dateType, minArgs: 0,
+			maxArgs: 0, volatile: true},
+		sql.ID("current_timestamp"): {lazyFn: nowCall, typ: timestampTZType, minArgs: 0,
+			maxArgs: 0, volatile: true},
+		sql.ID("currval"): {lazyFn: currValCall, typ: intType, minArgs: 1, maxArgs: 1,
+			volatile: true, sequence: true},
+		sql.ID("date_part"):  {fn: datePartCall, typ: floatType, minArgs: 2, maxArgs: 2},
+		sql.ID("date_trunc"): {fn: dateTruncCall, tfn: dateTruncType, minArgs: 2, maxArgs: 2},
+		sql.ID("gen_random_uuid"): {fn: genRandomUUIDCall, typ: uuidType, minArgs: 0,
+			maxArgs: 0, volatile: true},
+		sql.ID("greatest"): {fn: greatestCall, typeCheck: commonType, minArgs: 1,
+			maxArgs: math.MaxInt16, handleNull: true, castArgs: true},
+		sql.ID("in"): {fn: inCall, typeCheck: compareType, minArgs: 2, maxArgs: math.MaxInt16,
+			handleNull: true},
 		sql.ID("is_null"): {fn: isNull, typ: boolType, minArgs: 1, maxArgs: 1,
 			handleNull: true},
-		sql.ID("unique_rowid"): {fn: uniqueRowIDCall, typ: intType, minArgs: 0, maxArgs: 0},
-		sql.ID("version"):      {fn: versionCall, typ: stringType, minArgs: 0, maxArgs: 0},
+		sql.ID("json_build_object"): {fn: jsonBuildObjectCall, typ: jsonType, minArgs: 0,
+			maxArgs: math.MaxInt16, handleNull: true},
+		sql.ID("json_typeof"): {fn: jsonTypeofCall, typ: stringType, minArgs: 1, maxArgs: 1},
+		sql.ID("jsonb_build_object"): {fn: jsonBuildObjectCall, typ: jsonbType, minArgs: 0,
+			maxArgs: math.MaxInt16, handleNull: true},
+		sql.ID("jsonb_typeof"): {fn: jsonTypeofCall, typ: stringType, minArgs: 1, maxArgs: 1},
+		sql.ID("like_escape"):  {fn: likeEscapeCall, typ: stringType, minArgs: 2, maxArgs: 2},
+		sql.ID("localtimestamp"): {lazyFn: localTimestampCall, typ: timestampType, minArgs: 0,
+			maxArgs: 0, volatile: true},
+		sql.ID("least"): {fn: leastCall, typeCheck: commonType, minArgs: 1,
+			maxArgs: math.MaxInt16, handleNull: true, castArgs: true},
+		sql.ID("nextval"): {lazyFn: nextValCall, typ: intType, minArgs: 1, maxArgs: 1,
+			volatile: true, sequence: true},
+		sql.ID("now"): {lazyFn: nowCall, typ: timestampTZType, minArgs: 0, maxArgs: 0,
+			volatile: true},
+		sql.ID("nullif"): {fn: nullIfCall, typeCheck: nullIfType, minArgs: 2, maxArgs: 2,
+			handleNull: true},
+		sql.ID("setval"): {lazyFn: setValCall, typ: intType, minArgs: 2, maxArgs: 3,
+			volatile: true, sequence: true},
+		sql.ID("unique_rowid"): {fn: uniqueRowIDCall, typ: intType, minArgs: 0, maxArgs: 0,
+			volatile: true},
+		sql.ID("version"): {fn: versionCall, typ: stringType, minArgs: 0, maxArgs: 0},
 
 		// Aggregate functions
+		sql.ID("array_agg"): {typeCheck: arrayAggType, minArgs: 1, maxArgs: 1,
+			makeAggregator: makeArrayAggAggregator},
 		sql.ID("avg"): {tfn: numType, minArgs: 1, maxArgs: 1,
 			makeAggregator: makeAvgAggregator},
 		sql.ID("count"): {typ: intType, minArgs: 1, maxArgs: 1,
 			makeAggregator: makeCountAggregator},
 		sql.ID("count_all"): {typ: intType,
 			minArgs: 0, maxArgs: 0, makeAggregator: makeCountAllAggregator},
-		sql.ID("max"): {tfn: numType, minArgs: 1, maxArgs: 1, makeAggregator: makeMaxAggregator},
-		sql.ID("min"): {tfn: numType, minArgs: 1, maxArgs: 1, makeAggregator: makeMinAggregator},
+		sql.ID("json_agg"): {typ: jsonType, minArgs: 1, maxArgs: 1,
+			makeAggregator: makeJSONAggAggregator},
+		sql.ID("jsonb_agg"): {typ: jsonbType, minArgs: 1, maxArgs: 1,
+			makeAggregator: makeJSONAggAggregator},
+		sql.ID("max"): {tfn: extremeType, minArgs: 1, maxArgs: 1,
+			makeAggregator: makeMaxAggregator},
+		sql.ID("min"): {tfn: extremeType, minArgs: 1, maxArgs: 1,
+			makeAggregator: makeMinAggregator},
 		sql.ID("sum"): {tfn: numType, minArgs: 1, maxArgs: 1, makeAggregator: makeSumAggregator},
+
+		// Window functions
+		sql.ID("dense_rank"): {typ: intType, minArgs: 0, maxArgs: 0,
+			windowFunc: denseRankWindow},
+		sql.ID("first_value"): {tfn: firstArgType, minArgs: 1, maxArgs: 1,
+			windowFunc: firstValueWindow},
+		sql.ID("lag"): {tfn: firstArgType, minArgs: 1, maxArgs: 3, windowFunc: lagWindow},
+		sql.ID("last_value"): {tfn: firstArgType, minArgs: 1, maxArgs: 1,
+			windowFunc: lastValueWindow},
+		sql.ID("lead"):  {tfn: firstArgType, minArgs: 1, maxArgs: 3, windowFunc: leadWindow},
+		sql.ID("ntile"): {typ: intType, minArgs: 1, maxArgs: 1, windowFunc: ntileWindow},
+		sql.ID("rank"):  {typ: intType, minArgs: 0, maxArgs: 0, windowFunc: rankWindow},
+		sql.ID("row_number"): {typ: intType, minArgs: 0, maxArgs: 0,
+			windowFunc: rowNumberWindow},
 	}
 
-	funcs = map[string]*callFunc{}
+	// Used for CASE expressions; these are not callable by name.
+	caseFunc       = &callFunc{lazyFn: caseCall, name: "case"}
+	simpleCaseFunc = &callFunc{lazyFn: simpleCaseCall, name: "simple_case"}
+
+	funcs = map[string]*callFunc{
+		caseFunc.name:       caseFunc,
+		simpleCaseFunc.name: simpleCaseFunc,
+		arrayFunc.name:      arrayFunc,
+		subscriptFunc.name:  subscriptFunc,
+	}
 )
 
 func init() {
+	for op := range matchOps {
+		cf := &callFunc{fn: makeMatchCall(op), typ: boolType, minArgs: 2, maxArgs: 2}
+		opFuncs[op] = cf
+		matchFuncs[cf] = op
+	}
+
 	for op, cf := range opFuncs {
 		if op == NegateOp {
 			cf.name = "negate"
@@ -297,6 +491,10 @@ func init() {
 			panic(fmt.Sprintf("duplicate function name: %s", cf.name))
 		}
 		funcs[cf.name] = cf
+
+		if cf.typ.Type == sql.BooleanType && cf.maxArgs == 2 {
+			addArrayCompareFuncs(op, cf)
+		}
 	}
 
 	for id, cf := range idFuncs {
@@ -17,6 +17,8 @@ func (_ compileCtx) CompileRef(r []sql.Identifier) (int, int, sql.ColumnType, er
 		return 0, 0, sql.ColumnType{Type: sql.FloatType}, nil
 	} else if len(r) == 1 && r[0] == sql.ID("i") {
 		return 1, 0, sql.ColumnType{Type: sql.IntegerType}, nil
+	} else if len(r) == 1 && r[0] == sql.ID("s") {
+		return 2, 0, sql.ColumnType{Type: sql.StringType}, nil
 	}
 	return -1, -1, sql.ColumnType{}, fmt.Errorf("reference %s not found", r)
 }
@@ -31,13 +33,34 @@ func TestCompile(t *testing.T) {
 		{"1 * 2 + 3 / - 4", `"+"("*"(1, 2), "/"(3, negate(4)))`,
 			sql.ColumnType{Type: sql.IntegerType}},
 		{"abs(1 * 2 + 3 / - 4.5)", `abs("+"("*"(1, 2), "/"(3, negate(4.5))))`,
-			sql.ColumnType{Type: sql.FloatType}},
+			sql.ColumnType{Type: sql.NumericType}},
 		{"concat('abc', 123, 45.6, true, null)",
 			"concat('abc', 123, 45.6, " + sql.TrueString + ", " + sql.NullString + ")",
 			sql.ColumnType{Type: sql.StringType}},
 		{"1 + f", `"+"(1, f)`, sql.ColumnType{Type: sql.FloatType}},
-		{"1.2 + i", `"+"(1.2, i)`, sql.ColumnType{Type: sql.FloatType}},
+		{"1.2 + i", `"+"(1.2, i)`, sql.ColumnType{Type: sql.NumericType}},
+		{"1.2 + f", `"+"(1.2, f)`, sql.ColumnType{Type: sql.FloatType}},
 		{"1 + i", `"+"(1, i)`, sql.ColumnType{Type: sql.IntegerType}},
+		{"case when i > 1 then i else f end", `case(">"(i, 1), CAST(i AS DOUBLE), f)`,
+			sql.ColumnType{Type: sql.FloatType}},
+		{"case i when 1 then 'one' when 2 then null end", `simple_case(i, 1, 'one', 2, NULL)`,
+			sql.ColumnType{Type: sql.StringType}},
+		{"coalesce(null, i, 2)", `coalesce(NULL, i, 2)`, sql.ColumnType{Type: sql.IntegerType}},
+		{"nullif(i, 1.5)", `nullif(i, 1.5)`, sql.ColumnType{Type: sql.IntegerType}},
+		{"greatest(i, f, 1)", `greatest(CAST(i AS DOUBLE), f, CAST(1 AS DOUBLE))`,
+			sql.ColumnType{Type: sql.FloatType}},
+		{"least(i, 1)", `least(i, 1)`, sql.ColumnType{Type: sql.IntegerType}},
+		{"i between f and 1", `between(i, f, 1)`, sql.ColumnType{Type: sql.BooleanType}},
+		{"i not in (1, 2.5)", `"NOT"(in(i, 1, 2.5))`, sql.ColumnType{Type: sql.BooleanType}},
+		{"s like 'a%'", "s LIKE 'a%'", sql.ColumnType{Type: sql.BooleanType}},
+		{"s not ilike s escape '!'", "s NOT ILIKE like_escape(s, '!')",
+			sql.ColumnType{Type: sql.BooleanType}},
+		{"s ~* 'ab+c'", "s ~* 'ab+c'", sql.ColumnType{Type: sql.BooleanType}},
+		{"cast(s as int)", "CAST(s AS INT)", sql.ColumnType{Type: sql.IntegerType, Size: 4}},
+		{"i::varchar(8) || s", `"||"(CAST(i AS VARCHAR(8)), s)`,
+			sql.ColumnType{Type: sql.StringType}},
+		{"f::real", "CAST(f AS REAL)", sql.ColumnType{Type: sql.FloatType, Size: 4}},
+		{"null::bool", "CAST(NULL AS BOOL)", sql.ColumnType{Type: sql.BooleanType}},
 	}
 
 	for i, c := range cases {
@@ -55,6 +78,8 @@ func TestCompile(t *testing.T) {
 		}
 		if ct.Type != c.typ.Type {
 			t.Errorf("expr.Compile(%q) got %s want %s", c.s, ct.Type, c.typ.Type)
+		} else if c.typ.Size != 0 && ct.Size != c.typ.Size {
+			t.Errorf("expr.Compile(%q) got size %d want %d", c.s, ct.Size, c.typ.Size)
 		}
 	}
 
@@ -64,6 +89,33 @@ func TestCompile(t *testing.T) {
 		"abs(1, 2)",
 		"concat()",
 		"concat('abc')",
+		"rank()",
+		"lag(i, 1, 2, 3) over ()",
+		"row_number() over ()",
+		"abs(i) over ()",
+		"case when i then 1 end",
+		"case when i > 1 then 1 else 'one' end",
+		"case i when 'one' then 1 end",
+		"coalesce()",
+		"coalesce(i, 'abc')",
+		"nullif(i)",
+		"nullif(i, true)",
+		"greatest(1, 'abc')",
+		"least(f, true, 1)",
+		"i between 'a' and 'b'",
+		"i in ('a', 1)",
+		"i like 'a%'",
+		"s similar to 1",
+		"s ~ '('",
+		"s like 'abc\\'",
+		"f::bool",
+		"true::double precision",
+		"cast(i as bytea)",
+		"'abc' + 12.34",
+		"'abc' / 12.34",
+		"'abc' % 12.34",
+		"'abc' * 12.34",
+		"'abc' - 12.34",
 	}
 
 	for i, f := range fail {
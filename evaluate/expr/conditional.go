@@ -0,0 +1,363 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/sql"
+)
+
+type When struct {
+	Expr   Expr
+	Result Expr
+}
+
+// Case is a searched CASE expression when Expr is nil, and a simple CASE expression otherwise.
+type Case struct {
+	Expr  Expr
+	Whens []When
+	Else  Expr
+}
+
+func (c *Case) String() string {
+	s := "CASE"
+	if c.Expr != nil {
+		s += fmt.Sprintf(" %s", c.Expr)
+	}
+	for _, w := range c.Whens {
+		s += fmt.Sprintf(" WHEN %s THEN %s", w.Expr, w.Result)
+	}
+	if c.Else != nil {
+		s += fmt.Sprintf(" ELSE %s", c.Else)
+	}
+	return s + " END"
+}
+
+func equalExpr(e1, e2 Expr) bool {
+	if e1 == nil || e2 == nil {
+		return e1 == nil && e2 == nil
+	}
+	return e1.Equal(e2)
+}
+
+func (c *Case) Equal(e Expr) bool {
+	c2, ok := e.(*Case)
+	if !ok {
+		return false
+	}
+	if !equalExpr(c.Expr, c2.Expr) || !equalExpr(c.Else, c2.Else) ||
+		len(c.Whens) != len(c2.Whens) {
+
+		return false
+	}
+	for i := range c.Whens {
+		if !c.Whens[i].Expr.Equal(c2.Whens[i].Expr) ||
+			!c.Whens[i].Result.Equal(c2.Whens[i].Result) {
+
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Case) HasRef() bool {
+	if (c.Expr != nil && c.Expr.HasRef()) || (c.Else != nil && c.Else.HasRef()) {
+		return true
+	}
+	for _, w := range c.Whens {
+		if w.Expr.HasRef() || w.Result.HasRef() {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTypes(what string, cts ...sql.ColumnType) (sql.ColumnType, error) {
+	ct := cts[0]
+	for _, ct2 := range cts[1:] {
+		var ok bool
+		ct, ok = sql.UnifyColumnType(ct, ct2)
+		if !ok {
+			return ct, fmt.Errorf("engine: %s types %s and %s cannot be matched", what, ct.Type,
+				ct2.Type)
+		}
+	}
+	return ct, nil
+}
+
+// castResult casts a result of CASE, COALESCE, GREATEST, or LEAST to the type of the
+// expression, ct, if it has a different type, so that the value always has the type of the
+// expression.
+func castResult(ce sql.CExpr, rt, ct sql.ColumnType) sql.CExpr {
+	if rt.Type == ct.Type || rt.Type == sql.UnknownType || rt.Array != ct.Array {
+		return ce
+	}
+	return &castExpr{expr: ce, ct: ct}
+}
+
+func compileCase(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, c *Case, agg bool) (sql.CExpr, sql.ColumnType, error) {
+
+	var ct sql.ColumnType
+	var args []sql.CExpr
+	var valType sql.ColumnType
+	cf := caseFunc
+	if c.Expr != nil {
+		ce, vt, err := compile(ctx, pctx, tx, cctx, c.Expr, agg)
+		if err != nil {
+			return nil, ct, err
+		}
+		args = append(args, ce)
+		valType = vt
+		cf = simpleCaseFunc
+	}
+
+	var results []Expr
+	var resultArgs []int
+	var resultTypes []sql.ColumnType
+	for _, w := range c.Whens {
+		ce, wt, err := compile(ctx, pctx, tx, cctx, w.Expr, agg)
+		if err != nil {
+			return nil, ct, err
+		}
+		if c.Expr != nil {
+			valType, err = matchTypes("CASE", valType, wt)
+			if err != nil {
+				return nil, ct, err
+			}
+		} else if wt.Type != sql.BooleanType && wt.Type != sql.UnknownType {
+			return nil, ct,
+				fmt.Errorf("engine: argument of CASE/WHEN must be type %s, not type %s",
+					sql.BooleanType, wt.Type)
+		}
+
+		re, rt, err := compile(ctx, pctx, tx, cctx, w.Result, agg)
+		if err != nil {
+			return nil, ct, err
+		}
+		args = append(args, ce, re)
+		results = append(results, w.Result)
+		resultArgs = append(resultArgs, len(args)-1)
+		resultTypes = append(resultTypes, rt)
+	}
+
+	if c.Else != nil {
+		ce, et, err := compile(ctx, pctx, tx, cctx, c.Else, agg)
+		if err != nil {
+			return nil, ct, err
+		}
+		args = append(args, ce)
+		results = append(results, c.Else)
+		resultArgs = append(resultArgs, len(args)-1)
+		resultTypes = append(resultTypes, et)
+	}
+
+	ct, err := matchTypes("CASE", resultTypes...)
+	if err != nil {
+		return nil, ct, err
+	}
+	setParameterTypes(pctx, results, ct)
+	for rdx, adx := range resultArgs {
+		args[adx] = castResult(args[adx], resultTypes[rdx], ct)
+	}
+	if c.Expr != nil {
+		whens := []Expr{c.Expr}
+		for _, w := range c.Whens {
+			whens = append(whens, w.Expr)
+		}
+		setParameterTypes(pctx, whens, valType)
+	}
+	if c.Else == nil {
+		ct.NotNull = false
+	}
+	return &call{cf, args}, ct, nil
+}
+
+// caseCall evaluates a searched CASE: the arguments are pairs of conditions and results,
+// optionally followed by the ELSE result.
+func caseCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	for len(args) >= 2 {
+		val, err := args[0].Eval(ctx, tx, ectx)
+		if err != nil {
+			return nil, err
+		}
+		if val != nil {
+			b, ok := val.(sql.BoolValue)
+			if !ok {
+				return nil, fmt.Errorf("engine: want boolean got %v", val)
+			} else if b {
+				return args[1].Eval(ctx, tx, ectx)
+			}
+		}
+		args = args[2:]
+	}
+
+	if len(args) == 1 {
+		return args[0].Eval(ctx, tx, ectx)
+	}
+	return nil, nil
+}
+
+// simpleCaseCall evaluates a simple CASE: the first argument is compared against each of the
+// following pairs of values and results, optionally followed by the ELSE result.
+func simpleCaseCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	val, err := args[0].Eval(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	args = args[1:]
+	for len(args) >= 2 {
+		if val != nil {
+			wval, err := args[0].Eval(ctx, tx, ectx)
+			if err != nil {
+				return nil, err
+			}
+			if wval != nil {
+				cmp, err := val.Compare(wval)
+				if err != nil {
+					return nil, err
+				} else if cmp == 0 {
+					return args[1].Eval(ctx, tx, ectx)
+				}
+			}
+		}
+		args = args[2:]
+	}
+
+	if len(args) == 1 {
+		return args[0].Eval(ctx, tx, ectx)
+	}
+	return nil, nil
+}
+
+func coalesceCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	for _, a := range args {
+		val, err := a.Eval(ctx, tx, ectx)
+		if err != nil {
+			return nil, err
+		} else if val != nil {
+			return val, nil
+		}
+	}
+	return nil, nil
+}
+
+// commonType is the type of COALESCE, GREATEST, and LEAST: NULL is only returned if all of the
+// arguments are NULL.
+func commonType(nam string, args []sql.ColumnType) (sql.ColumnType, error) {
+	ct, err := matchTypes(strings.ToUpper(nam), args...)
+	if err != nil {
+		return ct, err
+	}
+	ct.NotNull = false
+	for _, at := range args {
+		if at.NotNull {
+			ct.NotNull = true
+			break
+		}
+	}
+	return ct, nil
+}
+
+func compareType(nam string, args []sql.ColumnType) (sql.ColumnType, error) {
+	_, err := matchTypes(strings.ToUpper(nam), args...)
+	return boolType, err
+}
+
+func nullIfType(nam string, args []sql.ColumnType) (sql.ColumnType, error) {
+	_, err := matchTypes(strings.ToUpper(nam), args...)
+	return firstArgType(args), err
+}
+
+func nullIfCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if args[0] == nil || args[1] == nil {
+		return args[0], nil
+	}
+	cmp, err := args[0].Compare(args[1])
+	if err != nil {
+		return nil, err
+	} else if cmp == 0 {
+		return nil, nil
+	}
+	return args[0], nil
+}
+
+func extremeValue(args []sql.Value, dir int) (sql.Value, error) {
+	var ret sql.Value
+	for _, a := range args {
+		if a == nil {
+			continue
+		} else if ret == nil {
+			ret = a
+			continue
+		}
+
+		cmp, err := a.Compare(ret)
+		if err != nil {
+			return nil, err
+		} else if cmp*dir > 0 {
+			ret = a
+		}
+	}
+	return ret, nil
+}
+
+func greatestCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	return extremeValue(args, 1)
+}
+
+func leastCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	return extremeValue(args, -1)
+}
+
+func betweenCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	ret := sql.Value(sql.BoolValue(true))
+	for i, a := range args[1:] {
+		if a == nil {
+			ret = nil
+			continue
+		}
+		cmp, err := args[0].Compare(a)
+		if err != nil {
+			return nil, err
+		}
+		if (i == 0 && cmp < 0) || (i == 1 && cmp > 0) {
+			return sql.BoolValue(false), nil
+		}
+	}
+	return ret, nil
+}
+
+func inCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	ret := sql.Value(sql.BoolValue(false))
+	for _, a := range args[1:] {
+		if a == nil {
+			ret = nil
+			continue
+		}
+		cmp, err := args[0].Compare(a)
+		if err != nil {
+			return nil, err
+		} else if cmp == 0 {
+			return sql.BoolValue(true), nil
+		}
+	}
+	return ret, nil
+}
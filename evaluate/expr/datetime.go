@@ -0,0 +1,667 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/leftmike/maho/sql"
+)
+
+var (
+	dateType        = sql.ColumnType{Type: sql.DateType, Size: 4}
+	timeType        = sql.ColumnType{Type: sql.TimeType, Size: 8}
+	timestampType   = sql.ColumnType{Type: sql.TimestampType, Size: 8}
+	timestampTZType = sql.ColumnType{Type: sql.TimestampTZType, Size: 8}
+	intervalType    = sql.ColumnType{Type: sql.IntervalType, Size: 16}
+	floatType       = sql.ColumnType{Type: sql.FloatType, Size: 8}
+)
+
+func isTemporalType(dt sql.DataType) bool {
+	switch dt {
+	case sql.DateType, sql.TimeType, sql.TimestampType, sql.TimestampTZType, sql.IntervalType:
+		return true
+	}
+	return false
+}
+
+// isCoercedType returns true if string literals are converted to dt when used with values of
+// type dt.
+func isCoercedType(dt sql.DataType) bool {
+	return isTemporalType(dt) || dt == sql.UUIDType || dt == sql.NumericType
+}
+
+func isTemporal(v sql.Value) bool {
+	switch v.(type) {
+	case sql.DateValue, sql.TimeValue, sql.TimestampValue, sql.TimestampTZValue,
+		sql.IntervalValue:
+		return true
+	}
+	return false
+}
+
+func temporalType(dt sql.DataType) sql.ColumnType {
+	switch dt {
+	case sql.DateType:
+		return dateType
+	case sql.TimeType:
+		return timeType
+	case sql.TimestampType:
+		return timestampType
+	case sql.TimestampTZType:
+		return timestampTZType
+	case sql.IntervalType:
+		return intervalType
+	}
+	panic(fmt.Sprintf("unexpected temporal type; got %v", dt))
+}
+
+// coerceLiteral converts a string literal to one of the data types in dts, trying each in turn.
+func coerceLiteral(ce sql.CExpr, ct sql.ColumnType, dts ...sql.DataType) (sql.CExpr,
+	sql.ColumnType, error) {
+
+	l, ok := ce.(*Literal)
+	if !ok {
+		return ce, ct, nil
+	}
+	s, ok := l.Value.(sql.StringValue)
+	if !ok {
+		return ce, ct, nil
+	}
+
+	var err error
+	for _, dt := range dts {
+		var v sql.Value
+		v, err = sql.ConvertValue(dt, s)
+		if err == nil {
+			var rct sql.ColumnType
+			switch dt {
+			case sql.JSONType:
+				rct = jsonType
+			case sql.JSONBType:
+				rct = jsonbType
+			case sql.UUIDType:
+				rct = uuidType
+			case sql.NumericType:
+				rct = numericType
+			default:
+				rct = temporalType(dt)
+			}
+			rct.NotNull = true
+			return &Literal{v}, rct, nil
+		}
+	}
+	return nil, ct, fmt.Errorf("engine: %s", err)
+}
+
+// coerceOperands converts a string literal operand of a binary operator to a date, time,
+// timestamp, interval, uuid, or numeric when the other operand is one of those types.
+func coerceOperands(op Op, a1 sql.CExpr, ct1 sql.ColumnType, a2 sql.CExpr,
+	ct2 sql.ColumnType) (sql.CExpr, sql.ColumnType, sql.CExpr, sql.ColumnType, error) {
+
+	var dts []sql.DataType
+	var dt sql.DataType
+	if ct1.Array || ct2.Array {
+		return a1, ct1, a2, ct2, nil
+	} else if isCoercedType(ct1.Type) && ct2.Type == sql.StringType {
+		dt = ct1.Type
+	} else if isCoercedType(ct2.Type) && ct1.Type == sql.StringType {
+		dt = ct2.Type
+	} else {
+		return a1, ct1, a2, ct2, nil
+	}
+
+	switch op {
+	case MultiplyOp, DivideOp, ModuloOp:
+		if dt != sql.NumericType {
+			return a1, ct1, a2, ct2, nil
+		}
+		dts = []sql.DataType{dt}
+	case AddOp:
+		if dt == sql.UUIDType {
+			return a1, ct1, a2, ct2, nil
+		} else if dt == sql.NumericType {
+			dts = []sql.DataType{dt}
+			break
+		}
+		dts = []sql.DataType{sql.IntervalType}
+	case SubtractOp:
+		if dt == sql.UUIDType {
+			return a1, ct1, a2, ct2, nil
+		} else if dt == sql.NumericType {
+			dts = []sql.DataType{dt}
+			break
+		}
+		dts = []sql.DataType{dt, sql.IntervalType}
+	case EqualOp, NotEqualOp, LessThanOp, LessEqualOp, GreaterThanOp, GreaterEqualOp:
+		dts = []sql.DataType{dt}
+	default:
+		return a1, ct1, a2, ct2, nil
+	}
+
+	var err error
+	if ct1.Type == sql.StringType {
+		a1, ct1, err = coerceLiteral(a1, ct1, dts...)
+	} else {
+		a2, ct2, err = coerceLiteral(a2, ct2, dts...)
+	}
+	return a1, ct1, a2, ct2, err
+}
+
+// coerceArgs converts string literal arguments to the type of the first argument which is a
+// date, time, timestamp, interval, or uuid.
+func coerceArgs(args []sql.CExpr, argTypes []sql.ColumnType) error {
+	dt := sql.UnknownType
+	for _, at := range argTypes {
+		if isCoercedType(at.Type) {
+			dt = at.Type
+			break
+		}
+	}
+	if dt == sql.UnknownType {
+		return nil
+	}
+
+	for adx := range args {
+		if argTypes[adx].Type == sql.StringType {
+			var err error
+			args[adx], argTypes[adx], err = coerceLiteral(args[adx], argTypes[adx], dt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func addType(args []sql.ColumnType) sql.ColumnType {
+	dt0, dt1 := args[0].Type, args[1].Type
+	if dt0 == sql.IntervalType || dt0 == sql.IntegerType {
+		dt0, dt1 = dt1, dt0
+	}
+	switch dt0 {
+	case sql.DateType:
+		if dt1 == sql.IntegerType {
+			return dateType
+		}
+		return timestampType
+	case sql.TimeType:
+		if dt1 == sql.DateType {
+			return timestampType
+		}
+		return timeType
+	case sql.TimestampType, sql.TimestampTZType, sql.IntervalType:
+		return temporalType(dt0)
+	}
+	return numType(args)
+}
+
+func subtractType(args []sql.ColumnType) sql.ColumnType {
+	dt0, dt1 := args[0].Type, args[1].Type
+	switch dt0 {
+	case sql.DateType:
+		if dt1 == sql.IntegerType {
+			return dateType
+		} else if dt1 == sql.DateType {
+			return intType
+		} else if dt1 == sql.IntervalType {
+			return timestampType
+		}
+		return intervalType
+	case sql.TimeType, sql.TimestampType, sql.TimestampTZType:
+		if dt1 == sql.IntervalType {
+			return temporalType(dt0)
+		}
+		return intervalType
+	case sql.IntervalType:
+		return intervalType
+	}
+	return numType(args)
+}
+
+func intervalNumType(args []sql.ColumnType) sql.ColumnType {
+	for _, at := range args {
+		if at.Type == sql.IntervalType {
+			return intervalType
+		}
+	}
+	return numType(args)
+}
+
+func addTemporal(a0, a1 sql.Value) (sql.Value, bool, error) {
+	switch v0 := a0.(type) {
+	case sql.DateValue:
+		switch v1 := a1.(type) {
+		case sql.Int64Value:
+			return v0 + sql.DateValue(v1), true, nil
+		case sql.IntervalValue:
+			return sql.TimestampValue(v0 * sql.MicrosecondsPerDay).AddInterval(v1), true, nil
+		case sql.TimeValue:
+			return sql.TimestampValue(int64(v0)*sql.MicrosecondsPerDay + int64(v1)), true, nil
+		}
+	case sql.TimeValue:
+		switch v1 := a1.(type) {
+		case sql.IntervalValue:
+			return v0.AddInterval(v1), true, nil
+		case sql.DateValue:
+			return addTemporal(v1, v0)
+		}
+	case sql.TimestampValue:
+		if v1, ok := a1.(sql.IntervalValue); ok {
+			return v0.AddInterval(v1), true, nil
+		}
+	case sql.TimestampTZValue:
+		if v1, ok := a1.(sql.IntervalValue); ok {
+			return v0.AddInterval(v1), true, nil
+		}
+	case sql.IntervalValue:
+		switch v1 := a1.(type) {
+		case sql.IntervalValue:
+			return v0.Add(v1), true, nil
+		case sql.DateValue, sql.TimeValue, sql.TimestampValue, sql.TimestampTZValue:
+			return addTemporal(v1, v0)
+		}
+	case sql.Int64Value:
+		if v1, ok := a1.(sql.DateValue); ok {
+			return addTemporal(v1, v0)
+		}
+	}
+
+	if isTemporal(a0) || isTemporal(a1) {
+		return nil, true, fmt.Errorf("engine: unable to add %s and %s", a0, a1)
+	}
+	return nil, false, nil
+}
+
+func subtractTemporal(a0, a1 sql.Value) (sql.Value, bool, error) {
+	switch v0 := a0.(type) {
+	case sql.DateValue:
+		switch v1 := a1.(type) {
+		case sql.Int64Value:
+			return v0 - sql.DateValue(v1), true, nil
+		case sql.DateValue:
+			return sql.Int64Value(v0 - v1), true, nil
+		case sql.IntervalValue:
+			return sql.TimestampValue(v0 * sql.MicrosecondsPerDay).AddInterval(v1.Negate()),
+				true, nil
+		case sql.TimestampValue, sql.TimestampTZValue:
+			return sql.SubtractTimestamps(int64(v0)*sql.MicrosecondsPerDay,
+				timestampMicroseconds(v1)), true, nil
+		}
+	case sql.TimeValue:
+		switch v1 := a1.(type) {
+		case sql.TimeValue:
+			return sql.IntervalValue{Microseconds: int64(v0 - v1)}, true, nil
+		case sql.IntervalValue:
+			return v0.AddInterval(v1.Negate()), true, nil
+		}
+	case sql.TimestampValue:
+		switch v1 := a1.(type) {
+		case sql.IntervalValue:
+			return v0.AddInterval(v1.Negate()), true, nil
+		case sql.DateValue, sql.TimestampValue, sql.TimestampTZValue:
+			return sql.SubtractTimestamps(int64(v0), timestampMicroseconds(v1)), true, nil
+		}
+	case sql.TimestampTZValue:
+		switch v1 := a1.(type) {
+		case sql.IntervalValue:
+			return v0.AddInterval(v1.Negate()), true, nil
+		case sql.DateValue, sql.TimestampValue, sql.TimestampTZValue:
+			return sql.SubtractTimestamps(int64(v0), timestampMicroseconds(v1)), true, nil
+		}
+	case sql.IntervalValue:
+		if v1, ok := a1.(sql.IntervalValue); ok {
+			return v0.Add(v1.Negate()), true, nil
+		}
+	}
+
+	if isTemporal(a0) || isTemporal(a1) {
+		return nil, true, fmt.Errorf("engine: unable to subtract %s from %s", a1, a0)
+	}
+	return nil, false, nil
+}
+
+func timestampMicroseconds(v sql.Value) int64 {
+	switch v := v.(type) {
+	case sql.DateValue:
+		return int64(v) * sql.MicrosecondsPerDay
+	case sql.TimestampValue:
+		return int64(v)
+	case sql.TimestampTZValue:
+		return int64(v)
+	}
+	panic(fmt.Sprintf("unexpected timestamp value; got %v", v))
+}
+
+func numberValue(v sql.Value) (float64, bool) {
+	switch v := v.(type) {
+	case sql.Int64Value:
+		return float64(v), true
+	case sql.Float64Value:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func multiplyInterval(a0, a1 sql.Value) (sql.Value, bool, error) {
+	if _, ok := a1.(sql.IntervalValue); ok {
+		a0, a1 = a1, a0
+	}
+	iv, ok := a0.(sql.IntervalValue)
+	if !ok {
+		return nil, false, nil
+	}
+	f, ok := numberValue(a1)
+	if !ok {
+		return nil, true, fmt.Errorf("engine: want number got %v", a1)
+	}
+	ret, err := iv.Multiply(f)
+	return ret, true, err
+}
+
+func divideInterval(a0, a1 sql.Value) (sql.Value, bool, error) {
+	iv, ok := a0.(sql.IntervalValue)
+	if !ok {
+		return nil, false, nil
+	}
+	f, ok := numberValue(a1)
+	if !ok {
+		return nil, true, fmt.Errorf("engine: want number got %v", a1)
+	} else if f == 0 {
+		return nil, true, fmt.Errorf("engine: division by zero")
+	}
+	ret, err := iv.Multiply(1 / f)
+	return ret, true, err
+}
+
+// startTime returns the start of the transaction, so that now() is the same throughout a
+// transaction, like PostgreSQL.
+func startTime(tx sql.Transaction) time.Time {
+	if tx == nil {
+		return time.Now()
+	}
+	return tx.StartTime()
+}
+
+func nowCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	return sql.MakeTimestampTZValue(startTime(tx)), nil
+}
+
+func currentDateCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	return sql.MakeDateValue(startTime(tx).UTC()), nil
+}
+
+func localTimestampCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	return sql.MakeTimestampValue(startTime(tx)), nil
+}
+
+func dateTruncType(args []sql.ColumnType) sql.ColumnType {
+	if args[1].Type == sql.DateType {
+		return timestampType
+	}
+	return firstArgType(args[1:])
+}
+
+func fieldArg(nam string, v sql.Value) (string, error) {
+	s, ok := v.(sql.StringValue)
+	if !ok {
+		return "", fmt.Errorf("engine: %s: want string got %v", nam, v)
+	}
+	return strings.ToLower(strings.TrimSpace(string(s))), nil
+}
+
+func unitError(nam, field string, v sql.Value) error {
+	return fmt.Errorf("engine: %s: unit \"%s\" not supported for %s", nam, field, v)
+}
+
+func truncMonth(y int, m time.Month, field string) (int, time.Month, bool) {
+	switch field {
+	case "month":
+	case "quarter":
+		m = (m-1)/3*3 + 1
+	case "year":
+		m = time.January
+	case "decade":
+		y, m = y-y%10, time.January
+	case "century":
+		y, m = (y-1)/100*100+1, time.January
+	case "millennium":
+		y, m = (y-1)/1000*1000+1, time.January
+	default:
+		return 0, 0, false
+	}
+	return y, m, true
+}
+
+func truncTimestamp(us int64, field string) (int64, bool) {
+	switch field {
+	case "microseconds":
+		return us, true
+	case "milliseconds":
+		return us - floorMod(us, 1000), true
+	case "second":
+		return us - floorMod(us, sql.MicrosecondsPerSecond), true
+	case "minute":
+		return us - floorMod(us, sql.MicrosecondsPerMinute), true
+	case "hour":
+		return us - floorMod(us, sql.MicrosecondsPerHour), true
+	case "day":
+		return us - floorMod(us, sql.MicrosecondsPerDay), true
+	case "week":
+		us -= floorMod(us, sql.MicrosecondsPerDay)
+		wd := sql.TimestampValue(us).Time().Weekday()
+		return us - int64((wd+6)%7)*sql.MicrosecondsPerDay, true
+	}
+
+	t := sql.TimestampValue(us).Time()
+	y, m, ok := truncMonth(t.Year(), t.Month(), field)
+	if !ok {
+		return 0, false
+	}
+	return int64(sql.MakeTimestampValue(time.Date(y, m, 1, 0, 0, 0, 0, time.UTC))), true
+}
+
+func truncInterval(iv sql.IntervalValue, field string) (sql.IntervalValue, bool) {
+	switch field {
+	case "microseconds":
+	case "milliseconds":
+		iv.Microseconds -= iv.Microseconds % 1000
+	case "second":
+		iv.Microseconds -= iv.Microseconds % sql.MicrosecondsPerSecond
+	case "minute":
+		iv.Microseconds -= iv.Microseconds % sql.MicrosecondsPerMinute
+	case "hour":
+		iv.Microseconds -= iv.Microseconds % sql.MicrosecondsPerHour
+	case "day":
+		iv.Microseconds = 0
+	case "month", "quarter", "year", "decade", "century", "millennium":
+		months := map[string]int64{"month": 1, "quarter": 3, "year": 12, "decade": 120,
+			"century": 1200, "millennium": 12000}[field]
+		iv = sql.IntervalValue{Months: iv.Months - iv.Months%months}
+	default:
+		return iv, false
+	}
+	return iv, true
+}
+
+func floorMod(n, d int64) int64 {
+	r := n % d
+	if r < 0 {
+		r += d
+	}
+	return r
+}
+
+func dateTruncCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	field, err := fieldArg("date_trunc", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := args[1].(type) {
+	case sql.DateValue, sql.TimestampValue, sql.TimestampTZValue:
+		us, ok := truncTimestamp(timestampMicroseconds(v), field)
+		if !ok {
+			break
+		} else if _, ok := v.(sql.TimestampTZValue); ok {
+			return sql.TimestampTZValue(us), nil
+		}
+		return sql.TimestampValue(us), nil
+	case sql.IntervalValue:
+		if iv, ok := truncInterval(v, field); ok {
+			return iv, nil
+		}
+	default:
+		return nil, fmt.Errorf("engine: date_trunc: want timestamp or interval got %v", v)
+	}
+	return nil, unitError("date_trunc", field, args[1])
+}
+
+func clockPart(us int64, field string) (float64, bool) {
+	switch field {
+	case "hour":
+		return float64(us / sql.MicrosecondsPerHour), true
+	case "minute":
+		return float64(us % sql.MicrosecondsPerHour / sql.MicrosecondsPerMinute), true
+	case "second":
+		return float64(us%sql.MicrosecondsPerMinute) / sql.MicrosecondsPerSecond, true
+	case "milliseconds":
+		return float64(us%sql.MicrosecondsPerMinute) / 1000, true
+	case "microseconds":
+		return float64(us % sql.MicrosecondsPerMinute), true
+	}
+	return 0, false
+}
+
+func timestampPart(us int64, field string) (float64, bool) {
+	if field == "epoch" {
+		return float64(us) / sql.MicrosecondsPerSecond, true
+	}
+	if f, ok := clockPart(floorMod(us, sql.MicrosecondsPerDay), field); ok {
+		return f, true
+	}
+
+	t := sql.TimestampValue(us).Time()
+	switch field {
+	case "day":
+		return float64(t.Day()), true
+	case "month":
+		return float64(t.Month()), true
+	case "quarter":
+		return float64((t.Month()-1)/3 + 1), true
+	case "year":
+		return float64(t.Year()), true
+	case "decade":
+		return math.Floor(float64(t.Year()) / 10), true
+	case "century":
+		return float64((t.Year()-1)/100 + 1), true
+	case "millennium":
+		return float64((t.Year()-1)/1000 + 1), true
+	case "dow":
+		return float64(t.Weekday()), true
+	case "isodow":
+		return float64((t.Weekday()+6)%7 + 1), true
+	case "doy":
+		return float64(t.YearDay()), true
+	case "week":
+		_, wk := t.ISOWeek()
+		return float64(wk), true
+	case "isoyear":
+		y, _ := t.ISOWeek()
+		return float64(y), true
+	}
+	return 0, false
+}
+
+func intervalPart(iv sql.IntervalValue, field string) (float64, bool) {
+	switch field {
+	case "epoch":
+		return float64(iv.Months/12)*365.25*86400 + float64(iv.Months%12)*30*86400 +
+			float64(iv.Days)*86400 + float64(iv.Microseconds)/sql.MicrosecondsPerSecond, true
+	case "day":
+		return float64(iv.Days), true
+	case "month":
+		return float64(iv.Months % 12), true
+	case "quarter":
+		return float64(iv.Months%12/3 + 1), true
+	case "year":
+		return float64(iv.Months / 12), true
+	case "decade":
+		return float64(iv.Months / 120), true
+	case "century":
+		return float64(iv.Months / 1200), true
+	case "millennium":
+		return float64(iv.Months / 12000), true
+	}
+
+	us := iv.Microseconds
+	if us < 0 {
+		f, ok := clockPart(-us, field)
+		return -f, ok
+	}
+	return clockPart(us, field)
+}
+
+func datePartCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	field, err := fieldArg("date_part", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var f float64
+	var ok bool
+	switch v := args[1].(type) {
+	case sql.DateValue, sql.TimestampValue, sql.TimestampTZValue:
+		f, ok = timestampPart(timestampMicroseconds(v), field)
+	case sql.TimeValue:
+		if field == "epoch" {
+			f, ok = float64(v)/sql.MicrosecondsPerSecond, true
+		} else {
+			f, ok = clockPart(int64(v), field)
+		}
+	case sql.IntervalValue:
+		f, ok = intervalPart(v, field)
+	default:
+		return nil, fmt.Errorf("engine: date_part: want date, time, timestamp, or interval got %v",
+			v)
+	}
+	if !ok {
+		return nil, unitError("date_part", field, args[1])
+	}
+	return sql.Float64Value(f), nil
+}
+
+func castToTemporal(ct sql.ColumnType, v sql.Value) (sql.Value, error) {
+	ret, err := sql.ConvertValue(ct.Type, v)
+	if err != nil {
+		if _, ok := v.(sql.StringValue); ok {
+			return nil, fmt.Errorf("engine: invalid input for type %s: %s", typeName(ct), v)
+		}
+		return nil, fmt.Errorf("engine: cannot cast %s to %s", v, typeName(ct))
+	}
+	return ret, nil
+}
+
+func valueDataType(v sql.Value) sql.DataType {
+	switch v.(type) {
+	case sql.DateValue:
+		return sql.DateType
+	case sql.TimeValue:
+		return sql.TimeType
+	case sql.TimestampValue:
+		return sql.TimestampType
+	case sql.TimestampTZValue:
+		return sql.TimestampTZType
+	case sql.IntervalValue:
+		return sql.IntervalType
+	}
+	panic(fmt.Sprintf("unexpected temporal value; got %v", v))
+}
@@ -15,13 +15,26 @@ import (
 )
 
 const (
-	boolLiteralTag    = 1
-	int64LiteralTag   = 2
-	float64LiteralTag = 3
-	stringLiteralTag  = 4
-	bytesLiteralTag   = 5
-	colRefTag         = 6
-	callTag           = 7
+	boolLiteralTag        = 1
+	int64LiteralTag       = 2
+	float64LiteralTag     = 3
+	stringLiteralTag      = 4
+	bytesLiteralTag       = 5
+	colRefTag             = 6
+	callTag               = 7
+	nullLiteralTag        = 8
+	castTag               = 9
+	dateLiteralTag        = 10
+	timeLiteralTag        = 11
+	timestampLiteralTag   = 12
+	timestampTZLiteralTag = 13
+	intervalLiteralTag    = 14
+	numericLiteralTag     = 15
+	jsonLiteralTag        = 16
+	uuidLiteralTag        = 17
+	arrayLiteralTag       = 18
+	arrayCastTag          = 19
+	jsonTextLiteralTag    = 20
 )
 
 func Encode(ce sql.CExpr) []byte {
@@ -42,6 +55,8 @@ func encode(buf []byte, ce sql.CExpr) []byte {
 	switch ce := ce.(type) {
 	case *Literal:
 		switch val := ce.Value.(type) {
+		case nil:
+			buf = append(buf, nullLiteralTag)
 		case sql.BoolValue:
 			buf = append(buf, boolLiteralTag)
 			if val {
@@ -65,6 +80,43 @@ func encode(buf []byte, ce sql.CExpr) []byte {
 		case sql.Int64Value:
 			buf = append(buf, int64LiteralTag)
 			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.DateValue:
+			buf = append(buf, dateLiteralTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.TimeValue:
+			buf = append(buf, timeLiteralTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.TimestampValue:
+			buf = append(buf, timestampLiteralTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.TimestampTZValue:
+			buf = append(buf, timestampTZLiteralTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.IntervalValue:
+			buf = append(buf, intervalLiteralTag)
+			buf = util.EncodeZigzag64(buf, val.Months)
+			buf = util.EncodeZigzag64(buf, val.Days)
+			buf = util.EncodeZigzag64(buf, val.Microseconds)
+		case sql.NumericValue:
+			buf = append(buf, numericLiteralTag)
+			buf = encodeString(val.String(), buf)
+		case sql.JSONValue:
+			if s, ok := val.Text(); ok {
+				buf = append(buf, jsonTextLiteralTag)
+				buf = encodeString(s, buf)
+			} else {
+				buf = append(buf, jsonLiteralTag)
+				buf = encodeString(val.String(), buf)
+			}
+		case sql.UUIDValue:
+			buf = append(buf, uuidLiteralTag)
+			buf = append(buf, val[:]...)
+		case sql.ArrayValue:
+			buf = append(buf, arrayLiteralTag)
+			buf = util.EncodeVarint(buf, uint64(len(val)))
+			for _, v := range val {
+				buf = encode(buf, &Literal{v})
+			}
 		default:
 			panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", ce, ce))
 		}
@@ -85,6 +137,29 @@ func encode(buf []byte, ce sql.CExpr) []byte {
 		for _, a := range ce.args {
 			buf = encode(buf, a)
 		}
+	case *matchExpr:
+		buf = append(buf, callTag)
+		buf = encodeString(opFuncs[ce.op].name, buf)
+		buf = util.EncodeVarint(buf, 2)
+		buf = encode(buf, ce.expr)
+		buf = encode(buf, ce.pattern)
+	case *castExpr:
+		if ce.ct.Array {
+			buf = append(buf, arrayCastTag)
+		} else {
+			buf = append(buf, castTag)
+		}
+		buf = util.EncodeVarint(buf, uint64(ce.ct.Type))
+		buf = util.EncodeVarint(buf, uint64(ce.ct.Size))
+		if ce.ct.Fixed {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		if ce.ct.Type == sql.NumericType {
+			buf = util.EncodeVarint(buf, uint64(ce.ct.Scale))
+		}
+		buf = encode(buf, ce.expr)
 	case param:
 		panic("engine: parameters may not be encoded")
 	default:
@@ -142,6 +217,8 @@ func decode(buf []byte) (sql.CExpr, []byte) {
 		}
 		buf = buf[1:]
 		return &Literal{val}, buf
+	case nullLiteralTag:
+		return &Literal{nil}, buf
 	case stringLiteralTag:
 		var ok bool
 		var u uint64
@@ -184,6 +261,99 @@ func decode(buf []byte) (sql.CExpr, []byte) {
 			return nil, nil
 		}
 		return &Literal{sql.Int64Value(n)}, buf
+	case dateLiteralTag, timeLiteralTag, timestampLiteralTag, timestampTZLiteralTag:
+		var n int64
+		var ok bool
+		buf, n, ok = util.DecodeZigzag64(buf)
+		if !ok {
+			return nil, nil
+		}
+		var val sql.Value
+		switch tag {
+		case dateLiteralTag:
+			val = sql.DateValue(n)
+		case timeLiteralTag:
+			val = sql.TimeValue(n)
+		case timestampLiteralTag:
+			val = sql.TimestampValue(n)
+		case timestampTZLiteralTag:
+			val = sql.TimestampTZValue(n)
+		}
+		return &Literal{val}, buf
+	case intervalLiteralTag:
+		var iv sql.IntervalValue
+		var ok bool
+		buf, iv.Months, ok = util.DecodeZigzag64(buf)
+		if !ok {
+			return nil, nil
+		}
+		buf, iv.Days, ok = util.DecodeZigzag64(buf)
+		if !ok {
+			return nil, nil
+		}
+		buf, iv.Microseconds, ok = util.DecodeZigzag64(buf)
+		if !ok {
+			return nil, nil
+		}
+		return &Literal{iv}, buf
+	case numericLiteralTag:
+		var ok bool
+		var s string
+		buf, s, ok = decodeString(buf)
+		if !ok {
+			return nil, nil
+		}
+		n, err := sql.ParseNumeric(s)
+		if err != nil {
+			return nil, nil
+		}
+		return &Literal{n}, buf
+	case jsonLiteralTag, jsonTextLiteralTag:
+		var ok bool
+		var s string
+		buf, s, ok = decodeString(buf)
+		if !ok {
+			return nil, nil
+		}
+		var j sql.JSONValue
+		var err error
+		if tag == jsonTextLiteralTag {
+			j, err = sql.ParseJSONText(s)
+		} else {
+			j, err = sql.ParseJSON(s)
+		}
+		if err != nil {
+			return nil, nil
+		}
+		return &Literal{j}, buf
+	case uuidLiteralTag:
+		if len(buf) < 16 {
+			return nil, nil
+		}
+		var u sql.UUIDValue
+		copy(u[:], buf)
+		return &Literal{u}, buf[16:]
+	case arrayLiteralTag:
+		var ok bool
+		var u uint64
+
+		buf, u, ok = util.DecodeVarint(buf)
+		if !ok {
+			return nil, nil
+		}
+		a := make(sql.ArrayValue, 0, u)
+		for u > 0 {
+			u -= 1
+
+			var ce sql.CExpr
+			ce, buf = decode(buf)
+			l, ok := ce.(*Literal)
+			if !ok {
+				return nil, nil
+			}
+			a = append(a, l.Value)
+		}
+		return &Literal{a}, buf
 	case colRefTag:
 		var idx, nest int64
 		var u uint64
@@ -242,7 +412,48 @@ func decode(buf []byte) (sql.CExpr, []byte) {
 				return nil, nil
 			}
 		}
+		if op, ok := matchFuncs[cf]; ok && len(c.args) == 2 {
+			me, err := makeMatchExpr(op, c.args[0], c.args[1])
+			if err != nil {
+				return nil, nil
+			}
+			return me, buf
+		}
 		return c, buf
+	case castTag, arrayCastTag:
+		var ok bool
+		var dt, sz uint64
+
+		buf, dt, ok = util.DecodeVarint(buf)
+		if !ok {
+			return nil, nil
+		}
+		buf, sz, ok = util.DecodeVarint(buf)
+		if !ok || len(buf) < 1 {
+			return nil, nil
+		}
+		ce := &castExpr{
+			ct: sql.ColumnType{
+				Type:  sql.DataType(dt),
+				Size:  uint32(sz),
+				Fixed: buf[0] != 0,
+				Array: tag == arrayCastTag,
+			},
+		}
+		buf = buf[1:]
+		if ce.ct.Type == sql.NumericType {
+			var sc uint64
+			buf, sc, ok = util.DecodeVarint(buf)
+			if !ok {
+				return nil, nil
+			}
+			ce.ct.Scale = uint32(sc)
+		}
+		ce.expr, buf = decode(buf)
+		if ce.expr == nil {
+			return nil, nil
+		}
+		return ce, buf
 	default:
 		return nil, nil
 	}
@@ -280,6 +491,73 @@ func ColumnIndex(ce sql.CExpr) (int, bool) {
 	return 0, false
 }
 
+// Constant returns true if ce evaluates to the same value every time: it does not reference any
+// columns and does not call any volatile functions.
+func Constant(ce sql.CExpr) bool {
+	switch ce := ce.(type) {
+	case *Literal:
+		return true
+	case *call:
+		if ce.call.volatile {
+			return false
+		}
+		for _, a := range ce.args {
+			if !Constant(a) {
+				return false
+			}
+		}
+		return true
+	case *matchExpr:
+		return Constant(ce.expr) && Constant(ce.pattern)
+	case *castExpr:
+		return Constant(ce.expr)
+	}
+	return false
+}
+
+// MapColumns returns a copy of ce with each column reference changed to refer to colMap[idx]; it
+// returns false if ce references a column which maps to -1.
+func MapColumns(ce sql.CExpr, colMap []int) (sql.CExpr, bool) {
+	switch ce := ce.(type) {
+	case *colRef:
+		if ce.nest != 0 {
+			return ce, true
+		}
+		idx := colMap[ce.idx]
+		if idx < 0 {
+			return nil, false
+		}
+		return &colRef{idx: idx, ref: ce.ref}, true
+	case *call:
+		args := make([]sql.CExpr, 0, len(ce.args))
+		for _, a := range ce.args {
+			a, ok := MapColumns(a, colMap)
+			if !ok {
+				return nil, false
+			}
+			args = append(args, a)
+		}
+		return &call{call: ce.call, args: args}, true
+	case *matchExpr:
+		e, ok := MapColumns(ce.expr, colMap)
+		if !ok {
+			return nil, false
+		}
+		pattern, ok := MapColumns(ce.pattern, colMap)
+		if !ok {
+			return nil, false
+		}
+		return &matchExpr{op: ce.op, expr: e, pattern: pattern, re: ce.re}, true
+	case *castExpr:
+		e, ok := MapColumns(ce.expr, colMap)
+		if !ok {
+			return nil, false
+		}
+		return &castExpr{expr: e, ct: ce.ct}, true
+	}
+	return ce, true
+}
+
 type subqueryExpr struct {
 	op       SubqueryOp
 	call     *callFunc
@@ -421,6 +699,10 @@ func (c *call) String() string {
 func (c *call) Eval(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext) (sql.Value,
 	error) {
 
+	if c.call.lazyFn != nil {
+		return c.call.lazyFn(ctx, tx, ectx, c.args)
+	}
+
 	args := make([]sql.Value, len(c.args))
 	for i, a := range c.args {
 		var err error
@@ -450,7 +732,8 @@ func (p param) Eval(ctx context.Context, tx sql.Transaction, ectx sql.EvalContex
 }
 
 func numFunc(a0 sql.Value, a1 sql.Value, ifn func(i0, i1 sql.Int64Value) sql.Value,
-	ffn func(f0, f1 sql.Float64Value) sql.Value) (sql.Value, error) {
+	ffn func(f0, f1 sql.Float64Value) sql.Value,
+	nfn func(n0, n1 sql.NumericValue) (sql.Value, error)) (sql.Value, error) {
 
 	switch a0 := a0.(type) {
 	case sql.Float64Value:
@@ -459,6 +742,8 @@ func numFunc(a0 sql.Value, a1 sql.Value, ifn func(i0, i1 sql.Int64Value) sql.Val
 			return ffn(a0, a1), nil
 		case sql.Int64Value:
 			return ffn(a0, sql.Float64Value(a1)), nil
+		case sql.NumericValue:
+			return ffn(a0, sql.Float64Value(a1.Float64())), nil
 		}
 	case sql.Int64Value:
 		switch a1 := a1.(type) {
@@ -466,6 +751,17 @@ func numFunc(a0 sql.Value, a1 sql.Value, ifn func(i0, i1 sql.Int64Value) sql.Val
 			return ffn(sql.Float64Value(a0), a1), nil
 		case sql.Int64Value:
 			return ifn(a0, a1), nil
+		case sql.NumericValue:
+			return nfn(sql.NumericFromInt64(int64(a0)), a1)
+		}
+	case sql.NumericValue:
+		switch a1 := a1.(type) {
+		case sql.Float64Value:
+			return ffn(sql.Float64Value(a0.Float64()), a1), nil
+		case sql.Int64Value:
+			return nfn(a0, sql.NumericFromInt64(int64(a1)))
+		case sql.NumericValue:
+			return nfn(a0, a1)
 		}
 	default:
 		return nil, fmt.Errorf("engine: want number got %v", a0)
@@ -474,11 +770,17 @@ func numFunc(a0 sql.Value, a1 sql.Value, ifn func(i0, i1 sql.Int64Value) sql.Val
 }
 
 func numType(args []sql.ColumnType) sql.ColumnType {
+	numeric := false
 	for adx := range args {
 		if args[adx].Type == sql.FloatType {
 			return sql.ColumnType{Type: sql.FloatType, Size: 8}
+		} else if args[adx].Type == sql.NumericType {
+			numeric = true
 		}
 	}
+	if numeric {
+		return numericType
+	}
 	return sql.ColumnType{Type: sql.IntegerType, Size: 8}
 }
 
@@ -510,12 +812,18 @@ func shiftFunc(a0 sql.Value, a1 sql.Value,
 }
 
 func addCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if ret, ok, err := addTemporal(args[0], args[1]); ok {
+		return ret, err
+	}
 	return numFunc(args[0], args[1],
 		func(i0, i1 sql.Int64Value) sql.Value {
 			return i0 + i1
 		},
 		func(f0, f1 sql.Float64Value) sql.Value {
 			return f0 + f1
+		},
+		func(n0, n1 sql.NumericValue) (sql.Value, error) {
+			return n0.Add(n1), nil
 		})
 }
 
@@ -564,6 +872,9 @@ func concatCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 			s += fmt.Sprintf("%v", v)
 		case sql.Int64Value:
 			s += fmt.Sprintf("%v", v)
+		case sql.DateValue, sql.TimeValue, sql.TimestampValue, sql.TimestampTZValue,
+			sql.IntervalValue, sql.NumericValue, sql.JSONValue, sql.UUIDValue, sql.ArrayValue:
+			s += v.String()
 		default:
 			panic("unexpected sql.Value")
 		}
@@ -572,12 +883,22 @@ func concatCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 }
 
 func divideCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if ret, ok, err := divideInterval(args[0], args[1]); ok {
+		return ret, err
+	}
 	return numFunc(args[0], args[1],
 		func(i0, i1 sql.Int64Value) sql.Value {
 			return i0 / i1
 		},
 		func(f0, f1 sql.Float64Value) sql.Value {
 			return f0 / f1
+		},
+		func(n0, n1 sql.NumericValue) (sql.Value, error) {
+			n, err := n0.Divide(n1)
+			if err != nil {
+				return nil, fmt.Errorf("engine: %s", err)
+			}
+			return n, nil
 		})
 }
 
@@ -628,7 +949,43 @@ func lShiftCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 		})
 }
 
+func moduloNumeric(a0, a1 sql.Value) (sql.Value, bool, error) {
+	n0, ok0 := a0.(sql.NumericValue)
+	n1, ok1 := a1.(sql.NumericValue)
+	if !ok0 && !ok1 {
+		return nil, false, nil
+	}
+
+	if i, ok := a0.(sql.Int64Value); ok {
+		n0, ok0 = sql.NumericFromInt64(int64(i)), true
+	}
+	if i, ok := a1.(sql.Int64Value); ok {
+		n1, ok1 = sql.NumericFromInt64(int64(i)), true
+	}
+	if !ok0 {
+		return nil, true, fmt.Errorf("engine: want integer or numeric got %v", a0)
+	} else if !ok1 {
+		return nil, true, fmt.Errorf("engine: want integer or numeric got %v", a1)
+	}
+
+	n, err := n0.Modulo(n1)
+	if err != nil {
+		return nil, true, fmt.Errorf("engine: %s", err)
+	}
+	return n, true, nil
+}
+
+func moduloType(args []sql.ColumnType) sql.ColumnType {
+	if args[0].Type == sql.NumericType || args[1].Type == sql.NumericType {
+		return numericType
+	}
+	return intType
+}
+
 func moduloCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if ret, ok, err := moduloNumeric(args[0], args[1]); ok {
+		return ret, err
+	}
 	return intFunc(args[0], args[1],
 		func(i0, i1 sql.Int64Value) sql.Value {
 			return i0 % i1
@@ -636,12 +993,18 @@ func moduloCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 }
 
 func multiplyCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if ret, ok, err := multiplyInterval(args[0], args[1]); ok {
+		return ret, err
+	}
 	return numFunc(args[0], args[1],
 		func(i0, i1 sql.Int64Value) sql.Value {
 			return i0 * i1
 		},
 		func(f0, f1 sql.Float64Value) sql.Value {
 			return f0 * f1
+		},
+		func(n0, n1 sql.NumericValue) (sql.Value, error) {
+			return n0.Multiply(n1), nil
 		})
 }
 
@@ -651,6 +1014,10 @@ func negateCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 		return -a0, nil
 	case sql.Int64Value:
 		return -a0, nil
+	case sql.IntervalValue:
+		return a0.Negate(), nil
+	case sql.NumericValue:
+		return a0.Negate(), nil
 	}
 	return nil, fmt.Errorf("engine: want number got %v", args[0])
 }
@@ -687,12 +1054,18 @@ func rShiftCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 }
 
 func subtractCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if ret, ok, err := subtractTemporal(args[0], args[1]); ok {
+		return ret, err
+	}
 	return numFunc(args[0], args[1],
 		func(i0, i1 sql.Int64Value) sql.Value {
 			return i0 - i1
 		},
 		func(f0, f1 sql.Float64Value) sql.Value {
 			return f0 - f1
+		},
+		func(n0, n1 sql.NumericValue) (sql.Value, error) {
+			return n0.Subtract(n1), nil
 		})
 }
 
@@ -708,6 +1081,8 @@ func absCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 			return -a0, nil
 		}
 		return a0, nil
+	case sql.NumericValue:
+		return a0.Abs(), nil
 	}
 	return nil, fmt.Errorf("engine: want number got %v", args[0])
 }
@@ -727,6 +1102,14 @@ func uniqueRowIDCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 	return sql.Int64Value(atomic.AddUint64(&rowID, 1)), nil
 }
 
+func genRandomUUIDCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	u, err := sql.RandomUUID()
+	if err != nil {
+		return nil, fmt.Errorf("engine: gen_random_uuid: %s", err)
+	}
+	return u, nil
+}
+
 func versionCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
 	return sql.StringValue(sql.Version()), nil
 }
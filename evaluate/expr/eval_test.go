@@ -3,6 +3,7 @@ package expr_test
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
@@ -27,15 +28,15 @@ func TestEval(t *testing.T) {
 		{"1 / null", sql.NullString},
 		{"null / 2.3", sql.NullString},
 		{"456 / 123", "3"},
-		{"123 / 45.6", fmt.Sprintf("%v", 123/45.6)},
-		{"123.45 / 6", fmt.Sprintf("%v", 123.45/6)},
-		{"12.3 / 45.6", fmt.Sprintf("%v", 12.3/45.6)},
+		{"123 / 45.6", "2.697368421052632"},
+		{"123.45 / 6", "20.57500000000000"},
+		{"12.3 / 45.6", "0.2697368421052632"},
 
 		{"1 * null", sql.NullString},
 		{"null * 2.3", sql.NullString},
 		{"456 * 123", "56088"},
 		{"123 * 45.6", fmt.Sprintf("%v", 123*45.6)},
-		{"123.45 * 6", fmt.Sprintf("%v", 123.45*6)},
+		{"123.45 * 6", "740.70"},
 		{"12.3 * 45.6", fmt.Sprintf("%v", 12.3*45.6)},
 
 		{"- null", sql.NullString},
@@ -161,6 +162,135 @@ func TestEval(t *testing.T) {
 		{"null is null", sql.TrueString},
 		{"null is not null", sql.FalseString},
 		{"not (123 is null)", sql.TrueString},
+		{"1 + 2 is null", sql.FalseString},
+
+		{"case when 1 > 2 then 'a' when 2 > 1 then 'b' else 'c' end", "'b'"},
+		{"case when 1 > 2 then 'a' else 'c' end", "'c'"},
+		{"case when 1 > 2 then 'a' end", sql.NullString},
+		{"case when null then 'a' else 'b' end", "'b'"},
+		{"case when 1 = 1 then 1 else 1 / 0 end", "1"},
+		{"case 2 when 1 then 'a' when 2 then 'b' else 'c' end", "'b'"},
+		{"case 3 when 1 then 'a' when 2 then 'b' end", sql.NullString},
+		{"case null when null then 'a' else 'b' end", "'b'"},
+		{"case 1 when null then 'a' when 1 then 'b' end", "'b'"},
+
+		{"coalesce(null, null)", sql.NullString},
+		{"coalesce(null, 2, 3)", "2"},
+		{"coalesce(1, 1 / 0)", "1"},
+		{"nullif(1, 1)", sql.NullString},
+		{"nullif(1, 2)", "1"},
+		{"nullif(null, 2)", sql.NullString},
+		{"nullif(1, null)", "1"},
+		{"greatest(1, 3, 2)", "3"},
+		{"greatest(1, null, 2.5)", "2.5"},
+		{"greatest(null, null)", sql.NullString},
+		{"least('b', 'a', 'c')", "'a'"},
+		{"least(null, 3, 2)", "2"},
+
+		{"2 between 1 and 3", sql.TrueString},
+		{"1 between 1 and 1", sql.TrueString},
+		{"4 between 1 and 3", sql.FalseString},
+		{"2 not between 1 and 3", sql.FalseString},
+		{"null between 1 and 3", sql.NullString},
+		{"2 between null and 3", sql.NullString},
+		{"4 between null and 3", sql.FalseString},
+		{"0 between 1 and null", sql.FalseString},
+		{"1 + 1 between 1 and 3 - 1", sql.TrueString},
+
+		{"2 in (1, 2, 3)", sql.TrueString},
+		{"4 in (1, 2, 3)", sql.FalseString},
+		{"4 not in (1, 2, 3)", sql.TrueString},
+		{"2 in (1, null, 2)", sql.TrueString},
+		{"4 in (1, null, 2)", sql.NullString},
+		{"4 not in (1, null, 2)", sql.NullString},
+		{"null in (1, 2)", sql.NullString},
+		{"2.0 in (1, 2)", sql.TrueString},
+
+		{"'abc' like 'abc'", sql.TrueString},
+		{"'abc' like 'a%'", sql.TrueString},
+		{"'abc' like '_b_'", sql.TrueString},
+		{"'abc' like 'b%'", sql.FalseString},
+		{"'ABC' like 'a%'", sql.FalseString},
+		{"'ABC' ilike 'a%'", sql.TrueString},
+		{"'abc' not like '%c'", sql.FalseString},
+		{"'ABC' not ilike '%d'", sql.TrueString},
+		{"'a.c' like 'a.c'", sql.TrueString},
+		{"'abc' like 'a.c'", sql.FalseString},
+		{"'a%c' like 'a\\%c'", sql.TrueString},
+		{"'abc' like 'a\\%c'", sql.FalseString},
+		{"'a%c' like 'a!%c' escape '!'", sql.TrueString},
+		{"'abc' like 'a!%c' escape '!'", sql.FalseString},
+		{"'a\\c' like 'a\\c' escape '!'", sql.TrueString},
+		{"'a_c' like 'a__c' escape '_'", sql.TrueString},
+		{"'a%c' like 'a%c' escape ''", sql.TrueString},
+		{"'ab' || 'c' like 'a' || '%'", sql.TrueString},
+		{"null like 'a%'", sql.NullString},
+		{"'abc' like null", sql.NullString},
+		{"'abc' similar to 'abc'", sql.TrueString},
+		{"'abc' similar to 'a%'", sql.TrueString},
+		{"'abc' similar to '(a|b)+c'", sql.TrueString},
+		{"'abc' similar to '[a-c]*'", sql.TrueString},
+		{"'abc' similar to 'a'", sql.FalseString},
+		{"'abc' similar to 'a.c'", sql.FalseString},
+		{"'abc' not similar to 'x%'", sql.TrueString},
+		{"'abc' ~ 'b'", sql.TrueString},
+		{"'abc' ~ '^b'", sql.FalseString},
+		{"'abc' ~ 'a.c'", sql.TrueString},
+		{"'ABC' ~ 'b'", sql.FalseString},
+		{"'ABC' ~* 'b'", sql.TrueString},
+		{"'abc' !~ 'd'", sql.TrueString},
+		{"'ABC' !~* 'b'", sql.FalseString},
+		{"null ~ 'a'", sql.NullString},
+
+		{"cast('123' as int) + 1", "124"},
+		{"' -45 '::smallint", "-45"},
+		{"2.5::int", "3"},
+		{"-2.5::bigint", "-3"},
+		{"1.4::int", "1"},
+		{"true::int + false::int", "1"},
+		{"32767::smallint", "32767"},
+		{"'1.5'::double precision * 2", "3"},
+		{"7::real / 2", "3.5"},
+		{"0.1::real::text", "'0.10000000149011612'"},
+		{"123::text || 'x'", "'123x'"},
+		{"1.5::varchar", "'1.5'"},
+		{"true::text", "'true'"},
+		{"'abc'::char(3)", "'abc'"},
+		{"'ab   '::char(3)", "'ab '"},
+		{"'abcdef'::varchar(10)", "'abcdef'"},
+		{"'日本語'::varchar(3)", "'日本語'"},
+		{"'abcd'::char(3)", "'abc'"},
+		{"'abcd'::varchar(3)", "'abc'"},
+		{"'abcd '::varchar(3)", "'abc'"},
+		{"'日本語です'::varchar(3)", "'日本語'"},
+		{"array['abcd', 'ab']::varchar(3)[]", "{abc,ab}"},
+		{"'abc'::bytea", "'\\x616263'"},
+		{"'Yes'::bool", sql.TrueString},
+		{"' off '::boolean", sql.FalseString},
+		{"0::bool", sql.FalseString},
+		{"-3::bool", sql.TrueString},
+		{"null::int", sql.NullString},
+		{"cast(null as text)", sql.NullString},
+		{"array[1, null, 3]", "{1,NULL,3}"},
+		{"array['a', 'b c']", `{a,"b c"}`},
+		{"array[1, 2][2]", "2"},
+		{"array[1, 2][3]", sql.NullString},
+		{"array[1, 2][null]", sql.NullString},
+		{"'{1,2}'::int[]", "{1,2}"},
+		{"array[1, 2]::text[]", "{1,2}"},
+		{"array[1, 2]::text", "'{1,2}'"},
+		{"2 = any(array[1, 2])", sql.TrueString},
+		{"3 = any(array[1, 2])", sql.FalseString},
+		{"3 = any(array[1, null])", sql.NullString},
+		{"2 = any('{1,2}')", sql.TrueString},
+		{"3 > all(array[1, 2])", sql.TrueString},
+		{"2 > all(array[1, 2])", sql.FalseString},
+		{"null = any(array[1])", sql.NullString},
+		{"null = all(array[]::int[])", sql.TrueString},
+		{"array_length(array[1, 2, 3], 1)", "3"},
+		{"array_length(array[1, 2, 3], 2)", sql.NullString},
+		{"array[1, 2] = '{1,2}'", sql.TrueString},
+		{"array[1, 2] < array[1, 2, 0]", sql.TrueString},
 	}
 
 	for i, c := range cases {
@@ -228,20 +358,30 @@ func TestEval(t *testing.T) {
 	}
 
 	fail := []string{
+		"'abc'::int",
+		"'1.5'::int",
+		"''::int",
+		"'99999999999999999999'::bigint",
+		"32768::smallint",
+		"-2147483649::int",
+		"10000000000000000000.0::bigint",
+		"'abc'::double precision",
+		"'1e40'::real",
+		"'maybe'::bool",
+		"'{a}'::int[]",
+		"'{1,{2}}'::int[]",
+		"'abc'::binary(2)",
+		"'abc' like 'a' escape 'xy'",
+		"'abc' ~ concat('(', 'a')",
 		"123 + 'abc'",
-		"'abc' + 12.34",
 		"true + 123",
 		"123 / 'abc'",
-		"'abc' / 12.34",
 		"true / 123",
 		"123 % 'abc'",
-		"'abc' % 12.34",
 		"true % 123",
 		"123 * 'abc'",
-		"'abc' * 12.34",
 		"true * 123",
 		"123 - 'abc'",
-		"'abc' - 12.34",
 		"true - 123",
 
 		"123 AND true",
@@ -316,6 +456,47 @@ func TestEval(t *testing.T) {
 	}
 }
 
+func TestEvalResultType(t *testing.T) {
+	cases := []struct {
+		s string
+		v sql.Value
+	}{
+		{"coalesce(1, 2.5)", sql.NumericFromInt64(1)},
+		{"coalesce(null, 2, 2.5::double precision)", sql.Float64Value(2)},
+		{"greatest(1, 2.5)", sql.MakeNumericValue(big.NewInt(25), 1)},
+		{"greatest(3, 2.5)", sql.NumericFromInt64(3)},
+		{"least(1, 2.5::double precision)", sql.Float64Value(1)},
+		{"case when true then 1 else 2.5 end", sql.NumericFromInt64(1)},
+		{"case 1 when 1 then 2 else 2.5::double precision end", sql.Float64Value(2)},
+		{"case when false then date '2020-01-02' else timestamp '2020-01-01 00:00' end",
+			sql.TimestampValue(18262 * sql.MicrosecondsPerDay)},
+		{"case when true then date '2020-01-02' else timestamp '2020-01-01 00:00' end",
+			sql.TimestampValue(18263 * sql.MicrosecondsPerDay)},
+		{"coalesce(null, 'abc')", sql.StringValue("abc")},
+	}
+
+	for i, c := range cases {
+		p := parser.NewParser(strings.NewReader(c.s), fmt.Sprintf("cases[%d]", i))
+		e, err := p.ParseExpr()
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed with %s", c.s, err)
+			continue
+		}
+		r, _, err := expr.Compile(context.Background(), nil, nil, nil, e)
+		if err != nil {
+			t.Errorf("Compile(%q) failed with %s", c.s, err)
+			continue
+		}
+		v, err := r.Eval(nil, nil, nil)
+		if err != nil {
+			t.Errorf("Eval(%q) failed with %s", c.s, err)
+		} else if reflect.TypeOf(v) != reflect.TypeOf(c.v) || sql.Format(v) != sql.Format(c.v) {
+			t.Errorf("Eval(%q) got %T(%s) want %T(%s)", c.s, v, sql.Format(v), c.v,
+				sql.Format(c.v))
+		}
+	}
+}
+
 func numberTest(t *testing.T, m, op, n string, b bool) {
 	compareTest(t, m, op, n, b)
 	if !strings.ContainsRune(m, '.') {
@@ -379,6 +560,16 @@ func TestEncode(t *testing.T) {
 		"1 + ghi",
 		"a + b * (abs(c) - 130) + -12",
 		"concat('abc', 'def', 1 + a * 2 - b / 3)",
+		"case when a > 1 then 'x' when b then 'y' else 'z' end",
+		"case a when 1 then b when 2 then null end",
+		"coalesce(a, b, 3) + nullif(c, 0)",
+		"a between b and 3 and d not in (1, 2, 3)",
+		"a like 'x%' or b not ilike c escape '!'",
+		"a similar to 'x|y' and b ~ c and d !~* 'z'",
+		"cast(a as varchar(10)) || b::int::text || c::char(2) || d::bytea",
+		"array[a, 1, null][2] + (b::int[])[c]",
+		"a = any(array[1, 2]) and b > all(c) and 3 = any('{1,2,NULL}')",
+		`concat(json ' {"b":1, "b":2} ', jsonb '[1,  2]')`,
 	}
 
 	ctx := &compileContext{}
@@ -414,3 +605,111 @@ func TestEncode(t *testing.T) {
 		}
 	}
 }
+
+type evalRow []sql.Value
+
+func (er evalRow) EvalRef(idx, nest int) sql.Value {
+	return er[idx]
+}
+
+func TestConstant(t *testing.T) {
+	cases := []struct {
+		s        string
+		constant bool
+	}{
+		{"123", true},
+		{"-123", true},
+		{"'abc' || 'def'", true},
+		{"cast('12' as int) + 3", true},
+		{"coalesce(null, 1)", true},
+		{"i + 1", false},
+		{"unique_rowid()", false},
+		{"abs(unique_rowid())", false},
+	}
+
+	for _, c := range cases {
+		p := parser.NewParser(strings.NewReader(c.s), c.s)
+		e, err := p.ParseExpr()
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed with %s", c.s, err)
+			continue
+		}
+		ce, _, err := expr.Compile(context.Background(), nil, nil, compileCtx{}, e)
+		if err != nil {
+			t.Errorf("Compile(%q) failed with %s", c.s, err)
+			continue
+		}
+		if expr.Constant(ce) != c.constant {
+			t.Errorf("Constant(%q) got %v want %v", c.s, !c.constant, c.constant)
+		}
+	}
+}
+
+func TestMapColumns(t *testing.T) {
+	cases := []struct {
+		s      string
+		colMap []int
+		row    evalRow
+		r      string
+		fail   bool
+	}{
+		{
+			s:      "i + 2",
+			colMap: []int{-1, 0, 1},
+			row:    evalRow{sql.Int64Value(10), sql.StringValue("abc")},
+			r:      "12",
+		},
+		{
+			s:      "f * i",
+			colMap: []int{-1, 0, 1},
+			fail:   true,
+		},
+		{
+			s:      "s || 'def'",
+			colMap: []int{2, 1, 0},
+			row:    evalRow{sql.StringValue("abc"), sql.Int64Value(10), sql.Float64Value(1.5)},
+			r:      "'abcdef'",
+		},
+		{
+			s:      "s like 'a%' and cast(i as double precision) > f",
+			colMap: []int{0, 2, 1},
+			row:    evalRow{sql.Float64Value(1.5), sql.StringValue("abc"), sql.Int64Value(10)},
+			r:      sql.TrueString,
+		},
+		{
+			s:      "s not like 'a%'",
+			colMap: []int{0, 1, -1},
+			fail:   true,
+		},
+	}
+
+	for _, c := range cases {
+		p := parser.NewParser(strings.NewReader(c.s), c.s)
+		e, err := p.ParseExpr()
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed with %s", c.s, err)
+			continue
+		}
+		ce, _, err := expr.Compile(context.Background(), nil, nil, compileCtx{}, e)
+		if err != nil {
+			t.Errorf("Compile(%q) failed with %s", c.s, err)
+			continue
+		}
+		mce, ok := expr.MapColumns(ce, c.colMap)
+		if c.fail {
+			if ok {
+				t.Errorf("MapColumns(%q) did not fail", c.s)
+			}
+			continue
+		} else if !ok {
+			t.Errorf("MapColumns(%q) failed", c.s)
+			continue
+		}
+		v, err := mce.Eval(context.Background(), nil, c.row)
+		if err != nil {
+			t.Errorf("Eval(%q) failed with %s", c.s, err)
+		} else if sql.Format(v) != c.r {
+			t.Errorf("Eval(%q) got %s want %s", c.s, sql.Format(v), c.r)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package expr
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/leftmike/maho/evaluate"
 	"github.com/leftmike/maho/sql"
@@ -25,17 +26,33 @@ const (
 	EqualOp
 	GreaterEqualOp
 	GreaterThanOp
+	ILikeOp
+	JSONContainsOp
+	JSONGetOp
+	JSONGetTextOp
+	JSONHasKeyOp
+	JSONPathOp
+	JSONPathTextOp
 	LessEqualOp
 	LessThanOp
+	LikeOp
 	LShiftOp
 	ModuloOp
 	MultiplyOp
 	NegateOp
 	NoOp
 	NotEqualOp
+	NotILikeOp
+	NotLikeOp
 	NotOp
+	NotRegexpIOp
+	NotRegexpOp
+	NotSimilarOp
 	OrOp
+	RegexpIOp
+	RegexpOp
 	RShiftOp
+	SimilarOp
 	SubtractOp
 )
 
@@ -43,27 +60,43 @@ var ops = [...]struct {
 	name       string
 	precedence int
 }{
-	AddOp:          {"+", 7},
+	AddOp:          {"+", 8},
 	AndOp:          {"AND", 2},
-	BinaryAndOp:    {"&", 6},
-	BinaryOrOp:     {"|", 6},
-	ConcatOp:       {"||", 10},
-	DivideOp:       {"/", 8},
+	BinaryAndOp:    {"&", 7},
+	BinaryOrOp:     {"|", 7},
+	ConcatOp:       {"||", 11},
+	DivideOp:       {"/", 9},
 	EqualOp:        {"==", 4},
 	GreaterEqualOp: {">=", 5},
 	GreaterThanOp:  {">", 5},
+	ILikeOp:        {"ILIKE", 6},
+	JSONContainsOp: {"@>", 7},
+	JSONGetOp:      {"->", 7},
+	JSONGetTextOp:  {"->>", 7},
+	JSONHasKeyOp:   {"?", 7},
+	JSONPathOp:     {"#>", 7},
+	JSONPathTextOp: {"#>>", 7},
 	LessEqualOp:    {"<=", 5},
 	LessThanOp:     {"<", 5},
-	LShiftOp:       {"<<", 6},
-	ModuloOp:       {"%", 8},
-	MultiplyOp:     {"*", 8},
-	NegateOp:       {"-", 9},
-	NoOp:           {"", 11},
+	LikeOp:         {"LIKE", 6},
+	LShiftOp:       {"<<", 7},
+	ModuloOp:       {"%", 9},
+	MultiplyOp:     {"*", 9},
+	NegateOp:       {"-", 10},
+	NoOp:           {"", 12},
 	NotEqualOp:     {"!=", 4},
+	NotILikeOp:     {"NOT ILIKE", 6},
+	NotLikeOp:      {"NOT LIKE", 6},
 	NotOp:          {"NOT", 3},
+	NotRegexpIOp:   {"!~*", 7},
+	NotRegexpOp:    {"!~", 7},
+	NotSimilarOp:   {"NOT SIMILAR TO", 6},
 	OrOp:           {"OR", 1},
-	RShiftOp:       {">>", 6},
-	SubtractOp:     {"-", 7},
+	RegexpIOp:      {"~*", 7},
+	RegexpOp:       {"~", 7},
+	RShiftOp:       {">>", 7},
+	SimilarOp:      {"SIMILAR TO", 6},
+	SubtractOp:     {"-", 8},
 }
 
 func (op Op) Precedence() int {
@@ -79,6 +112,37 @@ type Literal struct {
 }
 
 func (l *Literal) String() string {
+	switch v := l.Value.(type) {
+	case sql.DateValue, sql.TimeValue, sql.TimestampValue, sql.TimestampTZValue,
+		sql.IntervalValue:
+
+		return fmt.Sprintf("%s '%s'", valueDataType(l.Value), l.Value)
+	case sql.NumericValue:
+		// A constant with a decimal point is scanned as a numeric constant.
+		if s := v.String(); strings.Contains(s, ".") {
+			return s
+		}
+		return fmt.Sprintf("NUMERIC '%s'", l.Value)
+	case sql.JSONValue:
+		if _, ok := v.Text(); ok {
+			return fmt.Sprintf("JSON '%s'", strings.ReplaceAll(v.String(), "'", "''"))
+		}
+		return fmt.Sprintf("JSONB '%s'", strings.ReplaceAll(v.String(), "'", "''"))
+	case sql.UUIDValue:
+		return fmt.Sprintf("UUID '%s'", l.Value)
+	case sql.ArrayValue:
+		if len(v) == 0 {
+			return "'{}'"
+		}
+		s := "ARRAY["
+		for i, e := range v {
+			if i > 0 {
+				s += ", "
+			}
+			s += (&Literal{e}).String()
+		}
+		return s + "]"
+	}
 	return sql.Format(l.Value)
 }
 
@@ -114,6 +178,10 @@ func Float64Literal(f float64) *Literal {
 	return &Literal{sql.Float64Value(f)}
 }
 
+func NumericLiteral(n sql.NumericValue) *Literal {
+	return &Literal{n}
+}
+
 func StringLiteral(s string) *Literal {
 	return &Literal{sql.StringValue(s)}
 }
@@ -199,12 +267,17 @@ func (_ Ref) HasRef() bool {
 }
 
 type Call struct {
-	Name sql.Identifier
-	Args []Expr
+	Name     sql.Identifier
+	Distinct bool
+	Args     []Expr
+	Over     *Window
 }
 
 func (c *Call) String() string {
 	s := fmt.Sprintf("%s(", c.Name)
+	if c.Distinct {
+		s += "DISTINCT "
+	}
 	for i, a := range c.Args {
 		if i > 0 {
 			s += ", "
@@ -212,6 +285,9 @@ func (c *Call) String() string {
 		s += a.String()
 	}
 	s += ")"
+	if c.Over != nil {
+		s += fmt.Sprintf(" OVER (%s)", c.Over)
+	}
 	return s
 }
 
@@ -220,7 +296,7 @@ func (c *Call) Equal(e Expr) bool {
 	if !ok {
 		return false
 	}
-	if c.Name != c2.Name || len(c.Args) != len(c2.Args) {
+	if c.Name != c2.Name || c.Distinct != c2.Distinct || len(c.Args) != len(c2.Args) {
 		return false
 	}
 	for i := range c.Args {
@@ -228,7 +304,10 @@ func (c *Call) Equal(e Expr) bool {
 			return false
 		}
 	}
-	return true
+	if c.Over == nil || c2.Over == nil {
+		return c.Over == c2.Over
+	}
+	return c.Over.Equal(c2.Over)
 }
 
 func (c *Call) HasRef() bool {
@@ -237,7 +316,7 @@ func (c *Call) HasRef() bool {
 			return true
 		}
 	}
-	return false
+	return c.Over != nil && c.Over.HasRef()
 }
 
 type SubqueryOp int
@@ -360,3 +439,68 @@ func EqualColExpr(cctx sql.CompileContext, e Expr) []ColExpr {
 	}
 	return nil
 }
+
+// RenameRef returns a copy of e with each single identifier reference to col changed to ncol.
+// Subqueries and windows are not allowed in the expressions of check constraints, so they are
+// returned unchanged.
+func RenameRef(e Expr, col, ncol sql.Identifier) Expr {
+	if e == nil {
+		return nil
+	}
+
+	switch e := e.(type) {
+	case Ref:
+		if len(e) == 1 && e[0] == col {
+			return Ref{ncol}
+		}
+	case *Unary:
+		return &Unary{Op: e.Op, Expr: RenameRef(e.Expr, col, ncol)}
+	case *Binary:
+		return &Binary{
+			Op:    e.Op,
+			Left:  RenameRef(e.Left, col, ncol),
+			Right: RenameRef(e.Right, col, ncol),
+		}
+	case *Call:
+		c := *e
+		c.Args = make([]Expr, 0, len(e.Args))
+		for _, a := range e.Args {
+			c.Args = append(c.Args, RenameRef(a, col, ncol))
+		}
+		return &c
+	case *Cast:
+		return &Cast{Expr: RenameRef(e.Expr, col, ncol), Type: e.Type}
+	case *Case:
+		c := Case{
+			Expr: RenameRef(e.Expr, col, ncol),
+			Else: RenameRef(e.Else, col, ncol),
+		}
+		for _, w := range e.Whens {
+			c.Whens = append(c.Whens,
+				When{
+					Expr:   RenameRef(w.Expr, col, ncol),
+					Result: RenameRef(w.Result, col, ncol),
+				})
+		}
+		return &c
+	case *Array:
+		a := Array{Elems: make([]Expr, 0, len(e.Elems))}
+		for _, el := range e.Elems {
+			a.Elems = append(a.Elems, RenameRef(el, col, ncol))
+		}
+		return &a
+	case *Subscript:
+		return &Subscript{
+			Expr:  RenameRef(e.Expr, col, ncol),
+			Index: RenameRef(e.Index, col, ncol),
+		}
+	case *ArrayCompare:
+		return &ArrayCompare{
+			Op:     e.Op,
+			ExprOp: e.ExprOp,
+			Expr:   RenameRef(e.Expr, col, ncol),
+			Array:  RenameRef(e.Array, col, ncol),
+		}
+	}
+	return e
+}
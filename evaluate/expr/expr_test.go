@@ -127,3 +127,34 @@ func TestEqualColExpr(t *testing.T) {
 		}
 	}
 }
+
+func TestRenameRef(t *testing.T) {
+	cases := []struct {
+		s string
+		r string
+	}{
+		{s: "c1 > 12", r: "(c9 > 12)"},
+		{s: "c2 > 12", r: "(c2 > 12)"},
+		{s: "tbl.c1 > c1", r: "(tbl.c1 > c9)"},
+		{s: "abs(c1) < c2 + c1", r: "(abs(c9) < (c2 + c9))"},
+		{s: "c1::text <> 'c1'", r: "(CAST(c9 AS TEXT) != 'c1')"},
+		{
+			s: "case c1 when 1 then c2 else c1 end > 0",
+			r: "(CASE c9 WHEN 1 THEN c2 ELSE c9 END > 0)",
+		},
+		{s: "c3[c1] = any(array[c1, 2])", r: "c3[c9] == ANY(ARRAY[c9, 2])"},
+	}
+
+	for i, c := range cases {
+		p := parser.NewParser(strings.NewReader(c.s), fmt.Sprintf("%d", i))
+		e, err := p.ParseExpr()
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed with %s", c.s, err)
+			continue
+		}
+		r := RenameRef(e, sql.ID("c1"), sql.ID("c9")).String()
+		if r != c.r {
+			t.Errorf("RenameRef(%q) got %s want %s", c.s, r, c.r)
+		}
+	}
+}
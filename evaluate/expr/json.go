@@ -0,0 +1,315 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leftmike/maho/sql"
+)
+
+var (
+	jsonType  = sql.ColumnType{Type: sql.JSONType}
+	jsonbType = sql.ColumnType{Type: sql.JSONBType}
+)
+
+func isJSONType(dt sql.DataType) bool {
+	return dt == sql.JSONType || dt == sql.JSONBType
+}
+
+// coerceJSONOperands converts string literal operands to jsonb: the left operand of the json
+// operators and both operands of @>; when comparing or concatenating with json or jsonb, the
+// other operand is converted to the same type.
+func coerceJSONOperands(op Op, a1 sql.CExpr, ct1 sql.ColumnType, a2 sql.CExpr,
+	ct2 sql.ColumnType) (sql.CExpr, sql.ColumnType, sql.CExpr, sql.ColumnType, error) {
+
+	left := sql.UnknownType
+	right := sql.UnknownType
+	switch op {
+	case JSONGetOp, JSONGetTextOp, JSONHasKeyOp, JSONPathOp, JSONPathTextOp:
+		left = sql.JSONBType
+	case JSONContainsOp:
+		left = sql.JSONBType
+		right = sql.JSONBType
+	case ConcatOp, EqualOp, NotEqualOp, LessThanOp, LessEqualOp, GreaterThanOp, GreaterEqualOp:
+		if isJSONType(ct2.Type) {
+			left = ct2.Type
+		}
+		if isJSONType(ct1.Type) {
+			right = ct1.Type
+		}
+	}
+
+	var err error
+	if left != sql.UnknownType && ct1.Type == sql.StringType {
+		a1, ct1, err = coerceLiteral(a1, ct1, left)
+		if err != nil {
+			return nil, ct1, nil, ct2, err
+		}
+	}
+	if right != sql.UnknownType && ct2.Type == sql.StringType {
+		a2, ct2, err = coerceLiteral(a2, ct2, right)
+		if err != nil {
+			return nil, ct1, nil, ct2, err
+		}
+	}
+	return a1, ct1, a2, ct2, nil
+}
+
+func jsonArg(v sql.Value) (sql.JSONValue, error) {
+	switch v := v.(type) {
+	case sql.JSONValue:
+		return v, nil
+	case sql.StringValue:
+		j, err := sql.ParseJSON(string(v))
+		if err != nil {
+			return sql.JSONValue{}, fmt.Errorf("engine: %s", err)
+		}
+		return j, nil
+	}
+	return sql.JSONValue{}, fmt.Errorf("engine: want json got %v", v)
+}
+
+// valueToJSON converts v to json: numbers and booleans are converted to the same json types, and
+// other values are converted to strings.
+func valueToJSON(v sql.Value) sql.JSONValue {
+	switch v := v.(type) {
+	case nil:
+		return sql.JSONNullValue()
+	case sql.JSONValue:
+		return v
+	case sql.BoolValue:
+		return sql.JSONBoolValue(bool(v))
+	case sql.Int64Value:
+		return sql.JSONNumberValue(sql.NumericFromInt64(int64(v)))
+	case sql.Float64Value:
+		n, err := sql.NumericFromFloat64(float64(v))
+		if err == nil {
+			return sql.JSONNumberValue(n)
+		}
+	case sql.NumericValue:
+		return sql.JSONNumberValue(v)
+	case sql.StringValue:
+		return sql.JSONStringValue(string(v))
+	case sql.BytesValue:
+		return sql.JSONStringValue(string(v.HexBytes()))
+	}
+	return sql.JSONStringValue(v.String())
+}
+
+// jsonText returns j as text: strings are not quoted and the json null is NULL.
+func jsonText(j sql.JSONValue) sql.Value {
+	switch j.Kind() {
+	case sql.JSONNull:
+		return nil
+	case sql.JSONString:
+		return sql.StringValue(j.Str())
+	}
+	return sql.StringValue(j.String())
+}
+
+// parseJSONPath parses a path in the form of a text array, such as '{a,b,0}'; elements may be
+// double quoted.
+func parseJSONPath(s string) ([]string, error) {
+	t := strings.TrimSpace(s)
+	if len(t) < 2 || t[0] != '{' || t[len(t)-1] != '}' {
+		return nil, fmt.Errorf("engine: invalid json path: %s", s)
+	}
+	t = strings.TrimSpace(t[1 : len(t)-1])
+	if t == "" {
+		return nil, nil
+	}
+
+	var path []string
+	for {
+		var elem string
+		if t != "" && t[0] == '"' {
+			var sb strings.Builder
+			i := 1
+			for ; i < len(t) && t[i] != '"'; i++ {
+				if t[i] == '\\' && i+1 < len(t) {
+					i += 1
+				}
+				sb.WriteByte(t[i])
+			}
+			if i == len(t) {
+				return nil, fmt.Errorf("engine: invalid json path: %s", s)
+			}
+			elem = sb.String()
+			t = strings.TrimSpace(t[i+1:])
+		} else {
+			i := strings.IndexByte(t, ',')
+			if i < 0 {
+				i = len(t)
+			}
+			elem = strings.TrimSpace(t[:i])
+			if elem == "" || strings.ContainsAny(elem, "{}\"") {
+				return nil, fmt.Errorf("engine: invalid json path: %s", s)
+			}
+			t = t[i:]
+		}
+		path = append(path, elem)
+
+		if t == "" {
+			return path, nil
+		} else if t[0] != ',' {
+			return nil, fmt.Errorf("engine: invalid json path: %s", s)
+		}
+		t = strings.TrimSpace(t[1:])
+	}
+}
+
+func jsonGet(args []sql.Value) (sql.JSONValue, bool, error) {
+	j, err := jsonArg(args[0])
+	if err != nil {
+		return sql.JSONValue{}, false, err
+	}
+
+	switch a1 := args[1].(type) {
+	case sql.StringValue:
+		v, ok := j.Member(string(a1))
+		return v, ok, nil
+	case sql.Int64Value:
+		v, ok := j.Index(int(a1))
+		return v, ok, nil
+	}
+	return sql.JSONValue{}, false, fmt.Errorf("engine: want string or integer got %v", args[1])
+}
+
+func jsonGetCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j, ok, err := jsonGet(args)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return j, nil
+}
+
+func jsonGetTextCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j, ok, err := jsonGet(args)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return jsonText(j), nil
+}
+
+func jsonPath(args []sql.Value) (sql.JSONValue, bool, error) {
+	j, err := jsonArg(args[0])
+	if err != nil {
+		return sql.JSONValue{}, false, err
+	}
+	s, ok := args[1].(sql.StringValue)
+	if !ok {
+		return sql.JSONValue{}, false, fmt.Errorf("engine: want string got %v", args[1])
+	}
+	path, err := parseJSONPath(string(s))
+	if err != nil {
+		return sql.JSONValue{}, false, err
+	}
+	j, ok = j.Path(path)
+	return j, ok, nil
+}
+
+func jsonPathCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j, ok, err := jsonPath(args)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return j, nil
+}
+
+func jsonPathTextCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j, ok, err := jsonPath(args)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return jsonText(j), nil
+}
+
+func jsonContainsCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j0, err := jsonArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	j1, err := jsonArg(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return sql.BoolValue(j0.Contains(j1)), nil
+}
+
+func jsonHasKeyCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j, err := jsonArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	s, ok := args[1].(sql.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: want string got %v", args[1])
+	}
+	return sql.BoolValue(j.HasKey(string(s))), nil
+}
+
+// concatOpCall concatenates two json values, or otherwise, concatenates the arguments as
+// strings.
+func concatOpCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j0, ok0 := args[0].(sql.JSONValue)
+	j1, ok1 := args[1].(sql.JSONValue)
+	if ok0 && ok1 {
+		return j0.Concat(j1), nil
+	} else if (ok0 && args[1] == nil) || (ok1 && args[0] == nil) {
+		return nil, nil
+	}
+	return concatCall(ctx, args)
+}
+
+func concatType(args []sql.ColumnType) sql.ColumnType {
+	if isJSONType(args[0].Type) || isJSONType(args[1].Type) {
+		return jsonbType
+	}
+	return stringType
+}
+
+// jsonGetType returns json for json and jsonb for jsonb: the same type as the first argument.
+func jsonGetType(args []sql.ColumnType) sql.ColumnType {
+	if args[0].Type == sql.JSONType {
+		return jsonType
+	}
+	return jsonbType
+}
+
+func jsonBuildObjectCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("engine: jsonb_build_object: requires an even number of arguments")
+	}
+
+	var keys []string
+	var vals []sql.JSONValue
+	for adx := 0; adx < len(args); adx += 2 {
+		switch key := args[adx].(type) {
+		case nil:
+			return nil, fmt.Errorf("engine: jsonb_build_object: argument %d: key must not be null",
+				adx+1)
+		case sql.JSONValue:
+			if key.Kind() == sql.JSONString {
+				keys = append(keys, key.Str())
+			} else {
+				keys = append(keys, key.String())
+			}
+		default:
+			s, err := concatCall(ctx, []sql.Value{key})
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, string(s.(sql.StringValue)))
+		}
+		vals = append(vals, valueToJSON(args[adx+1]))
+	}
+	return sql.JSONObjectValue(keys, vals), nil
+}
+
+func jsonTypeofCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	j, err := jsonArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return sql.StringValue(j.Kind().String()), nil
+}
@@ -0,0 +1,356 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/leftmike/maho/sql"
+)
+
+type matchKind int
+
+const (
+	likeMatch matchKind = iota
+	similarMatch
+	regexpMatch
+)
+
+var (
+	matchOps = map[Op]struct {
+		kind matchKind
+		fold bool
+		not  bool
+	}{
+		LikeOp:       {kind: likeMatch},
+		NotLikeOp:    {kind: likeMatch, not: true},
+		ILikeOp:      {kind: likeMatch, fold: true},
+		NotILikeOp:   {kind: likeMatch, fold: true, not: true},
+		SimilarOp:    {kind: similarMatch},
+		NotSimilarOp: {kind: similarMatch, not: true},
+		RegexpOp:     {kind: regexpMatch},
+		NotRegexpOp:  {kind: regexpMatch, not: true},
+		RegexpIOp:    {kind: regexpMatch, fold: true},
+		NotRegexpIOp: {kind: regexpMatch, fold: true, not: true},
+	}
+
+	matchFuncs = map[*callFunc]Op{}
+)
+
+func likeToRegexp(pattern string) (string, error) {
+	var buf strings.Builder
+	buf.WriteString("^(?s:")
+	escape := false
+	for _, r := range pattern {
+		if escape {
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+			escape = false
+			continue
+		}
+
+		switch r {
+		case '\\':
+			escape = true
+		case '%':
+			buf.WriteString(".*")
+		case '_':
+			buf.WriteRune('.')
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escape {
+		return "", errors.New("engine: LIKE pattern must not end with escape character")
+	}
+	buf.WriteString(")$")
+	return buf.String(), nil
+}
+
+func similarToRegexp(pattern string) (string, error) {
+	var buf strings.Builder
+	buf.WriteString("^(?s:")
+	escape := false
+	bracket := false
+	for _, r := range pattern {
+		if escape {
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+			escape = false
+			continue
+		} else if bracket {
+			if r == ']' {
+				bracket = false
+			}
+			buf.WriteRune(r)
+			continue
+		}
+
+		switch r {
+		case '\\':
+			escape = true
+		case '%':
+			buf.WriteString(".*")
+		case '_':
+			buf.WriteRune('.')
+		case '.', '^', '$':
+			// Not metacharacters in SIMILAR TO patterns.
+			buf.WriteRune('\\')
+			buf.WriteRune(r)
+		case '[':
+			bracket = true
+			buf.WriteRune(r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	if escape {
+		return "", errors.New("engine: SIMILAR TO pattern must not end with escape character")
+	}
+	buf.WriteString(")$")
+	return buf.String(), nil
+}
+
+func compilePattern(op Op, pattern string) (*regexp.Regexp, error) {
+	mo := matchOps[op]
+
+	var re string
+	var err error
+	switch mo.kind {
+	case likeMatch:
+		re, err = likeToRegexp(pattern)
+	case similarMatch:
+		re, err = similarToRegexp(pattern)
+	case regexpMatch:
+		re = pattern
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mo.fold {
+		re = "(?i)" + re
+	}
+
+	rexp, err := regexp.Compile(re)
+	if err != nil {
+		return nil, fmt.Errorf("engine: invalid pattern %s: %s", sql.StringValue(pattern), err)
+	}
+	return rexp, nil
+}
+
+// likePrefix returns the characters which every string matching the LIKE pattern must start
+// with.
+func likePrefix(pattern string) string {
+	var buf strings.Builder
+	escape := false
+	for _, r := range pattern {
+		if escape {
+			escape = false
+		} else if r == '\\' {
+			escape = true
+			continue
+		} else if r == '%' || r == '_' {
+			break
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+type ColPrefix struct {
+	Col    int
+	Prefix string
+}
+
+// LikePrefixColExpr returns the columns which must start with a constant prefix for e to be
+// true: col LIKE 'prefix%', possibly combined with other expressions using AND.
+func LikePrefixColExpr(cctx sql.CompileContext, e Expr) []ColPrefix {
+	be, ok := e.(*Binary)
+	if !ok {
+		return nil
+	}
+
+	if be.Op == AndOp {
+		return append(LikePrefixColExpr(cctx, be.Left), LikePrefixColExpr(cctx, be.Right)...)
+	} else if be.Op != LikeOp {
+		return nil
+	}
+
+	r, ok := be.Left.(Ref)
+	if !ok {
+		return nil
+	}
+	l, ok := be.Right.(*Literal)
+	if !ok {
+		return nil
+	}
+	s, ok := l.Value.(sql.StringValue)
+	if !ok {
+		return nil
+	}
+	col, nest, ct, err := cctx.CompileRef(r)
+	if nest > 0 || err != nil || ct.Type != sql.StringType {
+		return nil
+	}
+	prefix := likePrefix(string(s))
+	if prefix == "" {
+		return nil
+	}
+	return []ColPrefix{{col, prefix}}
+}
+
+func makeMatchCall(op Op) func(ectx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	return func(ectx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+		s, ok := args[0].(sql.StringValue)
+		if !ok {
+			return nil, fmt.Errorf("engine: want string got %v", args[0])
+		}
+		p, ok := args[1].(sql.StringValue)
+		if !ok {
+			return nil, fmt.Errorf("engine: want string got %v", args[1])
+		}
+		re, err := compilePattern(op, string(p))
+		if err != nil {
+			return nil, err
+		}
+		return sql.BoolValue(re.MatchString(string(s)) != matchOps[op].not), nil
+	}
+}
+
+type lastPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// matchExpr is a compiled pattern matching operator. A constant pattern is compiled once, when
+// the expression is compiled. Otherwise, the most recently used pattern is kept, so that
+// patterns which are parameters are only compiled once per statement execution.
+type matchExpr struct {
+	op      Op
+	expr    sql.CExpr
+	pattern sql.CExpr
+	re      *regexp.Regexp
+	last    atomic.Value
+}
+
+func makeMatchExpr(op Op, e, pattern sql.CExpr) (*matchExpr, error) {
+	me := &matchExpr{
+		op:      op,
+		expr:    e,
+		pattern: pattern,
+	}
+	if l, ok := pattern.(*Literal); ok {
+		if s, ok := l.Value.(sql.StringValue); ok {
+			var err error
+			me.re, err = compilePattern(op, string(s))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return me, nil
+}
+
+func compileMatch(op Op, a1 sql.CExpr, ct1 sql.ColumnType, a2 sql.CExpr,
+	ct2 sql.ColumnType) (sql.CExpr, sql.ColumnType, error) {
+
+	for _, ct := range []sql.ColumnType{ct1, ct2} {
+		if ct.Type != sql.StringType && ct.Type != sql.UnknownType {
+			return nil, boolType,
+				fmt.Errorf("engine: %s: want %s got %s", op, sql.StringType, ct.Type)
+		}
+	}
+	me, err := makeMatchExpr(op, a1, a2)
+	if err != nil {
+		return nil, boolType, err
+	}
+	return me, boolType, nil
+}
+
+func (me *matchExpr) String() string {
+	return fmt.Sprintf("%s %s %s", me.expr, me.op, me.pattern)
+}
+
+func (me *matchExpr) compiledPattern(ctx context.Context, tx sql.Transaction,
+	ectx sql.EvalContext) (*regexp.Regexp, error) {
+
+	if me.re != nil {
+		return me.re, nil
+	}
+
+	val, err := me.pattern.Eval(ctx, tx, ectx)
+	if err != nil || val == nil {
+		return nil, err
+	}
+	s, ok := val.(sql.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: want string got %v", val)
+	}
+	if lp, ok := me.last.Load().(*lastPattern); ok && lp.pattern == string(s) {
+		return lp.re, nil
+	}
+
+	re, err := compilePattern(me.op, string(s))
+	if err != nil {
+		return nil, err
+	}
+	me.last.Store(&lastPattern{pattern: string(s), re: re})
+	return re, nil
+}
+
+func (me *matchExpr) Eval(ctx context.Context, tx sql.Transaction,
+	ectx sql.EvalContext) (sql.Value, error) {
+
+	val, err := me.expr.Eval(ctx, tx, ectx)
+	if err != nil || val == nil {
+		return nil, err
+	}
+	s, ok := val.(sql.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: want string got %v", val)
+	}
+
+	re, err := me.compiledPattern(ctx, tx, ectx)
+	if err != nil || re == nil {
+		return nil, err
+	}
+	return sql.BoolValue(re.MatchString(string(s)) != matchOps[me.op].not), nil
+}
+
+// likeEscapeCall rewrites a LIKE or SIMILAR TO pattern with an ESCAPE character to use the
+// default escape character of backslash.
+func likeEscapeCall(ctx sql.EvalContext, args []sql.Value) (sql.Value, error) {
+	p, ok := args[0].(sql.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: want string got %v", args[0])
+	}
+	e, ok := args[1].(sql.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("engine: want string got %v", args[1])
+	}
+	esc := []rune(string(e))
+	if len(esc) > 1 {
+		return nil, fmt.Errorf("engine: invalid escape string %s: must be empty or one character",
+			e)
+	} else if len(esc) == 1 && esc[0] == '\\' {
+		return p, nil
+	}
+
+	var buf strings.Builder
+	escape := false
+	for _, r := range p {
+		if escape {
+			buf.WriteRune(r)
+			escape = false
+		} else if len(esc) == 1 && r == esc[0] {
+			buf.WriteRune('\\')
+			escape = true
+		} else if r == '\\' {
+			buf.WriteString(`\\`)
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+	return sql.StringValue(buf.String()), nil
+}
@@ -0,0 +1,183 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/sql"
+)
+
+// parseSequenceName parses the name of a sequence as passed to nextval, currval, and setval:
+// [[database '.'] schema '.'] sequence, where each part may be double quoted.
+func parseSequenceName(s string) (sql.TableName, error) {
+	nam := s
+	var ids []sql.Identifier
+	for {
+		if strings.HasPrefix(s, `"`) {
+			n := strings.IndexByte(s[1:], '"')
+			if n <= 0 {
+				return sql.TableName{}, fmt.Errorf("engine: invalid sequence name: %s", nam)
+			}
+			ids = append(ids, sql.QuotedID(s[1:n+1]))
+			s = s[n+2:]
+		} else {
+			n := strings.IndexByte(s, '.')
+			if n < 0 {
+				n = len(s)
+			}
+			if n == 0 {
+				return sql.TableName{}, fmt.Errorf("engine: invalid sequence name: %s", nam)
+			}
+			ids = append(ids, sql.ID(s[:n]))
+			s = s[n:]
+		}
+
+		if s == "" {
+			break
+		} else if s[0] != '.' {
+			return sql.TableName{}, fmt.Errorf("engine: invalid sequence name: %s", nam)
+		}
+		s = s[1:]
+	}
+
+	switch len(ids) {
+	case 1:
+		return sql.TableName{Table: ids[0]}, nil
+	case 2:
+		return sql.TableName{Schema: ids[0], Table: ids[1]}, nil
+	case 3:
+		return sql.TableName{Database: ids[0], Schema: ids[1], Table: ids[2]}, nil
+	}
+	return sql.TableName{}, fmt.Errorf("engine: invalid sequence name: %s", nam)
+}
+
+func formatSequenceID(id sql.Identifier) string {
+	s := id.String()
+	if strings.ToLower(s) != s || strings.ContainsAny(s, `."`) {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// FormatSequenceName returns a string for tn which parseSequenceName parses back into tn.
+func FormatSequenceName(tn sql.TableName) string {
+	s := formatSequenceID(tn.Table)
+	if tn.Schema != 0 {
+		s = formatSequenceID(tn.Schema) + "." + s
+		if tn.Database != 0 {
+			s = formatSequenceID(tn.Database) + "." + s
+		}
+	}
+	return s
+}
+
+// resolveSequenceName makes a literal sequence name fully qualified when the expression is
+// compiled, so that it refers to the same sequence wherever the expression is evaluated.
+func resolveSequenceName(pctx evaluate.PlanContext, arg sql.CExpr) (sql.CExpr, error) {
+	l, ok := arg.(*Literal)
+	if !ok || pctx == nil {
+		return arg, nil
+	}
+	s, ok := l.Value.(sql.StringValue)
+	if !ok {
+		return arg, nil
+	}
+	tn, err := parseSequenceName(string(s))
+	if err != nil {
+		return nil, err
+	}
+	return &Literal{sql.StringValue(FormatSequenceName(pctx.ResolveTableName(tn)))}, nil
+}
+
+func evalSequenceName(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	arg sql.CExpr) (sql.TableName, bool, error) {
+
+	val, err := arg.Eval(ctx, tx, ectx)
+	if err != nil {
+		return sql.TableName{}, false, err
+	} else if val == nil {
+		return sql.TableName{}, false, nil
+	}
+	s, ok := val.(sql.StringValue)
+	if !ok {
+		return sql.TableName{}, false,
+			fmt.Errorf("engine: want string for sequence name got %v", val)
+	}
+	tn, err := parseSequenceName(string(s))
+	if err != nil {
+		return sql.TableName{}, false, err
+	}
+	if tn.Database == 0 {
+		return sql.TableName{}, false,
+			fmt.Errorf("engine: sequence name must be fully qualified: %s", s)
+	}
+	return tn, true, nil
+}
+
+func nextValCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	tn, ok, err := evalSequenceName(ctx, tx, ectx, args[0])
+	if !ok {
+		return nil, err
+	}
+	val, err := tx.NextSequenceValue(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.Int64Value(val), nil
+}
+
+func currValCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	tn, ok, err := evalSequenceName(ctx, tx, ectx, args[0])
+	if !ok {
+		return nil, err
+	}
+	val, err := tx.CurrentSequenceValue(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.Int64Value(val), nil
+}
+
+func setValCall(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	args []sql.CExpr) (sql.Value, error) {
+
+	tn, ok, err := evalSequenceName(ctx, tx, ectx, args[0])
+	if !ok {
+		return nil, err
+	}
+
+	vals := make([]sql.Value, len(args)-1)
+	for adx, a := range args[1:] {
+		vals[adx], err = a.Eval(ctx, tx, ectx)
+		if err != nil {
+			return nil, err
+		} else if vals[adx] == nil {
+			return nil, nil
+		}
+	}
+
+	val, ok := vals[0].(sql.Int64Value)
+	if !ok {
+		return nil, fmt.Errorf("engine: want integer got %v", vals[0])
+	}
+	called := true
+	if len(vals) > 1 {
+		b, ok := vals[1].(sql.BoolValue)
+		if !ok {
+			return nil, fmt.Errorf("engine: want boolean got %v", vals[1])
+		}
+		called = bool(b)
+	}
+
+	err = tx.SetSequenceValue(ctx, tn, int64(val), called)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
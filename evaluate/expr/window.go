@@ -0,0 +1,341 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/sql"
+)
+
+type FrameMode int
+
+const (
+	RowsFrame FrameMode = iota
+	RangeFrame
+)
+
+type FrameBoundType int
+
+const (
+	UnboundedPreceding FrameBoundType = iota
+	OffsetPreceding
+	CurrentRow
+	OffsetFollowing
+	UnboundedFollowing
+)
+
+type FrameBound struct {
+	Type   FrameBoundType
+	Offset Expr
+}
+
+type WindowFrame struct {
+	Mode  FrameMode
+	Start FrameBound
+	End   FrameBound
+}
+
+type OrderBy struct {
+	Expr    Expr
+	Reverse bool
+}
+
+type Window struct {
+	Partition []Expr
+	OrderBy   []OrderBy
+	Frame     *WindowFrame
+}
+
+func (fb FrameBound) String() string {
+	switch fb.Type {
+	case UnboundedPreceding:
+		return "UNBOUNDED PRECEDING"
+	case OffsetPreceding:
+		return fmt.Sprintf("%s PRECEDING", fb.Offset)
+	case CurrentRow:
+		return "CURRENT ROW"
+	case OffsetFollowing:
+		return fmt.Sprintf("%s FOLLOWING", fb.Offset)
+	case UnboundedFollowing:
+		return "UNBOUNDED FOLLOWING"
+	default:
+		panic(fmt.Sprintf("unexpected frame bound type: %d", fb.Type))
+	}
+}
+
+func (fb FrameBound) Equal(fb2 FrameBound) bool {
+	if fb.Type != fb2.Type {
+		return false
+	}
+	if fb.Offset == nil || fb2.Offset == nil {
+		return fb.Offset == nil && fb2.Offset == nil
+	}
+	return fb.Offset.Equal(fb2.Offset)
+}
+
+func (wf *WindowFrame) String() string {
+	s := "ROWS"
+	if wf.Mode == RangeFrame {
+		s = "RANGE"
+	}
+	return fmt.Sprintf("%s BETWEEN %s AND %s", s, wf.Start, wf.End)
+}
+
+func (w *Window) String() string {
+	var s string
+	if w.Partition != nil {
+		s += "PARTITION BY "
+		for i, e := range w.Partition {
+			if i > 0 {
+				s += ", "
+			}
+			s += e.String()
+		}
+	}
+	if w.OrderBy != nil {
+		if s != "" {
+			s += " "
+		}
+		s += "ORDER BY "
+		for i, by := range w.OrderBy {
+			if i > 0 {
+				s += ", "
+			}
+			s += by.Expr.String()
+			if by.Reverse {
+				s += " DESC"
+			} else {
+				s += " ASC"
+			}
+		}
+	}
+	if w.Frame != nil {
+		if s != "" {
+			s += " "
+		}
+		s += w.Frame.String()
+	}
+	return s
+}
+
+func (w *Window) Equal(w2 *Window) bool {
+	if len(w.Partition) != len(w2.Partition) || len(w.OrderBy) != len(w2.OrderBy) {
+		return false
+	}
+	for i := range w.Partition {
+		if !w.Partition[i].Equal(w2.Partition[i]) {
+			return false
+		}
+	}
+	for i := range w.OrderBy {
+		if w.OrderBy[i].Reverse != w2.OrderBy[i].Reverse ||
+			!w.OrderBy[i].Expr.Equal(w2.OrderBy[i].Expr) {
+			return false
+		}
+	}
+	if w.Frame == nil || w2.Frame == nil {
+		return w.Frame == w2.Frame
+	}
+	return w.Frame.Mode == w2.Frame.Mode && w.Frame.Start.Equal(w2.Frame.Start) &&
+		w.Frame.End.Equal(w2.Frame.End)
+}
+
+func (w *Window) HasRef() bool {
+	for _, e := range w.Partition {
+		if e.HasRef() {
+			return true
+		}
+	}
+	for _, by := range w.OrderBy {
+		if by.Expr.HasRef() {
+			return true
+		}
+	}
+	return false
+}
+
+// WindowRows is the sorted partition of rows that a window function is evaluated over. Rows
+// are identified by their position in the partition.
+type WindowRows interface {
+	// NumRows returns the number of rows in the partition.
+	NumRows() int
+
+	// Current returns the position of the row the window function is being evaluated for.
+	Current() int
+
+	// Frame returns the positions of the first and one past the last rows in the frame of the
+	// current row.
+	Frame() (int, int)
+
+	// Peers returns the positions of the first and one past the last rows which are equal to
+	// the current row according to the ORDER BY of the window.
+	Peers() (int, int)
+
+	// PeerGroup returns the number of groups of peers before the current row.
+	PeerGroup() int
+
+	// Args returns the arguments to the window function evaluated for a row.
+	Args(ctx context.Context, row int) ([]sql.Value, error)
+}
+
+type WindowFunc func(ctx context.Context, wr WindowRows) (sql.Value, error)
+
+type windowContext interface {
+	CompileWindow(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, c *Call,
+		fn WindowFunc, maker MakeAggregator, args []sql.CExpr) (int, error)
+	WindowArgContext() sql.CompileContext
+}
+
+func compileWindow(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, c *Call, cf *callFunc) (sql.CExpr, sql.ColumnType, error) {
+
+	var ct sql.ColumnType
+	if cf.windowFunc == nil && cf.makeAggregator == nil {
+		return nil, ct, fmt.Errorf("engine: OVER specified, but \"%s\" is not a window function",
+			c.Name)
+	}
+	if c.Distinct {
+		return nil, ct,
+			fmt.Errorf("engine: DISTINCT is not supported for window functions: \"%s\"", c.Name)
+	}
+	wctx, ok := cctx.(windowContext)
+	if !ok {
+		return nil, ct, fmt.Errorf("engine: window function \"%s\" not allowed here", c.Name)
+	}
+
+	// The arguments are compiled as aggregates when the window function is evaluated over the
+	// groups of a GROUP BY.
+	actx := wctx.WindowArgContext()
+	_, agg := actx.(aggregatorContext)
+	args := make([]sql.CExpr, len(c.Args))
+	argTypes := make([]sql.ColumnType, len(c.Args))
+	for i, a := range c.Args {
+		var err error
+		args[i], argTypes[i], err = compile(ctx, pctx, tx, actx, a, agg)
+		if err != nil {
+			return nil, ct, err
+		}
+	}
+	if cf.tfn != nil {
+		ct = cf.tfn(argTypes)
+	} else {
+		ct = cf.typ
+	}
+
+	idx, err := wctx.CompileWindow(ctx, pctx, tx, c, cf.windowFunc, cf.makeAggregator, args)
+	if err != nil {
+		return nil, ct, err
+	}
+	return &colRef{idx: idx, ref: Ref{c.Name}}, ct, nil
+}
+
+func firstArgType(args []sql.ColumnType) sql.ColumnType {
+	ct := args[0]
+	ct.NotNull = false
+	return ct
+}
+
+func rowNumberWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	return sql.Int64Value(wr.Current() + 1), nil
+}
+
+func rankWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	first, _ := wr.Peers()
+	return sql.Int64Value(first + 1), nil
+}
+
+func denseRankWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	return sql.Int64Value(wr.PeerGroup() + 1), nil
+}
+
+func ntileWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	args, err := wr.Args(ctx, wr.Current())
+	if err != nil {
+		return nil, err
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+	i, ok := args[0].(sql.Int64Value)
+	if !ok {
+		return nil, fmt.Errorf("engine: ntile: expected integer got %v", args[0])
+	} else if i <= 0 {
+		return nil, fmt.Errorf("engine: ntile: argument must be greater than zero: %d", i)
+	}
+
+	// The first (rows % buckets) buckets have one more row than the rest.
+	buckets := int(i)
+	rows := wr.NumRows() / buckets
+	extra := wr.NumRows() % buckets
+	cur := wr.Current()
+	if cur < extra*(rows+1) {
+		return sql.Int64Value(cur/(rows+1) + 1), nil
+	}
+	return sql.Int64Value(extra + (cur-extra*(rows+1))/rows + 1), nil
+}
+
+func offsetWindow(ctx context.Context, wr WindowRows, dir int) (sql.Value, error) {
+	args, err := wr.Args(ctx, wr.Current())
+	if err != nil {
+		return nil, err
+	}
+
+	off := 1
+	if len(args) > 1 {
+		if args[1] == nil {
+			return nil, nil
+		}
+		i, ok := args[1].(sql.Int64Value)
+		if !ok {
+			return nil, fmt.Errorf("engine: expected integer offset got %v", args[1])
+		}
+		off = int(i)
+	}
+	var def sql.Value
+	if len(args) > 2 {
+		def = args[2]
+	}
+
+	row := wr.Current() + off*dir
+	if row < 0 || row >= wr.NumRows() {
+		return def, nil
+	}
+	args, err = wr.Args(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return args[0], nil
+}
+
+func lagWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	return offsetWindow(ctx, wr, -1)
+}
+
+func leadWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	return offsetWindow(ctx, wr, 1)
+}
+
+func firstValueWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	first, last := wr.Frame()
+	if first >= last {
+		return nil, nil
+	}
+	args, err := wr.Args(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+	return args[0], nil
+}
+
+func lastValueWindow(ctx context.Context, wr WindowRows) (sql.Value, error) {
+	first, last := wr.Frame()
+	if first >= last {
+		return nil, nil
+	}
+	args, err := wr.Args(ctx, last-1)
+	if err != nil {
+		return nil, err
+	}
+	return args[0], nil
+}
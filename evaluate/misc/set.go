@@ -22,7 +22,7 @@ func (stmt *Set) String() string {
 func (stmt *Set) Plan(ctx context.Context, pctx evaluate.PlanContext,
 	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
 
-	ses, ok := pctx.(*evaluate.Session)
+	ses, ok := evaluate.SessionContext(pctx)
 	if !ok {
 		return nil, errors.New("engine: show not allowed here")
 	}
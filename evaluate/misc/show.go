@@ -21,7 +21,7 @@ func (stmt *Show) String() string {
 func (stmt *Show) Plan(ctx context.Context, pctx evaluate.PlanContext,
 	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
 
-	ses, ok := pctx.(*evaluate.Session)
+	ses, ok := evaluate.SessionContext(pctx)
 	if !ok {
 		return nil, errors.New("engine: show not allowed here")
 	}
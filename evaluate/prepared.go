@@ -10,8 +10,51 @@ import (
 )
 
 type prepareContext struct {
-	pctx   PlanContext
-	params []*sql.Value
+	pctx       PlanContext
+	params     []*sql.Value
+	paramTypes []sql.ColumnType
+}
+
+// PlanContextWrapper is implemented by plan contexts which wrap another plan context.
+type PlanContextWrapper interface {
+	WrappedPlanContext() PlanContext
+}
+
+// SessionContext returns the session underlying pctx, if there is one.
+func SessionContext(pctx PlanContext) (*Session, bool) {
+	if pcw, ok := pctx.(PlanContextWrapper); ok {
+		return SessionContext(pcw.WrappedPlanContext())
+	}
+	ses, ok := pctx.(*Session)
+	return ses, ok
+}
+
+// SetParameterType records the type of parameter num, if it is not already known, while a
+// statement is being prepared. Otherwise, it does nothing.
+func SetParameterType(pctx PlanContext, num int, ct sql.ColumnType) {
+	if ct.Type == sql.UnknownType {
+		return
+	}
+	prep, ok := pctx.(*prepareContext)
+	if !ok {
+		if pcw, ok := pctx.(PlanContextWrapper); ok {
+			SetParameterType(pcw.WrappedPlanContext(), num, ct)
+		}
+		return
+	}
+
+	if num > len(prep.paramTypes) {
+		ntypes := make([]sql.ColumnType, num)
+		copy(ntypes, prep.paramTypes)
+		prep.paramTypes = ntypes
+	}
+	if prep.paramTypes[num-1].Type == sql.UnknownType {
+		prep.paramTypes[num-1] = ct
+	}
+}
+
+func (prep *prepareContext) WrappedPlanContext() PlanContext {
+	return prep.pctx
 }
 
 func (prep *prepareContext) GetFlag(f flags.Flag) bool {
@@ -45,7 +88,7 @@ func (prep *prepareContext) PlanParameter(num int) (*sql.Value, error) {
 }
 
 func (prep *prepareContext) GetPreparedPlan(nam sql.Identifier) PreparedPlan {
-	panic("unexpected, should never be called")
+	return prep.pctx.GetPreparedPlan(nam)
 }
 
 func PreparePlan(ctx context.Context, stmt Stmt, pctx PlanContext,
@@ -63,37 +106,52 @@ func PreparePlan(ctx context.Context, stmt Stmt, pctx PlanContext,
 		}
 	}
 
-	return MakePreparedPlan(plan, prep.params), nil
+	paramTypes := make([]sql.ColumnType, len(prep.params))
+	copy(paramTypes, prep.paramTypes)
+	return MakePreparedPlan(plan, prep.params, paramTypes), nil
 }
 
 type PreparedPlan interface {
 	Plan
 	SetParameters(params []sql.Value) error
+	ParameterTypes() []sql.ColumnType
 }
 
 type PreparedStmtPlan struct {
-	plan   StmtPlan
-	params []*sql.Value
+	plan       StmtPlan
+	params     []*sql.Value
+	paramTypes []sql.ColumnType
 }
 
 type PreparedRowsPlan struct {
-	plan   RowsPlan
-	params []*sql.Value
+	plan       RowsPlan
+	params     []*sql.Value
+	paramTypes []sql.ColumnType
 }
 
-func MakePreparedPlan(plan Plan, params []*sql.Value) PreparedPlan {
+type PreparedCmdPlan struct {
+	plan CmdPlan
+}
+
+func MakePreparedPlan(plan Plan, params []*sql.Value, paramTypes []sql.ColumnType) PreparedPlan {
 	if sp, ok := plan.(StmtPlan); ok {
 		return &PreparedStmtPlan{
-			plan:   sp,
-			params: params,
+			plan:       sp,
+			params:     params,
+			paramTypes: paramTypes,
 		}
 	} else if rp, ok := plan.(RowsPlan); ok {
 		return &PreparedRowsPlan{
-			plan:   rp,
-			params: params,
+			plan:       rp,
+			params:     params,
+			paramTypes: paramTypes,
+		}
+	} else if cp, ok := plan.(CmdPlan); ok {
+		return &PreparedCmdPlan{
+			plan: cp,
 		}
 	} else {
-		panic(fmt.Sprintf("expected a stmt or rows plan; got %#v", plan))
+		panic(fmt.Sprintf("expected a stmt, rows, or cmd plan; got %#v", plan))
 	}
 }
 
@@ -116,6 +174,10 @@ func (psp *PreparedStmtPlan) SetParameters(params []sql.Value) error {
 	return setParameters(psp.params, params)
 }
 
+func (psp *PreparedStmtPlan) ParameterTypes() []sql.ColumnType {
+	return psp.paramTypes
+}
+
 func (psp *PreparedStmtPlan) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
 	return psp.plan.Execute(ctx, tx)
 }
@@ -128,6 +190,10 @@ func (prp *PreparedRowsPlan) SetParameters(params []sql.Value) error {
 	return setParameters(prp.params, params)
 }
 
+func (prp *PreparedRowsPlan) ParameterTypes() []sql.ColumnType {
+	return prp.paramTypes
+}
+
 func (prp *PreparedRowsPlan) Columns() []sql.Identifier {
 	return prp.plan.Columns()
 }
@@ -141,3 +207,19 @@ func (prp *PreparedRowsPlan) Rows(ctx context.Context, tx sql.Transaction,
 
 	return prp.plan.Rows(ctx, tx, ectx)
 }
+
+func (pcp *PreparedCmdPlan) Tag() string {
+	return pcp.plan.Tag()
+}
+
+func (pcp *PreparedCmdPlan) SetParameters(params []sql.Value) error {
+	return setParameters(nil, params)
+}
+
+func (pcp *PreparedCmdPlan) ParameterTypes() []sql.ColumnType {
+	return nil
+}
+
+func (pcp *PreparedCmdPlan) Command(ctx context.Context, ses *Session, e sql.Engine) error {
+	return pcp.plan.Command(ctx, ses, e)
+}
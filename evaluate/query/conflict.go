@@ -0,0 +1,396 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+var excludedTable = sql.ID("excluded")
+
+// OnConflict is the ON CONFLICT clause of an INSERT statement; without any ColumnUpdates, it
+// is DO NOTHING.
+type OnConflict struct {
+	Columns       []sql.Identifier
+	Constraint    sql.Identifier
+	ColumnUpdates []ColumnUpdate
+	Where         expr.Expr
+}
+
+func (oc *OnConflict) String() string {
+	s := " ON CONFLICT"
+	if oc.Columns != nil {
+		s += " ("
+		for i, col := range oc.Columns {
+			if i > 0 {
+				s += ", "
+			}
+			s += col.String()
+		}
+		s += ")"
+	} else if oc.Constraint != 0 {
+		s += fmt.Sprintf(" ON CONSTRAINT %s", oc.Constraint)
+	}
+
+	if oc.ColumnUpdates == nil {
+		return s + " DO NOTHING"
+	}
+
+	s += " DO UPDATE SET "
+	for i, cu := range oc.ColumnUpdates {
+		if i > 0 {
+			s += ", "
+		}
+		if cu.Expr == nil {
+			s += fmt.Sprintf("%s = DEFAULT", cu.Column)
+		} else {
+			s += fmt.Sprintf("%s = %s", cu.Column, cu.Expr)
+		}
+	}
+	if oc.Where != nil {
+		s += fmt.Sprintf(" WHERE %s", oc.Where)
+	}
+	return s
+}
+
+// arbiter is a unique key used to detect conflicts: either the primary key or a unique index.
+type arbiter struct {
+	iidx int // -1 for the primary key
+	key  []sql.ColumnKey
+}
+
+func sameColumns(key []sql.ColumnKey, cols map[int]struct{}) bool {
+	if len(key) != len(cols) {
+		return false
+	}
+	for _, ck := range key {
+		if _, ok := cols[ck.Column()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (oc *OnConflict) arbiters(tn sql.TableName, tt sql.TableType) ([]arbiter, error) {
+	var arbs []arbiter
+	if oc.Columns != nil {
+		cmap := map[sql.Identifier]int{}
+		for cdx, col := range tt.Columns() {
+			cmap[col] = cdx
+		}
+		cols := map[int]struct{}{}
+		for _, col := range oc.Columns {
+			cdx, ok := cmap[col]
+			if !ok {
+				return nil, fmt.Errorf("engine: %s: column not found: %s", tn, col)
+			}
+			cols[cdx] = struct{}{}
+		}
+
+		if sameColumns(tt.PrimaryKey(), cols) {
+			arbs = append(arbs, arbiter{iidx: -1, key: tt.PrimaryKey()})
+		}
+		for iidx, it := range tt.Indexes() {
+			if it.Unique && sameColumns(it.Key, cols) {
+				arbs = append(arbs, arbiter{iidx: iidx, key: it.Key})
+			}
+		}
+		if len(arbs) == 0 {
+			return nil,
+				fmt.Errorf("engine: %s: no unique constraint matching ON CONFLICT columns", tn)
+		}
+	} else if oc.Constraint != 0 {
+		if oc.Constraint == sql.PRIMARY_QUOTED {
+			arbs = append(arbs, arbiter{iidx: -1, key: tt.PrimaryKey()})
+		} else {
+			for iidx, it := range tt.Indexes() {
+				if it.Unique && !it.Hidden && it.Name == oc.Constraint {
+					arbs = append(arbs, arbiter{iidx: iidx, key: it.Key})
+					break
+				}
+			}
+			if len(arbs) == 0 {
+				return nil, fmt.Errorf("engine: %s: unique constraint %s not found", tn,
+					oc.Constraint)
+			}
+		}
+	} else if oc.ColumnUpdates != nil {
+		return nil, fmt.Errorf("engine: %s: ON CONFLICT DO UPDATE requires columns or a constraint",
+			tn)
+	} else {
+		arbs = append(arbs, arbiter{iidx: -1, key: tt.PrimaryKey()})
+		for iidx, it := range tt.Indexes() {
+			if it.Unique {
+				arbs = append(arbs, arbiter{iidx: iidx, key: it.Key})
+			}
+		}
+	}
+	return arbs, nil
+}
+
+// conflictPlan inserts rows one at a time, checking each arbiter for an existing row with the
+// same key. The expressions of DO UPDATE are evaluated with the existing row followed by the
+// row proposed for insertion, which is available as the EXCLUDED table.
+type conflictPlan struct {
+	tn       sql.TableName
+	cols     []sql.Identifier
+	colTypes []sql.ColumnType
+	arbiters []arbiter
+	where    sql.CExpr
+	updates  []columnUpdate
+}
+
+func (oc *OnConflict) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName, tt sql.TableType) (*conflictPlan, error) {
+
+	arbs, err := oc.arbiters(tn, tt)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := tt.Columns()
+	colTypes := tt.ColumnTypes()
+	cp := conflictPlan{
+		tn:       tn,
+		cols:     cols,
+		colTypes: colTypes,
+		arbiters: arbs,
+	}
+	if oc.ColumnUpdates == nil {
+		return &cp, nil
+	}
+
+	tctx := makeFromContext(tn.Table, cols, colTypes, nil)
+	fctx := joinContextsOn(tctx, makeFromContext(excludedTable, cols, colTypes, nil))
+	if oc.Where != nil {
+		var ct sql.ColumnType
+		cp.where, ct, err = expr.Compile(ctx, pctx, tx, fctx, oc.Where)
+		if err != nil {
+			return nil, err
+		}
+		if ct.Type != sql.BooleanType {
+			return nil, fmt.Errorf("engine: WHERE must be boolean expression: %s", oc.Where)
+		}
+	}
+
+	colDefaults := tt.ColumnDefaults()
+	for _, cu := range oc.ColumnUpdates {
+		col, ok := tctx.lookupColumn(cu.Column)
+		if !ok {
+			return nil, fmt.Errorf("engine: table %s: column %s not found", tn, cu.Column)
+		}
+
+		var ce sql.CExpr
+		if cu.Expr != nil {
+			if colDefaults[col].Always {
+				return nil, fmt.Errorf("engine: table %s: column %s can only be updated to DEFAULT",
+					tn, cu.Column)
+			}
+			if p, ok := cu.Expr.(expr.Param); ok {
+				evaluate.SetParameterType(pctx, p.Num, colTypes[col])
+			}
+			ce, _, err = expr.Compile(ctx, pctx, tx, fctx, cu.Expr)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			ce = colDefaults[col].Default
+		}
+		cp.updates = append(cp.updates, columnUpdate{column: col, expr: ce})
+	}
+	return &cp, nil
+}
+
+type conflictTable struct {
+	plan *conflictPlan
+	tbl  sql.Table
+	// Keys seen so far in this statement, for each arbiter: rows written by the statement
+	// might not be visible to it.
+	seen []map[string]struct{}
+	row  []sql.Value
+	rr   *returnedRows
+}
+
+func (cp *conflictPlan) start(tbl sql.Table, rr *returnedRows) *conflictTable {
+	seen := make([]map[string]struct{}, len(cp.arbiters))
+	for adx := range seen {
+		seen[adx] = map[string]struct{}{}
+	}
+	return &conflictTable{
+		plan: cp,
+		tbl:  tbl,
+		seen: seen,
+		row:  make([]sql.Value, len(cp.cols)*2),
+		rr:   rr,
+	}
+}
+
+func (ct *conflictTable) EvalRef(idx, nest int) sql.Value {
+	if nest != 0 {
+		panic(fmt.Sprintf("table %s: nested reference in insert on conflict", ct.plan.tn))
+	}
+	return ct.row[idx]
+}
+
+// seenKey returns a string for the key of the row; keys containing NULL never conflict.
+func seenKey(key []sql.ColumnKey, row []sql.Value) (string, bool) {
+	var buf strings.Builder
+	for _, ck := range key {
+		val := row[ck.Column()]
+		if val == nil {
+			return "", false
+		}
+		buf.WriteString(strconv.Quote(val.String()))
+		buf.WriteByte(',')
+	}
+	return buf.String(), true
+}
+
+// lookup returns the existing row, if any, with the same key as row, positioned so that it
+// can be updated. The existing row is in the first half of ct.row.
+func (ct *conflictTable) lookup(ctx context.Context, arb arbiter,
+	row []sql.Value) (sql.Rows, error) {
+
+	dest := ct.row[:len(row)]
+	if arb.iidx < 0 {
+		return lookupKey(ctx, ct.tbl, arb.key, row, dest)
+	}
+
+	keyRow := make([]sql.Value, len(row))
+	for _, ck := range arb.key {
+		keyRow[ck.Column()] = row[ck.Column()]
+	}
+
+	ir, err := ct.tbl.IndexRows(ctx, arb.iidx, keyRow, keyRow)
+	if err != nil {
+		return nil, err
+	}
+	err = ir.Next(ctx, make([]sql.Value, ir.NumColumns()))
+	if err == nil {
+		err = ir.Row(ctx, dest)
+	}
+	if err != nil {
+		ir.Close()
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ir, nil
+}
+
+// insert returns true if the row was either inserted or an existing row was updated.
+func (ct *conflictTable) insert(ctx context.Context, tx sql.Transaction,
+	row []sql.Value) (bool, error) {
+
+	cp := ct.plan
+	for cdx, colType := range cp.colTypes {
+		if row[cdx] != nil {
+			var err error
+			row[cdx], err = sql.ConvertColumnValue(colType, row[cdx])
+			if err != nil {
+				return false, fmt.Errorf("engine: table %s: column %s: %s", cp.tn, cp.cols[cdx],
+					err)
+			}
+		}
+	}
+
+	keys := make([]string, len(cp.arbiters))
+	for adx, arb := range cp.arbiters {
+		key, ok := seenKey(arb.key, row)
+		if !ok {
+			continue
+		}
+		if _, ok := ct.seen[adx][key]; ok {
+			if cp.updates == nil {
+				return false, nil
+			}
+			return false, fmt.Errorf(
+				"engine: table %s: ON CONFLICT DO UPDATE cannot affect row a second time", cp.tn)
+		}
+		keys[adx] = key
+
+		rows, err := ct.lookup(ctx, arb, row)
+		if err != nil {
+			return false, err
+		} else if rows != nil {
+			defer rows.Close()
+			if cp.updates == nil {
+				return false, nil
+			}
+			return ct.update(ctx, tx, rows, row)
+		}
+	}
+
+	err := ct.tbl.Insert(ctx, [][]sql.Value{row})
+	if err != nil {
+		return false, err
+	}
+	ct.rr.add(row)
+	for adx, key := range keys {
+		if key != "" {
+			ct.seen[adx][key] = struct{}{}
+		}
+	}
+	return true, nil
+}
+
+func (ct *conflictTable) update(ctx context.Context, tx sql.Transaction, rows sql.Rows,
+	row []sql.Value) (bool, error) {
+
+	cp := ct.plan
+	copy(ct.row[len(row):], row)
+	if cp.where != nil {
+		val, err := cp.where.Eval(ctx, tx, ct)
+		if err != nil {
+			return false, err
+		}
+		if b, ok := val.(sql.BoolValue); !ok || !bool(b) {
+			return false, nil
+		}
+	}
+
+	newRow := append(make([]sql.Value, 0, len(row)), ct.row[:len(row)]...)
+	var updates []sql.ColumnUpdate
+	for _, update := range cp.updates {
+		col := update.column
+
+		var val sql.Value
+		if update.expr != nil {
+			var err error
+			val, err = update.expr.Eval(ctx, tx, ct)
+			if err != nil {
+				return false, err
+			}
+		}
+		if sql.Compare(val, ct.row[col]) != 0 {
+			updates = append(updates, sql.ColumnUpdate{Column: col, Value: val})
+		}
+	}
+
+	if len(updates) > 0 {
+		err := rows.Update(ctx, updates)
+		if err != nil {
+			return false, err
+		}
+		for _, update := range updates {
+			newRow[update.Column] = update.Value
+		}
+	}
+	ct.rr.add(newRow)
+
+	for adx, arb := range cp.arbiters {
+		for _, r := range [][]sql.Value{ct.row[:len(row)], newRow} {
+			if key, ok := seenKey(arb.key, r); ok {
+				ct.seen[adx][key] = struct{}{}
+			}
+		}
+	}
+	return true, nil
+}
@@ -11,15 +11,23 @@ import (
 )
 
 type Delete struct {
-	Table sql.TableName
-	Where expr.Expr
+	Table     sql.TableName
+	Using     FromItem
+	Where     expr.Expr
+	Returning *Returning
 }
 
 func (stmt *Delete) String() string {
 	s := fmt.Sprintf("DELETE FROM %s", stmt.Table)
+	if stmt.Using != nil {
+		s += fmt.Sprintf(" USING %s", stmt.Using)
+	}
 	if stmt.Where != nil {
 		s += fmt.Sprintf(" WHERE %s", stmt.Where)
 	}
+	if stmt.Returning != nil {
+		s += stmt.Returning.String()
+	}
 	return s
 }
 
@@ -27,6 +35,12 @@ type deletePlan struct {
 	tn    sql.TableName
 	ttVer int64
 	where sql.CExpr
+
+	// DELETE ... USING: the table joined with the other tables; the columns of the table are
+	// first in each row.
+	rop     rowsOp
+	key     []sql.ColumnKey
+	numCols int
 }
 
 func (stmt *Delete) Plan(ctx context.Context, pctx evaluate.PlanContext,
@@ -38,11 +52,21 @@ func (stmt *Delete) Plan(ctx context.Context, pctx evaluate.PlanContext,
 		return nil, err
 	}
 
-	var where sql.CExpr
-	if stmt.Where != nil {
+	plan := &deletePlan{
+		tn:    tn,
+		ttVer: tt.Version(),
+	}
+	fctx := makeFromContext(tn.Table, tt.Columns(), tt.ColumnTypes(), nil)
+	if stmt.Using != nil {
+		plan.rop, fctx, err = joinTable(ctx, pctx, tx, tn, stmt.Using, stmt.Where)
+		if err != nil {
+			return nil, err
+		}
+		plan.key = tt.PrimaryKey()
+		plan.numCols = len(tt.Columns())
+	} else if stmt.Where != nil {
 		var ct sql.ColumnType
-		where, ct, err = expr.Compile(ctx, pctx, tx,
-			makeFromContext(tn.Table, tt.Columns(), tt.ColumnTypes(), nil), stmt.Where)
+		plan.where, ct, err = expr.Compile(ctx, pctx, tx, fctx, stmt.Where)
 		if err != nil {
 			return nil, err
 		}
@@ -50,7 +74,11 @@ func (stmt *Delete) Plan(ctx context.Context, pctx evaluate.PlanContext,
 			return nil, fmt.Errorf("engine: WHERE must be boolean expression: %s", stmt.Where)
 		}
 	}
-	return &deletePlan{tn, tt.Version(), where}, nil
+
+	if stmt.Returning != nil {
+		return stmt.Returning.plan(ctx, pctx, tx, fctx, plan)
+	}
+	return plan, nil
 }
 
 func (_ *deletePlan) Tag() string {
@@ -58,10 +86,19 @@ func (_ *deletePlan) Tag() string {
 }
 
 func (dp *deletePlan) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	return dp.modify(ctx, tx, nil)
+}
+
+func (dp *deletePlan) modify(ctx context.Context, tx sql.Transaction,
+	rr *returnedRows) (int64, error) {
+
 	tbl, err := tx.LookupTable(ctx, dp.tn, dp.ttVer)
 	if err != nil {
 		return -1, err
 	}
+	if dp.rop != nil {
+		return dp.modifyUsing(ctx, tx, tbl, rr)
+	}
 
 	rows, err := tbl.Rows(ctx, nil, nil)
 	if err != nil {
@@ -85,6 +122,51 @@ func (dp *deletePlan) Execute(ctx context.Context, tx sql.Transaction) (int64, e
 		if err != nil {
 			return cnt, err
 		}
+		rr.add(dest)
+		cnt += 1
+	}
+}
+
+func (dp *deletePlan) modifyUsing(ctx context.Context, tx sql.Transaction, tbl sql.Table,
+	rr *returnedRows) (int64, error) {
+
+	rows, err := dp.rop.rows(ctx, tx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer rows.Close()
+
+	seen := map[string]struct{}{}
+	dest := make([]sql.Value, rows.NumColumns())
+	cur := make([]sql.Value, dp.numCols)
+	var cnt int64
+	for {
+		err := rows.Next(ctx, dest)
+		if err == io.EOF {
+			return cnt, nil
+		} else if err != nil {
+			return cnt, err
+		}
+
+		key, _ := seenKey(dp.key, dest)
+		if _, ok := seen[key]; ok {
+			return cnt, fmt.Errorf("engine: table %s: row matched more than once", dp.tn)
+		}
+		seen[key] = struct{}{}
+
+		trows, err := lookupKey(ctx, tbl, dp.key, dest, cur)
+		if err != nil {
+			return cnt, err
+		} else if trows == nil {
+			return cnt, fmt.Errorf("engine: table %s: internal error: missing row to delete",
+				dp.tn)
+		}
+		err = trows.Delete(ctx)
+		trows.Close()
+		if err != nil {
+			return cnt, err
+		}
+		rr.add(dest)
 		cnt += 1
 	}
 }
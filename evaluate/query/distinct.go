@@ -0,0 +1,196 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+type distinctOp struct {
+	rop rowsOp
+	on  []sql.CExpr
+}
+
+func (_ distinctOp) Name() string {
+	return "distinct"
+}
+
+func (do distinctOp) Columns() []string {
+	return do.rop.Columns()
+}
+
+func (do distinctOp) Fields() []evaluate.FieldDescription {
+	var fd []evaluate.FieldDescription
+	for _, ce := range do.on {
+		fd = append(fd, evaluate.FieldDescription{Field: "on", Description: ce.String()})
+	}
+	return fd
+}
+
+func (do distinctOp) Children() []evaluate.ExplainTree {
+	return []evaluate.ExplainTree{do.rop}
+}
+
+func (do distinctOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext) (sql.Rows,
+	error) {
+
+	r, err := do.rop.rows(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &distinctRows{
+		tx:   tx,
+		ectx: ectx,
+		rows: r,
+		on:   do.on,
+		seen: map[string]struct{}{},
+	}, nil
+}
+
+type distinctRows struct {
+	tx   sql.Transaction
+	ectx sql.EvalContext
+	rows sql.Rows
+	on   []sql.CExpr
+	seen map[string]struct{}
+	dest []sql.Value
+}
+
+func (dr *distinctRows) EvalRef(idx, nest int) sql.Value {
+	if nest > 0 {
+		return dr.ectx.EvalRef(idx, nest-1)
+	}
+	return dr.dest[idx]
+}
+
+func (dr *distinctRows) NumColumns() int {
+	return dr.rows.NumColumns()
+}
+
+func (dr *distinctRows) Close() error {
+	return dr.rows.Close()
+}
+
+func (dr *distinctRows) key(ctx context.Context, dest []sql.Value) (string, error) {
+	if dr.on == nil {
+		return sql.HashKey(dest), nil
+	}
+
+	dr.dest = dest
+	defer func() {
+		dr.dest = nil
+	}()
+	vals := make([]sql.Value, len(dr.on))
+	for idx, ce := range dr.on {
+		val, err := ce.Eval(ctx, dr.tx, dr)
+		if err != nil {
+			return "", err
+		}
+		vals[idx] = val
+	}
+	return sql.HashKey(vals), nil
+}
+
+func (dr *distinctRows) Next(ctx context.Context, dest []sql.Value) error {
+	for {
+		err := dr.rows.Next(ctx, dest)
+		if err != nil {
+			return err
+		}
+
+		key, err := dr.key(ctx, dest)
+		if err != nil {
+			return err
+		}
+		if _, ok := dr.seen[key]; !ok {
+			dr.seen[key] = struct{}{}
+			return nil
+		}
+	}
+}
+
+func (_ *distinctRows) Delete(ctx context.Context) error {
+	return fmt.Errorf("distinct rows may not be deleted")
+}
+
+func (_ *distinctRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return fmt.Errorf("distinct rows may not be updated")
+}
+
+// checkDistinctOn checks that the initial ORDER BY expressions match the DISTINCT ON expressions,
+// in any order, so that the first row of each set of duplicates is well defined.
+func checkDistinctOn(on []expr.Expr, order []OrderBy) error {
+	for odx, by := range order {
+		if odx == len(on) {
+			break
+		}
+
+		var match bool
+		for _, e := range on {
+			if e.String() == by.Expr.String() {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return fmt.Errorf(
+				"engine: SELECT DISTINCT ON expressions must match initial ORDER BY expressions")
+		}
+	}
+	return nil
+}
+
+func compileDistinctOn(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, on []expr.Expr) ([]sql.CExpr, error) {
+
+	var ces []sql.CExpr
+	for _, e := range on {
+		ce, _, err := expr.Compile(ctx, pctx, tx, cctx, e)
+		if err != nil {
+			return nil, err
+		}
+		ces = append(ces, ce)
+	}
+	return ces, nil
+}
+
+// distinct removes duplicate rows, keeping the first of each; with DISTINCT ON, rows are
+// duplicates when the on expressions are equal. The on expressions are compiled against the
+// output columns and then, if fctx is not nil, against the input columns.
+func distinct(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, rop rowsOp,
+	cols []sql.Identifier, colTypes []sql.ColumnType, fctx *fromContext,
+	on []expr.Expr) (rowsOp, error) {
+
+	if on == nil {
+		return distinctOp{rop: rop}, nil
+	}
+
+	ces, err := compileDistinctOn(ctx, pctx, tx, makeFromContext(0, cols, colTypes, nil), on)
+	if err == nil {
+		return distinctOp{rop: rop, on: ces}, nil
+	} else if fctx == nil {
+		return nil, err
+	}
+
+	switch ro := rop.(type) {
+	case *resultsOp:
+		ces, err = compileDistinctOn(ctx, pctx, tx, fctx, on)
+		if err != nil {
+			return nil, err
+		}
+		ro.rop = distinctOp{rop: ro.rop, on: ces}
+		return ro, nil
+	case *allResultsOp:
+		ces, err = compileDistinctOn(ctx, pctx, tx, fctx, on)
+		if err != nil {
+			return nil, err
+		}
+		ro.rop = distinctOp{rop: ro.rop, on: ces}
+		return ro, nil
+	}
+	return nil, fmt.Errorf("engine: DISTINCT ON expressions must match ORDER BY expressions")
+}
@@ -29,8 +29,59 @@ func (fta FromTableAlias) String() string {
 	return s
 }
 
+// keyValue converts a literal to the type of a key column, so that it is encoded in the same way
+// as the values of the column. A string literal is converted in the same way that a string
+// literal compared to a column of that type is converted when compiling an expression. A number
+// is converted to the type of a numeric column only if the conversion is exact; otherwise, false
+// is returned and the key is not used.
+func keyValue(ct sql.ColumnType, val sql.Value) (sql.Value, bool) {
+	switch val := val.(type) {
+	case sql.StringValue:
+		if ct.Array {
+			v, err := sql.ConvertArray(ct, val)
+			return v, err == nil
+		}
+		switch ct.Type {
+		case sql.DateType, sql.TimeType, sql.TimestampType, sql.TimestampTZType,
+			sql.IntervalType, sql.NumericType, sql.JSONType, sql.UUIDType, sql.JSONBType:
+
+			v, err := sql.ConvertValue(ct.Type, val)
+			return v, err == nil
+		}
+	case sql.Int64Value:
+		switch ct.Type {
+		case sql.FloatType:
+			return sql.Float64Value(val), true
+		case sql.NumericType:
+			return sql.NumericFromInt64(int64(val)), true
+		}
+	case sql.Float64Value:
+		switch ct.Type {
+		case sql.IntegerType:
+			i := sql.Int64Value(val)
+			return i, sql.Float64Value(i) == val
+		case sql.NumericType:
+			// Numeric values are compared to floats as floats.
+			return val, false
+		}
+	case sql.NumericValue:
+		switch ct.Type {
+		case sql.IntegerType:
+			i, ok := val.Int64()
+			if !ok {
+				return val, false
+			}
+			cmp, _ := val.Compare(sql.Int64Value(i))
+			return sql.Int64Value(i), cmp == 0
+		case sql.FloatType:
+			return sql.Float64Value(val.Float64()), true
+		}
+	}
+	return val, true
+}
+
 func equalKeyExpr(fctx sql.CompileContext, cond expr.Expr, key []sql.ColumnKey,
-	cols []int) []expr.ColExpr {
+	cols []int, colTypes []sql.ColumnType) []expr.ColExpr {
 
 	ce := expr.EqualColExpr(fctx, cond)
 	if len(key) != len(ce) {
@@ -43,6 +94,16 @@ func equalKeyExpr(fctx sql.CompileContext, cond expr.Expr, key []sql.ColumnKey,
 		}
 	}
 
+	for cdx := range ce {
+		if ce[cdx].Param <= 0 {
+			var ok bool
+			ce[cdx].Val, ok = keyValue(colTypes[ce[cdx].Col], ce[cdx].Val)
+			if !ok {
+				return nil
+			}
+		}
+	}
+
 	for _, ck := range key {
 		found := false
 		for cdx := range ce {
@@ -59,23 +120,86 @@ func equalKeyExpr(fctx sql.CompileContext, cond expr.Expr, key []sql.ColumnKey,
 	return ce
 }
 
+// keyPrefix is a range of rows whose key column starts with a prefix.
+type keyPrefix struct {
+	col    int
+	prefix string
+}
+
+// prefixKeyExpr returns the prefix which the first column of key must start with for cond to be
+// true, if any. Only ascending key columns are supported.
+func prefixKeyExpr(fctx sql.CompileContext, cond expr.Expr, key []sql.ColumnKey,
+	cols []int) *keyPrefix {
+
+	if len(key) == 0 || key[0].Reverse() {
+		return nil
+	}
+
+	for _, cp := range expr.LikePrefixColExpr(fctx, cond) {
+		col := cp.Col
+		if cols != nil {
+			col = cols[col]
+		}
+		if col == key[0].Column() {
+			return &keyPrefix{col: col, prefix: cp.Prefix}
+		}
+	}
+	return nil
+}
+
+// keyRows returns the minimum and maximum rows for the range. Strings are UTF-8, which never
+// contains 0xFF, so every string starting with the prefix is less than the prefix followed by
+// 0xFF.
+func (kp *keyPrefix) keyRows(numCols int) ([]sql.Value, []sql.Value) {
+	minRow := make([]sql.Value, numCols)
+	minRow[kp.col] = sql.StringValue(kp.prefix)
+	maxRow := make([]sql.Value, numCols)
+	maxRow[kp.col] = sql.StringValue(kp.prefix + "\xff")
+	return minRow, maxRow
+}
+
+func (kp *keyPrefix) field(cols []sql.Identifier) evaluate.FieldDescription {
+	return evaluate.FieldDescription{
+		Field:       "prefix",
+		Description: fmt.Sprintf("%s: %s", cols[kp.col], sql.StringValue(kp.prefix)),
+	}
+}
+
 func (fta FromTableAlias) plan(ctx context.Context, pctx evaluate.PlanContext,
 	tx sql.Transaction, cctx sql.CompileContext, cond expr.Expr) (rowsOp, *fromContext, error) {
 
-	tn := pctx.ResolveTableName(fta.TableName)
-	tt, err := tx.LookupTableType(ctx, tn)
-	if err != nil {
-		return nil, nil, err
+	if fta.Database == 0 && fta.Schema == 0 {
+		if ct, ok := lookupCommonTable(pctx, fta.Table); ok {
+			nam := fta.Table
+			if fta.Alias != 0 {
+				nam = fta.Alias
+			}
+			return ct.plan(ctx, pctx, tx, cctx, cond, nam)
+		}
 	}
 
+	tn := pctx.ResolveTableName(fta.TableName)
 	nam := tn.Table
 	if fta.Alias != 0 {
 		nam = fta.Alias
 	}
+
+	vt, err := tx.LookupView(ctx, tn)
+	if err != nil {
+		return nil, nil, err
+	} else if vt != nil {
+		return planView(ctx, pctx, tx, cctx, cond, tn, vt, nam)
+	}
+
+	tt, err := tx.LookupTableType(ctx, tn)
+	if err != nil {
+		return nil, nil, err
+	}
 	fctx := makeFromContext(nam, tt.Columns(), tt.ColumnTypes(), cctx)
 
 	if cond != nil && pctx.GetFlag(flags.PushdownWhere) {
-		if colExpr := equalKeyExpr(fctx, cond, tt.PrimaryKey(), nil); colExpr != nil {
+		if colExpr := equalKeyExpr(fctx, cond, tt.PrimaryKey(), nil,
+			tt.ColumnTypes()); colExpr != nil {
 			valKey := make([]*sql.Value, len(tt.Columns()))
 			for _, ce := range colExpr {
 				if ce.Param > 0 {
@@ -83,6 +207,7 @@ func (fta FromTableAlias) plan(ctx context.Context, pctx evaluate.PlanContext,
 					if err != nil {
 						return nil, nil, err
 					}
+					evaluate.SetParameterType(pctx, ce.Param, tt.ColumnTypes()[ce.Col])
 					valKey[ce.Col] = ptr
 				} else {
 					val := ce.Val
@@ -99,8 +224,11 @@ func (fta FromTableAlias) plan(ctx context.Context, pctx evaluate.PlanContext,
 		}
 	}
 
-	rop, err := where(ctx, pctx, tx, scanTableOp{tn: tn, ttVer: tt.Version(), cols: tt.Columns()},
-		fctx, cond)
+	sto := scanTableOp{tn: tn, ttVer: tt.Version(), cols: tt.Columns()}
+	if cond != nil && pctx.GetFlag(flags.PushdownWhere) {
+		sto.prefix = prefixKeyExpr(fctx, cond, tt.PrimaryKey(), nil)
+	}
+	rop, err := where(ctx, pctx, tx, sto, fctx, cond)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -113,6 +241,7 @@ type scanTableOp struct {
 	ttVer  int64
 	cols   []sql.Identifier
 	valKey []*sql.Value
+	prefix *keyPrefix
 }
 
 func (sto scanTableOp) Name() string {
@@ -148,6 +277,8 @@ func (sto scanTableOp) Fields() []evaluate.FieldDescription {
 
 	if sto.valKey != nil {
 		fd = append(fd, filterField(sto.valKey, sto.cols))
+	} else if sto.prefix != nil {
+		fd = append(fd, sto.prefix.field(sto.cols))
 	}
 	return fd
 }
@@ -164,6 +295,11 @@ func (sto scanTableOp) rows(ctx context.Context, tx sql.Transaction,
 		return nil, err
 	}
 
+	if sto.prefix != nil {
+		minRow, maxRow := sto.prefix.keyRows(len(sto.cols))
+		return tbl.Rows(ctx, minRow, maxRow)
+	}
+
 	var keyRow []sql.Value
 	if sto.valKey != nil {
 		keyRow = make([]sql.Value, len(sto.cols))
@@ -235,7 +371,8 @@ func (fia FromIndexAlias) plan(ctx context.Context, pctx evaluate.PlanContext,
 	}
 
 	if cond != nil && pctx.GetFlag(flags.PushdownWhere) {
-		if colExpr := equalKeyExpr(fctx, cond, it.Key, it.Columns); colExpr != nil {
+		if colExpr := equalKeyExpr(fctx, cond, it.Key, it.Columns,
+			ttColTypes); colExpr != nil {
 			valKey := make([]*sql.Value, len(tt.Columns()))
 			for _, ce := range colExpr {
 				if ce.Param > 0 {
@@ -243,6 +380,7 @@ func (fia FromIndexAlias) plan(ctx context.Context, pctx evaluate.PlanContext,
 					if err != nil {
 						return nil, nil, err
 					}
+					evaluate.SetParameterType(pctx, ce.Param, ttColTypes[ce.Col])
 					valKey[ce.Col] = ptr
 				} else {
 					val := ce.Val
@@ -254,6 +392,11 @@ func (fia FromIndexAlias) plan(ctx context.Context, pctx evaluate.PlanContext,
 			sio.ttCols = ttCols
 			return sio, fctx, nil
 		}
+
+		sio.prefix = prefixKeyExpr(fctx, cond, it.Key, it.Columns)
+		if sio.prefix != nil {
+			sio.ttCols = ttCols
+		}
 	}
 
 	rop, err := where(ctx, pctx, tx, sio, fctx, cond)
@@ -271,6 +414,7 @@ type scanIndexOp struct {
 	cols   []sql.Identifier
 	ttCols []sql.Identifier
 	valKey []*sql.Value
+	prefix *keyPrefix
 }
 
 func (sio scanIndexOp) Name() string {
@@ -293,6 +437,8 @@ func (sio scanIndexOp) Fields() []evaluate.FieldDescription {
 
 	if sio.valKey != nil {
 		fd = append(fd, filterField(sio.valKey, sio.ttCols))
+	} else if sio.prefix != nil {
+		fd = append(fd, sio.prefix.field(sio.ttCols))
 	}
 	return fd
 }
@@ -309,6 +455,11 @@ func (sio scanIndexOp) rows(ctx context.Context, tx sql.Transaction,
 		return nil, err
 	}
 
+	if sio.prefix != nil {
+		minRow, maxRow := sio.prefix.keyRows(len(sio.ttCols))
+		return tbl.IndexRows(ctx, sio.iidx, minRow, maxRow)
+	}
+
 	var keyRow []sql.Value
 	if sio.valKey != nil {
 		keyRow = make([]sql.Value, len(sio.ttCols))
@@ -349,23 +500,33 @@ func (fs FromStmt) plan(ctx context.Context, pctx evaluate.PlanContext,
 	if err != nil {
 		return nil, nil, err
 	}
-	rowsPlan := plan.(evaluate.RowsPlan)
+	rowsPlan, ok := plan.(evaluate.RowsPlan)
+	if !ok {
+		return nil, nil, fmt.Errorf("engine: expected rows: %s", fs.Stmt)
+	}
+	return fromRowsPlan(ctx, pctx, tx, cctx, cond, rowsPlan, fs.Stmt.String(), fs.Alias,
+		fs.ColumnAliases)
+}
+
+func fromRowsPlan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, cond expr.Expr, rowsPlan evaluate.RowsPlan, stmt string,
+	nam sql.Identifier, colAliases []sql.Identifier) (rowsOp, *fromContext, error) {
 
 	cols := rowsPlan.Columns()
-	if fs.ColumnAliases != nil {
-		if len(fs.ColumnAliases) != len(cols) {
+	if colAliases != nil {
+		if len(colAliases) != len(cols) {
 			return nil, nil, fmt.Errorf("engine: wrong number of column aliases")
 		}
-		cols = fs.ColumnAliases
+		cols = colAliases
 	}
-	fctx := makeFromContext(fs.Alias, cols, rowsPlan.ColumnTypes(), cctx)
+	fctx := makeFromContext(nam, cols, rowsPlan.ColumnTypes(), cctx)
 
 	if cond == nil {
 		if rp, ok := rowsPlan.(rowsOpPlan); ok {
 			return rp.rop, fctx, nil
 		}
 	}
-	rop, err := where(ctx, pctx, tx, fromPlanOp{rowsPlan, fs.Stmt.String(), cols}, fctx, cond)
+	rop, err := where(ctx, pctx, tx, fromPlanOp{rowsPlan, stmt, cols}, fctx, cond)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -566,6 +727,20 @@ func (fctx *fromContext) CompileRef(r []sql.Identifier) (int, int, sql.ColumnTyp
 	return -1, -1, sql.ColumnType{}, fmt.Errorf("engine: reference %s not found", col)
 }
 
+// resultRef returns a reference to the column, qualified by its table if possible; the column
+// may not be referenced if its name is ambiguous.
+func (fctx *fromContext) resultRef(cr colRef) (expr.Ref, bool) {
+	if cr.table != 0 {
+		if fc, ok := fctx.colRefMap[cr]; ok && !fc.ambiguous {
+			return expr.Ref{cr.table, cr.column}, true
+		}
+	}
+	if fc, ok := fctx.colMap[cr.column]; ok && !fc.ambiguous {
+		return expr.Ref{cr.column}, true
+	}
+	return nil, false
+}
+
 func (fctx *fromContext) lookupColumn(col sql.Identifier) (int, bool) {
 	fc, ok := fctx.colMap[col]
 	if !ok {
@@ -0,0 +1,286 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+// FromFunction is a set returning function used as a from item. It may refer to the columns of
+// from items to its left in the same FROM clause.
+type FromFunction struct {
+	Name          sql.Identifier
+	Args          []expr.Expr
+	Alias         sql.Identifier
+	ColumnAliases []sql.Identifier
+}
+
+type setFunc struct {
+	cols     []sql.Identifier
+	colTypes []sql.ColumnType
+	tfn      func(argTypes []sql.ColumnType) []sql.ColumnType
+	minArgs  int
+	maxArgs  int
+	fn       func(nam sql.Identifier, args []sql.Value) ([][]sql.Value, error)
+}
+
+var (
+	setFuncs = map[sql.Identifier]*setFunc{
+		sql.ID("json_array_elements"): {
+			cols:     []sql.Identifier{sql.ID("value")},
+			colTypes: []sql.ColumnType{{Type: sql.JSONType}},
+			minArgs:  1,
+			maxArgs:  1,
+			fn:       jsonArrayElementsCall,
+		},
+		sql.ID("jsonb_array_elements"): {
+			cols:     []sql.Identifier{sql.ID("value")},
+			colTypes: []sql.ColumnType{{Type: sql.JSONBType}},
+			minArgs:  1,
+			maxArgs:  1,
+			fn:       jsonArrayElementsCall,
+		},
+		sql.ID("unnest"): {
+			cols:    []sql.Identifier{sql.ID("unnest")},
+			tfn:     unnestType,
+			minArgs: 1,
+			maxArgs: 1,
+			fn:      unnestCall,
+		},
+	}
+)
+
+func unnestType(argTypes []sql.ColumnType) []sql.ColumnType {
+	ct := argTypes[0]
+	if !ct.Array {
+		return []sql.ColumnType{{Type: sql.StringType}}
+	}
+	ct.Array = false
+	ct.NotNull = false
+	return []sql.ColumnType{ct}
+}
+
+func unnestCall(nam sql.Identifier, args []sql.Value) ([][]sql.Value, error) {
+	var a sql.ArrayValue
+	switch arg := args[0].(type) {
+	case nil:
+		return nil, nil
+	case sql.ArrayValue:
+		a = arg
+	case sql.StringValue:
+		var err error
+		a, err = sql.ParseArray(string(arg))
+		if err != nil {
+			return nil, fmt.Errorf("engine: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("engine: %s: want array got %v", nam, arg)
+	}
+
+	var rows [][]sql.Value
+	for _, e := range a {
+		rows = append(rows, []sql.Value{e})
+	}
+	return rows, nil
+}
+
+func jsonArrayElementsCall(nam sql.Identifier, args []sql.Value) ([][]sql.Value, error) {
+	var j sql.JSONValue
+	switch arg := args[0].(type) {
+	case nil:
+		return nil, nil
+	case sql.JSONValue:
+		j = arg
+	case sql.StringValue:
+		var err error
+		j, err = sql.ParseJSON(string(arg))
+		if err != nil {
+			return nil, fmt.Errorf("engine: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("engine: %s: want json got %v", nam, arg)
+	}
+
+	if j.Kind() != sql.JSONArray {
+		return nil, fmt.Errorf("engine: %s: cannot extract elements from a json %s", nam,
+			j.Kind())
+	}
+
+	var rows [][]sql.Value
+	for _, e := range j.Elements() {
+		rows = append(rows, []sql.Value{e})
+	}
+	return rows, nil
+}
+
+func (ff FromFunction) String() string {
+	s := fmt.Sprintf("%s(", ff.Name)
+	for i, a := range ff.Args {
+		if i > 0 {
+			s += ", "
+		}
+		s += a.String()
+	}
+	s += ")"
+	if ff.Alias != 0 {
+		s += fmt.Sprintf(" AS %s", ff.Alias)
+	}
+	if ff.ColumnAliases != nil {
+		s += " ("
+		for i, col := range ff.ColumnAliases {
+			if i > 0 {
+				s += ", "
+			}
+			s += col.String()
+		}
+		s += ")"
+	}
+	return s
+}
+
+func (ff FromFunction) plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext, cond expr.Expr) (rowsOp, *fromContext, error) {
+
+	sf, ok := setFuncs[ff.Name]
+	if !ok {
+		return nil, nil, fmt.Errorf("engine: set returning function \"%s\" not found", ff.Name)
+	}
+	if len(ff.Args) < sf.minArgs {
+		return nil, nil, fmt.Errorf("engine: function \"%s\": minimum %d arguments got %d",
+			ff.Name, sf.minArgs, len(ff.Args))
+	}
+	if len(ff.Args) > sf.maxArgs {
+		return nil, nil, fmt.Errorf("engine: function \"%s\": maximum %d arguments got %d",
+			ff.Name, sf.maxArgs, len(ff.Args))
+	}
+
+	args := make([]sql.CExpr, len(ff.Args))
+	argTypes := make([]sql.ColumnType, len(ff.Args))
+	for adx, a := range ff.Args {
+		var err error
+		args[adx], argTypes[adx], err = expr.Compile(ctx, pctx, tx, cctx, a)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	colTypes := sf.colTypes
+	if sf.tfn != nil {
+		colTypes = sf.tfn(argTypes)
+	}
+
+	cols := sf.cols
+	if ff.ColumnAliases != nil {
+		if len(ff.ColumnAliases) != len(cols) {
+			return nil, nil, fmt.Errorf("engine: wrong number of column aliases")
+		}
+		cols = ff.ColumnAliases
+	} else if ff.Alias != 0 && len(cols) == 1 {
+		// The alias of a function which returns a single column also names the column.
+		cols = []sql.Identifier{ff.Alias}
+	}
+	nam := ff.Name
+	if ff.Alias != 0 {
+		nam = ff.Alias
+	}
+	fctx := makeFromContext(nam, cols, colTypes, cctx)
+
+	rop, err := where(ctx, pctx, tx,
+		functionOp{name: ff.Name, sf: sf, args: args, cols: cols}, fctx, cond)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rop, fctx, nil
+}
+
+type functionOp struct {
+	name sql.Identifier
+	sf   *setFunc
+	args []sql.CExpr
+	cols []sql.Identifier
+}
+
+func (_ functionOp) Name() string {
+	return "function"
+}
+
+func (fo functionOp) Columns() []string {
+	var cols []string
+	for _, col := range fo.cols {
+		cols = append(cols, col.String())
+	}
+	return cols
+}
+
+func (fo functionOp) Fields() []evaluate.FieldDescription {
+	desc := fmt.Sprintf("%s(", fo.name)
+	for adx, a := range fo.args {
+		if adx > 0 {
+			desc += ", "
+		}
+		desc += a.String()
+	}
+	desc += ")"
+
+	return []evaluate.FieldDescription{
+		{Field: "function", Description: desc},
+	}
+}
+
+func (_ functionOp) Children() []evaluate.ExplainTree {
+	return nil
+}
+
+func (fo functionOp) rows(ctx context.Context, tx sql.Transaction,
+	ectx sql.EvalContext) (sql.Rows, error) {
+
+	args := make([]sql.Value, len(fo.args))
+	for adx, a := range fo.args {
+		var err error
+		args[adx], err = a.Eval(ctx, tx, ectx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := fo.sf.fn(fo.name, args)
+	if err != nil {
+		return nil, err
+	}
+	return &functionRows{numCols: len(fo.cols), rows: rows}, nil
+}
+
+type functionRows struct {
+	numCols int
+	rows    [][]sql.Value
+	index   int
+}
+
+func (fr *functionRows) NumColumns() int {
+	return fr.numCols
+}
+
+func (fr *functionRows) Close() error {
+	fr.index = len(fr.rows)
+	return nil
+}
+
+func (fr *functionRows) Next(ctx context.Context, dest []sql.Value) error {
+	if fr.index < len(fr.rows) {
+		copy(dest, fr.rows[fr.index])
+		fr.index += 1
+		return nil
+	}
+	return io.EOF
+}
+
+func (_ *functionRows) Delete(ctx context.Context) error {
+	return fmt.Errorf("function rows may not be deleted")
+}
+
+func (_ *functionRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return fmt.Errorf("function rows may not be updated")
+}
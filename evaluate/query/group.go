@@ -47,6 +47,9 @@ func (gbo groupByOp) Fields() []evaluate.FieldDescription {
 			}
 			desc += arg.String()
 		}
+		if agg.distinct {
+			desc = "DISTINCT " + desc
+		}
 		//XXX: agg.maker.String()
 		fd = append(fd, evaluate.FieldDescription{Field: "aggregate", Description: desc})
 	}
@@ -76,8 +79,9 @@ func (gbo groupByOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.Eval
 }
 
 type aggregator struct {
-	maker expr.MakeAggregator
-	args  []sql.CExpr
+	maker    expr.MakeAggregator
+	args     []sql.CExpr
+	distinct bool
 }
 
 type groupRows struct {
@@ -130,15 +134,16 @@ func (gr *groupRows) group(ctx context.Context) error {
 		}
 
 		row := make([]sql.Value, len(gr.groupExprs)+len(gr.aggregators))
-		var key string
-		for _, e2d := range gr.groupExprs {
+		vals := make([]sql.Value, len(gr.groupExprs))
+		for gdx, e2d := range gr.groupExprs {
 			val, err := e2d.expr.Eval(ctx, gr.tx, gr)
 			if err != nil {
 				return err
 			}
 			row[e2d.destColIndex] = val
-			key = fmt.Sprintf("%s[%s]", key, val)
+			vals[gdx] = val
 		}
+		key := sql.HashKey(vals)
 		group, ok := groups[key]
 		if !ok {
 			group = groupRow{row: row, aggregators: make([]expr.Aggregator, len(gr.aggregators))}
@@ -243,8 +248,13 @@ func (gctx *groupContext) MaybeRefExpr(e expr.Expr) (int, sql.ColumnType, bool)
 	return 0, sql.ColumnType{}, false
 }
 
-func (gctx *groupContext) CompileAggregator(maker expr.MakeAggregator, args []sql.CExpr) int {
-	gctx.aggregators = append(gctx.aggregators, aggregator{maker, args})
+func (gctx *groupContext) CompileAggregator(maker expr.MakeAggregator, args []sql.CExpr,
+	distinct bool) int {
+
+	if distinct {
+		maker = expr.Distinct(maker)
+	}
+	gctx.aggregators = append(gctx.aggregators, aggregator{maker, args, distinct})
 	gctx.groupCols = append(gctx.groupCols, sql.ID(fmt.Sprintf("agg%d", len(gctx.groupCols)+1)))
 	return len(gctx.group) + len(gctx.aggregators) - 1
 }
@@ -287,33 +297,60 @@ func makeGroupContext(ctx context.Context, pctx evaluate.PlanContext, tx sql.Tra
 	}, nil
 }
 
-func group(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, rop rowsOp,
+// groupWindowContext is used to compile the results of a GROUP BY which might contain window
+// functions; the window functions are evaluated over the groups, and their values follow the
+// group and aggregate columns.
+type groupWindowContext struct {
+	*groupContext
+	*windowContext
+}
+
+func (gwctx groupWindowContext) CompileRef(r []sql.Identifier) (int, int, sql.ColumnType,
+	error) {
+
+	return gwctx.groupContext.CompileRef(r)
+}
+
+type groupResults struct {
+	gctx           *groupContext
+	wctx           *windowContext
+	destExprs      []expr2dest
+	resultCols     []sql.Identifier
+	resultColTypes []sql.ColumnType
+	hce            sql.CExpr
+}
+
+func compileGroup(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 	fctx *fromContext, results []SelectResult, group []expr.Expr, having expr.Expr,
-	orderBy []OrderBy) (evaluate.Plan, error) {
+	numCols int) (*groupResults, error) {
 
 	gctx, err := makeGroupContext(ctx, pctx, tx, fctx, group)
+	if err != nil {
+		return nil, err
+	}
+	gr := groupResults{
+		gctx: gctx,
+		wctx: &windowContext{actx: gctx, numCols: numCols},
+	}
+	gwctx := groupWindowContext{gctx, gr.wctx}
 
-	var destExprs []expr2dest
-	var resultCols []sql.Identifier
-	var resultColTypes []sql.ColumnType
 	for ddx, sr := range results {
 		er, ok := sr.(ExprResult)
 		if !ok {
 			panic(fmt.Sprintf("unexpected type for query.SelectResult: %T: %v", sr, sr))
 		}
-		ce, ct, err := expr.CompileAggregator(ctx, pctx, tx, gctx, er.Expr)
+		ce, ct, err := expr.CompileAggregator(ctx, pctx, tx, gwctx, er.Expr)
 		if err != nil {
 			return nil, err
 		}
-		destExprs = append(destExprs, expr2dest{destColIndex: ddx, expr: ce})
-		resultCols = append(resultCols, er.Column(len(resultCols)))
-		resultColTypes = append(resultColTypes, ct)
+		gr.destExprs = append(gr.destExprs, expr2dest{destColIndex: ddx, expr: ce})
+		gr.resultCols = append(gr.resultCols, er.Column(len(gr.resultCols)))
+		gr.resultColTypes = append(gr.resultColTypes, ct)
 	}
 
-	var hce sql.CExpr
 	if having != nil {
 		var ct sql.ColumnType
-		hce, ct, err = expr.CompileAggregator(ctx, pctx, tx, gctx, having)
+		gr.hce, ct, err = expr.CompileAggregator(ctx, pctx, tx, gctx, having)
 		if err != nil {
 			return nil, err
 		}
@@ -322,6 +359,28 @@ func group(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, r
 		}
 	}
 
+	return &gr, nil
+}
+
+func group(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, rop rowsOp,
+	fctx *fromContext, results []SelectResult, group []expr.Expr, having expr.Expr,
+	orderBy []OrderBy) (rowsOpPlan, error) {
+
+	gr, err := compileGroup(ctx, pctx, tx, fctx, results, group, having, 0)
+	if err != nil {
+		return rowsOpPlan{}, err
+	}
+	if gr.wctx.windows != nil {
+		// The number of aggregate columns, which the window functions follow, is not known
+		// until all of the results have been compiled, so compile them again.
+		gr, err = compileGroup(ctx, pctx, tx, fctx, results, group, having,
+			len(gr.gctx.groupCols))
+		if err != nil {
+			return rowsOpPlan{}, err
+		}
+	}
+
+	gctx := gr.gctx
 	rop = &groupByOp{
 		rop:         rop,
 		cols:        gctx.groupCols,
@@ -330,17 +389,25 @@ func group(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, r
 	}
 
 	if having != nil {
-		rop = &filterOp{rop: rop, cond: hce}
+		rop = &filterOp{rop: rop, cond: gr.hce}
 	}
 
-	rrop := makeResultsOp(rop, resultCols, resultColTypes, destExprs)
+	if gr.wctx.windows != nil {
+		wcols := append([]sql.Identifier{}, gctx.groupCols...)
+		for _, wf := range gr.wctx.windows {
+			wcols = append(wcols, wf.call.Name)
+		}
+		rop = windowOp{rop: rop, cols: wcols, windows: gr.wctx.windows}
+	}
+
+	rrop := makeResultsOp(rop, gr.resultCols, gr.resultColTypes, gr.destExprs)
 	if orderBy == nil {
-		return makeRowsOpPlan(rrop, resultCols, resultColTypes), nil
+		return makeRowsOpPlan(rrop, gr.resultCols, gr.resultColTypes), nil
 	}
 
 	rop, err = order(rrop, makeFromContext(0, rrop.columns(), rrop.columnTypes(), nil), orderBy)
 	if err != nil {
-		return nil, err
+		return rowsOpPlan{}, err
 	}
-	return makeRowsOpPlan(rop, resultCols, resultColTypes), nil
+	return makeRowsOpPlan(rop, gr.resultCols, gr.resultColTypes), nil
 }
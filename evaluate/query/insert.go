@@ -3,16 +3,20 @@ package query
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/leftmike/maho/evaluate"
 	"github.com/leftmike/maho/evaluate/expr"
 	"github.com/leftmike/maho/sql"
 )
 
+// InsertValues inserts rows of values; a single empty row is DEFAULT VALUES.
 type InsertValues struct {
-	Table   sql.TableName
-	Columns []sql.Identifier
-	Rows    [][]expr.Expr
+	Table      sql.TableName
+	Columns    []sql.Identifier
+	Rows       [][]expr.Expr
+	OnConflict *OnConflict
+	Returning  *Returning
 }
 
 func (stmt *InsertValues) String() string {
@@ -28,6 +32,17 @@ func (stmt *InsertValues) String() string {
 		s += ") "
 	}
 
+	if len(stmt.Rows) == 1 && len(stmt.Rows[0]) == 0 {
+		s += "DEFAULT VALUES"
+		if stmt.OnConflict != nil {
+			s += stmt.OnConflict.String()
+		}
+		if stmt.Returning != nil {
+			s += stmt.Returning.String()
+		}
+		return s
+	}
+
 	s += "VALUES"
 
 	for i, r := range stmt.Rows {
@@ -51,23 +66,21 @@ func (stmt *InsertValues) String() string {
 		s += ")"
 	}
 
+	if stmt.OnConflict != nil {
+		s += stmt.OnConflict.String()
+	}
+	if stmt.Returning != nil {
+		s += stmt.Returning.String()
+	}
 	return s
 }
 
-func (stmt *InsertValues) Plan(ctx context.Context, pctx evaluate.PlanContext,
-	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
-
-	tn := pctx.ResolveTableName(stmt.Table)
-	tt, err := tx.LookupTableType(ctx, tn)
-	if err != nil {
-		return nil, err
-	}
-
-	cols := tt.Columns()
-	colDefaults := tt.ColumnDefaults()
+// insertColumns returns a mapping from column number to value number, and the maximum number of
+// values. Columns without a value are mapped to the number of columns.
+func insertColumns(tn sql.TableName, cols, insertCols []sql.Identifier) ([]int, int, error) {
 	mv := len(cols)
 	c2v := make([]int, mv) // column number to value number
-	if stmt.Columns == nil {
+	if insertCols == nil {
 		for c := range c2v {
 			c2v[c] = c
 		}
@@ -81,15 +94,34 @@ func (stmt *InsertValues) Plan(ctx context.Context, pctx evaluate.PlanContext,
 			cmap[cn] = i
 		}
 
-		mv = len(stmt.Columns)
-		for v, nam := range stmt.Columns {
+		mv = len(insertCols)
+		for v, nam := range insertCols {
 			c, ok := cmap[nam]
 			if !ok {
-				return nil, fmt.Errorf("engine: %s: column not found: %s", tn, nam)
+				return nil, 0, fmt.Errorf("engine: %s: column not found: %s", tn, nam)
 			}
 			c2v[c] = v
 		}
 	}
+	return c2v, mv, nil
+}
+
+func (stmt *InsertValues) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	tn := pctx.ResolveTableName(stmt.Table)
+	tt, err := tx.LookupTableType(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := tt.Columns()
+	colTypes := tt.ColumnTypes()
+	colDefaults := tt.ColumnDefaults()
+	c2v, mv, err := insertColumns(tn, cols, stmt.Columns)
+	if err != nil {
+		return nil, err
+	}
 
 	var rows [][]sql.CExpr
 	for _, r := range stmt.Rows {
@@ -105,6 +137,14 @@ func (stmt *InsertValues) Plan(ctx context.Context, pctx evaluate.PlanContext,
 
 			var ce sql.CExpr
 			if e != nil {
+				if cd.Always {
+					return nil, fmt.Errorf(
+						"engine: %s: cannot insert a non-DEFAULT value into column %s", tn,
+						cols[i])
+				}
+				if p, ok := e.(expr.Param); ok {
+					evaluate.SetParameterType(pctx, p.Num, colTypes[i])
+				}
 				ce, _, err = expr.Compile(ctx, pctx, tx, nil, e)
 				if err != nil {
 					return nil, err
@@ -118,14 +158,27 @@ func (stmt *InsertValues) Plan(ctx context.Context, pctx evaluate.PlanContext,
 		rows = append(rows, row)
 	}
 
-	return &insertValuesPlan{tn, tt.Version(), cols, rows}, nil
+	var cp *conflictPlan
+	if stmt.OnConflict != nil {
+		cp, err = stmt.OnConflict.plan(ctx, pctx, tx, tn, tt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	plan := &insertValuesPlan{tn, tt.Version(), cols, rows, cp}
+	if stmt.Returning != nil {
+		return stmt.Returning.plan(ctx, pctx, tx,
+			makeFromContext(tn.Table, tt.Columns(), tt.ColumnTypes(), nil), plan)
+	}
+	return plan, nil
 }
 
 type insertValuesPlan struct {
-	tn    sql.TableName
-	ttVer int64
-	cols  []sql.Identifier
-	rows  [][]sql.CExpr
+	tn         sql.TableName
+	ttVer      int64
+	cols       []sql.Identifier
+	rows       [][]sql.CExpr
+	onConflict *conflictPlan
 }
 
 func (_ *insertValuesPlan) Tag() string {
@@ -133,6 +186,12 @@ func (_ *insertValuesPlan) Tag() string {
 }
 
 func (plan *insertValuesPlan) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	return plan.modify(ctx, tx, nil)
+}
+
+func (plan *insertValuesPlan) modify(ctx context.Context, tx sql.Transaction,
+	rr *returnedRows) (int64, error) {
+
 	tbl, err := tx.LookupTable(ctx, plan.tn, plan.ttVer)
 	if err != nil {
 		return -1, err
@@ -159,10 +218,233 @@ func (plan *insertValuesPlan) Execute(ctx context.Context, tx sql.Transaction) (
 		rows = append(rows, row)
 	}
 
+	if plan.onConflict != nil {
+		return insertOnConflict(ctx, tx, plan.onConflict.start(tbl, rr), rows)
+	}
+
 	err = tbl.Insert(ctx, rows)
 	if err != nil {
 		return -1, err
 	}
+	for _, row := range rows {
+		rr.add(row)
+	}
 
 	return int64(len(rows)), nil
 }
+
+func insertOnConflict(ctx context.Context, tx sql.Transaction, ct *conflictTable,
+	rows [][]sql.Value) (int64, error) {
+
+	var cnt int64
+	for _, row := range rows {
+		ok, err := ct.insert(ctx, tx, row)
+		if err != nil {
+			return -1, err
+		}
+		if ok {
+			cnt += 1
+		}
+	}
+	return cnt, nil
+}
+
+type InsertQuery struct {
+	Table      sql.TableName
+	Columns    []sql.Identifier
+	Query      evaluate.Stmt
+	OnConflict *OnConflict
+	Returning  *Returning
+}
+
+func (stmt *InsertQuery) String() string {
+	s := fmt.Sprintf("INSERT INTO %s ", stmt.Table)
+	if stmt.Columns != nil {
+		s += "("
+		for i, col := range stmt.Columns {
+			if i > 0 {
+				s += ", "
+			}
+			s += col.String()
+		}
+		s += ") "
+	}
+	s += stmt.Query.String()
+	if stmt.OnConflict != nil {
+		s += stmt.OnConflict.String()
+	}
+	if stmt.Returning != nil {
+		s += stmt.Returning.String()
+	}
+	return s
+}
+
+func (stmt *InsertQuery) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	tn := pctx.ResolveTableName(stmt.Table)
+	tt, err := tx.LookupTableType(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := tt.Columns()
+	c2v, mv, err := insertColumns(tn, cols, stmt.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := stmt.Query.Plan(ctx, pctx, tx, cctx)
+	if err != nil {
+		return nil, err
+	}
+	rowsPlan, ok := plan.(evaluate.RowsPlan)
+	if !ok {
+		return nil, fmt.Errorf("engine: expected rows: %s", stmt.Query)
+	}
+	numVals := len(rowsPlan.Columns())
+	if numVals > mv {
+		return nil, fmt.Errorf("engine: %s: too many values", tn)
+	} else if stmt.Columns != nil && numVals < mv {
+		return nil, fmt.Errorf("engine: %s: more target columns than values", tn)
+	}
+
+	var defaultRow []sql.CExpr
+	for cdx, cd := range tt.ColumnDefaults() {
+		if c2v[cdx] < numVals && cd.Always {
+			return nil, fmt.Errorf("engine: %s: cannot insert a non-DEFAULT value into column %s",
+				tn, cols[cdx])
+		}
+		if c2v[cdx] >= numVals && cd.Default != nil {
+			if defaultRow == nil {
+				defaultRow = make([]sql.CExpr, len(cols))
+			}
+			defaultRow[cdx] = cd.Default
+		}
+	}
+
+	var cp *conflictPlan
+	if stmt.OnConflict != nil {
+		cp, err = stmt.OnConflict.plan(ctx, pctx, tx, tn, tt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	iqp := &insertQueryPlan{
+		tn:         tn,
+		ttVer:      tt.Version(),
+		cols:       cols,
+		rowsPlan:   rowsPlan,
+		c2v:        c2v,
+		defaultRow: defaultRow,
+		onConflict: cp,
+	}
+	if stmt.Returning != nil {
+		return stmt.Returning.plan(ctx, pctx, tx,
+			makeFromContext(tn.Table, tt.Columns(), tt.ColumnTypes(), nil), iqp)
+	}
+	return iqp, nil
+}
+
+type insertQueryPlan struct {
+	tn         sql.TableName
+	ttVer      int64
+	cols       []sql.Identifier
+	rowsPlan   evaluate.RowsPlan
+	c2v        []int
+	defaultRow []sql.CExpr
+	onConflict *conflictPlan
+}
+
+func (_ *insertQueryPlan) Tag() string {
+	return "INSERT"
+}
+
+func (plan *insertQueryPlan) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	return plan.modify(ctx, tx, nil)
+}
+
+func (plan *insertQueryPlan) modify(ctx context.Context, tx sql.Transaction,
+	rr *returnedRows) (int64, error) {
+
+	tbl, err := tx.LookupTable(ctx, plan.tn, plan.ttVer)
+	if err != nil {
+		return -1, err
+	}
+
+	r, err := plan.rowsPlan.Rows(ctx, tx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Close()
+
+	var ct *conflictTable
+	if plan.onConflict != nil {
+		ct = plan.onConflict.start(tbl, rr)
+	}
+
+	var cnt int64
+	dest := make([]sql.Value, r.NumColumns())
+	rows := make([][]sql.Value, 0, 128)
+	for {
+		err = r.Next(ctx, dest)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return -1, err
+		}
+
+		row := make([]sql.Value, len(plan.cols))
+		for cdx, vdx := range plan.c2v {
+			if vdx < len(dest) {
+				row[cdx] = dest[vdx]
+			} else if plan.defaultRow != nil && plan.defaultRow[cdx] != nil {
+				row[cdx], err = plan.defaultRow[cdx].Eval(ctx, tx, nil)
+				if err != nil {
+					return -1, err
+				}
+			}
+		}
+
+		if ct != nil {
+			ok, err := ct.insert(ctx, tx, row)
+			if err != nil {
+				return -1, err
+			}
+			if ok {
+				cnt += 1
+			}
+			continue
+		}
+		cnt += 1
+
+		if len(rows) == cap(rows) {
+			err = tbl.Insert(ctx, rows)
+			if err != nil {
+				return -1, err
+			}
+			for _, row := range rows {
+				rr.add(row)
+			}
+			if len(rows) < 1024 {
+				rows = make([][]sql.Value, 0, 1024)
+			} else {
+				rows = rows[:0]
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) > 0 {
+		err = tbl.Insert(ctx, rows)
+		if err != nil {
+			return -1, err
+		}
+		for _, row := range rows {
+			rr.add(row)
+		}
+	}
+	return cnt, nil
+}
@@ -127,6 +127,37 @@ var (
 			stmt: "insert into t (c4) values ('123b')",
 			fail: true,
 		},
+		{
+			stmt: "insert into t default values",
+			rows: [][]sql.Value{{nil, nil, nil, nil}},
+		},
+		{
+			stmt: "insert into t (c4, c2) select 123, 'abc'",
+			rows: [][]sql.Value{{nil, sql.StringValue("abc"), nil, sql.Int64Value(123)}},
+		},
+		{
+			stmt: "insert into t (c4, c1) (values (1, true), (2, false))",
+			rows: [][]sql.Value{
+				{sql.BoolValue(true), nil, nil, sql.Int64Value(1)},
+				{sql.BoolValue(false), nil, nil, sql.Int64Value(2)},
+			},
+		},
+		{
+			stmt: "insert into t select true, 'abc', 1.5, 2, 3, 4",
+			fail: true,
+		},
+		{
+			stmt: "insert into t (c1) select 'abcd'",
+			fail: true,
+		},
+		{
+			stmt: "insert into t (c5) select 1",
+			fail: true,
+		},
+		{
+			stmt: "insert into t (c4, c1) select 123",
+			fail: true,
+		},
 	}
 
 	insertColumns2 = []sql.Identifier{sql.ID("b1"), sql.ID("b2"), sql.ID("b3"), sql.ID("b4"),
@@ -180,6 +211,18 @@ var (
 			stmt: "insert into t3 (c1, c2, c3) values (1, 2, NULL)",
 			fail: true,
 		},
+		{
+			stmt: "insert into t3 default values",
+			fail: true,
+		},
+		{
+			stmt: "insert into t3 (c2) select 2",
+			rows: [][]sql.Value{{sql.Int64Value(1), sql.Int64Value(2), sql.Int64Value(3)}},
+		},
+		{
+			stmt: "insert into t3 (c1, c2) select NULL, 2",
+			rows: [][]sql.Value{{nil, sql.Int64Value(2), sql.Int64Value(3)}},
+		},
 	}
 )
 
@@ -71,6 +71,7 @@ type joinOp struct {
 	rightRowsOp   rowsOp
 	rightLen      int
 	needRightUsed bool
+	lateral       bool
 
 	cols []sql.Identifier
 
@@ -128,35 +129,44 @@ func (jo joinOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.EvalCont
 		return nil, err
 	}
 
-	rows, err := jo.rightRowsOp.rows(ctx, tx, ectx)
-	if err != nil {
-		return nil, err
+	jr := &joinRows{
+		tx:       tx,
+		ectx:     ectx,
+		leftRows: leftRows,
+		leftDest: make([]sql.Value, jo.leftLen),
+		leftLen:  jo.leftLen,
+		needLeft: jo.needLeft,
+		using:    jo.using,
+		src2dest: jo.src2dest,
+		rightLen: jo.rightLen,
+		on:       jo.on,
+		numCols:  len(jo.cols),
+	}
+
+	if jo.lateral {
+		// The right rows depend on the left row, so they are evaluated for each left row.
+		jr.lateral = jo.rightRowsOp
+		return jr, nil
 	}
 
-	rightRows, err := evaluate.AllRows(ctx, rows)
+	jr.rightRows, err = allRightRows(ctx, tx, ectx, jo.rightRowsOp)
 	if err != nil {
 		return nil, err
 	}
-	var rightUsed []bool
 	if jo.needRightUsed {
-		rightUsed = make([]bool, len(rightRows))
+		jr.rightUsed = make([]bool, len(jr.rightRows))
 	}
+	return jr, nil
+}
+
+func allRightRows(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	rop rowsOp) ([][]sql.Value, error) {
 
-	return &joinRows{
-		tx:        tx,
-		ectx:      ectx,
-		leftRows:  leftRows,
-		leftDest:  make([]sql.Value, jo.leftLen),
-		leftLen:   jo.leftLen,
-		rightRows: rightRows,
-		rightUsed: rightUsed,
-		needLeft:  jo.needLeft,
-		using:     jo.using,
-		src2dest:  jo.src2dest,
-		rightLen:  jo.rightLen,
-		on:        jo.on,
-		numCols:   len(jo.cols),
-	}, nil
+	rows, err := rop.rows(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate.AllRows(ctx, rows)
 }
 
 type joinState int
@@ -189,6 +199,7 @@ type joinRows struct {
 	rightDest  []sql.Value
 	rightLen   int
 	rightUsed  []bool
+	lateral    rowsOp
 
 	numCols int
 
@@ -302,6 +313,13 @@ func (jr *joinRows) Next(ctx context.Context, dest []sql.Value) error {
 				jr.state = allDone
 				return err
 			}
+			if jr.lateral != nil {
+				jr.rightRows, err = allRightRows(ctx, jr.tx, jr, jr.lateral)
+				if err != nil {
+					jr.state = allDone
+					return err
+				}
+			}
 			jr.rightIndex = 0
 			jr.haveLeft = true
 			jr.leftUsed = false
@@ -362,7 +380,17 @@ func (fj FromJoin) plan(ctx context.Context, pctx evaluate.PlanContext,
 	if err != nil {
 		return nil, nil, err
 	}
-	rightRowsOp, rightCtx, err := fj.Right.plan(ctx, pctx, tx, cctx, nil)
+
+	// A function on the right side of a join may refer to the columns on the left side, unless
+	// the join needs all of the right rows.
+	rightCCtx := cctx
+	_, lateral := fj.Right.(FromFunction)
+	if lateral && fj.Type != RightJoin && fj.Type != FullJoin {
+		rightCCtx = leftCtx
+	} else {
+		lateral = false
+	}
+	rightRowsOp, rightCtx, err := fj.Right.plan(ctx, pctx, tx, rightCCtx, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -372,6 +400,7 @@ func (fj FromJoin) plan(ctx context.Context, pctx evaluate.PlanContext,
 		leftRowsOp:  leftRowsOp,
 		leftLen:     len(leftCtx.cols),
 		rightRowsOp: rightRowsOp,
+		lateral:     lateral,
 	}
 	if fj.Type == LeftJoin || fj.Type == FullJoin {
 		jop.needLeft = true
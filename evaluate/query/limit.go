@@ -0,0 +1,195 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+type limitOp struct {
+	rop    rowsOp
+	limit  sql.CExpr
+	offset sql.CExpr
+}
+
+func (_ limitOp) Name() string {
+	return "limit"
+}
+
+func (lo limitOp) Columns() []string {
+	return lo.rop.Columns()
+}
+
+func (lo limitOp) Fields() []evaluate.FieldDescription {
+	var fd []evaluate.FieldDescription
+	if lo.limit != nil {
+		fd = append(fd, evaluate.FieldDescription{Field: "limit", Description: lo.limit.String()})
+	}
+	if lo.offset != nil {
+		fd = append(fd,
+			evaluate.FieldDescription{Field: "offset", Description: lo.offset.String()})
+	}
+	return fd
+}
+
+func (lo limitOp) Children() []evaluate.ExplainTree {
+	return []evaluate.ExplainTree{lo.rop}
+}
+
+func evalLimit(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext, ce sql.CExpr,
+	what string) (int64, bool, error) {
+
+	if ce == nil {
+		return 0, false, nil
+	}
+	v, err := ce.Eval(ctx, tx, ectx)
+	if err != nil {
+		return 0, false, err
+	}
+	if v == nil {
+		return 0, false, nil
+	}
+	i, ok := v.(sql.Int64Value)
+	if !ok {
+		return 0, false, fmt.Errorf("engine: %s must be an integer: %s", what, sql.Format(v))
+	}
+	if i < 0 {
+		return 0, false, fmt.Errorf("engine: %s must not be negative: %d", what, i)
+	}
+	return int64(i), true, nil
+}
+
+func (lo limitOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext) (sql.Rows,
+	error) {
+
+	limit, hasLimit, err := evalLimit(ctx, tx, ectx, lo.limit, "LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	offset, _, err := evalLimit(ctx, tx, ectx, lo.offset, "OFFSET")
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := lo.rop.rows(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasLimit {
+		limit = -1
+	}
+	return &limitRows{rows: r, limit: limit, offset: offset}, nil
+}
+
+type limitRows struct {
+	rows   sql.Rows
+	limit  int64
+	offset int64
+}
+
+func (lr *limitRows) NumColumns() int {
+	return lr.rows.NumColumns()
+}
+
+func (lr *limitRows) Close() error {
+	lr.limit = 0
+	return lr.rows.Close()
+}
+
+func (lr *limitRows) Next(ctx context.Context, dest []sql.Value) error {
+	for lr.offset > 0 {
+		err := lr.rows.Next(ctx, dest)
+		if err != nil {
+			return err
+		}
+		lr.offset -= 1
+	}
+
+	if lr.limit == 0 {
+		return io.EOF
+	}
+	err := lr.rows.Next(ctx, dest)
+	if err != nil {
+		return err
+	}
+	if lr.limit > 0 {
+		lr.limit -= 1
+	}
+	return nil
+}
+
+func (lr *limitRows) Delete(ctx context.Context) error {
+	return lr.rows.Delete(ctx)
+}
+
+func (lr *limitRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return lr.rows.Update(ctx, updates)
+}
+
+func compileLimit(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	e expr.Expr, what string) (sql.CExpr, error) {
+
+	if e == nil {
+		return nil, nil
+	}
+	if p, ok := e.(expr.Param); ok {
+		evaluate.SetParameterType(pctx, p.Num, sql.Int64ColType)
+	}
+	ce, ct, err := expr.Compile(ctx, pctx, tx, nil, e)
+	if err != nil {
+		return nil, err
+	}
+	if ct.Type != sql.IntegerType && ct.Type != sql.UnknownType {
+		return nil, fmt.Errorf("engine: %s must be an integer expression: %s", what, e)
+	}
+	return ce, nil
+}
+
+// topSort finds a sort under rop, which returns rows one for one, and changes it to only keep
+// the top rows.
+func topSort(rop rowsOp, limit, offset sql.CExpr) (rowsOp, bool) {
+	switch rop := rop.(type) {
+	case sortOp:
+		rop.limit = limit
+		rop.offset = offset
+		return rop, true
+	case *resultsOp:
+		if sop, ok := topSort(rop.rop, limit, offset); ok {
+			rop.rop = sop
+			return rop, true
+		}
+	case *allResultsOp:
+		if sop, ok := topSort(rop.rop, limit, offset); ok {
+			rop.rop = sop
+			return rop, true
+		}
+	}
+	return rop, false
+}
+
+func limit(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, rop rowsOp,
+	limit, offset expr.Expr) (rowsOp, error) {
+
+	if limit == nil && offset == nil {
+		return rop, nil
+	}
+
+	lce, err := compileLimit(ctx, pctx, tx, limit, "LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	oce, err := compileLimit(ctx, pctx, tx, offset, "OFFSET")
+	if err != nil {
+		return nil, err
+	}
+
+	if lce != nil {
+		rop, _ = topSort(rop, lce, oce)
+	}
+	return limitOp{rop: rop, limit: lce, offset: oce}, nil
+}
@@ -0,0 +1,180 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+// Returning is the RETURNING clause of INSERT, UPDATE, and DELETE; no Results means *.
+type Returning struct {
+	Results []SelectResult
+}
+
+func (ret *Returning) String() string {
+	if ret.Results == nil {
+		return " RETURNING *"
+	}
+
+	s := " RETURNING "
+	for i, sr := range ret.Results {
+		if i > 0 {
+			s += ", "
+		}
+		s += sr.String()
+	}
+	return s
+}
+
+// returnedRows collects the rows affected by a statement with a RETURNING clause: the new rows
+// for INSERT and UPDATE, and the old rows for DELETE. A nil returnedRows discards the rows.
+type returnedRows struct {
+	rows [][]sql.Value
+}
+
+func (rr *returnedRows) add(row []sql.Value) {
+	if rr != nil {
+		rr.rows = append(rr.rows, append(make([]sql.Value, 0, len(row)), row...))
+	}
+}
+
+type modifyPlan interface {
+	evaluate.StmtPlan
+	modify(ctx context.Context, tx sql.Transaction, rr *returnedRows) (int64, error)
+}
+
+func (ret *Returning) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	fctx *fromContext, mp modifyPlan) (evaluate.Plan, error) {
+
+	rp := returningPlan{mp: mp}
+	if ret.Results == nil {
+		rp.cols = fctx.columns()
+		rp.colTypes = fctx.columnTypes()
+		return &rp, nil
+	}
+
+	for _, sr := range ret.Results {
+		switch sr := sr.(type) {
+		case TableResult:
+			cols := fctx.tableColumns(sr.Table)
+			if len(cols) == 0 {
+				return nil, fmt.Errorf("engine: table %s not found", sr.Table)
+			}
+			for _, col := range cols {
+				ce, ct, err := expr.Compile(ctx, pctx, tx, fctx, expr.Ref{sr.Table, col})
+				if err != nil {
+					return nil, err
+				}
+				rp.cols = append(rp.cols, col)
+				rp.colTypes = append(rp.colTypes, ct)
+				rp.exprs = append(rp.exprs, ce)
+			}
+		case ExprResult:
+			ce, ct, err := expr.Compile(ctx, pctx, tx, fctx, sr.Expr)
+			if err != nil {
+				return nil, err
+			}
+			rp.cols = append(rp.cols, sr.Column(len(rp.cols)))
+			rp.colTypes = append(rp.colTypes, ct)
+			rp.exprs = append(rp.exprs, ce)
+		default:
+			panic(fmt.Sprintf("unexpected type for query.SelectResult: %T: %v", sr, sr))
+		}
+	}
+	return &rp, nil
+}
+
+// returningPlan modifies the table when its rows are requested, and then returns the results
+// evaluated for each of the affected rows.
+type returningPlan struct {
+	mp       modifyPlan
+	cols     []sql.Identifier
+	colTypes []sql.ColumnType
+	exprs    []sql.CExpr
+}
+
+func (rp *returningPlan) Tag() string {
+	return rp.mp.Tag()
+}
+
+func (rp *returningPlan) Columns() []sql.Identifier {
+	return rp.cols
+}
+
+func (rp *returningPlan) ColumnTypes() []sql.ColumnType {
+	return rp.colTypes
+}
+
+func (rp *returningPlan) Rows(ctx context.Context, tx sql.Transaction,
+	ectx sql.EvalContext) (sql.Rows, error) {
+
+	var rr returnedRows
+	_, err := rp.mp.modify(ctx, tx, &rr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &returningRows{
+		tx:      tx,
+		numCols: len(rp.cols),
+		exprs:   rp.exprs,
+		rows:    rr.rows,
+	}, nil
+}
+
+// returningRows returns the affected rows unchanged when there are no exprs.
+type returningRows struct {
+	tx      sql.Transaction
+	numCols int
+	exprs   []sql.CExpr
+	rows    [][]sql.Value
+	index   int
+}
+
+func (rr *returningRows) EvalRef(idx, nest int) sql.Value {
+	if nest != 0 {
+		panic("nested reference in returning rows")
+	}
+	return rr.rows[rr.index-1][idx]
+}
+
+func (rr *returningRows) NumColumns() int {
+	return rr.numCols
+}
+
+func (rr *returningRows) Close() error {
+	rr.index = len(rr.rows)
+	return nil
+}
+
+func (rr *returningRows) Next(ctx context.Context, dest []sql.Value) error {
+	if rr.index == len(rr.rows) {
+		return io.EOF
+	}
+	rr.index += 1
+
+	if rr.exprs == nil {
+		copy(dest, rr.rows[rr.index-1])
+		return nil
+	}
+	for edx, e := range rr.exprs {
+		val, err := e.Eval(ctx, rr.tx, rr)
+		if err != nil {
+			return err
+		}
+		dest[edx] = val
+	}
+	return nil
+}
+
+func (_ *returningRows) Delete(ctx context.Context) error {
+	return fmt.Errorf("returning rows may not be deleted")
+}
+
+func (_ *returningRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return fmt.Errorf("returning rows may not be updated")
+}
@@ -0,0 +1,163 @@
+package query_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/test"
+	"github.com/leftmike/maho/parser"
+	"github.com/leftmike/maho/sql"
+)
+
+func TestReturning(t *testing.T) {
+	cases := []struct {
+		stmt string
+		fail bool
+		cnt  int64
+		cols []sql.Identifier
+		rows [][]sql.Value
+	}{
+		{
+			stmt: "create table tbl (c1 int primary key, c2 text default 'dflt', " +
+				"c3 double precision)",
+		},
+		{
+			stmt: "insert into tbl values (1, 'one', 1.5), (2, 'two', 2.5) returning *",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c2"), sql.ID("c3")},
+			rows: [][]sql.Value{
+				{sql.Int64Value(1), sql.StringValue("one"), sql.Float64Value(1.5)},
+				{sql.Int64Value(2), sql.StringValue("two"), sql.Float64Value(2.5)},
+			},
+		},
+		{
+			stmt: "insert into tbl (c1, c3) values (3, 3) returning c2, c3 * 2 as c4",
+			cols: []sql.Identifier{sql.ID("c2"), sql.ID("c4")},
+			rows: [][]sql.Value{{sql.StringValue("dflt"), sql.Float64Value(6)}},
+		},
+		{
+			stmt: "insert into tbl select c1 + 10, c2, c3 from tbl where c1 < 3 returning c1",
+			cols: []sql.Identifier{sql.ID("c1")},
+			rows: [][]sql.Value{{sql.Int64Value(11)}, {sql.Int64Value(12)}},
+		},
+		{
+			stmt: "insert into tbl values (1, 'uno', 0), (4, 'four', 4) " +
+				"on conflict (c1) do update set c2 = excluded.c2 returning c1, c2, c3",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c2"), sql.ID("c3")},
+			rows: [][]sql.Value{
+				{sql.Int64Value(1), sql.StringValue("uno"), sql.Float64Value(1.5)},
+				{sql.Int64Value(4), sql.StringValue("four"), sql.Float64Value(4)},
+			},
+		},
+		{
+			stmt: "update tbl set c3 = 10 where c1 = 2 returning tbl.*",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c2"), sql.ID("c3")},
+			rows: [][]sql.Value{
+				{sql.Int64Value(2), sql.StringValue("two"), sql.Float64Value(10)},
+			},
+		},
+		{
+			stmt: "update tbl set c3 = c3 where c1 <= 2 returning c1, c3",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c3")},
+			rows: [][]sql.Value{
+				{sql.Int64Value(1), sql.Float64Value(1.5)},
+				{sql.Int64Value(2), sql.Float64Value(10)},
+			},
+		},
+		{
+			stmt: "update tbl set c2 = c2 where c1 > 10",
+			cnt:  2,
+		},
+		{
+			stmt: "delete from tbl where c1 > 10 returning c1, c2",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c2")},
+			rows: [][]sql.Value{
+				{sql.Int64Value(11), sql.StringValue("one")},
+				{sql.Int64Value(12), sql.StringValue("two")},
+			},
+		},
+		{
+			stmt: "delete from tbl where c1 > 10 returning *",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c2"), sql.ID("c3")},
+			rows: [][]sql.Value{},
+		},
+		{stmt: "create table tbl2 (c1 int primary key, c4 text)"},
+		{stmt: "insert into tbl2 values (1, 'eins'), (3, 'drei')"},
+		{
+			stmt: "update tbl set c2 = tbl2.c4 from tbl2 where tbl.c1 = tbl2.c1 returning *",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c2"), sql.ID("c3"), sql.ID("c1"),
+				sql.ID("c4")},
+			rows: [][]sql.Value{
+				{sql.Int64Value(1), sql.StringValue("eins"), sql.Float64Value(1.5),
+					sql.Int64Value(1), sql.StringValue("eins")},
+				{sql.Int64Value(3), sql.StringValue("drei"), sql.Float64Value(3),
+					sql.Int64Value(3), sql.StringValue("drei")},
+			},
+		},
+		{
+			stmt: "delete from tbl using tbl2 where tbl.c1 = tbl2.c1 returning tbl.c1, c4",
+			cols: []sql.Identifier{sql.ID("c1"), sql.ID("c4")},
+			rows: [][]sql.Value{
+				{sql.Int64Value(1), sql.StringValue("eins")},
+				{sql.Int64Value(3), sql.StringValue("drei")},
+			},
+		},
+		{stmt: "insert into tbl values (5) returning c4", fail: true},
+		{stmt: "delete from tbl returning tbl2.*", fail: true},
+		{stmt: "update tbl set c3 = 1 returning sum(c3)", fail: true},
+	}
+
+	e, ses := test.StartSession(t)
+	ctx := context.Background()
+	tx := e.Begin(0)
+	for _, c := range cases {
+		p := parser.NewParser(strings.NewReader(c.stmt), "returning")
+		stmt, err := p.Parse()
+		if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.stmt, err)
+			continue
+		}
+		plan, err := stmt.Plan(ctx, ses, tx, nil)
+		if c.fail {
+			if err == nil {
+				t.Errorf("Plan(%q) did not fail", c.stmt)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Plan(%q) failed with %s", c.stmt, err)
+			continue
+		}
+
+		if c.cols == nil {
+			cnt, err := plan.(evaluate.StmtPlan).Execute(ctx, tx)
+			if err != nil {
+				t.Errorf("Execute(%q) failed with %s", c.stmt, err)
+			} else if c.cnt > 0 && cnt != c.cnt {
+				t.Errorf("Execute(%q) got %d want %d", c.stmt, cnt, c.cnt)
+			}
+			continue
+		}
+
+		rowsPlan, ok := plan.(evaluate.RowsPlan)
+		if !ok {
+			t.Errorf("Plan(%q) got %T want evaluate.RowsPlan", c.stmt, plan)
+			continue
+		}
+		if !reflect.DeepEqual(rowsPlan.Columns(), c.cols) {
+			t.Errorf("Plan(%q).Columns() got %v want %v", c.stmt, rowsPlan.Columns(), c.cols)
+		}
+		rows, err := rowsPlan.Rows(ctx, tx, nil)
+		if err != nil {
+			t.Errorf("Plan(%q).Rows() failed with %s", c.stmt, err)
+			continue
+		}
+		all, err := evaluate.AllRows(ctx, rows)
+		if err != nil {
+			t.Errorf("Plan(%q).Rows().Next() failed with %s", c.stmt, err)
+		} else if !reflect.DeepEqual(all, c.rows) {
+			t.Errorf("Plan(%q).Rows() got %v want %v", c.stmt, all, c.rows)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
 	"io"
@@ -30,12 +31,16 @@ type OrderBy struct {
 }
 
 type Select struct {
-	Results []SelectResult
-	From    FromItem
-	Where   expr.Expr
-	GroupBy []expr.Expr
-	Having  expr.Expr
-	OrderBy []OrderBy
+	Distinct   bool
+	DistinctOn []expr.Expr
+	Results    []SelectResult
+	From       FromItem
+	Where      expr.Expr
+	GroupBy    []expr.Expr
+	Having     expr.Expr
+	OrderBy    []OrderBy
+	Limit      expr.Expr
+	Offset     expr.Expr
 }
 
 func (tr TableResult) String() string {
@@ -53,14 +58,23 @@ func (er ExprResult) String() string {
 func (er ExprResult) Column(idx int) sql.Identifier {
 	col := er.Alias
 	if col == 0 {
-		if ref, ok := er.Expr.(expr.Ref); ok && (len(ref) == 1 || len(ref) == 2) {
+		e := er.Expr
+		for {
+			c, ok := e.(*expr.Cast)
+			if !ok {
+				break
+			}
+			e = c.Expr
+		}
+
+		if ref, ok := e.(expr.Ref); ok && (len(ref) == 1 || len(ref) == 2) {
 			// [ table '.' ] column
 			if len(ref) == 1 {
 				col = ref[0]
 			} else {
 				col = ref[1]
 			}
-		} else if call, ok := er.Expr.(*expr.Call); ok {
+		} else if call, ok := e.(*expr.Call); ok {
 			col = call.Name
 		} else {
 			col = sql.ID(fmt.Sprintf("expr%d", idx+1))
@@ -71,6 +85,18 @@ func (er ExprResult) Column(idx int) sql.Identifier {
 
 func (stmt *Select) String() string {
 	s := "SELECT "
+	if stmt.DistinctOn != nil {
+		s += "DISTINCT ON ("
+		for i, e := range stmt.DistinctOn {
+			if i > 0 {
+				s += ", "
+			}
+			s += e.String()
+		}
+		s += ") "
+	} else if stmt.Distinct {
+		s += "DISTINCT "
+	}
 	if stmt.Results == nil {
 		s += "*"
 	} else {
@@ -81,7 +107,9 @@ func (stmt *Select) String() string {
 			s += sr.String()
 		}
 	}
-	s += fmt.Sprintf(" FROM %s", stmt.From)
+	if stmt.From != nil {
+		s += fmt.Sprintf(" FROM %s", stmt.From)
+	}
 	if stmt.Where != nil {
 		s += fmt.Sprintf(" WHERE %s", stmt.Where)
 	}
@@ -111,48 +139,89 @@ func (stmt *Select) String() string {
 			}
 		}
 	}
+	if stmt.Limit != nil {
+		s += fmt.Sprintf(" LIMIT %s", stmt.Limit)
+	}
+	if stmt.Offset != nil {
+		s += fmt.Sprintf(" OFFSET %s", stmt.Offset)
+	}
 	return s
 }
 
 func (stmt *Select) Plan(ctx context.Context, pctx evaluate.PlanContext,
 	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
 
+	rp, err := stmt.plan(ctx, pctx, tx, cctx)
+	if err != nil {
+		return rowsOpPlan{}, err
+	}
+	rp.rop, err = limit(ctx, pctx, tx, rp.rop, stmt.Limit, stmt.Offset)
+	if err != nil {
+		return rowsOpPlan{}, err
+	}
+	return rp, nil
+}
+
+func (stmt *Select) plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (rowsOpPlan, error) {
+
+	err := checkDistinctOn(stmt.DistinctOn, stmt.OrderBy)
+	if err != nil {
+		return rowsOpPlan{}, err
+	}
+
 	var rop rowsOp
 	var fctx *fromContext
-	var err error
 
 	if stmt.From == nil {
 		fctx = &fromContext{cctx: cctx}
 		rop, err = where(ctx, pctx, tx, oneEmptyOp{}, fctx, stmt.Where)
 		if err != nil {
-			return nil, err
+			return rowsOpPlan{}, err
 		}
 	} else {
 		rop, fctx, err = stmt.From.plan(ctx, pctx, tx, cctx, stmt.Where)
 		if err != nil {
-			return nil, err
+			return rowsOpPlan{}, err
 		}
 	}
 
 	if stmt.GroupBy == nil && stmt.Having == nil {
 		rrop, err := results(ctx, pctx, tx, rop, fctx, stmt.Results)
 		if err == nil {
-			if stmt.OrderBy == nil {
-				return makeRowsOpPlan(rrop, rrop.columns(), rrop.columnTypes()), nil
+			rop = rrop
+			if stmt.OrderBy != nil {
+				rop, err = order(rrop, fctx, stmt.OrderBy)
+				if err != nil {
+					return rowsOpPlan{}, err
+				}
 			}
-
-			rop, err = order(rrop, fctx, stmt.OrderBy)
-			if err != nil {
-				return nil, err
+			if stmt.Distinct {
+				rop, err = distinct(ctx, pctx, tx, rop, rrop.columns(), rrop.columnTypes(), fctx,
+					stmt.DistinctOn)
+				if err != nil {
+					return rowsOpPlan{}, err
+				}
 			}
 			return makeRowsOpPlan(rop, rrop.columns(), rrop.columnTypes()), nil
 		} else if _, ok := err.(*expr.ContextError); !ok {
-			return nil, err
+			return rowsOpPlan{}, err
 		}
 		// Aggregrate function used in SELECT results causes an implicit GROUP BY
 	}
 
-	return group(ctx, pctx, tx, rop, fctx, stmt.Results, stmt.GroupBy, stmt.Having, stmt.OrderBy)
+	rp, err := group(ctx, pctx, tx, rop, fctx, stmt.Results, stmt.GroupBy, stmt.Having,
+		stmt.OrderBy)
+	if err != nil {
+		return rowsOpPlan{}, err
+	}
+	if stmt.Distinct {
+		rp.rop, err = distinct(ctx, pctx, tx, rp.rop, rp.cols, rp.colTypes, nil, stmt.DistinctOn)
+		if err != nil {
+			return rowsOpPlan{}, err
+		}
+	}
+	return rp, nil
 }
 
 type rowsOpPlan struct {
@@ -200,6 +269,8 @@ func (rp rowsOpPlan) Explain() evaluate.ExplainTree {
 type sortOp struct {
 	rop     rowsOp
 	orderBy []orderBy
+	limit   sql.CExpr
+	offset  sql.CExpr
 }
 
 func (_ sortOp) Name() string {
@@ -225,9 +296,17 @@ func (so sortOp) Fields() []evaluate.FieldDescription {
 		desc += cols[ob.colIndex]
 	}
 
-	return []evaluate.FieldDescription{
+	fd := []evaluate.FieldDescription{
 		{Field: "order", Description: desc},
 	}
+	if so.limit != nil {
+		desc = so.limit.String()
+		if so.offset != nil {
+			desc += fmt.Sprintf(" + %s", so.offset)
+		}
+		fd = append(fd, evaluate.FieldDescription{Field: "top", Description: desc})
+	}
+	return fd
 }
 
 func (so sortOp) Children() []evaluate.ExplainTree {
@@ -237,12 +316,27 @@ func (so sortOp) Children() []evaluate.ExplainTree {
 func (so sortOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext) (sql.Rows,
 	error) {
 
+	top := int64(-1)
+	if so.limit != nil {
+		limit, ok, err := evalLimit(ctx, tx, ectx, so.limit, "LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			offset, _, err := evalLimit(ctx, tx, ectx, so.offset, "OFFSET")
+			if err != nil {
+				return nil, err
+			}
+			top = limit + offset
+		}
+	}
+
 	r, err := so.rop.rows(ctx, tx, ectx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &sortRows{rows: r, orderBy: so.orderBy}, nil
+	return &sortRows{rows: r, orderBy: so.orderBy, top: top}, nil
 }
 
 type orderBy struct {
@@ -253,6 +347,7 @@ type orderBy struct {
 type sortRows struct {
 	rows    sql.Rows
 	orderBy []orderBy
+	top     int64
 	values  [][]sql.Value
 	index   int
 	sorted  bool
@@ -278,7 +373,16 @@ func (sr *sortRows) sort(ctx context.Context) error {
 		} else if err != nil {
 			return err
 		}
-		sr.values = append(sr.values, dest)
+		if sr.top < 0 || int64(len(sr.values)) < sr.top {
+			sr.values = append(sr.values, dest)
+			if int64(len(sr.values)) == sr.top {
+				heap.Init(topRows{sr})
+			}
+		} else if sr.top > 0 && sr.less(dest, sr.values[0]) {
+			// Replace the largest of the top rows.
+			sr.values[0] = dest
+			heap.Fix(topRows{sr}, 0)
+		}
 	}
 	sort.Sort(sr)
 
@@ -318,9 +422,13 @@ func (sr *sortRows) Swap(i, j int) {
 }
 
 func (sr *sortRows) Less(i, j int) bool {
+	return sr.less(sr.values[i], sr.values[j])
+}
+
+func (sr *sortRows) less(ri, rj []sql.Value) bool {
 	for _, by := range sr.orderBy {
-		vi := sr.values[i][by.colIndex]
-		vj := sr.values[j][by.colIndex]
+		vi := ri[by.colIndex]
+		vj := rj[by.colIndex]
 		cmp := sql.Compare(vi, vj)
 		if cmp < 0 {
 			return !by.reverse
@@ -331,6 +439,23 @@ func (sr *sortRows) Less(i, j int) bool {
 	return false
 }
 
+// topRows is a max heap of the values of sortRows; it is used to keep just the top rows.
+type topRows struct {
+	*sortRows
+}
+
+func (tr topRows) Less(i, j int) bool {
+	return tr.sortRows.Less(j, i)
+}
+
+func (tr topRows) Push(x interface{}) {
+	panic("unexpected, should never be called")
+}
+
+func (tr topRows) Pop() interface{} {
+	panic("unexpected, should never be called")
+}
+
 func orderByOutput(order []OrderBy, cols []sql.Identifier) []orderBy {
 	var byOutput []orderBy
 	for odx, by := range order {
@@ -751,6 +876,78 @@ func (_ *resultRows) Update(ctx context.Context, updates []sql.ColumnUpdate) err
 	return fmt.Errorf("result rows may not be updated")
 }
 
+// ExpandResults replaces * and table.* in the results of a SELECT, and of the queries of a set
+// operation, with references to the columns that they currently stand for; the query of a view
+// is expanded, so that adding columns to its tables does not change the columns of the view.
+func ExpandResults(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	stmt evaluate.Stmt) error {
+
+	switch stmt := stmt.(type) {
+	case *Select:
+		return stmt.expandResults(ctx, pctx, tx)
+	case *SetOp:
+		err := ExpandResults(ctx, pctx, tx, stmt.Left)
+		if err != nil {
+			return err
+		}
+		return ExpandResults(ctx, pctx, tx, stmt.Right)
+	}
+	return nil
+}
+
+func (stmt *Select) expandResults(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction) error {
+
+	if stmt.From == nil {
+		return nil
+	}
+	star := stmt.Results == nil
+	for _, sr := range stmt.Results {
+		if _, ok := sr.(TableResult); ok {
+			star = true
+		}
+	}
+	if !star {
+		return nil
+	}
+
+	_, fctx, err := stmt.From.plan(ctx, pctx, tx, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var results []SelectResult
+	if stmt.Results == nil {
+		for _, cr := range fctx.cols {
+			ref, ok := fctx.resultRef(cr)
+			if !ok {
+				return nil
+			}
+			results = append(results, ExprResult{Expr: ref})
+		}
+	} else {
+		for _, sr := range stmt.Results {
+			tr, ok := sr.(TableResult)
+			if !ok {
+				results = append(results, sr)
+				continue
+			}
+			for _, cr := range fctx.cols {
+				if cr.table != tr.Table {
+					continue
+				}
+				ref, ok := fctx.resultRef(cr)
+				if !ok {
+					return nil
+				}
+				results = append(results, ExprResult{Expr: ref})
+			}
+		}
+	}
+	stmt.Results = results
+	return nil
+}
+
 func results(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction, rop rowsOp,
 	fctx *fromContext, results []SelectResult) (resultRowsOp, error) {
 
@@ -767,6 +964,7 @@ func results(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 	var cols []sql.Identifier
 	var colTypes []sql.ColumnType
 
+	wctx := &windowContext{actx: fctx, numCols: len(fctx.cols)}
 	ddx := 0
 	for _, sr := range results {
 		switch sr := sr.(type) {
@@ -782,7 +980,7 @@ func results(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 				ddx += 1
 			}
 		case ExprResult:
-			ce, ct, err := expr.Compile(ctx, pctx, tx, fctx, sr.Expr)
+			ce, ct, err := expr.Compile(ctx, pctx, tx, wctx, sr.Expr)
 			if err != nil {
 				return nil, err
 			}
@@ -794,6 +992,14 @@ func results(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
 			panic(fmt.Sprintf("unexpected type for query.SelectResult: %T: %v", sr, sr))
 		}
 	}
+
+	if wctx.windows != nil {
+		wcols := fctx.columns()
+		for _, wf := range wctx.windows {
+			wcols = append(wcols, wf.call.Name)
+		}
+		rop = windowOp{rop: rop, cols: wcols, windows: wctx.windows}
+	}
 	return makeResultsOp(rop, cols, colTypes, destExprs), nil
 }
 
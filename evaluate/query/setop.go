@@ -0,0 +1,374 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+type SetOpType int
+
+const (
+	Union SetOpType = iota
+	Intersect
+	Except
+)
+
+func (sot SetOpType) String() string {
+	switch sot {
+	case Union:
+		return "UNION"
+	case Intersect:
+		return "INTERSECT"
+	case Except:
+		return "EXCEPT"
+	default:
+		panic(fmt.Sprintf("unexpected set op type: %d", sot))
+	}
+}
+
+type SetOp struct {
+	Op      SetOpType
+	All     bool
+	Left    evaluate.Stmt
+	Right   evaluate.Stmt
+	OrderBy []OrderBy
+	Limit   expr.Expr
+	Offset  expr.Expr
+}
+
+func (stmt *SetOp) String() string {
+	s := fmt.Sprintf("(%s) %s ", stmt.Left, stmt.Op)
+	if stmt.All {
+		s += "ALL "
+	}
+	s += fmt.Sprintf("(%s)", stmt.Right)
+	if stmt.OrderBy != nil {
+		s += " ORDER BY "
+		for i, by := range stmt.OrderBy {
+			if i > 0 {
+				s += ", "
+			}
+			s += by.Expr.String()
+			if by.Reverse {
+				s += " DESC"
+			} else {
+				s += " ASC"
+			}
+		}
+	}
+	if stmt.Limit != nil {
+		s += fmt.Sprintf(" LIMIT %s", stmt.Limit)
+	}
+	if stmt.Offset != nil {
+		s += fmt.Sprintf(" OFFSET %s", stmt.Offset)
+	}
+	return s
+}
+
+func planSetOperand(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, stmt evaluate.Stmt) (rowsOp, evaluate.RowsPlan, error) {
+
+	plan, err := stmt.Plan(ctx, pctx, tx, cctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rowsPlan, ok := plan.(evaluate.RowsPlan)
+	if !ok {
+		return nil, nil, fmt.Errorf("engine: expected rows: %s", stmt)
+	}
+	if rp, ok := rowsPlan.(rowsOpPlan); ok {
+		return rp.rop, rowsPlan, nil
+	}
+	return fromPlanOp{rowsPlan, stmt.String(), rowsPlan.Columns()}, rowsPlan, nil
+}
+
+func (stmt *SetOp) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	lrop, lrp, err := planSetOperand(ctx, pctx, tx, cctx, stmt.Left)
+	if err != nil {
+		return nil, err
+	}
+	rrop, rrp, err := planSetOperand(ctx, pctx, tx, cctx, stmt.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := lrp.Columns()
+	ltypes := lrp.ColumnTypes()
+	rtypes := rrp.ColumnTypes()
+	if len(ltypes) != len(rtypes) {
+		return nil, fmt.Errorf("engine: each %s query must have the same number of columns",
+			stmt.Op)
+	}
+	colTypes := make([]sql.ColumnType, len(ltypes))
+	for cdx := range ltypes {
+		var ok bool
+		colTypes[cdx], ok = sql.UnifyColumnType(ltypes[cdx], rtypes[cdx])
+		if !ok {
+			return nil, fmt.Errorf("engine: %s types %s and %s cannot be matched: %s", stmt.Op,
+				ltypes[cdx].Type, rtypes[cdx].Type, cols[cdx])
+		}
+	}
+
+	var rop rowsOp = setOp{
+		op:       stmt.Op,
+		all:      stmt.All,
+		left:     lrop,
+		right:    rrop,
+		cols:     cols,
+		colTypes: colTypes,
+		lcasts:   castColumns(ltypes, colTypes),
+		rcasts:   castColumns(rtypes, colTypes),
+	}
+	if stmt.OrderBy != nil {
+		byCols := orderByOutput(stmt.OrderBy, cols)
+		if byCols == nil {
+			return nil,
+				fmt.Errorf("engine: ORDER BY on %s must use result columns", stmt.Op)
+		}
+		rop = sortOp{rop: rop, orderBy: byCols}
+	}
+	rop, err = limit(ctx, pctx, tx, rop, stmt.Limit, stmt.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return makeRowsOpPlan(rop, cols, colTypes), nil
+}
+
+type setOp struct {
+	op       SetOpType
+	all      bool
+	left     rowsOp
+	right    rowsOp
+	cols     []sql.Identifier
+	colTypes []sql.ColumnType
+	lcasts   []bool
+	rcasts   []bool
+}
+
+// castColumns returns which columns must be cast from their type in one of the queries to the
+// type of the set operation, or nil if none of them need to be.
+func castColumns(from, to []sql.ColumnType) []bool {
+	var casts []bool
+	for cdx := range from {
+		if from[cdx].Type != to[cdx].Type && from[cdx].Type != sql.UnknownType {
+			if casts == nil {
+				casts = make([]bool, len(from))
+			}
+			casts[cdx] = true
+		}
+	}
+	return casts
+}
+
+// castRows casts the values of the columns of one of the queries of a set operation to the
+// types of the set operation, so that equal values have the same key and are returned with
+// the type of the column.
+type castRows struct {
+	sql.Rows
+	colTypes []sql.ColumnType
+	casts    []bool
+}
+
+func (cr *castRows) Next(ctx context.Context, dest []sql.Value) error {
+	err := cr.Rows.Next(ctx, dest)
+	if err != nil {
+		return err
+	}
+	for cdx, cast := range cr.casts {
+		if cast && dest[cdx] != nil {
+			dest[cdx], err = expr.CastValue(cr.colTypes[cdx], dest[cdx])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func makeCastRows(rows sql.Rows, colTypes []sql.ColumnType, casts []bool) sql.Rows {
+	if casts == nil {
+		return rows
+	}
+	return &castRows{Rows: rows, colTypes: colTypes, casts: casts}
+}
+
+func (so setOp) Name() string {
+	if so.all {
+		return strings.ToLower(so.op.String()) + " all"
+	}
+	return strings.ToLower(so.op.String())
+}
+
+func (so setOp) Columns() []string {
+	var cols []string
+	for _, col := range so.cols {
+		cols = append(cols, col.String())
+	}
+	return cols
+}
+
+func (_ setOp) Fields() []evaluate.FieldDescription {
+	return nil
+}
+
+func (so setOp) Children() []evaluate.ExplainTree {
+	return []evaluate.ExplainTree{so.left, so.right}
+}
+
+func (so setOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext) (sql.Rows,
+	error) {
+
+	lr, err := so.left.rows(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+	rr, err := so.right.rows(ctx, tx, ectx)
+	if err != nil {
+		lr.Close()
+		return nil, err
+	}
+
+	return &setRows{
+		op:      so.op,
+		all:     so.all,
+		left:    makeCastRows(lr, so.colTypes, so.lcasts),
+		right:   makeCastRows(rr, so.colTypes, so.rcasts),
+		numCols: len(so.cols),
+		counts:  map[string]int{},
+	}, nil
+}
+
+type setRows struct {
+	op      SetOpType
+	all     bool
+	left    sql.Rows
+	right   sql.Rows
+	numCols int
+	counts  map[string]int
+	loaded  bool
+}
+
+func (sr *setRows) NumColumns() int {
+	return sr.numCols
+}
+
+func (sr *setRows) Close() error {
+	err := sr.left.Close()
+	if sr.right != nil {
+		rerr := sr.right.Close()
+		if err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// load counts the rows on the right; used by INTERSECT and EXCEPT.
+func (sr *setRows) load(ctx context.Context) error {
+	sr.loaded = true
+
+	dest := make([]sql.Value, sr.right.NumColumns())
+	for {
+		err := sr.right.Next(ctx, dest)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		sr.counts[sql.HashKey(dest)] += 1
+	}
+
+	err := sr.right.Close()
+	sr.right = nil
+	return err
+}
+
+func (sr *setRows) union(ctx context.Context, dest []sql.Value) error {
+	for {
+		err := sr.left.Next(ctx, dest)
+		if err == io.EOF {
+			if sr.right == nil {
+				return io.EOF
+			}
+			err = sr.left.Close()
+			if err != nil {
+				return err
+			}
+			sr.left, sr.right = sr.right, nil
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if sr.all {
+			return nil
+		}
+		key := sql.HashKey(dest)
+		if sr.counts[key] == 0 {
+			sr.counts[key] = 1
+			return nil
+		}
+	}
+}
+
+func (sr *setRows) Next(ctx context.Context, dest []sql.Value) error {
+	if sr.op == Union {
+		return sr.union(ctx, dest)
+	}
+
+	if !sr.loaded {
+		err := sr.load(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		err := sr.left.Next(ctx, dest)
+		if err != nil {
+			return err
+		}
+
+		key := sql.HashKey(dest)
+		cnt := sr.counts[key]
+		if sr.op == Intersect {
+			// cnt > 0: the row is on the right and has not been returned (yet).
+			if cnt > 0 {
+				if sr.all {
+					sr.counts[key] = cnt - 1
+				} else {
+					sr.counts[key] = 0
+				}
+				return nil
+			}
+		} else {
+			// cnt > 0: the row is on the right; cnt < 0: the row has already been returned.
+			if cnt > 0 {
+				if sr.all {
+					sr.counts[key] = cnt - 1
+				}
+			} else if cnt == 0 {
+				if !sr.all {
+					sr.counts[key] = -1
+				}
+				return nil
+			}
+		}
+	}
+}
+
+func (_ *setRows) Delete(ctx context.Context) error {
+	return fmt.Errorf("set operation rows may not be deleted")
+}
+
+func (_ *setRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return fmt.Errorf("set operation rows may not be updated")
+}
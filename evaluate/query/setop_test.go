@@ -0,0 +1,144 @@
+package query_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/test"
+	"github.com/leftmike/maho/parser"
+	"github.com/leftmike/maho/sql"
+)
+
+func mustParseNumeric(s string) sql.NumericValue {
+	n, err := sql.ParseNumeric(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// sameRows compares the type and the text of each value, so that values which are equal, but
+// of different types, do not match.
+func sameRows(rows1, rows2 [][]sql.Value) bool {
+	if len(rows1) != len(rows2) {
+		return false
+	}
+	for rdx := range rows1 {
+		if len(rows1[rdx]) != len(rows2[rdx]) {
+			return false
+		}
+		for cdx := range rows1[rdx] {
+			v1 := rows1[rdx][cdx]
+			v2 := rows2[rdx][cdx]
+			if reflect.TypeOf(v1) != reflect.TypeOf(v2) || sql.Format(v1) != sql.Format(v2) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func testQueryRows(t *testing.T, cases []queryRowsCase) {
+	t.Helper()
+
+	e, ses := test.StartSession(t)
+	ctx := context.Background()
+	tx := e.Begin(0)
+	for _, c := range cases {
+		p := parser.NewParser(strings.NewReader(c.stmt), "query")
+		stmt, err := p.Parse()
+		if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.stmt, err)
+			continue
+		}
+		plan, err := stmt.Plan(ctx, ses, tx, nil)
+		if c.fail {
+			if err == nil {
+				t.Errorf("Plan(%q) did not fail", c.stmt)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Plan(%q) failed with %s", c.stmt, err)
+			continue
+		}
+
+		rowsPlan, ok := plan.(evaluate.RowsPlan)
+		if !ok {
+			_, err = plan.(evaluate.StmtPlan).Execute(ctx, tx)
+			if err != nil {
+				t.Errorf("Execute(%q) failed with %s", c.stmt, err)
+			}
+			continue
+		}
+		rows, err := rowsPlan.Rows(ctx, tx, nil)
+		if err != nil {
+			t.Errorf("Plan(%q).Rows() failed with %s", c.stmt, err)
+			continue
+		}
+		all, err := evaluate.AllRows(ctx, rows)
+		if err != nil {
+			t.Errorf("Plan(%q).Rows().Next() failed with %s", c.stmt, err)
+		} else if !sameRows(all, c.rows) {
+			t.Errorf("Plan(%q).Rows() got %v want %v", c.stmt, all, c.rows)
+		}
+	}
+}
+
+type queryRowsCase struct {
+	stmt string
+	fail bool
+	rows [][]sql.Value
+}
+
+func TestSetOpTypes(t *testing.T) {
+	ts, err := sql.ParseTimestamp("2020-01-01 00:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testQueryRows(t, []queryRowsCase{
+		{
+			stmt: "select 1 union all select 1.5::double precision",
+			rows: [][]sql.Value{{sql.Float64Value(1)}, {sql.Float64Value(1.5)}},
+		},
+		{
+			stmt: "select 2.5 union all select 3",
+			rows: [][]sql.Value{{mustParseNumeric("2.5")}, {mustParseNumeric("3")}},
+		},
+		{
+			stmt: "select 1 union select 1::double precision union select 2.5",
+			rows: [][]sql.Value{{sql.Float64Value(1)}, {sql.Float64Value(2.5)}},
+		},
+		{
+			stmt: "select 1 union select 1.0 union select 1.00",
+			rows: [][]sql.Value{{mustParseNumeric("1")}},
+		},
+		{
+			stmt: "select 1 intersect select 1.0",
+			rows: [][]sql.Value{{mustParseNumeric("1")}},
+		},
+		{
+			stmt: "select 2 intersect select 2::double precision",
+			rows: [][]sql.Value{{sql.Float64Value(2)}},
+		},
+		{
+			stmt: "select 2::double precision except select 2",
+			rows: [][]sql.Value{},
+		},
+		{
+			stmt: "select date '2020-01-01' union select timestamp '2020-01-01 00:00'",
+			rows: [][]sql.Value{{sql.TimestampValue(ts)}},
+		},
+		{
+			stmt: "select timestamp '2020-01-01 00:00' intersect select date '2020-01-01'",
+			rows: [][]sql.Value{{sql.TimestampValue(ts)}},
+		},
+		{
+			stmt: "select 1 union select 'a'",
+			fail: true,
+		},
+	})
+}
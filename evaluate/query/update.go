@@ -18,7 +18,9 @@ type ColumnUpdate struct {
 type Update struct {
 	Table         sql.TableName
 	ColumnUpdates []ColumnUpdate
+	From          FromItem
 	Where         expr.Expr
+	Returning     *Returning
 }
 
 func (stmt *Update) String() string {
@@ -29,9 +31,15 @@ func (stmt *Update) String() string {
 		}
 		s += fmt.Sprintf("%s = %s", cu.Column, cu.Expr)
 	}
+	if stmt.From != nil {
+		s += fmt.Sprintf(" FROM %s", stmt.From)
+	}
 	if stmt.Where != nil {
 		s += fmt.Sprintf(" WHERE %s", stmt.Where)
 	}
+	if stmt.Returning != nil {
+		s += stmt.Returning.String()
+	}
 	return s
 }
 
@@ -46,6 +54,12 @@ type updatePlan struct {
 	where   sql.CExpr
 	dest    []sql.Value
 	updates []columnUpdate
+
+	// UPDATE ... FROM: the table joined with the other tables; the columns of the table are
+	// first in each row.
+	rop     rowsOp
+	key     []sql.ColumnKey
+	numCols int
 }
 
 func (up *updatePlan) EvalRef(idx, nest int) sql.Value {
@@ -55,6 +69,44 @@ func (up *updatePlan) EvalRef(idx, nest int) sql.Value {
 	return up.dest[idx]
 }
 
+// joinTable plans the table joined with the other tables in from, filtered by where, for
+// UPDATE ... FROM and DELETE ... USING.
+func joinTable(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	tn sql.TableName, from FromItem, where expr.Expr) (rowsOp, *fromContext, error) {
+
+	fj := FromJoin{
+		Left:  FromTableAlias{TableName: tn},
+		Right: from,
+		Type:  CrossJoin,
+	}
+	return fj.plan(ctx, pctx, tx, nil, where)
+}
+
+// lookupKey returns the row of tbl, in dest, with the same primary key as row; the returned
+// rows are positioned at that row. If there is no such row, nil is returned.
+func lookupKey(ctx context.Context, tbl sql.Table, key []sql.ColumnKey, row,
+	dest []sql.Value) (sql.Rows, error) {
+
+	keyRow := make([]sql.Value, len(dest))
+	for _, ck := range key {
+		keyRow[ck.Column()] = row[ck.Column()]
+	}
+
+	rows, err := tbl.Rows(ctx, keyRow, keyRow)
+	if err != nil {
+		return nil, err
+	}
+	err = rows.Next(ctx, dest)
+	if err != nil {
+		rows.Close()
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
 func (stmt *Update) Plan(ctx context.Context, pctx evaluate.PlanContext,
 	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
 
@@ -64,11 +116,24 @@ func (stmt *Update) Plan(ctx context.Context, pctx evaluate.PlanContext,
 		return nil, err
 	}
 
-	fctx := makeFromContext(tn.Table, tt.Columns(), tt.ColumnTypes(), nil)
-	var where sql.CExpr
-	if stmt.Where != nil {
+	plan := updatePlan{
+		tn:      tn,
+		ttVer:   tt.Version(),
+		updates: make([]columnUpdate, 0, len(stmt.ColumnUpdates)),
+	}
+
+	tctx := makeFromContext(tn.Table, tt.Columns(), tt.ColumnTypes(), nil)
+	fctx := tctx
+	if stmt.From != nil {
+		plan.rop, fctx, err = joinTable(ctx, pctx, tx, tn, stmt.From, stmt.Where)
+		if err != nil {
+			return nil, err
+		}
+		plan.key = tt.PrimaryKey()
+		plan.numCols = len(tt.Columns())
+	} else if stmt.Where != nil {
 		var ct sql.ColumnType
-		where, ct, err = expr.Compile(ctx, pctx, tx, fctx, stmt.Where)
+		plan.where, ct, err = expr.Compile(ctx, pctx, tx, fctx, stmt.Where)
 		if err != nil {
 			return nil, err
 		}
@@ -76,24 +141,24 @@ func (stmt *Update) Plan(ctx context.Context, pctx evaluate.PlanContext,
 			return nil, fmt.Errorf("engine: WHERE must be boolean expression: %s", stmt.Where)
 		}
 	}
-
-	plan := updatePlan{
-		tn:      tn,
-		ttVer:   tt.Version(),
-		where:   where,
-		dest:    make([]sql.Value, len(tt.Columns())),
-		updates: make([]columnUpdate, 0, len(stmt.ColumnUpdates)),
-	}
+	plan.dest = make([]sql.Value, len(fctx.cols))
 
 	colDefaults := tt.ColumnDefaults()
 	for _, cu := range stmt.ColumnUpdates {
-		col, ok := fctx.lookupColumn(cu.Column)
+		col, ok := tctx.lookupColumn(cu.Column)
 		if !ok {
 			return nil, fmt.Errorf("engine: table %s: column %s not found", tn, cu.Column)
 		}
 
 		var ce sql.CExpr
 		if cu.Expr != nil {
+			if colDefaults[col].Always {
+				return nil, fmt.Errorf("engine: table %s: column %s can only be updated to DEFAULT",
+					tn, cu.Column)
+			}
+			if p, ok := cu.Expr.(expr.Param); ok {
+				evaluate.SetParameterType(pctx, p.Num, tt.ColumnTypes()[col])
+			}
 			ce, _, err = expr.Compile(ctx, pctx, tx, fctx, cu.Expr)
 			if err != nil {
 				return nil, err
@@ -103,6 +168,10 @@ func (stmt *Update) Plan(ctx context.Context, pctx evaluate.PlanContext,
 		}
 		plan.updates = append(plan.updates, columnUpdate{column: col, expr: ce})
 	}
+
+	if stmt.Returning != nil {
+		return stmt.Returning.plan(ctx, pctx, tx, fctx, &plan)
+	}
 	return &plan, nil
 }
 
@@ -111,10 +180,19 @@ func (_ *updatePlan) Tag() string {
 }
 
 func (up *updatePlan) Execute(ctx context.Context, tx sql.Transaction) (int64, error) {
+	return up.modify(ctx, tx, nil)
+}
+
+func (up *updatePlan) modify(ctx context.Context, tx sql.Transaction,
+	rr *returnedRows) (int64, error) {
+
 	tbl, err := tx.LookupTable(ctx, up.tn, up.ttVer)
 	if err != nil {
 		return -1, err
 	}
+	if up.rop != nil {
+		return up.modifyFrom(ctx, tx, tbl, rr)
+	}
 
 	rows, err := tbl.Rows(ctx, nil, nil)
 	if err != nil {
@@ -135,28 +213,99 @@ func (up *updatePlan) Execute(ctx context.Context, tx sql.Transaction) (int64, e
 			return -1, err
 		}
 
-		updates = updates[:0]
-		for _, update := range up.updates {
-			col := update.column
+		updates, err = up.columnUpdates(ctx, tx, updates[:0])
+		if err != nil {
+			return -1, err
+		}
 
-			var val sql.Value
-			if update.expr != nil {
-				val, err = update.expr.Eval(ctx, tx, up)
-				if err != nil {
-					return -1, err
-				}
+		if len(updates) > 0 {
+			err = rows.Update(ctx, updates)
+			if err != nil {
+				return -1, err
 			}
-			if sql.Compare(val, up.dest[col]) != 0 {
-				updates = append(updates, sql.ColumnUpdate{Column: col, Value: val})
+		}
+		if rr != nil {
+			for _, update := range updates {
+				up.dest[update.Column] = update.Value
 			}
+			rr.add(up.dest)
 		}
+		cnt += 1
+	}
+}
+
+func (up *updatePlan) columnUpdates(ctx context.Context, tx sql.Transaction,
+	updates []sql.ColumnUpdate) ([]sql.ColumnUpdate, error) {
 
+	for _, update := range up.updates {
+		col := update.column
+
+		var val sql.Value
+		if update.expr != nil {
+			var err error
+			val, err = update.expr.Eval(ctx, tx, up)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if sql.Compare(val, up.dest[col]) != 0 {
+			updates = append(updates, sql.ColumnUpdate{Column: col, Value: val})
+		}
+	}
+	return updates, nil
+}
+
+func (up *updatePlan) modifyFrom(ctx context.Context, tx sql.Transaction, tbl sql.Table,
+	rr *returnedRows) (int64, error) {
+
+	rows, err := up.rop.rows(ctx, tx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer rows.Close()
+
+	seen := map[string]struct{}{}
+	cur := make([]sql.Value, up.numCols)
+	updates := make([]sql.ColumnUpdate, len(up.updates))
+	var cnt int64
+	for {
+		err := rows.Next(ctx, up.dest)
+		if err == io.EOF {
+			return cnt, nil
+		} else if err != nil {
+			return -1, err
+		}
+
+		key, _ := seenKey(up.key, up.dest)
+		if _, ok := seen[key]; ok {
+			return -1, fmt.Errorf("engine: table %s: row matched more than once", up.tn)
+		}
+		seen[key] = struct{}{}
+
+		updates, err = up.columnUpdates(ctx, tx, updates[:0])
+		if err != nil {
+			return -1, err
+		}
 		if len(updates) > 0 {
-			err = rows.Update(ctx, updates)
+			trows, err := lookupKey(ctx, tbl, up.key, up.dest, cur)
 			if err != nil {
 				return -1, err
+			} else if trows == nil {
+				return -1, fmt.Errorf("engine: table %s: internal error: missing row to update",
+					up.tn)
+			}
+			err = trows.Update(ctx, updates)
+			trows.Close()
+			if err != nil {
+				return -1, err
+			}
+		}
+		if rr != nil {
+			for _, update := range updates {
+				up.dest[update.Column] = update.Value
 			}
-			cnt += 1
+			rr.add(up.dest)
 		}
+		cnt += 1
 	}
 }
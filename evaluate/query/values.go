@@ -13,6 +13,8 @@ import (
 
 type Values struct {
 	Expressions [][]expr.Expr
+	Limit       expr.Expr
+	Offset      expr.Expr
 }
 
 func (stmt *Values) String() string {
@@ -34,6 +36,12 @@ func (stmt *Values) String() string {
 		s += ")"
 	}
 
+	if stmt.Limit != nil {
+		s += fmt.Sprintf(" LIMIT %s", stmt.Limit)
+	}
+	if stmt.Offset != nil {
+		s += fmt.Sprintf(" OFFSET %s", stmt.Offset)
+	}
 	return s
 }
 
@@ -56,12 +64,12 @@ func (stmt *Values) Plan(ctx context.Context, pctx evaluate.PlanContext,
 			if err != nil {
 				return nil, err
 			}
-			if colTypes[j].Type == sql.UnknownType {
+			if rows == nil {
 				colTypes[j] = ct
-			} else if colTypes[j].Type != ct.Type && ct.Type != sql.UnknownType {
-				if colTypes[j].Type == sql.IntegerType && ct.Type == sql.FloatType {
-					colTypes[j] = ct
-				} else if colTypes[j].Type != sql.FloatType || ct.Type != sql.IntegerType {
+			} else {
+				var ok bool
+				colTypes[j], ok = sql.UnifyColumnType(colTypes[j], ct)
+				if !ok {
 					return nil,
 						fmt.Errorf("engine: incompatible expression type in VALUES: %s", r[j])
 				}
@@ -69,11 +77,20 @@ func (stmt *Values) Plan(ctx context.Context, pctx evaluate.PlanContext,
 		}
 		rows = append(rows, row)
 	}
-	return valuesPlan{
+	vp := valuesPlan{
 		cols:     cols,
 		colTypes: colTypes,
 		rows:     rows,
-	}, nil
+	}
+	if stmt.Limit == nil && stmt.Offset == nil {
+		return vp, nil
+	}
+
+	rop, err := limit(ctx, pctx, tx, fromPlanOp{vp, "VALUES", cols}, stmt.Limit, stmt.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return makeRowsOpPlan(rop, cols, colTypes), nil
 }
 
 type valuesPlan struct {
@@ -0,0 +1,57 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+// viewContext is used to plan the query of a view: table names are resolved relative to the
+// schema of the view, and the common tables of the referencing statement are not visible.
+type viewContext struct {
+	evaluate.PlanContext
+	sn sql.SchemaName
+}
+
+func (vctx viewContext) ResolveTableName(tn sql.TableName) sql.TableName {
+	if tn.Database == 0 {
+		tn.Database = vctx.sn.Database
+		if tn.Schema == 0 {
+			tn.Schema = vctx.sn.Schema
+		}
+	}
+	return tn
+}
+
+func (vctx viewContext) ResolveSchemaName(sn sql.SchemaName) sql.SchemaName {
+	if sn.Database == 0 {
+		sn.Database = vctx.sn.Database
+	}
+	return sn
+}
+
+func planView(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, cond expr.Expr, tn sql.TableName, vt sql.ViewType,
+	nam sql.Identifier) (rowsOp, *fromContext, error) {
+
+	stmt, err := evaluate.ParseStmt(vt.Query())
+	if err != nil {
+		return nil, nil, fmt.Errorf("engine: view %s: %s", tn, err)
+	}
+	plan, err := stmt.Plan(ctx, viewContext{PlanContext: pctx, sn: vt.SchemaName()}, tx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("engine: view %s: %s", tn, err)
+	}
+	rowsPlan, ok := plan.(evaluate.RowsPlan)
+	if !ok {
+		return nil, nil, fmt.Errorf("engine: view %s: expected rows: %s", tn, vt.Query())
+	}
+	if len(rowsPlan.Columns()) != len(vt.Columns()) {
+		return nil, nil, fmt.Errorf("engine: view %s: columns of the query have changed", tn)
+	}
+
+	return fromRowsPlan(ctx, pctx, tx, cctx, cond, rowsPlan, vt.Query(), nam, vt.Columns())
+}
@@ -0,0 +1,676 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+type windowOrderBy struct {
+	expr    sql.CExpr
+	reverse bool
+}
+
+type frameBound struct {
+	typ    expr.FrameBoundType
+	offset sql.CExpr
+}
+
+type windowFunc struct {
+	call      *expr.Call
+	fn        expr.WindowFunc
+	maker     expr.MakeAggregator
+	args      []sql.CExpr
+	partition []sql.CExpr
+	orderBy   []windowOrderBy
+	mode      expr.FrameMode
+	start     frameBound
+	end       frameBound
+}
+
+// windowContext is used to compile select results which might contain window functions; the
+// values of the window functions follow the input columns. The input is either the rows from
+// the from items, or the groups when actx is a groupContext.
+type windowContext struct {
+	actx    sql.CompileContext
+	numCols int
+	windows []windowFunc
+}
+
+func (wctx *windowContext) CompileRef(r []sql.Identifier) (int, int, sql.ColumnType, error) {
+	return wctx.actx.CompileRef(r)
+}
+
+func (wctx *windowContext) WindowArgContext() sql.CompileContext {
+	return wctx.actx
+}
+
+func (wctx *windowContext) compile(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, e expr.Expr) (sql.CExpr, sql.ColumnType, error) {
+
+	if _, ok := wctx.actx.(*groupContext); ok {
+		return expr.CompileAggregator(ctx, pctx, tx, wctx.actx, e)
+	}
+	return expr.Compile(ctx, pctx, tx, wctx.actx, e)
+}
+
+func isNumericType(ct sql.ColumnType) bool {
+	return ct.Type == sql.IntegerType || ct.Type == sql.FloatType || ct.Type == sql.NumericType
+}
+
+func compileFrameBound(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	mode expr.FrameMode, fb expr.FrameBound) (frameBound, error) {
+
+	if fb.Offset == nil {
+		return frameBound{typ: fb.Type}, nil
+	}
+	ce, ct, err := expr.Compile(ctx, pctx, tx, nil, fb.Offset)
+	if err != nil {
+		return frameBound{}, err
+	}
+	if mode == expr.RowsFrame && ct.Type != sql.IntegerType && ct.Type != sql.UnknownType {
+		return frameBound{}, fmt.Errorf("engine: ROWS frame offset must be an integer: %s",
+			fb.Offset)
+	} else if mode == expr.RangeFrame && !isNumericType(ct) && ct.Type != sql.UnknownType {
+		return frameBound{}, fmt.Errorf("engine: RANGE frame offset must be a number: %s",
+			fb.Offset)
+	}
+	return frameBound{typ: fb.Type, offset: ce}, nil
+}
+
+func (wctx *windowContext) CompileWindow(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, c *expr.Call, fn expr.WindowFunc, maker expr.MakeAggregator,
+	args []sql.CExpr) (int, error) {
+
+	wf := windowFunc{
+		call:  c,
+		fn:    fn,
+		maker: maker,
+		args:  args,
+	}
+	for _, e := range c.Over.Partition {
+		ce, _, err := wctx.compile(ctx, pctx, tx, e)
+		if err != nil {
+			return -1, err
+		}
+		wf.partition = append(wf.partition, ce)
+	}
+
+	var orderTypes []sql.ColumnType
+	for _, by := range c.Over.OrderBy {
+		ce, ct, err := wctx.compile(ctx, pctx, tx, by.Expr)
+		if err != nil {
+			return -1, err
+		}
+		wf.orderBy = append(wf.orderBy, windowOrderBy{expr: ce, reverse: by.Reverse})
+		orderTypes = append(orderTypes, ct)
+	}
+
+	if c.Over.Frame == nil {
+		// Without ORDER BY, the frame is the whole partition. Otherwise, the frame is from the
+		// start of the partition through the last peer of the current row.
+		if c.Over.OrderBy == nil {
+			wf.mode = expr.RowsFrame
+			wf.start = frameBound{typ: expr.UnboundedPreceding}
+			wf.end = frameBound{typ: expr.UnboundedFollowing}
+		} else {
+			wf.mode = expr.RangeFrame
+			wf.start = frameBound{typ: expr.UnboundedPreceding}
+			wf.end = frameBound{typ: expr.CurrentRow}
+		}
+	} else {
+		frame := c.Over.Frame
+		if frame.Mode == expr.RangeFrame &&
+			(frame.Start.Offset != nil || frame.End.Offset != nil) {
+
+			if len(orderTypes) != 1 || !isNumericType(orderTypes[0]) {
+				return -1, fmt.Errorf("engine: RANGE with offset requires exactly one numeric " +
+					"ORDER BY column")
+			}
+		}
+
+		var err error
+		wf.mode = frame.Mode
+		wf.start, err = compileFrameBound(ctx, pctx, tx, frame.Mode, frame.Start)
+		if err != nil {
+			return -1, err
+		}
+		wf.end, err = compileFrameBound(ctx, pctx, tx, frame.Mode, frame.End)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	wctx.windows = append(wctx.windows, wf)
+	return wctx.numCols + len(wctx.windows) - 1, nil
+}
+
+type windowOp struct {
+	rop     rowsOp
+	cols    []sql.Identifier
+	windows []windowFunc
+}
+
+func (_ windowOp) Name() string {
+	return "window"
+}
+
+func (wo windowOp) Columns() []string {
+	var cols []string
+	for _, col := range wo.cols {
+		cols = append(cols, col.String())
+	}
+	return cols
+}
+
+func (wo windowOp) Fields() []evaluate.FieldDescription {
+	var fd []evaluate.FieldDescription
+	for _, wf := range wo.windows {
+		fd = append(fd, evaluate.FieldDescription{Field: "window", Description: wf.call.String()})
+	}
+	return fd
+}
+
+func (wo windowOp) Children() []evaluate.ExplainTree {
+	return []evaluate.ExplainTree{wo.rop}
+}
+
+func evalFrameOffset(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext,
+	fb frameBound) (sql.Value, error) {
+
+	if fb.offset == nil {
+		return nil, nil
+	}
+	v, err := fb.offset.Eval(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case sql.Int64Value:
+		if v >= 0 {
+			return v, nil
+		}
+	case sql.Float64Value:
+		if v >= 0 {
+			return v, nil
+		}
+	case sql.NumericValue:
+		if v.Sign() >= 0 {
+			return v, nil
+		}
+	case nil:
+		return nil, fmt.Errorf("engine: frame offset must not be null")
+	default:
+		return nil, fmt.Errorf("engine: frame offset must be a number: %s", sql.Format(v))
+	}
+	return nil, fmt.Errorf("engine: frame offset must not be negative: %s", sql.Format(v))
+}
+
+func (wo windowOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext) (sql.Rows,
+	error) {
+
+	offsets := make([][2]sql.Value, len(wo.windows))
+	for wdx, wf := range wo.windows {
+		var err error
+		offsets[wdx][0], err = evalFrameOffset(ctx, tx, ectx, wf.start)
+		if err != nil {
+			return nil, err
+		}
+		offsets[wdx][1], err = evalFrameOffset(ctx, tx, ectx, wf.end)
+		if err != nil {
+			return nil, err
+		}
+		if wf.mode == expr.RowsFrame {
+			for _, off := range offsets[wdx] {
+				switch off.(type) {
+				case sql.Float64Value, sql.NumericValue:
+					return nil, fmt.Errorf("engine: ROWS frame offset must be an integer: %s",
+						sql.Format(off))
+				}
+			}
+		}
+	}
+
+	r, err := wo.rop.rows(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &windowRows{
+		tx:      tx,
+		ectx:    ectx,
+		rows:    r,
+		numCols: len(wo.cols),
+		windows: wo.windows,
+		offsets: offsets,
+	}, nil
+}
+
+type windowRows struct {
+	tx      sql.Transaction
+	ectx    sql.EvalContext
+	rows    sql.Rows
+	numCols int
+	windows []windowFunc
+	offsets [][2]sql.Value
+	values  [][]sql.Value
+	index   int
+	done    bool
+}
+
+func (wr *windowRows) NumColumns() int {
+	return wr.numCols
+}
+
+func (wr *windowRows) Close() error {
+	wr.index = len(wr.values)
+	return wr.rows.Close()
+}
+
+type rowContext struct {
+	ectx sql.EvalContext
+	row  []sql.Value
+}
+
+func (rc rowContext) EvalRef(idx, nest int) sql.Value {
+	if nest > 0 {
+		return rc.ectx.EvalRef(idx, nest-1)
+	}
+	return rc.row[idx]
+}
+
+func (wr *windowRows) evalExprs(ctx context.Context, ces []sql.CExpr,
+	row []sql.Value) ([]sql.Value, error) {
+
+	vals := make([]sql.Value, len(ces))
+	for cdx, ce := range ces {
+		val, err := ce.Eval(ctx, wr.tx, rowContext{wr.ectx, row})
+		if err != nil {
+			return nil, err
+		}
+		vals[cdx] = val
+	}
+	return vals, nil
+}
+
+func equalValues(vals1, vals2 []sql.Value) bool {
+	for vdx := range vals1 {
+		if sql.Compare(vals1[vdx], vals2[vdx]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (wr *windowRows) load(ctx context.Context) error {
+	wr.done = true
+
+	numInput := wr.rows.NumColumns()
+	for {
+		dest := make([]sql.Value, wr.numCols)
+		err := wr.rows.Next(ctx, dest[:numInput])
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		wr.values = append(wr.values, dest)
+	}
+
+	for wdx := range wr.windows {
+		err := wr.evalWindow(ctx, wdx, numInput+wdx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evalWindow sorts the rows by the partition and then the order of the window, and evaluates
+// the window function for each row of each partition. The rows are left in sorted order.
+func (wr *windowRows) evalWindow(ctx context.Context, wdx, vdx int) error {
+	wf := wr.windows[wdx]
+
+	parts := make([][]sql.Value, len(wr.values))
+	orders := make([][]sql.Value, len(wr.values))
+	for rdx, row := range wr.values {
+		var err error
+		parts[rdx], err = wr.evalExprs(ctx, wf.partition, row)
+		if err != nil {
+			return err
+		}
+		orders[rdx] = make([]sql.Value, len(wf.orderBy))
+		for odx, by := range wf.orderBy {
+			orders[rdx][odx], err = by.expr.Eval(ctx, wr.tx, rowContext{wr.ectx, row})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	wp := windowPartition{
+		wr:      wr,
+		wf:      wf,
+		offsets: wr.offsets[wdx],
+	}
+	wp.sorter = windowSorter{
+		values:  wr.values,
+		parts:   parts,
+		orders:  orders,
+		orderBy: wf.orderBy,
+	}
+	sort.Stable(&wp.sorter)
+
+	for first := 0; first < len(wr.values); {
+		last := first + 1
+		for last < len(wr.values) && equalValues(parts[first], parts[last]) {
+			last += 1
+		}
+
+		err := wp.eval(ctx, wr.values[first:last], orders[first:last], vdx)
+		if err != nil {
+			return err
+		}
+		first = last
+	}
+	return nil
+}
+
+type windowSorter struct {
+	values  [][]sql.Value
+	parts   [][]sql.Value
+	orders  [][]sql.Value
+	orderBy []windowOrderBy
+}
+
+func (ws *windowSorter) Len() int {
+	return len(ws.values)
+}
+
+func (ws *windowSorter) Swap(i, j int) {
+	ws.values[i], ws.values[j] = ws.values[j], ws.values[i]
+	ws.parts[i], ws.parts[j] = ws.parts[j], ws.parts[i]
+	ws.orders[i], ws.orders[j] = ws.orders[j], ws.orders[i]
+}
+
+func (ws *windowSorter) Less(i, j int) bool {
+	for pdx := range ws.parts[i] {
+		cmp := sql.Compare(ws.parts[i][pdx], ws.parts[j][pdx])
+		if cmp != 0 {
+			return cmp < 0
+		}
+	}
+	for odx, by := range ws.orderBy {
+		cmp := sql.Compare(ws.orders[i][odx], ws.orders[j][odx])
+		if cmp < 0 {
+			return !by.reverse
+		} else if cmp > 0 {
+			return by.reverse
+		}
+	}
+	return false
+}
+
+// windowPartition implements expr.WindowRows for the rows of one partition.
+type windowPartition struct {
+	wr        *windowRows
+	wf        windowFunc
+	offsets   [2]sql.Value
+	sorter    windowSorter
+	values    [][]sql.Value
+	orders    [][]sql.Value
+	current   int
+	peerFirst int
+	peerLast  int
+	peerGroup int
+	first     int
+	last      int
+}
+
+func (wp *windowPartition) NumRows() int {
+	return len(wp.values)
+}
+
+func (wp *windowPartition) Current() int {
+	return wp.current
+}
+
+func (wp *windowPartition) Frame() (int, int) {
+	return wp.first, wp.last
+}
+
+func (wp *windowPartition) Peers() (int, int) {
+	return wp.peerFirst, wp.peerLast
+}
+
+func (wp *windowPartition) PeerGroup() int {
+	return wp.peerGroup
+}
+
+func (wp *windowPartition) Args(ctx context.Context, row int) ([]sql.Value, error) {
+	return wp.wr.evalExprs(ctx, wp.wf.args, wp.values[row])
+}
+
+func (wp *windowPartition) eval(ctx context.Context, values, orders [][]sql.Value,
+	vdx int) error {
+
+	wp.values = values
+	wp.orders = orders
+	wp.peerFirst = 0
+	wp.peerLast = 0
+	wp.peerGroup = -1
+
+	var agg expr.Aggregator
+	var aggFirst, aggLast int
+	for wp.current = 0; wp.current < len(values); wp.current += 1 {
+		if wp.current == wp.peerLast {
+			wp.peerFirst = wp.current
+			wp.peerLast = wp.current + 1
+			for wp.peerLast < len(values) && equalValues(orders[wp.current], orders[wp.peerLast]) {
+				wp.peerLast += 1
+			}
+			wp.peerGroup += 1
+		}
+
+		var err error
+		wp.first, err = wp.frameBound(wp.wf.start, wp.offsets[0], true)
+		if err != nil {
+			return err
+		}
+		wp.last, err = wp.frameBound(wp.wf.end, wp.offsets[1], false)
+		if err != nil {
+			return err
+		}
+		if wp.last < wp.first {
+			wp.last = wp.first
+		}
+
+		var val sql.Value
+		if wp.wf.fn != nil {
+			val, err = wp.wf.fn(ctx, wp)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Reuse the aggregator when the frame only grows at the end; this is the case for
+			// running totals.
+			if agg == nil || wp.first != aggFirst || wp.last < aggLast {
+				agg = wp.wf.maker()
+				aggFirst = wp.first
+				aggLast = wp.first
+			}
+			for ; aggLast < wp.last; aggLast += 1 {
+				args, err := wp.Args(ctx, aggLast)
+				if err != nil {
+					return err
+				}
+				err = agg.Accumulate(args)
+				if err != nil {
+					return err
+				}
+			}
+			val, err = agg.Total()
+			if err != nil {
+				return err
+			}
+		}
+		values[wp.current][vdx] = val
+	}
+	return nil
+}
+
+// frameBound returns the position of the start of the frame if start is true, otherwise one past
+// the end of the frame.
+func (wp *windowPartition) frameBound(fb frameBound, off sql.Value, start bool) (int, error) {
+	switch fb.typ {
+	case expr.UnboundedPreceding:
+		return 0, nil
+	case expr.UnboundedFollowing:
+		return len(wp.values), nil
+	case expr.CurrentRow:
+		if wp.wf.mode == expr.RowsFrame {
+			if start {
+				return wp.current, nil
+			}
+			return wp.current + 1, nil
+		}
+		if start {
+			return wp.peerFirst, nil
+		}
+		return wp.peerLast, nil
+	}
+
+	if wp.wf.mode == expr.RowsFrame {
+		n := int(off.(sql.Int64Value))
+		if fb.typ == expr.OffsetPreceding {
+			n = -n
+		}
+		pos := wp.current + n
+		if !start {
+			pos += 1
+		}
+		if pos < 0 {
+			return 0, nil
+		} else if pos > len(wp.values) {
+			return len(wp.values), nil
+		}
+		return pos, nil
+	}
+
+	// RANGE with an offset: the frame bound is the current row's ORDER BY value plus or minus
+	// the offset. NULL values are only peers of each other.
+	cur := wp.orders[wp.current][0]
+	if cur == nil {
+		if start {
+			return wp.peerFirst, nil
+		}
+		return wp.peerLast, nil
+	}
+	reverse := wp.wf.orderBy[0].reverse
+	bound, err := addOffset(cur, off, (fb.typ == expr.OffsetPreceding) != reverse)
+	if err != nil {
+		return 0, err
+	}
+
+	// before is true if val sorts before bound.
+	before := func(val sql.Value) bool {
+		cmp := sql.Compare(val, bound)
+		if reverse {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	if start {
+		pos := 0
+		for pos < len(wp.values) && before(wp.orders[pos][0]) {
+			pos += 1
+		}
+		return pos, nil
+	}
+
+	// after is true if val sorts after bound.
+	after := func(val sql.Value) bool {
+		cmp := sql.Compare(val, bound)
+		if reverse {
+			return cmp < 0
+		}
+		return cmp > 0
+	}
+	pos := len(wp.values)
+	for pos > 0 && after(wp.orders[pos-1][0]) {
+		pos -= 1
+	}
+	return pos, nil
+}
+
+func addOffset(val, off sql.Value, subtract bool) (sql.Value, error) {
+	switch off := off.(type) {
+	case sql.Int64Value:
+		if subtract {
+			off = -off
+		}
+		switch val := val.(type) {
+		case sql.Int64Value:
+			return val + off, nil
+		case sql.Float64Value:
+			return val + sql.Float64Value(off), nil
+		case sql.NumericValue:
+			return val.Add(sql.NumericFromInt64(int64(off))), nil
+		}
+	case sql.Float64Value:
+		if subtract {
+			off = -off
+		}
+		switch val := val.(type) {
+		case sql.Int64Value:
+			return sql.Float64Value(val) + off, nil
+		case sql.Float64Value:
+			return val + off, nil
+		case sql.NumericValue:
+			return sql.Float64Value(val.Float64()) + off, nil
+		}
+	case sql.NumericValue:
+		if subtract {
+			off = off.Negate()
+		}
+		switch val := val.(type) {
+		case sql.Int64Value:
+			return sql.NumericFromInt64(int64(val)).Add(off), nil
+		case sql.Float64Value:
+			return val + sql.Float64Value(off.Float64()), nil
+		case sql.NumericValue:
+			return val.Add(off), nil
+		}
+	}
+	return nil, fmt.Errorf("engine: unable to add frame offset %s to %s", sql.Format(off),
+		sql.Format(val))
+}
+
+func (wr *windowRows) Next(ctx context.Context, dest []sql.Value) error {
+	if !wr.done {
+		err := wr.load(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if wr.index < len(wr.values) {
+		copy(dest, wr.values[wr.index])
+		wr.index += 1
+		return nil
+	}
+	return io.EOF
+}
+
+func (_ *windowRows) Delete(ctx context.Context) error {
+	return fmt.Errorf("window rows may not be deleted")
+}
+
+func (_ *windowRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return fmt.Errorf("window rows may not be updated")
+}
@@ -0,0 +1,452 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/leftmike/maho/evaluate"
+	"github.com/leftmike/maho/evaluate/expr"
+	"github.com/leftmike/maho/sql"
+)
+
+type CommonTableExpr struct {
+	Name    sql.Identifier
+	Columns []sql.Identifier
+	Stmt    evaluate.Stmt
+}
+
+type With struct {
+	Recursive bool
+	CTEs      []CommonTableExpr
+	Stmt      evaluate.Stmt
+}
+
+func (cte CommonTableExpr) String() string {
+	s := cte.Name.String()
+	if cte.Columns != nil {
+		s += " ("
+		for i, col := range cte.Columns {
+			if i > 0 {
+				s += ", "
+			}
+			s += col.String()
+		}
+		s += ")"
+	}
+	return fmt.Sprintf("%s AS (%s)", s, cte.Stmt)
+}
+
+func (stmt *With) String() string {
+	s := "WITH "
+	if stmt.Recursive {
+		s += "RECURSIVE "
+	}
+	for i, cte := range stmt.CTEs {
+		if i > 0 {
+			s += ", "
+		}
+		s += cte.String()
+	}
+	return fmt.Sprintf("%s %s", s, stmt.Stmt)
+}
+
+func (stmt *With) Plan(ctx context.Context, pctx evaluate.PlanContext,
+	tx sql.Transaction, cctx sql.CompileContext) (evaluate.Plan, error) {
+
+	for _, cte := range stmt.CTEs {
+		wctx := &withContext{
+			PlanContext: pctx,
+			name:        cte.Name,
+			ct: &commonTable{
+				cte:       cte,
+				pctx:      pctx,
+				cctx:      cctx,
+				recursive: stmt.Recursive,
+			},
+		}
+
+		// Plan each common table once, even if it is not referenced, to catch any errors.
+		_, _, err := wctx.ct.plan(ctx, pctx, tx, cctx, nil, cte.Name)
+		if err != nil {
+			return nil, err
+		}
+		pctx = wctx
+	}
+
+	return stmt.Stmt.Plan(ctx, pctx, tx, cctx)
+}
+
+// withContext makes a common table visible to the statements planned using it.
+type withContext struct {
+	evaluate.PlanContext
+	name sql.Identifier
+	ct   *commonTable
+}
+
+func (wctx *withContext) WrappedPlanContext() evaluate.PlanContext {
+	return wctx.PlanContext
+}
+
+func lookupCommonTable(pctx evaluate.PlanContext, nam sql.Identifier) (*commonTable, bool) {
+	for {
+		if wctx, ok := pctx.(*withContext); ok {
+			if wctx.name == nam {
+				return wctx.ct, true
+			}
+		}
+		pcw, ok := pctx.(evaluate.PlanContextWrapper)
+		if !ok {
+			return nil, false
+		}
+		pctx = pcw.WrappedPlanContext()
+	}
+}
+
+type commonTable struct {
+	cte       CommonTableExpr
+	pctx      evaluate.PlanContext
+	cctx      sql.CompileContext
+	recursive bool
+
+	// Only used while planning the recursive term of a recursive common table: references to
+	// the common table are to the working table.
+	wt         *workingTable
+	cols       []sql.Identifier
+	colTypes   []sql.ColumnType
+	referenced bool
+}
+
+func (ct *commonTable) plan(ctx context.Context, pctx evaluate.PlanContext, tx sql.Transaction,
+	cctx sql.CompileContext, cond expr.Expr, nam sql.Identifier) (rowsOp, *fromContext, error) {
+
+	if ct.wt != nil {
+		ct.referenced = true
+		wto := workingTableOp{name: ct.cte.Name, cols: ct.cols, wt: ct.wt}
+		fctx := makeFromContext(nam, ct.cols, ct.colTypes, cctx)
+		rop, err := where(ctx, pctx, tx, wto, fctx, cond)
+		if err != nil {
+			return nil, nil, err
+		}
+		return rop, fctx, nil
+	}
+
+	if ct.recursive {
+		rp, ok, err := ct.planRecursive(ctx, tx)
+		if err != nil {
+			return nil, nil, err
+		} else if ok {
+			fctx := makeFromContext(nam, rp.cols, rp.colTypes, cctx)
+			rop, err := where(ctx, pctx, tx, rp.rop, fctx, cond)
+			if err != nil {
+				return nil, nil, err
+			}
+			return rop, fctx, nil
+		}
+	}
+
+	plan, err := ct.cte.Stmt.Plan(ctx, ct.pctx, tx, ct.cctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rowsPlan, ok := plan.(evaluate.RowsPlan)
+	if !ok {
+		return nil, nil, fmt.Errorf("engine: expected rows: %s", ct.cte.Stmt)
+	}
+	return fromRowsPlan(ctx, pctx, tx, cctx, cond, rowsPlan, ct.cte.Stmt.String(), nam,
+		ct.cte.Columns)
+}
+
+// planRecursive plans a recursive common table, which must be of the form
+// non-recursive-term UNION [ALL] recursive-term. If the common table does not reference itself,
+// it is not recursive, and false is returned.
+func (ct *commonTable) planRecursive(ctx context.Context, tx sql.Transaction) (rowsOpPlan, bool,
+	error) {
+
+	so, ok := ct.cte.Stmt.(*SetOp)
+	if !ok || so.Op != Union {
+		return rowsOpPlan{}, false, nil
+	}
+
+	lrop, lrp, err := planSetOperand(ctx, ct.pctx, tx, ct.cctx, so.Left)
+	if err != nil {
+		return rowsOpPlan{}, false, err
+	}
+	cols := lrp.Columns()
+	if ct.cte.Columns != nil {
+		if len(ct.cte.Columns) != len(cols) {
+			return rowsOpPlan{}, false, fmt.Errorf("engine: wrong number of column aliases")
+		}
+		cols = ct.cte.Columns
+	}
+	ltypes := lrp.ColumnTypes()
+	colTypes := ltypes
+
+	// The working table has the column types of the common table, which are the column types
+	// of the non-recursive term unified with those of the recursive term. If unifying changes
+	// the column types, the recursive term is planned again with the new types.
+	for {
+		wt := &workingTable{}
+		rct := &commonTable{
+			cte:      ct.cte,
+			wt:       wt,
+			cols:     cols,
+			colTypes: colTypes,
+		}
+		rctx := &withContext{PlanContext: ct.pctx, name: ct.cte.Name, ct: rct}
+		rrop, rrp, err := planSetOperand(ctx, rctx, tx, ct.cctx, so.Right)
+		if err != nil {
+			return rowsOpPlan{}, false, err
+		}
+		if !rct.referenced {
+			return rowsOpPlan{}, false, nil
+		}
+
+		if so.OrderBy != nil || so.Limit != nil || so.Offset != nil {
+			return rowsOpPlan{}, false,
+				fmt.Errorf("engine: ORDER BY, LIMIT, or OFFSET not allowed in recursive query: %s",
+					ct.cte.Name)
+		}
+		rtypes := rrp.ColumnTypes()
+		if len(rtypes) != len(cols) {
+			return rowsOpPlan{}, false,
+				fmt.Errorf("engine: each UNION query must have the same number of columns")
+		}
+
+		unified := make([]sql.ColumnType, len(colTypes))
+		var changed bool
+		for cdx := range colTypes {
+			var ok bool
+			unified[cdx], ok = sql.UnifyColumnType(colTypes[cdx], rtypes[cdx])
+			if !ok {
+				return rowsOpPlan{}, false,
+					fmt.Errorf("engine: recursive query %s column %s has type %s but %s overall",
+						ct.cte.Name, cols[cdx], rtypes[cdx].Type, colTypes[cdx].Type)
+			}
+			if unified[cdx].Type != colTypes[cdx].Type {
+				changed = true
+			}
+		}
+		if changed {
+			colTypes = unified
+			continue
+		}
+
+		return makeRowsOpPlan(
+			recursiveOp{
+				all:      so.All,
+				left:     lrop,
+				right:    rrop,
+				cols:     cols,
+				colTypes: colTypes,
+				lcasts:   castColumns(ltypes, colTypes),
+				rcasts:   castColumns(rtypes, colTypes),
+				wt:       wt,
+			}, cols, colTypes), true, nil
+	}
+}
+
+type workingTable struct {
+	rows [][]sql.Value
+}
+
+type workingTableOp struct {
+	name sql.Identifier
+	cols []sql.Identifier
+	wt   *workingTable
+}
+
+func (_ workingTableOp) Name() string {
+	return "working table"
+}
+
+func (wto workingTableOp) Columns() []string {
+	var cols []string
+	for _, col := range wto.cols {
+		cols = append(cols, col.String())
+	}
+	return cols
+}
+
+func (wto workingTableOp) Fields() []evaluate.FieldDescription {
+	return []evaluate.FieldDescription{
+		{Field: "table", Description: wto.name.String()},
+	}
+}
+
+func (_ workingTableOp) Children() []evaluate.ExplainTree {
+	return nil
+}
+
+func (wto workingTableOp) rows(ctx context.Context, tx sql.Transaction,
+	ectx sql.EvalContext) (sql.Rows, error) {
+
+	return &workingTableRows{numCols: len(wto.cols), rows: wto.wt.rows}, nil
+}
+
+type workingTableRows struct {
+	numCols int
+	rows    [][]sql.Value
+	index   int
+}
+
+func (wtr *workingTableRows) NumColumns() int {
+	return wtr.numCols
+}
+
+func (wtr *workingTableRows) Close() error {
+	wtr.index = len(wtr.rows)
+	return nil
+}
+
+func (wtr *workingTableRows) Next(ctx context.Context, dest []sql.Value) error {
+	if wtr.index < len(wtr.rows) {
+		copy(dest, wtr.rows[wtr.index])
+		wtr.index += 1
+		return nil
+	}
+	return io.EOF
+}
+
+func (_ *workingTableRows) Delete(ctx context.Context) error {
+	return fmt.Errorf("working table rows may not be deleted")
+}
+
+func (_ *workingTableRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return fmt.Errorf("working table rows may not be updated")
+}
+
+type recursiveOp struct {
+	all      bool
+	left     rowsOp
+	right    rowsOp
+	cols     []sql.Identifier
+	colTypes []sql.ColumnType
+	lcasts   []bool
+	rcasts   []bool
+	wt       *workingTable
+}
+
+func (ro recursiveOp) Name() string {
+	if ro.all {
+		return "recursive union all"
+	}
+	return "recursive union"
+}
+
+func (ro recursiveOp) Columns() []string {
+	var cols []string
+	for _, col := range ro.cols {
+		cols = append(cols, col.String())
+	}
+	return cols
+}
+
+func (_ recursiveOp) Fields() []evaluate.FieldDescription {
+	return nil
+}
+
+func (ro recursiveOp) Children() []evaluate.ExplainTree {
+	return []evaluate.ExplainTree{ro.left, ro.right}
+}
+
+func (ro recursiveOp) rows(ctx context.Context, tx sql.Transaction, ectx sql.EvalContext) (sql.Rows,
+	error) {
+
+	r, err := ro.left.rows(ctx, tx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recursiveRows{
+		tx:       tx,
+		ectx:     ectx,
+		all:      ro.all,
+		rows:     makeCastRows(r, ro.colTypes, ro.lcasts),
+		right:    ro.right,
+		colTypes: ro.colTypes,
+		rcasts:   ro.rcasts,
+		numCols:  len(ro.cols),
+		wt:       ro.wt,
+		seen:     map[string]struct{}{},
+	}, nil
+}
+
+// recursiveRows returns the rows of the non-recursive term, and then repeatedly evaluates the
+// recursive term, with the working table containing the rows returned by the previous
+// evaluation, until no more rows are returned.
+type recursiveRows struct {
+	tx       sql.Transaction
+	ectx     sql.EvalContext
+	all      bool
+	rows     sql.Rows
+	right    rowsOp
+	colTypes []sql.ColumnType
+	rcasts   []bool
+	numCols  int
+	wt       *workingTable
+	working  [][]sql.Value
+	seen     map[string]struct{}
+}
+
+func (rr *recursiveRows) NumColumns() int {
+	return rr.numCols
+}
+
+func (rr *recursiveRows) Close() error {
+	rr.working = nil
+	if rr.rows == nil {
+		return nil
+	}
+	err := rr.rows.Close()
+	rr.rows = nil
+	return err
+}
+
+func (rr *recursiveRows) Next(ctx context.Context, dest []sql.Value) error {
+	for rr.rows != nil {
+		err := rr.rows.Next(ctx, dest)
+		if err == io.EOF {
+			err = rr.rows.Close()
+			rr.rows = nil
+			if err != nil {
+				return err
+			}
+			if len(rr.working) == 0 {
+				return io.EOF
+			}
+
+			rr.wt.rows = rr.working
+			rr.working = nil
+			r, err := rr.right.rows(ctx, rr.tx, rr.ectx)
+			if err != nil {
+				return err
+			}
+			rr.rows = makeCastRows(r, rr.colTypes, rr.rcasts)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if !rr.all {
+			key := sql.HashKey(dest)
+			if _, ok := rr.seen[key]; ok {
+				continue
+			}
+			rr.seen[key] = struct{}{}
+		}
+		row := make([]sql.Value, len(dest))
+		copy(row, dest)
+		rr.working = append(rr.working, row)
+		return nil
+	}
+	return io.EOF
+}
+
+func (_ *recursiveRows) Delete(ctx context.Context) error {
+	return fmt.Errorf("recursive rows may not be deleted")
+}
+
+func (_ *recursiveRows) Update(ctx context.Context, updates []sql.ColumnUpdate) error {
+	return fmt.Errorf("recursive rows may not be updated")
+}
@@ -0,0 +1,34 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/sql"
+)
+
+func TestRecursiveTypes(t *testing.T) {
+	testQueryRows(t, []queryRowsCase{
+		{
+			stmt: `with recursive n (i) as (values (1) union all select i + 0.5 from n where i < 2)
+select * from n`,
+			rows: [][]sql.Value{
+				{mustParseNumeric("1")},
+				{mustParseNumeric("1.5")},
+				{mustParseNumeric("2.0")},
+			},
+		},
+		{
+			stmt: `with recursive n (i) as (values (1) union select i * 1.0 from n) select * from n`,
+			rows: [][]sql.Value{{mustParseNumeric("1")}},
+		},
+		{
+			stmt: `with recursive n (i) as
+(values (1) union all select i * 2::double precision from n where i < 4) select * from n`,
+			rows: [][]sql.Value{{sql.Float64Value(1)}, {sql.Float64Value(2)}, {sql.Float64Value(4)}},
+		},
+		{
+			stmt: `with recursive n (i) as (values (1) union select 'abc' from n) select * from n`,
+			fail: true,
+		},
+	})
+}
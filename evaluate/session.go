@@ -43,6 +43,11 @@ func (ses *Session) String() string {
 	return fmt.Sprintf("session-%d", ses.sesid)
 }
 
+// Close ends the session; the engine forgets any state it kept for the session.
+func (ses *Session) Close() {
+	ses.e.EndSession(ses.sesid)
+}
+
 func (ses *Session) ActiveTx() bool {
 	return ses.tx != nil
 }
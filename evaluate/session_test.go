@@ -85,6 +85,27 @@ func (st *testStore) DropTable(ctx context.Context, tx engine.Transaction,
 	return nil
 }
 
+func (st *testStore) TruncateTable(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) error {
+
+	st.t.Error("TruncateTable should never be called")
+	return nil
+}
+
+func (st *testStore) RebuildTable(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	tt *engine.TableType, copyRows func(otbl, ntbl engine.Table) error) error {
+
+	st.t.Error("RebuildTable should never be called")
+	return nil
+}
+
+func (st *testStore) RenameTable(ctx context.Context, tx engine.Transaction, tn,
+	ntn sql.TableName) error {
+
+	st.t.Error("RenameTable should never be called")
+	return nil
+}
+
 func (st *testStore) UpdateType(ctx context.Context, tx engine.Transaction, tn sql.TableName,
 	tt *engine.TableType) error {
 
@@ -92,6 +113,13 @@ func (st *testStore) UpdateType(ctx context.Context, tx engine.Transaction, tn s
 	return nil
 }
 
+func (st *testStore) AddColumn(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	tt *engine.TableType, val sql.Value) error {
+
+	st.t.Error("AddColumn should never be called")
+	return nil
+}
+
 func (st *testStore) MakeIndexType(tt *engine.TableType, nam sql.Identifier, key []sql.ColumnKey,
 	unique bool) sql.IndexType {
 
@@ -99,6 +127,69 @@ func (st *testStore) MakeIndexType(tt *engine.TableType, nam sql.Identifier, key
 	return sql.IndexType{}
 }
 
+func (st *testStore) LookupView(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) (*engine.ViewType, error) {
+
+	st.t.Error("LookupView should never be called")
+	return nil, nil
+}
+
+func (st *testStore) CreateView(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	vt *engine.ViewType, replace bool) error {
+
+	st.t.Error("CreateView should never be called")
+	return nil
+}
+
+func (st *testStore) DropView(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) error {
+
+	st.t.Error("DropView should never be called")
+	return nil
+}
+
+func (st *testStore) LookupSequence(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) (*engine.SequenceType, error) {
+
+	st.t.Error("LookupSequence should never be called")
+	return nil, nil
+}
+
+func (st *testStore) CreateSequence(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName, sqt *engine.SequenceType, ifNotExists bool) error {
+
+	st.t.Error("CreateSequence should never be called")
+	return nil
+}
+
+func (st *testStore) UpdateSequence(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName, sqt *engine.SequenceType) error {
+
+	st.t.Error("UpdateSequence should never be called")
+	return nil
+}
+
+func (st *testStore) DropSequence(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) error {
+
+	st.t.Error("DropSequence should never be called")
+	return nil
+}
+
+func (st *testStore) NextSequenceValues(ctx context.Context, tx engine.Transaction,
+	sesid uint64, tn sql.TableName) (*engine.SequenceType, int64, int64, error) {
+
+	st.t.Error("NextSequenceValues should never be called")
+	return nil, 0, 0, nil
+}
+
+func (st *testStore) SetSequenceValue(ctx context.Context, tx engine.Transaction,
+	sesid uint64, tn sql.TableName, val int64, called bool) error {
+
+	st.t.Error("SetSequenceValue should never be called")
+	return nil
+}
+
 func (st *testStore) AddIndex(ctx context.Context, tx engine.Transaction, tn sql.TableName,
 	tt *engine.TableType, it sql.IndexType) error {
 
@@ -152,6 +243,20 @@ func (st *testStore) ListTables(ctx context.Context, tx engine.Transaction,
 	return nil, nil
 }
 
+func (st *testStore) ListViews(ctx context.Context, tx engine.Transaction,
+	sn sql.SchemaName) ([]sql.Identifier, error) {
+
+	st.t.Error("ListViews should never be called")
+	return nil, nil
+}
+
+func (st *testStore) ListSequences(ctx context.Context, tx engine.Transaction,
+	sn sql.SchemaName) ([]sql.Identifier, error) {
+
+	st.t.Error("ListSequences should never be called")
+	return nil, nil
+}
+
 func (ttx *testTransaction) Commit(ctx context.Context) error {
 	if !ttx.wantCommit {
 		ttx.t.Error("Commit unexpected")
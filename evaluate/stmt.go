@@ -14,6 +14,10 @@ type Stmt interface {
 		error)
 }
 
+// ParseStmt parses a single statement, such as the query of a view. It is set by the parser,
+// which depends on this package.
+var ParseStmt func(s string) (Stmt, error)
+
 type PlanContext interface {
 	GetFlag(f flags.Flag) bool
 	ResolveTableName(tn sql.TableName) sql.TableName
@@ -9,6 +9,10 @@ accounts = [
         password = "default"
     }
 ]
+// auth = "scram-sha-256"
+// tls-cert = "server.crt"
+// tls-key = "server.key"
+// require-tls = true
 // ssh-authorized-keys = "authorized_keys"
 
 // maho flags
@@ -31,16 +31,16 @@ select * from metadata.tables
     where table_name != 'locks' and table_name != 'transactions' and schema_name != 'private'
     order by table_name
 `,
-			`+---------------+-------------+-------------+
-| database_name | schema_name | table_name  |
-+---------------+-------------+-------------+
-| system        | metadata    | columns     |
-| system        | metadata    | constraints |
-| system        | info        | databases   |
-| system        | info        | identifiers |
-| system        | metadata    | schemas     |
-| system        | metadata    | tables      |
-+---------------+-------------+-------------+
+			`+---------------+-------------+-------------+------------+
+| database_name | schema_name | table_name  | table_type |
++---------------+-------------+-------------+------------+
+| system        | metadata    | columns     | table      |
+| system        | metadata    | constraints | table      |
+| system        | info        | databases   | table      |
+| system        | info        | identifiers | table      |
+| system        | metadata    | schemas     | table      |
+| system        | metadata    | tables      | table      |
++---------------+-------------+-------------+------------+
 (6 rows)
 `},
 		{"select schema_name, table_name, column_name from (show columns from identifiers) as c",
@@ -78,14 +78,14 @@ select * from metadata.tables
 (1 rows)
 `},
 		{"select * from (show tables from metadata) as c order by table_name",
-			`+---------------+-------------+-------------+
-| database_name | schema_name | table_name  |
-+---------------+-------------+-------------+
-| system        | metadata    | columns     |
-| system        | metadata    | constraints |
-| system        | metadata    | schemas     |
-| system        | metadata    | tables      |
-+---------------+-------------+-------------+
+			`+---------------+-------------+-------------+------------+
+| database_name | schema_name | table_name  | table_type |
++---------------+-------------+-------------+------------+
+| system        | metadata    | columns     | table      |
+| system        | metadata    | constraints | table      |
+| system        | metadata    | schemas     | table      |
+| system        | metadata    | tables      | table      |
++---------------+-------------+-------------+------------+
 (4 rows)
 `},
 		{"show schemas",
@@ -99,20 +99,20 @@ select * from metadata.tables
 (3 rows)
 `},
 		{"select * from metadata.tables order by table_name, schema_name",
-			`+---------------+-------------+-------------+
-| database_name | schema_name | table_name  |
-+---------------+-------------+-------------+
-| system        | metadata    | columns     |
-| system        | metadata    | constraints |
-| system        | info        | databases   |
-| system        | private     | databases   |
-| system        | info        | identifiers |
-| system        | metadata    | schemas     |
-| system        | private     | schemas     |
-| system        | private     | sequences   |
-| system        | metadata    | tables      |
-| system        | private     | tables      |
-+---------------+-------------+-------------+
+			`+---------------+-------------+-------------+------------+
+| database_name | schema_name | table_name  | table_type |
++---------------+-------------+-------------+------------+
+| system        | metadata    | columns     | table      |
+| system        | metadata    | constraints | table      |
+| system        | info        | databases   | table      |
+| system        | private     | databases   | table      |
+| system        | info        | identifiers | table      |
+| system        | metadata    | schemas     | table      |
+| system        | private     | schemas     | table      |
+| system        | private     | sequences   | table      |
+| system        | metadata    | tables      | table      |
+| system        | private     | tables      | table      |
++---------------+-------------+-------------+------------+
 (10 rows)
 `},
 		{`select * from metadata.constraints
@@ -124,8 +124,9 @@ order by table_name, schema_name, constraint_name`,
 | system        | metadata    | tables     | NULL            | NOT NULL        | column database_name |
 | system        | metadata    | tables     | NULL            | NOT NULL        | column schema_name   |
 | system        | metadata    | tables     | NULL            | NOT NULL        | column table_name    |
+| system        | metadata    | tables     | NULL            | NOT NULL        | column table_type    |
 +---------------+-------------+------------+-----------------+-----------------+----------------------+
-(3 rows)
+(4 rows)
 `,
 		},
 	}
@@ -3,8 +3,10 @@ package parser
 import (
 	"fmt"
 	"io"
+	"math"
 	"runtime"
 	"strconv"
+	"strings"
 
 	"github.com/leftmike/maho/evaluate"
 	"github.com/leftmike/maho/evaluate/datadef"
@@ -23,6 +25,12 @@ type Parser interface {
 
 const lookBackAmount = 3
 
+func init() {
+	evaluate.ParseStmt = func(s string) (evaluate.Stmt, error) {
+		return NewParser(strings.NewReader(s), s).Parse()
+	}
+}
+
 type parser struct {
 	scanner   scanner.Scanner
 	lookBack  [lookBackAmount]scanner.ScanCtx
@@ -140,8 +148,8 @@ func (p *parser) got() string {
 		return fmt.Sprintf("bytes %v", p.sctx.String)
 	case token.Integer:
 		return fmt.Sprintf("integer %d", p.sctx.Integer)
-	case token.Float:
-		return fmt.Sprintf("float %f", p.sctx.Float)
+	case token.Numeric:
+		return fmt.Sprintf("numeric %s", p.sctx.Numeric)
 	}
 
 	return token.Format(p.sctx.Token)
@@ -259,6 +267,14 @@ func (p *parser) expectEndOfStatement() {
 func (p *parser) parseStmt() evaluate.Stmt {
 	if p.maybeToken(token.EndOfStatement) {
 		return nil
+	} else if p.maybeToken(token.LParen) || p.optionalReserved(sql.TABLE) {
+		// ( query ) ...
+		// TABLE ...
+		p.unscan()
+		return p.parseQuery(p.parseQueryPrimary())
+	} else if p.maybeIdentifier(sql.TRUNCATE) {
+		// TRUNCATE ...
+		return p.parseTruncate()
 	}
 
 	switch p.expectReserved(
@@ -282,8 +298,14 @@ func (p *parser) parseStmt() evaluate.Stmt {
 		sql.UPDATE,
 		sql.USE,
 		sql.VALUES,
+		sql.WITH,
 	) {
 	case sql.ALTER:
+		if p.maybeIdentifier(sql.SEQUENCE) {
+			// ALTER SEQUENCE ...
+			return p.parseAlterSequence()
+		}
+
 		// ALTER TABLE ...
 		p.expectReserved(sql.TABLE)
 		return p.parseAlterTable()
@@ -297,7 +319,16 @@ func (p *parser) parseStmt() evaluate.Stmt {
 		// COPY
 		return p.parseCopy()
 	case sql.CREATE:
-		switch p.expectReserved(sql.DATABASE, sql.INDEX, sql.SCHEMA, sql.TABLE, sql.UNIQUE) {
+		if p.maybeIdentifier(sql.SEQUENCE) {
+			// CREATE SEQUENCE ...
+			return p.parseCreateSequence()
+		} else if p.maybeIdentifier(sql.VIEW) {
+			// CREATE VIEW ...
+			return p.parseCreateView(false)
+		}
+
+		switch p.expectReserved(sql.DATABASE, sql.INDEX, sql.OR, sql.SCHEMA, sql.TABLE,
+			sql.UNIQUE) {
 		case sql.DATABASE:
 			// CREATE DATABASE ...
 			return p.parseCreateDatabase()
@@ -314,12 +345,31 @@ func (p *parser) parseStmt() evaluate.Stmt {
 			// CREATE UNIQUE INDEX ...
 			p.expectReserved(sql.INDEX)
 			return p.parseCreateIndex(true)
+		case sql.OR:
+			// CREATE OR REPLACE VIEW ...
+			if !p.maybeIdentifier(sql.REPLACE) {
+				p.scan()
+				p.error(fmt.Sprintf("expected REPLACE, got %s", p.got()))
+			}
+			if !p.maybeIdentifier(sql.VIEW) {
+				p.scan()
+				p.error(fmt.Sprintf("expected VIEW, got %s", p.got()))
+			}
+			return p.parseCreateView(true)
 		}
 	case sql.DELETE:
 		// DELETE FROM ...
 		p.expectReserved(sql.FROM)
 		return p.parseDelete()
 	case sql.DROP:
+		if p.maybeIdentifier(sql.SEQUENCE) {
+			// DROP SEQUENCE ...
+			return p.parseDropSequence()
+		} else if p.maybeIdentifier(sql.VIEW) {
+			// DROP VIEW ...
+			return p.parseDropView()
+		}
+
 		switch p.expectReserved(sql.DATABASE, sql.INDEX, sql.SCHEMA, sql.TABLE) {
 		case sql.DATABASE:
 			// DROP DATABASE ...
@@ -349,7 +399,7 @@ func (p *parser) parseStmt() evaluate.Stmt {
 		return &misc.Rollback{}
 	case sql.SELECT:
 		// SELECT ...
-		return p.parseSelect()
+		return p.parseQuery(p.parseSelect())
 	case sql.SET:
 		// SET ...
 		return p.parseSet()
@@ -368,7 +418,10 @@ func (p *parser) parseStmt() evaluate.Stmt {
 		return p.parseUse()
 	case sql.VALUES:
 		// VALUES ...
-		return p.parseValues()
+		return p.parseQuery(p.parseValues())
+	case sql.WITH:
+		// WITH ...
+		return p.parseWith(true)
 	}
 
 	return nil
@@ -417,6 +470,9 @@ func (p *parser) parseAlias(required bool) sql.Identifier {
 
 func (p *parser) parseTableAlias() query.FromItem {
 	tn := p.parseTableName()
+	if tn.Database == 0 && tn.Schema == 0 && p.maybeToken(token.LParen) {
+		return p.parseFromFunction(tn.Table)
+	}
 	if p.maybeToken(token.AtSign) {
 		return &query.FromIndexAlias{
 			TableName: tn,
@@ -427,6 +483,25 @@ func (p *parser) parseTableAlias() query.FromItem {
 	return &query.FromTableAlias{TableName: tn, Alias: p.parseAlias(false)}
 }
 
+func (p *parser) parseFromFunction(nam sql.Identifier) query.FromItem {
+	// func '(' [expr [',' ...]] ')' [[AS] alias ['(' column-alias [',' ...] ')']]
+	ff := query.FromFunction{Name: nam}
+	if !p.maybeToken(token.RParen) {
+		for {
+			ff.Args = append(ff.Args, p.parseExpr())
+			if p.maybeToken(token.RParen) {
+				break
+			}
+			p.expectTokens(token.Comma)
+		}
+	}
+	ff.Alias = p.parseAlias(false)
+	if ff.Alias != 0 {
+		ff.ColumnAliases = p.parseColumnAliases()
+	}
+	return ff
+}
+
 func (p *parser) parseColumnAliases() []sql.Identifier {
 	if !p.maybeToken(token.LParen) {
 		return nil
@@ -459,6 +534,144 @@ func (p *parser) parseCreateTable() evaluate.Stmt {
 	return &s
 }
 
+func (p *parser) parseCreateView(replace bool) evaluate.Stmt {
+	// CREATE [OR REPLACE] VIEW [[database '.'] schema '.'] view ['(' column [',' ...] ')']
+	//     AS query
+	var s datadef.CreateView
+	s.Replace = replace
+	s.View = p.parseTableName()
+	s.Columns = p.parseColumnAliases()
+	p.expectReserved(sql.AS)
+	s.Stmt = p.parseQuery(p.parseQueryPrimary())
+	return &s
+}
+
+func (p *parser) parseCreateSequence() evaluate.Stmt {
+	// CREATE SEQUENCE [IF NOT EXISTS] [[database '.'] schema '.'] sequence
+	//     [sequence_option ...]
+	var s datadef.CreateSequence
+
+	if p.optionalReserved(sql.IF) {
+		p.expectReserved(sql.NOT)
+		p.expectReserved(sql.EXISTS)
+		s.IfNotExists = true
+	}
+
+	s.Sequence = p.parseTableName()
+	s.Options = p.parseSequenceOptions(false)
+	return &s
+}
+
+func (p *parser) parseAlterSequence() evaluate.Stmt {
+	// ALTER SEQUENCE [[database '.'] schema '.'] sequence
+	//     {sequence_option | RESTART [[WITH] restart]} ...
+	var s datadef.AlterSequence
+
+	s.Sequence = p.parseTableName()
+	s.Options = p.parseSequenceOptions(true)
+	if s.Options == (datadef.SequenceOptions{}) {
+		p.scan()
+		p.error(fmt.Sprintf("expected a sequence option, got %s", p.got()))
+	}
+	return &s
+}
+
+func (p *parser) parseSequenceValue() *int64 {
+	val := p.expectInteger(math.MinInt64, math.MaxInt64)
+	return &val
+}
+
+func (p *parser) parseSequenceOptions(alter bool) datadef.SequenceOptions {
+	/*
+		sequence_option =
+			  INCREMENT [BY] increment
+			| MINVALUE minvalue | NO MINVALUE
+			| MAXVALUE maxvalue | NO MAXVALUE
+			| START [WITH] start
+			| CACHE cache
+			| [NO] CYCLE
+	*/
+
+	var so datadef.SequenceOptions
+	var minValue, maxValue bool
+	for {
+		if p.maybeIdentifier(sql.INCREMENT) {
+			p.optionalReserved(sql.BY)
+			if so.Increment != nil {
+				p.error("INCREMENT specified more than once")
+			}
+			so.Increment = p.parseSequenceValue()
+		} else if p.maybeIdentifier(sql.MINVALUE) {
+			if minValue {
+				p.error("MINVALUE specified more than once")
+			}
+			minValue = true
+			so.MinValue = p.parseSequenceValue()
+		} else if p.maybeIdentifier(sql.MAXVALUE) {
+			if maxValue {
+				p.error("MAXVALUE specified more than once")
+			}
+			maxValue = true
+			so.MaxValue = p.parseSequenceValue()
+		} else if p.optionalReserved(sql.START) {
+			p.optionalReserved(sql.WITH)
+			if so.Start != nil {
+				p.error("START specified more than once")
+			}
+			so.Start = p.parseSequenceValue()
+		} else if p.maybeIdentifier(sql.CACHE) {
+			if so.Cache != nil {
+				p.error("CACHE specified more than once")
+			}
+			so.Cache = p.parseSequenceValue()
+		} else if p.maybeIdentifier(sql.CYCLE) {
+			if so.Cycle != nil {
+				p.error("CYCLE specified more than once")
+			}
+			cycle := true
+			so.Cycle = &cycle
+		} else if p.optionalReserved(sql.NO) {
+			if p.maybeIdentifier(sql.MINVALUE) {
+				if minValue {
+					p.error("MINVALUE specified more than once")
+				}
+				minValue = true
+				so.NoMinValue = true
+			} else if p.maybeIdentifier(sql.MAXVALUE) {
+				if maxValue {
+					p.error("MAXVALUE specified more than once")
+				}
+				maxValue = true
+				so.NoMaxValue = true
+			} else if p.maybeIdentifier(sql.CYCLE) {
+				if so.Cycle != nil {
+					p.error("CYCLE specified more than once")
+				}
+				cycle := false
+				so.Cycle = &cycle
+			} else {
+				p.scan()
+				p.error(fmt.Sprintf("expected MINVALUE, MAXVALUE, or CYCLE, got %s", p.got()))
+			}
+		} else if alter && p.maybeIdentifier(sql.RESTART) {
+			if so.Restart {
+				p.error("RESTART specified more than once")
+			}
+			so.Restart = true
+			if p.optionalReserved(sql.WITH) {
+				so.RestartWith = p.parseSequenceValue()
+			} else if p.maybeToken(token.Integer) {
+				p.unscan()
+				so.RestartWith = p.parseSequenceValue()
+			}
+		} else {
+			break
+		}
+	}
+
+	return so
+}
+
 func (p *parser) parseKey(unique bool) datadef.IndexKey {
 	key := datadef.IndexKey{
 		Unique: unique,
@@ -644,6 +857,37 @@ var types = map[sql.Identifier]sql.ColumnType{
 	sql.INT4:      {Type: sql.IntegerType, Size: 4},
 	sql.INT8:      {Type: sql.IntegerType, Size: 8},
 	sql.BIGINT:    {Type: sql.IntegerType, Size: 8},
+
+	sql.DATE:        {Type: sql.DateType, Size: 4},
+	sql.TIME:        {Type: sql.TimeType, Size: 8},
+	sql.TIMESTAMP:   {Type: sql.TimestampType, Size: 8},
+	sql.TIMESTAMPTZ: {Type: sql.TimestampTZType, Size: 8},
+	sql.INTERVAL:    {Type: sql.IntervalType, Size: 16},
+
+	sql.NUMERIC: {Type: sql.NumericType},
+	sql.DECIMAL: {Type: sql.NumericType},
+
+	sql.JSON:  {Type: sql.JSONType},
+	sql.JSONB: {Type: sql.JSONBType},
+
+	sql.UUID: {Type: sql.UUIDType, Size: 16},
+}
+
+var serialTypes = map[sql.Identifier]sql.ColumnType{
+	sql.SMALLSERIAL: {Type: sql.IntegerType, Size: 2},
+	sql.SERIAL:      {Type: sql.IntegerType, Size: 4},
+	sql.BIGSERIAL:   {Type: sql.IntegerType, Size: 8},
+}
+
+func (p *parser) parseSerialType() (sql.ColumnType, bool) {
+	// SMALLSERIAL | SERIAL | BIGSERIAL: an integer which is GENERATED BY DEFAULT AS IDENTITY
+	if p.scan() == token.Identifier {
+		if ct, ok := serialTypes[p.sctx.Identifier]; ok {
+			return ct, true
+		}
+	}
+	p.unscan()
+	return sql.ColumnType{}, false
 }
 
 func (p *parser) parseColumnType() sql.ColumnType {
@@ -670,6 +914,17 @@ func (p *parser) parseColumnType() sql.ColumnType {
 			| INTEGER
 			| BIGINT
 			| INT8
+			| DATE
+			| TIME [WITHOUT TIME ZONE]
+			| TIMESTAMP [(WITH | WITHOUT) TIME ZONE]
+			| TIMESTAMPTZ
+			| INTERVAL
+			| NUMERIC ['(' precision [',' scale] ')']
+			| DECIMAL ['(' precision [',' scale] ')']
+			| JSON
+			| JSONB
+			| UUID
+			| data_type '[' [length] ']'
 	*/
 
 	typ := p.expectIdentifier("expected a data type")
@@ -682,6 +937,16 @@ func (p *parser) parseColumnType() sql.ColumnType {
 
 	if typ == sql.DOUBLE {
 		p.maybeIdentifier(sql.PRECISION)
+	} else if typ == sql.TIME || typ == sql.TIMESTAMP {
+		if p.optionalReserved(sql.WITH) {
+			p.expectTimeZone()
+			if typ == sql.TIME {
+				p.error("TIME WITH TIME ZONE is not supported")
+			}
+			ct = types[sql.TIMESTAMPTZ]
+		} else if p.maybeIdentifier(sql.WITHOUT) {
+			p.expectTimeZone()
+		}
 	}
 
 	if ct.Type == sql.StringType || ct.Type == sql.BytesType {
@@ -689,11 +954,37 @@ func (p *parser) parseColumnType() sql.ColumnType {
 			ct.Size = uint32(p.expectInteger(0, sql.MaxColumnSize))
 			p.expectTokens(token.RParen)
 		}
+	} else if ct.Type == sql.NumericType {
+		if p.maybeToken(token.LParen) {
+			ct.Size = uint32(p.expectInteger(1, sql.MaxNumericPrecision))
+			if p.maybeToken(token.Comma) {
+				ct.Scale = uint32(p.expectInteger(0, int64(ct.Size)))
+			}
+			p.expectTokens(token.RParen)
+		}
+	}
+
+	if p.maybeToken(token.LBracket) {
+		if !p.maybeToken(token.RBracket) {
+			p.expectInteger(0, math.MaxInt32)
+			p.expectTokens(token.RBracket)
+		}
+		if p.maybeToken(token.LBracket) {
+			p.error("only one dimensional arrays are supported")
+		}
+		ct.Array = true
 	}
 
 	return ct
 }
 
+func (p *parser) expectTimeZone() {
+	if !p.maybeIdentifier(sql.TIME) || !p.maybeIdentifier(sql.ZONE) {
+		p.scan()
+		p.error(fmt.Sprintf("expected TIME ZONE, got %s", p.got()))
+	}
+}
+
 func makeKeyConstraintName(cn sql.Identifier, key datadef.IndexKey, suffix string) sql.Identifier {
 	if cn != 0 {
 		return cn
@@ -764,6 +1055,7 @@ func (p *parser) parseColumn(s *datadef.CreateTable) {
 			| CHECK '(' expr ')'
 			| REFERENCES [[database '.'] schema '.'] table ['(' column ')']
 			  [ON DELETE referential_action] [ON UPDATE referential_action]
+			| GENERATED {ALWAYS | BY DEFAULT} AS IDENTITY ['(' sequence_option ... ')']
 		referential_action = NO ACTION | RESTRICT | CASCADE | SET NULL | SET DEFAULT
 	*/
 
@@ -775,7 +1067,12 @@ func (p *parser) parseColumn(s *datadef.CreateTable) {
 	}
 	s.Columns = append(s.Columns, nam)
 
-	ct := p.parseColumnType()
+	ct, identity := p.parseSerialType()
+	if identity {
+		s.Identities = append(s.Identities, datadef.Identity{ColNum: len(s.Columns) - 1})
+	} else {
+		ct = p.parseColumnType()
+	}
 
 	var dflt expr.Expr
 	for {
@@ -850,6 +1147,29 @@ func (p *parser) parseColumn(s *datadef.CreateTable) {
 						RefTable: rtn,
 						RefCols:  refCols,
 					}))
+		} else if p.maybeIdentifier(sql.GENERATED) {
+			if identity {
+				p.error("identity specified more than once per column")
+			}
+			identity = true
+
+			id := datadef.Identity{ColNum: len(s.Columns) - 1}
+			if p.maybeIdentifier(sql.ALWAYS) {
+				id.Always = true
+			} else {
+				p.expectReserved(sql.BY)
+				p.expectReserved(sql.DEFAULT)
+			}
+			p.expectReserved(sql.AS)
+			if !p.maybeIdentifier(sql.IDENTITY) {
+				p.scan()
+				p.error(fmt.Sprintf("expected IDENTITY, got %s", p.got()))
+			}
+			if p.maybeToken(token.LParen) {
+				id.Options = p.parseSequenceOptions(false)
+				p.expectTokens(token.RParen)
+			}
+			s.Identities = append(s.Identities, id)
 		} else if cn != 0 {
 			p.error("CONSTRAINT name specified without a constraint")
 		} else {
@@ -857,41 +1177,157 @@ func (p *parser) parseColumn(s *datadef.CreateTable) {
 		}
 	}
 
+	if identity && dflt != nil {
+		p.error(fmt.Sprintf("both DEFAULT and identity specified for column %s", nam))
+	}
+
 	s.ColumnTypes = append(s.ColumnTypes, ct)
 	s.ColumnDefaults = append(s.ColumnDefaults, dflt)
 }
 
+func (p *parser) parseAddColumn() []datadef.AlterAction {
+	p.optionalReserved(sql.COLUMN)
+
+	var ifNotExists bool
+	if p.optionalReserved(sql.IF) {
+		p.expectReserved(sql.NOT)
+		p.expectReserved(sql.EXISTS)
+		ifNotExists = true
+	}
+
+	var ct datadef.CreateTable
+	p.parseColumn(&ct)
+	if len(ct.Identities) > 0 {
+		p.error("SERIAL and identity columns may not be added to a table")
+	}
+
+	ac := datadef.AddColumn{
+		Column:      ct.Columns[0],
+		ColumnType:  ct.ColumnTypes[0],
+		Default:     ct.ColumnDefaults[0],
+		IfNotExists: ifNotExists,
+	}
+	for _, con := range ct.Constraints {
+		if con.Type != sql.DefaultConstraint && con.Type != sql.NotNullConstraint {
+			p.error(fmt.Sprintf("%s constraint not allowed when adding a column", con.Type))
+		}
+		ac.Constraints = append(ac.Constraints, con)
+	}
+
+	actions := []datadef.AlterAction{&ac}
+	for _, fk := range ct.ForeignKeys {
+		actions = append(actions, &datadef.AddForeignKey{*fk})
+	}
+	return actions
+}
+
+func (p *parser) parseAlterType(nam sql.Identifier) datadef.AlterAction {
+	at := datadef.AlterType{
+		Column:     nam,
+		ColumnType: p.parseColumnType(),
+	}
+	if p.optionalReserved(sql.USING) {
+		at.Using = p.parseExpr()
+	}
+	if p.optionalReserved(sql.CASCADE) {
+		at.Cascade = true
+	} else {
+		p.optionalReserved(sql.RESTRICT)
+	}
+	return &at
+}
+
 func (p *parser) parseAlterTable() evaluate.Stmt {
 	// ALTER TABLE table action [',' ...]
+	// ALTER TABLE table RENAME [COLUMN] column TO column [CASCADE | RESTRICT]
+	// ALTER TABLE table RENAME TO table
 	// action =
 	//      ADD [CONSTRAINT constraint] table_constraint
+	//    | ADD [COLUMN] [IF NOT EXISTS] column data_type
+	//      [[CONSTRAINT constraint] column_constraint]
 	//    | DROP CONSTRAINT [IF EXISTS] constraint
+	//    | DROP [COLUMN] [IF EXISTS] column [CASCADE | RESTRICT]
 	//    | ALTER [COLUMN] column DROP DEFAULT
 	//    | ALTER [COLUMN] column DROP NOT NULL
+	//    | ALTER [COLUMN] column SET DEFAULT expr
+	//    | ALTER [COLUMN] column SET NOT NULL
+	//    | ALTER [COLUMN] column [SET DATA] TYPE data_type [USING expr] [CASCADE | RESTRICT]
 	// table_constraint = FOREIGN KEY columns
 	//    REFERENCES [[database '.'] schema '.'] table [columns]
 	//    [ON DELETE referential_action] [ON UPDATE referential_action]
+	// column_constraint =
+	//      DEFAULT expr
+	//    | NOT NULL
+	//    | REFERENCES [[database '.'] schema '.'] table ['(' column ')']
+	//      [ON DELETE referential_action] [ON UPDATE referential_action]
 	// referential_action = NO ACTION | RESTRICT | CASCADE | SET NULL | SET DEFAULT
 	// columns = '(' column [',' ...] ')'
 	var s datadef.AlterTable
 
 	s.Table = p.parseTableName()
 
+	if p.maybeIdentifier(sql.RENAME) {
+		if p.optionalReserved(sql.TO) {
+			s.Actions = append(s.Actions,
+				&datadef.RenameTable{
+					NewName: p.expectIdentifier("expected a table name"),
+				})
+		} else {
+			p.optionalReserved(sql.COLUMN)
+			col := p.expectIdentifier("expected a column name")
+			p.expectReserved(sql.TO)
+			rc := datadef.RenameColumn{
+				Column:  col,
+				NewName: p.expectIdentifier("expected a column name"),
+			}
+			if p.optionalReserved(sql.CASCADE) {
+				rc.Cascade = true
+			} else {
+				p.optionalReserved(sql.RESTRICT)
+			}
+			s.Actions = append(s.Actions, &rc)
+		}
+		return &s
+	}
+
 	for {
 		switch p.expectReserved(sql.ADD, sql.DROP, sql.ALTER) {
 		case sql.ADD:
 			var cn sql.Identifier
 			if p.optionalReserved(sql.CONSTRAINT) {
 				cn = p.expectIdentifier("expected a constraint name")
+				p.expectReserved(sql.FOREIGN)
+			} else if !p.optionalReserved(sql.FOREIGN) {
+				s.Actions = append(s.Actions, p.parseAddColumn()...)
+				break
 			}
 
-			p.expectReserved(sql.FOREIGN)
 			p.expectReserved(sql.KEY)
 
 			fk := p.parseForeignKey(cn)
 			s.Actions = append(s.Actions, &datadef.AddForeignKey{*fk})
 		case sql.DROP:
-			p.expectReserved(sql.CONSTRAINT)
+			if !p.optionalReserved(sql.CONSTRAINT) {
+				p.optionalReserved(sql.COLUMN)
+
+				var ifExists bool
+				if p.optionalReserved(sql.IF) {
+					p.expectReserved(sql.EXISTS)
+					ifExists = true
+				}
+
+				dc := datadef.DropColumn{
+					Column:   p.expectIdentifier("expected a column name"),
+					IfExists: ifExists,
+				}
+				if p.optionalReserved(sql.CASCADE) {
+					dc.Cascade = true
+				} else {
+					p.optionalReserved(sql.RESTRICT)
+				}
+				s.Actions = append(s.Actions, &dc)
+				break
+			}
 
 			var ifExists bool
 			if p.optionalReserved(sql.IF) {
@@ -907,6 +1343,32 @@ func (p *parser) parseAlterTable() evaluate.Stmt {
 		case sql.ALTER:
 			p.optionalReserved(sql.COLUMN)
 			nam := p.expectIdentifier("expected a column name")
+			if p.optionalReserved(sql.SET) {
+				if p.maybeIdentifier(sql.DATA) {
+					if !p.maybeIdentifier(sql.TYPE) {
+						p.scan()
+						p.error(fmt.Sprintf("expected TYPE, got %s", p.got()))
+					}
+					s.Actions = append(s.Actions, p.parseAlterType(nam))
+					break
+				}
+
+				switch p.expectReserved(sql.DEFAULT, sql.NOT) {
+				case sql.DEFAULT:
+					s.Actions = append(s.Actions,
+						&datadef.SetDefault{
+							Column:  nam,
+							Default: p.parseExpr(),
+						})
+				case sql.NOT:
+					p.expectReserved(sql.NULL)
+					s.Actions = append(s.Actions, &datadef.SetNotNull{Column: nam})
+				}
+				break
+			} else if p.maybeIdentifier(sql.TYPE) {
+				s.Actions = append(s.Actions, p.parseAlterType(nam))
+				break
+			}
 			p.expectReserved(sql.DROP)
 
 			var ct sql.ConstraintType
@@ -959,16 +1421,31 @@ func (p *parser) parseCreateIndex(unique bool) evaluate.Stmt {
 }
 
 func (p *parser) parseDelete() evaluate.Stmt {
-	// DELETE FROM [database '.'] table [WHERE expr]
+	/*
+		DELETE FROM [database '.'] table [USING from-item [',' ...]] [WHERE expr]
+			[RETURNING returning]
+	*/
 	var s query.Delete
 	s.Table = p.parseTableName()
+	if p.optionalReserved(sql.USING) {
+		s.Using = p.parseFromList()
+	}
 	if p.optionalReserved(sql.WHERE) {
 		s.Where = p.parseExpr()
 	}
+	s.Returning = p.optionalReturning()
 
 	return &s
 }
 
+func (p *parser) optionalReturning() *query.Returning {
+	// RETURNING ('*' | result [',' ...])
+	if !p.optionalReserved(sql.RETURNING) {
+		return nil
+	}
+	return &query.Returning{Results: p.parseSelectResults()}
+}
+
 func (p *parser) parseDropTable() evaluate.Stmt {
 	// DROP TABLE [IF EXISTS] [database '.' ] table [',' ...] [CASCADE | RESTRICT]
 	var s datadef.DropTable
@@ -991,6 +1468,62 @@ func (p *parser) parseDropTable() evaluate.Stmt {
 	return &s
 }
 
+func (p *parser) parseDropView() evaluate.Stmt {
+	// DROP VIEW [IF EXISTS] [database '.' ] view [',' ...] [CASCADE | RESTRICT]
+	var s datadef.DropView
+	if p.optionalReserved(sql.IF) {
+		p.expectReserved(sql.EXISTS)
+		s.IfExists = true
+	}
+
+	s.Views = []sql.TableName{p.parseTableName()}
+	for p.maybeToken(token.Comma) {
+		s.Views = append(s.Views, p.parseTableName())
+	}
+
+	if p.optionalReserved(sql.CASCADE) {
+		s.Cascade = true
+	} else {
+		p.optionalReserved(sql.RESTRICT)
+	}
+
+	return &s
+}
+
+func (p *parser) parseTruncate() evaluate.Stmt {
+	// TRUNCATE [TABLE] [database '.' ] table [',' ...] [CASCADE | RESTRICT]
+	var s datadef.TruncateTable
+	p.optionalReserved(sql.TABLE)
+
+	s.Tables = []sql.TableName{p.parseTableName()}
+	for p.maybeToken(token.Comma) {
+		s.Tables = append(s.Tables, p.parseTableName())
+	}
+
+	if p.optionalReserved(sql.CASCADE) {
+		s.Cascade = true
+	} else {
+		p.optionalReserved(sql.RESTRICT)
+	}
+
+	return &s
+}
+
+func (p *parser) parseDropSequence() evaluate.Stmt {
+	// DROP SEQUENCE [IF EXISTS] [database '.' ] sequence [',' ...]
+	var s datadef.DropSequence
+	if p.optionalReserved(sql.IF) {
+		p.expectReserved(sql.EXISTS)
+		s.IfExists = true
+	}
+
+	s.Sequences = []sql.TableName{p.parseTableName()}
+	for p.maybeToken(token.Comma) {
+		s.Sequences = append(s.Sequences, p.parseTableName())
+	}
+	return &s
+}
+
 func (p *parser) parseDropIndex() evaluate.Stmt {
 	// DROP INDEX [IF EXISTS] index ON table
 	var s datadef.DropIndex
@@ -1004,21 +1537,23 @@ func (p *parser) parseDropIndex() evaluate.Stmt {
 	return &s
 }
 
+// optionalSubquery parses a subquery, if there is one; the opening parenthesis has already
+// been scanned. A subquery which starts with a parenthesized query might not be followed by the
+// closing parenthesis: for example, ((SELECT ...) + 1). Callers need to handle this case.
 func (p *parser) optionalSubquery() (evaluate.Stmt, bool) {
-	if p.optionalReserved(sql.SELECT) {
-		// ( select )
-		return p.parseSelect(), true
-	} else if p.optionalReserved(sql.VALUES) {
-		// ( values )
-		return p.parseValues(), true
+	paren := p.maybeToken(token.LParen)
+	if p.optionalReserved(sql.SELECT, sql.VALUES, sql.TABLE, sql.WITH) {
+		// ( query )
+		p.unscan()
+		if paren {
+			p.unscan()
+		}
+		return p.parseQuery(p.parseQueryPrimary()), true
+	} else if paren {
+		p.unscan()
 	} else if p.optionalReserved(sql.SHOW) {
 		// ( show )
 		return p.parseShow(), true
-	} else if p.optionalReserved(sql.TABLE) {
-		// ( TABLE [[database .] schema .] table )
-		return &query.Select{
-			From: &query.FromTableAlias{TableName: p.parseTableName()},
-		}, true
 	}
 	return nil, false
 }
@@ -1052,6 +1587,14 @@ func adjustPrecedence(e expr.Expr) expr.Expr {
 			b.Left = e
 			return adjustPrecedence(b)
 		}
+
+		// - {2 IN (3, 4)} --> {- 2} IN (3, 4)
+		if c, ok := postfixCall(e.Expr, e.Op.Precedence()); ok {
+			pe := e.Expr
+			e.Expr = c.Args[0]
+			c.Args[0] = adjustPrecedence(e)
+			return pe
+		}
 	case *expr.Binary:
 		e.Left = adjustPrecedence(e.Left)
 		e.Right = adjustPrecedence(e.Right)
@@ -1069,6 +1612,14 @@ func adjustPrecedence(e expr.Expr) expr.Expr {
 			b.Right = e
 			return adjustPrecedence(b)
 		}
+
+		// 1 + {2 BETWEEN 3 AND 4} --> {1 + 2} BETWEEN 3 AND 4
+		if c, ok := postfixCall(e.Right, e.Op.Precedence()); ok {
+			pe := e.Right
+			e.Right = c.Args[0]
+			c.Args[0] = adjustPrecedence(e)
+			return pe
+		}
 	case *expr.Call:
 		for i, a := range e.Args {
 			e.Args[i] = adjustPrecedence(a)
@@ -1078,6 +1629,33 @@ func adjustPrecedence(e expr.Expr) expr.Expr {
 	return e
 }
 
+var (
+	isNullID     = sql.ID("is_null")
+	betweenID    = sql.ID("between")
+	inID         = sql.ID("in")
+	likeEscapeID = sql.ID("like_escape")
+)
+
+// postfixCall returns the call for IS NULL, BETWEEN, or IN, possibly negated with NOT, if the
+// operator binds less tightly than an operator with precedence prec.
+func postfixCall(e expr.Expr, prec int) (*expr.Call, bool) {
+	if u, ok := e.(*expr.Unary); ok && u.Op == expr.NotOp {
+		e = u.Expr
+	}
+	c, ok := e.(*expr.Call)
+	if !ok || c.Over != nil {
+		return nil, false
+	}
+
+	switch c.Name {
+	case isNullID:
+		return c, prec > expr.NotOp.Precedence()
+	case betweenID, inID:
+		return c, prec > expr.GreaterThanOp.Precedence()
+	}
+	return nil, false
+}
+
 func (p *parser) parseExpr() expr.Expr {
 	return adjustPrecedence(p.parseSubExpr())
 }
@@ -1095,14 +1673,26 @@ expr = literal
     | func '(' [expr [',' ...]] ')'
     | COUNT '(' '*' ')'
     | EXISTS '(' subquery ')'
-    | expr IN '(' subquery ')'
-    | expr NOT IN '(' subquery ')'
+    | expr [NOT] BETWEEN expr AND expr
+    | expr [NOT] (LIKE | ILIKE | SIMILAR TO) expr [ESCAPE expr]
+    | expr [NOT] IN '(' subquery ')'
+    | expr [NOT] IN '(' expr [',' ...] ')'
+    | CASE [expr] WHEN expr THEN expr [WHEN ...] [ELSE expr] END
+    | CAST '(' expr AS data_type ')'
+    | expr '::' data_type
     | expr op ANY '(' subquery ')'
     | expr op SOME '(' subquery ')'
     | expr op ALL '(' subquery ')'
+    | expr op ANY '(' expr ')'
+    | expr op SOME '(' expr ')'
+    | expr op ALL '(' expr ')'
+    | ARRAY '[' [expr [',' ...]] ']'
+    | expr '[' expr ']'
 op = '+' '-' '*' '/' '%'
     | '=' '==' '!=' '<>' '<' '<=' '>' '>='
     | '<<' '>>' '&' '|'
+    | '~' '~*' '!~' '!~*'
+    | '->' '->>' '#>' '#>>' '@>' '?'
     | AND | OR
 subquery = select | values | show
 */
@@ -1129,6 +1719,17 @@ var binaryOps = map[rune]struct {
 	token.Plus:           {expr.AddOp, false},
 	token.Slash:          {expr.DivideOp, false},
 	token.Star:           {expr.MultiplyOp, false},
+	token.Tilde:          {expr.RegexpOp, true},
+	token.TildeStar:      {expr.RegexpIOp, true},
+	token.BangTilde:      {expr.NotRegexpOp, true},
+	token.BangTildeStar:  {expr.NotRegexpIOp, true},
+
+	token.MinusGreater:        {expr.JSONGetOp, false},
+	token.MinusGreaterGreater: {expr.JSONGetTextOp, false},
+	token.HashGreater:         {expr.JSONPathOp, false},
+	token.HashGreaterGreater:  {expr.JSONPathTextOp, false},
+	token.AtGreater:           {expr.JSONContainsOp, true},
+	token.Question:            {expr.JSONHasKeyOp, true},
 }
 
 func (p *parser) optionalBinaryOp() (expr.Op, bool, bool) {
@@ -1141,6 +1742,13 @@ func (p *parser) optionalBinaryOp() (expr.Op, bool, bool) {
 			return expr.AndOp, true, true
 		case sql.OR:
 			return expr.OrOp, true, true
+		case sql.LIKE:
+			return expr.LikeOp, true, true
+		case sql.ILIKE:
+			return expr.ILikeOp, true, true
+		case sql.SIMILAR:
+			p.expectReserved(sql.TO)
+			return expr.SimilarOp, true, true
 		}
 	}
 
@@ -1148,7 +1756,23 @@ func (p *parser) optionalBinaryOp() (expr.Op, bool, bool) {
 	return 0, false, false
 }
 
+func (p *parser) optionalNotMatchOp() (expr.Op, bool) {
+	if p.optionalReserved(sql.LIKE) {
+		return expr.NotLikeOp, true
+	} else if p.optionalReserved(sql.ILIKE) {
+		return expr.NotILikeOp, true
+	} else if p.optionalReserved(sql.SIMILAR) {
+		p.expectReserved(sql.TO)
+		return expr.NotSimilarOp, true
+	}
+	return 0, false
+}
+
 func (p *parser) parseSubExpr() expr.Expr {
+	return p.parseOperators(p.parsePrimary())
+}
+
+func (p *parser) parsePrimary() expr.Expr {
 	var e expr.Expr
 	r := p.scan()
 	if r == token.Reserved {
@@ -1163,6 +1787,16 @@ func (p *parser) parseSubExpr() expr.Expr {
 		} else if p.sctx.Identifier == sql.EXISTS {
 			// EXISTS ( subquery )
 			e = expr.Subquery{Op: expr.Exists, Stmt: p.parseSubquery()}
+		} else if p.sctx.Identifier == sql.CASE {
+			e = p.parseCase()
+		} else if p.sctx.Identifier == sql.CAST {
+			// CAST ( expr AS data_type )
+			p.expectTokens(token.LParen)
+			c := &expr.Cast{Expr: p.parseExpr()}
+			p.expectReserved(sql.AS)
+			c.Type = p.parseColumnType()
+			p.expectTokens(token.RParen)
+			e = c
 		} else {
 			p.error(fmt.Sprintf("unexpected identifier %s", p.sctx.Identifier))
 		}
@@ -1172,17 +1806,41 @@ func (p *parser) parseSubExpr() expr.Expr {
 		e = expr.BytesLiteral(p.sctx.Bytes)
 	} else if r == token.Integer {
 		e = expr.Int64Literal(p.sctx.Integer)
-	} else if r == token.Float {
-		e = expr.Float64Literal(p.sctx.Float)
+	} else if r == token.Numeric {
+		e = expr.NumericLiteral(p.sctx.Numeric)
 	} else if r == token.Parameter {
 		e = expr.Param{Num: int(p.sctx.Integer)}
 	} else if r == token.Identifier {
 		id := p.sctx.Identifier
-		if p.maybeToken(token.LParen) {
-			// func ( expr [,...] )
+		if ct, ok := p.optionalTypedLiteral(id); ok {
+			// DATE | TIME | TIMESTAMP | TIMESTAMPTZ | INTERVAL | NUMERIC | JSON | UUID string
+			e = &expr.Cast{Expr: expr.StringLiteral(p.sctx.String), Type: ct}
+		} else if id == sql.ARRAY && p.maybeToken(token.LBracket) {
+			// ARRAY [ [expr [,...]] ]
+			a := &expr.Array{}
+			if !p.maybeToken(token.RBracket) {
+				for {
+					a.Elems = append(a.Elems, p.parseExpr())
+					if p.maybeToken(token.RBracket) {
+						break
+					}
+					p.expectTokens(token.Comma)
+				}
+			}
+			e = a
+		} else if id == sql.EXTRACT && p.maybeToken(token.LParen) {
+			// EXTRACT ( field FROM expr )
+			e = p.parseExtract()
+		} else if p.maybeToken(token.LParen) {
+			// func ( [ALL | DISTINCT] expr [,...] )
 			c := &expr.Call{Name: id}
 			if !p.maybeToken(token.RParen) {
-				if id == sql.COUNT && p.maybeToken(token.Star) {
+				if p.optionalReserved(sql.DISTINCT) {
+					c.Distinct = true
+				} else {
+					p.optionalReserved(sql.ALL)
+				}
+				if !c.Distinct && id == sql.COUNT && p.maybeToken(token.Star) {
 					p.expectTokens(token.RParen)
 					c.Name = sql.COUNT_ALL
 				} else {
@@ -1195,7 +1853,14 @@ func (p *parser) parseSubExpr() expr.Expr {
 					}
 				}
 			}
+			if p.optionalReserved(sql.OVER) {
+				// func ( ... ) OVER ( window )
+				c.Over = p.parseWindow()
+			}
 			e = c
+		} else if _, ok := sqlValueFunctions[id]; ok {
+			// CURRENT_DATE | CURRENT_TIMESTAMP | LOCALTIMESTAMP
+			e = &expr.Call{Name: id}
 		} else {
 			// ref [. ref]
 			ref := expr.Ref{p.sctx.Identifier}
@@ -1211,6 +1876,12 @@ func (p *parser) parseSubExpr() expr.Expr {
 		if s, ok := p.optionalSubquery(); ok {
 			// ( subquery )
 			e = expr.Subquery{Op: expr.Scalar, Stmt: s}
+			if !p.maybeToken(token.RParen) {
+				// ( ( subquery ) op expr )
+				e = &expr.Unary{Op: expr.NoOp, Expr: p.parseOperators(e)}
+			} else {
+				p.unscan()
+			}
 		} else {
 			// ( expr )
 			e = &expr.Unary{Op: expr.NoOp, Expr: p.parseSubExpr()}
@@ -1222,31 +1893,206 @@ func (p *parser) parseSubExpr() expr.Expr {
 		p.error(fmt.Sprintf("expected an expression, got %s", p.got()))
 	}
 
-	op, ok, bop := p.optionalBinaryOp()
-	if !ok {
-		if p.optionalReserved(sql.IN, sql.NOT, sql.IS) {
-			switch p.sctx.Identifier {
-			case sql.IN:
-				return expr.Subquery{Op: expr.Any, ExprOp: expr.EqualOp, Expr: e,
-					Stmt: p.parseSubquery()}
-			case sql.NOT:
-				if p.optionalReserved(sql.IN) {
-					return expr.Subquery{Op: expr.All, ExprOp: expr.NotEqualOp, Expr: e,
-						Stmt: p.parseSubquery()}
-				}
-				p.unscan()
-			case sql.IS:
-				var not bool
+	for {
+		if p.maybeToken(token.ColonColon) {
+			// expr :: data_type
+			e = &expr.Cast{Expr: e, Type: p.parseColumnType()}
+		} else if p.maybeToken(token.LBracket) {
+			// expr [ expr ]
+			e = &expr.Subscript{Expr: e, Index: p.parseExpr()}
+			p.expectTokens(token.RBracket)
+		} else {
+			break
+		}
+	}
+	return e
+}
+
+var sqlValueFunctions = map[sql.Identifier]struct{}{
+	sql.ID("current_date"):      {},
+	sql.ID("current_timestamp"): {},
+	sql.ID("localtimestamp"):    {},
+}
+
+func (p *parser) optionalTypedLiteral(id sql.Identifier) (sql.ColumnType, bool) {
+	switch id {
+	case sql.DATE, sql.TIME, sql.TIMESTAMP, sql.TIMESTAMPTZ, sql.INTERVAL, sql.NUMERIC,
+		sql.DECIMAL, sql.JSON, sql.JSONB, sql.UUID:
+
+		if p.scan() == token.String {
+			return types[id], true
+		}
+		p.unscan()
+	}
+	return sql.ColumnType{}, false
+}
+
+func (p *parser) parseExtract() expr.Expr {
+	var field string
+	switch p.scan() {
+	case token.Identifier, token.Reserved:
+		field = p.sctx.Identifier.String()
+	case token.String:
+		field = p.sctx.String
+	default:
+		p.error(fmt.Sprintf("expected a field, got %s", p.got()))
+	}
+	p.expectReserved(sql.FROM)
+	e := p.parseExpr()
+	p.expectTokens(token.RParen)
+	return &expr.Call{Name: sql.ID("date_part"), Args: []expr.Expr{expr.StringLiteral(field), e}}
+}
+
+func (p *parser) parseCase() expr.Expr {
+	// CASE [expr] WHEN expr THEN expr [WHEN ...] [ELSE expr] END
+
+	var c expr.Case
+	if !p.optionalReserved(sql.WHEN) {
+		c.Expr = p.parseExpr()
+		p.expectReserved(sql.WHEN)
+	}
+	for {
+		w := expr.When{Expr: p.parseExpr()}
+		p.expectReserved(sql.THEN)
+		w.Result = p.parseExpr()
+		c.Whens = append(c.Whens, w)
+		if !p.optionalReserved(sql.WHEN) {
+			break
+		}
+	}
+	if p.optionalReserved(sql.ELSE) {
+		c.Else = p.parseExpr()
+	}
+	p.expectReserved(sql.END)
+	return &c
+}
+
+func (p *parser) parseWindow() *expr.Window {
+	/*
+		'(' [PARTITION BY expr [',' ...]] [ORDER BY expr [ASC | DESC] [',' ...]] [frame] ')'
+		frame = (ROWS | RANGE) (frame-start | BETWEEN frame-start AND frame-end)
+	*/
+
+	var w expr.Window
+	p.expectTokens(token.LParen)
+	if p.maybeIdentifier(sql.PARTITION) {
+		p.expectReserved(sql.BY)
+		for {
+			w.Partition = append(w.Partition, p.parseExpr())
+			if !p.maybeToken(token.Comma) {
+				break
+			}
+		}
+	}
+	if p.optionalReserved(sql.ORDER) {
+		p.expectReserved(sql.BY)
+		for {
+			by := expr.OrderBy{Expr: p.parseExpr()}
+			if p.optionalReserved(sql.DESC) {
+				by.Reverse = true
+			} else {
+				p.optionalReserved(sql.ASC)
+			}
+			w.OrderBy = append(w.OrderBy, by)
+			if !p.maybeToken(token.Comma) {
+				break
+			}
+		}
+	}
+
+	var mode expr.FrameMode
+	if p.maybeIdentifier(sql.ROWS) {
+		mode = expr.RowsFrame
+	} else if p.maybeIdentifier(sql.RANGE) {
+		mode = expr.RangeFrame
+	} else {
+		p.expectTokens(token.RParen)
+		return &w
+	}
+
+	w.Frame = &expr.WindowFrame{Mode: mode}
+	if p.optionalReserved(sql.BETWEEN) {
+		w.Frame.Start = p.parseFrameBound()
+		p.expectReserved(sql.AND)
+		w.Frame.End = p.parseFrameBound()
+	} else {
+		w.Frame.Start = p.parseFrameBound()
+		w.Frame.End = expr.FrameBound{Type: expr.CurrentRow}
+	}
+	if w.Frame.Start.Type == expr.UnboundedFollowing {
+		p.error("frame start cannot be UNBOUNDED FOLLOWING")
+	} else if w.Frame.End.Type == expr.UnboundedPreceding {
+		p.error("frame end cannot be UNBOUNDED PRECEDING")
+	} else if w.Frame.Start.Type > w.Frame.End.Type {
+		p.error(fmt.Sprintf("frame cannot start with %s and end with %s", w.Frame.Start,
+			w.Frame.End))
+	}
+
+	p.expectTokens(token.RParen)
+	return &w
+}
+
+func (p *parser) parseFrameBound() expr.FrameBound {
+	// UNBOUNDED (PRECEDING | FOLLOWING) | CURRENT ROW | expr (PRECEDING | FOLLOWING)
+
+	if p.maybeIdentifier(sql.UNBOUNDED) {
+		if p.maybeIdentifier(sql.PRECEDING) {
+			return expr.FrameBound{Type: expr.UnboundedPreceding}
+		} else if p.maybeIdentifier(sql.FOLLOWING) {
+			return expr.FrameBound{Type: expr.UnboundedFollowing}
+		}
+		p.scan()
+		p.error(fmt.Sprintf("expected PRECEDING or FOLLOWING, got %s", p.got()))
+	} else if p.maybeIdentifier(sql.CURRENT) {
+		if !p.maybeIdentifier(sql.ROW) {
+			p.scan()
+			p.error(fmt.Sprintf("expected ROW, got %s", p.got()))
+		}
+		return expr.FrameBound{Type: expr.CurrentRow}
+	}
+
+	e := p.parseSubExpr()
+	if p.maybeIdentifier(sql.PRECEDING) {
+		return expr.FrameBound{Type: expr.OffsetPreceding, Offset: e}
+	} else if p.maybeIdentifier(sql.FOLLOWING) {
+		return expr.FrameBound{Type: expr.OffsetFollowing, Offset: e}
+	}
+	p.scan()
+	p.error(fmt.Sprintf("expected PRECEDING or FOLLOWING, got %s", p.got()))
+	return expr.FrameBound{}
+}
+
+func (p *parser) parseOperators(e expr.Expr) expr.Expr {
+	op, ok, bop := p.optionalBinaryOp()
+	if !ok {
+		if p.optionalReserved(sql.IN, sql.NOT, sql.IS, sql.BETWEEN) {
+			switch p.sctx.Identifier {
+			case sql.IN:
+				return p.parseOperators(p.parseIn(e, false))
+			case sql.NOT:
+				if p.optionalReserved(sql.IN) {
+					return p.parseOperators(p.parseIn(e, true))
+				} else if p.optionalReserved(sql.BETWEEN) {
+					return p.parseOperators(
+						&expr.Unary{Op: expr.NotOp, Expr: p.parseBetween(e)})
+				} else if op, ok := p.optionalNotMatchOp(); ok {
+					return p.parseMatch(op, e)
+				}
+				p.unscan()
+			case sql.IS:
+				var not bool
 				if p.optionalReserved(sql.NOT) {
 					not = true
 				}
 				p.expectReserved(sql.NULL)
 
-				e = &expr.Call{Name: sql.ID("is_null"), Args: []expr.Expr{e}}
+				e = &expr.Call{Name: isNullID, Args: []expr.Expr{e}}
 				if not {
-					return &expr.Unary{Op: expr.NotOp, Expr: e}
+					e = &expr.Unary{Op: expr.NotOp, Expr: e}
 				}
-				return e
+				return p.parseOperators(e)
+			case sql.BETWEEN:
+				return p.parseOperators(p.parseBetween(e))
 			}
 		}
 
@@ -1263,12 +2109,94 @@ func (p *parser) parseSubExpr() expr.Expr {
 		} else {
 			subqueryOp = expr.Any
 		}
-		return expr.Subquery{Op: subqueryOp, ExprOp: op, Expr: e, Stmt: p.parseSubquery()}
+		p.expectTokens(token.LParen)
+		if s, ok := p.optionalSubquery(); ok {
+			p.expectTokens(token.RParen)
+			return p.parseOperators(expr.Subquery{Op: subqueryOp, ExprOp: op, Expr: e, Stmt: s})
+		}
+		a := p.parseExpr()
+		p.expectTokens(token.RParen)
+		return p.parseOperators(&expr.ArrayCompare{Op: subqueryOp, ExprOp: op, Expr: e,
+			Array: a})
 	}
 
+	switch op {
+	case expr.LikeOp, expr.ILikeOp, expr.SimilarOp:
+		return p.parseMatch(op, e)
+	}
 	return &expr.Binary{Op: op, Left: e, Right: p.parseSubExpr()}
 }
 
+func (p *parser) parseMatch(op expr.Op, e expr.Expr) expr.Expr {
+	// expr [NOT] (LIKE | ILIKE | SIMILAR TO) expr [ESCAPE expr]
+
+	pattern := p.parseSubExpr()
+	if !p.maybeIdentifier(sql.ESCAPE) {
+		return &expr.Binary{Op: op, Left: e, Right: pattern}
+	}
+
+	pattern = &expr.Call{Name: likeEscapeID, Args: []expr.Expr{pattern, p.parsePrimary()}}
+	return p.parseOperators(&expr.Binary{Op: op, Left: e, Right: pattern})
+}
+
+func (p *parser) parseIn(e expr.Expr, not bool) expr.Expr {
+	// expr [NOT] IN '(' subquery | expr [',' ...] ')'
+
+	p.expectTokens(token.LParen)
+	var first expr.Expr
+	if s, ok := p.optionalSubquery(); ok {
+		if p.maybeToken(token.RParen) {
+			if not {
+				return expr.Subquery{Op: expr.All, ExprOp: expr.NotEqualOp, Expr: e, Stmt: s}
+			}
+			return expr.Subquery{Op: expr.Any, ExprOp: expr.EqualOp, Expr: e, Stmt: s}
+		}
+		first = adjustPrecedence(p.parseOperators(expr.Subquery{Op: expr.Scalar, Stmt: s}))
+	} else {
+		first = p.parseExpr()
+	}
+
+	c := &expr.Call{Name: inID, Args: []expr.Expr{e, first}}
+	for p.maybeToken(token.Comma) {
+		c.Args = append(c.Args, p.parseExpr())
+	}
+	p.expectTokens(token.RParen)
+
+	if not {
+		return &expr.Unary{Op: expr.NotOp, Expr: c}
+	}
+	return c
+}
+
+func (p *parser) parseBetween(e expr.Expr) expr.Expr {
+	// expr [NOT] BETWEEN expr AND expr
+
+	low := adjustPrecedence(p.parseBetweenBound())
+	p.expectReserved(sql.AND)
+	high := adjustPrecedence(p.parseBetweenBound())
+	return &expr.Call{Name: betweenID, Args: []expr.Expr{e, low, high}}
+}
+
+func (p *parser) parseBetweenBound() expr.Expr {
+	// Like parseSubExpr, but stop at AND (or OR) since it separates the bounds.
+
+	var e expr.Expr
+	if p.maybeToken(token.Minus) {
+		e = &expr.Unary{Op: expr.NegateOp, Expr: p.parseBetweenBound()}
+	} else {
+		e = p.parsePrimary()
+	}
+
+	op, ok, _ := p.optionalBinaryOp()
+	if !ok {
+		return e
+	} else if op == expr.AndOp || op == expr.OrOp {
+		p.unscan()
+		return e
+	}
+	return &expr.Binary{Op: op, Left: e, Right: p.parseBetweenBound()}
+}
+
 func (p *parser) parseSubquery() evaluate.Stmt {
 	p.expectTokens(token.LParen)
 	s, ok := p.optionalSubquery()
@@ -1283,12 +2211,36 @@ func (p *parser) parseInsert() evaluate.Stmt {
 	/*
 		INSERT INTO [database '.'] table ['(' column [',' ...] ')']
 			VALUES '(' (expr | DEFAULT) [',' ...] ')' [',' ...]
+		INSERT INTO [database '.'] table ['(' column [',' ...] ')'] query
+		INSERT INTO [database '.'] table DEFAULT VALUES
+			[ON CONFLICT [conflict_target] conflict_action]
+
+		[RETURNING returning]
+
+		conflict_target = '(' column [',' ...] ')' | ON CONSTRAINT constraint
+		conflict_action = DO NOTHING
+			| DO UPDATE SET column '=' (expr | DEFAULT) [',' ...] [WHERE expr]
 	*/
 
 	var s query.InsertValues
 	s.Table = p.parseTableName()
 
+	if p.optionalReserved(sql.DEFAULT) {
+		p.expectReserved(sql.VALUES)
+		s.Rows = [][]expr.Expr{{}}
+		s.OnConflict = p.optionalOnConflict()
+		s.Returning = p.optionalReturning()
+		return &s
+	}
+
 	if p.maybeToken(token.LParen) {
+		if p.optionalReserved(sql.SELECT, sql.VALUES, sql.TABLE, sql.WITH) {
+			// ( query )
+			p.unscan()
+			p.unscan()
+			return p.parseInsertQuery(&s)
+		}
+
 		for {
 			nam := p.expectIdentifier("expected a column name")
 			for _, c := range s.Columns {
@@ -1304,7 +2256,9 @@ func (p *parser) parseInsert() evaluate.Stmt {
 		}
 	}
 
-	p.expectReserved(sql.VALUES)
+	if !p.optionalReserved(sql.VALUES) {
+		return p.parseInsertQuery(&s)
+	}
 
 	for {
 		var row []expr.Expr
@@ -1331,9 +2285,67 @@ func (p *parser) parseInsert() evaluate.Stmt {
 		}
 	}
 
+	s.OnConflict = p.optionalOnConflict()
+	s.Returning = p.optionalReturning()
 	return &s
 }
 
+func (p *parser) parseInsertQuery(s *query.InsertValues) evaluate.Stmt {
+	q, ok := p.optionalSubquery()
+	if !ok {
+		p.scan()
+		p.error(fmt.Sprintf("expected VALUES or a query, got %s", p.got()))
+	}
+	return &query.InsertQuery{
+		Table:      s.Table,
+		Columns:    s.Columns,
+		Query:      q,
+		OnConflict: p.optionalOnConflict(),
+		Returning:  p.optionalReturning(),
+	}
+}
+
+func (p *parser) optionalOnConflict() *query.OnConflict {
+	if !p.optionalReserved(sql.ON) {
+		return nil
+	}
+	if !p.maybeIdentifier(sql.CONFLICT) {
+		p.error("expected CONFLICT")
+	}
+
+	var oc query.OnConflict
+	if p.maybeToken(token.LParen) {
+		for {
+			nam := p.expectIdentifier("expected a column name")
+			for _, c := range oc.Columns {
+				if c == nam {
+					p.error(fmt.Sprintf("duplicate column name %s", nam))
+				}
+			}
+			oc.Columns = append(oc.Columns, nam)
+			r := p.expectTokens(token.Comma, token.RParen)
+			if r == token.RParen {
+				break
+			}
+		}
+	} else if p.optionalReserved(sql.ON) {
+		p.expectReserved(sql.CONSTRAINT)
+		oc.Constraint = p.expectIdentifier("expected a constraint name")
+	}
+
+	p.expectReserved(sql.DO)
+	if p.maybeIdentifier(sql.NOTHING) {
+		return &oc
+	}
+	p.expectReserved(sql.UPDATE)
+	p.expectReserved(sql.SET)
+	oc.ColumnUpdates = p.parseColumnUpdates()
+	if p.optionalReserved(sql.WHERE) {
+		oc.Where = p.parseExpr()
+	}
+	return &oc
+}
+
 func (p *parser) parseCopy() evaluate.Stmt {
 	/*
 		COPY [[database '.'] schema '.'] table '(' column [',' ...] ')' FROM STDIN
@@ -1410,14 +2422,170 @@ func (p *parser) parseValues() *query.Values {
 	return &s
 }
 
+/*
+query = query-term [(UNION | EXCEPT) [ALL | DISTINCT] query-term] ...
+    [ORDER BY column [ASC | DESC] [',' ...]]
+    [LIMIT (expr | ALL)] [OFFSET expr]
+query-term = query-primary [INTERSECT [ALL | DISTINCT] query-primary] ...
+query-primary = select | values | TABLE [[database '.'] schema '.'] table | '(' query ')'
+*/
+
+func (p *parser) parseQuery(s evaluate.Stmt) evaluate.Stmt {
+	s = p.parseSetOps(s)
+
+	orderBy := p.parseOrderBy()
+	limit, offset := p.parseLimit()
+	if orderBy == nil && limit == nil && offset == nil {
+		return s
+	}
+
+	switch s := s.(type) {
+	case *query.Select:
+		if s.OrderBy == nil && s.Limit == nil && s.Offset == nil {
+			s.OrderBy = orderBy
+			s.Limit = limit
+			s.Offset = offset
+			return s
+		}
+	case *query.SetOp:
+		if s.OrderBy == nil && s.Limit == nil && s.Offset == nil {
+			s.OrderBy = orderBy
+			s.Limit = limit
+			s.Offset = offset
+			return s
+		}
+	case *query.Values:
+		if orderBy == nil && s.Limit == nil && s.Offset == nil {
+			s.Limit = limit
+			s.Offset = offset
+			return s
+		}
+	}
+	p.error("unexpected ORDER BY, LIMIT, or OFFSET")
+	return nil
+}
+
+func (p *parser) parseQueryPrimary() evaluate.Stmt {
+	if p.maybeToken(token.LParen) {
+		// ( query )
+		s := p.parseQuery(p.parseQueryPrimary())
+		p.expectTokens(token.RParen)
+		return s
+	}
+
+	switch p.expectReserved(sql.SELECT, sql.TABLE, sql.VALUES, sql.WITH) {
+	case sql.SELECT:
+		// SELECT ...
+		return p.parseSelect()
+	case sql.TABLE:
+		// TABLE [[database .] schema .] table
+		return &query.Select{
+			From: &query.FromTableAlias{TableName: p.parseTableName()},
+		}
+	case sql.VALUES:
+		// VALUES ...
+		return p.parseValues()
+	case sql.WITH:
+		// WITH ... query
+		return p.parseWith(false)
+	}
+	return nil
+}
+
+func (p *parser) parseWith(dml bool) evaluate.Stmt {
+	/*
+		WITH [RECURSIVE] name ['(' column [',' ...] ')'] AS '(' query ')' [',' ...]
+			(delete | insert | query | update)
+	*/
+
+	var s query.With
+	s.Recursive = p.optionalReserved(sql.RECURSIVE)
+	for {
+		var cte query.CommonTableExpr
+		cte.Name = p.expectIdentifier("expected a common table")
+		for _, prev := range s.CTEs {
+			if prev.Name == cte.Name {
+				p.error(fmt.Sprintf("duplicate common table: %s", cte.Name))
+			}
+		}
+		cte.Columns = p.parseColumnAliases()
+		p.expectReserved(sql.AS)
+		p.expectTokens(token.LParen)
+		cte.Stmt = p.parseQuery(p.parseQueryPrimary())
+		p.expectTokens(token.RParen)
+		s.CTEs = append(s.CTEs, cte)
+
+		if !p.maybeToken(token.Comma) {
+			break
+		}
+	}
+
+	if dml && p.optionalReserved(sql.DELETE, sql.INSERT, sql.UPDATE) {
+		switch p.sctx.Identifier {
+		case sql.DELETE:
+			// DELETE FROM ...
+			p.expectReserved(sql.FROM)
+			s.Stmt = p.parseDelete()
+		case sql.INSERT:
+			// INSERT INTO ...
+			p.expectReserved(sql.INTO)
+			s.Stmt = p.parseInsert()
+		case sql.UPDATE:
+			// UPDATE ...
+			s.Stmt = p.parseUpdate()
+		}
+	} else {
+		s.Stmt = p.parseQuery(p.parseQueryPrimary())
+	}
+	return &s
+}
+
+func (p *parser) parseSetQuantifier() bool {
+	// [ALL | DISTINCT]
+
+	if p.optionalReserved(sql.ALL) {
+		return true
+	}
+	p.optionalReserved(sql.DISTINCT)
+	return false
+}
+
+func (p *parser) parseIntersects(s evaluate.Stmt) evaluate.Stmt {
+	for p.optionalReserved(sql.INTERSECT) {
+		s = &query.SetOp{
+			Op:    query.Intersect,
+			All:   p.parseSetQuantifier(),
+			Left:  s,
+			Right: p.parseQueryPrimary(),
+		}
+	}
+	return s
+}
+
+func (p *parser) parseSetOps(s evaluate.Stmt) evaluate.Stmt {
+	s = p.parseIntersects(s)
+	for p.optionalReserved(sql.UNION, sql.EXCEPT) {
+		op := query.Union
+		if p.sctx.Identifier == sql.EXCEPT {
+			op = query.Except
+		}
+		s = &query.SetOp{
+			Op:    op,
+			All:   p.parseSetQuantifier(),
+			Left:  s,
+			Right: p.parseIntersects(p.parseQueryPrimary()),
+		}
+	}
+	return s
+}
+
 /*
 select =
-    SELECT select-list
+    SELECT [ALL | DISTINCT [ON '(' expr [',' ...] ')']] select-list
     [FROM from-item [',' ...]]
     [WHERE expr]
     [GROUP BY expr [',' ...]]
     [HAVING expr]
-    [ORDER BY column [ASC | DESC] [',' ...]]
 select-list = '*'
     | select-item [',' ...]
 select-item = table '.' '*'
@@ -1425,40 +2593,65 @@ select-item = table '.' '*'
     | expr [[AS] column-alias]
 */
 
-func (p *parser) parseSelect() *query.Select {
-	var s query.Select
-	if !p.maybeToken(token.Star) {
-		for {
-			t := p.scan()
-			if t == token.Identifier {
-				tbl := p.sctx.Identifier
-				if p.maybeToken(token.Dot) {
-					if p.maybeToken(token.Star) {
-						// table '.' *
-						s.Results = append(s.Results, query.TableResult{Table: tbl})
-
-						if !p.maybeToken(token.Comma) {
-							break
-						}
-						continue
+func (p *parser) parseSelectResults() []query.SelectResult {
+	// '*' | (table '.' '*' | expr [[ AS ] column-alias]) [',' ...]
+	if p.maybeToken(token.Star) {
+		return nil
+	}
+
+	var results []query.SelectResult
+	for {
+		t := p.scan()
+		if t == token.Identifier {
+			tbl := p.sctx.Identifier
+			if p.maybeToken(token.Dot) {
+				if p.maybeToken(token.Star) {
+					// table '.' *
+					results = append(results, query.TableResult{Table: tbl})
+
+					if !p.maybeToken(token.Comma) {
+						break
 					}
-					p.unscan()
+					continue
 				}
+				p.unscan()
 			}
-			p.unscan()
+		}
+		p.unscan()
 
-			// expr [[ AS ] column-alias]
-			s.Results = append(s.Results, query.ExprResult{
-				Expr:  p.parseExpr(),
-				Alias: p.parseAlias(false),
-			})
+		// expr [[ AS ] column-alias]
+		results = append(results, query.ExprResult{
+			Expr:  p.parseExpr(),
+			Alias: p.parseAlias(false),
+		})
 
-			if !p.maybeToken(token.Comma) {
-				break
+		if !p.maybeToken(token.Comma) {
+			break
+		}
+	}
+	return results
+}
+
+func (p *parser) parseSelect() *query.Select {
+	var s query.Select
+	if p.optionalReserved(sql.DISTINCT) {
+		s.Distinct = true
+		if p.optionalReserved(sql.ON) {
+			p.expectTokens(token.LParen)
+			for {
+				s.DistinctOn = append(s.DistinctOn, p.parseExpr())
+				if !p.maybeToken(token.Comma) {
+					break
+				}
 			}
+			p.expectTokens(token.RParen)
 		}
+	} else {
+		p.optionalReserved(sql.ALL)
 	}
 
+	s.Results = p.parseSelectResults()
+
 	if p.optionalReserved(sql.FROM) {
 		s.From = p.parseFromList()
 	}
@@ -1482,6 +2675,13 @@ func (p *parser) parseSelect() *query.Select {
 		s.Having = p.parseExpr()
 	}
 
+	return &s
+}
+
+func (p *parser) parseOrderBy() []query.OrderBy {
+	// [ORDER BY column [ASC | DESC] [',' ...]]
+
+	var orderBy []query.OrderBy
 	if p.optionalReserved(sql.ORDER) {
 		p.expectReserved(sql.BY)
 
@@ -1493,18 +2693,33 @@ func (p *parser) parseSelect() *query.Select {
 			} else {
 				p.optionalReserved(sql.ASC)
 			}
-			s.OrderBy = append(s.OrderBy, by)
+			orderBy = append(orderBy, by)
 			if !p.maybeToken(token.Comma) {
 				break
 			}
 		}
 	}
+	return orderBy
+}
 
-	return &s
+func (p *parser) parseLimit() (expr.Expr, expr.Expr) {
+	// [LIMIT (expr | ALL)] [OFFSET expr]
+
+	var limit, offset expr.Expr
+	if p.optionalReserved(sql.LIMIT) {
+		if !p.optionalReserved(sql.ALL) {
+			limit = p.parseExpr()
+		}
+	}
+	if p.optionalReserved(sql.OFFSET) {
+		offset = p.parseExpr()
+	}
+	return limit, offset
 }
 
 /*
 from-item = [[database '.'] schema '.'] table ['@' index] [[AS] alias]
+    | func '(' [expr [',' ...]] ')' [[AS] alias ['(' column-alias [',' ...] ')']]
     | '(' select | values | show ')' [AS] alias ['(' column-alias [',' ...] ')']
     | '(' from-item [',' ...] ')'
     | from-item join-type from-item [ON expr | USING '(' join-column [',' ...] ')']
@@ -1519,8 +2734,14 @@ func (p *parser) parseFromItem() query.FromItem {
 	var fi query.FromItem
 	if p.maybeToken(token.LParen) {
 		if s, ok := p.optionalSubquery(); ok {
-			// ( subquery )
-			fi = p.parseFromStmt(s)
+			if p.maybeToken(token.RParen) {
+				// ( subquery )
+				fi = p.parseFromStmt(s)
+			} else {
+				// ( ( subquery ) ... [',' ...] )
+				fi = p.parseCrossJoins(p.parseJoin(p.parseFromStmt(s)))
+				p.expectTokens(token.RParen)
+			}
 		} else {
 			fi = p.parseFromList()
 			p.expectTokens(token.RParen)
@@ -1529,6 +2750,10 @@ func (p *parser) parseFromItem() query.FromItem {
 		fi = p.parseTableAlias()
 	}
 
+	return p.parseJoin(fi)
+}
+
+func (p *parser) parseJoin(fi query.FromItem) query.FromItem {
 	jt := query.NoJoin
 	if p.optionalReserved(sql.JOIN) {
 		jt = query.Join
@@ -1591,7 +2816,10 @@ func (p *parser) parseFromItem() query.FromItem {
 }
 
 func (p *parser) parseFromList() query.FromItem {
-	fi := p.parseFromItem()
+	return p.parseCrossJoins(p.parseFromItem())
+}
+
+func (p *parser) parseCrossJoins(fi query.FromItem) query.FromItem {
 	for p.maybeToken(token.Comma) {
 		fi = query.FromJoin{Left: fi, Right: p.parseFromItem(), Type: query.CrossJoin}
 	}
@@ -1599,17 +2827,34 @@ func (p *parser) parseFromList() query.FromItem {
 }
 
 func (p *parser) parseFromStmt(s evaluate.Stmt) query.FromItem {
-	p.expectTokens(token.RParen)
 	a := p.parseAlias(true)
 	return query.FromStmt{Stmt: s, Alias: a, ColumnAliases: p.parseColumnAliases()}
 }
 
 func (p *parser) parseUpdate() evaluate.Stmt {
-	// UPDATE [database '.'] table SET column '=' (expr | DEFAULT) [',' ...] [WHERE expr]
+	/*
+		UPDATE [database '.'] table SET column '=' (expr | DEFAULT) [',' ...]
+			[FROM from-item [',' ...]] [WHERE expr] [RETURNING returning]
+	*/
 	var s query.Update
 	s.Table = p.parseTableName()
 	p.expectReserved(sql.SET)
+	s.ColumnUpdates = p.parseColumnUpdates()
+	if p.optionalReserved(sql.FROM) {
+		s.From = p.parseFromList()
+	}
 
+	if p.optionalReserved(sql.WHERE) {
+		s.Where = p.parseExpr()
+	}
+	s.Returning = p.optionalReturning()
+
+	return &s
+}
+
+func (p *parser) parseColumnUpdates() []query.ColumnUpdate {
+	// column '=' (expr | DEFAULT) [',' ...]
+	var cus []query.ColumnUpdate
 	for {
 		var cu query.ColumnUpdate
 		cu.Column = p.expectIdentifier("expected a column name")
@@ -1621,17 +2866,12 @@ func (p *parser) parseUpdate() evaluate.Stmt {
 			p.unscan()
 			cu.Expr = p.parseExpr()
 		}
-		s.ColumnUpdates = append(s.ColumnUpdates, cu)
+		cus = append(cus, cu)
 		if !p.maybeToken(token.Comma) {
 			break
 		}
 	}
-
-	if p.optionalReserved(sql.WHERE) {
-		s.Where = p.parseExpr()
-	}
-
-	return &s
+	return cus
 }
 
 func (p *parser) parseSet() evaluate.Stmt {
@@ -1862,8 +3102,8 @@ func (p *parser) parseOptions() map[sql.Identifier]string {
 			val = string(p.sctx.Bytes)
 		case token.Integer:
 			val = strconv.FormatInt(p.sctx.Integer, 10)
-		case token.Float:
-			val = strconv.FormatFloat(p.sctx.Float, 'g', -1, 64)
+		case token.Numeric:
+			val = p.sctx.Numeric.String()
 		default:
 			p.error("expected a value")
 		}
@@ -1910,7 +3150,7 @@ func (p *parser) parseCreateSchema() evaluate.Stmt {
 }
 
 func (p *parser) parseDropSchema() evaluate.Stmt {
-	// DROP SCHEMA [IF EXISTS] [database '.'] schema
+	// DROP SCHEMA [IF EXISTS] [database '.'] schema [CASCADE | RESTRICT]
 	var s datadef.DropSchema
 
 	if p.optionalReserved(sql.IF) {
@@ -1919,6 +3159,13 @@ func (p *parser) parseDropSchema() evaluate.Stmt {
 	}
 
 	s.Schema = p.parseSchemaName()
+
+	if p.optionalReserved(sql.CASCADE) {
+		s.Cascade = true
+	} else {
+		p.optionalReserved(sql.RESTRICT)
+	}
+
 	return &s
 }
 
@@ -1927,22 +3174,26 @@ func (p *parser) parseExplain() evaluate.Stmt {
 
 	var s misc.Explain
 	s.Verbose = p.optionalReserved(sql.VERBOSE)
-	switch p.expectReserved(sql.SELECT) {
+	switch p.expectReserved(sql.SELECT, sql.WITH) {
 	case sql.SELECT:
 		// SELECT ...
-		s.Stmt = p.parseSelect()
+		s.Stmt = p.parseQuery(p.parseSelect())
+	case sql.WITH:
+		// WITH ... select
+		s.Stmt = p.parseWith(false)
 	}
 
 	return s
 }
 
 func (p *parser) parsePrepare() evaluate.Stmt {
-	// PREPARE name AS (delete | insert | select | update | values)
+	// PREPARE name AS (delete | insert | select | update | values | with)
 
 	var s misc.Prepare
 	s.Name = p.expectIdentifier("expected a prepared statement")
 	p.expectReserved(sql.AS)
-	switch p.expectReserved(sql.DELETE, sql.INSERT, sql.SELECT, sql.UPDATE, sql.VALUES) {
+	switch p.expectReserved(sql.DELETE, sql.INSERT, sql.SELECT, sql.UPDATE, sql.VALUES,
+		sql.WITH) {
 	case sql.DELETE:
 		// DELETE FROM ...
 		p.expectReserved(sql.FROM)
@@ -1953,13 +3204,16 @@ func (p *parser) parsePrepare() evaluate.Stmt {
 		s.Stmt = p.parseInsert()
 	case sql.SELECT:
 		// SELECT ...
-		s.Stmt = p.parseSelect()
+		s.Stmt = p.parseQuery(p.parseSelect())
 	case sql.UPDATE:
 		// UPDATE ...
 		s.Stmt = p.parseUpdate()
 	case sql.VALUES:
 		// VALUES ...
-		s.Stmt = p.parseValues()
+		s.Stmt = p.parseQuery(p.parseValues())
+	case sql.WITH:
+		// WITH ...
+		s.Stmt = p.parseWith(true)
 	}
 
 	return &s
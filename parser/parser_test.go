@@ -19,7 +19,7 @@ func TestScan(t *testing.T) {
 	s := `create foobar * 123 (,) 'string' "identifier" ; 456.789`
 	tokens := []rune{token.Reserved, token.Identifier, token.Star, token.Integer, token.LParen,
 		token.Comma, token.RParen, token.String, token.Identifier, token.EndOfStatement,
-		token.Float, token.EOF}
+		token.Numeric, token.EOF}
 	p := newParser(strings.NewReader(s), "scan")
 	for _, e := range tokens {
 		r := p.scan()
@@ -104,7 +104,14 @@ func TestCreateTable(t *testing.T) {
 		{sql: "create table t (c int(256))", fail: true},
 		{sql: "create table t (c char(1,2))", fail: true},
 		{sql: "create table t (c char(-1))", fail: true},
+		{sql: "create table t (c numeric())", fail: true},
+		{sql: "create table t (c numeric(0))", fail: true},
+		{sql: "create table t (c numeric(1001))", fail: true},
+		{sql: "create table t (c numeric(3,4))", fail: true},
+		{sql: "create table t (c decimal(5,2,1))", fail: true},
 		{sql: "create table t (c blob binary)", fail: true},
+		{sql: "create table t (c int[][])", fail: true},
+		{sql: "create table t (c int[)", fail: true},
 		{sql: "create table t (c int binary)", fail: true},
 		{sql: "create table t (c bool binary)", fail: true},
 		{sql: "create table t (c char(123) binary)", fail: true},
@@ -172,6 +179,52 @@ func TestCreateTable(t *testing.T) {
 				ColumnDefaults: []expr.Expr{nil, nil, nil, nil, nil},
 			},
 		},
+		{
+			sql: "create table t (c1 date, c2 time without time zone, c3 timestamp, " +
+				"c4 timestamp with time zone, c5 timestamptz, c6 interval)",
+			stmt: datadef.CreateTable{
+				Table: sql.TableName{Table: sql.ID("t")},
+				Columns: []sql.Identifier{sql.ID("c1"), sql.ID("c2"), sql.ID("c3"), sql.ID("c4"),
+					sql.ID("c5"), sql.ID("c6")},
+				ColumnTypes: []sql.ColumnType{
+					{Type: sql.DateType, Size: 4},
+					{Type: sql.TimeType, Size: 8},
+					{Type: sql.TimestampType, Size: 8},
+					{Type: sql.TimestampTZType, Size: 8},
+					{Type: sql.TimestampTZType, Size: 8},
+					{Type: sql.IntervalType, Size: 16},
+				},
+				ColumnDefaults: []expr.Expr{nil, nil, nil, nil, nil, nil},
+			},
+		},
+		{
+			sql: "create table t (n1 numeric, n2 numeric(10), n3 numeric(12,2), " +
+				"n4 decimal(5, 5))",
+			stmt: datadef.CreateTable{
+				Table:   sql.TableName{Table: sql.ID("t")},
+				Columns: []sql.Identifier{sql.ID("n1"), sql.ID("n2"), sql.ID("n3"), sql.ID("n4")},
+				ColumnTypes: []sql.ColumnType{
+					{Type: sql.NumericType},
+					{Type: sql.NumericType, Size: 10},
+					{Type: sql.NumericType, Size: 12, Scale: 2},
+					{Type: sql.NumericType, Size: 5, Scale: 5},
+				},
+				ColumnDefaults: []expr.Expr{nil, nil, nil, nil},
+			},
+		},
+		{
+			sql: "create table t (a1 int[], a2 text[3], a3 varchar(10)[])",
+			stmt: datadef.CreateTable{
+				Table:   sql.TableName{Table: sql.ID("t")},
+				Columns: []sql.Identifier{sql.ID("a1"), sql.ID("a2"), sql.ID("a3")},
+				ColumnTypes: []sql.ColumnType{
+					{Type: sql.IntegerType, Size: 4, Array: true},
+					{Type: sql.StringType, Size: sql.MaxColumnSize, Array: true},
+					{Type: sql.StringType, Size: 10, Array: true},
+				},
+				ColumnDefaults: []expr.Expr{nil, nil, nil},
+			},
+		},
 		{
 			sql: "create table t (b1 binary(123), b2 varbinary(456), b3 blob(789))",
 			stmt: datadef.CreateTable{
@@ -817,6 +870,17 @@ func TestInsertValues(t *testing.T) {
 		{sql: "insert into t (a, ) values (1, 2)", fail: true},
 		{sql: "insert into t (a, a) values (1, 2)", fail: true},
 		{sql: "insert into t (a, b, a) values (1, 2)", fail: true},
+		{sql: "insert into t default", fail: true},
+		{sql: "insert into t default values (1)", fail: true},
+		{sql: "insert into t (a) default values", fail: true},
+		{sql: "insert into t (a) 1", fail: true},
+		{
+			sql: "insert into t default values",
+			stmt: query.InsertValues{
+				Table: sql.TableName{Table: sql.ID("t")},
+				Rows:  [][]expr.Expr{{}},
+			},
+		},
 		{
 			sql: "insert into t values (1, 'abc', true)",
 			stmt: query.InsertValues{
@@ -865,6 +929,206 @@ func TestInsertValues(t *testing.T) {
 	}
 }
 
+func TestInsertQuery(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt string
+	}{
+		{
+			sql:  "insert into t select * from t2",
+			stmt: "INSERT INTO t SELECT * FROM t2",
+		},
+		{
+			sql:  "insert into t (a, b) select c, d from t2 where c > 1",
+			stmt: "INSERT INTO t (a, b) SELECT c, d FROM t2 WHERE (c > 1)",
+		},
+		{
+			sql:  "insert into t (select 1, 2)",
+			stmt: "INSERT INTO t SELECT 1, 2",
+		},
+		{
+			sql:  "insert into t (a) (values (1), (2))",
+			stmt: "INSERT INTO t (a) VALUES (1), (2)",
+		},
+		{
+			sql:  "insert into t table t2",
+			stmt: "INSERT INTO t SELECT * FROM t2",
+		},
+		{
+			sql:  "insert into t with w as (select 1) select * from w",
+			stmt: "INSERT INTO t WITH w AS (SELECT 1) SELECT * FROM w",
+		},
+		{
+			sql:  "insert into t select * from t2 on conflict do nothing",
+			stmt: "INSERT INTO t SELECT * FROM t2 ON CONFLICT DO NOTHING",
+		},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		stmt, err := p.Parse()
+		if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.sql, err)
+		} else if _, ok := stmt.(*query.InsertQuery); !ok {
+			t.Errorf("Parse(%q) got %T want *query.InsertQuery", c.sql, stmt)
+		} else if stmt.String() != c.stmt {
+			t.Errorf("Parse(%q) got %s want %s", c.sql, stmt, c.stmt)
+		}
+	}
+}
+
+func TestInsertOnConflict(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt string
+		fail bool
+	}{
+		{
+			sql:  "insert into t values (1, 2) on conflict do nothing",
+			stmt: "INSERT INTO t VALUES (1, 2) ON CONFLICT DO NOTHING",
+		},
+		{
+			sql:  "insert into t default values on conflict (a, b) do nothing",
+			stmt: "INSERT INTO t DEFAULT VALUES ON CONFLICT (a, b) DO NOTHING",
+		},
+		{
+			sql:  "insert into t values (1, 2) on conflict on constraint c1 do nothing",
+			stmt: "INSERT INTO t VALUES (1, 2) ON CONFLICT ON CONSTRAINT c1 DO NOTHING",
+		},
+		{
+			sql: "insert into t (a, b) values (1, 2) on conflict (a) do update set b = excluded.b",
+			stmt: "INSERT INTO t (a, b) VALUES (1, 2) ON CONFLICT (a) DO UPDATE SET " +
+				"b = excluded.b",
+		},
+		{
+			sql: "insert into t values (1, 2) on conflict (a) do update set b = t.b + 1, " +
+				"c = default where t.b < excluded.b",
+			stmt: "INSERT INTO t VALUES (1, 2) ON CONFLICT (a) DO UPDATE SET b = (t.b + 1), " +
+				"c = DEFAULT WHERE (t.b < excluded.b)",
+		},
+		{sql: "insert into t values (1) on", fail: true},
+		{sql: "insert into t values (1) on conflict", fail: true},
+		{sql: "insert into t values (1) on conflict () do nothing", fail: true},
+		{sql: "insert into t values (1) on conflict (a, a) do nothing", fail: true},
+		{sql: "insert into t values (1) on conflict on c1 do nothing", fail: true},
+		{sql: "insert into t values (1) on conflict do", fail: true},
+		{sql: "insert into t values (1) on conflict do update", fail: true},
+		{sql: "insert into t values (1) on conflict do update set", fail: true},
+		{sql: "insert into t values (1) on conflict do update set a = 1 where", fail: true},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		stmt, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.sql, err)
+		} else if stmt.String() != c.stmt {
+			t.Errorf("Parse(%q) got %s want %s", c.sql, stmt, c.stmt)
+		}
+	}
+}
+
+func TestReturning(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt string
+		fail bool
+	}{
+		{
+			sql:  "insert into t values (1, 2) returning *",
+			stmt: "INSERT INTO t VALUES (1, 2) RETURNING *",
+		},
+		{
+			sql:  "insert into t default values returning a, b + 1 as c",
+			stmt: "INSERT INTO t DEFAULT VALUES RETURNING a, (b + 1) AS c",
+		},
+		{
+			sql:  "insert into t select * from t2 returning t.*",
+			stmt: "INSERT INTO t SELECT * FROM t2 RETURNING t.*",
+		},
+		{
+			sql: "insert into t values (1) on conflict (a) do update set b = 2 where t.b > 1 " +
+				"returning a",
+			stmt: "INSERT INTO t VALUES (1) ON CONFLICT (a) DO UPDATE SET b = 2 " +
+				"WHERE (t.b > 1) RETURNING a",
+		},
+		{
+			sql:  "update t set a = 1 where b = 2 returning *",
+			stmt: "UPDATE t SET a = 1 WHERE (b == 2) RETURNING *",
+		},
+		{
+			sql:  "delete from t returning a, b",
+			stmt: "DELETE FROM t RETURNING a, b",
+		},
+		{sql: "insert into t values (1) returning", fail: true},
+		{sql: "update t set a = 1 returning a,", fail: true},
+		{sql: "delete from t returning * where a = 1", fail: true},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		stmt, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.sql, err)
+		} else if stmt.String() != c.stmt {
+			t.Errorf("Parse(%q) got %s want %s", c.sql, stmt, c.stmt)
+		}
+	}
+}
+
+func TestUpdateFromDeleteUsing(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt string
+		fail bool
+	}{
+		{
+			sql:  "update t set a = t2.a from t2 where t.b = t2.b",
+			stmt: "UPDATE t SET a = t2.a FROM t2 WHERE (t.b == t2.b)",
+		},
+		{
+			sql: "update t set a = x.a, c = y.c from t2 as x, t3 y where t.b = x.b returning *",
+			stmt: "UPDATE t SET a = x.a, c = y.c FROM t2 AS x CROSS JOIN t3 AS y " +
+				"WHERE (t.b == x.b) RETURNING *",
+		},
+		{
+			sql:  "delete from t using t2 where t.a = t2.a",
+			stmt: "DELETE FROM t USING t2 WHERE (t.a == t2.a)",
+		},
+		{
+			sql: "delete from t using t2 join t3 on t2.b = t3.b where t.a = t2.a returning t.*",
+			stmt: "DELETE FROM t USING t2 JOIN t3 ON (t2.b == t3.b) WHERE (t.a == t2.a) " +
+				"RETURNING t.*",
+		},
+		{sql: "update t set a = 1 from where a = 2", fail: true},
+		{sql: "update t from t2 set a = 1", fail: true},
+		{sql: "delete from t using", fail: true},
+		{sql: "delete from t where a = 1 using t2", fail: true},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		stmt, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.sql, err)
+		} else if stmt.String() != c.stmt {
+			t.Errorf("Parse(%q) got %s want %s", c.sql, stmt, c.stmt)
+		}
+	}
+}
+
 func TestParseExpr(t *testing.T) {
 	cases := []struct {
 		sql  string
@@ -908,6 +1172,79 @@ func TestParseExpr(t *testing.T) {
 		{"(c1 + c2) not in (values (1), (2), (3))", "(c1 + c2) != ALL(VALUES (1), (2), (3))"},
 		{"c1 > some(select * from t1)", "c1 > ANY(SELECT * FROM t1)"},
 		{"c1 <= all(select c1 from t1)", "c1 <= ALL(SELECT c1 FROM t1)"},
+		{"row_number() over ()", "row_number() OVER ()"},
+		{"rank() over (partition by c1, c2 order by c3 desc, c4)",
+			"rank() OVER (PARTITION BY c1, c2 ORDER BY c3 DESC, c4 ASC)"},
+		{"sum(c1) over (order by c2 rows 2 preceding)",
+			"sum(c1) OVER (ORDER BY c2 ASC ROWS BETWEEN 2 PRECEDING AND CURRENT ROW)"},
+		{"count(*) over (range between unbounded preceding and unbounded following)",
+			"count_all() OVER (RANGE BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)"},
+		{"avg(c1) over (order by c1 range between 1 + 2 preceding and 3 following) * 2",
+			"(avg(c1) OVER (ORDER BY c1 ASC RANGE BETWEEN (1 + 2) PRECEDING AND 3 FOLLOWING) * 2)"},
+		{"lag(c1) over (rows between current row and current row)",
+			"lag(c1) OVER (ROWS BETWEEN CURRENT ROW AND CURRENT ROW)"},
+		{"c1 in (1, 2, 3)", "in(c1, 1, 2, 3)"},
+		{"c1 not in (1, 2 + 3)", "(NOT in(c1, 1, (2 + 3)))"},
+		{"c1 + 1 in (2) and c2", "(in((c1 + 1), 2) AND c2)"},
+		{"c1 in ((select c2 from t1), 3)", "in(c1, (SELECT c2 FROM t1), 3)"},
+		{"c1 in ((select c2 from t1) + 1)", "in(c1, ((SELECT c2 FROM t1) + 1))"},
+		{"c1 between 1 and 10", "between(c1, 1, 10)"},
+		{"c1 not between 1 and 10", "(NOT between(c1, 1, 10))"},
+		{"c1 between - c0 * 2 and 1 + 2 and c2 = 3",
+			"(between(c1, ((- c0) * 2), (1 + 2)) AND (c2 == 3))"},
+		{"c1 * 2 between c2 and c3 or c4", "(between((c1 * 2), c2, c3) OR c4)"},
+		{"c1 = c2 between c3 and c4", "(c1 == between(c2, c3, c4))"},
+		{"c1 is null and c2 is not null", "(is_null(c1) AND (NOT is_null(c2)))"},
+		{"c1 + c2 is null", "is_null((c1 + c2))"},
+		{"case when c1 = 1 then 'one' when c1 = 2 then 'two' else 'many' end",
+			"CASE WHEN (c1 == 1) THEN 'one' WHEN (c1 == 2) THEN 'two' ELSE 'many' END"},
+		{"case c1 + 1 when 1 then c2 * 2 end + 3", "(CASE (c1 + 1) WHEN 1 THEN (c2 * 2) END + 3)"},
+		{"coalesce(c1, nullif(c2, 0), greatest(c3, 1), least(c4, 2))",
+			"coalesce(c1, nullif(c2, 0), greatest(c3, 1), least(c4, 2))"},
+		{"c1 like 'a%'", "(c1 LIKE 'a%')"},
+		{"c1 not ilike 'x' escape '!'", "(c1 NOT ILIKE like_escape('x', '!'))"},
+		{"c1 similar to 'a|b' and c2 not similar to 'c'",
+			"((c1 SIMILAR TO 'a|b') AND (c2 NOT SIMILAR TO 'c'))"},
+		{"c1 ~ 'a' or c2 !~* 'b'", "((c1 ~ 'a') OR (c2 !~* 'b'))"},
+		{"c1 ~* c2 || 'x' and c3 !~ 'y'", "((c1 ~* (c2 || 'x')) AND (c3 !~ 'y'))"},
+		{"c1 || 'x' like 'y' || c2", "((c1 || 'x') LIKE ('y' || c2))"},
+		{"c1 like 'a' = c2 not like 'b'", "((c1 LIKE 'a') == (c2 NOT LIKE 'b'))"},
+		{"not c1 like 'a'", "(NOT (c1 LIKE 'a'))"},
+		{"cast(c1 as int)", "CAST(c1 AS INT)"},
+		{"cast(c1 + 1 as varchar(10)) || 'x'", "(CAST((c1 + 1) AS VARCHAR(10)) || 'x')"},
+		{"c1::text", "CAST(c1 AS TEXT)"},
+		{"'123'::bigint + c2::double precision",
+			"(CAST('123' AS BIGINT) + CAST(c2 AS DOUBLE))"},
+		{"(c1 + c2)::char(3)", "CAST((c1 + c2) AS CHAR(3))"},
+		{"- c1::int", "(- CAST(c1 AS INT))"},
+		{"c1::int::text", "CAST(CAST(c1 AS INT) AS TEXT)"},
+		{"$1::smallint", "CAST($1 AS SMALLINT)"},
+		{"date '2020-01-02' + interval '1 day'",
+			"(CAST('2020-01-02' AS DATE) + CAST('1 day' AS INTERVAL))"},
+		{"timestamp '2020-01-02 03:04' - c1", "(CAST('2020-01-02 03:04' AS TIMESTAMP) - c1)"},
+		{"numeric '1.5' * c1::decimal(8,2)",
+			"(CAST('1.5' AS NUMERIC) * CAST(c1 AS NUMERIC(8,2)))"},
+		{"c1::timestamp with time zone", "CAST(c1 AS TIMESTAMPTZ)"},
+		{"c1 -> 'a' ->> 'b'", "((c1 -> 'a') ->> 'b')"},
+		{"c1->-1", "(c1 -> -1)"},
+		{"c1 #> '{a,0}' = c2 #>> '{b}'", "((c1 #> '{a,0}') == (c2 #>> '{b}'))"},
+		{`c1 @> '{"a": 1}' and c1 ? 'b'`, `((c1 @> '{"a": 1}') AND (c1 ? 'b'))`},
+		{`jsonb '{"a": 1}' || c1::json`, `(CAST('{"a": 1}' AS JSONB) || CAST(c1 AS JSON))`},
+		{"uuid 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11' = c1::uuid",
+			"(CAST('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11' AS UUID) == CAST(c1 AS UUID))"},
+		{"gen_random_uuid()", "gen_random_uuid()"},
+		{"array[1, c1 + 2, null]", "ARRAY[1, (c1 + 2), NULL]"},
+		{"array[]::int[]", "CAST(ARRAY[] AS INT[])"},
+		{"c1[1] + c1[c2 - 1]", "(c1[1] + c1[(c2 - 1)])"},
+		{"'{1,2}'::int[]", "CAST('{1,2}' AS INT[])"},
+		{"c1 = any(c2)", "c1 == ANY(c2)"},
+		{"c1 < some(array[1, 2])", "c1 < ANY(ARRAY[1, 2])"},
+		{"c1 != all('{1,2}')", "c1 != ALL('{1,2}')"},
+		{"c1 = any(c2) or c3 > all(select c4 from t1)",
+			"(c1 == ANY(c2) OR c3 > ALL(SELECT c4 FROM t1))"},
+		{"extract(year from c1)", "date_part('year', c1)"},
+		{"current_date", "current_date()"},
+		{"now() - current_timestamp", "(now() - current_timestamp())"},
 	}
 
 	for i, c := range cases {
@@ -931,12 +1268,48 @@ func TestParseExpr(t *testing.T) {
 		"exists()",
 		"exists(1 + 2)",
 		"exists(select * show schema)",
-		"c1 in (1, 2, 3)",
 		"c1 in (select * from tbl1, select * from tbl2)",
-		"c1 not in (1, 2, 3)",
+		"c1 in ()",
+		"c1 in (1, 2",
+		"c1 between 1",
+		"c1 not between and 2",
 		"(c1 not (1, 2, 3))",
 		"(c1 all = (select * from t1))",
 		"(c1 + any(select c2 from t1)",
+		"rank() over",
+		"rank() over (",
+		"rank() over (partition c1)",
+		"rank() over (order by c1 rows)",
+		"rank() over (rows unbounded following)",
+		"rank() over (rows between 1 preceding)",
+		"rank() over (rows between current row and unbounded preceding)",
+		"rank() over (rows between 1 following and current row)",
+		"rank() over (rows between current row and 1 preceding)",
+		"rank() over (rows between current and 1 following)",
+		"rank() over (rows 1)",
+		"case end",
+		"case when c1 end",
+		"case when c1 then 1",
+		"case c1 else 2 end",
+		"c1 similar 'x'",
+		"c1 like",
+		"c1 not like 'a' escape",
+		"c1 ~~ 'a'",
+		"cast(c1)",
+		"cast(c1 as)",
+		"cast(c1 as int",
+		"cast(c1 int)",
+		"c1::",
+		"c1::abc",
+		"c1 # 'a'",
+		"c1 ->",
+		"c1 @>> 'a'",
+		"array[1, 2",
+		"array[1 2]",
+		"c1[1",
+		"c1[]",
+		"c1 = any(c2",
+		"c1::int[][]",
 	}
 
 	for i, f := range fails {
@@ -1046,6 +1419,21 @@ func TestSelect(t *testing.T) {
 				},
 			},
 		},
+		{
+			sql: "select [c 1], [t].c2[1] from [t]",
+			stmt: query.Select{
+				From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.QuotedID("t")}},
+				Results: []query.SelectResult{
+					query.ExprResult{Expr: expr.Ref{sql.QuotedID("c 1")}},
+					query.ExprResult{
+						Expr: &expr.Subscript{
+							Expr:  expr.Ref{sql.QuotedID("t"), sql.ID("c2")},
+							Index: expr.Int64Literal(1),
+						},
+					},
+				},
+			},
+		},
 		{
 			sql: "select t.*, c1, c2 from t",
 			stmt: query.Select{
@@ -1132,6 +1520,32 @@ func TestSelect(t *testing.T) {
 				},
 			},
 		},
+		{
+			sql: "select * from jsonb_array_elements('[1, 2]')",
+			stmt: query.Select{
+				From: query.FromFunction{
+					Name: sql.ID("jsonb_array_elements"),
+					Args: []expr.Expr{expr.StringLiteral("[1, 2]")},
+				},
+			},
+		},
+		{
+			sql: "select * from t, json_array_elements(t.c) as e (v)",
+			stmt: query.Select{
+				From: query.FromJoin{
+					Left: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+					Right: query.FromFunction{
+						Name:          sql.ID("json_array_elements"),
+						Args:          []expr.Expr{expr.Ref{sql.ID("t"), sql.ID("c")}},
+						Alias:         sql.ID("e"),
+						ColumnAliases: []sql.Identifier{sql.ID("v")},
+					},
+					Type: query.CrossJoin,
+				},
+			},
+		},
+		{sql: "select * from f(1", fail: true},
+		{sql: "select * from s.f(1)", fail: true},
 		{
 			sql: "select * from t1, t2, t3",
 			stmt: query.Select{
@@ -1395,6 +1809,79 @@ func TestSelect(t *testing.T) {
 					Right: expr.Int64Literal(1)},
 			},
 		},
+		{sql: "select distinct on c from t", fail: true},
+		{sql: "select distinct on (c from t", fail: true},
+		{sql: "select distinct on () c from t", fail: true},
+		{
+			sql: "select distinct c from t",
+			stmt: query.Select{
+				Distinct: true,
+				From:     &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+				Results: []query.SelectResult{
+					query.ExprResult{Expr: expr.Ref{sql.ID("c")}},
+				},
+			},
+		},
+		{
+			sql: "select all * from t",
+			stmt: query.Select{
+				From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+			},
+		},
+		{
+			sql: "select distinct on (c, d) * from t",
+			stmt: query.Select{
+				Distinct:   true,
+				DistinctOn: []expr.Expr{expr.Ref{sql.ID("c")}, expr.Ref{sql.ID("d")}},
+				From:       &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+			},
+		},
+		{
+			sql: "select count(distinct c), count(all d) from t",
+			stmt: query.Select{
+				From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+				Results: []query.SelectResult{
+					query.ExprResult{
+						Expr: &expr.Call{Name: sql.ID("count"), Distinct: true,
+							Args: []expr.Expr{expr.Ref{sql.ID("c")}}},
+					},
+					query.ExprResult{
+						Expr: &expr.Call{Name: sql.ID("count"),
+							Args: []expr.Expr{expr.Ref{sql.ID("d")}}},
+					},
+				},
+			},
+		},
+		{sql: "select * from t limit", fail: true},
+		{sql: "select * from t offset", fail: true},
+		{sql: "select * from t offset 1 limit 2", fail: true},
+		{
+			sql: "select * from t limit 10",
+			stmt: query.Select{
+				From:  &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+				Limit: expr.Int64Literal(10),
+			},
+		},
+		{
+			sql: "select * from t limit all offset $1",
+			stmt: query.Select{
+				From:   &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+				Offset: expr.Param{Num: 1},
+			},
+		},
+		{
+			sql: "select c from t order by c limit 2 + 3 offset 4",
+			stmt: query.Select{
+				From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+				Results: []query.SelectResult{
+					query.ExprResult{Expr: expr.Ref{sql.ID("c")}},
+				},
+				OrderBy: []query.OrderBy{{Expr: expr.Ref{sql.ID("c")}}},
+				Limit: &expr.Binary{Op: expr.AddOp, Left: expr.Int64Literal(2),
+					Right: expr.Int64Literal(3)},
+				Offset: expr.Int64Literal(4),
+			},
+		},
 	}
 
 	for i, c := range cases {
@@ -1414,65 +1901,252 @@ func TestSelect(t *testing.T) {
 	}
 }
 
-func TestValues(t *testing.T) {
+func TestSetOp(t *testing.T) {
+	t1 := &query.Select{From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t1")}}}
+	t2 := &query.Select{From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t2")}}}
+	t3 := &query.Select{From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t3")}}}
+
 	cases := []struct {
 		sql  string
-		stmt query.Values
+		stmt evaluate.Stmt
 		fail bool
 	}{
-		{sql: "values", fail: true},
-		{sql: "values (", fail: true},
-		{sql: "values ()", fail: true},
-		{sql: "values (1", fail: true},
-		{sql: "values (1, 2", fail: true},
-		{sql: "values (1 2)", fail: true},
-		{sql: "values (1, 2), (3)", fail: true},
-		{sql: "values (1, 2, 3), (4, 5), (6, 7, 8)", fail: true},
+		{sql: "select * from t1 union", fail: true},
+		{sql: "select * from t1 union all all select * from t2", fail: true},
+		{sql: "select * from t1 limit 1 union select * from t2", fail: true},
+		{sql: "(select * from t1 limit 1) limit 2", fail: true},
+		{sql: "(select * from t1 union select * from t2", fail: true},
 		{
-			sql: "values (1, 'abc', true)",
-			stmt: query.Values{
-				Expressions: [][]expr.Expr{
-					{expr.Int64Literal(1), expr.StringLiteral("abc"), expr.True()},
-				},
+			sql:  "select * from t1 union select * from t2",
+			stmt: &query.SetOp{Op: query.Union, Left: t1, Right: t2},
+		},
+		{
+			sql:  "select * from t1 union distinct table t2",
+			stmt: &query.SetOp{Op: query.Union, Left: t1, Right: t2},
+		},
+		{
+			sql:  "select * from t1 except all select * from t2",
+			stmt: &query.SetOp{Op: query.Except, All: true, Left: t1, Right: t2},
+		},
+		{
+			sql: "select * from t1 union select * from t2 intersect select * from t3",
+			stmt: &query.SetOp{
+				Op:    query.Union,
+				Left:  t1,
+				Right: &query.SetOp{Op: query.Intersect, Left: t2, Right: t3},
 			},
 		},
 		{
-			sql: "values (1, 'abc', true), (2, 'def', false)",
-			stmt: query.Values{
-				Expressions: [][]expr.Expr{
-					{expr.Int64Literal(1), expr.StringLiteral("abc"), expr.True()},
-					{expr.Int64Literal(2), expr.StringLiteral("def"), expr.False()},
-				},
+			sql: "select * from t1 intersect all select * from t2 except select * from t3",
+			stmt: &query.SetOp{
+				Op:    query.Except,
+				Left:  &query.SetOp{Op: query.Intersect, All: true, Left: t1, Right: t2},
+				Right: t3,
+			},
+		},
+		{
+			sql: "select * from t1 union (select * from t2 union all select * from t3)",
+			stmt: &query.SetOp{
+				Op:    query.Union,
+				Left:  t1,
+				Right: &query.SetOp{Op: query.Union, All: true, Left: t2, Right: t3},
+			},
+		},
+		{
+			sql: "(table t1) union table t2 order by c limit 10",
+			stmt: &query.SetOp{
+				Op:      query.Union,
+				Left:    t1,
+				Right:   t2,
+				OrderBy: []query.OrderBy{{Expr: expr.Ref{sql.ID("c")}}},
+				Limit:   expr.Int64Literal(10),
+			},
+		},
+		{
+			sql: "values (1) union values (2)",
+			stmt: &query.SetOp{
+				Op:    query.Union,
+				Left:  &query.Values{Expressions: [][]expr.Expr{{expr.Int64Literal(1)}}},
+				Right: &query.Values{Expressions: [][]expr.Expr{{expr.Int64Literal(2)}}},
 			},
 		},
 	}
 
 	for i, c := range cases {
 		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
-		vs, err := p.Parse()
+		stmt, err := p.Parse()
 		if c.fail {
 			if err == nil {
 				t.Errorf("Parse(%q) did not fail", c.sql)
 			}
-		} else {
-			if err != nil {
-				t.Errorf("Parse(%q) failed with %s", c.sql, err)
-			} else if vs, ok := vs.(*query.Values); !ok || !reflect.DeepEqual(&c.stmt, vs) {
-				t.Errorf("Parse(%q) got %s want %s", c.sql, vs.String(), c.stmt.String())
-			}
+		} else if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.sql, err)
+		} else if !reflect.DeepEqual(c.stmt, stmt) {
+			t.Errorf("Parse(%q) got %s want %s", c.sql, stmt, c.stmt)
 		}
 	}
 }
 
-func TestDelete(t *testing.T) {
+func TestWith(t *testing.T) {
+	t1 := &query.Select{From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t1")}}}
+	t2 := &query.Select{From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t2")}}}
+
 	cases := []struct {
 		sql  string
-		stmt query.Delete
+		stmt evaluate.Stmt
 		fail bool
 	}{
-		{sql: "delete", fail: true},
-		{sql: "delete t", fail: true},
-		{sql: "delete from", fail: true},
+		{sql: "with", fail: true},
+		{sql: "with c1 select * from c1", fail: true},
+		{sql: "with c1 as select * from t1 select * from c1", fail: true},
+		{sql: "with c1 as (select * from t1) c2 as (table t2) table c1", fail: true},
+		{sql: "with c1 as (table t1), c1 as (table t2) table c1", fail: true},
+		{sql: "with c1 () as (table t1) table c1", fail: true},
+		{sql: "with c1 as (table t1)", fail: true},
+		{sql: "with c1 as (table t1) show database", fail: true},
+		{
+			sql: "with c1 as (table t1) table c1",
+			stmt: &query.With{
+				CTEs: []query.CommonTableExpr{{Name: sql.ID("c1"), Stmt: t1}},
+				Stmt: &query.Select{
+					From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("c1")}},
+				},
+			},
+		},
+		{
+			sql: "with recursive c1 (a, b) as (table t1 union all table t2), c2 as (table t2) " +
+				"select * from c1 union table c2",
+			stmt: &query.With{
+				Recursive: true,
+				CTEs: []query.CommonTableExpr{
+					{
+						Name:    sql.ID("c1"),
+						Columns: []sql.Identifier{sql.ID("a"), sql.ID("b")},
+						Stmt:    &query.SetOp{Op: query.Union, All: true, Left: t1, Right: t2},
+					},
+					{Name: sql.ID("c2"), Stmt: t2},
+				},
+				Stmt: &query.SetOp{
+					Op: query.Union,
+					Left: &query.Select{
+						From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("c1")}},
+					},
+					Right: &query.Select{
+						From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("c2")}},
+					},
+				},
+			},
+		},
+		{
+			sql: "with c1 as (table t1) delete from t2",
+			stmt: &query.With{
+				CTEs: []query.CommonTableExpr{{Name: sql.ID("c1"), Stmt: t1}},
+				Stmt: &query.Delete{Table: sql.TableName{Table: sql.ID("t2")}},
+			},
+		},
+		{
+			sql: "select * from (with c1 as (table t1) table c1) as s",
+			stmt: &query.Select{
+				From: query.FromStmt{
+					Stmt: &query.With{
+						CTEs: []query.CommonTableExpr{{Name: sql.ID("c1"), Stmt: t1}},
+						Stmt: &query.Select{
+							From: &query.FromTableAlias{
+								TableName: sql.TableName{Table: sql.ID("c1")},
+							},
+						},
+					},
+					Alias: sql.ID("s"),
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		stmt, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else if err != nil {
+			t.Errorf("Parse(%q) failed with %s", c.sql, err)
+		} else if !reflect.DeepEqual(c.stmt, stmt) {
+			t.Errorf("Parse(%q) got %s want %s", c.sql, stmt, c.stmt)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt query.Values
+		fail bool
+	}{
+		{sql: "values", fail: true},
+		{sql: "values (", fail: true},
+		{sql: "values ()", fail: true},
+		{sql: "values (1", fail: true},
+		{sql: "values (1, 2", fail: true},
+		{sql: "values (1 2)", fail: true},
+		{sql: "values (1, 2), (3)", fail: true},
+		{sql: "values (1, 2, 3), (4, 5), (6, 7, 8)", fail: true},
+		{
+			sql: "values (1, 'abc', true)",
+			stmt: query.Values{
+				Expressions: [][]expr.Expr{
+					{expr.Int64Literal(1), expr.StringLiteral("abc"), expr.True()},
+				},
+			},
+		},
+		{
+			sql: "values (1, 'abc', true), (2, 'def', false)",
+			stmt: query.Values{
+				Expressions: [][]expr.Expr{
+					{expr.Int64Literal(1), expr.StringLiteral("abc"), expr.True()},
+					{expr.Int64Literal(2), expr.StringLiteral("def"), expr.False()},
+				},
+			},
+		},
+		{
+			sql: "values (1), (2), (3) limit 2",
+			stmt: query.Values{
+				Expressions: [][]expr.Expr{
+					{expr.Int64Literal(1)},
+					{expr.Int64Literal(2)},
+					{expr.Int64Literal(3)},
+				},
+				Limit: expr.Int64Literal(2),
+			},
+		},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		vs, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Parse(%q) failed with %s", c.sql, err)
+			} else if vs, ok := vs.(*query.Values); !ok || !reflect.DeepEqual(&c.stmt, vs) {
+				t.Errorf("Parse(%q) got %s want %s", c.sql, vs.String(), c.stmt.String())
+			}
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt query.Delete
+		fail bool
+	}{
+		{sql: "delete", fail: true},
+		{sql: "delete t", fail: true},
+		{sql: "delete from", fail: true},
 		{sql: "delete from t1, t2", fail: true},
 		{sql: "delete from t where", fail: true},
 		{
@@ -1508,6 +2182,68 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt datadef.TruncateTable
+		fail bool
+	}{
+		{sql: "truncate", fail: true},
+		{sql: "truncate table", fail: true},
+		{sql: "truncate t1,", fail: true},
+		{sql: "truncate t1 restrict cascade", fail: true},
+		{
+			sql: "truncate t",
+			stmt: datadef.TruncateTable{
+				Tables: []sql.TableName{{Table: sql.ID("t")}},
+			},
+		},
+		{
+			sql: "truncate table db.sc.t1, t2 restrict",
+			stmt: datadef.TruncateTable{
+				Tables: []sql.TableName{
+					{Database: sql.ID("db"), Schema: sql.ID("sc"), Table: sql.ID("t1")},
+					{Table: sql.ID("t2")},
+				},
+			},
+		},
+		{
+			sql: "truncate truncate",
+			stmt: datadef.TruncateTable{
+				Tables: []sql.TableName{{Table: sql.TRUNCATE}},
+			},
+		},
+		{
+			sql: "truncate t1, sc.t2 cascade",
+			stmt: datadef.TruncateTable{
+				Cascade: true,
+				Tables: []sql.TableName{
+					{Table: sql.ID("t1")},
+					{Schema: sql.ID("sc"), Table: sql.ID("t2")},
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		ts, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Parse(%q) failed with %s", c.sql, err)
+			} else if ts, ok := ts.(*datadef.TruncateTable); !ok ||
+				!reflect.DeepEqual(&c.stmt, ts) {
+
+				t.Errorf("Parse(%q) got %s want %s", c.sql, ts.String(), c.stmt.String())
+			}
+		}
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	cases := []struct {
 		sql  string
@@ -1634,6 +2370,104 @@ func TestCreateDatabase(t *testing.T) {
 	}
 }
 
+func TestViews(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt evaluate.Stmt
+		fail bool
+	}{
+		{sql: "create view v", fail: true},
+		{sql: "create view v as", fail: true},
+		{sql: "create view v (c1, c2 as select * from t", fail: true},
+		{sql: "create view v as insert into t values (1)", fail: true},
+		{sql: "create or view v as select * from t", fail: true},
+		{sql: "create or replace table t (c int)", fail: true},
+		{
+			sql: "create view v as select * from t",
+			stmt: &datadef.CreateView{
+				View: sql.TableName{Table: sql.ID("v")},
+				Stmt: &query.Select{
+					From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+				},
+			},
+		},
+		{
+			sql: "create or replace view sc.v (c1, c2) as values (1, 2)",
+			stmt: &datadef.CreateView{
+				View:    sql.TableName{Schema: sql.ID("sc"), Table: sql.ID("v")},
+				Columns: []sql.Identifier{sql.ID("c1"), sql.ID("c2")},
+				Stmt: &query.Values{
+					Expressions: [][]expr.Expr{{expr.Int64Literal(1), expr.Int64Literal(2)}},
+				},
+				Replace: true,
+			},
+		},
+		{
+			sql: "create view view (view) as select view from t",
+			stmt: &datadef.CreateView{
+				View:    sql.TableName{Table: sql.VIEW},
+				Columns: []sql.Identifier{sql.VIEW},
+				Stmt: &query.Select{
+					From: &query.FromTableAlias{TableName: sql.TableName{Table: sql.ID("t")}},
+					Results: []query.SelectResult{
+						query.ExprResult{Expr: expr.Ref{sql.VIEW}},
+					},
+				},
+			},
+		},
+		{sql: "drop view", fail: true},
+		{sql: "drop view v1,", fail: true},
+		{sql: "drop view v1 cascade restrict", fail: true},
+		{
+			sql: "drop view if exists v1, db.sc.v2 cascade",
+			stmt: &datadef.DropView{
+				IfExists: true,
+				Cascade:  true,
+				Views: []sql.TableName{
+					{Table: sql.ID("v1")},
+					{Database: sql.ID("db"), Schema: sql.ID("sc"), Table: sql.ID("v2")},
+				},
+			},
+		},
+		{
+			sql: "drop view v restrict",
+			stmt: &datadef.DropView{
+				Views: []sql.TableName{{Table: sql.ID("v")}},
+			},
+		},
+		{
+			sql: "drop schema if exists sc cascade",
+			stmt: &datadef.DropSchema{
+				IfExists: true,
+				Cascade:  true,
+				Schema:   sql.SchemaName{Schema: sql.ID("sc")},
+			},
+		},
+		{
+			sql: "drop schema db.sc restrict",
+			stmt: &datadef.DropSchema{
+				Schema: sql.SchemaName{Database: sql.ID("db"), Schema: sql.ID("sc")},
+			},
+		},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		stmt, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Parse(%q) failed with %s", c.sql, err)
+			} else if !reflect.DeepEqual(c.stmt, stmt) {
+				t.Errorf("Parse(%q) got %s want %s", c.sql, stmt.String(), c.stmt.String())
+			}
+		}
+	}
+}
+
 func TestAlterTable(t *testing.T) {
 	cases := []struct {
 		sql  string
@@ -1739,7 +2573,201 @@ alter column c1 drop default, alter c2 drop not null, drop constraint con`,
 			},
 		},
 		{
-			sql:  "alter table tbl drop con",
+			sql: "alter table tbl drop con",
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.DropColumn{Column: sql.ID("con")},
+				},
+			},
+		},
+		{
+			sql: `alter table tbl add column c3 int default 10, add if not exists c4 text not null,
+drop column if exists c1, drop c2`,
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.AddColumn{
+						Column:     sql.ID("c3"),
+						ColumnType: sql.ColumnType{Type: sql.IntegerType, Size: 4},
+						Default:    expr.Int64Literal(10),
+					},
+					&datadef.AddColumn{
+						Column: sql.ID("c4"),
+						ColumnType: sql.ColumnType{Type: sql.StringType, Size: sql.MaxColumnSize,
+							NotNull: true},
+						IfNotExists: true,
+					},
+					&datadef.DropColumn{
+						Column:   sql.ID("c1"),
+						IfExists: true,
+					},
+					&datadef.DropColumn{Column: sql.ID("c2")},
+				},
+			},
+		},
+		{
+			sql: `alter table tbl add c3 int constraint dflt default 0 constraint nn not null
+references rtbl`,
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.AddColumn{
+						Column:     sql.ID("c3"),
+						ColumnType: sql.ColumnType{Type: sql.IntegerType, Size: 4, NotNull: true},
+						Default:    expr.Int64Literal(0),
+						Constraints: []datadef.Constraint{
+							{Type: sql.DefaultConstraint, Name: sql.ID("dflt"), ColNum: 0},
+							{Type: sql.NotNullConstraint, Name: sql.ID("nn"), ColNum: 0},
+						},
+					},
+					&datadef.AddForeignKey{
+						datadef.ForeignKey{
+							FKCols:   []sql.Identifier{sql.ID("c3")},
+							RefTable: sql.TableName{Table: sql.ID("rtbl")},
+						},
+					},
+				},
+			},
+		},
+		{
+			sql: "alter table tbl rename column c1 to c2",
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.RenameColumn{
+						Column:  sql.ID("c1"),
+						NewName: sql.ID("c2"),
+					},
+				},
+			},
+		},
+		{
+			sql: "alter table tbl drop column c1 cascade, drop c2 restrict",
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.DropColumn{Column: sql.ID("c1"), Cascade: true},
+					&datadef.DropColumn{Column: sql.ID("c2")},
+				},
+			},
+		},
+		{
+			sql: "alter table tbl rename c1 to c2 cascade",
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.RenameColumn{
+						Column:  sql.ID("c1"),
+						NewName: sql.ID("c2"),
+						Cascade: true,
+					},
+				},
+			},
+		},
+		{
+			sql: "alter table tbl rename c1 to c2",
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.RenameColumn{
+						Column:  sql.ID("c1"),
+						NewName: sql.ID("c2"),
+					},
+				},
+			},
+		},
+		{
+			sql: "alter table tbl rename to tbl2",
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.RenameTable{NewName: sql.ID("tbl2")},
+				},
+			},
+		},
+		{
+			sql: `alter table tbl alter c1 set default 10, alter column c2 set not null,
+alter c3 type bigint, alter c4 set data type text using c4 || '!'`,
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.SetDefault{
+						Column:  sql.ID("c1"),
+						Default: expr.Int64Literal(10),
+					},
+					&datadef.SetNotNull{Column: sql.ID("c2")},
+					&datadef.AlterType{
+						Column:     sql.ID("c3"),
+						ColumnType: sql.ColumnType{Type: sql.IntegerType, Size: 8},
+					},
+					&datadef.AlterType{
+						Column:     sql.ID("c4"),
+						ColumnType: sql.ColumnType{Type: sql.StringType, Size: sql.MaxColumnSize},
+						Using: &expr.Binary{
+							Op:    expr.ConcatOp,
+							Left:  expr.Ref{sql.ID("c4")},
+							Right: expr.StringLiteral("!"),
+						},
+					},
+				},
+			},
+		},
+		{
+			sql: "alter table tbl alter c1 type bigint cascade, alter c2 type text restrict",
+			stmt: &datadef.AlterTable{
+				Table: sql.TableName{Table: sql.ID("tbl")},
+				Actions: []datadef.AlterAction{
+					&datadef.AlterType{
+						Column:     sql.ID("c1"),
+						ColumnType: sql.ColumnType{Type: sql.IntegerType, Size: 8},
+						Cascade:    true,
+					},
+					&datadef.AlterType{
+						Column:     sql.ID("c2"),
+						ColumnType: sql.ColumnType{Type: sql.StringType, Size: sql.MaxColumnSize},
+					},
+				},
+			},
+		},
+		{
+			sql:  "alter table tbl alter c1 set null",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl alter c1 set data int",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl alter c1 type int using",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl add c3 int primary key",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl add c3 int unique",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl add c3 int check (c3 > 0)",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl add column if exists c3 int",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl rename to tbl2, drop c1",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl rename c1 c2",
+			fail: true,
+		},
+		{
+			sql:  "alter table tbl drop c1, rename c2 to c3",
 			fail: true,
 		},
 		{
@@ -1782,3 +2810,172 @@ alter column c1 drop default, alter c2 drop not null, drop constraint con`,
 		}
 	}
 }
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestSequences(t *testing.T) {
+	cases := []struct {
+		sql  string
+		stmt evaluate.Stmt
+		fail bool
+	}{
+		{sql: "create sequence", fail: true},
+		{sql: "create sequence s increment", fail: true},
+		{sql: "create sequence s increment by 1 increment by 2", fail: true},
+		{sql: "create sequence s minvalue 1 no minvalue", fail: true},
+		{sql: "create sequence s no increment", fail: true},
+		{sql: "create sequence s cycle no cycle", fail: true},
+		{sql: "create sequence s restart", fail: true},
+		{
+			sql: "create sequence s",
+			stmt: &datadef.CreateSequence{
+				Sequence: sql.TableName{Table: sql.ID("s")},
+			},
+		},
+		{
+			sql: "create sequence sequence",
+			stmt: &datadef.CreateSequence{
+				Sequence: sql.TableName{Table: sql.SEQUENCE},
+			},
+		},
+		{
+			sql: "create sequence if not exists sc.s increment by -2 minvalue -100 no maxvalue " +
+				"start with 10 cache 5 cycle",
+			stmt: &datadef.CreateSequence{
+				Sequence: sql.TableName{Schema: sql.ID("sc"), Table: sql.ID("s")},
+				Options: datadef.SequenceOptions{
+					Increment:  int64Ptr(-2),
+					MinValue:   int64Ptr(-100),
+					NoMaxValue: true,
+					Start:      int64Ptr(10),
+					Cache:      int64Ptr(5),
+					Cycle:      boolPtr(true),
+				},
+				IfNotExists: true,
+			},
+		},
+		{
+			sql: "create sequence s increment 3 maxvalue 99 start 7 no cycle",
+			stmt: &datadef.CreateSequence{
+				Sequence: sql.TableName{Table: sql.ID("s")},
+				Options: datadef.SequenceOptions{
+					Increment: int64Ptr(3),
+					MaxValue:  int64Ptr(99),
+					Start:     int64Ptr(7),
+					Cycle:     boolPtr(false),
+				},
+			},
+		},
+		{sql: "alter sequence s", fail: true},
+		{sql: "alter sequence s restart restart", fail: true},
+		{
+			sql: "alter sequence s restart",
+			stmt: &datadef.AlterSequence{
+				Sequence: sql.TableName{Table: sql.ID("s")},
+				Options:  datadef.SequenceOptions{Restart: true},
+			},
+		},
+		{
+			sql: "alter sequence s no minvalue restart with 5",
+			stmt: &datadef.AlterSequence{
+				Sequence: sql.TableName{Table: sql.ID("s")},
+				Options: datadef.SequenceOptions{
+					NoMinValue:  true,
+					Restart:     true,
+					RestartWith: int64Ptr(5),
+				},
+			},
+		},
+		{
+			sql: "alter sequence s restart 8",
+			stmt: &datadef.AlterSequence{
+				Sequence: sql.TableName{Table: sql.ID("s")},
+				Options: datadef.SequenceOptions{
+					Restart:     true,
+					RestartWith: int64Ptr(8),
+				},
+			},
+		},
+		{sql: "drop sequence", fail: true},
+		{sql: "drop sequence s cascade", fail: true},
+		{
+			sql: "drop sequence if exists s1, db.sc.s2",
+			stmt: &datadef.DropSequence{
+				IfExists: true,
+				Sequences: []sql.TableName{
+					{Table: sql.ID("s1")},
+					{Database: sql.ID("db"), Schema: sql.ID("sc"), Table: sql.ID("s2")},
+				},
+			},
+		},
+		{sql: "create table t (c serial generated always as identity)", fail: true},
+		{sql: "create table t (c int default 1 generated always as identity)", fail: true},
+		{sql: "create table t (c int generated as identity)", fail: true},
+		{sql: "create table t (c int generated always identity)", fail: true},
+		{sql: "create table t (c int generated always as identity (restart))", fail: true},
+		{sql: "alter table t add column c serial", fail: true},
+		{
+			sql: "create table t (c1 serial, c2 bigserial not null, c3 smallserial)",
+			stmt: &datadef.CreateTable{
+				Table:   sql.TableName{Table: sql.ID("t")},
+				Columns: []sql.Identifier{sql.ID("c1"), sql.ID("c2"), sql.ID("c3")},
+				ColumnTypes: []sql.ColumnType{
+					{Type: sql.IntegerType, Size: 4},
+					{Type: sql.IntegerType, Size: 8, NotNull: true},
+					{Type: sql.IntegerType, Size: 2},
+				},
+				ColumnDefaults: []expr.Expr{nil, nil, nil},
+				Identities: []datadef.Identity{
+					{ColNum: 0},
+					{ColNum: 1},
+					{ColNum: 2},
+				},
+			},
+		},
+		{
+			sql: "create table t (c1 int generated always as identity, " +
+				"c2 bigint generated by default as identity (start with 10 increment by 5))",
+			stmt: &datadef.CreateTable{
+				Table:   sql.TableName{Table: sql.ID("t")},
+				Columns: []sql.Identifier{sql.ID("c1"), sql.ID("c2")},
+				ColumnTypes: []sql.ColumnType{
+					{Type: sql.IntegerType, Size: 4},
+					{Type: sql.IntegerType, Size: 8},
+				},
+				ColumnDefaults: []expr.Expr{nil, nil},
+				Identities: []datadef.Identity{
+					{ColNum: 0, Always: true},
+					{
+						ColNum: 1,
+						Options: datadef.SequenceOptions{
+							Increment: int64Ptr(5),
+							Start:     int64Ptr(10),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.sql), fmt.Sprintf("tests[%d]", i))
+		stmt, err := p.Parse()
+		if c.fail {
+			if err == nil {
+				t.Errorf("Parse(%q) did not fail", c.sql)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Parse(%q) failed with %s", c.sql, err)
+			} else if !reflect.DeepEqual(c.stmt, stmt) {
+				t.Errorf("Parse(%q) got %s want %s", c.sql, stmt.String(), c.stmt.String())
+			}
+		}
+	}
+}
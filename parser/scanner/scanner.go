@@ -31,7 +31,7 @@ type ScanCtx struct {
 	String     string
 	Bytes      []byte
 	Integer    int64
-	Float      float64
+	Numeric    sql.NumericValue
 	Position
 }
 
@@ -44,6 +44,7 @@ type Scanner struct {
 	line        int
 	column      int
 	buffer      bytes.Buffer
+	prev        rune
 }
 
 func (pos Position) String() string {
@@ -70,6 +71,18 @@ func (s *Scanner) Scan(sctx *ScanCtx) {
 	s.buffer.Reset()
 	sctx.Filename = s.filename
 	sctx.Token = s.scan(sctx)
+	s.prev = sctx.Token
+}
+
+// isOperand returns true if tok ends an operand or a type name: a '[' following it is an array
+// subscript or an array type rather than the start of a [quoted identifier].
+func isOperand(tok rune) bool {
+	switch tok {
+	case token.Identifier, token.String, token.Bytes, token.Integer, token.Numeric,
+		token.Parameter, token.RParen, token.RBracket:
+		return true
+	}
+	return false
 }
 
 type runeReader struct {
@@ -210,12 +223,25 @@ SkipWhitespace:
 		r = s.readRune(sctx)
 		if unicode.IsDigit(r) {
 			return s.scanNumber(sctx, r, -1)
+		} else if r == '>' {
+			return s.scanGreater(sctx, token.MinusGreater, token.MinusGreaterGreater)
 		}
 		s.unreadRune()
 		return '-'
+	} else if r == '#' {
+		if s.readRune(sctx) == '>' {
+			return s.scanGreater(sctx, token.HashGreater, token.HashGreaterGreater)
+		}
+		s.unreadRune()
+	} else if r == '@' {
+		if s.readRune(sctx) == '>' {
+			return token.AtGreater
+		}
+		s.unreadRune()
+		return '@'
 	} else if r == '"' || r == '`' {
 		return s.scanQuotedIdentifier(sctx, r)
-	} else if r == '[' {
+	} else if r == '[' && !isOperand(s.prev) {
 		return s.scanQuotedIdentifier(sctx, ']')
 	} else if r == '\'' {
 		return s.scanString(sctx, false)
@@ -227,8 +253,14 @@ SkipWhitespace:
 			return r
 		} else if token.IsOpRune(r2) {
 			s.buffer.WriteRune(r2)
-			if r3, ok := token.Operators[s.buffer.String()]; ok {
-				return r3
+			if op, ok := token.Operators[s.buffer.String()]; ok {
+				r3 := s.readRune(sctx)
+				if op3, ok := token.Operators[s.buffer.String()+string(r3)]; ok {
+					s.buffer.WriteRune(r3)
+					return op3
+				}
+				s.unreadRune()
+				return op
 			}
 			sctx.Error = fmt.Errorf("scanner: unexpected operator %s", s.buffer.String())
 			return token.Error
@@ -236,7 +268,7 @@ SkipWhitespace:
 			s.unreadRune()
 			return r
 		}
-	} else if r == '.' || r == ',' || r == '(' || r == ')' || r == '@' {
+	} else if r == '.' || r == ',' || r == '(' || r == ')' || r == '?' || r == '[' || r == ']' {
 		return r
 	} else if r == '$' {
 		r = s.readRune(sctx)
@@ -251,6 +283,16 @@ SkipWhitespace:
 	return token.Error
 }
 
+// scanGreater is called after the first '>' of an operator: op1 is returned unless the next rune
+// is also a '>', in which case op2 is returned.
+func (s *Scanner) scanGreater(sctx *ScanCtx, op1, op2 rune) rune {
+	if s.readRune(sctx) == '>' {
+		return op2
+	}
+	s.unreadRune()
+	return op1
+}
+
 func (s *Scanner) readRune(sctx *ScanCtx) rune {
 	if s.unread {
 		s.unread = false
@@ -321,23 +363,30 @@ func (s *Scanner) scanNumber(sctx *ScanCtx, r rune, sign int64) rune {
 		}
 	}
 
-	var err error
-	if dbl {
-		sctx.Float, err = strconv.ParseFloat(s.buffer.String(), 64)
-	} else {
+	// Constants with a decimal point, and integer constants which are too large, are exact
+	// numeric constants.
+	if !dbl {
+		var err error
 		sctx.Integer, err = strconv.ParseInt(s.buffer.String(), 10, 64)
+		if err == nil {
+			sctx.Integer *= sign
+			return token.Integer
+		} else if !errors.Is(err, strconv.ErrRange) {
+			sctx.Error = err
+			return token.Error
+		}
 	}
+
+	var err error
+	sctx.Numeric, err = sql.ParseNumeric(s.buffer.String())
 	if err != nil {
-		sctx.Error = err
+		sctx.Error = fmt.Errorf("scanner: %s", err)
 		return token.Error
 	}
-	if dbl {
-		sctx.Float *= float64(sign)
-		return token.Float
-	} else {
-		sctx.Integer *= sign
-		return token.Integer
+	if sign < 0 {
+		sctx.Numeric = sctx.Numeric.Negate()
 	}
+	return token.Numeric
 }
 
 func (s *Scanner) scanParameter(sctx *ScanCtx, r rune) rune {
@@ -26,11 +26,12 @@ func TestScan(t *testing.T) {
 		{"\"create\"", token.Identifier},
 		{"'isn\\'t go fun?'", token.String},
 		{"12345", token.Integer},
-		{"1234.5678", token.Float},
+		{"1234.5678", token.Numeric},
 		{", ", token.Comma},
 		{".id", token.Dot},
 		{"(123", token.LParen},
 		{")+", token.RParen},
+		{"]", token.RBracket},
 		{"-abc", token.Minus},
 		{"+abc", token.Plus},
 		{"*(abc)", token.Star},
@@ -49,11 +50,28 @@ func TestScan(t *testing.T) {
 		{">=", token.GreaterEqual},
 		{"==", token.EqualEqual},
 		{"!=", token.BangEqual},
+		{"~'abc'", token.Tilde},
+		{"~*", token.TildeStar},
+		{"!~'abc'", token.BangTilde},
+		{"!~*'abc'", token.BangTildeStar},
+		{"!~-", token.BangTilde},
+		{"~~", token.Error},
+		{"::int", token.ColonColon},
+		{":int", token.Colon},
+		{":=", token.Error},
 		{"!*", token.Error},
 		{"**", token.Error},
 		{">%", token.Error},
 		{">-123", token.Greater},
 		{"=>", token.Error},
+		{"->'a'", token.MinusGreater},
+		{"->>'a'", token.MinusGreaterGreater},
+		{"#>'{a}'", token.HashGreater},
+		{"#>>'{a}'", token.HashGreaterGreater},
+		{"#'{a}'", token.Error},
+		{"@>'{}'", token.AtGreater},
+		{"@idx", token.AtSign},
+		{"?'a'", token.Question},
 		{"$1", token.Parameter},
 		{"$25", token.Parameter},
 		{"$-2", token.Error},
@@ -150,28 +168,29 @@ func TestScan(t *testing.T) {
 		}
 	}
 
-	doubles := []struct {
+	numerics := []struct {
 		s string
-		n float64
+		n string
 	}{
-		{"123.456", 123.456},
-		{"999.", 999.0},
-		{"99.9 ", 99.9},
-		{"9.99zzz", 9.99},
-		{"-12.3", -12.3},
-		{"+1.23", 1.23},
+		{"123.456", "123.456"},
+		{"999.", "999"},
+		{"99.9 ", "99.9"},
+		{"9.99zzz", "9.99"},
+		{"-12.3", "-12.3"},
+		{"+1.23", "1.23"},
+		{"12345678901234567890", "12345678901234567890"},
 	}
 
-	for i, n := range doubles {
+	for i, n := range numerics {
 		var s Scanner
-		s.Init(strings.NewReader(n.s), fmt.Sprintf("doubles[%d]", i))
+		s.Init(strings.NewReader(n.s), fmt.Sprintf("numerics[%d]", i))
 		var sctx ScanCtx
 		s.Scan(&sctx)
-		if sctx.Token != token.Float {
-			t.Errorf("Scan(%q) got %d want Float", n.s, sctx.Token)
+		if sctx.Token != token.Numeric {
+			t.Errorf("Scan(%q) got %d want Numeric", n.s, sctx.Token)
 		}
-		if sctx.Float != n.n {
-			t.Errorf("Scan(%q).Float got %f want %f", n.s, sctx.Float, n.n)
+		if sctx.Numeric.String() != n.n {
+			t.Errorf("Scan(%q).Numeric got %s want %s", n.s, sctx.Numeric, n.n)
 		}
 	}
 
@@ -222,6 +241,34 @@ abcd -- identifier
 		}
 	}
 
+	brackets := []struct {
+		s      string
+		tokens []rune
+	}{
+		{"[a b]", []rune{token.Identifier}},
+		{"a[1]", []rune{token.Identifier, token.LBracket, token.Integer, token.RBracket}},
+		{"$1[2]", []rune{token.Parameter, token.LBracket, token.Integer, token.RBracket}},
+		{"(a)[b]", []rune{token.LParen, token.Identifier, token.RParen, token.LBracket,
+			token.Identifier, token.RBracket}},
+		{"[a][1]", []rune{token.Identifier, token.LBracket, token.Integer, token.RBracket}},
+		{"int[]", []rune{token.Identifier, token.LBracket, token.RBracket}},
+		{"select [a], [b] from [c]", []rune{token.Reserved, token.Identifier, token.Comma,
+			token.Identifier, token.Reserved, token.Identifier}},
+	}
+
+	for i, c := range brackets {
+		var s Scanner
+		s.Init(strings.NewReader(c.s), fmt.Sprintf("brackets[%d]", i))
+		for _, r := range append(c.tokens, token.EOF) {
+			var sctx ScanCtx
+			s.Scan(&sctx)
+			if sctx.Token != r {
+				t.Errorf("Scan(%q) got %d want %d", c.s, sctx.Token, r)
+				break
+			}
+		}
+	}
+
 	parameters := []struct {
 		s string
 		n int64
@@ -13,7 +13,7 @@ const (
 	String
 	Bytes
 	Integer
-	Float
+	Numeric
 	Parameter
 
 	BarBar
@@ -24,14 +24,25 @@ const (
 	GreaterEqual
 	EqualEqual
 	BangEqual
+	TildeStar
+	BangTilde
+	BangTildeStar
+	ColonColon
+	MinusGreater
+	MinusGreaterGreater
+	HashGreater
+	HashGreaterGreater
+	AtGreater
 )
 
 const (
-	Comma  = ','
-	Dot    = '.'
-	LParen = '('
-	RParen = ')'
-	AtSign = '@'
+	Comma    = ','
+	Dot      = '.'
+	LParen   = '('
+	RParen   = ')'
+	AtSign   = '@'
+	LBracket = '['
+	RBracket = ']'
 )
 
 const (
@@ -46,6 +57,10 @@ const (
 	Ampersand = '&'
 	Bar       = '|'
 	Bang      = '!'
+	Tilde     = '~'
+	Colon     = ':'
+	Hash      = '#'
+	Question  = '?'
 )
 
 var operators = map[rune]string{
@@ -57,12 +72,22 @@ var operators = map[rune]string{
 	GreaterEqual:   ">=",
 	EqualEqual:     "==",
 	BangEqual:      "!=",
+	TildeStar:      "~*",
+	BangTilde:      "!~",
+	BangTildeStar:  "!~*",
+	ColonColon:     "::",
+
+	MinusGreater:        "->",
+	MinusGreaterGreater: "->>",
+	HashGreater:         "#>",
+	HashGreaterGreater:  "#>>",
+	AtGreater:           "@>",
 }
 
 var (
 	opRunes = map[rune]bool{
 		'-': true, '+': true, '*': true, '/': true, '%': true, '=': true, '<': true,
-		'>': true, '&': true, '|': true, '!': true,
+		'>': true, '&': true, '|': true, '!': true, '~': true, ':': true, '#': true, '@': true,
 	}
 	Operators = map[string]rune{}
 )
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	pgproto3 "github.com/jackc/pgproto3/v2"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	PasswordAuth    = "password"
+	MD5Auth         = "md5"
+	SCRAMSHA256Auth = "scram-sha-256"
+
+	scramIterations = 4096
+)
+
+var errAuthFailed = errors.New("authentication failed")
+
+func receivePasswordMessage(cr pgproto3.ChunkReader) ([]byte, error) {
+	header, err := cr.Next(5)
+	if err != nil {
+		return nil, err
+	}
+	if header[0] != 'p' {
+		return nil, fmt.Errorf("expected password message; got %c", header[0])
+	}
+	n := int(binary.BigEndian.Uint32(header[1:])) - 4
+	if n < 0 {
+		return nil, errors.New("bad password message length")
+	}
+	buf, err := cr.Next(n)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), buf...), nil
+}
+
+func passwordString(buf []byte) string {
+	if idx := bytes.IndexByte(buf, 0); idx >= 0 {
+		buf = buf[:idx]
+	}
+	return string(buf)
+}
+
+func proto3Authenticate(p3Cfg Proto3Config, cr pgproto3.ChunkReader, conn net.Conn,
+	user string, entry *log.Entry) error {
+
+	password, found := p3Cfg.LookupPassword(user)
+
+	switch p3Cfg.AuthMethod {
+	case PasswordAuth:
+		_, err := conn.Write((&pgproto3.AuthenticationCleartextPassword{}).Encode(nil))
+		if err != nil {
+			return err
+		}
+		buf, err := receivePasswordMessage(cr)
+		if err != nil {
+			return err
+		}
+		if !found || subtle.ConstantTimeCompare([]byte(password),
+			[]byte(passwordString(buf))) != 1 {
+
+			return errAuthFailed
+		}
+	case MD5Auth:
+		var salt [4]byte
+		_, err := rand.Read(salt[:])
+		if err != nil {
+			return err
+		}
+		_, err = conn.Write((&pgproto3.AuthenticationMD5Password{Salt: salt}).Encode(nil))
+		if err != nil {
+			return err
+		}
+		buf, err := receivePasswordMessage(cr)
+		if err != nil {
+			return err
+		}
+		if !found || subtle.ConstantTimeCompare([]byte(md5Password(user, password, salt)),
+			[]byte(passwordString(buf))) != 1 {
+
+			return errAuthFailed
+		}
+	case SCRAMSHA256Auth, "":
+		if !found {
+			// Go through the whole exchange, so as to not reveal whether or not the user exists.
+			var mock [16]byte
+			rand.Read(mock[:])
+			password = hex.EncodeToString(mock[:])
+		}
+		err := scramSHA256(cr, conn, password)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errAuthFailed
+		}
+	default:
+		return fmt.Errorf("unknown authentication method: %s", p3Cfg.AuthMethod)
+	}
+
+	return nil
+}
+
+func md5Password(user, password string, salt [4]byte) string {
+	sum := md5.Sum([]byte(password + user))
+	sum = md5.Sum(append([]byte(hex.EncodeToString(sum[:])), salt[:]...))
+	return "md5" + hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
+
+func scramAttributes(msg string) map[byte]string {
+	attrs := map[byte]string{}
+	for _, attr := range strings.Split(msg, ",") {
+		if len(attr) >= 2 && attr[1] == '=' {
+			attrs[attr[0]] = attr[2:]
+		}
+	}
+	return attrs
+}
+
+func scramSHA256(cr pgproto3.ChunkReader, conn net.Conn, password string) error {
+	_, err := conn.Write((&pgproto3.AuthenticationSASL{
+		AuthMechanisms: []string{"SCRAM-SHA-256"},
+	}).Encode(nil))
+	if err != nil {
+		return err
+	}
+
+	buf, err := receivePasswordMessage(cr)
+	if err != nil {
+		return err
+	}
+	var initial pgproto3.SASLInitialResponse
+	err = initial.Decode(buf)
+	if err != nil {
+		return err
+	}
+	if initial.AuthMechanism != "SCRAM-SHA-256" {
+		return fmt.Errorf("unsupported SASL mechanism: %s", initial.AuthMechanism)
+	}
+
+	// client-first-message = gs2-header client-first-message-bare
+	// gs2-header = ("n" | "y") "," [authzid] ","
+	clientFirst := string(initial.Data)
+	if !strings.HasPrefix(clientFirst, "n,") && !strings.HasPrefix(clientFirst, "y,") {
+		return errors.New("SCRAM channel binding not supported")
+	}
+	idx := strings.IndexByte(clientFirst[2:], ',')
+	if idx < 0 {
+		return errors.New("malformed SCRAM client first message")
+	}
+	gs2Header := clientFirst[:idx+3]
+	clientFirstBare := clientFirst[idx+3:]
+	clientNonce := scramAttributes(clientFirstBare)['r']
+	if clientNonce == "" {
+		return errors.New("malformed SCRAM client first message")
+	}
+
+	var salt, nonce [18]byte
+	_, err = rand.Read(salt[:])
+	if err != nil {
+		return err
+	}
+	_, err = rand.Read(nonce[:])
+	if err != nil {
+		return err
+	}
+	serverNonce := clientNonce + base64.StdEncoding.EncodeToString(nonce[:])
+	serverFirst := fmt.Sprintf("r=%s,s=%s,i=%d", serverNonce,
+		base64.StdEncoding.EncodeToString(salt[:]), scramIterations)
+	_, err = conn.Write((&pgproto3.AuthenticationSASLContinue{Data: []byte(serverFirst)}).
+		Encode(nil))
+	if err != nil {
+		return err
+	}
+
+	buf, err = receivePasswordMessage(cr)
+	if err != nil {
+		return err
+	}
+	clientFinal := string(buf)
+	idx = strings.LastIndex(clientFinal, ",p=")
+	if idx < 0 {
+		return errors.New("malformed SCRAM client final message")
+	}
+	clientFinalWithoutProof := clientFinal[:idx]
+	attrs := scramAttributes(clientFinalWithoutProof)
+	if attrs['c'] != base64.StdEncoding.EncodeToString([]byte(gs2Header)) ||
+		attrs['r'] != serverNonce {
+
+		return errors.New("SCRAM client final message does not match")
+	}
+	proof, err := base64.StdEncoding.DecodeString(clientFinal[idx+3:])
+	if err != nil || len(proof) != sha256.Size {
+		return errors.New("malformed SCRAM client proof")
+	}
+
+	saltedPassword := pbkdf2.Key([]byte(password), salt[:], scramIterations, sha256.Size,
+		sha256.New)
+	clientKey := hmacSHA256(saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	authMessage := clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof
+	clientSignature := hmacSHA256(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientSignature[i]
+	}
+	if subtle.ConstantTimeCompare(clientKey, proof) != 1 {
+		return errAuthFailed
+	}
+
+	serverKey := hmacSHA256(saltedPassword, "Server Key")
+	serverSignature := hmacSHA256(serverKey, authMessage)
+	_, err = conn.Write((&pgproto3.AuthenticationSASLFinal{
+		Data: []byte("v=" + base64.StdEncoding.EncodeToString(serverSignature)),
+	}).Encode(nil))
+	return err
+}
@@ -2,10 +2,17 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"net"
+	"strconv"
 	"strings"
 	"sync/atomic"
 
@@ -19,16 +26,38 @@ import (
 )
 
 type Proto3Config struct {
-	Address string
+	Address        string
+	AuthMethod     string
+	LookupPassword func(user string) (string, bool)
+	CertBytes      []byte
+	KeyBytes       []byte
+	RequireTLS     bool
 }
 
 func (svr *Server) ListenAndServeProto3(p3Cfg Proto3Config) error {
+	var tlsCfg *tls.Config
+	if p3Cfg.CertBytes != nil || p3Cfg.KeyBytes != nil {
+		cert, err := tls.X509KeyPair(p3Cfg.CertBytes, p3Cfg.KeyBytes)
+		if err != nil {
+			return err
+		}
+		tlsCfg = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		}
+	} else if p3Cfg.RequireTLS {
+		return errors.New("server: proto3 TLS required, but no certificate and key")
+	}
+
 	l, err := net.Listen("tcp", p3Cfg.Address)
 	if err != nil {
 		return err
 	}
 	svr.addListener(l)
 
+	if p3Cfg.LookupPassword == nil {
+		log.Warn("proto3 client auth: NONE")
+	}
+
 	for {
 		conn, err := l.Accept()
 		if err != nil {
@@ -46,13 +75,15 @@ func (svr *Server) ListenAndServeProto3(p3Cfg Proto3Config) error {
 		})
 		entry.Info("proto3 connected")
 
-		go svr.handleProto3Conn(conn, entry)
+		go svr.handleProto3Conn(p3Cfg, tlsCfg, conn, entry)
 	}
 
 	return nil
 }
 
-func (svr *Server) handleProto3Conn(conn net.Conn, entry *log.Entry) {
+func (svr *Server) handleProto3Conn(p3Cfg Proto3Config, tlsCfg *tls.Config, conn net.Conn,
+	entry *log.Entry) {
+
 	atomic.AddInt32(&svr.connCount, 1)
 	defer atomic.AddInt32(&svr.connCount, -1)
 
@@ -65,20 +96,24 @@ func (svr *Server) handleProto3Conn(conn net.Conn, entry *log.Entry) {
 		return
 	}
 
+	netConn := conn
 	defer func() {
-		if svr.trackConn(conn, false) {
+		// conn might have been upgraded to TLS.
+		if svr.trackConn(netConn, false) {
 			conn.Close()
 		}
 	}()
 
-	be := pgproto3.NewBackend(pgproto3.NewChunkReader(conn), conn)
+	cr := pgproto3.NewChunkReader(conn)
+	be := pgproto3.NewBackend(cr, conn)
 
-	var started bool
-	for !started {
+	var secure bool
+	var user string
+	for user == "" {
 		msg, err := be.ReceiveStartupMessage()
 		if err != nil {
 			entry.Errorf("receive startup message: %s", err)
-			break
+			return
 		}
 
 		switch msg := msg.(type) {
@@ -87,33 +122,117 @@ func (svr *Server) handleProto3Conn(conn net.Conn, entry *log.Entry) {
 			for nam, val := range msg.Parameters {
 				entry.Infof("parameter: %s = %s", nam, val)
 			}
+
+			if p3Cfg.RequireTLS && !secure {
+				entry.Error("proto3 TLS required")
+				_, err = conn.Write((&pgproto3.ErrorResponse{
+					Severity: "FATAL",
+					Code:     "28000",
+					Message:  "proto3: TLS required",
+				}).Encode(nil))
+				if err != nil {
+					entry.Errorf("send error response: %s", err)
+				}
+				return
+			}
+
+			user = msg.Parameters["user"]
+			if user == "" {
+				proto3ErrorResponse(conn, errors.New("proto3: no user name specified"), entry)
+				return
+			}
+			entry = entry.WithField("user", user)
+
+			if p3Cfg.LookupPassword != nil {
+				err = proto3Authenticate(p3Cfg, cr, conn, user, entry)
+				if err != nil {
+					entry.WithField("error", err.Error()).Error("authentication failed")
+					_, err = conn.Write((&pgproto3.ErrorResponse{
+						Severity: "FATAL",
+						Code:     "28P01",
+						Message:  fmt.Sprintf("password authentication failed for user %s", user),
+					}).Encode(nil))
+					if err != nil {
+						entry.Errorf("send error response: %s", err)
+					}
+					return
+				}
+				entry.Info("authentication succeeded")
+			}
+
 			_, err := conn.Write((&pgproto3.AuthenticationOk{}).Encode(nil))
 			if err != nil {
 				entry.Errorf("send authentication ok: %s", err)
-				break
+				return
 			}
-			started = true
 		case *pgproto3.SSLRequest:
-			_, err := conn.Write([]byte("N"))
+			if tlsCfg == nil || secure {
+				_, err := conn.Write([]byte("N"))
+				if err != nil {
+					entry.Errorf("send deny SSL request: %s", err)
+					return
+				}
+				continue
+			}
+
+			_, err := conn.Write([]byte("S"))
 			if err != nil {
-				entry.Errorf("send deny SSL request: %s", err)
-				break
+				entry.Errorf("send accept SSL request: %s", err)
+				return
+			}
+			tlsConn := tls.Server(conn, tlsCfg)
+			err = tlsConn.Handshake()
+			if err != nil {
+				entry.Errorf("TLS handshake: %s", err)
+				return
 			}
+
+			conn = tlsConn
+			cr = pgproto3.NewChunkReader(conn)
+			be = pgproto3.NewBackend(cr, conn)
+			secure = true
+			entry = entry.WithField("tls", true)
 		default:
 			entry.Errorf("unknown startup message: %v", msg)
-			break
+			return
 		}
 	}
 
-	if started {
-		svr.HandleSession(func(ses *evaluate.Session) {
-			handleProto3Session(ses, be, conn, entry)
-		}, "user", "proto3", "addr") // XXX
-	}
+	svr.HandleSession(func(ses *evaluate.Session) {
+		handleProto3Session(ses, be, conn, entry)
+	}, user, "proto3", conn.RemoteAddr().String())
+}
+
+var arrayOIDs = map[oid.Oid]oid.Oid{
+	oid.T_bool:        oid.T__bool,
+	oid.T_bpchar:      oid.T__bpchar,
+	oid.T_bytea:       oid.T__bytea,
+	oid.T_date:        oid.T__date,
+	oid.T_float4:      oid.T__float4,
+	oid.T_float8:      oid.T__float8,
+	oid.T_int2:        oid.T__int2,
+	oid.T_int4:        oid.T__int4,
+	oid.T_int8:        oid.T__int8,
+	oid.T_interval:    oid.T__interval,
+	oid.T_json:        oid.T__json,
+	oid.T_jsonb:       oid.T__jsonb,
+	oid.T_numeric:     oid.T__numeric,
+	oid.T_text:        oid.T__text,
+	oid.T_time:        oid.T__time,
+	oid.T_timestamp:   oid.T__timestamp,
+	oid.T_timestamptz: oid.T__timestamptz,
+	oid.T_uuid:        oid.T__uuid,
+	oid.T_varchar:     oid.T__varchar,
 }
 
 func dataType(ct sql.ColumnType) (oid.Oid, int16, int32) {
 	// Return oid, size, and type modifier.
+	if ct.Array {
+		ct.Array = false
+		o, _, tmod := dataType(ct)
+		return arrayOIDs[o], -1, tmod
+	}
+
 	switch ct.Type {
 	case sql.UnknownType:
 		return oid.T_text, -1, -1
@@ -144,25 +263,103 @@ func dataType(ct sql.ColumnType) (oid.Oid, int16, int32) {
 		default:
 			return oid.T_int8, 8, -1
 		}
+	case sql.DateType:
+		return oid.T_date, 4, -1
+	case sql.TimeType:
+		return oid.T_time, 8, -1
+	case sql.TimestampType:
+		return oid.T_timestamp, 8, -1
+	case sql.TimestampTZType:
+		return oid.T_timestamptz, 8, -1
+	case sql.IntervalType:
+		return oid.T_interval, 16, -1
+	case sql.NumericType:
+		if ct.Size == 0 {
+			return oid.T_numeric, -1, -1
+		}
+		return oid.T_numeric, -1, int32(ct.Size<<16|ct.Scale) + 4
+	case sql.JSONType:
+		return oid.T_json, -1, -1
+	case sql.JSONBType:
+		return oid.T_jsonb, -1, -1
+	case sql.UUIDType:
+		return oid.T_uuid, 16, -1
 	default:
 		panic(fmt.Sprintf("unexpected column type; got %#v", ct))
 	}
 }
 
+type proto3Statement struct {
+	stmt      evaluate.Stmt
+	prep      evaluate.PreparedPlan
+	paramOIDs []uint32
+}
+
+type proto3Portal struct {
+	ps            *proto3Statement
+	params        []sql.Value
+	resultFormats []int16
+	colTypes      []sql.ColumnType
+	tag           string
+	rows          sql.Rows
+	done          bool
+	cnt           int64
+
+	// A statement which returns no rows is only executed once: later executes of the portal
+	// return the same command complete.
+	completed bool
+}
+
+func (portal *proto3Portal) close() {
+	if portal.rows != nil && !portal.done {
+		portal.rows.Close()
+	}
+	portal.done = true
+}
+
+type proto3Session struct {
+	ses     *evaluate.Session
+	be      *pgproto3.Backend
+	conn    net.Conn
+	entry   *log.Entry
+	stmts   map[string]*proto3Statement
+	portals map[string]*proto3Portal
+
+	// The rows of a portal may be fetched by more than one execute, so, like an implicit
+	// transaction in PostgreSQL, a transaction is started by the first execute and committed
+	// by the next sync.
+	implicitTx bool
+}
+
 func handleProto3Session(ses *evaluate.Session, be *pgproto3.Backend, conn net.Conn,
 	entry *log.Entry) {
 
+	p3 := proto3Session{
+		ses:     ses,
+		be:      be,
+		conn:    conn,
+		entry:   entry,
+		stmts:   map[string]*proto3Statement{},
+		portals: map[string]*proto3Portal{},
+	}
+	defer p3.sync(false)
+
+	ready := true
+	skipToSync := false
 	for {
-		var ch byte
-		if ses.ActiveTx() {
-			ch = 'T'
-		} else {
-			ch = 'I'
-		}
-		_, err := conn.Write((&pgproto3.ReadyForQuery{TxStatus: ch}).Encode(nil))
-		if err != nil {
-			entry.Errorf("send ready for query: %s", err)
-			break
+		if ready {
+			var ch byte
+			if ses.ActiveTx() {
+				ch = 'T'
+			} else {
+				ch = 'I'
+			}
+			_, err := conn.Write((&pgproto3.ReadyForQuery{TxStatus: ch}).Encode(nil))
+			if err != nil {
+				entry.Errorf("send ready for query: %s", err)
+				break
+			}
+			ready = false
 		}
 
 		msg, err := be.Receive()
@@ -173,122 +370,866 @@ func handleProto3Session(ses *evaluate.Session, be *pgproto3.Backend, conn net.C
 			break
 		}
 
+		if skipToSync {
+			// After an error in the extended query protocol, messages are discarded until a
+			// Sync is received.
+			switch msg.(type) {
+			case *pgproto3.Sync:
+				skipToSync = false
+				ready = true
+				if err := p3.sync(false); err != nil {
+					proto3ErrorResponse(conn, err, entry)
+				}
+			case *pgproto3.Terminate:
+				return
+			}
+			continue
+		}
+
 		switch msg := msg.(type) {
 		case *pgproto3.Query:
 			proto3Query(ses, conn, msg, entry)
+			ready = true
+		case *pgproto3.Parse:
+			err = p3.parse(msg)
+		case *pgproto3.Bind:
+			err = p3.bind(msg)
+		case *pgproto3.Describe:
+			err = p3.describe(msg)
+		case *pgproto3.Execute:
+			err = p3.execute(msg)
+		case *pgproto3.Close:
+			err = p3.close(msg)
+		case *pgproto3.Sync:
+			ready = true
+			if err := p3.sync(true); err != nil {
+				proto3ErrorResponse(conn, err, entry)
+			}
+		case *pgproto3.Flush:
+			// Messages are written as they are generated, so there is nothing to flush.
 		case *pgproto3.Terminate:
-			break
+			return
 		default:
 			buf, _ := json.Marshal(msg)
 			entry.Errorf("backend unexpected message: %s", string(buf))
 		}
+
+		if err != nil {
+			proto3ErrorResponse(conn, err, entry)
+			skipToSync = true
+		}
 	}
 }
 
-func proto3Query(ses *evaluate.Session, conn net.Conn, msg *pgproto3.Query, entry *log.Entry) {
-	p := parser.NewParser(strings.NewReader(msg.String), "proto3")
+func (p3 *proto3Session) send(msg pgproto3.BackendMessage, what string) error {
+	_, err := p3.conn.Write(msg.Encode(nil))
+	if err != nil {
+		p3.entry.Errorf("send %s: %s", what, err)
+	}
+	return err
+}
+
+// sync ends an implicit transaction, committing it if ok is true, and otherwise rolling it
+// back.
+func (p3 *proto3Session) sync(ok bool) error {
+	var err error
+	if p3.implicitTx {
+		p3.implicitTx = false
+		if p3.ses.ActiveTx() {
+			p3.closePortals()
+			if ok {
+				err = p3.ses.Commit()
+			} else {
+				err = p3.ses.Rollback()
+			}
+		}
+	}
+
+	if !p3.ses.ActiveTx() {
+		// Portals only last until the end of the transaction.
+		p3.closePortals()
+	}
+	return err
+}
+
+func (p3 *proto3Session) closePortals() {
+	for _, portal := range p3.portals {
+		portal.close()
+	}
+	p3.portals = map[string]*proto3Portal{}
+}
+
+func (p3 *proto3Session) parse(msg *pgproto3.Parse) error {
+	p := parser.NewParser(strings.NewReader(msg.Query), "proto3")
 	stmt, err := p.Parse()
-	if (stmt == nil && err == nil) || err == io.EOF {
-		_, err := conn.Write((&pgproto3.EmptyQueryResponse{}).Encode(nil))
+	if err == io.EOF {
+		stmt = nil
+	} else if err != nil {
+		return err
+	} else if stmt != nil {
+		nxt, err := p.Parse()
+		if nxt != nil || (err != nil && err != io.EOF) {
+			return errors.New(
+				"proto3: cannot insert multiple commands into a prepared statement")
+		}
+	}
+
+	ps := proto3Statement{
+		stmt:      stmt,
+		paramOIDs: append([]uint32(nil), msg.ParameterOIDs...),
+	}
+
+	if stmt != nil {
+		err = p3.ses.Run(stmt,
+			func(ctx context.Context, ses *evaluate.Session, e sql.Engine,
+				tx sql.Transaction) error {
+
+				var err error
+				ps.prep, err = evaluate.PreparePlan(ctx, stmt, ses, tx)
+				return err
+			})
 		if err != nil {
-			entry.Errorf("send empty query response: %s", err)
+			return err
+		}
+
+		paramTypes := ps.prep.ParameterTypes()
+		for len(ps.paramOIDs) < len(paramTypes) {
+			ps.paramOIDs = append(ps.paramOIDs, 0)
+		}
+		for pdx, pt := range paramTypes {
+			// The type of a parameter which could not be inferred is left unspecified.
+			if ps.paramOIDs[pdx] == 0 && pt.Type != sql.UnknownType {
+				o, _, _ := dataType(pt)
+				ps.paramOIDs[pdx] = uint32(o)
+			}
+		}
+		for pdx := len(paramTypes); pdx < len(ps.paramOIDs); pdx += 1 {
+			if ps.paramOIDs[pdx] == 0 {
+				ps.paramOIDs[pdx] = uint32(oid.T_text)
+			}
 		}
-		return
-	} else if err != nil {
-		proto3ErrorResponse(conn, err, entry)
-		return
 	}
 
-	err = ses.Run(stmt,
+	p3.stmts[msg.Name] = &ps
+	return p3.send(&pgproto3.ParseComplete{}, "parse complete")
+}
+
+func formatCode(formats []int16, idx int) int16 {
+	if len(formats) == 0 {
+		return 0
+	} else if len(formats) == 1 {
+		return formats[0]
+	}
+	return formats[idx]
+}
+
+func (p3 *proto3Session) bind(msg *pgproto3.Bind) error {
+	ps, ok := p3.stmts[msg.PreparedStatement]
+	if !ok {
+		return fmt.Errorf("proto3: prepared statement not found: %s", msg.PreparedStatement)
+	}
+
+	if len(msg.Parameters) != len(ps.paramOIDs) {
+		return fmt.Errorf("proto3: bind expected %d parameters; got %d", len(ps.paramOIDs),
+			len(msg.Parameters))
+	}
+	if len(msg.ParameterFormatCodes) > 1 &&
+		len(msg.ParameterFormatCodes) != len(msg.Parameters) {
+
+		return errors.New("proto3: bind wrong number of parameter format codes")
+	}
+
+	var params []sql.Value
+	for pdx, buf := range msg.Parameters {
+		if buf == nil {
+			params = append(params, nil)
+			continue
+		}
+
+		val, err := decodeParameter(oid.Oid(ps.paramOIDs[pdx]),
+			formatCode(msg.ParameterFormatCodes, pdx), buf)
+		if err != nil {
+			return err
+		}
+		params = append(params, val)
+	}
+
+	portal := proto3Portal{
+		ps:            ps,
+		params:        params,
+		resultFormats: append([]int16(nil), msg.ResultFormatCodes...),
+	}
+	if rowsPlan, ok := ps.prep.(evaluate.RowsPlan); ok {
+		portal.colTypes = rowsPlan.ColumnTypes()
+		if len(portal.resultFormats) > 1 && len(portal.resultFormats) != len(portal.colTypes) {
+			return errors.New("proto3: bind wrong number of result format codes")
+		}
+	}
+
+	if old, ok := p3.portals[msg.DestinationPortal]; ok {
+		old.close()
+	}
+	p3.portals[msg.DestinationPortal] = &portal
+	return p3.send(&pgproto3.BindComplete{}, "bind complete")
+}
+
+func (p3 *proto3Session) describe(msg *pgproto3.Describe) error {
+	var ps *proto3Statement
+	var formats []int16
+
+	switch msg.ObjectType {
+	case 'S':
+		var ok bool
+		ps, ok = p3.stmts[msg.Name]
+		if !ok {
+			return fmt.Errorf("proto3: prepared statement not found: %s", msg.Name)
+		}
+		err := p3.send(&pgproto3.ParameterDescription{ParameterOIDs: ps.paramOIDs},
+			"parameter description")
+		if err != nil {
+			return err
+		}
+	case 'P':
+		portal, ok := p3.portals[msg.Name]
+		if !ok {
+			return fmt.Errorf("proto3: portal not found: %s", msg.Name)
+		}
+		ps = portal.ps
+		formats = portal.resultFormats
+	default:
+		return fmt.Errorf("proto3: describe unexpected object type: %c", msg.ObjectType)
+	}
+
+	if rowsPlan, ok := ps.prep.(evaluate.RowsPlan); ok {
+		return p3.send(rowDescription(rowsPlan.Columns(), rowsPlan.ColumnTypes(), formats),
+			"row description")
+	}
+	return p3.send(&pgproto3.NoData{}, "no data")
+}
+
+func (p3 *proto3Session) execute(msg *pgproto3.Execute) error {
+	portal, ok := p3.portals[msg.Portal]
+	if !ok {
+		return fmt.Errorf("proto3: portal not found: %s", msg.Portal)
+	}
+
+	if portal.rows != nil {
+		return p3.sendRows(context.Background(), portal, msg.MaxRows)
+	} else if portal.completed {
+		proto3CommandComplete(p3.conn, portal.tag, portal.cnt, p3.entry)
+		return nil
+	}
+
+	ps := portal.ps
+	if ps.stmt == nil {
+		return p3.send(&pgproto3.EmptyQueryResponse{}, "empty query response")
+	}
+
+	if _, ok := ps.prep.(evaluate.RowsPlan); ok && msg.MaxRows > 0 && !p3.ses.ActiveTx() {
+		err := p3.ses.Begin()
+		if err != nil {
+			return err
+		}
+		p3.implicitTx = true
+	}
+
+	err := p3.ses.Run(ps.stmt,
 		func(ctx context.Context, ses *evaluate.Session, e sql.Engine,
 			tx sql.Transaction) error {
-			plan, err := stmt.Plan(ctx, ses, tx, nil)
+
+			params := portal.params
+			if len(params) > len(ps.prep.ParameterTypes()) {
+				params = params[:len(ps.prep.ParameterTypes())]
+			}
+			err := ps.prep.SetParameters(params)
 			if err != nil {
 				return err
 			}
-			if stmtPlan, ok := plan.(evaluate.StmtPlan); ok {
-				n, err := stmtPlan.Execute(ctx, tx)
+
+			if rowsPlan, ok := ps.prep.(evaluate.RowsPlan); ok && msg.MaxRows > 0 {
+				var err error
+				portal.rows, err = rowsPlan.Rows(ctx, tx, nil)
 				if err != nil {
 					return err
 				}
-				proto3CommandComplete(conn, stmtPlan.Tag(), n, entry)
-			} else if cmdPlan, ok := plan.(evaluate.CmdPlan); ok {
-				err = cmdPlan.Command(ctx, ses, e)
+				portal.tag = rowsPlan.Tag()
+				return p3.sendRows(ctx, portal, msg.MaxRows)
+			}
+
+			if stmtPlan, ok := ps.prep.(evaluate.StmtPlan); ok {
+				n, err := stmtPlan.Execute(ctx, tx)
 				if err != nil {
 					return err
 				}
-				proto3CommandComplete(conn, cmdPlan.Tag(), -1, entry)
-			} else if rowsPlan, ok := plan.(evaluate.RowsPlan); ok {
-				var fields []pgproto3.FieldDescription
-				cols := rowsPlan.Columns()
-				colTypes := rowsPlan.ColumnTypes()
-				for cdx := range cols {
-					oid, sz, tmod := dataType(colTypes[cdx])
-					fields = append(fields,
-						pgproto3.FieldDescription{
-							Name:                 []byte(cols[cdx].String()),
-							TableOID:             0,
-							TableAttributeNumber: 0,
-							DataTypeOID:          uint32(oid),
-							DataTypeSize:         sz,
-							TypeModifier:         tmod,
-							Format:               0, // Text format; binary format = 1
-						})
-				}
-				_, err := conn.Write((&pgproto3.RowDescription{Fields: fields}).Encode(nil))
+				portal.tag = stmtPlan.Tag()
+				portal.cnt = n
+			} else if cmdPlan, ok := ps.prep.(evaluate.CmdPlan); ok {
+				err := cmdPlan.Command(ctx, ses, e)
 				if err != nil {
-					entry.Errorf("send row description: %s", err)
 					return err
 				}
+				portal.tag = cmdPlan.Tag()
+				portal.cnt = -1
+			} else {
+				return proto3Execute(ctx, ses, e, tx, p3.conn, ps.prep, portal.resultFormats,
+					false, p3.entry)
+			}
+			portal.completed = true
+			proto3CommandComplete(p3.conn, portal.tag, portal.cnt, p3.entry)
+			return nil
+		})
+	if err != nil {
+		portal.completed = false
+	}
+	return err
+}
+
+// sendRows sends up to maxRows rows of the portal, or all of the rows if maxRows is zero. The
+// rows are left open, so that the next execute of the portal continues where this one stopped.
+func (p3 *proto3Session) sendRows(ctx context.Context, portal *proto3Portal,
+	maxRows uint32) error {
+
+	if !portal.done {
+		dest := make([]sql.Value, portal.rows.NumColumns())
+		for n := uint32(0); maxRows == 0 || n < maxRows; n += 1 {
+			err := portal.rows.Next(ctx, dest)
+			if err == io.EOF {
+				portal.close()
+				break
+			} else if err != nil {
+				portal.close()
+				return err
+			}
+
+			dr, err := dataRow(dest, portal.colTypes, portal.resultFormats)
+			if err != nil {
+				portal.close()
+				return err
+			}
+			err = p3.send(dr, "data row")
+			if err != nil {
+				portal.close()
+				return err
+			}
+			portal.cnt += 1
+		}
+
+		if !portal.done {
+			return p3.send(&pgproto3.PortalSuspended{}, "portal suspended")
+		}
+	}
+	proto3CommandComplete(p3.conn, portal.tag, portal.cnt, p3.entry)
+	return nil
+}
 
-				rows, err := rowsPlan.Rows(ctx, tx, nil)
+func (p3 *proto3Session) close(msg *pgproto3.Close) error {
+	switch msg.ObjectType {
+	case 'S':
+		delete(p3.stmts, msg.Name)
+	case 'P':
+		if portal, ok := p3.portals[msg.Name]; ok {
+			portal.close()
+			delete(p3.portals, msg.Name)
+		}
+	default:
+		return fmt.Errorf("proto3: close unexpected object type: %c", msg.ObjectType)
+	}
+	return p3.send(&pgproto3.CloseComplete{}, "close complete")
+}
+
+const (
+	// Dates and timestamps are sent in binary relative to 2000-01-01.
+	pgEpochDays = 10957
+)
+
+func decodeParameter(o oid.Oid, format int16, buf []byte) (sql.Value, error) {
+	if format == 0 {
+		s := string(buf)
+		switch o {
+		case oid.T_bool:
+			switch strings.ToLower(strings.TrimSpace(s)) {
+			case "t", "true", "y", "yes", "on", "1":
+				return sql.BoolValue(true), nil
+			case "f", "false", "n", "no", "off", "0":
+				return sql.BoolValue(false), nil
+			}
+			return nil, fmt.Errorf("proto3: invalid boolean parameter: %s", s)
+		case oid.T_int2, oid.T_int4, oid.T_int8:
+			// Like binary parameters, the value must fit in the size of the integer type.
+			bitSize := 64
+			if o == oid.T_int2 {
+				bitSize = 16
+			} else if o == oid.T_int4 {
+				bitSize = 32
+			}
+			i, err := strconv.ParseInt(strings.TrimSpace(s), 10, bitSize)
+			if err != nil {
+				if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+					return nil, fmt.Errorf("proto3: integer parameter out of range: %s", s)
+				}
+				return nil, fmt.Errorf("proto3: invalid integer parameter: %s", s)
+			}
+			return sql.Int64Value(i), nil
+		case oid.T_float4, oid.T_float8:
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("proto3: invalid float parameter: %s", s)
+			}
+			return sql.Float64Value(f), nil
+		case oid.T_bytea:
+			if strings.HasPrefix(s, `\x`) {
+				b, err := hex.DecodeString(s[2:])
 				if err != nil {
-					return err
+					return nil, fmt.Errorf("proto3: invalid bytea parameter: %s", s)
 				}
+				return sql.BytesValue(b), nil
+			}
+			b, err := unescapeBytea(buf)
+			if err != nil {
+				return nil, err
+			}
+			return sql.BytesValue(b), nil
+		case oid.T_date:
+			return temporalParameter(sql.ParseDate(s))
+		case oid.T_time:
+			return temporalParameter(sql.ParseTime(s))
+		case oid.T_timestamp:
+			return temporalParameter(sql.ParseTimestamp(s))
+		case oid.T_timestamptz:
+			return temporalParameter(sql.ParseTimestampTZ(s))
+		case oid.T_interval:
+			return temporalParameter(sql.ParseInterval(s))
+		case oid.T_numeric:
+			n, err := sql.ParseNumeric(s)
+			if err != nil {
+				return nil, fmt.Errorf("proto3: invalid numeric parameter: %s", s)
+			}
+			return n, nil
+		case oid.T_json:
+			return jsonParameter(sql.JSONType, s)
+		case oid.T_jsonb:
+			return jsonParameter(sql.JSONBType, s)
+		case oid.T_uuid:
+			u, err := sql.ParseUUID(s)
+			if err != nil {
+				return nil, fmt.Errorf("proto3: invalid uuid parameter: %s", s)
+			}
+			return u, nil
+		}
+		return sql.StringValue(s), nil
+	} else if format != 1 {
+		return nil, fmt.Errorf("proto3: unexpected parameter format: %d", format)
+	}
 
-				values := make([][]byte, len(cols))
-				dest := make([]sql.Value, len(cols))
-				var cnt int64
-				for {
-					err = rows.Next(ctx, dest)
-					if err != nil {
-						break
-					}
+	switch o {
+	case oid.T_bool:
+		if len(buf) == 1 {
+			return sql.BoolValue(buf[0] != 0), nil
+		}
+	case oid.T_int2:
+		if len(buf) == 2 {
+			return sql.Int64Value(int16(binary.BigEndian.Uint16(buf))), nil
+		}
+	case oid.T_int4:
+		if len(buf) == 4 {
+			return sql.Int64Value(int32(binary.BigEndian.Uint32(buf))), nil
+		}
+	case oid.T_int8:
+		if len(buf) == 8 {
+			return sql.Int64Value(int64(binary.BigEndian.Uint64(buf))), nil
+		}
+	case oid.T_float4:
+		if len(buf) == 4 {
+			return sql.Float64Value(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+		}
+	case oid.T_float8:
+		if len(buf) == 8 {
+			return sql.Float64Value(math.Float64frombits(binary.BigEndian.Uint64(buf))), nil
+		}
+	case oid.T_bytea:
+		return sql.BytesValue(append([]byte(nil), buf...)), nil
+	case oid.T_date:
+		if len(buf) == 4 {
+			return sql.DateValue(int64(int32(binary.BigEndian.Uint32(buf))) + pgEpochDays), nil
+		}
+	case oid.T_time:
+		if len(buf) == 8 {
+			return sql.TimeValue(binary.BigEndian.Uint64(buf)), nil
+		}
+	case oid.T_timestamp, oid.T_timestamptz:
+		if len(buf) == 8 {
+			us := int64(binary.BigEndian.Uint64(buf)) + pgEpochDays*sql.MicrosecondsPerDay
+			if o == oid.T_timestamp {
+				return sql.TimestampValue(us), nil
+			}
+			return sql.TimestampTZValue(us), nil
+		}
+	case oid.T_interval:
+		if len(buf) == 16 {
+			return sql.IntervalValue{
+				Microseconds: int64(binary.BigEndian.Uint64(buf)),
+				Days:         int64(int32(binary.BigEndian.Uint32(buf[8:]))),
+				Months:       int64(int32(binary.BigEndian.Uint32(buf[12:]))),
+			}, nil
+		}
+	case oid.T_numeric:
+		return decodeNumeric(buf)
+	case oid.T_json:
+		return jsonParameter(sql.JSONType, string(buf))
+	case oid.T_jsonb:
+		// The binary format of jsonb is a version number followed by the text.
+		if len(buf) > 0 && buf[0] == jsonbVersion {
+			return jsonParameter(sql.JSONBType, string(buf[1:]))
+		}
+		return nil, fmt.Errorf("proto3: unsupported jsonb parameter")
+	case oid.T_uuid:
+		if len(buf) == 16 {
+			var u sql.UUIDValue
+			copy(u[:], buf)
+			return u, nil
+		}
+	case oid.T_text, oid.T_varchar, oid.T_bpchar, oid.T_unknown:
+		return sql.StringValue(string(buf)), nil
+	default:
+		return nil, fmt.Errorf("proto3: unsupported binary parameter type: %d", o)
+	}
+	return nil, fmt.Errorf("proto3: binary parameter has wrong length: %d", len(buf))
+}
 
-					for vdx, v := range dest {
-						if v == nil {
-							values[vdx] = nil
-						} else {
-							switch v := v.(type) {
-							case sql.StringValue:
-								values[vdx] = []byte(string(v))
-							case sql.BytesValue:
-								values[vdx] = v.HexBytes()
-							default:
-								values[vdx] = []byte(sql.Format(v))
-							}
-						}
-					}
+const (
+	jsonbVersion = 1
+)
 
-					_, err := conn.Write((&pgproto3.DataRow{Values: values}).Encode(nil))
-					if err != nil {
-						entry.Errorf("send data row: %s", err)
-						return err
-					}
+func jsonParameter(dt sql.DataType, s string) (sql.Value, error) {
+	j, err := sql.ConvertJSON(dt, sql.StringValue(s))
+	if err != nil {
+		return nil, fmt.Errorf("proto3: invalid json parameter: %s", s)
+	}
+	return j, nil
+}
 
-					cnt += 1
-				}
+const (
+	numericPos = 0x0000
+	numericNeg = 0x4000
+)
 
-				if err != io.EOF {
-					return err
-				}
+// decodeNumeric decodes the binary format of numeric: the number of base 10000 digits, the
+// weight of the first digit, the sign, the display scale, and then the digits.
+func decodeNumeric(buf []byte) (sql.Value, error) {
+	if len(buf) < 8 {
+		return nil, fmt.Errorf("proto3: binary parameter has wrong length: %d", len(buf))
+	}
+	ndigits := int(binary.BigEndian.Uint16(buf))
+	weight := int(int16(binary.BigEndian.Uint16(buf[2:])))
+	sign := binary.BigEndian.Uint16(buf[4:])
+	dscale := int(binary.BigEndian.Uint16(buf[6:]))
+	if len(buf) != 8+ndigits*2 {
+		return nil, fmt.Errorf("proto3: binary parameter has wrong length: %d", len(buf))
+	} else if sign != numericPos && sign != numericNeg {
+		return nil, fmt.Errorf("proto3: unsupported numeric parameter")
+	}
 
-				proto3CommandComplete(conn, rowsPlan.Tag(), cnt, entry)
-			} else {
-				panic(fmt.Sprintf("expected StmtPlan, CmdPlan, or RowsPlan: %#v", plan))
+	coef := new(big.Int)
+	for ddx := 0; ddx < ndigits; ddx += 1 {
+		coef.Mul(coef, big.NewInt(10000))
+		coef.Add(coef, big.NewInt(int64(binary.BigEndian.Uint16(buf[8+ddx*2:]))))
+	}
+	if sign == numericNeg {
+		coef.Neg(coef)
+	}
+	return sql.MakeNumericValue(coef, (ndigits-1-weight)*4).Round(dscale), nil
+}
+
+func binaryNumeric(n sql.NumericValue) []byte {
+	s := new(big.Int).Abs(n.Coefficient()).String()
+	scale := n.Scale()
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	ip, fp := s[:len(s)-scale], s[len(s)-scale:]
+	ip = strings.Repeat("0", (4-len(ip)%4)%4) + ip
+	fp += strings.Repeat("0", (4-len(fp)%4)%4)
+
+	var digits []uint16
+	weight := len(ip)/4 - 1
+	for ds := ip + fp; len(ds) > 0; ds = ds[4:] {
+		d, _ := strconv.Atoi(ds[:4])
+		digits = append(digits, uint16(d))
+	}
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+		weight -= 1
+	}
+	for len(digits) > 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	if len(digits) == 0 {
+		weight = 0
+	}
+
+	sign := uint16(numericPos)
+	if n.Sign() < 0 {
+		sign = numericNeg
+	}
+	buf := make([]byte, 8+len(digits)*2)
+	binary.BigEndian.PutUint16(buf, uint16(len(digits)))
+	binary.BigEndian.PutUint16(buf[2:], uint16(weight))
+	binary.BigEndian.PutUint16(buf[4:], sign)
+	binary.BigEndian.PutUint16(buf[6:], uint16(scale))
+	for ddx, d := range digits {
+		binary.BigEndian.PutUint16(buf[8+ddx*2:], d)
+	}
+	return buf
+}
+
+func temporalParameter(v sql.Value, err error) (sql.Value, error) {
+	if err != nil {
+		return nil, fmt.Errorf("proto3: %s", err)
+	}
+	return v, nil
+}
+
+func unescapeBytea(buf []byte) ([]byte, error) {
+	// Decode the escape format of bytea: \\ is a backslash and \ooo is an octal byte.
+	var b []byte
+	for len(buf) > 0 {
+		if buf[0] != '\\' {
+			b = append(b, buf[0])
+			buf = buf[1:]
+		} else if len(buf) >= 2 && buf[1] == '\\' {
+			b = append(b, '\\')
+			buf = buf[2:]
+		} else if len(buf) >= 4 {
+			n, err := strconv.ParseUint(string(buf[1:4]), 8, 8)
+			if err != nil {
+				return nil, fmt.Errorf("proto3: invalid bytea parameter: %s", string(buf))
 			}
+			b = append(b, byte(n))
+			buf = buf[4:]
+		} else {
+			return nil, fmt.Errorf("proto3: invalid bytea parameter: %s", string(buf))
+		}
+	}
+	return b, nil
+}
 
-			return nil
+func rowDescription(cols []sql.Identifier, colTypes []sql.ColumnType,
+	formats []int16) *pgproto3.RowDescription {
+
+	var fields []pgproto3.FieldDescription
+	for cdx := range cols {
+		oid, sz, tmod := dataType(colTypes[cdx])
+		fields = append(fields,
+			pgproto3.FieldDescription{
+				Name:                 []byte(cols[cdx].String()),
+				TableOID:             0,
+				TableAttributeNumber: 0,
+				DataTypeOID:          uint32(oid),
+				DataTypeSize:         sz,
+				TypeModifier:         tmod,
+				Format:               formatCode(formats, cdx),
+			})
+	}
+	return &pgproto3.RowDescription{Fields: fields}
+}
+
+func textValue(v sql.Value) []byte {
+	switch v := v.(type) {
+	case sql.StringValue:
+		return []byte(string(v))
+	case sql.BytesValue:
+		return v.HexBytes()
+	default:
+		return []byte(sql.Format(v))
+	}
+}
+
+func binaryTimestamp(us int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(us-pgEpochDays*sql.MicrosecondsPerDay))
+	return buf
+}
+
+// binaryArray encodes a one dimensional array as the number of dimensions, whether or not there
+// are any nulls, the element oid, the length and lower bound of the dimension, and then each
+// element as a length (-1 for null) followed by the binary value of the element.
+func binaryArray(a sql.ArrayValue, ct sql.ColumnType) ([]byte, error) {
+	ct.Array = false
+	o, _, _ := dataType(ct)
+
+	var ndim, hasNull uint32
+	if len(a) > 0 {
+		ndim = 1
+	}
+	for _, e := range a {
+		if e == nil {
+			hasNull = 1
+		}
+	}
+
+	buf := make([]byte, 12)
+	binary.BigEndian.PutUint32(buf, ndim)
+	binary.BigEndian.PutUint32(buf[4:], hasNull)
+	binary.BigEndian.PutUint32(buf[8:], uint32(o))
+	if ndim == 0 {
+		return buf, nil
+	}
+	buf = append(buf, 0, 0, 0, 0, 0, 0, 0, 1)
+	binary.BigEndian.PutUint32(buf[12:], uint32(len(a)))
+
+	for _, e := range a {
+		if e == nil {
+			buf = append(buf, 0xFF, 0xFF, 0xFF, 0xFF)
+			continue
+		}
+		b, err := binaryValue(e, ct)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[len(buf)-4:], uint32(len(b)))
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+func binaryValue(v sql.Value, ct sql.ColumnType) ([]byte, error) {
+	if a, ok := v.(sql.ArrayValue); ok && ct.Array {
+		return binaryArray(a, ct)
+	}
+
+	o, _, _ := dataType(ct)
+	switch o {
+	case oid.T_bool:
+		if b, ok := v.(sql.BoolValue); ok {
+			if b {
+				return []byte{1}, nil
+			}
+			return []byte{0}, nil
+		}
+	case oid.T_int2, oid.T_int4, oid.T_int8:
+		if i, ok := v.(sql.Int64Value); ok {
+			switch o {
+			case oid.T_int2:
+				buf := make([]byte, 2)
+				binary.BigEndian.PutUint16(buf, uint16(i))
+				return buf, nil
+			case oid.T_int4:
+				buf := make([]byte, 4)
+				binary.BigEndian.PutUint32(buf, uint32(i))
+				return buf, nil
+			default:
+				buf := make([]byte, 8)
+				binary.BigEndian.PutUint64(buf, uint64(i))
+				return buf, nil
+			}
+		}
+	case oid.T_float4, oid.T_float8:
+		if f, ok := v.(sql.Float64Value); ok {
+			if o == oid.T_float4 {
+				buf := make([]byte, 4)
+				binary.BigEndian.PutUint32(buf, math.Float32bits(float32(f)))
+				return buf, nil
+			}
+			buf := make([]byte, 8)
+			binary.BigEndian.PutUint64(buf, math.Float64bits(float64(f)))
+			return buf, nil
+		}
+	case oid.T_bytea:
+		if b, ok := v.(sql.BytesValue); ok {
+			return []byte(b), nil
+		}
+	case oid.T_date:
+		if d, ok := v.(sql.DateValue); ok {
+			buf := make([]byte, 4)
+			binary.BigEndian.PutUint32(buf, uint32(int64(d)-pgEpochDays))
+			return buf, nil
+		}
+	case oid.T_time:
+		if t, ok := v.(sql.TimeValue); ok {
+			buf := make([]byte, 8)
+			binary.BigEndian.PutUint64(buf, uint64(t))
+			return buf, nil
+		}
+	case oid.T_timestamp:
+		if ts, ok := v.(sql.TimestampValue); ok {
+			return binaryTimestamp(int64(ts)), nil
+		}
+	case oid.T_timestamptz:
+		if ts, ok := v.(sql.TimestampTZValue); ok {
+			return binaryTimestamp(int64(ts)), nil
+		}
+	case oid.T_interval:
+		if iv, ok := v.(sql.IntervalValue); ok {
+			buf := make([]byte, 16)
+			binary.BigEndian.PutUint64(buf, uint64(iv.Microseconds))
+			binary.BigEndian.PutUint32(buf[8:], uint32(iv.Days))
+			binary.BigEndian.PutUint32(buf[12:], uint32(iv.Months))
+			return buf, nil
+		}
+	case oid.T_numeric:
+		if n, ok := v.(sql.NumericValue); ok {
+			return binaryNumeric(n), nil
+		}
+	case oid.T_jsonb:
+		if j, ok := v.(sql.JSONValue); ok {
+			return append([]byte{jsonbVersion}, j.String()...), nil
+		}
+	case oid.T_uuid:
+		if u, ok := v.(sql.UUIDValue); ok {
+			return append([]byte(nil), u[:]...), nil
+		}
+	default:
+		if s, ok := v.(sql.StringValue); ok {
+			return []byte(string(s)), nil
+		}
+		return []byte(sql.Format(v)), nil
+	}
+	return nil, fmt.Errorf("proto3: unable to encode %s as binary %d", sql.Format(v), o)
+}
+
+func dataRow(row []sql.Value, colTypes []sql.ColumnType,
+	formats []int16) (*pgproto3.DataRow, error) {
+
+	values := make([][]byte, len(row))
+	for vdx, v := range row {
+		if v == nil {
+			values[vdx] = nil
+		} else if formatCode(formats, vdx) == 1 {
+			var err error
+			values[vdx], err = binaryValue(v, colTypes[vdx])
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			values[vdx] = textValue(v)
+		}
+	}
+	return &pgproto3.DataRow{Values: values}, nil
+}
+
+func proto3Query(ses *evaluate.Session, conn net.Conn, msg *pgproto3.Query, entry *log.Entry) {
+	p := parser.NewParser(strings.NewReader(msg.String), "proto3")
+	stmt, err := p.Parse()
+	if (stmt == nil && err == nil) || err == io.EOF {
+		_, err := conn.Write((&pgproto3.EmptyQueryResponse{}).Encode(nil))
+		if err != nil {
+			entry.Errorf("send empty query response: %s", err)
+		}
+		return
+	} else if err != nil {
+		proto3ErrorResponse(conn, err, entry)
+		return
+	}
+
+	err = ses.Run(stmt,
+		func(ctx context.Context, ses *evaluate.Session, e sql.Engine,
+			tx sql.Transaction) error {
+			plan, err := stmt.Plan(ctx, ses, tx, nil)
+			if err != nil {
+				return err
+			}
+			return proto3Execute(ctx, ses, e, tx, conn, plan, nil, true, entry)
 		})
 
 	if err != nil {
@@ -296,6 +1237,70 @@ func proto3Query(ses *evaluate.Session, conn net.Conn, msg *pgproto3.Query, entr
 	}
 }
 
+func proto3Execute(ctx context.Context, ses *evaluate.Session, e sql.Engine, tx sql.Transaction,
+	conn net.Conn, plan evaluate.Plan, formats []int16, describe bool, entry *log.Entry) error {
+
+	if stmtPlan, ok := plan.(evaluate.StmtPlan); ok {
+		n, err := stmtPlan.Execute(ctx, tx)
+		if err != nil {
+			return err
+		}
+		proto3CommandComplete(conn, stmtPlan.Tag(), n, entry)
+	} else if cmdPlan, ok := plan.(evaluate.CmdPlan); ok {
+		err := cmdPlan.Command(ctx, ses, e)
+		if err != nil {
+			return err
+		}
+		proto3CommandComplete(conn, cmdPlan.Tag(), -1, entry)
+	} else if rowsPlan, ok := plan.(evaluate.RowsPlan); ok {
+		cols := rowsPlan.Columns()
+		colTypes := rowsPlan.ColumnTypes()
+		if describe {
+			_, err := conn.Write(rowDescription(cols, colTypes, formats).Encode(nil))
+			if err != nil {
+				entry.Errorf("send row description: %s", err)
+				return err
+			}
+		}
+
+		rows, err := rowsPlan.Rows(ctx, tx, nil)
+		if err != nil {
+			return err
+		}
+
+		dest := make([]sql.Value, len(cols))
+		var cnt int64
+		for {
+			err = rows.Next(ctx, dest)
+			if err != nil {
+				break
+			}
+
+			dr, err := dataRow(dest, colTypes, formats)
+			if err != nil {
+				return err
+			}
+			_, err = conn.Write(dr.Encode(nil))
+			if err != nil {
+				entry.Errorf("send data row: %s", err)
+				return err
+			}
+
+			cnt += 1
+		}
+
+		if err != io.EOF {
+			return err
+		}
+
+		proto3CommandComplete(conn, rowsPlan.Tag(), cnt, entry)
+	} else {
+		panic(fmt.Sprintf("expected StmtPlan, CmdPlan, or RowsPlan: %#v", plan))
+	}
+
+	return nil
+}
+
 func proto3ErrorResponse(conn net.Conn, err error, entry *log.Entry) {
 	_, cerr := conn.Write((&pgproto3.ErrorResponse{
 		Severity: "ERROR",
@@ -0,0 +1,877 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	pgproto3 "github.com/jackc/pgproto3/v2"
+	"github.com/lib/pq"
+	"github.com/lib/pq/oid"
+
+	"github.com/leftmike/maho/engine"
+	"github.com/leftmike/maho/flags"
+	mahosql "github.com/leftmike/maho/sql"
+	"github.com/leftmike/maho/storage/basic"
+)
+
+func startProto3Server(t *testing.T, p3Cfg Proto3Config) *Server {
+	t.Helper()
+
+	st, err := basic.NewStore("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := engine.NewEngine(st, flags.Default())
+	err = e.CreateDatabase(mahosql.ID("test"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Server{
+		Engine:          e,
+		DefaultDatabase: mahosql.ID("test"),
+	}
+	go func() {
+		s.ListenAndServeProto3(p3Cfg)
+	}()
+	return &s
+}
+
+func openProto3DB(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for retries := 0; ; retries += 1 {
+		err = db.Ping()
+		if err == nil {
+			break
+		}
+		if retries > 3 {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond * time.Duration(retries+1))
+	}
+	return db
+}
+
+// openProto3Frontend connects to the server at addr and completes the startup of a session.
+func openProto3Frontend(t *testing.T, addr string) (net.Conn, *pgproto3.Frontend) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fe := pgproto3.NewFrontend(pgproto3.NewChunkReader(conn), conn)
+	sendProto3Messages(t, fe, []pgproto3.FrontendMessage{
+		&pgproto3.StartupMessage{
+			ProtocolVersion: pgproto3.ProtocolVersionNumber,
+			Parameters:      map[string]string{"user": "root", "database": "test"},
+		},
+	})
+	return conn, fe
+}
+
+// sendProto3Messages sends msgs, and then receives messages until ready for query. Data rows
+// (only the first column), parameter descriptions, and other messages of interest are
+// returned as strings.
+func sendProto3Messages(t *testing.T, fe *pgproto3.Frontend,
+	msgs []pgproto3.FrontendMessage) []string {
+
+	t.Helper()
+
+	for _, msg := range msgs {
+		err := fe.Send(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	for {
+		msg, err := fe.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.DataRow:
+			got = append(got, string(msg.Values[0]))
+		case *pgproto3.ParameterDescription:
+			got = append(got, fmt.Sprintf("parameters: %v", msg.ParameterOIDs))
+		case *pgproto3.PortalSuspended:
+			got = append(got, "suspended")
+		case *pgproto3.CommandComplete:
+			got = append(got, string(msg.CommandTag))
+		case *pgproto3.ErrorResponse:
+			got = append(got, "error: "+msg.Message)
+		case *pgproto3.ReadyForQuery:
+			return got
+		}
+	}
+}
+
+func TestProto3Extended(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10011"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10011 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec(
+		"create table tbl (c1 int primary key, c2 text, c3 bool, c4 double precision, c5 bytea)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 5; i += 1 {
+		res, err := db.Exec("insert into tbl values ($1, $2, $3, $4, $5)", i,
+			fmt.Sprintf("row %d", i), i%2 == 0, float64(i)/2, []byte{byte(i), 0xFF})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n != 1 {
+			t.Errorf("RowsAffected() got %d, %v want 1", n, err)
+		}
+	}
+
+	type row struct {
+		c1 int64
+		c2 string
+		c3 bool
+		c4 float64
+		c5 []byte
+	}
+
+	var r row
+	err = db.QueryRow("select * from tbl where c1 = $1", 3).Scan(&r.c1, &r.c2, &r.c3, &r.c4,
+		&r.c5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (row{3, "row 3", false, 1.5, []byte{3, 0xFF}}); !reflect.DeepEqual(r, want) {
+		t.Errorf("QueryRow() got %v want %v", r, want)
+	}
+
+	stmt, err := db.Prepare("select c1, c2 from tbl where c1 > $1 and c2 <> $2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		c1   int
+		c2   string
+		want []int64
+	}{
+		{c1: 0, c2: "", want: []int64{1, 2, 3, 4, 5}},
+		{c1: 2, c2: "row 4", want: []int64{3, 5}},
+		{c1: 5, c2: "", want: nil},
+	} {
+		rows, err := stmt.Query(c.c1, c.c2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for rows.Next() {
+			var c1 int64
+			var c2 string
+			err = rows.Scan(&c1, &c2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c2 != fmt.Sprintf("row %d", c1) {
+				t.Errorf("Scan() got %d, %s", c1, c2)
+			}
+			got = append(got, c1)
+		}
+		if err = rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Query(%d, %s) got %v want %v", c.c1, c.c2, got, c.want)
+		}
+	}
+	stmt.Close()
+
+	res, err := db.Exec("update tbl set c2 = $1 where c1 = $2", "updated", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		t.Errorf("RowsAffected() got %d, %v want 1", n, err)
+	}
+	var c2 string
+	err = db.QueryRow("select c2 from tbl where c1 = $1", 2).Scan(&c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2 != "updated" {
+		t.Errorf("QueryRow() got %s want updated", c2)
+	}
+
+	_, err = db.Exec("select * from missing where c1 = $1", 1)
+	if err == nil {
+		t.Error("Exec() did not fail")
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err = conn.ExecContext(ctx, "begin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.ExecContext(ctx, "delete from tbl where c1 = $1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.ExecContext(ctx, "rollback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	rows, err := db.Query(
+		"select c1::smallint, cast(c2 as varchar(20)), c4::real, c1::text, c3::char(5) from tbl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typeNames []string
+	for _, ct := range colTypes {
+		typeNames = append(typeNames, ct.DatabaseTypeName())
+	}
+	if want := []string{"INT2", "VARCHAR", "FLOAT4", "TEXT", "BPCHAR"}; !reflect.DeepEqual(typeNames,
+		want) {
+
+		t.Errorf("ColumnTypes() got %v want %v", typeNames, want)
+	}
+	rows.Close()
+
+	var cnt int64
+	err = db.QueryRow("select count(*) from tbl").Scan(&cnt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnt != 5 {
+		t.Errorf("count(*) got %d want 5", cnt)
+	}
+
+	_, err = db.Exec("create table ids (name text)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id int64
+	err = db.QueryRow("insert into ids values ($1) returning rowid", "first").Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == 0 {
+		t.Error("insert returning rowid got 0")
+	}
+	var name string
+	err = db.QueryRow("update ids set name = $1 where rowid = $2 returning name", "second",
+		id).Scan(&name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "second" {
+		t.Errorf("update returning name got %s want second", name)
+	}
+	res, err = db.Exec("delete from ids returning *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		t.Errorf("RowsAffected() got %d, %v want 1", n, err)
+	}
+}
+
+func TestProto3Temporal(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10012"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10012 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec(
+		"create table tbl (c1 int primary key, c2 date, c3 timestamptz, c4 interval, c5 time)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Date(2020, 2, 29, 12, 34, 56, 789000, time.UTC)
+	_, err = db.Exec("insert into tbl values ($1, $2, $3, $4, $5)", 1, "2020-02-29", ts,
+		"1 day 02:00:00", "10:11:12")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c2, c3, c5, c6 time.Time
+	var c4 string
+	err = db.QueryRow("select c2, c3, c4, c5, c3 + c4 from tbl where c3 = $1", ts).Scan(&c2,
+		&c3, &c4, &c5, &c6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC); !c2.Equal(want) {
+		t.Errorf("QueryRow() got %s want %s", c2, want)
+	}
+	if !c3.Equal(ts) {
+		t.Errorf("QueryRow() got %s want %s", c3, ts)
+	}
+	if c4 != "1 day 02:00:00" {
+		t.Errorf("QueryRow() got %s want 1 day 02:00:00", c4)
+	}
+	if c5.Format("15:04:05") != "10:11:12" {
+		t.Errorf("QueryRow() got %s want 10:11:12", c5)
+	}
+	if want := ts.Add(26 * time.Hour); !c6.Equal(want) {
+		t.Errorf("QueryRow() got %s want %s", c6, want)
+	}
+
+	rows, err := db.Query("select c2, c3, c4, c5, c3::timestamp from tbl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typeNames []string
+	for _, ct := range colTypes {
+		typeNames = append(typeNames, ct.DatabaseTypeName())
+	}
+	if want := []string{"DATE", "TIMESTAMPTZ", "INTERVAL", "TIME", "TIMESTAMP"}; !reflect.DeepEqual(
+		typeNames, want) {
+
+		t.Errorf("ColumnTypes() got %v want %v", typeNames, want)
+	}
+	rows.Close()
+}
+
+func TestProto3Numeric(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10013"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10013 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec("create table tbl (c1 numeric(12,2) primary key, c2 numeric)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("insert into tbl values ($1, $2), ($3, $4)", "1234.5", "-0.000012345",
+		"-99.999", "123456789012345678901234567890")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c1, c2 string
+	err = db.QueryRow("select c1, c2 from tbl where c1 = $1", "1234.50").Scan(&c1, &c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 != "1234.50" || c2 != "-0.000012345" {
+		t.Errorf("QueryRow() got %s, %s want 1234.50, -0.000012345", c1, c2)
+	}
+
+	var sum string
+	err = db.QueryRow("select sum(c1) from tbl").Scan(&sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != "1134.50" {
+		t.Errorf("QueryRow() got %s want 1134.50", sum)
+	}
+}
+
+func TestProto3JSON(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10014"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10014 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec("create table tbl (c1 int primary key, c2 jsonb, c3 json)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("insert into tbl values ($1, $2, $3), ($4, $5, $6)", 1,
+		`{"b": [1, 2], "a": "x"}`, `{"b":1,  "a":2, "b":3}`, 2, `{"a": "y", "c": null}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("insert into tbl values ($1, $2)", 3, `{"a": `)
+	if err == nil {
+		t.Errorf("Exec() did not fail with invalid json")
+	}
+
+	var c2, a string
+	err = db.QueryRow("select c2, c2->>'a' from tbl where c2 @> $1", `{"b": [2]}`).Scan(&c2, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2 != `{"a": "x", "b": [1, 2]}` || a != "x" {
+		t.Errorf(`QueryRow() got %s, %s want {"a": "x", "b": [1, 2]}, x`, c2, a)
+	}
+
+	var c3 string
+	err = db.QueryRow("select c3 from tbl where c1 = 1").Scan(&c3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3 != `{"b":1,  "a":2, "b":3}` {
+		t.Errorf(`QueryRow() got %s want {"b":1,  "a":2, "b":3}`, c3)
+	}
+
+	rows, err := db.Query("select c2, c3 from tbl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols[0].DatabaseTypeName() != "JSONB" {
+		t.Errorf("DatabaseTypeName() got %s want JSONB", cols[0].DatabaseTypeName())
+	}
+	if cols[1].DatabaseTypeName() != "JSON" {
+		t.Errorf("DatabaseTypeName() got %s want JSON", cols[1].DatabaseTypeName())
+	}
+	rows.Close()
+}
+
+func TestProto3UUID(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10015"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10015 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec("create table tbl (c1 uuid primary key default gen_random_uuid(), c2 int)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("insert into tbl values ($1, $2)", "A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("insert into tbl (c2) values ($1)", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("insert into tbl values ($1, $2)", "a0eebc99", 3)
+	if err == nil {
+		t.Errorf("Exec() did not fail with invalid uuid")
+	}
+
+	var c2 int
+	err = db.QueryRow("select c2 from tbl where c1 = $1",
+		"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11").Scan(&c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2 != 1 {
+		t.Errorf("QueryRow() got %d want 1", c2)
+	}
+
+	var c1 string
+	err = db.QueryRow("select c1 from tbl where c2 = 2").Scan(&c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mahosql.ParseUUID(c1); err != nil || len(c1) != 36 {
+		t.Errorf("QueryRow() got %s want a uuid", c1)
+	}
+
+	rows, err := db.Query("select c1 from tbl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols[0].DatabaseTypeName() != "UUID" {
+		t.Errorf("DatabaseTypeName() got %s want UUID", cols[0].DatabaseTypeName())
+	}
+	rows.Close()
+}
+
+func TestProto3Array(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10016"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10016 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec("create table tbl (c1 int primary key, c2 int[], c3 text[])")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("insert into tbl values ($1, $2, $3)", 1, pq.Array([]int64{1, 2, 3}),
+		pq.Array([]string{"a b", `c"d`, ""}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("insert into tbl values (2, array[4, null], '{}')")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c2 []int64
+	var c3 []string
+	err = db.QueryRow("select c2, c3 from tbl where $1 = any(c2)", 2).Scan(pq.Array(&c2),
+		pq.Array(&c3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(c2, want) {
+		t.Errorf("QueryRow() got %v want %v", c2, want)
+	}
+	if want := []string{"a b", `c"d`, ""}; !reflect.DeepEqual(c3, want) {
+		t.Errorf("QueryRow() got %v want %v", c3, want)
+	}
+
+	var nc2 []sql.NullInt64
+	err = db.QueryRow("select c2 from tbl where c1 = 2").Scan(pq.Array(&nc2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nc2) != 2 || nc2[0].Int64 != 4 || nc2[1].Valid {
+		t.Errorf("QueryRow() got %v want [4 NULL]", nc2)
+	}
+
+	rows, err := db.Query("select c2, c3, c2[1] from tbl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typeNames []string
+	for _, ct := range colTypes {
+		typeNames = append(typeNames, ct.DatabaseTypeName())
+	}
+	if want := []string{"_INT4", "_TEXT", "INT4"}; !reflect.DeepEqual(typeNames, want) {
+		t.Errorf("ColumnTypes() got %v want %v", typeNames, want)
+	}
+	rows.Close()
+
+	buf, err := binaryValue(mahosql.ArrayValue{mahosql.Int64Value(7), nil},
+		mahosql.ColumnType{Type: mahosql.IntegerType, Size: 2, Array: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 21, 0, 0, 0, 2, 0, 0, 0, 1,
+		0, 0, 0, 2, 0, 7, 0xFF, 0xFF, 0xFF, 0xFF}
+	if !reflect.DeepEqual(buf, want) {
+		t.Errorf("binaryValue() got %v want %v", buf, want)
+	}
+}
+
+func TestProto3PortalSuspended(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10017"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10017 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec("create table pages (c1 int primary key)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("insert into pages values (1), (2), (3), (4), (5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, fe := openProto3Frontend(t, "localhost:10017")
+	defer conn.Close()
+
+	for _, c := range []struct {
+		msgs []pgproto3.FrontendMessage
+		want []string
+	}{
+		{
+			msgs: []pgproto3.FrontendMessage{
+				&pgproto3.Parse{Query: "select c1 from pages order by c1"},
+				&pgproto3.Bind{},
+				&pgproto3.Execute{MaxRows: 2},
+				&pgproto3.Execute{MaxRows: 2},
+				&pgproto3.Execute{MaxRows: 2},
+				&pgproto3.Sync{},
+			},
+			want: []string{"1", "2", "suspended", "3", "4", "suspended", "5", "SELECT 5"},
+		},
+		{
+			msgs: []pgproto3.FrontendMessage{
+				&pgproto3.Bind{},
+				&pgproto3.Execute{MaxRows: 3},
+				&pgproto3.Close{ObjectType: 'P'},
+				&pgproto3.Parse{Query: "insert into pages values (6)"},
+				&pgproto3.Bind{},
+				&pgproto3.Execute{},
+				&pgproto3.Sync{},
+			},
+			want: []string{"1", "2", "3", "suspended", "INSERT 0 1"},
+		},
+		{
+			msgs: []pgproto3.FrontendMessage{
+				&pgproto3.Parse{Query: "select c1 from pages where c1 > $1 order by c1"},
+				&pgproto3.Bind{Parameters: [][]byte{[]byte("3")}},
+				&pgproto3.Execute{MaxRows: 3},
+				&pgproto3.Execute{MaxRows: 3},
+				&pgproto3.Sync{},
+			},
+			want: []string{"4", "5", "6", "suspended", "SELECT 3"},
+		},
+		{
+			msgs: []pgproto3.FrontendMessage{
+				&pgproto3.Parse{Query: "insert into pages values (7)"},
+				&pgproto3.Bind{},
+				&pgproto3.Execute{},
+				&pgproto3.Execute{},
+				&pgproto3.Sync{},
+			},
+			want: []string{"INSERT 0 1", "INSERT 0 1"},
+		},
+	} {
+		got := sendProto3Messages(t, fe, c.msgs)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Receive() got %v want %v", got, c.want)
+		}
+	}
+
+	var cnt int64
+	err = db.QueryRow("select count(*) from pages").Scan(&cnt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnt != 7 {
+		t.Errorf("count(*) got %d want 7", cnt)
+	}
+}
+
+func TestProto3ParameterTypes(t *testing.T) {
+	s := startProto3Server(t, Proto3Config{Address: "localhost:10018"})
+	defer s.Shutdown(context.Background())
+
+	db := openProto3DB(t,
+		"host=localhost port=10018 dbname=test sslmode=disable")
+	defer db.Close()
+
+	_, err := db.Exec("create table params (c1 int primary key, c2 text, c3 double precision)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, fe := openProto3Frontend(t, "localhost:10018")
+	defer conn.Close()
+
+	for _, c := range []struct {
+		query string
+		want  []uint32
+	}{
+		{"select c1 + $1 from params", []uint32{23}},
+		{"select c3 * $1 from params", []uint32{701}},
+		{"select c1 from params where c1 in ($1, $2)", []uint32{23, 23}},
+		{"select c1 from params where c1 between $1 and $2", []uint32{23, 23}},
+		{"select coalesce(c2, $1), nullif(c1, $2) from params", []uint32{25, 23}},
+		{"select case when c1 > $1 then $2 else c3 end from params", []uint32{23, 701}},
+		{"select case c1 when $1 then c2 else $2 end from params", []uint32{23, 25}},
+		{"select $1", []uint32{0}},
+		{"select abs($1), -$2", []uint32{0, 0}},
+	} {
+		got := sendProto3Messages(t, fe, []pgproto3.FrontendMessage{
+			&pgproto3.Parse{Query: c.query},
+			&pgproto3.Describe{ObjectType: 'S'},
+			&pgproto3.Sync{},
+		})
+		want := []string{fmt.Sprintf("parameters: %v", c.want)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Describe(%s) got %v want %v", c.query, got, want)
+		}
+	}
+
+	for _, c := range []struct {
+		o    oid.Oid
+		val  string
+		want []string
+	}{
+		{oid.T_int2, "32767", []string{"32767", "SELECT 1"}},
+		{oid.T_int2, "32768", []string{"error: proto3: integer parameter out of range: 32768"}},
+		{oid.T_int2, "-32769", []string{"error: proto3: integer parameter out of range: -32769"}},
+		{oid.T_int4, "-2147483648", []string{"-2147483648", "SELECT 1"}},
+		{oid.T_int4, "2147483648",
+			[]string{"error: proto3: integer parameter out of range: 2147483648"}},
+		{oid.T_int8, "2147483648", []string{"2147483648", "SELECT 1"}},
+		{oid.T_int4, "abc", []string{"error: proto3: invalid integer parameter: abc"}},
+	} {
+		got := sendProto3Messages(t, fe, []pgproto3.FrontendMessage{
+			&pgproto3.Parse{Query: "select $1", ParameterOIDs: []uint32{uint32(c.o)}},
+			&pgproto3.Bind{Parameters: [][]byte{[]byte(c.val)}},
+			&pgproto3.Execute{},
+			&pgproto3.Sync{},
+		})
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Bind(%s) got %v want %v", c.val, got, c.want)
+		}
+	}
+}
+
+func TestProto3Authentication(t *testing.T) {
+	lookupPassword := func(user string) (string, bool) {
+		if user == "testing" {
+			return "secret", true
+		}
+		return "", false
+	}
+
+	for pdx, method := range []string{PasswordAuth, MD5Auth, SCRAMSHA256Auth} {
+		port := 10021 + pdx
+		s := startProto3Server(t,
+			Proto3Config{
+				Address:        fmt.Sprintf("localhost:%d", port),
+				AuthMethod:     method,
+				LookupPassword: lookupPassword,
+			})
+
+		db := openProto3DB(t,
+			fmt.Sprintf("host=localhost port=%d dbname=test sslmode=disable user=testing "+
+				"password=secret", port))
+		var n int64
+		err := db.QueryRow("select 123").Scan(&n)
+		if err != nil {
+			t.Errorf("%s: QueryRow() failed with %s", method, err)
+		} else if n != 123 {
+			t.Errorf("%s: QueryRow() got %d want 123", method, n)
+		}
+		db.Close()
+
+		for _, up := range []struct {
+			user     string
+			password string
+		}{
+			{"testing", "wrong"},
+			{"unknown", "secret"},
+			{"testing", ""},
+		} {
+			db, err := sql.Open("postgres",
+				fmt.Sprintf("host=localhost port=%d dbname=test sslmode=disable user=%s "+
+					"password='%s'", port, up.user, up.password))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = db.Ping()
+			if err == nil {
+				t.Errorf("%s: Ping(%s, %s) did not fail", method, up.user, up.password)
+			}
+			db.Close()
+		}
+
+		s.Shutdown(context.Background())
+	}
+}
+
+func selfSignedCert(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestProto3TLS(t *testing.T) {
+	certBytes, keyBytes := selfSignedCert(t)
+
+	for pdx, requireTLS := range []bool{false, true} {
+		port := 10031 + pdx
+		s := startProto3Server(t,
+			Proto3Config{
+				Address:    fmt.Sprintf("localhost:%d", port),
+				CertBytes:  certBytes,
+				KeyBytes:   keyBytes,
+				RequireTLS: requireTLS,
+			})
+
+		db := openProto3DB(t,
+			fmt.Sprintf("host=localhost port=%d dbname=test sslmode=require", port))
+		var n int64
+		err := db.QueryRow("select 456").Scan(&n)
+		if err != nil {
+			t.Errorf("QueryRow(sslmode=require) failed with %s", err)
+		} else if n != 456 {
+			t.Errorf("QueryRow(sslmode=require) got %d want 456", n)
+		}
+		db.Close()
+
+		db, err = sql.Open("postgres",
+			fmt.Sprintf("host=localhost port=%d dbname=test sslmode=disable", port))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.Ping()
+		if requireTLS && err == nil {
+			t.Error("Ping(sslmode=disable) did not fail")
+		} else if !requireTLS && err != nil {
+			t.Errorf("Ping(sslmode=disable) failed with %s", err)
+		}
+		db.Close()
+
+		s.Shutdown(context.Background())
+	}
+}
@@ -70,6 +70,7 @@ func (svr *Server) HandleSession(shfn evaluate.SessionHandler, user, typ, addr s
 
 	svr.addSession(ses)
 	defer svr.removeSession(ses)
+	defer ses.Close()
 
 	shfn(ses)
 }
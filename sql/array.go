@@ -0,0 +1,210 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ArrayValue is a one dimensional array; elements may be nil.
+type ArrayValue []Value
+
+func needsQuotes(s string) bool {
+	if s == "" || strings.EqualFold(s, NullString) {
+		return true
+	}
+	for _, r := range s {
+		if r == '{' || r == '}' || r == ',' || r == '"' || r == '\\' || unicode.IsSpace(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ArrayElementString returns the text of v as an element of an array.
+func ArrayElementString(v Value) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return NullString
+	case StringValue:
+		s = string(v)
+	case BytesValue:
+		s = string(v.HexBytes())
+	default:
+		s = v.String()
+	}
+
+	if !needsQuotes(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteRune('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteRune('"')
+	return sb.String()
+}
+
+// String returns the array in the same text format as PostgreSQL: {1,2,NULL}.
+func (a ArrayValue) String() string {
+	var sb strings.Builder
+	sb.WriteRune('{')
+	for i, v := range a {
+		if i > 0 {
+			sb.WriteRune(',')
+		}
+		sb.WriteString(ArrayElementString(v))
+	}
+	sb.WriteRune('}')
+	return sb.String()
+}
+
+// Compare orders arrays element by element; nil elements are less than all other elements,
+// and a shorter array is less than a longer array that it is a prefix of.
+func (a1 ArrayValue) Compare(v2 Value) (int, error) {
+	a2, ok := v2.(ArrayValue)
+	if !ok {
+		return 0, fmt.Errorf("engine: want array got %v", v2)
+	}
+
+	for i := 0; i < len(a1) && i < len(a2); i += 1 {
+		if a1[i] == nil || a2[i] == nil {
+			if cmp := Compare(a1[i], a2[i]); cmp != 0 {
+				return cmp, nil
+			}
+			continue
+		}
+
+		cmp, err := a1[i].Compare(a2[i])
+		if err != nil {
+			return 0, err
+		} else if cmp != 0 {
+			return cmp, nil
+		}
+	}
+
+	if len(a1) < len(a2) {
+		return -1, nil
+	} else if len(a1) > len(a2) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// ParseArray parses the text format of a one dimensional array; the elements are returned as
+// string values or nil.
+func ParseArray(s string) (ArrayValue, error) {
+	t := strings.TrimSpace(s)
+	if len(t) < 2 || t[0] != '{' || t[len(t)-1] != '}' {
+		return nil, fmt.Errorf("invalid array: %s", s)
+	}
+	t = t[1 : len(t)-1]
+	if strings.TrimSpace(t) == "" {
+		return ArrayValue{}, nil
+	}
+
+	a := ArrayValue{}
+	for {
+		t = strings.TrimLeftFunc(t, unicode.IsSpace)
+
+		var sb strings.Builder
+		quoted := false
+		if strings.HasPrefix(t, `"`) {
+			quoted = true
+			t = t[1:]
+			for {
+				if t == "" {
+					return nil, fmt.Errorf("invalid array: %s: missing closing quote", s)
+				}
+				c := t[0]
+				t = t[1:]
+				if c == '"' {
+					break
+				} else if c == '\\' {
+					if t == "" {
+						return nil, fmt.Errorf("invalid array: %s", s)
+					}
+					c = t[0]
+					t = t[1:]
+				}
+				sb.WriteByte(c)
+			}
+			t = strings.TrimLeftFunc(t, unicode.IsSpace)
+		} else {
+			for t != "" && t[0] != ',' {
+				c := t[0]
+				if c == '{' {
+					return nil, fmt.Errorf("invalid array: %s: only one dimension is supported", s)
+				} else if c == '}' || c == '"' {
+					return nil, fmt.Errorf("invalid array: %s", s)
+				} else if c == '\\' {
+					if len(t) < 2 {
+						return nil, fmt.Errorf("invalid array: %s", s)
+					}
+					t = t[1:]
+					c = t[0]
+				}
+				sb.WriteByte(c)
+				t = t[1:]
+			}
+		}
+
+		e := sb.String()
+		if !quoted {
+			e = strings.TrimRightFunc(e, unicode.IsSpace)
+			if e == "" {
+				return nil, fmt.Errorf("invalid array: %s: missing element", s)
+			}
+		}
+		if !quoted && strings.EqualFold(e, NullString) {
+			a = append(a, nil)
+		} else {
+			a = append(a, StringValue(e))
+		}
+
+		if t == "" {
+			break
+		} else if t[0] != ',' {
+			return nil, fmt.Errorf("invalid array: %s", s)
+		}
+		t = t[1:]
+	}
+	return a, nil
+}
+
+// ConvertArray converts v, either an array or the text format of an array, to an array of the
+// element type of ct.
+func ConvertArray(ct ColumnType, v Value) (Value, error) {
+	var a ArrayValue
+	switch v := v.(type) {
+	case ArrayValue:
+		a = v
+	case StringValue:
+		var err error
+		a, err = ParseArray(string(v))
+		if err != nil {
+			return nil, fmt.Errorf("expected an array value: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("expected an array value: %v", v)
+	}
+
+	ect := ct
+	ect.Array = false
+	ra := make(ArrayValue, len(a))
+	for i, e := range a {
+		if e != nil {
+			var err error
+			ra[i], err = ConvertColumnValue(ect, e)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ra, nil
+}
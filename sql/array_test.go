@@ -0,0 +1,168 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/sql"
+)
+
+func TestParseArray(t *testing.T) {
+	cases := []struct {
+		s    string
+		a    sql.ArrayValue
+		fail bool
+	}{
+		{s: "{}", a: sql.ArrayValue{}},
+		{s: " { } ", a: sql.ArrayValue{}},
+		{s: "{1,2,3}", a: sql.ArrayValue{sql.StringValue("1"), sql.StringValue("2"),
+			sql.StringValue("3")}},
+		{s: "{ a , b c ,NULL, null}", a: sql.ArrayValue{sql.StringValue("a"),
+			sql.StringValue("b c"), nil, nil}},
+		{s: `{"NULL","", "a,b", "x\"y\\z"}`, a: sql.ArrayValue{sql.StringValue("NULL"),
+			sql.StringValue(""), sql.StringValue("a,b"), sql.StringValue(`x"y\z`)}},
+		{s: `{a\,b}`, a: sql.ArrayValue{sql.StringValue("a,b")}},
+		{s: "", fail: true},
+		{s: "1,2", fail: true},
+		{s: "{1,2", fail: true},
+		{s: "{1,,2}", fail: true},
+		{s: "{1,2,}", fail: true},
+		{s: "{{1,2},{3,4}}", fail: true},
+		{s: `{"a}`, fail: true},
+		{s: `{"a" b}`, fail: true},
+	}
+
+	for _, c := range cases {
+		a, err := sql.ParseArray(c.s)
+		if c.fail {
+			if err == nil {
+				t.Errorf("ParseArray(%q) did not fail", c.s)
+			}
+		} else if err != nil {
+			t.Errorf("ParseArray(%q) failed with %s", c.s, err)
+		} else if cmp, err := a.Compare(c.a); err != nil || cmp != 0 || len(a) != len(c.a) {
+			t.Errorf("ParseArray(%q) got %v want %v", c.s, a, c.a)
+		}
+	}
+}
+
+func TestArrayString(t *testing.T) {
+	cases := []struct {
+		a sql.ArrayValue
+		s string
+	}{
+		{sql.ArrayValue{}, "{}"},
+		{sql.ArrayValue{sql.Int64Value(1), nil, sql.Int64Value(-3)}, "{1,NULL,-3}"},
+		{sql.ArrayValue{sql.StringValue("a"), sql.StringValue("b c"), sql.StringValue(""),
+			sql.StringValue("null"), sql.StringValue(`x"y\z`), sql.StringValue("{}")},
+			`{a,"b c","","null","x\"y\\z","{}"}`},
+		{sql.ArrayValue{sql.BoolValue(true), sql.Float64Value(1.5)}, "{true,1.5}"},
+		{sql.ArrayValue{sql.BytesValue{1, 0xAB}}, `{"\\x01ab"}`},
+	}
+
+	for _, c := range cases {
+		if c.a.String() != c.s {
+			t.Errorf("%#v.String() got %s want %s", c.a, c.a.String(), c.s)
+		}
+		a, err := sql.ParseArray(c.s)
+		if err != nil {
+			t.Errorf("ParseArray(%q) failed with %s", c.s, err)
+		} else if len(a) != len(c.a) {
+			t.Errorf("ParseArray(%q) got %d elements want %d", c.s, len(a), len(c.a))
+		}
+	}
+}
+
+func TestArrayCompare(t *testing.T) {
+	values := []sql.ArrayValue{
+		{},
+		{nil},
+		{nil, sql.Int64Value(1)},
+		{sql.Int64Value(-1)},
+		{sql.Int64Value(1)},
+		{sql.Int64Value(1), nil},
+		{sql.Int64Value(1), sql.Int64Value(2)},
+		{sql.Int64Value(1), sql.Int64Value(2), sql.Int64Value(3)},
+		{sql.Int64Value(2)},
+	}
+
+	for i := range values {
+		for j := range values {
+			cmp, err := values[i].Compare(values[j])
+			if err != nil {
+				t.Errorf("Compare(%s, %s) failed with %s", values[i], values[j], err)
+			}
+			var want int
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if cmp != want {
+				t.Errorf("Compare(%s, %s) got %d want %d", values[i], values[j], cmp, want)
+			}
+		}
+	}
+
+	_, err := sql.ArrayValue{}.Compare(sql.Int64Value(1))
+	if err == nil {
+		t.Errorf("Compare({}, 1) did not fail")
+	}
+}
+
+func TestConvertArray(t *testing.T) {
+	cases := []struct {
+		ct   sql.ColumnType
+		v    sql.Value
+		s    string
+		fail bool
+	}{
+		{
+			ct: sql.ColumnType{Type: sql.IntegerType, Size: 4, Array: true},
+			v:  sql.StringValue("{1, 2, NULL}"),
+			s:  "{1,2,NULL}",
+		},
+		{
+			ct: sql.ColumnType{Type: sql.NumericType, Size: 5, Scale: 2, Array: true},
+			v:  sql.ArrayValue{sql.Int64Value(1), sql.StringValue("2.5")},
+			s:  "{1.00,2.50}",
+		},
+		{
+			ct: sql.ColumnType{Type: sql.StringType, Array: true},
+			v:  sql.StringValue(`{"a b", c}`),
+			s:  `{"a b",c}`,
+		},
+		{
+			ct:   sql.ColumnType{Type: sql.IntegerType, Size: 4, Array: true},
+			v:    sql.StringValue("{1, a}"),
+			fail: true,
+		},
+		{
+			ct:   sql.ColumnType{Type: sql.IntegerType, Size: 4, Array: true},
+			v:    sql.StringValue("1, 2"),
+			fail: true,
+		},
+		{
+			ct:   sql.ColumnType{Type: sql.IntegerType, Size: 4, Array: true},
+			v:    sql.Int64Value(1),
+			fail: true,
+		},
+		{
+			ct:   sql.ColumnType{Type: sql.IntegerType, Size: 4},
+			v:    sql.ArrayValue{sql.Int64Value(1)},
+			fail: true,
+		},
+	}
+
+	for _, c := range cases {
+		v, err := sql.ConvertColumnValue(c.ct, c.v)
+		if c.fail {
+			if err == nil {
+				t.Errorf("ConvertColumnValue(%v, %v) did not fail", c.ct, c.v)
+			}
+		} else if err != nil {
+			t.Errorf("ConvertColumnValue(%v, %v) failed with %s", c.ct, c.v, err)
+		} else if v.String() != c.s {
+			t.Errorf("ConvertColumnValue(%v, %v) got %s want %s", c.ct, c.v, v, c.s)
+		}
+	}
+}
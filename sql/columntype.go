@@ -17,9 +17,12 @@ const (
 type ColumnType struct {
 	Type DataType
 
-	// Size of the column in bytes for integers and in characters for character columns
+	// Size of the column in bytes for integers, in characters for character columns, and in
+	// digits for numeric columns; numeric columns with a size of zero are not constrained
 	Size  uint32
-	Fixed bool // fixed sized character column
+	Fixed bool   // fixed sized character column
+	Scale uint32 // digits after the decimal point for numeric columns
+	Array bool   // one dimensional array of Type
 
 	NotNull bool // not allowed to be NULL
 }
@@ -27,6 +30,7 @@ type ColumnType struct {
 type ColumnDefault struct {
 	Default     CExpr
 	DefaultExpr string
+	Always      bool // GENERATED ALWAYS AS IDENTITY: only the default may be used
 }
 
 var (
@@ -39,7 +43,14 @@ var (
 	NullStringColType = ColumnType{Type: StringType, Size: 4096}
 )
 
-func ColumnDataType(dt DataType, size uint32, fixed bool) string {
+func ColumnDataType(ct ColumnType) string {
+	if ct.Array {
+		ect := ct
+		ect.Array = false
+		return ColumnDataType(ect) + "[]"
+	}
+
+	dt, size, fixed := ct.Type, ct.Size, ct.Fixed
 	switch dt {
 	case BooleanType:
 		return "BOOL"
@@ -48,6 +59,8 @@ func ColumnDataType(dt DataType, size uint32, fixed bool) string {
 			return fmt.Sprintf("CHAR(%d)", size)
 		} else if size == MaxColumnSize {
 			return "TEXT"
+		} else if size == 0 {
+			return "VARCHAR"
 		} else {
 			return fmt.Sprintf("VARCHAR(%d)", size)
 		}
@@ -56,10 +69,15 @@ func ColumnDataType(dt DataType, size uint32, fixed bool) string {
 			return fmt.Sprintf("BINARY(%d)", size)
 		} else if size == MaxColumnSize {
 			return "BYTES"
+		} else if size == 0 {
+			return "VARBINARY"
 		} else {
 			return fmt.Sprintf("VARBINARY(%d)", size)
 		}
 	case FloatType:
+		if size == 4 {
+			return "REAL"
+		}
 		return "DOUBLE"
 	case IntegerType:
 		switch size {
@@ -70,6 +88,75 @@ func ColumnDataType(dt DataType, size uint32, fixed bool) string {
 		case 8:
 			return "BIGINT"
 		}
+	case DateType, TimeType, TimestampType, TimestampTZType, IntervalType:
+		return dt.String()
+	case NumericType:
+		if size == 0 {
+			return "NUMERIC"
+		} else if ct.Scale == 0 {
+			return fmt.Sprintf("NUMERIC(%d)", size)
+		}
+		return fmt.Sprintf("NUMERIC(%d,%d)", size, ct.Scale)
+	case JSONType:
+		return "JSON"
+	case UUIDType:
+		return "UUID"
+	case JSONBType:
+		return "JSONB"
 	}
 	return ""
 }
+
+// numberRank orders the types which hold numbers; other types are zero.
+func numberRank(dt DataType) int {
+	switch dt {
+	case IntegerType:
+		return 1
+	case NumericType:
+		return 2
+	case FloatType:
+		return 3
+	}
+	return 0
+}
+
+// timestampRank orders the types which hold dates and timestamps; other types are zero.
+func timestampRank(dt DataType) int {
+	switch dt {
+	case DateType:
+		return 1
+	case TimestampType:
+		return 2
+	case TimestampTZType:
+		return 3
+	}
+	return 0
+}
+
+// UnifyColumnType returns a column type which can hold values of both column types.
+func UnifyColumnType(ct1, ct2 ColumnType) (ColumnType, bool) {
+	notNull := ct1.NotNull && ct2.NotNull
+	if ct1.Array != ct2.Array && ct1.Type != UnknownType && ct2.Type != UnknownType {
+		return ct1, false
+	}
+	if ct1.Type == UnknownType ||
+		(numberRank(ct1.Type) > 0 && numberRank(ct1.Type) < numberRank(ct2.Type)) ||
+		(timestampRank(ct1.Type) > 0 && timestampRank(ct1.Type) < timestampRank(ct2.Type)) {
+		ct1 = ct2
+	} else if ct1.Type != ct2.Type && ct2.Type != UnknownType &&
+		(numberRank(ct1.Type) == 0 || numberRank(ct2.Type) == 0) &&
+		(timestampRank(ct1.Type) == 0 || timestampRank(ct2.Type) == 0) {
+
+		return ct1, false
+	}
+
+	if ct1.Type == NumericType && (ct1.Size != ct2.Size || ct1.Scale != ct2.Scale) {
+		// The numeric column type is not constrained unless both column types are the same.
+		ct1.Size = 0
+		ct1.Scale = 0
+	} else if ct1.Type == ct2.Type && ct2.Size > ct1.Size {
+		ct1.Size = ct2.Size
+	}
+	ct1.NotNull = notNull
+	return ct1, true
+}
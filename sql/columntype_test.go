@@ -55,10 +55,42 @@ func TestDataType(t *testing.T) {
 			sql.ColumnType{Type: sql.IntegerType, Size: 8},
 			"BIGINT",
 		},
+		{
+			sql.ColumnType{Type: sql.DateType, Size: 4},
+			"DATE",
+		},
+		{
+			sql.ColumnType{Type: sql.TimestampTZType, Size: 8},
+			"TIMESTAMPTZ",
+		},
+		{
+			sql.ColumnType{Type: sql.IntervalType, Size: 16},
+			"INTERVAL",
+		},
+		{
+			sql.ColumnType{Type: sql.NumericType},
+			"NUMERIC",
+		},
+		{
+			sql.ColumnType{Type: sql.NumericType, Size: 10},
+			"NUMERIC(10)",
+		},
+		{
+			sql.ColumnType{Type: sql.NumericType, Size: 10, Scale: 2},
+			"NUMERIC(10,2)",
+		},
+		{
+			sql.ColumnType{Type: sql.IntegerType, Size: 4, Array: true},
+			"INT[]",
+		},
+		{
+			sql.ColumnType{Type: sql.StringType, Size: 10, Array: true},
+			"VARCHAR(10)[]",
+		},
 	}
 
 	for _, c := range cases {
-		s := sql.ColumnDataType(c.ct.Type, c.ct.Size, c.ct.Fixed)
+		s := sql.ColumnDataType(c.ct)
 		if s != c.dt {
 			t.Errorf("ColumnType{%v}.DataType() got %s want %s", c.ct, s, c.dt)
 		}
@@ -11,6 +11,15 @@ const (
 	BytesType
 	FloatType
 	IntegerType
+	DateType
+	TimeType
+	TimestampType
+	TimestampTZType
+	IntervalType
+	NumericType
+	JSONType
+	UUIDType
+	JSONBType
 )
 
 func (dt DataType) String() string {
@@ -27,6 +36,24 @@ func (dt DataType) String() string {
 		return "DOUBLE"
 	case IntegerType:
 		return "INT"
+	case DateType:
+		return "DATE"
+	case TimeType:
+		return "TIME"
+	case TimestampType:
+		return "TIMESTAMP"
+	case TimestampTZType:
+		return "TIMESTAMPTZ"
+	case IntervalType:
+		return "INTERVAL"
+	case NumericType:
+		return "NUMERIC"
+	case JSONType:
+		return "JSON"
+	case UUIDType:
+		return "UUID"
+	case JSONBType:
+		return "JSONB"
 	default:
 		panic(fmt.Sprintf("unexpected datatype; got %#v", dt))
 	}
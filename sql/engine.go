@@ -2,6 +2,7 @@ package sql
 
 import (
 	"context"
+	"time"
 
 	"github.com/leftmike/maho/flags"
 )
@@ -20,21 +21,47 @@ type Transaction interface {
 	Commit(ctx context.Context) error
 	Rollback() error
 	NextStmt(ctx context.Context) error
+	// StartTime is when the transaction began; it is the value of now() and current_timestamp.
+	StartTime() time.Time
 
 	CreateSchema(ctx context.Context, sn SchemaName) error
-	DropSchema(ctx context.Context, sn SchemaName, ifExists bool) error
+	DropSchema(ctx context.Context, sn SchemaName, ifExists, cascade bool) error
 
 	LookupTableType(ctx context.Context, tn TableName) (TableType, error)
 	LookupTable(ctx context.Context, tn TableName, ttVer int64) (Table, error)
 	CreateTable(ctx context.Context, tn TableName, cols []Identifier, colTypes []ColumnType,
 		colDefaults []ColumnDefault, cons []Constraint, ifNotExists bool) error
 	DropTable(ctx context.Context, tn TableName, ifExists, cascade bool) error
+	TruncateTables(ctx context.Context, tns []TableName, cascade bool) error
+	RenameTable(ctx context.Context, tn TableName, nam Identifier) error
+	AddColumn(ctx context.Context, tn TableName, col Identifier, ct ColumnType,
+		cd ColumnDefault, cons []Constraint, ifNotExists bool) error
+	DropColumn(ctx context.Context, tn TableName, col Identifier, ifExists, cascade bool) error
+	RenameColumn(ctx context.Context, tn TableName, col, ncol Identifier, cascade bool) error
+	SetColumnDefault(ctx context.Context, tn TableName, col Identifier, cd ColumnDefault) error
+	SetNotNull(ctx context.Context, tn TableName, col Identifier) error
+	AlterColumnType(ctx context.Context, tn TableName, col Identifier, ct ColumnType,
+		using CExpr, cascade bool) error
 	AddForeignKey(ctx context.Context, con Identifier, fktn TableName, fkCols []int, rtn TableName,
 		ridx Identifier, onDel, onUpd RefAction, check bool) error
 	AddTrigger(ctx context.Context, tn TableName, events int64, trig Trigger) error
 	DropConstraint(ctx context.Context, tn TableName, con Identifier, ifExists bool,
 		col Identifier, ct ConstraintType) error
 
+	LookupView(ctx context.Context, tn TableName) (ViewType, error)
+	CreateView(ctx context.Context, tn TableName, cols []Identifier, query string, sn SchemaName,
+		deps []TableName, replace bool) error
+	DropView(ctx context.Context, tn TableName, ifExists, cascade bool) error
+
+	LookupSequence(ctx context.Context, tn TableName) (Sequence, error)
+	CreateSequence(ctx context.Context, tn TableName, seq Sequence, owner TableName,
+		col Identifier, ifNotExists bool) error
+	AlterSequence(ctx context.Context, tn TableName, seq Sequence) error
+	DropSequence(ctx context.Context, tn TableName, ifExists bool) error
+	NextSequenceValue(ctx context.Context, tn TableName) (int64, error)
+	CurrentSequenceValue(ctx context.Context, tn TableName) (int64, error)
+	SetSequenceValue(ctx context.Context, tn TableName, val int64, called bool) error
+
 	CreateIndex(ctx context.Context, idxname Identifier, tn TableName, unique bool,
 		keys []ColumnKey, ifNotExists bool) error
 	DropIndex(ctx context.Context, idxname Identifier, tn TableName, ifExists bool) error
@@ -48,6 +75,17 @@ type IndexType struct {
 	Hidden  bool
 }
 
+// Sequence generates values starting at Start and changing by Increment; the values stay
+// within MinValue and MaxValue, and wrap around to the other end if Cycle is set.
+type Sequence struct {
+	Increment int64
+	MinValue  int64
+	MaxValue  int64
+	Start     int64
+	Cache     int64
+	Cycle     bool
+}
+
 type TableType interface {
 	Version() int64
 	Columns() []Identifier
@@ -57,6 +95,13 @@ type TableType interface {
 	Indexes() []IndexType
 }
 
+// ViewType is a named query: the table names in Query are resolved relative to SchemaName.
+type ViewType interface {
+	Columns() []Identifier
+	Query() string
+	SchemaName() SchemaName
+}
+
 const (
 	DeleteEvent = 1 << iota
 	InsertEvent
@@ -88,4 +133,5 @@ type Engine interface {
 	DropDatabase(dbname Identifier, ifExists bool, options map[Identifier]string) error
 
 	Begin(sesid uint64) Transaction
+	EndSession(sesid uint64)
 }
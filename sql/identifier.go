@@ -11,6 +11,9 @@ const MaxIdentifier = 128
 
 const (
 	BIGINT Identifier = iota + 1
+	ALWAYS
+	ARRAY
+	BIGSERIAL
 	BINARY
 	BLOB
 	BOOL
@@ -18,18 +21,31 @@ const (
 	BTREE
 	BYTEA
 	BYTES
+	CACHE
 	CHAR
 	CHARACTER
 	COLUMNS
 	CONFIG
+	CONFLICT
 	CONSTRAINTS
 	COUNT
 	COUNT_ALL
+	CURRENT
+	CYCLE
+	DATA
 	DATABASES
+	DATE
+	DECIMAL
 	DESCRIPTION
 	DOUBLE
+	ESCAPE
+	EXTRACT
 	FLAGS
 	FIELD
+	FOLLOWING
+	GENERATED
+	IDENTITY
+	INCREMENT
 	INDEXES
 	INFO
 	INT
@@ -37,23 +53,51 @@ const (
 	INT4
 	INT8
 	INTEGER
+	INTERVAL
+	JSON
+	JSONB
+	MAXVALUE
 	METADATA
+	MINVALUE
+	NOTHING
+	NUMERIC
+	PARTITION
 	PATH
+	PRECEDING
 	PRIMARY_QUOTED
 	PRIVATE
 	PUBLIC
 	PRECISION
+	RANGE
 	REAL
+	RENAME
+	REPLACE
+	RESTART
+	ROW
+	ROWS
 	SCHEMAS
+	SEQUENCE
 	SEQUENCES
+	SERIAL
 	SMALLINT
+	SMALLSERIAL
 	STDIN
 	SYSTEM
 	TABLES
 	TEXT
+	TIME
+	TIMESTAMP
+	TIMESTAMPTZ
 	TREE
+	TRUNCATE
+	TYPE
+	UNBOUNDED
+	UUID
 	VARBINARY
 	VARCHAR
+	VIEW
+	WITHOUT
+	ZONE
 )
 
 const (
@@ -66,8 +110,11 @@ const (
 	AS
 	ASC
 	BEGIN
+	BETWEEN
 	BY
 	CASCADE
+	CASE
+	CAST
 	CHECK
 	COLUMN
 	COMMIT
@@ -81,7 +128,12 @@ const (
 	DELIMITER
 	DESC
 	DETACH
+	DISTINCT
+	DO
 	DROP
+	ELSE
+	END
+	EXCEPT
 	EXECUTE
 	EXISTS
 	EXPLAIN
@@ -92,44 +144,56 @@ const (
 	GROUP
 	HAVING
 	IF
+	ILIKE
 	IN
 	INDEX
 	INNER
 	INSERT
+	INTERSECT
 	INTO
 	IS
 	JOIN
 	KEY
 	LEFT
+	LIKE
+	LIMIT
 	NO
 	NOT
 	NULL
+	OFFSET
 	ON
 	OR
 	ORDER
 	OUTER
+	OVER
 	PREPARE
 	PRIMARY
+	RECURSIVE
 	REFERENCES
 	RESTRICT
+	RETURNING
 	RIGHT
 	ROLLBACK
 	SCHEMA
 	SELECT
 	SET
 	SHOW
+	SIMILAR
 	SOME
 	START
 	TABLE
+	THEN
 	TO
 	TRANSACTION
 	TRUE
+	UNION
 	UNIQUE
 	UPDATE
 	USE
 	USING
 	VALUES
 	VERBOSE
+	WHEN
 	WHERE
 	WITH
 )
@@ -166,11 +230,15 @@ var knownKeywords = map[string]struct {
 	"ADD":         {ADD, true},
 	"ALL":         {ALL, true},
 	"ALTER":       {ALTER, true},
+	"ALWAYS":      {ALWAYS, false},
 	"AND":         {AND, true},
 	"ANY":         {ANY, true},
+	"ARRAY":       {ARRAY, false},
 	"AS":          {AS, true},
 	"ASC":         {ASC, true},
 	"BEGIN":       {BEGIN, true},
+	"BETWEEN":     {BETWEEN, true},
+	"BIGSERIAL":   {BIGSERIAL, false},
 	"BY":          {BY, true},
 	"BIGINT":      {BIGINT, false},
 	"BINARY":      {BINARY, false},
@@ -179,35 +247,56 @@ var knownKeywords = map[string]struct {
 	"BOOLEAN":     {BOOLEAN, false},
 	"BYTEA":       {BYTEA, false},
 	"BYTES":       {BYTES, false},
+	"CACHE":       {CACHE, false},
 	"CASCADE":     {CASCADE, true},
+	"CASE":        {CASE, true},
+	"CAST":        {CAST, true},
 	"CHAR":        {CHAR, false},
 	"CHARACTER":   {CHARACTER, false},
 	"CHECK":       {CHECK, true},
 	"COLUMN":      {COLUMN, true},
 	"COMMIT":      {COMMIT, true},
+	"CONFLICT":    {CONFLICT, false},
 	"CONSTRAINT":  {CONSTRAINT, true},
 	"COPY":        {COPY, true},
 	"CREATE":      {CREATE, true},
 	"CROSS":       {CROSS, true},
+	"CURRENT":     {CURRENT, false},
+	"CYCLE":       {CYCLE, false},
+	"DATA":        {DATA, false},
 	"DATABASE":    {DATABASE, true},
+	"DATE":        {DATE, false},
+	"DECIMAL":     {DECIMAL, false},
 	"DEFAULT":     {DEFAULT, true},
 	"DELETE":      {DELETE, true},
 	"DELIMITER":   {DELIMITER, true},
 	"DESC":        {DESC, true},
 	"DETACH":      {DETACH, true},
+	"DISTINCT":    {DISTINCT, true},
+	"DO":          {DO, true},
 	"DOUBLE":      {DOUBLE, false},
 	"DROP":        {DROP, true},
+	"ELSE":        {ELSE, true},
+	"END":         {END, true},
+	"ESCAPE":      {ESCAPE, false},
+	"EXCEPT":      {EXCEPT, true},
 	"EXECUTE":     {EXECUTE, true},
 	"EXISTS":      {EXISTS, true},
 	"EXPLAIN":     {EXPLAIN, true},
+	"EXTRACT":     {EXTRACT, false},
 	"FALSE":       {FALSE, true},
+	"FOLLOWING":   {FOLLOWING, false},
 	"FOREIGN":     {FOREIGN, true},
 	"FROM":        {FROM, true},
 	"FULL":        {FULL, true},
+	"GENERATED":   {GENERATED, false},
 	"GROUP":       {GROUP, true},
 	"HAVING":      {HAVING, true},
+	"IDENTITY":    {IDENTITY, false},
 	"IF":          {IF, true},
+	"ILIKE":       {ILIKE, true},
 	"IN":          {IN, true},
+	"INCREMENT":   {INCREMENT, false},
 	"INDEX":       {INDEX, true},
 	"INNER":       {INNER, true},
 	"INSERT":      {INSERT, true},
@@ -216,50 +305,89 @@ var knownKeywords = map[string]struct {
 	"INT4":        {INT4, false},
 	"INT8":        {INT8, false},
 	"INTEGER":     {INTEGER, false},
+	"INTERSECT":   {INTERSECT, true},
+	"INTERVAL":    {INTERVAL, false},
 	"INTO":        {INTO, true},
 	"IS":          {IS, true},
 	"JOIN":        {JOIN, true},
+	"JSON":        {JSON, false},
+	"JSONB":       {JSONB, false},
 	"KEY":         {KEY, true},
 	"LEFT":        {LEFT, true},
+	"LIKE":        {LIKE, true},
+	"LIMIT":       {LIMIT, true},
+	"MAXVALUE":    {MAXVALUE, false},
+	"MINVALUE":    {MINVALUE, false},
 	"NO":          {NO, true},
 	"NOT":         {NOT, true},
+	"NOTHING":     {NOTHING, false},
 	"NULL":        {NULL, true},
+	"NUMERIC":     {NUMERIC, false},
+	"OFFSET":      {OFFSET, true},
 	"ON":          {ON, true},
 	"OR":          {OR, true},
 	"ORDER":       {ORDER, true},
 	"OUTER":       {OUTER, true},
+	"OVER":        {OVER, true},
+	"PARTITION":   {PARTITION, false},
 	"PATH":        {PATH, false},
+	"PRECEDING":   {PRECEDING, false},
 	"PRECISION":   {PRECISION, false},
 	"PREPARE":     {PREPARE, true},
 	"PRIMARY":     {PRIMARY, true},
+	"RANGE":       {RANGE, false},
 	"REAL":        {REAL, false},
+	"RECURSIVE":   {RECURSIVE, true},
+	"RENAME":      {RENAME, false},
+	"REPLACE":     {REPLACE, false},
+	"RESTART":     {RESTART, false},
 	"RESTRICT":    {RESTRICT, true},
 	"REFERENCES":  {REFERENCES, true},
+	"RETURNING":   {RETURNING, true},
 	"RIGHT":       {RIGHT, true},
 	"ROLLBACK":    {ROLLBACK, true},
+	"ROW":         {ROW, false},
+	"ROWS":        {ROWS, false},
 	"SCHEMA":      {SCHEMA, true},
 	"SELECT":      {SELECT, true},
+	"SEQUENCE":    {SEQUENCE, false},
+	"SERIAL":      {SERIAL, false},
 	"SET":         {SET, true},
 	"SHOW":        {SHOW, true},
+	"SIMILAR":     {SIMILAR, true},
 	"SMALLINT":    {SMALLINT, false},
+	"SMALLSERIAL": {SMALLSERIAL, false},
 	"SOME":        {SOME, true},
 	"STDIN":       {STDIN, false},
 	"START":       {START, true},
 	"TABLE":       {TABLE, true},
 	"TEXT":        {TEXT, false},
+	"THEN":        {THEN, true},
+	"TIME":        {TIME, false},
+	"TIMESTAMP":   {TIMESTAMP, false},
+	"TIMESTAMPTZ": {TIMESTAMPTZ, false},
 	"TO":          {TO, true},
 	"TRANSACTION": {TRANSACTION, true},
 	"TRUE":        {TRUE, true},
+	"TRUNCATE":    {TRUNCATE, false},
+	"TYPE":        {TYPE, false},
+	"UNBOUNDED":   {UNBOUNDED, false},
+	"UNION":       {UNION, true},
 	"UNIQUE":      {UNIQUE, true},
 	"UPDATE":      {UPDATE, true},
 	"USE":         {USE, true},
 	"USING":       {USING, true},
+	"UUID":        {UUID, false},
 	"VALUES":      {VALUES, true},
 	"VARBINARY":   {VARBINARY, false},
 	"VARCHAR":     {VARCHAR, false},
 	"VERBOSE":     {VERBOSE, true},
+	"VIEW":        {VIEW, false},
+	"WHEN":        {WHEN, true},
 	"WHERE":       {WHERE, true},
 	"WITH":        {WITH, true},
+	"WITHOUT":     {WITHOUT, false},
+	"ZONE":        {ZONE, false},
 }
 
 var (
@@ -0,0 +1,530 @@
+package sql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type JSONKind int
+
+const (
+	JSONNull JSONKind = iota
+	JSONString
+	JSONNumber
+	JSONBoolean
+	JSONArray
+	JSONObject
+)
+
+func (jk JSONKind) String() string {
+	switch jk {
+	case JSONNull:
+		return "null"
+	case JSONString:
+		return "string"
+	case JSONNumber:
+		return "number"
+	case JSONBoolean:
+		return "boolean"
+	case JSONArray:
+		return "array"
+	case JSONObject:
+		return "object"
+	default:
+		panic(fmt.Sprintf("unexpected json kind; got %#v", jk))
+	}
+}
+
+type jsonNode struct {
+	kind  JSONKind
+	b     bool
+	s     string
+	n     NumericValue
+	keys  []string
+	elems []JSONValue // array elements or object values
+}
+
+// JSONValue is a JSON document in a canonical form, like JSONB in PostgreSQL: numbers are exact,
+// object keys are unique, and the members of objects are ordered by the length of their keys and
+// then by key. The zero value is the JSON null. A json value, like JSON in PostgreSQL, also keeps
+// the text it was parsed from, which is used whenever it is output.
+type JSONValue struct {
+	node *jsonNode
+	text string
+}
+
+func JSONNullValue() JSONValue {
+	return JSONValue{}
+}
+
+func JSONBoolValue(b bool) JSONValue {
+	return JSONValue{node: &jsonNode{kind: JSONBoolean, b: b}}
+}
+
+func JSONStringValue(s string) JSONValue {
+	return JSONValue{node: &jsonNode{kind: JSONString, s: s}}
+}
+
+func JSONNumberValue(n NumericValue) JSONValue {
+	return JSONValue{node: &jsonNode{kind: JSONNumber, n: n}}
+}
+
+func JSONArrayValue(elems []JSONValue) JSONValue {
+	return JSONValue{node: &jsonNode{kind: JSONArray, elems: elems}}
+}
+
+func compareJSONKeys(k1, k2 string) int {
+	if len(k1) < len(k2) {
+		return -1
+	} else if len(k1) > len(k2) {
+		return 1
+	}
+	return strings.Compare(k1, k2)
+}
+
+// JSONObjectValue returns an object with the keys and values; if a key is repeated, the last
+// value is used.
+func JSONObjectValue(keys []string, vals []JSONValue) JSONValue {
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return compareJSONKeys(keys[idx[i]], keys[idx[j]]) < 0
+	})
+
+	node := &jsonNode{kind: JSONObject}
+	for i, k := range idx {
+		if i+1 < len(idx) && keys[idx[i+1]] == keys[k] {
+			continue
+		}
+		node.keys = append(node.keys, keys[k])
+		node.elems = append(node.elems, vals[k])
+	}
+	return JSONValue{node: node}
+}
+
+// ParseJSON parses s as a JSON document.
+func ParseJSON(s string) (JSONValue, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	j, err := parseJSON(dec)
+	if err == nil {
+		_, err = dec.Token()
+		if err == io.EOF {
+			return j, nil
+		} else if err == nil {
+			err = errors.New("unexpected data after value")
+		}
+	}
+	return JSONValue{}, fmt.Errorf("invalid json: %s", err)
+}
+
+// ParseJSONText parses s as a JSON document and keeps s as its text.
+func ParseJSONText(s string) (JSONValue, error) {
+	j, err := ParseJSON(s)
+	if err != nil {
+		return JSONValue{}, err
+	}
+	j.text = s
+	return j, nil
+}
+
+// Text returns the text of a json value; for a jsonb value, false is returned.
+func (j JSONValue) Text() (string, bool) {
+	return j.text, j.text != ""
+}
+
+// JSONB returns j in canonical form, without its text.
+func (j JSONValue) JSONB() JSONValue {
+	return JSONValue{node: j.node}
+}
+
+func parseJSON(dec *json.Decoder) (JSONValue, error) {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return JSONValue{}, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return JSONValue{}, err
+	}
+
+	switch tok := tok.(type) {
+	case nil:
+		return JSONNullValue(), nil
+	case bool:
+		return JSONBoolValue(tok), nil
+	case string:
+		return JSONStringValue(tok), nil
+	case json.Number:
+		n, err := ParseNumeric(string(tok))
+		if err != nil {
+			return JSONValue{}, err
+		}
+		return JSONNumberValue(n), nil
+	case json.Delim:
+		var keys []string
+		var elems []JSONValue
+		for dec.More() {
+			if tok == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return JSONValue{}, err
+				}
+				keys = append(keys, key.(string))
+			}
+			elem, err := parseJSON(dec)
+			if err != nil {
+				return JSONValue{}, err
+			}
+			elems = append(elems, elem)
+		}
+		if _, err := dec.Token(); err != nil {
+			return JSONValue{}, err
+		}
+
+		if tok == '{' {
+			return JSONObjectValue(keys, elems), nil
+		}
+		return JSONArrayValue(elems), nil
+	}
+	panic(fmt.Sprintf("unexpected json token: %T: %v", tok, tok))
+}
+
+func (j JSONValue) Kind() JSONKind {
+	if j.node == nil {
+		return JSONNull
+	}
+	return j.node.kind
+}
+
+func (j JSONValue) Bool() bool {
+	return j.node != nil && j.node.b
+}
+
+func (j JSONValue) Str() string {
+	if j.node == nil {
+		return ""
+	}
+	return j.node.s
+}
+
+func (j JSONValue) Number() NumericValue {
+	if j.node == nil {
+		return NumericValue{}
+	}
+	return j.node.n
+}
+
+// Elements returns the elements of an array or the values of an object; it must not be
+// modified.
+func (j JSONValue) Elements() []JSONValue {
+	if j.node == nil {
+		return nil
+	}
+	return j.node.elems
+}
+
+// Keys returns the keys of an object in the same order as the values returned by Elements; it
+// must not be modified.
+func (j JSONValue) Keys() []string {
+	if j.node == nil {
+		return nil
+	}
+	return j.node.keys
+}
+
+// Member returns the value of key if j is an object with the key.
+func (j JSONValue) Member(key string) (JSONValue, bool) {
+	if j.Kind() != JSONObject {
+		return JSONValue{}, false
+	}
+	keys := j.node.keys
+	i := sort.Search(len(keys), func(i int) bool {
+		return compareJSONKeys(keys[i], key) >= 0
+	})
+	if i < len(keys) && keys[i] == key {
+		return j.node.elems[i], true
+	}
+	return JSONValue{}, false
+}
+
+// Index returns element i if j is an array with the element; negative indexes count from the
+// end of the array.
+func (j JSONValue) Index(i int) (JSONValue, bool) {
+	if j.Kind() != JSONArray {
+		return JSONValue{}, false
+	}
+	if i < 0 {
+		i += len(j.node.elems)
+	}
+	if i < 0 || i >= len(j.node.elems) {
+		return JSONValue{}, false
+	}
+	return j.node.elems[i], true
+}
+
+// Path follows the keys and array indexes in path starting from j.
+func (j JSONValue) Path(path []string) (JSONValue, bool) {
+	for _, p := range path {
+		var ok bool
+		if j.Kind() == JSONArray {
+			i, err := strconv.Atoi(p)
+			if err != nil {
+				return JSONValue{}, false
+			}
+			j, ok = j.Index(i)
+		} else {
+			j, ok = j.Member(p)
+		}
+		if !ok {
+			return JSONValue{}, false
+		}
+	}
+	return j, true
+}
+
+// HasKey returns true if key is a key of the object j, a string element of the array j, or the
+// string j.
+func (j JSONValue) HasKey(key string) bool {
+	switch j.Kind() {
+	case JSONObject:
+		_, ok := j.Member(key)
+		return ok
+	case JSONArray:
+		for _, e := range j.node.elems {
+			if e.Kind() == JSONString && e.node.s == key {
+				return true
+			}
+		}
+	case JSONString:
+		return j.node.s == key
+	}
+	return false
+}
+
+// Contains returns true if j1 contains j2: j2 is an object whose members are contained in the
+// members of the object j1, or j2 is an array whose elements are each contained in some element
+// of the array j1. As a special case, an array contains a scalar which is one of its elements.
+func (j1 JSONValue) Contains(j2 JSONValue) bool {
+	if j1.Kind() == JSONArray && j2.Kind() != JSONArray && j2.Kind() != JSONObject {
+		for _, e := range j1.node.elems {
+			if compareJSON(e, j2) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	return containsJSON(j1, j2)
+}
+
+func containsJSON(j1, j2 JSONValue) bool {
+	if j1.Kind() != j2.Kind() {
+		return false
+	}
+
+	switch j1.Kind() {
+	case JSONObject:
+		for i, key := range j2.node.keys {
+			v1, ok := j1.Member(key)
+			if !ok || !containsJSON(v1, j2.node.elems[i]) {
+				return false
+			}
+		}
+		return true
+	case JSONArray:
+		for _, e2 := range j2.node.elems {
+			found := false
+			for _, e1 := range j1.node.elems {
+				if containsJSON(e1, e2) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return compareJSON(j1, j2) == 0
+}
+
+func (j JSONValue) arrayElements() []JSONValue {
+	if j.Kind() == JSONArray {
+		return j.node.elems
+	}
+	return []JSONValue{j}
+}
+
+// Concat merges two objects, with the members of j2 replacing those of j1 with the same key.
+// Otherwise, it concatenates two arrays; any value which is not an array is treated as an array
+// with a single element.
+func (j1 JSONValue) Concat(j2 JSONValue) JSONValue {
+	if j1.Kind() == JSONObject && j2.Kind() == JSONObject {
+		keys := append(append([]string(nil), j1.node.keys...), j2.node.keys...)
+		vals := append(append([]JSONValue(nil), j1.node.elems...), j2.node.elems...)
+		return JSONObjectValue(keys, vals)
+	}
+
+	e1 := j1.arrayElements()
+	e2 := j2.arrayElements()
+	return JSONArrayValue(append(append(make([]JSONValue, 0, len(e1)+len(e2)), e1...), e2...))
+}
+
+func quoteJSON(sb *strings.Builder, s string) {
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+}
+
+func (j JSONValue) format(sb *strings.Builder) {
+	switch j.Kind() {
+	case JSONNull:
+		sb.WriteString("null")
+	case JSONString:
+		quoteJSON(sb, j.node.s)
+	case JSONNumber:
+		sb.WriteString(j.node.n.String())
+	case JSONBoolean:
+		if j.node.b {
+			sb.WriteString("true")
+		} else {
+			sb.WriteString("false")
+		}
+	case JSONArray:
+		sb.WriteByte('[')
+		for i, e := range j.node.elems {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			e.format(sb)
+		}
+		sb.WriteByte(']')
+	case JSONObject:
+		sb.WriteByte('{')
+		for i, key := range j.node.keys {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			quoteJSON(sb, key)
+			sb.WriteString(": ")
+			j.node.elems[i].format(sb)
+		}
+		sb.WriteByte('}')
+	}
+}
+
+func (j JSONValue) String() string {
+	if j.text != "" {
+		return j.text
+	}
+
+	var sb strings.Builder
+	j.format(&sb)
+	return sb.String()
+}
+
+// compareJSON orders values by kind: null, string, number, boolean, array, and then object.
+// Arrays and objects with more elements are greater; otherwise, the elements, or the keys and
+// values, are compared in order.
+func compareJSON(j1, j2 JSONValue) int {
+	k1, k2 := j1.Kind(), j2.Kind()
+	if k1 < k2 {
+		return -1
+	} else if k1 > k2 {
+		return 1
+	}
+
+	switch k1 {
+	case JSONString:
+		return strings.Compare(j1.node.s, j2.node.s)
+	case JSONNumber:
+		cmp, _ := j1.node.n.Compare(j2.node.n)
+		return cmp
+	case JSONBoolean:
+		if j1.node.b == j2.node.b {
+			return 0
+		} else if j2.node.b {
+			return -1
+		}
+		return 1
+	case JSONArray, JSONObject:
+		e1, e2 := j1.node.elems, j2.node.elems
+		if len(e1) < len(e2) {
+			return -1
+		} else if len(e1) > len(e2) {
+			return 1
+		}
+		for i := range e1 {
+			if k1 == JSONObject {
+				if cmp := compareJSONKeys(j1.node.keys[i], j2.node.keys[i]); cmp != 0 {
+					return cmp
+				}
+			}
+			if cmp := compareJSON(e1[i], e2[i]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return 0
+}
+
+func (j1 JSONValue) Compare(v2 Value) (int, error) {
+	if j2, ok := v2.(JSONValue); ok {
+		return compareJSON(j1, j2), nil
+	}
+	return 0, fmt.Errorf("engine: want json got %v", v2)
+}
+
+// ConvertJSON parses a string as a JSON document; for jsonb, the document is only kept in
+// canonical form.
+func ConvertJSON(dt DataType, v Value) (Value, error) {
+	switch v := v.(type) {
+	case JSONValue:
+		if dt == JSONBType {
+			return v.JSONB(), nil
+		}
+		return v, nil
+	case StringValue:
+		var j JSONValue
+		var err error
+		if dt == JSONBType {
+			j, err = ParseJSON(string(v))
+		} else {
+			j, err = ParseJSONText(string(v))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("expected a json value: %v: %s", v, err)
+		}
+		return j, nil
+	}
+	return nil, fmt.Errorf("expected a json value: %v", v)
+}
@@ -0,0 +1,209 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/sql"
+)
+
+func parseJSON(t *testing.T, s string) sql.JSONValue {
+	t.Helper()
+
+	j, err := sql.ParseJSON(s)
+	if err != nil {
+		t.Fatalf("ParseJSON(%q) failed with %s", s, err)
+	}
+	return j
+}
+
+func TestParseJSON(t *testing.T) {
+	cases := []struct {
+		s    string
+		r    string
+		kind sql.JSONKind
+		fail bool
+	}{
+		{s: "null", r: "null", kind: sql.JSONNull},
+		{s: " true ", r: "true", kind: sql.JSONBoolean},
+		{s: "false", r: "false", kind: sql.JSONBoolean},
+		{s: `"abc"`, r: `"abc"`, kind: sql.JSONString},
+		{s: `"a\"b\\c\né"`, r: `"a\"b\\c\né"`, kind: sql.JSONString},
+		{s: "123", r: "123", kind: sql.JSONNumber},
+		{s: "-1.50", r: "-1.50", kind: sql.JSONNumber},
+		{s: "1e3", r: "1000", kind: sql.JSONNumber},
+		{s: "[]", r: "[]", kind: sql.JSONArray},
+		{s: `[1, "two", [3], {"four": 4}]`, r: `[1, "two", [3], {"four": 4}]`,
+			kind: sql.JSONArray},
+		{s: "{}", r: "{}", kind: sql.JSONObject},
+		{s: `{"bb":1,"a":2,"c":3}`, r: `{"a": 2, "c": 3, "bb": 1}`, kind: sql.JSONObject},
+		{s: `{"a":1,"b":2,"a":3}`, r: `{"a": 3, "b": 2}`, kind: sql.JSONObject},
+		{s: "", fail: true},
+		{s: "nul", fail: true},
+		{s: "[1,]", fail: true},
+		{s: `{"a" 1}`, fail: true},
+		{s: `{"a": 1} x`, fail: true},
+		{s: "[1] [2]", fail: true},
+		{s: "'abc'", fail: true},
+	}
+
+	for _, c := range cases {
+		j, err := sql.ParseJSON(c.s)
+		if c.fail {
+			if err == nil {
+				t.Errorf("ParseJSON(%q) did not fail", c.s)
+			}
+		} else if err != nil {
+			t.Errorf("ParseJSON(%q) failed with %s", c.s, err)
+		} else {
+			if j.String() != c.r {
+				t.Errorf("ParseJSON(%q) got %s want %s", c.s, j, c.r)
+			}
+			if j.Kind() != c.kind {
+				t.Errorf("ParseJSON(%q).Kind() got %s want %s", c.s, j.Kind(), c.kind)
+			}
+		}
+
+		j, err = sql.ParseJSONText(c.s)
+		if c.fail {
+			if err == nil {
+				t.Errorf("ParseJSONText(%q) did not fail", c.s)
+			}
+		} else if err != nil {
+			t.Errorf("ParseJSONText(%q) failed with %s", c.s, err)
+		} else {
+			if j.String() != c.s {
+				t.Errorf("ParseJSONText(%q) got %s", c.s, j)
+			}
+			if j.JSONB().String() != c.r {
+				t.Errorf("ParseJSONText(%q).JSONB() got %s want %s", c.s, j.JSONB(), c.r)
+			}
+		}
+	}
+}
+
+func TestJSONCompare(t *testing.T) {
+	values := []string{
+		"null",
+		`""`,
+		`"a"`,
+		`"b"`,
+		"-1",
+		"0",
+		"1.5",
+		"false",
+		"true",
+		"[]",
+		"[2]",
+		`["a", 1]`,
+		"[1, 2]",
+		"{}",
+		`{"b": 1}`,
+		`{"b": 2}`,
+		`{"aa": 1}`,
+		`{"a": 1, "b": 2}`,
+	}
+
+	for i := range values {
+		for j := range values {
+			j1 := parseJSON(t, values[i])
+			j2 := parseJSON(t, values[j])
+			cmp, err := j1.Compare(j2)
+			if err != nil {
+				t.Errorf("Compare(%s, %s) failed with %s", j1, j2, err)
+			}
+			var want int
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if cmp != want {
+				t.Errorf("Compare(%s, %s) got %d want %d", j1, j2, cmp, want)
+			}
+		}
+	}
+
+	cmp, err := parseJSON(t, "1.0").Compare(parseJSON(t, "1"))
+	if err != nil || cmp != 0 {
+		t.Errorf("Compare(1.0, 1) got %d, %v want 0", cmp, err)
+	}
+}
+
+func TestJSONOperations(t *testing.T) {
+	doc := parseJSON(t, `{"a": {"b": [10, 20, {"c": "x"}]}, "tags": ["red", "blue"], "n": null}`)
+
+	for _, c := range []struct {
+		path []string
+		r    string
+		ok   bool
+	}{
+		{path: []string{"a", "b", "1"}, r: "20", ok: true},
+		{path: []string{"a", "b", "-1", "c"}, r: `"x"`, ok: true},
+		{path: []string{"n"}, r: "null", ok: true},
+		{path: []string{"a", "b", "x"}},
+		{path: []string{"a", "b", "3"}},
+		{path: []string{"missing"}},
+		{path: nil, r: doc.String(), ok: true},
+	} {
+		j, ok := doc.Path(c.path)
+		if ok != c.ok {
+			t.Errorf("Path(%v) got %v want %v", c.path, ok, c.ok)
+		} else if ok && j.String() != c.r {
+			t.Errorf("Path(%v) got %s want %s", c.path, j, c.r)
+		}
+	}
+
+	for _, c := range []struct {
+		j1, j2 string
+		r      bool
+	}{
+		{`{"a": 1, "b": 2}`, `{"a": 1}`, true},
+		{`{"a": 1, "b": 2}`, `{"a": 2}`, false},
+		{`{"a": {"b": 1, "c": 2}}`, `{"a": {"c": 2}}`, true},
+		{`[1, 2, [3, 4]]`, `[[4], 1]`, true},
+		{`[1, 2, 3]`, `[1, 1]`, true},
+		{`[1, 2, 3]`, `[4]`, false},
+		{`["a", "b"]`, `"a"`, true},
+		{`"a"`, `["a"]`, false},
+		{`{"a": [1, 2]}`, `{"a": 1}`, false},
+		{`1`, `1.0`, true},
+		{`{}`, `{}`, true},
+	} {
+		r := parseJSON(t, c.j1).Contains(parseJSON(t, c.j2))
+		if r != c.r {
+			t.Errorf("Contains(%s, %s) got %v want %v", c.j1, c.j2, r, c.r)
+		}
+	}
+
+	for _, c := range []struct {
+		j   string
+		key string
+		r   bool
+	}{
+		{`{"a": 1}`, "a", true},
+		{`{"a": 1}`, "b", false},
+		{`["a", "b"]`, "b", true},
+		{`[1, 2]`, "1", false},
+		{`"a"`, "a", true},
+	} {
+		r := parseJSON(t, c.j).HasKey(c.key)
+		if r != c.r {
+			t.Errorf("HasKey(%s, %s) got %v want %v", c.j, c.key, r, c.r)
+		}
+	}
+
+	for _, c := range []struct {
+		j1, j2 string
+		r      string
+	}{
+		{`{"a": 1, "b": 2}`, `{"b": 3, "c": 4}`, `{"a": 1, "b": 3, "c": 4}`},
+		{`[1, 2]`, `[3]`, `[1, 2, 3]`},
+		{`[1]`, `{"a": 1}`, `[1, {"a": 1}]`},
+		{`1`, `"a"`, `[1, "a"]`},
+	} {
+		r := parseJSON(t, c.j1).Concat(parseJSON(t, c.j2))
+		if r.String() != c.r {
+			t.Errorf("Concat(%s, %s) got %s want %s", c.j1, c.j2, r, c.r)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxNumericPrecision is the largest number of digits in a numeric column.
+	MaxNumericPrecision = 1000
+
+	// MaxNumericScale is the largest number of digits after the decimal point.
+	MaxNumericScale = 1000
+
+	// MinDivisionDigits is the minimum number of significant digits in the result of dividing
+	// two numeric values.
+	MinDivisionDigits = 16
+)
+
+// NumericValue is an exact decimal number: coef * 10^-scale. The scale is never negative and
+// is kept from the input, so 1.50 and 1.5 are equal but are displayed differently.
+type NumericValue struct {
+	coef  *big.Int // nil is zero
+	scale int
+}
+
+var (
+	bigZero = big.NewInt(0)
+	bigTen  = big.NewInt(10)
+)
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// MakeNumericValue returns coef * 10^-scale.
+func MakeNumericValue(coef *big.Int, scale int) NumericValue {
+	coef = new(big.Int).Set(coef)
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return NumericValue{coef: coef, scale: scale}
+}
+
+func NumericFromInt64(i int64) NumericValue {
+	return NumericValue{coef: big.NewInt(i)}
+}
+
+// NumericFromFloat64 converts f using 15 significant digits, which is the precision that a
+// float64 reliably holds.
+func NumericFromFloat64(f float64) (NumericValue, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return NumericValue{}, fmt.Errorf("cannot convert %v to numeric", f)
+	}
+	return ParseNumeric(strconv.FormatFloat(f, 'g', 15, 64))
+}
+
+// ParseNumeric parses a decimal number with an optional sign, fraction, and exponent.
+func ParseNumeric(s string) (NumericValue, error) {
+	t := strings.TrimSpace(s)
+	exp := 0
+	if i := strings.IndexAny(t, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.Atoi(t[i+1:])
+		if err != nil || exp > MaxNumericScale || exp < -MaxNumericScale {
+			return NumericValue{}, fmt.Errorf("invalid numeric: %s", s)
+		}
+		t = t[:i]
+	}
+
+	neg := false
+	if len(t) > 0 && (t[0] == '-' || t[0] == '+') {
+		neg = t[0] == '-'
+		t = t[1:]
+	}
+
+	scale := 0
+	if i := strings.IndexByte(t, '.'); i >= 0 {
+		scale = len(t) - i - 1
+		t = t[:i] + t[i+1:]
+	}
+	if t == "" || strings.IndexFunc(t, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return NumericValue{}, fmt.Errorf("invalid numeric: %s", s)
+	}
+
+	coef, _ := new(big.Int).SetString(t, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	scale -= exp
+	if scale > MaxNumericScale {
+		return NumericValue{}, fmt.Errorf("invalid numeric: %s", s)
+	}
+	return MakeNumericValue(coef, scale), nil
+}
+
+func (n NumericValue) bigInt() *big.Int {
+	if n.coef == nil {
+		return bigZero
+	}
+	return n.coef
+}
+
+// Coefficient returns the unscaled value of n.
+func (n NumericValue) Coefficient() *big.Int {
+	return new(big.Int).Set(n.bigInt())
+}
+
+func (n NumericValue) Scale() int {
+	return n.scale
+}
+
+func (n NumericValue) Sign() int {
+	return n.bigInt().Sign()
+}
+
+func (n NumericValue) String() string {
+	s := new(big.Int).Abs(n.bigInt()).String()
+	if n.scale > 0 {
+		if len(s) <= n.scale {
+			s = strings.Repeat("0", n.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-n.scale] + "." + s[len(s)-n.scale:]
+	}
+	if n.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// Digits returns the significant digits of n, without leading or trailing zeros, and the
+// exponent e such that the absolute value of n is 0.digits * 10^e.
+func (n NumericValue) Digits() (string, int) {
+	s := new(big.Int).Abs(n.bigInt()).String()
+	exp := len(s) - n.scale
+	return strings.TrimRight(s, "0"), exp
+}
+
+// rescale returns the coefficient of n with scale digits after the decimal point; scale must
+// be at least n.scale.
+func (n NumericValue) rescale(scale int) *big.Int {
+	if scale == n.scale {
+		return n.bigInt()
+	}
+	return new(big.Int).Mul(n.bigInt(), pow10(scale-n.scale))
+}
+
+func (n1 NumericValue) Compare(v2 Value) (int, error) {
+	switch v2 := v2.(type) {
+	case Int64Value:
+		return n1.Compare(NumericFromInt64(int64(v2)))
+	case Float64Value:
+		f1 := n1.Float64()
+		if f1 < float64(v2) {
+			return -1, nil
+		} else if f1 > float64(v2) {
+			return 1, nil
+		}
+		return 0, nil
+	case NumericValue:
+		scale := n1.scale
+		if v2.scale > scale {
+			scale = v2.scale
+		}
+		return n1.rescale(scale).Cmp(v2.rescale(scale)), nil
+	}
+	return 0, fmt.Errorf("engine: want number got %v", v2)
+}
+
+func (n NumericValue) Float64() float64 {
+	f, _ := strconv.ParseFloat(n.String(), 64)
+	return f
+}
+
+// Int64 rounds n to an integer; it returns false if the integer does not fit in an int64.
+func (n NumericValue) Int64() (int64, bool) {
+	i := n.Round(0).bigInt()
+	if !i.IsInt64() {
+		return 0, false
+	}
+	return i.Int64(), true
+}
+
+// Round returns n with scale digits after the decimal point, rounding half away from zero.
+func (n NumericValue) Round(scale int) NumericValue {
+	if scale >= n.scale {
+		return NumericValue{coef: n.rescale(scale), scale: scale}
+	}
+
+	d := pow10(n.scale - scale)
+	q, r := new(big.Int).QuoRem(n.bigInt(), d, new(big.Int))
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(d) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return NumericValue{coef: q, scale: scale}
+}
+
+// Fit rounds n to scale and checks that the result has no more than precision digits. A
+// precision of zero means that n is not constrained.
+func (n NumericValue) Fit(precision, scale int) (NumericValue, error) {
+	if precision == 0 {
+		return n, nil
+	}
+	r := n.Round(scale)
+	if len(new(big.Int).Abs(r.bigInt()).String()) > precision {
+		return NumericValue{}, fmt.Errorf("numeric field overflow: precision %d, scale %d: %s",
+			precision, scale, n)
+	}
+	return r, nil
+}
+
+func (n NumericValue) Negate() NumericValue {
+	return NumericValue{coef: new(big.Int).Neg(n.bigInt()), scale: n.scale}
+}
+
+func (n NumericValue) Abs() NumericValue {
+	return NumericValue{coef: new(big.Int).Abs(n.bigInt()), scale: n.scale}
+}
+
+func (n1 NumericValue) Add(n2 NumericValue) NumericValue {
+	scale := n1.scale
+	if n2.scale > scale {
+		scale = n2.scale
+	}
+	return NumericValue{coef: new(big.Int).Add(n1.rescale(scale), n2.rescale(scale)),
+		scale: scale}
+}
+
+func (n1 NumericValue) Subtract(n2 NumericValue) NumericValue {
+	return n1.Add(n2.Negate())
+}
+
+func (n1 NumericValue) Multiply(n2 NumericValue) NumericValue {
+	return NumericValue{coef: new(big.Int).Mul(n1.bigInt(), n2.bigInt()),
+		scale: n1.scale + n2.scale}
+}
+
+// Divide returns n1 / n2 rounded to at least MinDivisionDigits significant digits, and to at
+// least as many digits after the decimal point as either n1 or n2.
+func (n1 NumericValue) Divide(n2 NumericValue) (NumericValue, error) {
+	if n2.Sign() == 0 {
+		return NumericValue{}, fmt.Errorf("division by zero")
+	}
+
+	scale := MinDivisionDigits
+	if n1.Sign() != 0 {
+		_, e1 := n1.Digits()
+		_, e2 := n2.Digits()
+		scale -= e1 - e2
+	}
+	if n1.scale > scale {
+		scale = n1.scale
+	}
+	if n2.scale > scale {
+		scale = n2.scale
+	}
+	if scale < 0 {
+		scale = 0
+	} else if scale > MaxNumericScale {
+		scale = MaxNumericScale
+	}
+
+	// Compute one extra digit and then round.
+	num := new(big.Int).Mul(n1.bigInt(), pow10(scale+1+n2.scale))
+	den := new(big.Int).Mul(n2.bigInt(), pow10(n1.scale))
+	q := NumericValue{coef: num.Quo(num, den), scale: scale + 1}
+	return q.Round(scale), nil
+}
+
+// Modulo returns the remainder of n1 / n2 truncated toward zero; it has the sign of n1.
+func (n1 NumericValue) Modulo(n2 NumericValue) (NumericValue, error) {
+	if n2.Sign() == 0 {
+		return NumericValue{}, fmt.Errorf("division by zero")
+	}
+	scale := n1.scale
+	if n2.scale > scale {
+		scale = n2.scale
+	}
+	return NumericValue{coef: new(big.Int).Rem(n1.rescale(scale), n2.rescale(scale)),
+		scale: scale}, nil
+}
+
+// ConvertNumeric converts a string or a number to a numeric value.
+func ConvertNumeric(v Value) (Value, error) {
+	switch v := v.(type) {
+	case NumericValue:
+		return v, nil
+	case Int64Value:
+		return NumericFromInt64(int64(v)), nil
+	case Float64Value:
+		n, err := NumericFromFloat64(float64(v))
+		if err != nil {
+			return nil, fmt.Errorf("expected a numeric value: %v: %s", v, err)
+		}
+		return n, nil
+	case StringValue:
+		n, err := ParseNumeric(string(v))
+		if err != nil {
+			return nil, fmt.Errorf("expected a numeric value: %v: %s", v, err)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("expected a numeric value: %v", v)
+}
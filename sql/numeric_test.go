@@ -0,0 +1,144 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/sql"
+)
+
+func parseNumeric(t *testing.T, s string) sql.NumericValue {
+	t.Helper()
+
+	n, err := sql.ParseNumeric(s)
+	if err != nil {
+		t.Fatalf("ParseNumeric(%q) failed with %s", s, err)
+	}
+	return n
+}
+
+func TestParseNumeric(t *testing.T) {
+	cases := []struct {
+		s    string
+		r    string
+		fail bool
+	}{
+		{s: "0", r: "0"},
+		{s: "123", r: "123"},
+		{s: " -123.450 ", r: "-123.450"},
+		{s: "+.5", r: "0.5"},
+		{s: "5.", r: "5"},
+		{s: "-0.005", r: "-0.005"},
+		{s: "1e3", r: "1000"},
+		{s: "1.5E-3", r: "0.0015"},
+		{s: "12345678901234567890.123456789", r: "12345678901234567890.123456789"},
+		{s: "", fail: true},
+		{s: ".", fail: true},
+		{s: "1.2.3", fail: true},
+		{s: "12a", fail: true},
+		{s: "1e", fail: true},
+		{s: "--1", fail: true},
+	}
+
+	for _, c := range cases {
+		n, err := sql.ParseNumeric(c.s)
+		if c.fail {
+			if err == nil {
+				t.Errorf("ParseNumeric(%q) did not fail", c.s)
+			}
+		} else if err != nil {
+			t.Errorf("ParseNumeric(%q) failed with %s", c.s, err)
+		} else if n.String() != c.r {
+			t.Errorf("ParseNumeric(%q) got %s want %s", c.s, n, c.r)
+		}
+	}
+}
+
+func TestNumericArithmetic(t *testing.T) {
+	cases := []struct {
+		op     string
+		n1, n2 string
+		r      string
+	}{
+		{op: "+", n1: "0.1", n2: "0.2", r: "0.3"},
+		{op: "+", n1: "1.50", n2: "-2", r: "-0.50"},
+		{op: "-", n1: "100", n2: "0.001", r: "99.999"},
+		{op: "*", n1: "1.5", n2: "1.5", r: "2.25"},
+		{op: "*", n1: "-0.10", n2: "3", r: "-0.30"},
+		{op: "/", n1: "1", n2: "3", r: "0.3333333333333333"},
+		{op: "/", n1: "2", n2: "3", r: "0.6666666666666667"},
+		{op: "/", n1: "-2", n2: "3", r: "-0.6666666666666667"},
+		{op: "/", n1: "10", n2: "4", r: "2.500000000000000"},
+		{op: "/", n1: "1.00000000000000000000", n2: "8", r: "0.12500000000000000000"},
+		{op: "/", n1: "0", n2: "7", r: "0.0000000000000000"},
+		{op: "%", n1: "7.5", n2: "2", r: "1.5"},
+		{op: "%", n1: "-7.5", n2: "2", r: "-1.5"},
+		{op: "round", n1: "2.5", n2: "0", r: "3"},
+		{op: "round", n1: "-2.5", n2: "0", r: "-3"},
+		{op: "round", n1: "1.005", n2: "2", r: "1.01"},
+		{op: "round", n1: "1.004", n2: "2", r: "1.00"},
+		{op: "round", n1: "1.5", n2: "3", r: "1.500"},
+	}
+
+	for _, c := range cases {
+		n1 := parseNumeric(t, c.n1)
+		n2 := parseNumeric(t, c.n2)
+
+		var r sql.NumericValue
+		var err error
+		switch c.op {
+		case "+":
+			r = n1.Add(n2)
+		case "-":
+			r = n1.Subtract(n2)
+		case "*":
+			r = n1.Multiply(n2)
+		case "/":
+			r, err = n1.Divide(n2)
+		case "%":
+			r, err = n1.Modulo(n2)
+		case "round":
+			i, _ := n2.Int64()
+			r = n1.Round(int(i))
+		}
+		if err != nil {
+			t.Errorf("%s %s %s failed with %s", c.n1, c.op, c.n2, err)
+		} else if r.String() != c.r {
+			t.Errorf("%s %s %s got %s want %s", c.n1, c.op, c.n2, r, c.r)
+		}
+	}
+
+	_, err := parseNumeric(t, "1").Divide(parseNumeric(t, "0.00"))
+	if err == nil {
+		t.Errorf("Divide(1, 0.00) did not fail")
+	}
+}
+
+func TestNumericFit(t *testing.T) {
+	cases := []struct {
+		s                string
+		precision, scale int
+		r                string
+		fail             bool
+	}{
+		{s: "123.456", r: "123.456"},
+		{s: "123.456", precision: 6, scale: 2, r: "123.46"},
+		{s: "123.456", precision: 5, scale: 2, r: "123.46"},
+		{s: "123.456", precision: 4, scale: 2, fail: true},
+		{s: "-999.995", precision: 5, scale: 2, fail: true},
+		{s: "0.001", precision: 3, scale: 3, r: "0.001"},
+		{s: "7", precision: 5, scale: 2, r: "7.00"},
+	}
+
+	for _, c := range cases {
+		r, err := parseNumeric(t, c.s).Fit(c.precision, c.scale)
+		if c.fail {
+			if err == nil {
+				t.Errorf("Fit(%s, %d, %d) did not fail", c.s, c.precision, c.scale)
+			}
+		} else if err != nil {
+			t.Errorf("Fit(%s, %d, %d) failed with %s", c.s, c.precision, c.scale, err)
+		} else if r.String() != c.r {
+			t.Errorf("Fit(%s, %d, %d) got %s want %s", c.s, c.precision, c.scale, r, c.r)
+		}
+	}
+}
@@ -0,0 +1,645 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MicrosecondsPerSecond = 1000000
+	MicrosecondsPerMinute = 60 * MicrosecondsPerSecond
+	MicrosecondsPerHour   = 60 * MicrosecondsPerMinute
+	MicrosecondsPerDay    = 24 * MicrosecondsPerHour
+	DaysPerMonth          = 30
+)
+
+// DateValue is the number of days since 1970-01-01.
+type DateValue int64
+
+// TimeValue is the number of microseconds since midnight.
+type TimeValue int64
+
+// TimestampValue is the number of microseconds since 1970-01-01 00:00:00; it does not have a
+// time zone.
+type TimestampValue int64
+
+// TimestampTZValue is the number of microseconds since 1970-01-01 00:00:00 UTC.
+type TimestampTZValue int64
+
+// IntervalValue is a span of time. Months and days are kept separately from microseconds
+// because the number of days in a month and the number of hours in a day vary.
+type IntervalValue struct {
+	Months       int64
+	Days         int64
+	Microseconds int64
+}
+
+func floorDiv(n, d int64) (int64, int64) {
+	q := n / d
+	r := n % d
+	if r < 0 {
+		q -= 1
+		r += d
+	}
+	return q, r
+}
+
+func civilDays(y int, m time.Month, d int) int64 {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+func civilDate(days int64) (int, time.Month, int) {
+	return time.Unix(days*86400, 0).UTC().Date()
+}
+
+func MakeDateValue(t time.Time) DateValue {
+	return DateValue(civilDays(t.Date()))
+}
+
+func MakeTimestampValue(t time.Time) TimestampValue {
+	return TimestampValue(timeMicroseconds(t))
+}
+
+func MakeTimestampTZValue(t time.Time) TimestampTZValue {
+	return TimestampTZValue(timeMicroseconds(t))
+}
+
+func timeMicroseconds(t time.Time) int64 {
+	return t.Unix()*MicrosecondsPerSecond + int64(t.Nanosecond()/1000)
+}
+
+func microsecondsTime(us int64) time.Time {
+	sec, rem := floorDiv(us, MicrosecondsPerSecond)
+	return time.Unix(sec, rem*1000).UTC()
+}
+
+func (d DateValue) Time() time.Time {
+	return time.Unix(int64(d)*86400, 0).UTC()
+}
+
+func (ts TimestampValue) Time() time.Time {
+	return microsecondsTime(int64(ts))
+}
+
+func (ts TimestampTZValue) Time() time.Time {
+	return microsecondsTime(int64(ts))
+}
+
+func formatClock(us int64) string {
+	s := fmt.Sprintf("%02d:%02d:%02d", us/MicrosecondsPerHour,
+		(us%MicrosecondsPerHour)/MicrosecondsPerMinute,
+		(us%MicrosecondsPerMinute)/MicrosecondsPerSecond)
+	if frac := us % MicrosecondsPerSecond; frac != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%06d", frac), "0")
+	}
+	return s
+}
+
+// formatDate returns the date and its era; years before 1 AD are BC, and year 0 is 1 BC. The
+// era goes at the end of a timestamp, after the time and the time zone.
+func formatDate(days int64) (string, string) {
+	y, m, d := civilDate(days)
+	if y <= 0 {
+		return fmt.Sprintf("%04d-%02d-%02d", 1-y, m, d), " BC"
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", y, m, d), ""
+}
+
+func formatTimestamp(us int64, zone string) string {
+	days, tod := floorDiv(us, MicrosecondsPerDay)
+	date, era := formatDate(days)
+	return date + " " + formatClock(tod) + zone + era
+}
+
+func (d DateValue) String() string {
+	date, era := formatDate(int64(d))
+	return date + era
+}
+
+func (t TimeValue) String() string {
+	return formatClock(int64(t))
+}
+
+func (ts TimestampValue) String() string {
+	return formatTimestamp(int64(ts), "")
+}
+
+func (ts TimestampTZValue) String() string {
+	return formatTimestamp(int64(ts), "+00")
+}
+
+func pluralUnit(n int64, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func (iv IntervalValue) String() string {
+	var parts []string
+	if years := iv.Months / 12; years != 0 {
+		parts = append(parts, pluralUnit(years, "year"))
+	}
+	if mons := iv.Months % 12; mons != 0 {
+		parts = append(parts, pluralUnit(mons, "mon"))
+	}
+	if iv.Days != 0 {
+		parts = append(parts, pluralUnit(iv.Days, "day"))
+	}
+	if iv.Microseconds != 0 || len(parts) == 0 {
+		if iv.Microseconds < 0 {
+			parts = append(parts, "-"+formatClock(-iv.Microseconds))
+		} else if iv.Months < 0 || iv.Days < 0 {
+			parts = append(parts, "+"+formatClock(iv.Microseconds))
+		} else {
+			parts = append(parts, formatClock(iv.Microseconds))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// timestampMicroseconds returns the number of microseconds since 1970-01-01 for dates and
+// timestamps so that they can be compared with each other.
+func timestampMicroseconds(v Value) (int64, bool) {
+	switch v := v.(type) {
+	case DateValue:
+		return int64(v) * MicrosecondsPerDay, true
+	case TimestampValue:
+		return int64(v), true
+	case TimestampTZValue:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func compareInt64(i1, i2 int64) int {
+	if i1 < i2 {
+		return -1
+	} else if i1 > i2 {
+		return 1
+	}
+	return 0
+}
+
+func compareTimestamps(v1, v2 Value) (int, error) {
+	us1, _ := timestampMicroseconds(v1)
+	us2, ok := timestampMicroseconds(v2)
+	if !ok {
+		return 0, fmt.Errorf("engine: want date or timestamp got %v", v2)
+	}
+	return compareInt64(us1, us2), nil
+}
+
+func (d1 DateValue) Compare(v2 Value) (int, error) {
+	if d2, ok := v2.(DateValue); ok {
+		return compareInt64(int64(d1), int64(d2)), nil
+	}
+	return compareTimestamps(d1, v2)
+}
+
+func (t1 TimeValue) Compare(v2 Value) (int, error) {
+	if t2, ok := v2.(TimeValue); ok {
+		return compareInt64(int64(t1), int64(t2)), nil
+	}
+	return 0, fmt.Errorf("engine: want time got %v", v2)
+}
+
+func (ts1 TimestampValue) Compare(v2 Value) (int, error) {
+	return compareTimestamps(ts1, v2)
+}
+
+func (ts1 TimestampTZValue) Compare(v2 Value) (int, error) {
+	return compareTimestamps(ts1, v2)
+}
+
+// Span returns the interval as a number of days and a number of microseconds less than a day,
+// assuming that a month is always 30 days and a day is always 24 hours.
+func (iv IntervalValue) Span() (int64, int64) {
+	days, us := floorDiv(iv.Microseconds, MicrosecondsPerDay)
+	return iv.Months*DaysPerMonth + iv.Days + days, us
+}
+
+func (iv1 IntervalValue) Compare(v2 Value) (int, error) {
+	if iv2, ok := v2.(IntervalValue); ok {
+		days1, us1 := iv1.Span()
+		days2, us2 := iv2.Span()
+		if cmp := compareInt64(days1, days2); cmp != 0 {
+			return cmp, nil
+		}
+		return compareInt64(us1, us2), nil
+	}
+	return 0, fmt.Errorf("engine: want interval got %v", v2)
+}
+
+func (iv IntervalValue) Negate() IntervalValue {
+	return IntervalValue{-iv.Months, -iv.Days, -iv.Microseconds}
+}
+
+func (iv1 IntervalValue) Add(iv2 IntervalValue) IntervalValue {
+	return IntervalValue{
+		Months:       iv1.Months + iv2.Months,
+		Days:         iv1.Days + iv2.Days,
+		Microseconds: iv1.Microseconds + iv2.Microseconds,
+	}
+}
+
+// Multiply scales the interval by f; fractional months and days cascade down to days and
+// microseconds.
+func (iv IntervalValue) Multiply(f float64) (IntervalValue, error) {
+	months := float64(iv.Months) * f
+	days := float64(iv.Days)*f + (months-math.Trunc(months))*DaysPerMonth
+	us := float64(iv.Microseconds)*f + (days-math.Trunc(days))*MicrosecondsPerDay
+	if math.IsNaN(us) || math.Abs(months) >= math.MaxInt64 || math.Abs(days) >= math.MaxInt64 ||
+		math.Abs(us) >= math.MaxInt64 {
+
+		return IntervalValue{}, fmt.Errorf("engine: interval out of range")
+	}
+	return IntervalValue{
+		Months:       int64(months),
+		Days:         int64(days),
+		Microseconds: int64(math.Round(us)),
+	}, nil
+}
+
+// addMonths adds months to a date; if the day is past the end of the resulting month, it is
+// changed to the last day of that month.
+func addMonths(days, months int64) int64 {
+	if months == 0 {
+		return days
+	}
+
+	y, m, d := civilDate(days)
+	n := int64(y)*12 + int64(m) - 1 + months
+	y = int(n / 12)
+	m = time.Month(n%12 + 1)
+	if n%12 < 0 {
+		y -= 1
+		m += 12
+	}
+	if last := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day(); d > last {
+		d = last
+	}
+	return civilDays(y, m, d)
+}
+
+func addInterval(us int64, iv IntervalValue) int64 {
+	days, tod := floorDiv(us, MicrosecondsPerDay)
+	days = addMonths(days, iv.Months) + iv.Days
+	return days*MicrosecondsPerDay + tod + iv.Microseconds
+}
+
+func (ts TimestampValue) AddInterval(iv IntervalValue) TimestampValue {
+	return TimestampValue(addInterval(int64(ts), iv))
+}
+
+func (ts TimestampTZValue) AddInterval(iv IntervalValue) TimestampTZValue {
+	return TimestampTZValue(addInterval(int64(ts), iv))
+}
+
+func (t TimeValue) AddInterval(iv IntervalValue) TimeValue {
+	_, us := floorDiv(int64(t)+iv.Microseconds, MicrosecondsPerDay)
+	return TimeValue(us)
+}
+
+// SubtractTimestamps returns the interval between two timestamps as days and microseconds.
+func SubtractTimestamps(us1, us2 int64) IntervalValue {
+	us := us1 - us2
+	return IntervalValue{
+		Days:         us / MicrosecondsPerDay,
+		Microseconds: us % MicrosecondsPerDay,
+	}
+}
+
+var (
+	dateRegexp  = regexp.MustCompile(`^(\d{4,})-(\d{1,2})-(\d{1,2})`)
+	clockRegexp = regexp.MustCompile(`^(\d{1,2}):(\d{2})(?::(\d{2})(?:\.(\d+))?)?`)
+	zoneRegexp  = regexp.MustCompile(`^(?:z|utc|gmt|([+-])(\d{1,2})(?::?(\d{2}))?)$`)
+)
+
+func parseDate(s string, bc bool) (int64, string, bool) {
+	m := dateRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", false
+	}
+	y, _ := strconv.Atoi(m[1])
+	if y < 1 {
+		return 0, "", false
+	} else if bc {
+		y = 1 - y
+	}
+	mon, _ := strconv.Atoi(m[2])
+	d, _ := strconv.Atoi(m[3])
+	if mon < 1 || mon > 12 || d < 1 ||
+		d > time.Date(y, time.Month(mon)+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+
+		return 0, "", false
+	}
+	return civilDays(y, time.Month(mon), d), s[len(m[0]):], true
+}
+
+func parseClock(s string) (int64, string, bool) {
+	m := clockRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", false
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	var sec, frac int
+	if m[3] != "" {
+		sec, _ = strconv.Atoi(m[3])
+	}
+	if m[4] != "" {
+		f := m[4]
+		if len(f) > 6 {
+			f = f[:6]
+		}
+		frac, _ = strconv.Atoi(f + strings.Repeat("0", 6-len(f)))
+	}
+	if min > 59 || sec > 59 || h > 24 || (h == 24 && (min != 0 || sec != 0 || frac != 0)) {
+		return 0, "", false
+	}
+	return int64(h)*MicrosecondsPerHour + int64(min)*MicrosecondsPerMinute +
+		int64(sec)*MicrosecondsPerSecond + int64(frac), s[len(m[0]):], true
+}
+
+// parseZone returns the offset from UTC in microseconds.
+func parseZone(s string) (int64, bool) {
+	m := zoneRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	} else if m[1] == "" {
+		return 0, true
+	}
+	h, _ := strconv.Atoi(m[2])
+	var min int
+	if m[3] != "" {
+		min, _ = strconv.Atoi(m[3])
+	}
+	if h > 15 || min > 59 {
+		return 0, false
+	}
+	off := int64(h)*MicrosecondsPerHour + int64(min)*MicrosecondsPerMinute
+	if m[1] == "-" {
+		off = -off
+	}
+	return off, true
+}
+
+// parseTimestamp parses a date followed by an optional time, an optional time zone, and an
+// optional era. It returns the number of microseconds since 1970-01-01 00:00:00 in the time zone, and the offset
+// of the time zone from UTC.
+func parseTimestamp(s string) (int64, int64, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "epoch" {
+		return 0, 0, true
+	}
+
+	var bc bool
+	if strings.HasSuffix(s, " bc") {
+		bc = true
+		s = strings.TrimSpace(s[:len(s)-3])
+	} else if strings.HasSuffix(s, " ad") {
+		s = strings.TrimSpace(s[:len(s)-3])
+	}
+
+	days, s, ok := parseDate(s, bc)
+	if !ok {
+		return 0, 0, false
+	}
+	us := days * MicrosecondsPerDay
+	if s == "" {
+		return us, 0, true
+	} else if s[0] != ' ' && s[0] != 't' {
+		return 0, 0, false
+	}
+
+	tod, s, ok := parseClock(strings.TrimSpace(s[1:]))
+	if !ok {
+		return 0, 0, false
+	}
+	us += tod
+	if strings.TrimSpace(s) == "" {
+		return us, 0, true
+	}
+	off, ok := parseZone(s)
+	if !ok {
+		return 0, 0, false
+	}
+	return us, off, true
+}
+
+func ParseDate(s string) (DateValue, error) {
+	us, _, ok := parseTimestamp(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid input for type DATE: %s", s)
+	}
+	days, _ := floorDiv(us, MicrosecondsPerDay)
+	return DateValue(days), nil
+}
+
+func ParseTime(s string) (TimeValue, error) {
+	us, rest, ok := parseClock(strings.ToLower(strings.TrimSpace(s)))
+	if ok && strings.TrimSpace(rest) != "" {
+		_, ok = parseZone(rest)
+	}
+	if !ok {
+		return 0, fmt.Errorf("invalid input for type TIME: %s", s)
+	}
+	return TimeValue(us), nil
+}
+
+// ParseTimestamp parses a timestamp; a time zone, if any, is ignored.
+func ParseTimestamp(s string) (TimestampValue, error) {
+	us, _, ok := parseTimestamp(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid input for type TIMESTAMP: %s", s)
+	}
+	return TimestampValue(us), nil
+}
+
+// ParseTimestampTZ parses a timestamp; if it does not include a time zone, it is in UTC.
+func ParseTimestampTZ(s string) (TimestampTZValue, error) {
+	us, off, ok := parseTimestamp(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid input for type TIMESTAMPTZ: %s", s)
+	}
+	return TimestampTZValue(us - off), nil
+}
+
+var (
+	intervalUnits = map[string]struct {
+		months int64
+		days   int64
+		us     int64
+	}{
+		"microsecond": {us: 1},
+		"us":          {us: 1},
+		"millisecond": {us: 1000},
+		"ms":          {us: 1000},
+		"second":      {us: MicrosecondsPerSecond},
+		"sec":         {us: MicrosecondsPerSecond},
+		"s":           {us: MicrosecondsPerSecond},
+		"minute":      {us: MicrosecondsPerMinute},
+		"min":         {us: MicrosecondsPerMinute},
+		"m":           {us: MicrosecondsPerMinute},
+		"hour":        {us: MicrosecondsPerHour},
+		"hr":          {us: MicrosecondsPerHour},
+		"h":           {us: MicrosecondsPerHour},
+		"day":         {days: 1},
+		"d":           {days: 1},
+		"week":        {days: 7},
+		"w":           {days: 7},
+		"month":       {months: 1},
+		"mon":         {months: 1},
+		"year":        {months: 12},
+		"yr":          {months: 12},
+		"y":           {months: 12},
+		"decade":      {months: 120},
+		"century":     {months: 1200},
+		"centuries":   {months: 1200},
+		"millennium":  {months: 12000},
+		"millennia":   {months: 12000},
+	}
+
+	intervalRegexp = regexp.MustCompile(`^([+-]?(?:\d+\.?\d*|\.\d+))\s*([a-z]*)$`)
+)
+
+func intervalUnit(unit string) (int64, int64, int64, bool) {
+	u, ok := intervalUnits[unit]
+	if !ok && strings.HasSuffix(unit, "s") {
+		u, ok = intervalUnits[unit[:len(unit)-1]]
+	}
+	return u.months, u.days, u.us, ok
+}
+
+// ParseInterval parses intervals such as '1 year 2 mons 3 days 04:05:06' or '-2.5 hours ago'.
+func ParseInterval(s string) (IntervalValue, error) {
+	var iv IntervalValue
+
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) > 0 && fields[0] == "@" {
+		fields = fields[1:]
+	}
+	var ago bool
+	if len(fields) > 0 && fields[len(fields)-1] == "ago" {
+		ago = true
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 {
+		return iv, fmt.Errorf("invalid input for type INTERVAL: %s", s)
+	}
+
+	for len(fields) > 0 {
+		f := fields[0]
+		fields = fields[1:]
+
+		if strings.IndexByte(f, ':') > 0 {
+			neg := strings.HasPrefix(f, "-")
+			us, rest, ok := parseClock(strings.TrimLeft(f, "+-"))
+			if !ok || rest != "" {
+				return iv, fmt.Errorf("invalid input for type INTERVAL: %s", s)
+			}
+			if neg {
+				us = -us
+			}
+			iv.Microseconds += us
+			continue
+		}
+
+		m := intervalRegexp.FindStringSubmatch(f)
+		if m == nil {
+			return iv, fmt.Errorf("invalid input for type INTERVAL: %s", s)
+		}
+		unit := m[2]
+		if unit == "" {
+			if len(fields) == 0 {
+				// A number by itself is a number of seconds.
+				unit = "second"
+			} else {
+				unit = fields[0]
+				fields = fields[1:]
+			}
+		}
+		months, days, us, ok := intervalUnit(unit)
+		if !ok {
+			return iv, fmt.Errorf("invalid input for type INTERVAL: %s", s)
+		}
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return iv, fmt.Errorf("invalid input for type INTERVAL: %s", s)
+		}
+
+		fiv, err := IntervalValue{Months: months, Days: days, Microseconds: us}.Multiply(n)
+		if err != nil {
+			return iv, err
+		}
+		iv = iv.Add(fiv)
+	}
+
+	if ago {
+		iv = iv.Negate()
+	}
+	return iv, nil
+}
+
+// ConvertTemporal converts a value to a date, time, timestamp, or interval.
+func ConvertTemporal(dt DataType, v Value) (Value, error) {
+	if s, ok := v.(StringValue); ok {
+		switch dt {
+		case DateType:
+			return ParseDate(string(s))
+		case TimeType:
+			return ParseTime(string(s))
+		case TimestampType:
+			return ParseTimestamp(string(s))
+		case TimestampTZType:
+			return ParseTimestampTZ(string(s))
+		case IntervalType:
+			return ParseInterval(string(s))
+		}
+	}
+
+	switch dt {
+	case DateType:
+		switch v := v.(type) {
+		case DateValue:
+			return v, nil
+		case TimestampValue, TimestampTZValue:
+			us, _ := timestampMicroseconds(v)
+			days, _ := floorDiv(us, MicrosecondsPerDay)
+			return DateValue(days), nil
+		}
+	case TimeType:
+		switch v := v.(type) {
+		case TimeValue:
+			return v, nil
+		case TimestampValue, TimestampTZValue:
+			us, _ := timestampMicroseconds(v)
+			_, tod := floorDiv(us, MicrosecondsPerDay)
+			return TimeValue(tod), nil
+		case IntervalValue:
+			_, tod := floorDiv(v.Microseconds, MicrosecondsPerDay)
+			return TimeValue(tod), nil
+		}
+	case TimestampType:
+		if us, ok := timestampMicroseconds(v); ok {
+			return TimestampValue(us), nil
+		}
+	case TimestampTZType:
+		if us, ok := timestampMicroseconds(v); ok {
+			return TimestampTZValue(us), nil
+		}
+	case IntervalType:
+		switch v := v.(type) {
+		case IntervalValue:
+			return v, nil
+		case TimeValue:
+			return IntervalValue{Microseconds: int64(v)}, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a %s value: %v", dt, v)
+}
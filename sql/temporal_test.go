@@ -0,0 +1,113 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/sql"
+)
+
+func TestParseTemporal(t *testing.T) {
+	cases := []struct {
+		dt   sql.DataType
+		s    string
+		r    string
+		fail bool
+	}{
+		{dt: sql.DateType, s: "2020-02-29", r: "2020-02-29"},
+		{dt: sql.DateType, s: "1969-12-31", r: "1969-12-31"},
+		{dt: sql.DateType, s: "2020-03-04 12:34:56", r: "2020-03-04"},
+		{dt: sql.DateType, s: "epoch", r: "1970-01-01"},
+		{dt: sql.DateType, s: "0001-12-31 BC", r: "0001-12-31 BC"},
+		{dt: sql.DateType, s: "0044-03-15 bc", r: "0044-03-15 BC"},
+		{dt: sql.DateType, s: "0001-01-01 AD", r: "0001-01-01"},
+		{dt: sql.DateType, s: "0000-01-01", fail: true},
+		{dt: sql.DateType, s: "2021-02-29", fail: true},
+		{dt: sql.DateType, s: "2021-13-01", fail: true},
+		{dt: sql.DateType, s: "abc", fail: true},
+		{dt: sql.TimeType, s: "12:34", r: "12:34:00"},
+		{dt: sql.TimeType, s: "01:02:03.45", r: "01:02:03.45"},
+		{dt: sql.TimeType, s: "23:59:59.999999", r: "23:59:59.999999"},
+		{dt: sql.TimeType, s: "24:00:00", r: "24:00:00"},
+		{dt: sql.TimeType, s: "24:00", r: "24:00:00"},
+		{dt: sql.TimeType, s: "12:60", fail: true},
+		{dt: sql.TimeType, s: "24:00:01", fail: true},
+		{dt: sql.TimestampType, s: "2020-03-04T05:06:07", r: "2020-03-04 05:06:07"},
+		{dt: sql.TimestampType, s: "2020-03-04 05:06:07+02", r: "2020-03-04 05:06:07"},
+		{dt: sql.TimestampType, s: "1960-01-01 00:00:00.5", r: "1960-01-01 00:00:00.5"},
+		{dt: sql.TimestampType, s: "2020-03-04 24:00:00", r: "2020-03-05 00:00:00"},
+		{dt: sql.TimestampType, s: "0001-12-31 23:00:00 BC", r: "0001-12-31 23:00:00 BC"},
+		{dt: sql.TimestampType, s: "2020-03-04 05:06:07 pst", fail: true},
+		{dt: sql.TimestampTZType, s: "2020-03-04 05:06:07", r: "2020-03-04 05:06:07+00"},
+		{dt: sql.TimestampTZType, s: "2020-03-04 05:06:07-08", r: "2020-03-04 13:06:07+00"},
+		{dt: sql.TimestampTZType, s: "2020-03-04 05:06:07+05:30",
+			r: "2020-03-03 23:36:07+00"},
+		{dt: sql.TimestampTZType, s: "2020-03-04 05:06:07Z", r: "2020-03-04 05:06:07+00"},
+		{dt: sql.TimestampTZType, s: "0001-12-31 23:00:00-02 BC",
+			r: "0001-01-01 01:00:00+00"},
+		{dt: sql.TimestampTZType, s: "0002-01-01 BC", r: "0002-01-01 00:00:00+00 BC"},
+		{dt: sql.IntervalType, s: "1 year 2 months 3 days 04:05:06",
+			r: "1 year 2 mons 3 days 04:05:06"},
+		{dt: sql.IntervalType, s: "1.5 days", r: "1 day 12:00:00"},
+		{dt: sql.IntervalType, s: "1.5 months", r: "1 mon 15 days"},
+		{dt: sql.IntervalType, s: "90 minutes", r: "01:30:00"},
+		{dt: sql.IntervalType, s: "@ 2 hours ago", r: "-02:00:00"},
+		{dt: sql.IntervalType, s: "-1 day 02:00", r: "-1 days +02:00:00"},
+		{dt: sql.IntervalType, s: "10", r: "00:00:10"},
+		{dt: sql.IntervalType, s: "0 seconds", r: "00:00:00"},
+		{dt: sql.IntervalType, s: "2 weeks 250ms", r: "14 days 00:00:00.25"},
+		{dt: sql.IntervalType, s: "1 fortnight", fail: true},
+		{dt: sql.IntervalType, s: "", fail: true},
+	}
+
+	for _, c := range cases {
+		v, err := sql.ConvertValue(c.dt, sql.StringValue(c.s))
+		if c.fail {
+			if err == nil {
+				t.Errorf("ConvertValue(%s, %q) did not fail", c.dt, c.s)
+			}
+		} else if err != nil {
+			t.Errorf("ConvertValue(%s, %q) failed with %s", c.dt, c.s, err)
+		} else if v.String() != c.r {
+			t.Errorf("ConvertValue(%s, %q) got %s want %s", c.dt, c.s, v, c.r)
+		}
+	}
+}
+
+func TestTemporalArithmetic(t *testing.T) {
+	ts, err := sql.ParseTimestamp("2020-01-31 10:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		iv sql.IntervalValue
+		r  string
+	}{
+		{sql.IntervalValue{Months: 1}, "2020-02-29 10:00:00"},
+		{sql.IntervalValue{Months: 13}, "2021-02-28 10:00:00"},
+		{sql.IntervalValue{Months: -2}, "2019-11-30 10:00:00"},
+		{sql.IntervalValue{Days: 1, Microseconds: 15 * sql.MicrosecondsPerHour},
+			"2020-02-02 01:00:00"},
+		{sql.IntervalValue{Microseconds: -11 * sql.MicrosecondsPerHour}, "2020-01-30 23:00:00"},
+	}
+
+	for _, c := range cases {
+		r := ts.AddInterval(c.iv).String()
+		if r != c.r {
+			t.Errorf("%s.AddInterval(%s) got %s want %s", ts, c.iv, r, c.r)
+		}
+	}
+
+	tm := sql.TimeValue(23 * sql.MicrosecondsPerHour)
+	r := tm.AddInterval(sql.IntervalValue{Microseconds: 2 * sql.MicrosecondsPerHour}).String()
+	if r != "01:00:00" {
+		t.Errorf("%s.AddInterval(2 hours) got %s want 01:00:00", tm, r)
+	}
+
+	iv, err := sql.IntervalValue{Months: 1, Days: 1}.Multiply(0.5)
+	if err != nil {
+		t.Fatal(err)
+	} else if iv.String() != "15 days 12:00:00" {
+		t.Errorf("Multiply(0.5) got %s want 15 days 12:00:00", iv)
+	}
+}
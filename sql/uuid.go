@@ -0,0 +1,115 @@
+package sql
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+)
+
+// UUIDValue is a 128 bit universally unique identifier.
+type UUIDValue [16]byte
+
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// ParseUUID parses 32 hex digits, optionally surrounded by braces and with an optional hyphen
+// after any group of four digits; this is the same input that PostgreSQL accepts.
+func ParseUUID(s string) (UUIDValue, error) {
+	var u UUIDValue
+
+	t := s
+	if len(t) > 0 && t[0] == '{' {
+		if t[len(t)-1] != '}' {
+			return u, fmt.Errorf("invalid uuid: %s", s)
+		}
+		t = t[1 : len(t)-1]
+	}
+
+	n := 0
+	for i := 0; i < len(t); i += 1 {
+		if t[i] == '-' {
+			if n == 0 || n == 32 || n%4 != 0 || t[i-1] == '-' {
+				return u, fmt.Errorf("invalid uuid: %s", s)
+			}
+			continue
+		}
+
+		h, ok := hexValue(t[i])
+		if !ok || n == 32 {
+			return u, fmt.Errorf("invalid uuid: %s", s)
+		}
+		if n%2 == 0 {
+			u[n/2] = h << 4
+		} else {
+			u[n/2] |= h
+		}
+		n += 1
+	}
+	if n != 32 {
+		return u, fmt.Errorf("invalid uuid: %s", s)
+	}
+	return u, nil
+}
+
+// RandomUUID returns a version 4 uuid.
+func RandomUUID() (UUIDValue, error) {
+	var u UUIDValue
+	_, err := rand.Read(u[:])
+	if err != nil {
+		return u, err
+	}
+	u[6] = (u[6] & 0x0F) | 0x40
+	u[8] = (u[8] & 0x3F) | 0x80
+	return u, nil
+}
+
+func (u UUIDValue) String() string {
+	var buf [36]byte
+	n := 0
+	for i, b := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			buf[n] = '-'
+			n += 1
+		}
+		buf[n] = byte(hexDigits[b>>4])
+		buf[n+1] = byte(hexDigits[b&0xF])
+		n += 2
+	}
+	return string(buf[:])
+}
+
+func (u1 UUIDValue) Compare(v2 Value) (int, error) {
+	if u2, ok := v2.(UUIDValue); ok {
+		return bytes.Compare(u1[:], u2[:]), nil
+	}
+	return 0, fmt.Errorf("engine: want uuid got %v", v2)
+}
+
+func ConvertUUID(v Value) (Value, error) {
+	switch v := v.(type) {
+	case UUIDValue:
+		return v, nil
+	case StringValue:
+		u, err := ParseUUID(string(v))
+		if err != nil {
+			return nil, fmt.Errorf("expected a uuid value: %v", v)
+		}
+		return u, nil
+	case BytesValue:
+		if len(v) == 16 {
+			var u UUIDValue
+			copy(u[:], v)
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a uuid value: %v", v)
+}
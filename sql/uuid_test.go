@@ -0,0 +1,118 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/leftmike/maho/sql"
+)
+
+func TestParseUUID(t *testing.T) {
+	cases := []struct {
+		s    string
+		r    string
+		fail bool
+	}{
+		{s: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", r: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{s: "A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11", r: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{s: "{a0eebc99-9c0b4ef8-bb6d6bb9-bd380a11}", r: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{s: "a0eebc999c0b4ef8bb6d6bb9bd380a11", r: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{s: "a0ee-bc99-9c0b-4ef8-bb6d-6bb9-bd38-0a11", r: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{s: "00000000-0000-0000-0000-000000000000", r: "00000000-0000-0000-0000-000000000000"},
+		{s: "", fail: true},
+		{s: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a1", fail: true},
+		{s: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a111", fail: true},
+		{s: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a1g", fail: true},
+		{s: "-a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", fail: true},
+		{s: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11-", fail: true},
+		{s: "a0eebc99--9c0b-4ef8-bb6d-6bb9bd380a11", fail: true},
+		{s: "a0eeb-c99-9c0b-4ef8-bb6d-6bb9bd380a11", fail: true},
+		{s: "{a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", fail: true},
+		{s: " a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", fail: true},
+	}
+
+	for _, c := range cases {
+		u, err := sql.ParseUUID(c.s)
+		if c.fail {
+			if err == nil {
+				t.Errorf("ParseUUID(%q) did not fail", c.s)
+			}
+		} else if err != nil {
+			t.Errorf("ParseUUID(%q) failed with %s", c.s, err)
+		} else if u.String() != c.r {
+			t.Errorf("ParseUUID(%q) got %s want %s", c.s, u, c.r)
+		}
+	}
+}
+
+func TestUUIDCompare(t *testing.T) {
+	values := []string{
+		"00000000-0000-0000-0000-000000000000",
+		"00000000-0000-0000-0000-000000000001",
+		"00000000-0000-0000-0001-000000000000",
+		"0fffffff-ffff-ffff-ffff-ffffffffffff",
+		"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11",
+		"ffffffff-ffff-ffff-ffff-ffffffffffff",
+	}
+
+	for i := range values {
+		for j := range values {
+			u1, err := sql.ParseUUID(values[i])
+			if err != nil {
+				t.Fatalf("ParseUUID(%q) failed with %s", values[i], err)
+			}
+			u2, err := sql.ParseUUID(values[j])
+			if err != nil {
+				t.Fatalf("ParseUUID(%q) failed with %s", values[j], err)
+			}
+			cmp, err := u1.Compare(u2)
+			if err != nil {
+				t.Errorf("Compare(%s, %s) failed with %s", u1, u2, err)
+			}
+			var want int
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if cmp != want {
+				t.Errorf("Compare(%s, %s) got %d want %d", u1, u2, cmp, want)
+			}
+		}
+	}
+
+	u, err := sql.RandomUUID()
+	if err != nil {
+		t.Fatalf("RandomUUID() failed with %s", err)
+	}
+	if _, err := u.Compare(sql.StringValue(u.String())); err == nil {
+		t.Errorf("Compare(%s, '%s') did not fail", u, u)
+	}
+}
+
+func TestRandomUUID(t *testing.T) {
+	seen := map[sql.UUIDValue]bool{}
+	for i := 0; i < 100; i += 1 {
+		u, err := sql.RandomUUID()
+		if err != nil {
+			t.Fatalf("RandomUUID() failed with %s", err)
+		}
+		if seen[u] {
+			t.Errorf("RandomUUID() returned %s twice", u)
+		}
+		seen[u] = true
+
+		s := u.String()
+		if s[14] != '4' {
+			t.Errorf("RandomUUID() got %s want version 4", s)
+		}
+		if s[19] != '8' && s[19] != '9' && s[19] != 'a' && s[19] != 'b' {
+			t.Errorf("RandomUUID() got %s want variant 1", s)
+		}
+		r, err := sql.ParseUUID(s)
+		if err != nil {
+			t.Errorf("ParseUUID(%q) failed with %s", s, err)
+		} else if r != u {
+			t.Errorf("ParseUUID(%q) got %s", s, r)
+		}
+	}
+}
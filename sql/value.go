@@ -71,6 +71,8 @@ func (i1 Int64Value) Compare(v2 Value) (int, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case NumericValue:
+		return NumericFromInt64(int64(i1)).Compare(v2)
 	}
 	return 0, fmt.Errorf("engine: want number got %v", v2)
 }
@@ -97,6 +99,9 @@ func (d1 Float64Value) Compare(v2 Value) (int, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case NumericValue:
+		cmp, err := v2.Compare(d1)
+		return -cmp, err
 	}
 	return 0, fmt.Errorf("engine: want number got %v", v2)
 }
@@ -150,6 +155,34 @@ func (b1 BytesValue) Compare(v2 Value) (int, error) {
 	return 0, fmt.Errorf("engine: want bytes got %v", v2)
 }
 
+// valueRank orders values of different types.
+func valueRank(v Value) int {
+	switch v.(type) {
+	case BoolValue:
+		return 0
+	case Float64Value, Int64Value, NumericValue:
+		return 1
+	case StringValue:
+		return 2
+	case BytesValue:
+		return 3
+	case DateValue, TimestampValue, TimestampTZValue:
+		return 4
+	case TimeValue:
+		return 5
+	case IntervalValue:
+		return 6
+	case JSONValue:
+		return 7
+	case UUIDValue:
+		return 8
+	case ArrayValue:
+		return 9
+	default:
+		panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", v, v))
+	}
+}
+
 func Compare(v1, v2 Value) int {
 	if v1 == nil {
 		if v2 == nil {
@@ -160,54 +193,16 @@ func Compare(v1, v2 Value) int {
 	if v2 == nil {
 		return 1
 	}
-	switch v1 := v1.(type) {
-	case BoolValue:
-		switch v2 := v2.(type) {
-		case BoolValue:
-			cmp, _ := v1.Compare(v2)
-			return cmp
-		case Float64Value, Int64Value, StringValue, BytesValue:
-			return -1
-		default:
-			panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", v2, v2))
-		}
-	case Float64Value, Int64Value:
-		switch v2 := v2.(type) {
-		case BoolValue:
-			return 1
-		case Float64Value, Int64Value:
-			cmp, _ := v1.Compare(v2)
-			return cmp
-		case StringValue, BytesValue:
-			return -1
-		default:
-			panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", v2, v2))
-		}
-	case StringValue:
-		switch v2 := v2.(type) {
-		case BoolValue, Float64Value, Int64Value:
-			return 1
-		case StringValue:
-			cmp, _ := v1.Compare(v2)
-			return cmp
-		case BytesValue:
-			return -1
-		default:
-			panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", v2, v2))
-		}
-	case BytesValue:
-		switch v2 := v2.(type) {
-		case BoolValue, Float64Value, Int64Value, StringValue:
-			return 1
-		case BytesValue:
-			cmp, _ := v1.Compare(v2)
-			return cmp
-		default:
-			panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", v2, v2))
-		}
-	default:
-		panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", v1, v1))
+
+	r1 := valueRank(v1)
+	r2 := valueRank(v2)
+	if r1 < r2 {
+		return -1
+	} else if r1 > r2 {
+		return 1
 	}
+	cmp, _ := v1.Compare(v2)
+	return cmp
 }
 
 func Format(v Value) string {
@@ -218,6 +213,54 @@ func Format(v Value) string {
 	return v.String()
 }
 
+// HashKey returns a key for a row of values, for use in a map: rows whose values are equal have
+// the same key, including numerics which differ only in scale, such as 1.0 and 1.00, and
+// intervals which span the same time, such as 1 mon and 30 days.
+func HashKey(vals []Value) string {
+	var buf strings.Builder
+	for _, val := range vals {
+		appendHashKey(&buf, val)
+	}
+	return buf.String()
+}
+
+func appendHashKey(buf *strings.Builder, val Value) {
+	var s string
+	switch val := val.(type) {
+	case nil:
+		buf.WriteString("-")
+		return
+	case ArrayValue:
+		fmt.Fprintf(buf, "[%d", len(val))
+		for _, elem := range val {
+			appendHashKey(buf, elem)
+		}
+		buf.WriteString("]")
+		return
+	case Float64Value:
+		if val == 0 {
+			val = 0 // -0 and 0 are equal.
+		}
+		s = val.String()
+	case IntervalValue:
+		days, us := val.Span()
+		s = fmt.Sprintf("%d %d", days, us)
+	case NumericValue:
+		if val.Sign() == 0 {
+			s = "0"
+		} else {
+			digits, exp := val.Digits()
+			s = fmt.Sprintf("%se%d", digits, exp)
+			if val.Sign() < 0 {
+				s = "-" + s
+			}
+		}
+	default:
+		s = val.String()
+	}
+	fmt.Fprintf(buf, "%d:%s", len(s), s)
+}
+
 func ConvertValue(dt DataType, v Value) (Value, error) {
 	switch dt {
 	case BooleanType:
@@ -244,6 +287,12 @@ func ConvertValue(dt DataType, v Value) (Value, error) {
 			}
 			return StringValue(b), nil
 		} else if _, ok := v.(StringValue); !ok {
+			switch v.(type) {
+			case DateValue, TimeValue, TimestampValue, TimestampTZValue, IntervalValue,
+				NumericValue, JSONValue, UUIDValue:
+
+				return StringValue(v.String()), nil
+			}
 			return nil, fmt.Errorf("expected a string value: %v", v)
 		}
 	case BytesType:
@@ -255,6 +304,8 @@ func ConvertValue(dt DataType, v Value) (Value, error) {
 	case FloatType:
 		if i, ok := v.(Int64Value); ok {
 			return Float64Value(i), nil
+		} else if n, ok := v.(NumericValue); ok {
+			return Float64Value(n.Float64()), nil
 		} else if s, ok := v.(StringValue); ok {
 			d, err := strconv.ParseFloat(strings.Trim(string(s), " \t\n"), 64)
 			if err != nil {
@@ -267,6 +318,12 @@ func ConvertValue(dt DataType, v Value) (Value, error) {
 	case IntegerType:
 		if f, ok := v.(Float64Value); ok {
 			return Int64Value(f), nil
+		} else if n, ok := v.(NumericValue); ok {
+			i, ok := n.Int64()
+			if !ok {
+				return nil, fmt.Errorf("integer out of range: %v", v)
+			}
+			return Int64Value(i), nil
 		} else if s, ok := v.(StringValue); ok {
 			i, err := strconv.ParseInt(strings.Trim(string(s), " \t\n"), 10, 64)
 			if err != nil {
@@ -276,6 +333,14 @@ func ConvertValue(dt DataType, v Value) (Value, error) {
 		} else if _, ok := v.(Int64Value); !ok {
 			return nil, fmt.Errorf("expected an integer value: %v", v)
 		}
+	case DateType, TimeType, TimestampType, TimestampTZType, IntervalType:
+		return ConvertTemporal(dt, v)
+	case NumericType:
+		return ConvertNumeric(v)
+	case JSONType, JSONBType:
+		return ConvertJSON(dt, v)
+	case UUIDType:
+		return ConvertUUID(v)
 	default:
 		panic(fmt.Sprintf("expected a valid data type; got %v", dt))
 	}
@@ -283,6 +348,46 @@ func ConvertValue(dt DataType, v Value) (Value, error) {
 	return v, nil
 }
 
+// ConvertColumnValue converts v to the data type of ct; numeric values are also fit to the
+// precision and scale of ct, strings must fit in the size of ct, and arrays are converted
+// element by element.
+func ConvertColumnValue(ct ColumnType, v Value) (Value, error) {
+	if ct.Array {
+		return ConvertArray(ct, v)
+	}
+
+	v, err := ConvertValue(ct.Type, v)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case NumericValue:
+		return v.Fit(int(ct.Size), int(ct.Scale))
+	case StringValue:
+		return fitString(ct, v)
+	}
+	return v, nil
+}
+
+// fitString returns s if it fits in the size of ct. Otherwise, it is an error unless only
+// spaces need to be truncated.
+func fitString(ct ColumnType, s StringValue) (Value, error) {
+	if ct.Size == 0 || ct.Size >= MaxColumnSize ||
+		uint32(utf8.RuneCountInString(string(s))) <= ct.Size {
+
+		return s, nil
+	}
+
+	if uint32(utf8.RuneCountInString(strings.TrimRight(string(s), " "))) > ct.Size {
+		typ := "VARCHAR"
+		if ct.Fixed {
+			typ = "CHAR"
+		}
+		return nil, fmt.Errorf("value too long for %s(%d): %s", typ, ct.Size, s)
+	}
+	return StringValue([]rune(string(s))[:ct.Size]), nil
+}
+
 /*
 database/sql package ==>
 Scan converts from columns to Go types:
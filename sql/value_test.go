@@ -1,6 +1,8 @@
 package sql_test
 
 import (
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/leftmike/maho/sql"
@@ -41,6 +43,24 @@ func TestCompare(t *testing.T) {
 		{sql.StringValue("def"), sql.StringValue("ghi"), -1},
 		{sql.StringValue("def"), sql.StringValue("def"), 0},
 		{sql.StringValue("def"), sql.StringValue("abc"), 1},
+
+		{sql.DateValue(10), sql.StringValue("abc"), 1},
+		{sql.DateValue(10), sql.DateValue(11), -1},
+		{sql.DateValue(1), sql.TimestampValue(sql.MicrosecondsPerDay), 0},
+		{sql.TimestampValue(1), sql.TimestampTZValue(2), -1},
+		{sql.TimestampTZValue(1), sql.TimeValue(0), -1},
+		{sql.TimeValue(20), sql.TimeValue(10), 1},
+		{sql.IntervalValue{Months: 1}, sql.IntervalValue{Days: 30}, 0},
+		{sql.IntervalValue{Days: 1}, sql.IntervalValue{Microseconds: sql.MicrosecondsPerDay + 1},
+			-1},
+
+		{sql.NumericFromInt64(123), sql.Int64Value(123), 0},
+		{sql.Int64Value(124), sql.NumericFromInt64(123), 1},
+		{sql.Float64Value(1.5), sql.MakeNumericValue(big.NewInt(150), 2), 0},
+		{sql.MakeNumericValue(big.NewInt(-1), 3), sql.Float64Value(0), -1},
+		{sql.MakeNumericValue(big.NewInt(150), 2), sql.MakeNumericValue(big.NewInt(15), 1), 0},
+		{sql.MakeNumericValue(big.NewInt(151), 2), sql.MakeNumericValue(big.NewInt(15), 1), 1},
+		{sql.NumericFromInt64(1), sql.StringValue("abc"), -1},
 	}
 
 	for _, c := range cases {
@@ -50,3 +70,35 @@ func TestCompare(t *testing.T) {
 		}
 	}
 }
+
+func TestHashKey(t *testing.T) {
+	cases := []struct {
+		vals1 []sql.Value
+		vals2 []sql.Value
+		same  bool
+	}{
+		{[]sql.Value{sql.MakeNumericValue(big.NewInt(10), 1)}, []sql.Value{sql.NumericFromInt64(1)},
+			true},
+		{[]sql.Value{sql.MakeNumericValue(big.NewInt(-100), 2)},
+			[]sql.Value{sql.MakeNumericValue(big.NewInt(-1), 0)}, true},
+		{[]sql.Value{sql.MakeNumericValue(big.NewInt(0), 3)}, []sql.Value{sql.NumericFromInt64(0)},
+			true},
+		{[]sql.Value{sql.MakeNumericValue(big.NewInt(15), 1)}, []sql.Value{sql.NumericFromInt64(15)},
+			false},
+		{[]sql.Value{sql.ArrayValue{sql.MakeNumericValue(big.NewInt(20), 1)}},
+			[]sql.Value{sql.ArrayValue{sql.NumericFromInt64(2)}}, true},
+		{[]sql.Value{sql.IntervalValue{Months: 1}}, []sql.Value{sql.IntervalValue{Days: 30}}, true},
+		{[]sql.Value{sql.Float64Value(math.Copysign(0, -1))}, []sql.Value{sql.Float64Value(0)},
+			true},
+		{[]sql.Value{nil}, []sql.Value{sql.StringValue("NULL")}, false},
+		{[]sql.Value{sql.StringValue("a"), sql.StringValue("bc")},
+			[]sql.Value{sql.StringValue("ab"), sql.StringValue("c")}, false},
+	}
+
+	for _, c := range cases {
+		same := sql.HashKey(c.vals1) == sql.HashKey(c.vals2)
+		if same != c.same {
+			t.Errorf("HashKey(%v) == HashKey(%v) got %v want %v", c.vals1, c.vals2, same, c.same)
+		}
+	}
+}
@@ -81,6 +81,27 @@ func (_ *basicStore) Table(ctx context.Context, tx engine.Transaction, tn sql.Ta
 	}, nil
 }
 
+func (_ *basicStore) DeleteTable(ctx context.Context, tx engine.Transaction, tid int64) error {
+	btx := tx.(*transaction)
+	btx.forWrite()
+
+	var items []btree.Item
+	btx.tree.AscendRange(rowItem{rid: tid << 16}, rowItem{rid: (tid + 1) << 16},
+		func(item btree.Item) bool {
+			items = append(items, item)
+			return true
+		})
+	for _, item := range items {
+		btx.tree.Delete(item)
+	}
+	return nil
+}
+
+// Serial is true: only one transaction runs at a time.
+func (bst *basicStore) Serial() bool {
+	return true
+}
+
 func (bst *basicStore) Begin(sesid uint64) engine.Transaction {
 	bst.mutex.Lock()
 	return &transaction{
@@ -167,7 +188,8 @@ func (bt *table) fetchRows(ctx context.Context, minRow, maxRow []sql.Value) [][]
 			if ri.rid != rid {
 				return false
 			}
-			rows = append(rows, append(make([]sql.Value, 0, len(ri.row)), ri.row...))
+			rows = append(rows,
+				bt.tl.FillRow(append(make([]sql.Value, 0, len(ri.row)), ri.row...)))
 			return true
 		})
 
@@ -413,7 +435,7 @@ func (bir *indexRows) getRow() []sql.Value {
 	}
 
 	ri := item.(rowItem)
-	return ri.row
+	return bir.tbl.tl.FillRow(ri.row)
 }
 
 func (bir *indexRows) Row(ctx context.Context) ([]sql.Value, error) {
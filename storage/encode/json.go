@@ -0,0 +1,142 @@
+package encode
+
+import (
+	"github.com/leftmike/maho/sql"
+	"github.com/leftmike/maho/util"
+)
+
+// encodeJSON encodes the kind of j followed by its value: numbers and strings are length
+// prefixed, and arrays and objects are prefixed with the number of elements. Since JSON values
+// are canonical, equal values always have the same encoding.
+func encodeJSON(buf []byte, j sql.JSONValue) []byte {
+	buf = append(buf, byte(j.Kind()))
+	switch j.Kind() {
+	case sql.JSONString:
+		buf = encodeJSONString(buf, j.Str())
+	case sql.JSONNumber:
+		buf = encodeJSONString(buf, j.Number().String())
+	case sql.JSONBoolean:
+		if j.Bool() {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+	case sql.JSONArray, sql.JSONObject:
+		keys := j.Keys()
+		elems := j.Elements()
+		buf = util.EncodeVarint(buf, uint64(len(elems)))
+		for i, e := range elems {
+			if j.Kind() == sql.JSONObject {
+				buf = encodeJSONString(buf, keys[i])
+			}
+			buf = encodeJSON(buf, e)
+		}
+	}
+	return buf
+}
+
+func encodeJSONString(buf []byte, s string) []byte {
+	buf = util.EncodeVarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func decodeJSONString(buf []byte) ([]byte, string, bool) {
+	buf, u, ok := util.DecodeVarint(buf)
+	if !ok || uint64(len(buf)) < u {
+		return nil, "", false
+	}
+	return buf[u:], string(buf[:u]), true
+}
+
+func decodeJSON(buf []byte) ([]byte, sql.JSONValue, bool) {
+	if len(buf) == 0 {
+		return nil, sql.JSONValue{}, false
+	}
+
+	kind := sql.JSONKind(buf[0])
+	buf = buf[1:]
+	switch kind {
+	case sql.JSONNull:
+		return buf, sql.JSONNullValue(), true
+	case sql.JSONString:
+		buf, s, ok := decodeJSONString(buf)
+		if !ok {
+			return nil, sql.JSONValue{}, false
+		}
+		return buf, sql.JSONStringValue(s), true
+	case sql.JSONNumber:
+		buf, s, ok := decodeJSONString(buf)
+		if !ok {
+			return nil, sql.JSONValue{}, false
+		}
+		n, err := sql.ParseNumeric(s)
+		if err != nil {
+			return nil, sql.JSONValue{}, false
+		}
+		return buf, sql.JSONNumberValue(n), true
+	case sql.JSONBoolean:
+		if len(buf) < 1 {
+			return nil, sql.JSONValue{}, false
+		}
+		return buf[1:], sql.JSONBoolValue(buf[0] != 0), true
+	case sql.JSONArray, sql.JSONObject:
+		buf, u, ok := util.DecodeVarint(buf)
+		if !ok || uint64(len(buf)) < u {
+			return nil, sql.JSONValue{}, false
+		}
+
+		var keys []string
+		elems := make([]sql.JSONValue, u)
+		for i := range elems {
+			if kind == sql.JSONObject {
+				var key string
+				buf, key, ok = decodeJSONString(buf)
+				if !ok {
+					return nil, sql.JSONValue{}, false
+				}
+				keys = append(keys, key)
+			}
+			buf, elems[i], ok = decodeJSON(buf)
+			if !ok {
+				return nil, sql.JSONValue{}, false
+			}
+		}
+
+		if kind == sql.JSONObject {
+			return buf, sql.JSONObjectValue(keys, elems), true
+		}
+		return buf, sql.JSONArrayValue(elems), true
+	}
+	return nil, sql.JSONValue{}, false
+}
+
+// encodeKeyJSON encodes j so that the encodings sort in the same order as the values: the kind
+// of j, followed by its value. Arrays and objects start with the number of elements, and object
+// keys are prefixed by their length.
+func encodeKeyJSON(buf []byte, j sql.JSONValue) []byte {
+	buf = append(buf, byte(j.Kind()))
+	switch j.Kind() {
+	case sql.JSONString:
+		buf = encodeKeyBytes(buf, []byte(j.Str()), false)
+	case sql.JSONNumber:
+		buf = encodeKeyNumericValue(buf, j.Number())
+	case sql.JSONBoolean:
+		if j.Bool() {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+	case sql.JSONArray, sql.JSONObject:
+		keys := j.Keys()
+		elems := j.Elements()
+		buf = encodeKeyInt64(buf, int64(len(elems)), false)
+		for i, e := range elems {
+			if j.Kind() == sql.JSONObject {
+				buf = encodeKeyInt64(buf, int64(len(keys[i])), false)
+				buf = append(buf, keys[i]...)
+			}
+			buf = encodeKeyJSON(buf, e)
+		}
+	}
+	return buf
+}
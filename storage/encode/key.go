@@ -22,11 +22,24 @@ const (
 	Float64NaNReverseKeyTag = 144
 	StringKeyTag            = 150
 	BytesKeyTag             = 160
+	DateKeyTag              = 170
+	TimeKeyTag              = 171
+	TimestampKeyTag         = 172
+	TimestampTZKeyTag       = 173
+	IntervalKeyTag          = 174
+	NumericNegKeyTag        = 180
+	NumericZeroKeyTag       = 181
+	NumericPosKeyTag        = 182
+	JSONKeyTag              = 190
+	UUIDKeyTag              = 200
+	ArrayKeyTag             = 210
 	MaxKeyTag               = 255
 )
 
 var (
 	MaxKey = []byte{MaxKeyTag}
+
+	arrayElementKey = []sql.ColumnKey{sql.MakeColumnKey(0, false)}
 )
 
 func encodeKeyBytes(buf []byte, bytes []byte, reverse bool) []byte {
@@ -48,6 +61,44 @@ func encodeKeyBytes(buf []byte, bytes []byte, reverse bool) []byte {
 	return buf
 }
 
+func encodeKeyInt64(buf []byte, n int64, reverse bool) []byte {
+	u := uint64(n) ^ (1 << 63)
+	if reverse {
+		u = ^u
+	}
+	return util.EncodeUint64(buf, u)
+}
+
+// encodeKeyNumeric encodes the exponent followed by the significant digits, each plus one, and
+// then a zero. Negative numbers are inverted so that larger magnitudes sort first.
+func encodeKeyNumeric(buf []byte, n sql.NumericValue) []byte {
+	digits, exp := n.Digits()
+	neg := n.Sign() < 0
+	buf = encodeKeyInt64(buf, int64(exp), neg)
+	for _, d := range []byte(digits) {
+		d = d - '0' + 1
+		if neg {
+			d = ^d
+		}
+		buf = append(buf, d)
+	}
+	if neg {
+		return append(buf, 0xFF)
+	}
+	return append(buf, 0)
+}
+
+func encodeKeyNumericValue(buf []byte, n sql.NumericValue) []byte {
+	if n.Sign() < 0 {
+		buf = append(buf, NumericNegKeyTag)
+	} else if n.Sign() == 0 {
+		return append(buf, NumericZeroKeyTag)
+	} else {
+		buf = append(buf, NumericPosKeyTag)
+	}
+	return encodeKeyNumeric(buf, n)
+}
+
 func MakeKey(key []sql.ColumnKey, row []sql.Value) []byte {
 	var buf []byte
 
@@ -104,6 +155,61 @@ func MakeKey(key []sql.ColumnKey, row []sql.Value) []byte {
 				buf = append(buf, Int64NotNegKeyTag)
 			}
 			buf = util.EncodeUint64(buf, uint64(val))
+		case sql.DateValue:
+			buf = append(buf, DateKeyTag)
+			buf = encodeKeyInt64(buf, int64(val), reverse)
+		case sql.TimeValue:
+			buf = append(buf, TimeKeyTag)
+			buf = encodeKeyInt64(buf, int64(val), reverse)
+		case sql.TimestampValue:
+			buf = append(buf, TimestampKeyTag)
+			buf = encodeKeyInt64(buf, int64(val), reverse)
+		case sql.TimestampTZValue:
+			buf = append(buf, TimestampTZKeyTag)
+			buf = encodeKeyInt64(buf, int64(val), reverse)
+		case sql.IntervalValue:
+			days, us := val.Span()
+			buf = append(buf, IntervalKeyTag)
+			buf = encodeKeyInt64(buf, days, reverse)
+			buf = encodeKeyInt64(buf, us, reverse)
+		case sql.NumericValue:
+			if reverse {
+				val = val.Negate()
+			}
+			buf = encodeKeyNumericValue(buf, val)
+		case sql.JSONValue:
+			buf = append(buf, JSONKeyTag)
+			n := len(buf)
+			buf = encodeKeyJSON(buf, val)
+			if reverse {
+				for n < len(buf) {
+					buf[n] = ^buf[n]
+					n += 1
+				}
+			}
+		case sql.UUIDValue:
+			if reverse {
+				for i := range val {
+					val[i] = ^val[i]
+				}
+			}
+			buf = append(buf, UUIDKeyTag)
+			buf = append(buf, val[:]...)
+		case sql.ArrayValue:
+			// The elements are encoded as keys followed by a zero, which is less than any key
+			// tag, so that an array sorts before the longer arrays that it is a prefix of.
+			buf = append(buf, ArrayKeyTag)
+			n := len(buf)
+			for _, e := range val {
+				buf = append(buf, MakeKey(arrayElementKey, []sql.Value{e})...)
+			}
+			buf = append(buf, 0)
+			if reverse {
+				for n < len(buf) {
+					buf[n] = ^buf[n]
+					n += 1
+				}
+			}
 		default:
 			if val == nil {
 				buf = append(buf, NullKeyTag)
@@ -9,6 +9,38 @@ import (
 	"github.com/leftmike/maho/storage/encode"
 )
 
+func numeric(s string) sql.NumericValue {
+	n, err := sql.ParseNumeric(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func jsonText(s string) sql.JSONValue {
+	j, err := sql.ParseJSONText(s)
+	if err != nil {
+		panic(err)
+	}
+	return j
+}
+
+func jsonValue(s string) sql.JSONValue {
+	j, err := sql.ParseJSON(s)
+	if err != nil {
+		panic(err)
+	}
+	return j
+}
+
+func uuidValue(s string) sql.UUIDValue {
+	u, err := sql.ParseUUID(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
 func testMakeKey(t *testing.T, key []sql.ColumnKey, values []sql.Value,
 	makeRow func(val sql.Value) []sql.Value) {
 
@@ -58,6 +90,66 @@ func TestMakeKey(t *testing.T) {
 		sql.BytesValue([]byte{254, 0, 0}),
 		sql.BytesValue([]byte{254, 255}),
 		sql.BytesValue([]byte{255}),
+		sql.DateValue(-999),
+		sql.DateValue(0),
+		sql.DateValue(999),
+		sql.TimeValue(0),
+		sql.TimeValue(999),
+		sql.TimestampValue(-999),
+		sql.TimestampValue(999),
+		sql.TimestampTZValue(-999),
+		sql.TimestampTZValue(999),
+		sql.IntervalValue{Days: -1},
+		sql.IntervalValue{Microseconds: 999},
+		sql.IntervalValue{Days: 29, Microseconds: 999},
+		sql.IntervalValue{Months: 1, Microseconds: 999},
+		numeric("-100"),
+		numeric("-9.5"),
+		numeric("-9.499"),
+		numeric("-0.001"),
+		numeric("0"),
+		numeric("0.01"),
+		numeric("0.1"),
+		numeric("9.999"),
+		numeric("10.5"),
+		numeric("100"),
+		numeric("12345678901234567890"),
+		jsonValue("null"),
+		jsonValue(`""`),
+		jsonValue(`"a"`),
+		jsonValue(`"ab"`),
+		jsonValue(`"b"`),
+		jsonValue("-1.5"),
+		jsonValue("0"),
+		jsonValue("2"),
+		jsonValue("false"),
+		jsonValue("true"),
+		jsonValue("[]"),
+		jsonValue("[2]"),
+		jsonValue(`["a", 1]`),
+		jsonValue("[1, 2]"),
+		jsonValue("{}"),
+		jsonValue(`{"b": 1}`),
+		jsonValue(`{"b": 2}`),
+		jsonValue(`{"aa": 1}`),
+		jsonValue(`{"a": 1, "b": 2}`),
+		uuidValue("00000000-0000-0000-0000-000000000000"),
+		uuidValue("00000000-0000-0000-0000-000000000001"),
+		uuidValue("0f000000-0000-0000-0000-000000000000"),
+		uuidValue("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"),
+		uuidValue("ffffffff-ffff-ffff-ffff-ffffffffffff"),
+		sql.ArrayValue{},
+		sql.ArrayValue{nil},
+		sql.ArrayValue{nil, sql.Int64Value(1)},
+		sql.ArrayValue{sql.Int64Value(-1)},
+		sql.ArrayValue{sql.Int64Value(1)},
+		sql.ArrayValue{sql.Int64Value(1), nil},
+		sql.ArrayValue{sql.Int64Value(1), sql.Int64Value(2)},
+		sql.ArrayValue{sql.Int64Value(2)},
+		sql.ArrayValue{sql.StringValue("")},
+		sql.ArrayValue{sql.StringValue("a")},
+		sql.ArrayValue{sql.StringValue("a"), sql.StringValue("")},
+		sql.ArrayValue{sql.StringValue("ab")},
 	}
 
 	reverseValues := []sql.Value{
@@ -94,6 +186,65 @@ func TestMakeKey(t *testing.T) {
 		sql.BytesValue([]byte{0, 0, 0}),
 		sql.BytesValue([]byte{0, 0}),
 		sql.BytesValue([]byte{0}),
+		sql.DateValue(999),
+		sql.DateValue(0),
+		sql.DateValue(-999),
+		sql.TimeValue(999),
+		sql.TimeValue(0),
+		sql.TimestampValue(999),
+		sql.TimestampValue(-999),
+		sql.TimestampTZValue(999),
+		sql.TimestampTZValue(-999),
+		sql.IntervalValue{Months: 1, Microseconds: 999},
+		sql.IntervalValue{Days: 29, Microseconds: 999},
+		sql.IntervalValue{Microseconds: 999},
+		sql.IntervalValue{Days: -1},
+		numeric("100"),
+		numeric("10.5"),
+		numeric("9.999"),
+		numeric("0.1"),
+		numeric("0.01"),
+		numeric("0"),
+		numeric("-0.001"),
+		numeric("-9.499"),
+		numeric("-9.5"),
+		numeric("-100"),
+		jsonValue(`{"a": 1, "b": 2}`),
+		jsonValue(`{"aa": 1}`),
+		jsonValue(`{"b": 2}`),
+		jsonValue(`{"b": 1}`),
+		jsonValue("{}"),
+		jsonValue("[1, 2]"),
+		jsonValue(`["a", 1]`),
+		jsonValue("[2]"),
+		jsonValue("[]"),
+		jsonValue("true"),
+		jsonValue("false"),
+		jsonValue("2"),
+		jsonValue("0"),
+		jsonValue("-1.5"),
+		jsonValue(`"b"`),
+		jsonValue(`"ab"`),
+		jsonValue(`"a"`),
+		jsonValue(`""`),
+		jsonValue("null"),
+		uuidValue("ffffffff-ffff-ffff-ffff-ffffffffffff"),
+		uuidValue("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"),
+		uuidValue("0f000000-0000-0000-0000-000000000000"),
+		uuidValue("00000000-0000-0000-0000-000000000001"),
+		uuidValue("00000000-0000-0000-0000-000000000000"),
+		sql.ArrayValue{sql.StringValue("ab")},
+		sql.ArrayValue{sql.StringValue("a"), sql.StringValue("")},
+		sql.ArrayValue{sql.StringValue("a")},
+		sql.ArrayValue{sql.StringValue("")},
+		sql.ArrayValue{sql.Int64Value(2)},
+		sql.ArrayValue{sql.Int64Value(1), sql.Int64Value(2)},
+		sql.ArrayValue{sql.Int64Value(1), nil},
+		sql.ArrayValue{sql.Int64Value(1)},
+		sql.ArrayValue{sql.Int64Value(-1)},
+		sql.ArrayValue{nil, sql.Int64Value(1)},
+		sql.ArrayValue{nil},
+		sql.ArrayValue{},
 	}
 
 	testMakeKey(t, []sql.ColumnKey{sql.MakeColumnKey(0, false)}, values,
@@ -10,11 +10,21 @@ import (
 )
 
 const (
-	boolValueTag    = 1
-	int64ValueTag   = 2
-	float64ValueTag = 3
-	stringValueTag  = 4
-	bytesValueTag   = 5
+	boolValueTag        = 1
+	int64ValueTag       = 2
+	float64ValueTag     = 3
+	stringValueTag      = 4
+	bytesValueTag       = 5
+	dateValueTag        = 6
+	timeValueTag        = 7
+	timestampValueTag   = 8
+	timestampTZValueTag = 9
+	intervalValueTag    = 10
+	numericValueTag     = 11
+	jsonValueTag        = 12
+	uuidValueTag        = 13
+	arrayValueTag       = 14
+	jsonTextValueTag    = 15
 	// Value tags must be less than 16.
 )
 
@@ -65,6 +75,53 @@ func EncodeRowValue(row []sql.Value) []byte {
 		case sql.Int64Value:
 			buf = encodeColNumValueTag(buf, num, int64ValueTag)
 			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.DateValue:
+			buf = encodeColNumValueTag(buf, num, dateValueTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.TimeValue:
+			buf = encodeColNumValueTag(buf, num, timeValueTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.TimestampValue:
+			buf = encodeColNumValueTag(buf, num, timestampValueTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.TimestampTZValue:
+			buf = encodeColNumValueTag(buf, num, timestampTZValueTag)
+			buf = util.EncodeZigzag64(buf, int64(val))
+		case sql.IntervalValue:
+			buf = encodeColNumValueTag(buf, num, intervalValueTag)
+			buf = util.EncodeZigzag64(buf, val.Months)
+			buf = util.EncodeZigzag64(buf, val.Days)
+			buf = util.EncodeZigzag64(buf, val.Microseconds)
+		case sql.NumericValue:
+			b := []byte(val.String())
+			buf = encodeColNumValueTag(buf, num, numericValueTag)
+			buf = util.EncodeVarint(buf, uint64(len(b)))
+			buf = append(buf, b...)
+		case sql.JSONValue:
+			if s, ok := val.Text(); ok {
+				// json, rather than jsonb, is encoded as the original text.
+				b := []byte(s)
+				buf = encodeColNumValueTag(buf, num, jsonTextValueTag)
+				buf = util.EncodeVarint(buf, uint64(len(b)))
+				buf = append(buf, b...)
+			} else {
+				b := encodeJSON(nil, val)
+				buf = encodeColNumValueTag(buf, num, jsonValueTag)
+				buf = util.EncodeVarint(buf, uint64(len(b)))
+				buf = append(buf, b...)
+			}
+		case sql.UUIDValue:
+			buf = encodeColNumValueTag(buf, num, uuidValueTag)
+			buf = append(buf, val[:]...)
+		case sql.ArrayValue:
+			// The elements are encoded as a row, except that an empty array has no elements.
+			var b []byte
+			if len(val) > 0 {
+				b = EncodeRowValue(val)
+			}
+			buf = encodeColNumValueTag(buf, num, arrayValueTag)
+			buf = util.EncodeVarint(buf, uint64(len(b)))
+			buf = append(buf, b...)
 		default:
 			panic(fmt.Sprintf("unexpected type for sql.Value: %T: %v", val, val))
 		}
@@ -140,6 +197,105 @@ func DecodeRowValue(buf []byte) []sql.Value {
 				return nil
 			}
 			val = sql.Int64Value(n)
+		case dateValueTag, timeValueTag, timestampValueTag, timestampTZValueTag:
+			var n int64
+			buf, n, ok = util.DecodeZigzag64(buf)
+			if !ok {
+				return nil
+			}
+			switch tag {
+			case dateValueTag:
+				val = sql.DateValue(n)
+			case timeValueTag:
+				val = sql.TimeValue(n)
+			case timestampValueTag:
+				val = sql.TimestampValue(n)
+			case timestampTZValueTag:
+				val = sql.TimestampTZValue(n)
+			}
+		case intervalValueTag:
+			var iv sql.IntervalValue
+			buf, iv.Months, ok = util.DecodeZigzag64(buf)
+			if !ok {
+				return nil
+			}
+			buf, iv.Days, ok = util.DecodeZigzag64(buf)
+			if !ok {
+				return nil
+			}
+			buf, iv.Microseconds, ok = util.DecodeZigzag64(buf)
+			if !ok {
+				return nil
+			}
+			val = iv
+		case numericValueTag:
+			buf, u, ok = util.DecodeVarint(buf)
+			if !ok {
+				return nil
+			}
+			if len(buf) < int(u) {
+				return nil
+			}
+			n, err := sql.ParseNumeric(string(buf[:u]))
+			if err != nil {
+				return nil
+			}
+			val = n
+			buf = buf[u:]
+		case jsonValueTag:
+			buf, u, ok = util.DecodeVarint(buf)
+			if !ok {
+				return nil
+			}
+			if len(buf) < int(u) {
+				return nil
+			}
+			b, j, ok := decodeJSON(buf[:u])
+			if !ok || len(b) != 0 {
+				return nil
+			}
+			val = j
+			buf = buf[u:]
+		case jsonTextValueTag:
+			buf, u, ok = util.DecodeVarint(buf)
+			if !ok {
+				return nil
+			}
+			if len(buf) < int(u) {
+				return nil
+			}
+			j, err := sql.ParseJSONText(string(buf[:u]))
+			if err != nil {
+				return nil
+			}
+			val = j
+			buf = buf[u:]
+		case uuidValueTag:
+			if len(buf) < 16 {
+				return nil
+			}
+			var uv sql.UUIDValue
+			copy(uv[:], buf)
+			val = uv
+			buf = buf[16:]
+		case arrayValueTag:
+			buf, u, ok = util.DecodeVarint(buf)
+			if !ok {
+				return nil
+			}
+			if len(buf) < int(u) {
+				return nil
+			}
+			if u == 0 {
+				val = sql.ArrayValue{}
+			} else {
+				a := DecodeRowValue(buf[:u])
+				if a == nil {
+					return nil
+				}
+				val = sql.ArrayValue(a)
+			}
+			buf = buf[u:]
 		default:
 			return nil
 		}
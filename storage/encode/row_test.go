@@ -51,6 +51,40 @@ func TestRowValues(t *testing.T) {
 				sql.BytesValue{0xFF, 1, 2, 3}},
 			s: `123, '', '\xff010203'`,
 		},
+		{
+			row: []sql.Value{sql.DateValue(-1), sql.TimeValue(3723000004),
+				sql.TimestampValue(86400000001), sql.TimestampTZValue(-1)},
+			s: "1969-12-31, 01:02:03.000004, 1970-01-02 00:00:00.000001, " +
+				"1969-12-31 23:59:59.999999+00",
+		},
+		{
+			row: []sql.Value{nil, sql.IntervalValue{Months: 14, Days: -3, Microseconds: 5000000}},
+			s:   "NULL, 1 year 2 mons -3 days +00:00:05",
+		},
+		{
+			row: []sql.Value{numeric("-12.500"), nil, numeric("0.001")},
+			s:   "-12.500, NULL, 0.001",
+		},
+		{
+			row: []sql.Value{jsonValue(`{"b": [1, "x", null], "a": {"c": true}}`),
+				jsonValue("null"), jsonValue(`"s"`)},
+			s: `{"a": {"c": true}, "b": [1, "x", null]}, null, "s"`,
+		},
+		{
+			row: []sql.Value{jsonText(`{"b":1, "a":2, "b":3}`), jsonText(" [ ] ")},
+			s:   `{"b":1, "a":2, "b":3},  [ ] `,
+		},
+		{
+			row: []sql.Value{uuidValue("A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11"), nil,
+				uuidValue("00000000-0000-0000-0000-000000000000")},
+			s: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11, NULL, 00000000-0000-0000-0000-000000000000",
+		},
+		{
+			row: []sql.Value{sql.ArrayValue{sql.Int64Value(1), nil, sql.Int64Value(3), nil},
+				sql.ArrayValue{}, sql.ArrayValue{nil},
+				sql.ArrayValue{sql.StringValue("a b"), numeric("1.50"), sql.BoolValue(true)}},
+			s: `{1,NULL,3,NULL}, {}, {NULL}, {"a b",1.50,true}`,
+		},
 		{
 			row: []sql.Value{
 				sql.StringValue("19064"),
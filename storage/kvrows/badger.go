@@ -92,6 +92,30 @@ func (bkv *badgerKV) Updater() (Updater, error) {
 	}, nil
 }
 
+func (bkv *badgerKV) DeleteRange(minKey, maxKey []byte) error {
+	tx := bkv.db.NewTransaction(false)
+	defer tx.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := tx.NewIterator(opts)
+	defer it.Close()
+
+	wb := bkv.db.NewWriteBatch()
+	for it.Seek(minKey); it.Valid(); it.Next() {
+		key := it.Item().KeyCopy(nil)
+		if bytes.Compare(key, maxKey) >= 0 {
+			break
+		}
+		err := wb.Delete(key)
+		if err != nil {
+			wb.Cancel()
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
 func (bu badgerUpdater) Update(key []byte, fn func(val []byte) ([]byte, error)) error {
 	item, err := bu.tx.Get(key)
 
@@ -18,6 +18,7 @@ const (
 	updateCmd
 	commitCmd
 	rollbackCmd
+	deleteRangeCmd
 )
 
 type keyVal struct {
@@ -30,6 +31,7 @@ type kvCmd struct {
 	cmd     int
 	fail    bool
 	key     string
+	maxKey  string
 	oldVal  string
 	newVal  string
 	keyVals []keyVal
@@ -137,6 +139,12 @@ func runKVTest(t *testing.T, kv kvrows.KV, cmds []kvCmd) {
 			updater.Rollback()
 			updater = nil
 
+		case deleteRangeCmd:
+			err := kv.DeleteRange([]byte(cmd.key), []byte(cmd.maxKey))
+			if err != nil {
+				t.Errorf("%sDeleteRange() failed with %s", cmd.fln, err)
+			}
+
 		default:
 			panic(fmt.Sprintf("unexpected command: %d", cmd.cmd))
 		}
@@ -189,6 +197,18 @@ func testKV(t *testing.T, kv kvrows.KV) {
 					{"Addd", "ddd@3"},
 				},
 			},
+
+			{fln: fln(), cmd: deleteRangeCmd, key: "Ab", maxKey: "Addd"},
+			{fln: fln(), cmd: iterateCmd, key: "A",
+				keyVals: []keyVal{
+					{"Aaaa", "aaa@2"},
+					{"Addd", "ddd@3"},
+				},
+			},
+
+			{fln: fln(), cmd: deleteRangeCmd, key: "B", maxKey: "C"},
+			{fln: fln(), cmd: deleteRangeCmd, key: "A", maxKey: "B"},
+			{fln: fln(), cmd: iterateCmd, key: "A"},
 		})
 }
 
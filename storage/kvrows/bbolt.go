@@ -121,6 +121,29 @@ func (bkv bboltKV) Updater() (Updater, error) {
 	}, nil
 }
 
+func (bkv bboltKV) DeleteRange(minKey, maxKey []byte) error {
+	tx, bkt, err := bkv.begin(true)
+	if err != nil {
+		return err
+	}
+
+	var keys [][]byte
+	cr := bkt.Cursor()
+	key, _ := cr.Seek(minKey)
+	for key != nil && bytes.Compare(key, maxKey) < 0 {
+		keys = append(keys, append([]byte(nil), key...))
+		key, _ = cr.Next()
+	}
+	for _, key := range keys {
+		err = bkt.Delete(key)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (bu bboltUpdater) Update(key []byte, fn func(val []byte) ([]byte, error)) error {
 	val, err := fn(bu.bkt.Get(key))
 	if err != nil {
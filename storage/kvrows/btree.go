@@ -88,6 +88,30 @@ func (bkv *btreeKV) Updater() (Updater, error) {
 	}, nil
 }
 
+func (bkv *btreeKV) DeleteRange(minKey, maxKey []byte) error {
+	bkv.updateMutex.Lock()
+	defer bkv.updateMutex.Unlock()
+
+	bkv.treeMutex.Lock()
+	tree := bkv.tree.Clone()
+	bkv.treeMutex.Unlock()
+
+	var items []btree.Item
+	tree.AscendRange(btreeItem{key: minKey}, btreeItem{key: maxKey},
+		func(item btree.Item) bool {
+			items = append(items, item)
+			return true
+		})
+	for _, item := range items {
+		tree.Delete(item)
+	}
+
+	bkv.treeMutex.Lock()
+	bkv.tree = tree
+	bkv.treeMutex.Unlock()
+	return nil
+}
+
 func (bu btreeUpdater) Update(key []byte, fn func(val []byte) ([]byte, error)) error {
 	item := bu.tree.Get(btreeItem{key: key})
 
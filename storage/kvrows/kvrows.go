@@ -47,6 +47,9 @@ type Iterator interface {
 type KV interface {
 	Iterate(minKey, maxKey []byte) (Iterator, error)
 	Updater() (Updater, error)
+	// DeleteRange deletes all keys from minKey up to, but not including, maxKey. It is not
+	// part of any Updater.
+	DeleteRange(minKey, maxKey []byte) error
 }
 
 type kvStore struct {
@@ -57,6 +60,20 @@ type kvStore struct {
 	ver          uint64
 	epoch        uint64
 	commitMutex  sync.Mutex
+
+	// Versions of the active transactions, by txid.
+	activeVers map[uint64]uint64
+	// Version of the most recent committed transaction which updated a table, by tid.
+	updatedTIDs map[int64]uint64
+	// Version of the committed transaction which deleted a table, by tid; the table's keys
+	// have not yet been removed.
+	deletedTIDs    map[int64]uint64
+	pendingDeletes []pendingDelete
+}
+
+type pendingDelete struct {
+	tids []int64
+	ver  uint64
 }
 
 type transaction struct {
@@ -66,6 +83,8 @@ type transaction struct {
 	txid        uint64
 	sid         uint32
 	updatedKeys [][]byte
+	updatedTIDs map[int64]struct{}
+	deletedTIDs []int64
 }
 
 type table struct {
@@ -226,6 +245,9 @@ func makeStore(kv KV) (*kvStore, bool, error) {
 		kv:           kv,
 		transactions: transactions,
 		epoch:        epoch,
+		activeVers:   map[uint64]uint64{},
+		updatedTIDs:  map[int64]uint64{},
+		deletedTIDs:  map[int64]uint64{},
 	}
 
 	err = kvst.startupStore()
@@ -271,6 +293,55 @@ func (kvst *kvStore) Table(ctx context.Context, tx engine.Transaction, tn sql.Ta
 	}, nil
 }
 
+func (kvst *kvStore) DeleteTable(ctx context.Context, tx engine.Transaction, tid int64) error {
+	etx := tx.(*transaction)
+	etx.deletedTIDs = append(etx.deletedTIDs, tid)
+	return nil
+}
+
+// deleteTables removes the keys of tables which are no longer visible to any transactions. The
+// keys of a deleted table are kept until every transaction which started before the delete was
+// committed has completed, so older transactions continue to see the rows of the table.
+// XXX: pending deletes are lost if the store is restarted before they run.
+func (kvst *kvStore) deleteTables() {
+	kvst.mutex.Lock()
+	minVer := kvst.ver
+	for _, ver := range kvst.activeVers {
+		if ver < minVer {
+			minVer = ver
+		}
+	}
+
+	var tids []int64
+	var pending []pendingDelete
+	for _, pd := range kvst.pendingDeletes {
+		if pd.ver <= minVer {
+			tids = append(tids, pd.tids...)
+		} else {
+			pending = append(pending, pd)
+		}
+	}
+	kvst.pendingDeletes = pending
+	kvst.mutex.Unlock()
+
+	for _, tid := range tids {
+		err := kvst.kv.DeleteRange(util.EncodeUint64(make([]byte, 0, 8), uint64(tid<<16)),
+			util.EncodeUint64(make([]byte, 0, 8), uint64((tid+1)<<16)))
+		if err != nil {
+			log.WithField("tid", tid).Errorf("kvrows: unable to delete table: %s", err)
+		}
+	}
+
+	if len(tids) > 0 {
+		kvst.commitMutex.Lock()
+		for _, tid := range tids {
+			delete(kvst.deletedTIDs, tid)
+			delete(kvst.updatedTIDs, tid)
+		}
+		kvst.commitMutex.Unlock()
+	}
+}
+
 func (kvst *kvStore) setTransactionData(txid uint64, td *TransactionData) error {
 	upd, err := kvst.kv.Updater()
 	if err != nil {
@@ -301,6 +372,7 @@ func (kvst *kvStore) Begin(sesid uint64) engine.Transaction {
 		Epoch: kvst.epoch,
 	}
 	kvst.transactions[txid] = td
+	kvst.activeVers[txid] = ver
 	kvst.mutex.Unlock()
 
 	err := kvst.setTransactionData(txid, td)
@@ -309,11 +381,12 @@ func (kvst *kvStore) Begin(sesid uint64) engine.Transaction {
 	}
 
 	return &transaction{
-		st:    kvst,
-		sesid: sesid,
-		txid:  txid,
-		ver:   ver,
-		sid:   1,
+		st:          kvst,
+		sesid:       sesid,
+		txid:        txid,
+		ver:         ver,
+		sid:         1,
+		updatedTIDs: map[int64]struct{}{},
 	}
 }
 
@@ -325,9 +398,26 @@ func (kvst *kvStore) getTxState(txid uint64) (TransactionState, uint64) {
 	return txd.State, txd.Version
 }
 
-func (kvst *kvStore) commit(ctx context.Context, txid uint64) error {
+// commit fails if the transaction updated a table which was deleted by a newer transaction, or
+// if it deleted a table which was updated by a newer transaction: otherwise, the updates would
+// be lost when the keys of the table are removed.
+func (kvst *kvStore) commit(ctx context.Context, kvtx *transaction) error {
 	kvst.commitMutex.Lock()
-	defer kvst.commitMutex.Unlock()
+
+	for tid := range kvtx.updatedTIDs {
+		if _, ok := kvst.deletedTIDs[tid]; ok {
+			kvst.commitMutex.Unlock()
+			kvst.rollback(kvtx.txid)
+			return fmt.Errorf("kvrows: conflict with newer delete of table %d", tid)
+		}
+	}
+	for _, tid := range kvtx.deletedTIDs {
+		if kvst.updatedTIDs[tid] > kvtx.ver {
+			kvst.commitMutex.Unlock()
+			kvst.rollback(kvtx.txid)
+			return fmt.Errorf("kvrows: conflict with newer update of table %d", tid)
+		}
+	}
 
 	ver := kvst.ver + 1
 	td := &TransactionData{
@@ -336,26 +426,47 @@ func (kvst *kvStore) commit(ctx context.Context, txid uint64) error {
 		Version: ver,
 	}
 
-	err := kvst.setTransactionData(txid, td)
+	err := kvst.setTransactionData(kvtx.txid, td)
 	if err != nil {
-		return kvst.rollback(txid)
+		kvst.commitMutex.Unlock()
+		return kvst.rollback(kvtx.txid)
+	}
+
+	for tid := range kvtx.updatedTIDs {
+		kvst.updatedTIDs[tid] = ver
+	}
+	for _, tid := range kvtx.deletedTIDs {
+		kvst.deletedTIDs[tid] = ver
 	}
 
 	kvst.mutex.Lock()
-	kvst.transactions[txid] = td
+	kvst.transactions[kvtx.txid] = td
 	kvst.ver = ver
+	delete(kvst.activeVers, kvtx.txid)
+	if len(kvtx.deletedTIDs) > 0 {
+		kvst.pendingDeletes = append(kvst.pendingDeletes,
+			pendingDelete{
+				tids: kvtx.deletedTIDs,
+				ver:  ver,
+			})
+	}
 	kvst.mutex.Unlock()
+	kvst.commitMutex.Unlock()
 
-	return err
+	kvst.deleteTables()
+	return nil
 }
 
 func (kvst *kvStore) rollback(txid uint64) error {
 	kvst.mutex.Lock()
 	td := kvst.transactions[txid]
 	td.State = TransactionState_Aborted
+	delete(kvst.activeVers, txid)
 	kvst.mutex.Unlock()
 
-	return kvst.setTransactionData(txid, td)
+	err := kvst.setTransactionData(txid, td)
+	kvst.deleteTables()
+	return err
 }
 
 func (kvtx *transaction) Commit(ctx context.Context) error {
@@ -363,7 +474,7 @@ func (kvtx *transaction) Commit(ctx context.Context) error {
 		return errTransactionComplete
 	}
 
-	err := kvtx.st.commit(ctx, kvtx.txid)
+	err := kvtx.st.commit(ctx, kvtx)
 	kvtx.st = nil
 	// XXX: cleanup proposals
 	return err
@@ -502,6 +613,20 @@ func (kvt *table) fetchRows(ctx context.Context, minKey, maxKey []byte) ([][]sql
 	return vals, nil
 }
 
+func (kvt *table) fetchPrimaryRows(ctx context.Context, minKey,
+	maxKey []byte) ([][]sql.Value, error) {
+
+	vals, err := kvt.fetchRows(ctx, minKey, maxKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for vdx := range vals {
+		vals[vdx] = kvt.tl.FillRow(vals[vdx])
+	}
+	return vals, nil
+}
+
 func (kvt *table) Rows(ctx context.Context, minRow, maxRow []sql.Value) (engine.Rows, error) {
 	minKey := kvt.makePrimaryKey(minRow)
 	var maxKey []byte
@@ -509,7 +634,7 @@ func (kvt *table) Rows(ctx context.Context, minRow, maxRow []sql.Value) (engine.
 		maxKey = kvt.makePrimaryKey(maxRow)
 	}
 
-	vals, err := kvt.fetchRows(ctx, minKey, maxKey)
+	vals, err := kvt.fetchPrimaryRows(ctx, minKey, maxKey)
 	if err != nil {
 		return nil, err
 	}
@@ -635,6 +760,7 @@ func (kvt *table) proposeUpdate(upd Updater, updateKey []byte, row []sql.Value,
 			}
 
 			kvt.tx.updatedKeys = append(kvt.tx.updatedKeys, updateKey)
+			kvt.tx.updatedTIDs[kvt.tid] = struct{}{}
 
 			var rowValue []byte
 			if len(row) > 0 {
@@ -722,7 +848,7 @@ func (kvt *table) FillIndex(ctx context.Context, iidx int) error {
 	}
 	il := indexes[iidx]
 
-	rows, err := kvt.fetchRows(ctx, kvt.makePrimaryKey(nil), nil)
+	rows, err := kvt.fetchPrimaryRows(ctx, kvt.makePrimaryKey(nil), nil)
 	if err != nil {
 		return err
 	}
@@ -926,7 +1052,7 @@ func (kvir *indexRows) getRow(ctx context.Context) ([]sql.Value, error) {
 	kvir.il.IndexRowToRow(kvir.rows[kvir.idx-1], row)
 	key := kvir.tbl.makePrimaryKey(row)
 
-	vals, err := kvir.tbl.fetchRows(ctx, key, key)
+	vals, err := kvir.tbl.fetchPrimaryRows(ctx, key, key)
 	if err != nil {
 		return nil, err
 	}
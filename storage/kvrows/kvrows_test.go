@@ -1,9 +1,13 @@
 package kvrows_test
 
 import (
+	"context"
+	"io"
 	"path/filepath"
 	"testing"
 
+	"github.com/leftmike/maho/engine"
+	"github.com/leftmike/maho/sql"
 	"github.com/leftmike/maho/storage"
 	"github.com/leftmike/maho/storage/kvrows"
 	"github.com/leftmike/maho/storage/test"
@@ -185,3 +189,143 @@ func TestBTreeKVRows(t *testing.T) {
 	test.RunStressTest(t, st)
 	test.RunParallelTest(t, st)
 }
+
+func countRows(t *testing.T, st *storage.Store, tx engine.Transaction, tn sql.TableName) int {
+	t.Helper()
+
+	ctx := context.Background()
+	tbl, _, err := st.LookupTable(ctx, tx, tn)
+	if err != nil {
+		t.Fatalf("LookupTable(%s) failed with %s", tn, err)
+	}
+	rows, err := tbl.Rows(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("table.Rows() failed with %s", err)
+	}
+	defer rows.Close()
+
+	var cnt int
+	for {
+		_, err := rows.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("rows.Next() failed with %s", err)
+		}
+		cnt += 1
+	}
+	return cnt
+}
+
+func insertRow(t *testing.T, st *storage.Store, tx engine.Transaction, tn sql.TableName,
+	id int) {
+
+	t.Helper()
+
+	ctx := context.Background()
+	tbl, _, err := st.LookupTable(ctx, tx, tn)
+	if err != nil {
+		t.Fatalf("LookupTable(%s) failed with %s", tn, err)
+	}
+	err = tbl.Insert(ctx, [][]sql.Value{{sql.Int64Value(id)}})
+	if err != nil {
+		t.Fatalf("table.Insert() failed with %s", err)
+	}
+	tx.NextStmt()
+}
+
+// TestTruncateTable checks the semantics of TRUNCATE (and of other statements which delete
+// tables) with concurrent transactions. Transactions which started before the truncate was
+// committed continue to see the rows of the table. A transaction which updates the table fails
+// to commit if a newer transaction truncated it, and a transaction which truncates the table
+// fails to commit if a newer transaction updated it.
+func TestTruncateTable(t *testing.T) {
+	st, err := kvrows.NewBTreeStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	dbname := sql.ID("truncate_test")
+	err = st.CreateDatabase(dbname, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn := sql.TableName{dbname, sql.PUBLIC, sql.ID("tbl")}
+
+	tx := st.Begin(0)
+	err = st.CreateTable(ctx, tx, tn,
+		engine.MakeTableType([]sql.Identifier{sql.ID("id")},
+			[]sql.ColumnType{{Type: sql.IntegerType, Size: 8, NotNull: true}},
+			[]sql.ColumnDefault{{}}, []sql.ColumnKey{sql.MakeColumnKey(0, false)}),
+		false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.NextStmt()
+	for id := 1; id <= 3; id++ {
+		insertRow(t, st, tx, tn, id)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldTx := st.Begin(1)
+	if cnt := countRows(t, st, oldTx, tn); cnt != 3 {
+		t.Errorf("countRows() got %d want 3", cnt)
+	}
+
+	tx = st.Begin(2)
+	err = st.TruncateTable(ctx, tx, tn)
+	if err != nil {
+		t.Fatalf("TruncateTable(%s) failed with %s", tn, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx = st.Begin(2)
+	if cnt := countRows(t, st, tx, tn); cnt != 0 {
+		t.Errorf("countRows() after truncate got %d want 0", cnt)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cnt := countRows(t, st, oldTx, tn); cnt != 3 {
+		t.Errorf("countRows() in older transaction got %d want 3", cnt)
+	}
+	insertRow(t, st, oldTx, tn, 4)
+	err = oldTx.Commit(ctx)
+	if err == nil {
+		t.Error("Commit() of update of truncated table did not fail")
+	}
+
+	truncTx := st.Begin(1)
+	tx = st.Begin(2)
+	insertRow(t, st, tx, tn, 5)
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.TruncateTable(ctx, truncTx, tn)
+	if err != nil {
+		t.Fatalf("TruncateTable(%s) failed with %s", tn, err)
+	}
+	err = truncTx.Commit(ctx)
+	if err == nil {
+		t.Error("Commit() of truncate of updated table did not fail")
+	}
+
+	tx = st.Begin(2)
+	if cnt := countRows(t, st, tx, tn); cnt != 1 {
+		t.Errorf("countRows() got %d want 1", cnt)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
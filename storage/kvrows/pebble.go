@@ -85,6 +85,10 @@ func (pkv *pebbleKV) Updater() (Updater, error) {
 	}, nil
 }
 
+func (pkv *pebbleKV) DeleteRange(minKey, maxKey []byte) error {
+	return pkv.db.DeleteRange(minKey, maxKey, pebble.NoSync)
+}
+
 func (pu pebbleUpdater) Update(key []byte, fn func(val []byte) ([]byte, error)) error {
 	val, closer, err := pu.batch.Get(key)
 
@@ -25,6 +25,9 @@ type TableLayout struct {
 	tt      *engine.TableType
 	nextIID int64
 	indexes []IndexLayout
+	// Values for columns added after rows were written: rows have the value missing[col] for any
+	// column past the end of the row.
+	missing []sql.Value
 }
 
 func maybeNullColumns(key []sql.ColumnKey, colTypes []sql.ColumnType) bool {
@@ -95,6 +98,33 @@ func (tl *TableLayout) NumColumns() int {
 	return len(tl.tt.Columns())
 }
 
+func (tl *TableLayout) addColumn(val sql.Value) {
+	if val == nil && tl.missing == nil {
+		return
+	}
+
+	missing := make([]sql.Value, tl.NumColumns()+1)
+	copy(missing, tl.missing)
+	missing[len(missing)-1] = val
+	tl.missing = missing
+}
+
+// FillRow returns the row extended to the current number of columns, if it was written before
+// columns were added to the table.
+func (tl *TableLayout) FillRow(row []sql.Value) []sql.Value {
+	numCols := tl.NumColumns()
+	if len(row) >= numCols {
+		return row
+	}
+
+	fillRow := make([]sql.Value, numCols)
+	copy(fillRow, row)
+	for cdx := len(row); cdx < len(tl.missing); cdx += 1 {
+		fillRow[cdx] = tl.missing[cdx]
+	}
+	return fillRow
+}
+
 func (tl *TableLayout) PrimaryKey() []sql.ColumnKey {
 	return tl.tt.PrimaryKey()
 }
@@ -223,6 +253,10 @@ func (tl *TableLayout) encode() ([]byte, error) {
 			})
 	}
 
+	if tl.missing != nil {
+		md.Missing = encode.EncodeRowValue(tl.missing)
+	}
+
 	return proto.Marshal(&md)
 }
 
@@ -271,5 +305,12 @@ func (st *Store) decodeTableLayout(tn sql.TableName, tt *engine.TableType,
 			})
 	}
 
+	if len(md.Missing) > 0 {
+		tl.missing = encode.DecodeRowValue(md.Missing)
+		if tl.missing == nil {
+			return nil, fmt.Errorf("%s: table %s: corrupt metadata", st.name, tn)
+		}
+	}
+
 	return &tl, nil
 }
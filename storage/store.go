@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
+	"sync"
 
 	"github.com/leftmike/maho/engine"
 	"github.com/leftmike/maho/sql"
@@ -33,6 +35,7 @@ var (
 type sequenceRow struct {
 	Sequence string
 	Current  int64
+	Called   bool
 }
 
 type databaseRow struct {
@@ -46,21 +49,46 @@ type schemaRow struct {
 }
 
 type tableRow struct {
-	Database       string
-	Schema         string
-	Table          string
-	TID            int64
-	TypeMetadata   []byte
-	TypeVersion    int64
-	LayoutMetadata []byte
+	Database         string
+	Schema           string
+	Table            string
+	TID              int64
+	TypeMetadata     []byte
+	TypeVersion      int64
+	LayoutMetadata   []byte
+	ViewMetadata     []byte
+	SequenceMetadata []byte
+}
+
+const (
+	tableKind = iota
+	viewKind
+	sequenceKind
+)
+
+func (tr *tableRow) kind() int {
+	if len(tr.ViewMetadata) > 0 {
+		return viewKind
+	} else if len(tr.SequenceMetadata) > 0 {
+		return sequenceKind
+	}
+	return tableKind
 }
 
 type PersistentStore interface {
 	Table(ctx context.Context, tx engine.Transaction, tn sql.TableName, tid int64,
 		tt *engine.TableType, tl *TableLayout) (Table, error)
+	// DeleteTable removes all of the rows of the table, and its indexes, when tx commits.
+	DeleteTable(ctx context.Context, tx engine.Transaction, tid int64) error
 	Begin(sesid uint64) engine.Transaction
 }
 
+// SerialStore is implemented by a PersistentStore which runs only one transaction at a time:
+// Begin waits until the active transaction, if any, has committed or rolled back.
+type SerialStore interface {
+	Serial() bool
+}
+
 type Table interface {
 	engine.Table
 	FillIndex(ctx context.Context, iidx int) error
@@ -73,6 +101,10 @@ type Store struct {
 	databases *engine.TableType
 	schemas   *engine.TableType
 	tables    *engine.TableType
+
+	serial    bool
+	seqMutex  sync.Mutex
+	seqValues map[int64]sequenceRow // The most recent value of each sequence, if serial.
 }
 
 func NewStore(name string, ps PersistentStore, init bool) (*Store, error) {
@@ -80,9 +112,9 @@ func NewStore(name string, ps PersistentStore, init bool) (*Store, error) {
 		name: name,
 		ps:   ps,
 		sequences: engine.MakeTableType(
-			[]sql.Identifier{sql.ID("sequence"), sql.ID("current")},
-			[]sql.ColumnType{sql.IdColType, sql.Int64ColType},
-			make([]sql.ColumnDefault, 2),
+			[]sql.Identifier{sql.ID("sequence"), sql.ID("current"), sql.ID("called")},
+			[]sql.ColumnType{sql.IdColType, sql.Int64ColType, sql.BoolColType},
+			make([]sql.ColumnDefault, 3),
 			[]sql.ColumnKey{sql.MakeColumnKey(0, false)}),
 
 		databases: engine.MakeTableType(
@@ -99,14 +131,21 @@ func NewStore(name string, ps PersistentStore, init bool) (*Store, error) {
 
 		tables: engine.MakeTableType(
 			[]sql.Identifier{sql.ID("database"), sql.ID("schema"), sql.ID("table"), sql.ID("tid"),
-				sql.ID("typemetadata"), sql.ID("typeversion"), sql.ID("layoutmetadata")},
+				sql.ID("typemetadata"), sql.ID("typeversion"), sql.ID("layoutmetadata"),
+				sql.ID("viewmetadata"), sql.ID("sequencemetadata")},
 			[]sql.ColumnType{sql.IdColType, sql.IdColType, sql.IdColType, sql.Int64ColType,
 				{Type: sql.BytesType, Fixed: false, Size: sql.MaxColumnSize}, sql.Int64ColType,
+				{Type: sql.BytesType, Fixed: false, Size: sql.MaxColumnSize},
+				{Type: sql.BytesType, Fixed: false, Size: sql.MaxColumnSize},
 				{Type: sql.BytesType, Fixed: false, Size: sql.MaxColumnSize}},
-			make([]sql.ColumnDefault, 7),
+			make([]sql.ColumnDefault, 9),
 			[]sql.ColumnKey{sql.MakeColumnKey(0, false), sql.MakeColumnKey(1, false),
 				sql.MakeColumnKey(2, false)}),
 	}
+	if ss, ok := ps.(SerialStore); ok && ss.Serial() {
+		st.serial = true
+		st.seqValues = map[int64]sequenceRow{}
+	}
 	if init {
 		ctx := context.Background()
 		tx := st.ps.Begin(0)
@@ -485,6 +524,12 @@ func (st *Store) lookupTable(ctx context.Context, tx engine.Transaction,
 		}
 		return nil, nil, err
 	}
+	switch tr.kind() {
+	case viewKind:
+		return nil, nil, fmt.Errorf("%s: %s is a view", st.name, tn)
+	case sequenceKind:
+		return nil, nil, fmt.Errorf("%s: %s is a sequence", st.name, tn)
+	}
 	tid := tr.TID
 
 	tt, err := engine.DecodeTableType(tn, tr.TypeMetadata)
@@ -611,6 +656,148 @@ func (st *Store) DropTable(ctx context.Context, tx engine.Transaction, tn sql.Ta
 	return rows.Delete(ctx)
 }
 
+// TruncateTable moves the table to a new tid, and then deletes the rows at the old tid.
+func (st *Store) TruncateTable(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) error {
+
+	return st.moveTable(ctx, tx, tn, nil, nil)
+}
+
+// RebuildTable moves the table to a new tid, with a new layout for tt, and calls copyRows with
+// the table at the old tid and the table at the new tid; copyRows is responsible for inserting the
+// rows again. Then the rows at the old tid are deleted.
+func (st *Store) RebuildTable(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	tt *engine.TableType, copyRows func(otbl, ntbl engine.Table) error) error {
+
+	return st.moveTable(ctx, tx, tn, tt, copyRows)
+}
+
+func (st *Store) moveTable(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	tt *engine.TableType, copyRows func(otbl, ntbl engine.Table) error) error {
+
+	tid, err := st.nextSequenceValue(ctx, tx, tidSequence)
+	if err != nil {
+		return err
+	}
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err == io.EOF {
+		return fmt.Errorf("%s: table %s not found", st.name, tn)
+	} else if err != nil {
+		return err
+	}
+
+	if tt == nil {
+		err = rows.Update(ctx,
+			struct {
+				TID int64
+			}{tid})
+	} else {
+		if tr.TypeVersion != tt.Version()-1 {
+			return fmt.Errorf("%s: table %s: conflicting metadata update", st.name, tn)
+		}
+
+		var ott *engine.TableType
+		ott, err = engine.DecodeTableType(tn, tr.TypeMetadata)
+		if err != nil {
+			return err
+		}
+		var otl *TableLayout
+		otl, err = st.decodeTableLayout(tn, ott, tr.LayoutMetadata)
+		if err != nil {
+			return err
+		}
+		var otbl Table
+		otbl, err = st.ps.Table(ctx, tx, tn, tr.TID, ott, otl)
+		if err != nil {
+			return err
+		}
+
+		var typmd, lyomd []byte
+		typmd, err = tt.Encode()
+		if err != nil {
+			return err
+		}
+		tl := makeTableLayout(tt)
+		lyomd, err = tl.encode()
+		if err != nil {
+			return err
+		}
+
+		err = rows.Update(ctx,
+			struct {
+				TID            int64
+				TypeMetadata   []byte
+				TypeVersion    int64
+				LayoutMetadata []byte
+			}{tid, typmd, tt.Version(), lyomd})
+		if err != nil {
+			return err
+		}
+
+		var ntbl Table
+		ntbl, err = st.ps.Table(ctx, tx, tn, tid, tt, tl)
+		if err != nil {
+			return err
+		}
+		err = copyRows(otbl, ntbl)
+	}
+	if err != nil {
+		return err
+	}
+	return st.ps.DeleteTable(ctx, tx, tr.TID)
+}
+
+func (st *Store) RenameTable(ctx context.Context, tx engine.Transaction, tn,
+	ntn sql.TableName) error {
+
+	ok, err := st.validTable(ctx, tx, ntn)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("%s: table %s already exists", st.name, ntn)
+	}
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err == io.EOF {
+		return fmt.Errorf("%s: table %s not found", st.name, tn)
+	} else if err != nil {
+		return err
+	}
+
+	err = rows.Delete(ctx)
+	if err != nil {
+		return err
+	}
+
+	tbl, err := st.ps.Table(ctx, tx, tablesTableName, tablesTID, st.tables,
+		makeTableLayout(st.tables))
+	if err != nil {
+		return err
+	}
+	ttbl := util.MakeTypedTable(tablesTableName, tbl, st.tables)
+
+	tr.Database = ntn.Database.String()
+	tr.Schema = ntn.Schema.String()
+	tr.Table = ntn.Table.String()
+	return ttbl.Insert(ctx, tr)
+}
+
 func (st *Store) UpdateType(ctx context.Context, tx engine.Transaction, tn sql.TableName,
 	tt *engine.TableType) error {
 
@@ -677,6 +864,21 @@ func (st *Store) updateLayout(ctx context.Context, tx engine.Transaction, tn sql
 		}{typmd, tt.Version(), lyomd})
 }
 
+// AddColumn updates the type of the table to tt, which has one more column than the current
+// type; existing rows will have val for the new column.
+func (st *Store) AddColumn(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	tt *engine.TableType, val sql.Value) error {
+
+	return st.updateLayout(ctx, tx, tn, tt,
+		func(tl *TableLayout) error {
+			if tl.NumColumns()+1 != len(tt.Columns()) {
+				return fmt.Errorf("%s: table %s: expected one more column", st.name, tn)
+			}
+			tl.addColumn(val)
+			return nil
+		})
+}
+
 func (st *Store) AddIndex(ctx context.Context, tx engine.Transaction, tn sql.TableName,
 	tt *engine.TableType, it sql.IndexType) error {
 
@@ -719,6 +921,106 @@ func (st *Store) FillIndex(ctx context.Context, tx engine.Transaction, tn sql.Ta
 	return tbl.FillIndex(ctx, iidx)
 }
 
+// LookupView returns nil if tn is not a view.
+func (st *Store) LookupView(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) (*engine.ViewType, error) {
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if tr.kind() != viewKind {
+		return nil, nil
+	}
+	return engine.DecodeViewType(tn, tr.ViewMetadata)
+}
+
+func (st *Store) CreateView(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	vt *engine.ViewType, replace bool) error {
+
+	viewmd, err := vt.Encode()
+	if err != nil {
+		return err
+	}
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err == nil {
+		if tr.kind() != viewKind {
+			return fmt.Errorf("%s: table %s already exists", st.name, tn)
+		} else if !replace {
+			return fmt.Errorf("%s: view %s already exists", st.name, tn)
+		}
+		return rows.Update(ctx,
+			struct {
+				ViewMetadata []byte
+			}{viewmd})
+	} else if err != io.EOF {
+		return err
+	}
+
+	err = st.updateSchema(ctx, tx, tn.SchemaName(), 1)
+	if err != nil {
+		return err
+	}
+
+	tbl, err := st.ps.Table(ctx, tx, tablesTableName, tablesTID, st.tables,
+		makeTableLayout(st.tables))
+	if err != nil {
+		return err
+	}
+	ttbl := util.MakeTypedTable(tablesTableName, tbl, st.tables)
+
+	return ttbl.Insert(ctx,
+		tableRow{
+			Database:     tn.Database.String(),
+			Schema:       tn.Schema.String(),
+			Table:        tn.Table.String(),
+			ViewMetadata: viewmd,
+		})
+}
+
+func (st *Store) DropView(ctx context.Context, tx engine.Transaction, tn sql.TableName) error {
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err == io.EOF {
+		return fmt.Errorf("%s: view %s not found", st.name, tn)
+	} else if err != nil {
+		return err
+	}
+	if tr.kind() != viewKind {
+		return fmt.Errorf("%s: %s is not a view", st.name, tn)
+	}
+
+	err = rows.Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return st.updateSchema(ctx, tx, tn.SchemaName(), -1)
+}
+
 func (st *Store) Begin(sesid uint64) engine.Transaction {
 	return st.ps.Begin(sesid)
 }
@@ -786,8 +1088,8 @@ func (st *Store) ListSchemas(ctx context.Context, tx engine.Transaction,
 	return scnames, nil
 }
 
-func (st *Store) ListTables(ctx context.Context, tx engine.Transaction,
-	sn sql.SchemaName) ([]sql.Identifier, error) {
+func (st *Store) listTables(ctx context.Context, tx engine.Transaction, sn sql.SchemaName,
+	kind int) ([]sql.Identifier, error) {
 
 	tbl, err := st.ps.Table(ctx, tx, tablesTableName, tablesTID, st.tables,
 		makeTableLayout(st.tables))
@@ -797,7 +1099,7 @@ func (st *Store) ListTables(ctx context.Context, tx engine.Transaction,
 	ttbl := util.MakeTypedTable(tablesTableName, tbl, st.tables)
 
 	rows, err := ttbl.Rows(ctx,
-		tableRow{Database: sn.Database.String(), Table: sn.Schema.String()}, nil)
+		tableRow{Database: sn.Database.String(), Schema: sn.Schema.String()}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -815,13 +1117,33 @@ func (st *Store) ListTables(ctx context.Context, tx engine.Transaction,
 		if tr.Database != sn.Database.String() || tr.Schema != sn.Schema.String() {
 			break
 		}
-		tblnames = append(tblnames, sql.ID(tr.Table))
+		if tr.kind() == kind {
+			tblnames = append(tblnames, sql.ID(tr.Table))
+		}
 	}
 	return tblnames, nil
 }
 
-func (st *Store) nextSequenceValue(ctx context.Context, tx engine.Transaction,
-	sequence string) (int64, error) {
+func (st *Store) ListTables(ctx context.Context, tx engine.Transaction,
+	sn sql.SchemaName) ([]sql.Identifier, error) {
+
+	return st.listTables(ctx, tx, sn, tableKind)
+}
+
+func (st *Store) ListViews(ctx context.Context, tx engine.Transaction,
+	sn sql.SchemaName) ([]sql.Identifier, error) {
+
+	return st.listTables(ctx, tx, sn, viewKind)
+}
+
+func (st *Store) ListSequences(ctx context.Context, tx engine.Transaction,
+	sn sql.SchemaName) ([]sql.Identifier, error) {
+
+	return st.listTables(ctx, tx, sn, sequenceKind)
+}
+
+func (st *Store) updateSequenceValue(ctx context.Context, tx engine.Transaction,
+	sequence string, update func(sr sequenceRow) (int64, bool, error)) (int64, error) {
 
 	tbl, err := st.ps.Table(ctx, tx, sequencesTableName, sequencesTID, st.sequences,
 		makeTableLayout(st.sequences))
@@ -842,12 +1164,356 @@ func (st *Store) nextSequenceValue(ctx context.Context, tx engine.Transaction,
 	if err != nil {
 		return 0, fmt.Errorf("%s: sequence %s not found", st.name, sequence)
 	}
+	val, called, err := update(sr)
+	if err != nil {
+		return 0, err
+	}
 	err = rows.Update(ctx,
 		struct {
 			Current int64
-		}{sr.Current + 1})
+			Called  bool
+		}{val, called})
 	if err != nil {
 		return 0, err
 	}
-	return sr.Current + 1, nil
+	return val, nil
+}
+
+func (st *Store) nextSequenceValue(ctx context.Context, tx engine.Transaction,
+	sequence string) (int64, error) {
+
+	return st.updateSequenceValue(ctx, tx, sequence,
+		func(sr sequenceRow) (int64, bool, error) {
+			return sr.Current + 1, true, nil
+		})
+}
+
+// The values of user sequences are kept in the sequences table using the tid of the sequence as
+// the key.
+func sequenceKey(tid int64) string {
+	return strconv.FormatInt(tid, 10)
+}
+
+func (st *Store) lookupSequence(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) (*engine.SequenceType, int64, error) {
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err != nil {
+		if err == io.EOF {
+			return nil, 0, fmt.Errorf("%s: sequence %s not found", st.name, tn)
+		}
+		return nil, 0, err
+	}
+	if tr.kind() != sequenceKind {
+		return nil, 0, fmt.Errorf("%s: %s is not a sequence", st.name, tn)
+	}
+
+	sqt, err := engine.DecodeSequenceType(tn, tr.SequenceMetadata)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sqt, tr.TID, nil
+}
+
+// LookupSequence returns nil if tn is not a sequence.
+func (st *Store) LookupSequence(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) (*engine.SequenceType, error) {
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if tr.kind() != sequenceKind {
+		return nil, nil
+	}
+	return engine.DecodeSequenceType(tn, tr.SequenceMetadata)
+}
+
+func (st *Store) CreateSequence(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	sqt *engine.SequenceType, ifNotExists bool) error {
+
+	seqmd, err := sqt.Encode()
+	if err != nil {
+		return err
+	}
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err == nil {
+		if tr.kind() != sequenceKind {
+			return fmt.Errorf("%s: table %s already exists", st.name, tn)
+		} else if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("%s: sequence %s already exists", st.name, tn)
+	} else if err != io.EOF {
+		return err
+	}
+
+	tid, err := st.nextSequenceValue(ctx, tx, tidSequence)
+	if err != nil {
+		return err
+	}
+	st.forgetSequenceValue(tid)
+
+	err = st.updateSchema(ctx, tx, tn.SchemaName(), 1)
+	if err != nil {
+		return err
+	}
+
+	tbl, err := st.ps.Table(ctx, tx, tablesTableName, tablesTID, st.tables,
+		makeTableLayout(st.tables))
+	if err != nil {
+		return err
+	}
+	ttbl := util.MakeTypedTable(tablesTableName, tbl, st.tables)
+
+	err = ttbl.Insert(ctx,
+		tableRow{
+			Database:         tn.Database.String(),
+			Schema:           tn.Schema.String(),
+			Table:            tn.Table.String(),
+			TID:              tid,
+			SequenceMetadata: seqmd,
+		})
+	if err != nil {
+		return err
+	}
+
+	tbl, err = st.ps.Table(ctx, tx, sequencesTableName, sequencesTID, st.sequences,
+		makeTableLayout(st.sequences))
+	if err != nil {
+		return err
+	}
+	ttbl = util.MakeTypedTable(sequencesTableName, tbl, st.sequences)
+
+	return ttbl.Insert(ctx,
+		sequenceRow{
+			Sequence: sequenceKey(tid),
+			Current:  sqt.Sequence().Start,
+		})
+}
+
+func (st *Store) UpdateSequence(ctx context.Context, tx engine.Transaction, tn sql.TableName,
+	sqt *engine.SequenceType) error {
+
+	seqmd, err := sqt.Encode()
+	if err != nil {
+		return err
+	}
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err == io.EOF {
+		return fmt.Errorf("%s: sequence %s not found", st.name, tn)
+	} else if err != nil {
+		return err
+	}
+	if tr.kind() != sequenceKind {
+		return fmt.Errorf("%s: %s is not a sequence", st.name, tn)
+	}
+
+	return rows.Update(ctx,
+		struct {
+			SequenceMetadata []byte
+		}{seqmd})
+}
+
+func (st *Store) DropSequence(ctx context.Context, tx engine.Transaction,
+	tn sql.TableName) error {
+
+	rows, err := st.lookupTableRows(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tr tableRow
+	err = rows.Next(ctx, &tr)
+	if err == io.EOF {
+		return fmt.Errorf("%s: sequence %s not found", st.name, tn)
+	} else if err != nil {
+		return err
+	}
+	if tr.kind() != sequenceKind {
+		return fmt.Errorf("%s: %s is not a sequence", st.name, tn)
+	}
+
+	err = rows.Delete(ctx)
+	if err != nil {
+		return err
+	}
+
+	tbl, err := st.ps.Table(ctx, tx, sequencesTableName, sequencesTID, st.sequences,
+		makeTableLayout(st.sequences))
+	if err != nil {
+		return err
+	}
+	ttbl := util.MakeTypedTable(sequencesTableName, tbl, st.sequences)
+
+	keyRow := sequenceRow{Sequence: sequenceKey(tr.TID)}
+	srows, err := ttbl.Rows(ctx, keyRow, keyRow)
+	if err != nil {
+		return err
+	}
+	defer srows.Close()
+
+	var sr sequenceRow
+	err = srows.Next(ctx, &sr)
+	if err != nil {
+		return fmt.Errorf("%s: sequence %s not found", st.name, tn)
+	}
+	err = srows.Delete(ctx)
+	if err != nil {
+		return err
+	}
+	st.forgetSequenceValue(tr.TID)
+
+	return st.updateSchema(ctx, tx, tn.SchemaName(), -1)
+}
+
+// forgetSequenceValue forgets the most recent value of the sequence with tid, if it is
+// remembered; tids which are allocated by a transaction which rolls back are used again.
+func (st *Store) forgetSequenceValue(tid int64) {
+	if st.serial {
+		st.seqMutex.Lock()
+		delete(st.seqValues, tid)
+		st.seqMutex.Unlock()
+	}
+}
+
+// updateUserSequence updates the value of sequence tn. Sequences are not transactional, so,
+// unless tn was created by tx and is not yet committed, the value is updated in a transaction
+// of its own, which is committed before returning, rather than in tx. A serial store can't
+// start another transaction, so the value is updated in tx and also remembered, in case tx
+// rolls back. inTx is true if the value will be rolled back with tx.
+func (st *Store) updateUserSequence(ctx context.Context, tx engine.Transaction, sesid uint64,
+	tn sql.TableName, update func(sqt *engine.SequenceType, sr sequenceRow,
+		inTx bool) (int64, bool, error)) error {
+
+	sqt, tid, err := st.lookupSequence(ctx, tx, tn)
+	if err != nil {
+		return err
+	}
+
+	if st.serial {
+		st.seqMutex.Lock()
+		defer st.seqMutex.Unlock()
+
+		// A sequence may be used more than once by a single statement, so each use of a
+		// sequence is its own statement.
+		tx.NextStmt()
+		_, err = st.updateSequenceValue(ctx, tx, sequenceKey(tid),
+			func(sr sequenceRow) (int64, bool, error) {
+				if cur, ok := st.seqValues[tid]; ok {
+					sr = cur
+				}
+				val, called, err := update(sqt, sr, false)
+				if err != nil {
+					return 0, false, err
+				}
+				st.seqValues[tid] = sequenceRow{Current: val, Called: called}
+				return val, called, nil
+			})
+		return err
+	}
+
+	stx := st.ps.Begin(sesid)
+	_, stid, err := st.lookupSequence(ctx, stx, tn)
+	if err != nil || stid != tid {
+		stx.Rollback()
+
+		tx.NextStmt()
+		_, err = st.updateSequenceValue(ctx, tx, sequenceKey(tid),
+			func(sr sequenceRow) (int64, bool, error) {
+				return update(sqt, sr, true)
+			})
+		return err
+	}
+
+	st.seqMutex.Lock()
+	defer st.seqMutex.Unlock()
+
+	_, err = st.updateSequenceValue(ctx, stx, sequenceKey(tid),
+		func(sr sequenceRow) (int64, bool, error) {
+			return update(sqt, sr, false)
+		})
+	if err != nil {
+		stx.Rollback()
+		return err
+	}
+	return stx.Commit(ctx)
+}
+
+// NextSequenceValues allocates the next values of sequence tn, as many as its cache size, and
+// returns the sequence, the first value, and the number of values allocated; each value after
+// the first is the NextValue of the one before it.
+func (st *Store) NextSequenceValues(ctx context.Context, tx engine.Transaction, sesid uint64,
+	tn sql.TableName) (*engine.SequenceType, int64, int64, error) {
+
+	var sqt *engine.SequenceType
+	var first, num int64
+	err := st.updateUserSequence(ctx, tx, sesid, tn,
+		func(seqt *engine.SequenceType, sr sequenceRow, inTx bool) (int64, bool, error) {
+			cache := seqt.Sequence().Cache
+			if inTx {
+				// Don't hand out values of a sequence which might be rolled back.
+				cache = 1
+			}
+
+			var last int64
+			var err error
+			first, last, num, err = seqt.NextValues(tn, sr.Current, sr.Called, cache)
+			if err != nil {
+				return 0, false, err
+			}
+			sqt = seqt
+			return last, true, nil
+		})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return sqt, first, num, nil
+}
+
+func (st *Store) SetSequenceValue(ctx context.Context, tx engine.Transaction, sesid uint64,
+	tn sql.TableName, val int64, called bool) error {
+
+	return st.updateUserSequence(ctx, tx, sesid, tn,
+		func(sqt *engine.SequenceType, sr sequenceRow, inTx bool) (int64, bool, error) {
+			err := sqt.CheckValue(tn, val)
+			if err != nil {
+				return 0, false, err
+			}
+			return val, called, nil
+		})
 }
@@ -300,6 +300,45 @@ var (
 				updates: []sql.ColumnUpdate{{Column: 1, Value: i64Val(40)}}},
 			{fln: fln(), cmd: cmdCommit},
 		},
+		[]storeCmd{
+			{fln: fln(), cmd: cmdBegin},
+			{fln: fln(), cmd: cmdTruncateTable, name: sql.ID("tbl4")},
+			{fln: fln(), cmd: cmdLookupTable, name: sql.ID("tbl4")},
+			{fln: fln(), cmd: cmdRows, values: [][]sql.Value{}},
+			{fln: fln(), cmd: cmdRollback},
+		},
+		[]storeCmd{
+			{fln: fln(), cmd: cmdBegin},
+			{fln: fln(), cmd: cmdLookupTable, name: sql.ID("tbl4")},
+			{fln: fln(), cmd: cmdRows,
+				values: [][]sql.Value{
+					{i64Val(1), i64Val(40), strVal("first row")},
+					{i64Val(2), i64Val(4), strVal("second row")},
+				},
+			},
+			{fln: fln(), cmd: cmdTruncateTable, name: sql.ID("tbl4")},
+			{fln: fln(), cmd: cmdLookupTable, name: sql.ID("tbl4")},
+			{fln: fln(), cmd: cmdInsert,
+				row: []sql.Value{i64Val(3), i64Val(9), strVal("third row")}},
+			{fln: fln(), cmd: cmdNextStmt},
+			{fln: fln(), cmd: cmdRows,
+				values: [][]sql.Value{
+					{i64Val(3), i64Val(9), strVal("third row")},
+				},
+			},
+			{fln: fln(), cmd: cmdCommit},
+		},
+		[]storeCmd{
+			{fln: fln(), cmd: cmdBegin},
+			{fln: fln(), cmd: cmdLookupTable, name: sql.ID("tbl4")},
+			{fln: fln(), cmd: cmdRows,
+				values: [][]sql.Value{
+					{i64Val(3), i64Val(9), strVal("third row")},
+				},
+			},
+			{fln: fln(), cmd: cmdTruncateTable, name: sql.ID("tbl5"), fail: true},
+			{fln: fln(), cmd: cmdCommit},
+		},
 	}
 )
 
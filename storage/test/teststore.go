@@ -51,6 +51,7 @@ const (
 	cmdSync
 	cmdCreateDatabase
 	cmdDropDatabase
+	cmdTruncateTable
 )
 
 func (cmd command) String() string {
@@ -80,6 +81,7 @@ func (cmd command) String() string {
 		"Sync",
 		"CreateDatabase",
 		"DropDatabase",
+		"TruncateTable",
 	}[cmd]
 }
 
@@ -396,6 +398,16 @@ func testDatabaseCmds(t *testing.T, st *storage.Store, dbname sql.Identifier, sy
 				t.Errorf("%sDropTable(%s) failed with %s", cmd.fln, cmd.name, err)
 			}
 			state.tx.NextStmt()
+		case cmdTruncateTable:
+			err := st.TruncateTable(ctx, state.tx, sql.TableName{dbname, scname, cmd.name})
+			if cmd.fail {
+				if err == nil {
+					t.Errorf("%sTruncateTable(%s) did not fail", cmd.fln, cmd.name)
+				}
+			} else if err != nil {
+				t.Errorf("%sTruncateTable(%s) failed with %s", cmd.fln, cmd.name, err)
+			}
+			state.tx.NextStmt()
 		case cmdListTables:
 			tblnames, err := st.ListTables(ctx, state.tx, sql.SchemaName{dbname, scname})
 			if err != nil {
@@ -97,6 +97,112 @@ DELETE FROM tbl1 WHERE c1 = 4;
 			fln: fln(),
 			stmts: `
 COMMIT;
+`,
+		},
+	}
+
+	test2 = []step{
+		{
+			fln: fln(),
+			stmts: `
+DROP TABLE IF EXISTS tbl3;
+DROP SEQUENCE IF EXISTS seq1;
+CREATE TABLE tbl3 (
+    c1 serial PRIMARY KEY,
+    c2 int
+);
+CREATE SEQUENCE seq1 CACHE 3;
+`,
+		},
+		{fln: fln(), thrd: 0, cmd: "sync"},
+		{
+			fln: fln(),
+			stmts: `
+BEGIN;
+INSERT INTO tbl3 (c2) VALUES (10);
+`,
+		},
+		{fln: fln(), thrd: 0, cmd: "sync"},
+		{
+			fln:  fln(),
+			thrd: 1,
+			stmts: `
+INSERT INTO tbl3 (c2) VALUES (20);
+`,
+		},
+		{
+			fln: fln(),
+			stmts: `
+ROLLBACK;
+`,
+		},
+		{fln: fln(), thrd: 0, cmd: "sync"},
+		{fln: fln(), thrd: 1, cmd: "sync"},
+		{
+			fln: fln(),
+			stmts: `
+INSERT INTO tbl3 (c2) VALUES (30);
+SELECT * FROM tbl3 ORDER BY c1;
+`,
+			result: `1 rows updated
++----+----+
+| c1 | c2 |
++----+----+
+|  2 | 20 |
+|  3 | 30 |
++----+----+
+(2 rows)
+`,
+		},
+		{
+			fln: fln(),
+			stmts: `
+BEGIN;
+SELECT nextval('seq1');
+ROLLBACK;
+SELECT nextval('seq1');
+`,
+			result: `+---------+
+| nextval |
++---------+
+|       1 |
++---------+
+(1 rows)
++---------+
+| nextval |
++---------+
+|       2 |
++---------+
+(1 rows)
+`,
+		},
+		{fln: fln(), thrd: 0, cmd: "sync"},
+		{
+			fln:  fln(),
+			thrd: 1,
+			stmts: `
+SELECT nextval('seq1');
+`,
+			result: `+---------+
+| nextval |
++---------+
+|       3 |
++---------+
+(1 rows)
+`,
+		},
+		{fln: fln(), thrd: 1, cmd: "sync"},
+		{
+			fln: fln(),
+			stmts: `
+SELECT nextval('seq1');
+`,
+			result: `+---------+
+| nextval |
++---------+
+|       4 |
++---------+
+(1 rows)
 `,
 		},
 	}
@@ -215,3 +321,26 @@ func TestAsync(t *testing.T) {
 			})
 	}
 }
+
+func TestAsyncSequences(t *testing.T) {
+	cleanDir(t)
+
+	for _, cfg := range configs {
+		t.Run(cfg.name,
+			func(t *testing.T) {
+				dataDir := filepath.Join("testdata", "async", "sequences", cfg.name)
+				os.MkdirAll(dataDir, 0755)
+
+				st, err := cfg.newStore(dataDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				e := engine.NewEngine(st, flags.Default())
+				e.CreateDatabase(sql.ID("test"), nil)
+				// Ignore errors: the database might already exist.
+
+				testAsync(t, e, sql.ID("test"), test2)
+			})
+	}
+}
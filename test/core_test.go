@@ -3,6 +3,7 @@ package test_test
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
@@ -24,13 +25,14 @@ func TestValuesSimple(t *testing.T) {
 		{
 			sql: "values (true, 'abcd', 123.456, 789)",
 			rows: [][]sql.Value{{sql.BoolValue(true), sql.StringValue("abcd"),
-				sql.Float64Value(123.456), sql.Int64Value(789)}},
+				sql.MakeNumericValue(big.NewInt(123456), 3), sql.Int64Value(789)}},
 		},
 		{
 			sql: "values (1 + 2, 3, 4 - 5), (12, 34, 56.7 * 8)",
 			rows: [][]sql.Value{
 				{sql.Int64Value(3), sql.Int64Value(3), sql.Int64Value(-1)},
-				{sql.Int64Value(12), sql.Int64Value(34), sql.Float64Value(453.6)},
+				{sql.Int64Value(12), sql.Int64Value(34),
+					sql.MakeNumericValue(big.NewInt(4536), 1)},
 			},
 		},
 	}
@@ -48,6 +48,17 @@ func (run *Runner) RunExec(tst *sqltestdb.Test) (int64, error) {
 					if err != nil {
 						return err
 					}
+				} else if rowsPlan, ok := plan.(evaluate.RowsPlan); ok {
+					// INSERT, UPDATE, or DELETE with RETURNING
+					rows, err := rowsPlan.Rows(ctx, tx, nil)
+					if err != nil {
+						return err
+					}
+					all, err := evaluate.AllRows(ctx, rows)
+					if err != nil {
+						return err
+					}
+					n = int64(len(all))
 				} else {
 					return fmt.Errorf("%s:%d: expected a stmt or cmd", tst.Filename,
 						tst.LineNumber)
@@ -63,8 +74,16 @@ func (run *Runner) RunExec(tst *sqltestdb.Test) (int64, error) {
 }
 
 func columnType(ct sql.ColumnType) string {
+	if ct.Array {
+		ct.Array = false
+		return "_" + columnType(ct)
+	}
+
 	switch ct.Type {
-	case sql.UnknownType, sql.BooleanType:
+	case sql.UnknownType, sql.BooleanType, sql.DateType, sql.TimeType, sql.TimestampType,
+		sql.TimestampTZType, sql.IntervalType, sql.NumericType, sql.JSONType,
+		sql.UUIDType, sql.JSONBType:
+
 		return ct.Type.String()
 	case sql.BytesType:
 		return "BYTEA"
@@ -0,0 +1,96 @@
+--
+-- Test ALTER TABLE ALTER COLUMN SET DEFAULT, SET NOT NULL, and TYPE
+--
+
+DROP TABLE IF EXISTS altcol_child;
+
+DROP TABLE IF EXISTS altcol_tbl;
+
+CREATE TABLE altcol_tbl (id int PRIMARY KEY CHECK (id > 0), name text, cnt int,
+    amt text CONSTRAINT amt_check CHECK (amt <> 'bad'));
+
+CREATE INDEX altcol_cnt ON altcol_tbl (cnt);
+
+CREATE UNIQUE INDEX altcol_amt ON altcol_tbl (amt);
+
+INSERT INTO altcol_tbl VALUES (1, 'one', 10, '1.5'), (2, NULL, 20, '2.5'), (3, 'three', NULL, '3');
+
+ALTER TABLE altcol_tbl ALTER name SET DEFAULT 'none';
+
+INSERT INTO altcol_tbl (id, cnt, amt) VALUES (4, 40, '4.25');
+
+SELECT * FROM altcol_tbl;
+
+{{Fail .Test}}
+ALTER TABLE altcol_tbl ALTER COLUMN name SET NOT NULL;
+
+UPDATE altcol_tbl SET name = 'two' WHERE id = 2;
+
+ALTER TABLE altcol_tbl ALTER COLUMN name SET NOT NULL;
+
+{{Fail .Test}}
+INSERT INTO altcol_tbl VALUES (5, NULL, 50, '5');
+
+ALTER TABLE altcol_tbl ALTER name DROP NOT NULL, ALTER name DROP DEFAULT;
+
+INSERT INTO altcol_tbl (id, cnt, amt) VALUES (5, 50, '5');
+
+SELECT * FROM altcol_tbl WHERE id = 5;
+
+{{Fail .Test}}
+ALTER TABLE altcol_tbl ALTER amt TYPE double precision;
+
+{{Fail .Test}}
+INSERT INTO altcol_tbl VALUES (6, 'six', 60, 'bad');
+
+ALTER TABLE altcol_tbl DROP CONSTRAINT amt_check, ALTER amt TYPE double precision;
+
+SELECT * FROM altcol_tbl WHERE amt > 2;
+
+INSERT INTO altcol_tbl VALUES (6, 'six', 60, 6.75);
+
+{{Fail .Test}}
+INSERT INTO altcol_tbl VALUES (7, 'seven', 70, 'abc');
+
+{{Fail .Test}}
+INSERT INTO altcol_tbl VALUES (7, 'seven', 70, 6.75);
+
+ALTER TABLE altcol_tbl ALTER cnt SET DATA TYPE text USING 'cnt:' || cnt;
+
+SELECT * FROM altcol_tbl;
+
+SELECT * FROM altcol_tbl WHERE cnt = 'cnt:20';
+
+{{Fail .Test}}
+ALTER TABLE altcol_tbl ALTER name TYPE int;
+
+BEGIN;
+
+ALTER TABLE altcol_tbl ALTER id TYPE bigint USING id * 100;
+
+SELECT * FROM altcol_tbl;
+
+{{Fail .Test}}
+INSERT INTO altcol_tbl VALUES (0, 'zero', 'cnt:0', 0);
+
+ROLLBACK;
+
+SELECT * FROM altcol_tbl;
+
+{{Fail .Test}}
+ALTER TABLE altcol_tbl ALTER amt TYPE int USING 1;
+
+{{Fail .Test}}
+ALTER TABLE altcol_tbl ALTER missing TYPE int;
+
+SELECT * FROM altcol_tbl WHERE amt = 6.75;
+
+CREATE TABLE altcol_child (id int PRIMARY KEY, pid int REFERENCES altcol_tbl);
+
+{{Fail .Test}}
+ALTER TABLE altcol_tbl ALTER id TYPE bigint;
+
+{{Fail .Test}}
+ALTER TABLE altcol_child ALTER pid TYPE bigint;
+
+ALTER TABLE altcol_child ALTER id TYPE bigint;
@@ -0,0 +1,171 @@
+--
+-- Test ALTER TABLE ADD COLUMN, DROP COLUMN, RENAME COLUMN, and RENAME TO
+--
+
+DROP TABLE IF EXISTS alter_multi;
+
+DROP TABLE IF EXISTS alter_child;
+
+DROP TABLE IF EXISTS alter_parent;
+
+DROP TABLE IF EXISTS alter_renamed;
+
+DROP TABLE IF EXISTS alter_tbl;
+
+CREATE TABLE alter_tbl (id int PRIMARY KEY, name text, cnt int CHECK (cnt >= 0));
+
+CREATE INDEX alter_idx ON alter_tbl (name);
+
+INSERT INTO alter_tbl VALUES (1, 'one', 1), (2, 'two', 2), (3, 'three', 3);
+
+ALTER TABLE alter_tbl ADD COLUMN flag bool DEFAULT true;
+
+ALTER TABLE alter_tbl ADD amt double precision NOT NULL DEFAULT 1.5, ADD note text;
+
+SELECT * FROM alter_tbl;
+
+INSERT INTO alter_tbl (id, name, cnt, note) VALUES (4, 'four', 4, 'new');
+
+UPDATE alter_tbl SET amt = amt * 2 WHERE id = 2;
+
+SELECT * FROM alter_tbl;
+
+SELECT id, amt FROM alter_tbl WHERE name = 'two';
+
+ALTER TABLE alter_tbl ADD COLUMN IF NOT EXISTS note int;
+
+{{Fail .Test}}
+ALTER TABLE alter_tbl ADD COLUMN note int;
+
+{{Fail .Test}}
+ALTER TABLE alter_tbl ADD COLUMN req int NOT NULL;
+
+{{Fail .Test}}
+ALTER TABLE alter_tbl ADD COLUMN num int DEFAULT 'abc';
+
+ALTER TABLE alter_tbl ADD COLUMN seq int DEFAULT unique_rowid();
+
+SELECT id FROM alter_tbl WHERE seq IS NULL;
+
+ALTER TABLE alter_tbl DROP COLUMN seq;
+
+ALTER TABLE alter_tbl DROP COLUMN name;
+
+ALTER TABLE alter_tbl DROP cnt, DROP COLUMN IF EXISTS missing;
+
+SELECT * FROM alter_tbl;
+
+INSERT INTO alter_tbl VALUES (5, false, 5.5, 'five');
+
+SELECT * FROM alter_tbl;
+
+{{Fail .Test}}
+ALTER TABLE alter_tbl DROP COLUMN id;
+
+{{Fail .Test}}
+ALTER TABLE alter_tbl DROP COLUMN missing;
+
+BEGIN;
+
+ALTER TABLE alter_tbl DROP COLUMN note;
+
+SELECT * FROM alter_tbl;
+
+ROLLBACK;
+
+SELECT * FROM alter_tbl;
+
+ALTER TABLE alter_tbl RENAME COLUMN note TO comment;
+
+SELECT id, comment FROM alter_tbl;
+
+{{Fail .Test}}
+SELECT note FROM alter_tbl;
+
+{{Fail .Test}}
+ALTER TABLE alter_tbl RENAME flag TO amt;
+
+ALTER TABLE alter_tbl RENAME TO alter_renamed;
+
+SELECT * FROM alter_renamed;
+
+{{Fail .Test}}
+SELECT * FROM alter_tbl;
+
+CREATE TABLE alter_parent (id int PRIMARY KEY, name text);
+
+CREATE TABLE alter_child (id int PRIMARY KEY, pid int REFERENCES alter_parent);
+
+INSERT INTO alter_parent VALUES (1, 'one'), (2, 'two');
+
+INSERT INTO alter_child VALUES (10, 1), (20, 2);
+
+ALTER TABLE alter_parent RENAME COLUMN name TO title;
+
+ALTER TABLE alter_child RENAME pid TO parent_id;
+
+ALTER TABLE alter_parent RENAME TO alter_tbl;
+
+{{Fail .Test}}
+INSERT INTO alter_child VALUES (30, 3);
+
+INSERT INTO alter_child VALUES (30, 2);
+
+{{Fail .Test}}
+DELETE FROM alter_tbl WHERE id = 1;
+
+ALTER TABLE alter_child ADD COLUMN other int REFERENCES alter_tbl;
+
+{{Fail .Test}}
+INSERT INTO alter_child VALUES (40, 1, 3);
+
+INSERT INTO alter_child VALUES (40, 1, 2);
+
+SELECT * FROM alter_child;
+
+ALTER TABLE alter_child DROP COLUMN other;
+
+{{Fail .Test}}
+ALTER TABLE alter_tbl DROP COLUMN id;
+
+ALTER TABLE alter_tbl DROP COLUMN title;
+
+SELECT * FROM alter_tbl;
+
+{{Fail .Test}}
+DELETE FROM alter_tbl WHERE id = 1;
+
+DELETE FROM alter_child WHERE parent_id = 1;
+
+DELETE FROM alter_tbl WHERE id = 1;
+
+SELECT * FROM alter_child;
+
+ALTER TABLE alter_tbl RENAME TO alter_parent;
+
+
+CREATE TABLE alter_multi (id int PRIMARY KEY, p1 int, p2 int, cnt int CHECK (cnt > 0));
+
+ALTER TABLE alter_multi ADD CONSTRAINT fk1 FOREIGN KEY (p1) REFERENCES alter_parent,
+    ADD CONSTRAINT fk2 FOREIGN KEY (p2) REFERENCES alter_parent;
+
+INSERT INTO alter_multi VALUES (1, 2, 2, 1);
+
+ALTER TABLE alter_parent RENAME id TO pid;
+
+ALTER TABLE alter_multi DROP COLUMN p1;
+
+{{Fail .Test}}
+INSERT INTO alter_multi VALUES (2, 3, 1);
+
+INSERT INTO alter_multi VALUES (2, 2, 1);
+
+ALTER TABLE alter_multi RENAME cnt TO num;
+
+SELECT * FROM alter_multi;
+
+SELECT constraint_type, details FROM metadata.constraints
+    WHERE table_name = 'alter_multi' AND constraint_type = 'CHECK';
+
+{{Fail .Test}}
+INSERT INTO alter_multi VALUES (3, 2, 0);
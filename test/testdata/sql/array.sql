@@ -0,0 +1,117 @@
+--
+-- Test arrays
+--
+
+DROP TABLE IF EXISTS posts;
+
+CREATE TABLE posts (
+    id int primary key,
+    tags text[],
+    scores int[],
+    days date[]
+);
+
+INSERT INTO posts VALUES
+    (1, ARRAY['go', 'sql'], '{1,2,3}', '{2020-01-01}'),
+    (2, '{"a b", NULL}', ARRAY[4, NULL], NULL),
+    (3, '{}', ARRAY[]::int[], ARRAY['2021-02-03'::date]);
+
+-- {{Sort .Test false}}
+SELECT * FROM posts ORDER BY id;
+
+-- {{Sort .Test false}}
+SELECT id, tags[1], scores[2], scores[5], array_length(scores, 1) FROM posts ORDER BY id;
+
+SELECT id FROM posts WHERE 'sql' = ANY(tags);
+
+SELECT id FROM posts WHERE 2 = ANY(scores) OR id = 3;
+
+SELECT id FROM posts WHERE 4 = SOME(scores);
+
+-- {{Sort .Test false}}
+SELECT id, 3 > ALL(scores), 4 = ANY(scores), NULL = ANY(scores) FROM posts ORDER BY id;
+
+SELECT id FROM posts WHERE scores = '{1,2,3}';
+
+SELECT id FROM posts WHERE tags = ARRAY['go', 'sql'];
+
+SELECT id FROM posts WHERE '2021-02-03' = ANY(days);
+
+-- {{Sort .Test false}}
+SELECT id, scores FROM posts ORDER BY scores;
+
+SELECT days[1] + 1 FROM posts WHERE id = 1;
+
+SELECT ARRAY[1, 2, 3], ARRAY[1, 2][2], ARRAY['a', NULL, 'b c'], ARRAY[1.5, 2]::text;
+
+SELECT '{1,2}'::int[], ARRAY[1, 2]::text[], 1 = ANY('{1,2}'), 3 = ANY('{1,2}');
+
+SELECT array_length(ARRAY[1, 2], 1), array_length(ARRAY[1, 2], 2),
+    array_length('{}'::int[], 1);
+
+SELECT ARRAY[1, 2] || ARRAY[3, 4], ARRAY[1, 2] || 3, 0 || ARRAY[1, 2], ARRAY[1] || '{2,3}',
+    ARRAY[1, 2] || NULL, NULL || ARRAY[1, 2], ARRAY[1.5] || 2;
+
+SELECT id, tags || 'new'::text, scores || (days[1] - days[1]) FROM posts ORDER BY id;
+
+SELECT ARRAY[1, 2, 3] @> ARRAY[1], ARRAY[1, 2, 3] @> ARRAY[3, 1], ARRAY[1, 2, 3] @> ARRAY[4],
+    ARRAY[1, 2] @> '{}', ARRAY[1, NULL] @> ARRAY[NULL]::int[];
+
+SELECT id FROM posts WHERE tags @> ARRAY['sql'] ORDER BY id;
+
+SELECT * FROM unnest(ARRAY[1, 2, 3]);
+
+SELECT * FROM unnest(ARRAY['x', 'y']) AS u (letter);
+
+SELECT p.id, t FROM posts p, unnest(p.tags) t;
+
+SELECT n, n * 2 FROM unnest(ARRAY[1, 2, 3]) AS n WHERE n > 1;
+
+SELECT array_agg(id) FROM posts;
+
+SELECT array_agg(tags[1]) FROM posts;
+
+SELECT array_agg(id) FROM posts WHERE id > 10;
+
+UPDATE posts SET scores = ARRAY[9] WHERE id = 1;
+
+SELECT scores FROM posts WHERE id = 1;
+
+-- {{Fail .Test}}
+SELECT ARRAY[ARRAY[1]];
+
+-- {{Fail .Test}}
+SELECT ARRAY[1, 'a'];
+
+-- {{Fail .Test}}
+SELECT 1[1];
+
+-- {{Fail .Test}}
+SELECT ARRAY[1, 2]['a'];
+
+-- {{Fail .Test}}
+SELECT 1 = ANY(1);
+
+-- {{Fail .Test}}
+SELECT ARRAY[1, 2] || ARRAY['a'];
+
+-- {{Fail .Test}}
+SELECT ARRAY[1, 2] || 'a';
+
+-- {{Fail .Test}}
+SELECT ARRAY[1, 2] @> 1;
+
+-- {{Fail .Test}}
+SELECT ARRAY[1, 2] @> ARRAY['a'];
+
+-- {{Fail .Test}}
+SELECT '{1,{2}}'::int[];
+
+-- {{Fail .Test}}
+SELECT '{a,b}'::int[];
+
+-- {{Fail .Test}}
+INSERT INTO posts (id, scores) VALUES (4, '{1,x}');
+
+-- {{Fail .Test}}
+CREATE TABLE bad (c int[][]);
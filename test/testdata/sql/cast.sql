@@ -0,0 +1,77 @@
+--
+-- Test CAST and :: type conversions
+--
+
+DROP TABLE IF EXISTS conv;
+
+CREATE TABLE conv (id int primary key, s text, f double precision, b bool);
+
+INSERT INTO conv VALUES
+    (1, '10', 1.5, true),
+    (2, ' -20 ', -2.5, false),
+    (3, 'yes', 3.25, NULL),
+    (4, NULL, NULL, true);
+
+SELECT id, CAST(id AS text), id::double precision / 2, id::bool FROM conv;
+
+SELECT id, s::int * 2 FROM conv WHERE id < 3;
+
+SELECT id, f::int, f::smallint::text, f::real FROM conv;
+
+SELECT id, b::int, b::text, CAST(b AS varchar(5)) FROM conv;
+
+SELECT id, s::bool FROM conv WHERE id = 3;
+
+SELECT '2.5'::double precision + 1, '  42'::bigint, 'abc'::bytea, 'ab  '::char(2);
+
+SELECT CAST(CAST(123 AS text) AS int) = 123, 12.0::int::text || 'x';
+
+SELECT sum(id)::text, max(f)::int FROM conv;
+
+SELECT id FROM conv WHERE s::varchar(5) = '10';
+
+SELECT 'abcdef'::varchar(3), 'abc'::char, CAST('abcdef' AS char(4)), s::varchar(2) FROM conv
+    WHERE id = 3;
+
+DROP TABLE IF EXISTS short;
+
+CREATE TABLE short (id int primary key, s varchar(3));
+
+INSERT INTO short VALUES (1, 'abcdef'::varchar(3));
+
+-- {{Fail .Test}}
+INSERT INTO short VALUES (2, 'abcdef');
+
+-- {{Fail .Test}}
+UPDATE short SET s = 'abcd' WHERE id = 1;
+
+UPDATE short SET s = CAST('wxyz' AS varchar(3)) WHERE id = 1;
+
+SELECT * FROM short;
+
+-- {{Fail .Test}}
+SELECT s::int FROM conv WHERE id = 3;
+
+-- {{Fail .Test}}
+SELECT 'abc'::bool;
+
+-- {{Fail .Test}}
+SELECT 40000::smallint;
+
+-- {{Fail .Test}}
+SELECT '9223372036854775808'::bigint;
+
+-- {{Fail .Test}}
+SELECT f::bool FROM conv;
+
+-- {{Fail .Test}}
+SELECT b::double precision FROM conv;
+
+-- {{Fail .Test}}
+SELECT id::bytea FROM conv;
+
+-- {{Fail .Test}}
+SELECT id::integer2 FROM conv;
+
+-- {{Fail .Test}}
+SELECT CAST(id) FROM conv;
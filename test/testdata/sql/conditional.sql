@@ -0,0 +1,67 @@
+--
+-- Test conditional expressions: CASE, COALESCE, NULLIF, GREATEST, LEAST, BETWEEN, and IN lists
+--
+
+DROP TABLE IF EXISTS cond;
+
+CREATE TABLE cond (id int primary key, a int, b int, s text);
+
+INSERT INTO cond VALUES
+    (1, 1, 10, 'one'),
+    (2, 2, NULL, 'two'),
+    (3, NULL, 30, NULL),
+    (4, 4, 4, 'four'),
+    (5, NULL, NULL, 'five');
+
+SELECT id, CASE WHEN a < 2 THEN 'small' WHEN a < 4 THEN 'medium' ELSE 'large' END FROM cond;
+
+SELECT id, CASE WHEN a < 2 THEN 'small' WHEN a >= 2 THEN 'large' END AS size FROM cond;
+
+SELECT id, CASE a WHEN 1 THEN 'one' WHEN 2 THEN 'two' ELSE 'other' END FROM cond;
+
+SELECT id, CASE a WHEN NULL THEN 'null' ELSE 'not null' END FROM cond;
+
+SELECT id, CASE WHEN b = 0 THEN 0 ELSE 100 / b END FROM cond;
+
+SELECT id, CASE WHEN a > 1 THEN a ELSE 0.5 END FROM cond;
+
+SELECT id, coalesce(a, b, -1), coalesce(s, 'none') FROM cond;
+
+SELECT id, nullif(a, 4), nullif(a, b) FROM cond;
+
+SELECT id, greatest(a, b), least(a, b, 3) FROM cond;
+
+SELECT id FROM cond WHERE a BETWEEN 2 AND 4;
+
+SELECT id FROM cond WHERE a NOT BETWEEN 2 AND 4;
+
+SELECT id, a BETWEEN 1 AND b FROM cond;
+
+SELECT id FROM cond WHERE a + 1 BETWEEN 2 AND 3 AND id < 5;
+
+SELECT id FROM cond WHERE a IN (1, 4);
+
+SELECT id FROM cond WHERE a NOT IN (1, 4);
+
+SELECT id, a IN (1, b), a NOT IN (2, NULL) FROM cond;
+
+SELECT id FROM cond WHERE s IN ('one', 'four') OR b IS NULL;
+
+SELECT id FROM cond WHERE a IN ((SELECT max(a) FROM cond), 1);
+
+SELECT sum(CASE WHEN a IS NULL THEN 1 ELSE 0 END), count(*) FROM cond;
+
+-- {{Fail .Test}}
+SELECT CASE WHEN a THEN 1 END FROM cond;
+
+-- {{Fail .Test}}
+SELECT CASE WHEN a > 1 THEN 1 ELSE 'one' END FROM cond;
+
+-- {{Fail .Test}}
+SELECT coalesce(a, s) FROM cond;
+
+-- {{Fail .Test}}
+SELECT id FROM cond WHERE a IN (1, 'two');
+
+-- {{Fail .Test}}
+SELECT id FROM cond WHERE s BETWEEN 1 AND 2;
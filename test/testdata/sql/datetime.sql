@@ -0,0 +1,145 @@
+--
+-- Test DATE, TIME, TIMESTAMP, TIMESTAMPTZ, and INTERVAL
+--
+
+DROP TABLE IF EXISTS events;
+
+CREATE TABLE events (
+    id int primary key,
+    d date,
+    t time,
+    ts timestamp,
+    tstz timestamp with time zone,
+    iv interval
+);
+
+INSERT INTO events VALUES
+    (1, '2020-01-31', '12:34:56', '2020-01-31 12:34:56', '2020-01-31 12:34:56+02', '1 month'),
+    (2, '2020-02-29', '00:00:00.5', '2020-02-29T23:59:59.999', '2020-02-29 00:00:00',
+        '1 day 02:03:04'),
+    (3, '1969-07-20', '20:17:40', '1969-07-20 20:17:40', '1969-07-20 20:17:40-05',
+        '-2 hours'),
+    (4, NULL, NULL, NULL, NULL, NULL);
+
+SELECT id, d, t, ts, tstz, iv FROM events;
+
+-- {{Sort .Test false}}
+SELECT id FROM events ORDER BY ts DESC;
+
+SELECT id FROM events WHERE d > '2020-01-01' ORDER BY id;
+
+SELECT id FROM events WHERE ts BETWEEN '2020-01-01' AND '2020-02-29 23:59:59' ORDER BY id;
+
+SELECT id, d + 1, d - 1, d + iv, ts + iv, ts - iv, tstz + iv FROM events;
+
+SELECT id, t + iv, t - '01:00:00', iv * 2, iv / 2, -iv FROM events;
+
+SELECT ts - '2020-01-01', d - DATE '2020-01-01', ts - d FROM events WHERE id = 2;
+
+SELECT DATE '2020-01-31' + INTERVAL '1 month', TIMESTAMP '2020-12-31 23:00' + '2 hours';
+
+SELECT INTERVAL '1 year 2 months 3 days 04:05:06.5', INTERVAL '90 minutes',
+    INTERVAL '1.5 days', INTERVAL '@ 3 days ago';
+
+SELECT INTERVAL '1 month' = INTERVAL '30 days', INTERVAL '1 day' < INTERVAL '25 hours';
+
+SELECT '2020-03-04'::date, '2020-03-04 05:06:07.123'::timestamp, '05:06'::time,
+    CAST('2020-03-04 05:06:07-08' AS timestamptz);
+
+SELECT ts::date, ts::time, ts::timestamptz, d::timestamp, iv::text FROM events WHERE id = 1;
+
+SELECT TIMESTAMP '2020-03-04 05:06:07' = DATE '2020-03-04',
+    DATE '2020-03-04' < TIMESTAMP '2020-03-04 05:06:07';
+
+SELECT min(d), max(d), min(ts), max(iv) FROM events;
+
+SELECT greatest(d, '2020-02-01'), least(ts, '2020-02-01') FROM events WHERE id < 4;
+
+SELECT id FROM events WHERE d IN ('2020-01-31', '1969-07-20') ORDER BY id;
+
+SELECT date_trunc('month', ts), date_trunc('hour', tstz), date_trunc('week', d),
+    date_trunc('year', ts) FROM events;
+
+SELECT date_trunc('hour', INTERVAL '1 day 02:03:04'), date_trunc('year', INTERVAL '14 months');
+
+SELECT extract(year FROM ts), extract(month FROM d), extract(second FROM t),
+    extract(dow FROM d), extract(doy FROM ts) FROM events;
+
+SELECT extract(epoch FROM TIMESTAMP '1970-01-02'), extract(hour FROM INTERVAL '26 hours'),
+    date_part('day', INTERVAL '3 days 04:00'), EXTRACT(quarter FROM DATE '2020-08-01');
+
+SELECT now() > '2020-01-01', current_timestamp > TIMESTAMP '2020-01-01',
+    current_date > DATE '2020-01-01', localtimestamp > '2020-01-01',
+    now() - current_timestamp < INTERVAL '1 minute';
+
+SELECT now() = now(), now() = current_timestamp, localtimestamp = localtimestamp;
+
+CREATE TABLE started (id int primary key, ts timestamptz);
+
+BEGIN;
+
+INSERT INTO started VALUES (1, now());
+
+SELECT count(*) FROM events;
+
+INSERT INTO started VALUES (2, current_timestamp);
+
+SELECT s1.ts = s2.ts, s1.ts = now() FROM started s1, started s2 WHERE s1.id = 1 AND s2.id = 2;
+
+COMMIT;
+
+SELECT 'date: ' || DATE '2020-01-02', concat(TIME '01:02:03', ' ', INTERVAL '1 day');
+
+UPDATE events SET iv = iv + INTERVAL '1 day', d = d + 7 WHERE id = 1;
+
+SELECT id, d, iv FROM events WHERE id = 1;
+
+DROP TABLE IF EXISTS keyed;
+
+CREATE TABLE keyed (ts timestamp primary key, n int);
+
+INSERT INTO keyed VALUES
+    ('2021-06-01 12:00', 1),
+    ('1999-12-31 23:59:59', 2),
+    ('2000-01-01', 3),
+    ('1960-01-01', 4);
+
+-- {{Sort .Test false}}
+SELECT ts, n FROM keyed;
+
+SELECT n FROM keyed WHERE ts >= '2000-01-01';
+
+SELECT TIME '24:00:00', '24:00'::time > TIME '23:59:59.999999', TIMESTAMP '2020-12-31 24:00:00';
+
+SELECT DATE '0001-01-01' - 1, DATE '0044-03-15 BC',
+    TIMESTAMP '0001-01-01 00:00:00' - INTERVAL '1 hour', TIMESTAMPTZ '0010-06-01 12:00:00 BC';
+
+-- {{Fail .Test}}
+SELECT DATE '2021-02-29';
+
+-- {{Fail .Test}}
+SELECT '25:00'::time;
+
+-- {{Fail .Test}}
+SELECT INTERVAL '1 fortnight';
+
+-- {{Fail .Test}}
+SELECT DATE '2020-01-01' + TIME '01:00' + DATE '2020-01-01';
+
+-- {{Fail .Test}}
+SELECT date_trunc('fortnight', TIMESTAMP '2020-01-01');
+
+-- {{Fail .Test}}
+SELECT extract(dow FROM INTERVAL '1 day');
+
+-- {{Fail .Test}}
+SELECT 1.5::date;
+
+-- {{Fail .Test}}
+SELECT d FROM events WHERE d > 'tomorrow';
+
+-- {{Fail .Test}}
+CREATE TABLE bad (t time with time zone);
+
+-- {{Fail .Test}}
+INSERT INTO events VALUES (5, 'not a date');
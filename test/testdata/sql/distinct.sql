@@ -0,0 +1,87 @@
+--
+-- Test DISTINCT, DISTINCT ON, and DISTINCT in aggregates
+--
+
+DROP TABLE IF EXISTS tbl1;
+
+CREATE TABLE tbl1 (
+    c1 int primary key,
+    c2 int,
+    c3 text,
+    c4 int
+);
+
+INSERT INTO tbl1 VALUES
+    (1, 10, 'one', 100),
+    (2, 20, 'two', 100),
+    (3, 10, 'three', 200),
+    (4, 20, 'one', 200),
+    (5, 30, 'two', 300),
+    (6, 10, 'one', NULL),
+    (7, NULL, 'two', NULL),
+    (8, NULL, 'three', 300);
+
+SELECT DISTINCT c2 FROM tbl1;
+
+SELECT ALL c2 FROM tbl1;
+
+SELECT DISTINCT c3 FROM tbl1;
+
+SELECT DISTINCT c2, c3 FROM tbl1;
+
+SELECT DISTINCT c4 FROM tbl1;
+
+SELECT DISTINCT * FROM tbl1;
+
+SELECT DISTINCT c2 + c4 AS c FROM tbl1;
+
+SELECT DISTINCT c2 FROM tbl1 ORDER BY c2;
+
+SELECT DISTINCT c2 FROM tbl1 ORDER BY c2 LIMIT 2;
+
+SELECT DISTINCT c2 FROM tbl1 WHERE c1 > 2;
+
+SELECT DISTINCT ON (c3) c3, c1 FROM tbl1 ORDER BY c3, c1;
+
+SELECT DISTINCT ON (c3) c3, c1 FROM tbl1 ORDER BY c3, c1 DESC;
+
+SELECT DISTINCT ON (c2) c1, c3 FROM tbl1 ORDER BY c2, c1;
+
+SELECT DISTINCT ON (c2, c4) c2, c4 FROM tbl1;
+
+SELECT count(c2), count(DISTINCT c2), count(ALL c2) FROM tbl1;
+
+SELECT count(DISTINCT c3), sum(DISTINCT c4), sum(c4), avg(DISTINCT c4) FROM tbl1;
+
+SELECT c3, count(DISTINCT c2), count(c2) FROM tbl1 GROUP BY c3;
+
+SELECT DISTINCT count(c1) FROM tbl1 GROUP BY c3;
+
+SELECT DISTINCT c3, count(c1) AS cnt FROM tbl1 GROUP BY c3 ORDER BY cnt;
+
+EXPLAIN SELECT DISTINCT c2 FROM tbl1;
+
+EXPLAIN SELECT DISTINCT ON (c3) c3, c1 FROM tbl1 ORDER BY c3, c1;
+
+EXPLAIN SELECT DISTINCT ON (c2) c1, c3 FROM tbl1 ORDER BY c2, c1;
+
+EXPLAIN SELECT count(DISTINCT c2) FROM tbl1;
+
+{{Fail .Test}}
+SELECT abs(DISTINCT c2) FROM tbl1;
+
+{{Fail .Test}}
+SELECT DISTINCT ON (c5) c1 FROM tbl1;
+
+{{Fail .Test}}
+SELECT DISTINCT ON c2 c1 FROM tbl1;
+
+{{Fail .Test}}
+SELECT DISTINCT ON (c3) c3, c1 FROM tbl1 ORDER BY c1, c3;
+
+{{Fail .Test}}
+SELECT DISTINCT ON (c2, c3) c1 FROM tbl1 ORDER BY c2, c1;
+
+SELECT DISTINCT ON (c2, c3) c2, c3 FROM tbl1 ORDER BY c3, c2;
+
+SELECT DISTINCT ON (c3, c2) c2, c3, c1 FROM tbl1 ORDER BY c3;
@@ -0,0 +1,68 @@
+--
+-- Test INSERT ... SELECT, INSERT ... query, and INSERT ... DEFAULT VALUES
+--
+
+DROP TABLE IF EXISTS src;
+
+DROP TABLE IF EXISTS dst;
+
+DROP TABLE IF EXISTS dflt;
+
+CREATE TABLE src (id int primary key, name text, score int);
+
+INSERT INTO src VALUES
+    (1, 'one', 10),
+    (2, 'two', 20),
+    (3, 'three', 30),
+    (4, 'four', 40);
+
+CREATE TABLE dst (id int primary key, name text, score int DEFAULT -1);
+
+INSERT INTO dst SELECT id + 20, name, score FROM src WHERE id < 3;
+
+SELECT * FROM dst;
+
+INSERT INTO dst (id, name) SELECT id + 10, name || '!' FROM src WHERE id >= 3;
+
+SELECT * FROM dst;
+
+INSERT INTO dst (name, id) (VALUES ('five', 5), ('six', 6));
+
+INSERT INTO dst TABLE src;
+
+INSERT INTO dst WITH w AS (SELECT id + 100 AS id, name, score * 2 FROM src) SELECT * FROM w;
+
+SELECT * FROM dst;
+
+-- {{Fail .Test}}
+INSERT INTO dst SELECT id + 200, name, score, score FROM src;
+
+-- {{Fail .Test}}
+INSERT INTO dst (id, missing) SELECT id, name FROM src;
+
+-- {{Fail .Test}}
+INSERT INTO dst SELECT * FROM src;
+
+-- {{Fail .Test}}
+INSERT INTO dst (id, name, score) SELECT id + 300, name FROM src;
+
+SELECT count(*) FROM dst;
+
+INSERT INTO dst
+    WITH RECURSIVE n (i) AS (SELECT 1000 UNION ALL SELECT i + 1 FROM n WHERE i < 3999)
+    SELECT i, 'n', i % 7 FROM n;
+
+SELECT count(*), min(id), max(id), sum(score) FROM dst WHERE id >= 1000;
+
+INSERT INTO dst (id) SELECT id + 10000 FROM dst;
+
+SELECT count(*), min(score), max(score) FROM dst WHERE id >= 10000;
+
+CREATE TABLE dflt (id int DEFAULT 1 primary key, name text DEFAULT 'none', score int);
+
+INSERT INTO dflt DEFAULT VALUES;
+
+SELECT * FROM dflt;
+
+-- {{Fail .Test}}
+INSERT INTO dflt DEFAULT VALUES;
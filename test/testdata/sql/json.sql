@@ -0,0 +1,125 @@
+--
+-- Test JSON and JSONB
+--
+
+DROP TABLE IF EXISTS events;
+
+CREATE TABLE events (
+    id int primary key,
+    payload jsonb,
+    extra json
+);
+
+INSERT INTO events VALUES
+    (1, '{"type": "click", "user": {"id": 7, "name": "ann"}, "tags": ["a", "b"]}', '[1, 2]'),
+    (2, '{"type": "view", "user": {"id": 8}, "tags": [], "score": 1.50}', '{"b": 1, "a": 2}'),
+    (3, '{"type": "click", "user": {"id": 7}, "tags": ["b"], "type": "tap"}', 'null'),
+    (4, '[1, "two", null, true]', '"text"'),
+    (5, NULL, NULL);
+
+SELECT id, payload, extra FROM events;
+
+SELECT '{"b": 1,  "a": [1,2], "b": 2}'::json, '{"b": 1,  "a": [1,2], "b": 2}'::jsonb;
+
+SELECT '{"b":1, "a":2}'::json::jsonb, '{"b":1, "a":2}'::jsonb::json, JSON ' [1, 2] ';
+
+SELECT id, payload -> 'type', payload ->> 'type', payload -> 'user' -> 'id',
+    payload #> '{user,name}', payload #>> '{tags,0}' FROM events;
+
+SELECT id, payload -> 0, payload ->> 1, payload -> -1, payload -> 'missing' FROM events
+    WHERE id = 4;
+
+SELECT id FROM events WHERE payload @> '{"type": "click"}' ORDER BY id;
+
+SELECT id FROM events WHERE payload @> '{"user": {"id": 7}, "tags": ["b"]}' ORDER BY id;
+
+SELECT id FROM events WHERE payload ? 'score' OR payload ? 'two' ORDER BY id;
+
+SELECT id, jsonb_typeof(payload), json_typeof(extra) FROM events;
+
+SELECT (payload -> 'user') || '{"admin": true}', extra || '[3]', extra || (payload -> 'tags')
+    FROM events WHERE id = 1;
+
+SELECT jsonb_build_object('id', id, 'type', payload ->> 'type', 'score', payload -> 'score',
+    'none', NULL) FROM events WHERE id < 4;
+
+SELECT jsonb_agg(id), jsonb_agg(payload ->> 'type') FROM events;
+
+SELECT payload ->> 'type', jsonb_agg(id) FROM events WHERE id < 4 GROUP BY payload ->> 'type';
+
+SELECT jsonb_agg(id) FROM events WHERE id > 10;
+
+SELECT * FROM jsonb_array_elements('[1, "a", {"b": [2]}, null]');
+
+SELECT e.e, jsonb_typeof(e) FROM jsonb_array_elements('[true, 1.5]') AS e;
+
+SELECT v FROM jsonb_array_elements('[1, "a"]') AS v;
+
+SELECT id, tag FROM events, jsonb_array_elements(payload -> 'tags') AS t (tag)
+    WHERE id < 4;
+
+SELECT id, tag FROM events LEFT JOIN jsonb_array_elements(payload -> 'tags') AS t (tag)
+    ON true WHERE id < 4;
+
+SELECT count(*) FROM events e1
+    WHERE EXISTS (SELECT * FROM jsonb_array_elements(e1.payload -> 'tags') AS t (tag)
+        WHERE tag = '"b"');
+
+UPDATE events SET payload = payload || jsonb_build_object('seen', true) WHERE id = 2;
+
+SELECT payload FROM events WHERE id = 2;
+
+SELECT payload FROM events WHERE payload = '{"type": "view", "user": {"id": 8}, "tags": [],
+    "seen": true, "score": 1.5}';
+
+SELECT JSONB '{"a": 1}' = '{"a": 1.0}', JSON '[1, 2]' < '[1, 3]', '"a"'::jsonb < '1'::jsonb;
+
+SELECT '{"n": 12.5}'::jsonb -> 'n' > '12'::jsonb, ('{"n": 12.5}'::jsonb -> 'n')::numeric + 1,
+    ('{"b": true}'::jsonb -> 'b')::boolean, ('{"a": [1]}'::jsonb)::text;
+
+DROP TABLE IF EXISTS docs;
+
+CREATE TABLE docs (doc jsonb primary key, label text);
+
+INSERT INTO docs VALUES
+    ('{"a": 1}', 'object a'),
+    ('[1, 2]', 'array'),
+    ('"str"', 'string'),
+    ('10', 'ten'),
+    ('2.5', 'two and a half'),
+    ('null', 'null'),
+    ('{}', 'empty object'),
+    ('true', 'true'),
+    ('[]', 'empty array');
+
+-- {{Sort .Test false}}
+SELECT doc, label FROM docs;
+
+SELECT label FROM docs WHERE doc = '{"a": 1.0}';
+
+-- {{Fail .Test}}
+INSERT INTO docs VALUES ('{"a": 1}', 'duplicate');
+
+-- {{Fail .Test}}
+INSERT INTO events VALUES (6, '{"a": }', NULL);
+
+-- {{Fail .Test}}
+SELECT '[1, 2'::jsonb;
+
+-- {{Fail .Test}}
+SELECT ('{"a": "x"}'::jsonb -> 'a')::int;
+
+-- {{Fail .Test}}
+SELECT * FROM jsonb_array_elements('{"a": 1}');
+
+-- {{Fail .Test}}
+SELECT jsonb_build_object('a', 1, 'b');
+
+-- {{Fail .Test}}
+SELECT jsonb_build_object(NULL, 1);
+
+-- {{Fail .Test}}
+SELECT '{"a": 1}'::jsonb #> 'a';
+
+-- {{Fail .Test}}
+SELECT * FROM unknown_function(1);
@@ -0,0 +1,89 @@
+--
+-- Test pattern matching: LIKE, ILIKE, SIMILAR TO, and regular expressions
+--
+-- {{Sort .Global false}}
+
+DROP TABLE IF EXISTS words;
+
+CREATE TABLE words (word text primary key, n int, tag text);
+
+CREATE INDEX words_tag ON words (tag);
+
+INSERT INTO words VALUES
+    ('abacus', 1, 'tool'),
+    ('abbey', 2, 'place'),
+    ('abc', 3, NULL),
+    ('Abel', 4, 'name'),
+    ('about', 5, 'word'),
+    ('a_b', 6, 'odd'),
+    ('a%b', 7, 'odd'),
+    ('bacon', 8, 'food'),
+    ('cabbage', 9, 'food'),
+    ('zebra', 10, 'animal');
+
+SELECT word FROM words WHERE word LIKE 'ab%';
+
+SELECT word FROM words WHERE word NOT LIKE 'ab%';
+
+SELECT word FROM words WHERE word ILIKE 'ab%';
+
+SELECT word FROM words WHERE word NOT ILIKE '%E%';
+
+SELECT word FROM words WHERE word LIKE '_b%';
+
+SELECT word FROM words WHERE word LIKE 'a\_b';
+
+SELECT word FROM words WHERE word LIKE 'a!%b' ESCAPE '!';
+
+SELECT word FROM words WHERE word LIKE 'a%' AND word LIKE '%b';
+
+SELECT word, tag LIKE 'o%' FROM words;
+
+SELECT word FROM words WHERE word SIMILAR TO '(ab|ca)%';
+
+SELECT word FROM words WHERE word SIMILAR TO '[a-c]{3}';
+
+SELECT word FROM words WHERE word NOT SIMILAR TO '%(s|y)%';
+
+SELECT word FROM words WHERE word ~ 'b{2}';
+
+SELECT word FROM words WHERE word ~* '^ab';
+
+SELECT word FROM words WHERE word !~ '^[a-c]';
+
+SELECT word FROM words WHERE word !~* 'E';
+
+SELECT word FROM words WHERE 'cabbage and bacon' LIKE '%' || word || '%';
+
+-- {{Fail .Test}}
+SELECT word FROM words WHERE n LIKE '1%';
+
+-- {{Fail .Test}}
+SELECT word FROM words WHERE word ~ 'a(';
+
+-- {{Fail .Test}}
+SELECT word FROM words WHERE word LIKE 'a' ESCAPE '!!';
+
+SET pushdown_where = true;
+
+EXPLAIN SELECT word FROM words WHERE word LIKE 'ab%';
+
+SELECT word FROM words WHERE word LIKE 'ab%';
+
+SELECT word FROM words WHERE word LIKE 'abc%';
+
+SELECT word FROM words WHERE word LIKE 'ab%s';
+
+SELECT word FROM words WHERE word LIKE 'a\%%';
+
+EXPLAIN SELECT word, tag FROM words@words_tag WHERE tag LIKE 'fo%';
+
+SELECT word, tag FROM words@words_tag WHERE tag LIKE 'fo%';
+
+SELECT word, tag FROM words@words_tag WHERE tag LIKE 'o%' AND word LIKE '%b';
+
+SET pushdown_where = false;
+
+EXPLAIN SELECT word FROM words WHERE word LIKE 'ab%';
+
+SELECT word FROM words WHERE word LIKE 'ab%';
@@ -0,0 +1,70 @@
+--
+-- Test LIMIT and OFFSET
+--
+-- {{Sort .Global false}}
+
+DROP TABLE IF EXISTS tbl1;
+
+CREATE TABLE tbl1 (
+    c1 int primary key,
+    c2 int,
+    c3 text
+);
+
+INSERT INTO tbl1 VALUES
+    (1, 50, 'one'),
+    (2, 40, 'two'),
+    (3, 30, 'three'),
+    (4, 20, 'four'),
+    (5, 10, 'five'),
+    (6, 60, 'six'),
+    (7, 70, 'seven'),
+    (8, 80, 'eight');
+
+SELECT * FROM tbl1 LIMIT 3;
+
+SELECT * FROM tbl1 LIMIT 3 OFFSET 2;
+
+SELECT * FROM tbl1 OFFSET 6;
+
+SELECT * FROM tbl1 LIMIT 0;
+
+SELECT * FROM tbl1 LIMIT ALL OFFSET 7;
+
+SELECT * FROM tbl1 LIMIT 2 OFFSET 20;
+
+SELECT * FROM tbl1 LIMIT NULL;
+
+SELECT * FROM tbl1 ORDER BY c2 LIMIT 3;
+
+SELECT * FROM tbl1 ORDER BY c2 DESC LIMIT 3 OFFSET 1;
+
+SELECT c1, c3 FROM tbl1 ORDER BY c2 LIMIT 2 OFFSET 2;
+
+SELECT * FROM tbl1 ORDER BY c2 LIMIT 100;
+
+SELECT c2 / 20 AS c, count(*) FROM tbl1 GROUP BY c2 / 20 ORDER BY c LIMIT 2;
+
+SELECT * FROM tbl1 LIMIT 1 + 1;
+
+EXPLAIN SELECT * FROM tbl1 LIMIT 3 OFFSET 2;
+
+EXPLAIN SELECT * FROM tbl1 ORDER BY c2 LIMIT 3 OFFSET 2;
+
+EXPLAIN SELECT c1 FROM tbl1 ORDER BY c2 DESC LIMIT 2;
+
+SELECT * FROM (SELECT * FROM tbl1 ORDER BY c2 LIMIT 4) AS t LIMIT 2 OFFSET 1;
+
+VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd') LIMIT 2 OFFSET 1;
+
+{{Fail .Test}}
+SELECT * FROM tbl1 LIMIT -1;
+
+{{Fail .Test}}
+SELECT * FROM tbl1 OFFSET -1;
+
+{{Fail .Test}}
+SELECT * FROM tbl1 LIMIT 'abc';
+
+{{Fail .Test}}
+SELECT * FROM tbl1 LIMIT c1;
@@ -0,0 +1,131 @@
+--
+-- Test NUMERIC and DECIMAL
+--
+
+DROP TABLE IF EXISTS accounts;
+
+CREATE TABLE accounts (
+    id int primary key,
+    balance numeric(12,2),
+    rate decimal(6, 4),
+    amount numeric
+);
+
+INSERT INTO accounts VALUES
+    (1, 100.25, '0.0125', '12345678901234567890.123456789'),
+    (2, '2500', 0.035, -1),
+    (3, -15.5, '1.23456', 0.1),
+    (4, NULL, NULL, NULL);
+
+SELECT id, balance, rate, amount FROM accounts;
+
+SELECT id, balance + 0.01, balance - 100, balance * rate, balance * 2, -balance FROM accounts;
+
+SELECT id, balance / 3, amount / 7, balance % 7 FROM accounts WHERE id < 4;
+
+SELECT '0.1'::numeric + '0.2'::numeric = '0.3'::numeric, 0.1 + 0.2 = 0.3;
+
+SELECT NUMERIC '1.50' = NUMERIC '1.5', NUMERIC '1.50', NUMERIC '-0.005', NUMERIC '1e3',
+    NUMERIC '2.5e-3';
+
+SELECT '2.5'::numeric(3,0), '-2.5'::numeric(3,0), '1.005'::numeric(4,2), 123.456::numeric(6,1);
+
+SELECT NUMERIC '1' / NUMERIC '3', NUMERIC '10' / NUMERIC '4', NUMERIC '1.000' / 8,
+    NUMERIC '123456789' / NUMERIC '0.001';
+
+SELECT NUMERIC '2.5'::int, NUMERIC '-2.5'::int, NUMERIC '0.125'::double precision,
+    NUMERIC '12.340'::text, 7::numeric(5,2);
+
+SELECT id FROM accounts WHERE balance > 100 ORDER BY id;
+
+SELECT id FROM accounts WHERE balance = 2500 OR amount < 0.5 ORDER BY id;
+
+SELECT NUMERIC '1.5' < 2, 2.5 > NUMERIC '2.49', NUMERIC '3' = 3.0, abs(NUMERIC '-7.25');
+
+SELECT sum(balance), avg(balance), min(rate), max(amount), count(balance) FROM accounts;
+
+SELECT sum(rate * 100), avg(id::numeric) FROM accounts;
+
+UPDATE accounts SET balance = balance * 1.015 WHERE id = 1;
+
+SELECT id, balance FROM accounts WHERE id = 1;
+
+DROP TABLE IF EXISTS prices;
+
+CREATE TABLE prices (price numeric(8,3) primary key, label text);
+
+INSERT INTO prices VALUES
+    (10.5, 'ten and a half'),
+    (-0.001, 'tiny negative'),
+    (0, 'zero'),
+    (9.999, 'almost ten'),
+    (-100, 'minus one hundred'),
+    (100, 'one hundred'),
+    (0.01, 'one cent');
+
+-- {{Sort .Test false}}
+SELECT price, label FROM prices;
+
+SELECT label FROM prices WHERE price = 10.500;
+
+SELECT label FROM prices WHERE price = 100;
+
+SELECT label FROM prices WHERE price = '-100';
+
+SELECT label FROM prices WHERE price = '9.999';
+
+SELECT label FROM prices WHERE price = 10;
+
+DROP TABLE IF EXISTS quantities, weights;
+
+CREATE TABLE quantities (id int primary key, qty numeric(6,2));
+
+CREATE INDEX quantities_qty ON quantities (qty);
+
+INSERT INTO quantities VALUES (1, 5), (2, 7.25), (3, 10);
+
+SELECT id FROM quantities WHERE qty = 10;
+
+SELECT id FROM quantities WHERE qty = '7.25';
+
+SELECT id FROM quantities WHERE id = 2.0;
+
+SELECT id FROM quantities WHERE id = 2.5;
+
+CREATE TABLE weights (weight double precision primary key, label text);
+
+INSERT INTO weights VALUES (2.5, 'two and a half'), (3, 'three');
+
+SELECT label FROM weights WHERE weight = 2.5;
+
+SELECT label FROM weights WHERE weight = 3;
+
+CREATE TABLE amounts (id int primary key, amount numeric);
+
+INSERT INTO amounts VALUES (1, 1), (2, 1.0), (3, 1.00), (4, 2.5), (5, 2.50);
+
+SELECT DISTINCT amount FROM amounts ORDER BY amount;
+
+SELECT amount, count(*) FROM amounts GROUP BY amount ORDER BY amount;
+
+SELECT count(DISTINCT amount) FROM amounts;
+
+SELECT amount FROM amounts WHERE id = 1 UNION SELECT amount FROM amounts WHERE id = 2;
+
+-- {{Fail .Test}}
+INSERT INTO prices VALUES (10.5, 'duplicate');
+
+-- {{Fail .Test}}
+INSERT INTO prices VALUES (123456.5, 'too big');
+
+-- {{Fail .Test}}
+SELECT NUMERIC '1' / 0;
+
+-- {{Fail .Test}}
+SELECT 'abc'::numeric;
+
+-- {{Fail .Test}}
+SELECT '1e400'::numeric::int;
+
+-- {{Fail .Test}}
+CREATE TABLE bad (n numeric(3,4));
@@ -0,0 +1,57 @@
+--
+-- Test INSERT, UPDATE, and DELETE with RETURNING
+--
+
+DROP TABLE IF EXISTS items;
+
+DROP TABLE IF EXISTS notes;
+
+CREATE TABLE items (id int PRIMARY KEY, name text, price double precision DEFAULT 1.5, qty int DEFAULT 0);
+
+INSERT INTO items VALUES (1, 'apple', 0.5, 10), (2, 'banana', 0.25, 20) RETURNING *;
+
+INSERT INTO items (id, name) VALUES (3, 'cherry') RETURNING id, price, qty;
+
+INSERT INTO items (id, name, price) VALUES (4, 'date', 2) RETURNING price, price * 2 AS double;
+
+INSERT INTO items SELECT id + 10, name || '!', price, qty FROM items WHERE id < 3
+    RETURNING items.*;
+
+INSERT INTO items VALUES (1, 'apricot', 0.75, 5), (5, 'elderberry', 3, 1)
+    ON CONFLICT (id) DO UPDATE SET name = excluded.name RETURNING id, name, price;
+
+INSERT INTO items VALUES (1, 'avocado'), (6, 'fig') ON CONFLICT DO NOTHING RETURNING name;
+
+UPDATE items SET qty = qty + 1 WHERE id < 3 RETURNING id, qty;
+
+UPDATE items SET price = 1 WHERE id = 3 RETURNING price;
+
+UPDATE items SET name = 'none' WHERE id = 99 RETURNING *;
+
+DELETE FROM items WHERE id > 10 RETURNING id, name;
+
+SELECT * FROM items;
+
+{{Fail .Test}}
+INSERT INTO items VALUES (7, 'grape') RETURNING missing;
+
+{{Fail .Test}}
+DELETE FROM items RETURNING other.*;
+
+{{Fail .Test}}
+UPDATE items SET qty = 1 RETURNING count(*);
+
+{{Fail .Test}}
+INSERT INTO items VALUES (1, 'apple') RETURNING *;
+
+SELECT COUNT(*) FROM items;
+
+CREATE TABLE notes (note text);
+
+INSERT INTO notes VALUES ('first'), ('second') RETURNING rowid > 0 AS has_rowid, note;
+
+INSERT INTO notes DEFAULT VALUES RETURNING rowid;
+
+DELETE FROM notes RETURNING *;
+
+SELECT COUNT(*) FROM notes;
@@ -0,0 +1,145 @@
+--
+-- Test sequences, SERIAL, and identity columns
+--
+
+DROP TABLE IF EXISTS seq_serial, seq_ident, seq_always;
+
+DROP SEQUENCE IF EXISTS seq_a, seq_down, seq_cycle, seq_cache;
+
+CREATE SEQUENCE seq_a;
+
+SELECT nextval('seq_a'), nextval('seq_a');
+
+SELECT currval('seq_a');
+
+{{Fail .Test}}
+CREATE SEQUENCE seq_a;
+
+CREATE SEQUENCE IF NOT EXISTS seq_a;
+
+CREATE SEQUENCE seq_down INCREMENT BY -2 MINVALUE -4 MAXVALUE 10 START WITH 3;
+
+SELECT nextval('seq_down');
+
+SELECT nextval('seq_down');
+
+SELECT nextval('seq_down');
+
+SELECT nextval('seq_down');
+
+{{Fail .Test}}
+SELECT nextval('seq_down');
+
+CREATE SEQUENCE seq_cycle INCREMENT 5 MINVALUE 1 MAXVALUE 12 CYCLE;
+
+SELECT nextval('seq_cycle'), nextval('seq_cycle'), nextval('seq_cycle'), nextval('seq_cycle');
+
+SELECT setval('seq_a', 100);
+
+SELECT nextval('seq_a');
+
+SELECT setval('seq_a', 200, false);
+
+SELECT currval('seq_a'), nextval('seq_a');
+
+ALTER SEQUENCE seq_a INCREMENT BY 10 RESTART WITH 50;
+
+SELECT nextval('seq_a'), nextval('seq_a');
+
+CREATE SEQUENCE seq_cache INCREMENT BY 2 CACHE 5;
+
+SELECT nextval('seq_cache'), nextval('seq_cache');
+
+SELECT setval('seq_cache', 20);
+
+SELECT nextval('seq_cache'), currval('seq_cache');
+
+ALTER SEQUENCE seq_cache INCREMENT BY 100;
+
+SELECT nextval('seq_cache');
+
+{{Fail .Test}}
+CREATE SEQUENCE seq_bad CACHE 0;
+
+{{Fail .Test}}
+SELECT setval('seq_down', 100);
+
+{{Fail .Test}}
+CREATE SEQUENCE seq_bad INCREMENT BY 0;
+
+{{Fail .Test}}
+SELECT nextval('seq_none');
+
+{{Fail .Test}}
+SELECT * FROM seq_a;
+
+SELECT table_name, table_type FROM metadata.tables
+    WHERE table_name LIKE 'seq_%' ORDER BY table_name;
+
+DROP SEQUENCE seq_a, seq_down, seq_cycle, seq_cache;
+
+{{Fail .Test}}
+SELECT nextval('seq_a');
+
+CREATE TABLE seq_serial (id serial PRIMARY KEY, name text);
+
+INSERT INTO seq_serial (name) VALUES ('one'), ('two');
+
+INSERT INTO seq_serial VALUES (DEFAULT, 'three');
+
+INSERT INTO seq_serial VALUES (10, 'ten');
+
+SELECT * FROM seq_serial ORDER BY id;
+
+SELECT currval('seq_serial_id_seq');
+
+{{Fail .Test}}
+DROP SEQUENCE seq_serial_id_seq;
+
+CREATE TABLE seq_ident (id bigint GENERATED BY DEFAULT AS IDENTITY (START WITH 100 INCREMENT BY 10),
+    name text);
+
+INSERT INTO seq_ident (name) VALUES ('a'), ('b');
+
+SELECT id, name FROM seq_ident ORDER BY id;
+
+{{Fail .Test}}
+INSERT INTO seq_ident VALUES (NULL, 'null');
+
+CREATE TABLE seq_always (id int GENERATED ALWAYS AS IDENTITY, name text);
+
+INSERT INTO seq_always (name) VALUES ('x');
+
+INSERT INTO seq_always VALUES (DEFAULT, 'y');
+
+{{Fail .Test}}
+INSERT INTO seq_always VALUES (5, 'z');
+
+{{Fail .Test}}
+UPDATE seq_always SET id = 5;
+
+UPDATE seq_always SET id = DEFAULT WHERE name = 'x';
+
+SELECT id, name FROM seq_always ORDER BY id;
+
+{{Fail .Test}}
+CREATE TABLE seq_bad (id text GENERATED ALWAYS AS IDENTITY);
+
+{{Fail .Test}}
+CREATE TABLE seq_bad (id int DEFAULT 1 GENERATED ALWAYS AS IDENTITY);
+
+ALTER TABLE seq_serial RENAME TO seq_renamed;
+
+INSERT INTO seq_renamed (name) VALUES ('four');
+
+SELECT * FROM seq_renamed ORDER BY id;
+
+ALTER TABLE seq_ident DROP COLUMN id;
+
+SELECT table_name, table_type FROM metadata.tables
+    WHERE table_name LIKE 'seq_%' ORDER BY table_name;
+
+DROP TABLE seq_renamed, seq_ident, seq_always;
+
+SELECT table_name, table_type FROM metadata.tables
+    WHERE table_name LIKE 'seq_%' ORDER BY table_name;
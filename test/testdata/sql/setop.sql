@@ -0,0 +1,119 @@
+--
+-- Test UNION, INTERSECT, and EXCEPT
+--
+
+DROP TABLE IF EXISTS tbl1;
+DROP TABLE IF EXISTS tbl2;
+
+CREATE TABLE tbl1 (c1 int primary key, c2 int, c3 text);
+CREATE TABLE tbl2 (c1 int primary key, c2 int, c3 text);
+
+INSERT INTO tbl1 VALUES
+    (1, 10, 'one'),
+    (2, 20, 'two'),
+    (3, 20, 'three'),
+    (4, 30, 'four'),
+    (5, NULL, 'five');
+
+INSERT INTO tbl2 VALUES
+    (1, 20, 'one'),
+    (2, 20, 'two'),
+    (3, 40, 'three'),
+    (6, NULL, 'six');
+
+SELECT c2 FROM tbl1 UNION SELECT c2 FROM tbl2;
+
+SELECT c2 FROM tbl1 UNION ALL SELECT c2 FROM tbl2;
+
+SELECT c2 FROM tbl1 UNION DISTINCT SELECT c2 FROM tbl2;
+
+SELECT c2 FROM tbl1 INTERSECT SELECT c2 FROM tbl2;
+
+SELECT c2 FROM tbl1 INTERSECT ALL SELECT c2 FROM tbl2;
+
+SELECT c2 FROM tbl1 EXCEPT SELECT c2 FROM tbl2;
+
+SELECT c2 FROM tbl1 EXCEPT ALL SELECT c2 FROM tbl2;
+
+SELECT c2 FROM tbl2 EXCEPT ALL SELECT c2 FROM tbl1;
+
+SELECT c1, c3 FROM tbl1 INTERSECT SELECT c1, c3 FROM tbl2;
+
+SELECT * FROM tbl1 UNION SELECT * FROM tbl2;
+
+SELECT * FROM (TABLE tbl1 EXCEPT TABLE tbl2) AS s;
+
+-- INTERSECT binds more tightly than UNION and EXCEPT.
+SELECT c1 FROM tbl1 UNION SELECT c1 FROM tbl2 INTERSECT SELECT c1 FROM tbl1;
+
+SELECT c1 FROM tbl1 EXCEPT SELECT c1 FROM tbl2 EXCEPT SELECT 5;
+
+SELECT c1 FROM tbl1 EXCEPT (SELECT c1 FROM tbl2 EXCEPT SELECT 1);
+
+SELECT * FROM ((SELECT c1 FROM tbl1 UNION SELECT c1 FROM tbl2) INTERSECT SELECT c1 FROM tbl1) AS s;
+
+VALUES (1, 'one'), (2, 'two') UNION VALUES (2, 'two'), (3, 'three');
+
+SELECT c2 FROM tbl1 UNION VALUES (1.5), (2.5);
+
+SELECT c3 FROM tbl1 UNION ALL SELECT NULL;
+
+SELECT * FROM (SELECT c1 FROM tbl1 UNION SELECT c1 FROM tbl2) AS s;
+
+SELECT * FROM ((SELECT c1 FROM tbl1) UNION (SELECT c1 FROM tbl2)) AS s;
+
+SELECT * FROM ((SELECT c1 FROM tbl1) AS s1 JOIN tbl2 ON s1.c1 = tbl2.c1);
+
+SELECT c1 FROM tbl1 WHERE c1 IN (SELECT c1 FROM tbl2 EXCEPT SELECT 2);
+
+SELECT c1 FROM tbl1 WHERE c1 IN ((SELECT 1) UNION (SELECT 4));
+
+SELECT c1 FROM tbl1 WHERE EXISTS (SELECT 1 INTERSECT SELECT 1);
+
+SELECT c1 FROM tbl1 WHERE NOT EXISTS (SELECT 1 INTERSECT SELECT 2);
+
+SELECT ((SELECT 1) + 2) AS c;
+
+SELECT ((SELECT 1) UNION (SELECT 1)) AS c;
+
+-- {{Sort .Global false}}
+
+SELECT c2 FROM tbl1 UNION SELECT c2 FROM tbl2 ORDER BY c2;
+
+SELECT c2 FROM tbl1 UNION ALL SELECT c2 FROM tbl2 ORDER BY c2 DESC LIMIT 3;
+
+SELECT c1 FROM tbl1 UNION SELECT c1 FROM tbl2 ORDER BY c1 LIMIT 2 OFFSET 2;
+
+SELECT c1 FROM tbl1 UNION (SELECT c1 FROM tbl2 ORDER BY c1 DESC LIMIT 1) ORDER BY c1;
+
+SELECT * FROM ((SELECT c1 FROM tbl1 ORDER BY c1 DESC LIMIT 2) UNION ALL
+    (SELECT c1 FROM tbl2 ORDER BY c1 LIMIT 2)) AS s;
+
+EXPLAIN SELECT c2 FROM tbl1 UNION SELECT c2 FROM tbl2 ORDER BY c2 LIMIT 3;
+
+EXPLAIN SELECT c2 FROM tbl1 INTERSECT ALL VALUES (10), (20);
+
+SELECT 1 UNION ALL SELECT 2.5 UNION ALL SELECT 1.5::double precision;
+
+SELECT 1 UNION SELECT 1::double precision UNION SELECT 2.5;
+
+SELECT 2 INTERSECT SELECT 2::double precision;
+
+SELECT DATE '2020-01-01' UNION SELECT TIMESTAMP '2020-01-01 00:00:00';
+
+SELECT DATE '2020-01-01' EXCEPT SELECT TIMESTAMP '2020-01-01 00:00:00';
+
+{{Fail .Test}}
+SELECT c1, c2 FROM tbl1 UNION SELECT c1 FROM tbl2;
+
+{{Fail .Test}}
+SELECT c1 FROM tbl1 UNION SELECT c3 FROM tbl2;
+
+{{Fail .Test}}
+SELECT c1 FROM tbl1 ORDER BY c1 UNION SELECT c1 FROM tbl2;
+
+{{Fail .Test}}
+SELECT c1 FROM tbl1 UNION SELECT c1 FROM tbl2 ORDER BY c2;
+
+{{Fail .Test}}
+SELECT c1 FROM tbl1 UNION;
@@ -0,0 +1,81 @@
+--
+-- Test TRUNCATE TABLE
+--
+
+DROP TABLE IF EXISTS trunc_child;
+
+DROP TABLE IF EXISTS trunc_parent;
+
+DROP TABLE IF EXISTS trunc_tbl;
+
+CREATE TABLE trunc_tbl (id int PRIMARY KEY, name text UNIQUE);
+
+INSERT INTO trunc_tbl VALUES (1, 'one'), (2, 'two'), (3, 'three');
+
+TRUNCATE trunc_tbl;
+
+SELECT * FROM trunc_tbl;
+
+INSERT INTO trunc_tbl VALUES (1, 'one'), (4, 'four');
+
+SELECT * FROM trunc_tbl;
+
+SELECT * FROM trunc_tbl WHERE name = 'one';
+
+BEGIN;
+
+TRUNCATE TABLE trunc_tbl;
+
+SELECT * FROM trunc_tbl;
+
+INSERT INTO trunc_tbl VALUES (5, 'five');
+
+SELECT * FROM trunc_tbl;
+
+ROLLBACK;
+
+SELECT * FROM trunc_tbl;
+
+{{Fail .Test}}
+TRUNCATE trunc_missing;
+
+CREATE TABLE trunc_parent (id int PRIMARY KEY, name text);
+
+CREATE TABLE trunc_child (id int PRIMARY KEY, pid int REFERENCES trunc_parent);
+
+INSERT INTO trunc_parent VALUES (1, 'one'), (2, 'two');
+
+INSERT INTO trunc_child VALUES (10, 1), (20, 2);
+
+{{Fail .Test}}
+TRUNCATE trunc_parent;
+
+{{Fail .Test}}
+TRUNCATE trunc_parent RESTRICT;
+
+SELECT * FROM trunc_parent;
+
+TRUNCATE trunc_child;
+
+SELECT * FROM trunc_child;
+
+INSERT INTO trunc_child VALUES (10, 1), (20, 2);
+
+TRUNCATE trunc_parent, trunc_child;
+
+SELECT * FROM trunc_parent;
+
+SELECT * FROM trunc_child;
+
+INSERT INTO trunc_parent VALUES (1, 'one'), (2, 'two');
+
+INSERT INTO trunc_child VALUES (10, 1), (20, 2);
+
+TRUNCATE trunc_parent CASCADE;
+
+SELECT * FROM trunc_parent;
+
+SELECT * FROM trunc_child;
+
+{{Fail .Test}}
+INSERT INTO trunc_child VALUES (30, 3);
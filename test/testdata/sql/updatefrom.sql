@@ -0,0 +1,94 @@
+--
+-- Test UPDATE ... FROM and DELETE ... USING
+--
+
+DROP TABLE IF EXISTS orders;
+
+DROP TABLE IF EXISTS items;
+
+DROP TABLE IF EXISTS prices;
+
+DROP TABLE IF EXISTS customers;
+
+CREATE TABLE items (id int PRIMARY KEY, name text, price int);
+
+CREATE TABLE prices (name text PRIMARY KEY, price int);
+
+INSERT INTO items VALUES
+    (1, 'apple', 10),
+    (2, 'banana', 20),
+    (3, 'cherry', 30),
+    (4, 'date', 40);
+
+INSERT INTO prices VALUES
+    ('apple', 11),
+    ('cherry', 33),
+    ('fig', 66);
+
+UPDATE items SET price = prices.price FROM prices WHERE items.name = prices.name;
+
+SELECT * FROM items;
+
+UPDATE items SET price = p.price * 2, name = p.name || '!' FROM prices AS p
+    WHERE items.name = p.name AND p.price > 20;
+
+SELECT * FROM items;
+
+INSERT INTO prices VALUES ('banana', 22), ('banana2', 23);
+
+{{Fail .Test}}
+UPDATE items SET price = prices.price FROM prices WHERE items.name = 'banana';
+
+{{Fail .Test}}
+UPDATE items SET price = prices.price FROM prices WHERE items.name = prices.name AND id;
+
+{{Fail .Test}}
+UPDATE items SET price = other.price FROM prices WHERE items.name = prices.name;
+
+{{Fail .Test}}
+UPDATE items SET prices.price = 1 FROM prices WHERE items.name = prices.name;
+
+UPDATE items SET price = 0 FROM prices WHERE items.name = prices.name AND items.id > 10;
+
+SELECT * FROM items;
+
+DELETE FROM items USING prices WHERE items.name = prices.name;
+
+SELECT * FROM items;
+
+{{Fail .Test}}
+DELETE FROM items USING prices WHERE items.price > prices.price;
+
+SELECT * FROM items;
+
+DELETE FROM prices USING items, prices AS p2
+    WHERE prices.name = p2.name AND p2.price > items.price;
+
+SELECT * FROM prices;
+
+CREATE TABLE customers (id int PRIMARY KEY, name text);
+
+CREATE TABLE orders (id int PRIMARY KEY, cid int REFERENCES customers, total int);
+
+INSERT INTO customers VALUES (1, 'alice'), (2, 'bob'), (3, 'carol');
+
+INSERT INTO orders VALUES (10, 1, 100), (20, 2, 200), (30, 1, 300);
+
+{{Fail .Test}}
+DELETE FROM customers USING orders WHERE customers.id = orders.cid AND orders.total = 200;
+
+{{Fail .Test}}
+UPDATE orders SET cid = customers.id + 10 FROM customers WHERE customers.name = 'bob'
+    AND orders.cid = customers.id;
+
+UPDATE orders SET cid = customers.id FROM customers WHERE customers.name = 'carol'
+    AND orders.id = 20;
+
+DELETE FROM customers USING orders WHERE customers.id = orders.cid AND orders.total = 200
+    AND customers.name = 'bob';
+
+DELETE FROM customers USING (VALUES (2)) AS v (id) WHERE customers.id = v.id;
+
+SELECT * FROM customers;
+
+SELECT * FROM orders;
@@ -0,0 +1,107 @@
+--
+-- Test INSERT ... ON CONFLICT
+--
+
+DROP TABLE IF EXISTS child;
+
+DROP TABLE IF EXISTS parent;
+
+DROP TABLE IF EXISTS cnts;
+
+CREATE TABLE cnts (id int PRIMARY KEY, name text CONSTRAINT name_unique UNIQUE, cnt int);
+
+INSERT INTO cnts VALUES
+    (1, 'one', 1),
+    (2, 'two', 2),
+    (3, 'three', 3);
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (1, 'uno', 10);
+
+INSERT INTO cnts VALUES (1, 'uno', 10) ON CONFLICT DO NOTHING;
+
+INSERT INTO cnts VALUES (4, 'one', 10) ON CONFLICT DO NOTHING;
+
+INSERT INTO cnts VALUES (1, 'uno', 10), (5, 'five', 5) ON CONFLICT (id) DO NOTHING;
+
+SELECT * FROM cnts;
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (6, 'one', 10) ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO cnts VALUES (6, 'one', 10) ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO cnts VALUES (1, 'one', 100) ON CONFLICT (id) DO UPDATE SET cnt = cnts.cnt + excluded.cnt;
+
+INSERT INTO cnts VALUES (20, 'two', 200)
+    ON CONFLICT ON CONSTRAINT name_unique DO UPDATE SET cnt = excluded.cnt;
+
+INSERT INTO cnts VALUES (3, 'drei', 300)
+    ON CONFLICT ON CONSTRAINT "primary" DO UPDATE SET name = excluded.name, cnt = DEFAULT;
+
+INSERT INTO cnts VALUES (7, 'seven', 7), (5, 'fünf', 50)
+    ON CONFLICT (id) DO UPDATE SET name = excluded.name WHERE excluded.cnt > 100;
+
+INSERT INTO cnts VALUES (5, 'fünf', 500)
+    ON CONFLICT (id) DO UPDATE SET name = excluded.name WHERE excluded.cnt > 100;
+
+SELECT * FROM cnts;
+
+INSERT INTO cnts SELECT id, name, cnt * 10 FROM cnts WHERE id < 3
+    ON CONFLICT (id) DO UPDATE SET cnt = excluded.cnt;
+
+INSERT INTO cnts (id, name) SELECT id + 100, name || '!' FROM cnts WHERE id < 3
+    ON CONFLICT DO NOTHING;
+
+SELECT * FROM cnts;
+
+INSERT INTO cnts VALUES (8, 'eight', 8), (8, 'acht', 80) ON CONFLICT DO NOTHING;
+
+SELECT * FROM cnts WHERE id = 8;
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (9, 'nine', 9), (9, 'neun', 90)
+    ON CONFLICT (id) DO UPDATE SET cnt = excluded.cnt;
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (1, 'one', 1) ON CONFLICT DO UPDATE SET cnt = 0;
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (1, 'one', 1) ON CONFLICT (cnt) DO NOTHING;
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (1, 'one', 1) ON CONFLICT ON CONSTRAINT con99 DO NOTHING;
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (1, 'one', 1) ON CONFLICT (id) DO UPDATE SET cnt = cnt + 1;
+
+{{Fail .Test}}
+INSERT INTO cnts VALUES (1, 'one', 1) ON CONFLICT (id) DO UPDATE SET cnt = excluded.cnt
+    WHERE excluded.cnt;
+
+CREATE TABLE parent (id int PRIMARY KEY, name text);
+
+CREATE TABLE child (id int PRIMARY KEY, pid int REFERENCES parent);
+
+INSERT INTO parent VALUES (1, 'one'), (2, 'two');
+
+INSERT INTO child VALUES (10, 1), (20, 2);
+
+{{Fail .Test}}
+INSERT INTO child VALUES (10, 3) ON CONFLICT (id) DO UPDATE SET pid = excluded.pid;
+
+{{Fail .Test}}
+INSERT INTO child VALUES (30, 3) ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO child VALUES (10, 2) ON CONFLICT (id) DO UPDATE SET pid = excluded.pid;
+
+{{Fail .Test}}
+INSERT INTO parent VALUES (2, 'dos') ON CONFLICT (id) DO UPDATE SET id = 4;
+
+INSERT INTO parent VALUES (1, 'uno') ON CONFLICT (id) DO UPDATE SET id = 3, name = excluded.name;
+
+INSERT INTO parent VALUES (2, 'dos') ON CONFLICT (id) DO UPDATE SET name = excluded.name;
+
+SELECT * FROM parent;
+
+SELECT * FROM child;
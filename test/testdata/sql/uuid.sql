@@ -0,0 +1,79 @@
+--
+-- Test UUID
+--
+
+DROP TABLE IF EXISTS accounts;
+
+CREATE TABLE accounts (
+    id uuid primary key,
+    name text,
+    parent uuid
+);
+
+INSERT INTO accounts VALUES
+    ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', 'first', NULL),
+    ('A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A12', 'second', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11'),
+    ('{00000000-0000-0000-0000-000000000001}', 'third', 'a0eebc999c0b4ef8bb6d6bb9bd380a11'),
+    ('ffffffffffffffffffffffffffffffff', 'fourth', '00000000-0000-0000-0000-000000000001');
+
+-- {{Sort .Test false}}
+SELECT id, name, parent FROM accounts;
+
+-- {{Sort .Test false}}
+SELECT id, name FROM accounts ORDER BY id DESC;
+
+SELECT name FROM accounts WHERE id = 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12';
+
+SELECT name FROM accounts WHERE id = '{a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11}';
+
+SELECT name FROM accounts WHERE parent = 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11';
+
+SELECT name FROM accounts WHERE id IN ('00000000-0000-0000-0000-000000000001',
+    'ffffffff-ffff-ffff-ffff-ffffffffffff');
+
+SELECT name FROM accounts WHERE id > 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11';
+
+SELECT a.name, p.name FROM accounts a JOIN accounts p ON a.parent = p.id;
+
+SELECT UUID 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11' = 'A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11',
+    '00000000-0000-0000-0000-000000000001'::uuid < 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11'::uuid;
+
+SELECT id::text, CAST(parent AS bytea), 'prefix-' || id FROM accounts WHERE name = 'third';
+
+SELECT count(parent), count(DISTINCT parent) FROM accounts;
+
+DROP TABLE IF EXISTS sessions;
+
+CREATE TABLE sessions (
+    id uuid primary key DEFAULT gen_random_uuid(),
+    label text
+);
+
+INSERT INTO sessions (label) VALUES ('one'), ('two'), ('three');
+
+SELECT count(*), count(DISTINCT id) FROM sessions;
+
+SELECT id::text LIKE '________-____-4___-____-____________' FROM sessions;
+
+SELECT gen_random_uuid() = gen_random_uuid();
+
+-- {{Fail .Test}}
+INSERT INTO accounts VALUES ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', 'duplicate', NULL);
+
+-- {{Fail .Test}}
+INSERT INTO accounts VALUES ('a0eebc99-9c0b-4ef8-bb6d', 'short', NULL);
+
+-- {{Fail .Test}}
+INSERT INTO accounts VALUES ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a1g', 'bad digit', NULL);
+
+-- {{Fail .Test}}
+SELECT 'a0ee-bc99'::uuid;
+
+-- {{Fail .Test}}
+SELECT name FROM accounts WHERE id = 'not a uuid';
+
+-- {{Fail .Test}}
+SELECT UUID 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11' + 1;
+
+-- {{Fail .Test}}
+SELECT 1::uuid;
@@ -0,0 +1,182 @@
+--
+-- Test CREATE VIEW and DROP VIEW
+--
+
+DROP SCHEMA IF EXISTS view_sch CASCADE;
+
+DROP VIEW IF EXISTS view_top, view_names, view_big, view_star CASCADE;
+
+DROP TABLE IF EXISTS view_tbl, view_a, view_b CASCADE;
+
+CREATE TABLE view_tbl (id int PRIMARY KEY, name text, amt int);
+
+INSERT INTO view_tbl VALUES (1, 'one', 10), (2, 'two', 20), (3, 'three', 30);
+
+CREATE VIEW view_names AS SELECT id, name FROM view_tbl;
+
+SELECT * FROM view_names ORDER BY id;
+
+CREATE VIEW view_big (big_id, big_amt) AS SELECT id, amt * 10 FROM view_tbl WHERE amt > 10;
+
+SELECT * FROM view_big ORDER BY big_id;
+
+SELECT v.name, b.big_amt FROM view_names AS v JOIN view_big AS b ON v.id = b.big_id
+    ORDER BY name;
+
+CREATE VIEW view_top AS SELECT name FROM view_names WHERE id < 3;
+
+SELECT * FROM view_top ORDER BY name;
+
+INSERT INTO view_tbl VALUES (4, 'four', 40);
+
+SELECT * FROM view_big WHERE big_id = 4;
+
+SELECT * FROM (WITH view_tbl AS (SELECT 100 AS id, 'cte' AS name)
+    SELECT * FROM view_names ORDER BY id) AS s;
+
+SELECT table_name, table_type FROM metadata.tables
+    WHERE table_name LIKE 'view_%' ORDER BY table_name;
+
+{{Fail .Test}}
+CREATE VIEW view_names AS SELECT id FROM view_tbl;
+
+{{Fail .Test}}
+CREATE VIEW view_tbl AS SELECT 1;
+
+{{Fail .Test}}
+CREATE VIEW view_bad (a, b) AS SELECT id FROM view_tbl;
+
+{{Fail .Test}}
+INSERT INTO view_names VALUES (5, 'five');
+
+{{Fail .Test}}
+CREATE OR REPLACE VIEW view_names AS SELECT name FROM view_tbl;
+
+CREATE OR REPLACE VIEW view_names AS SELECT id, name, amt FROM view_tbl;
+
+SELECT * FROM view_names ORDER BY id;
+
+{{Fail .Test}}
+CREATE OR REPLACE VIEW view_names AS SELECT * FROM view_top;
+
+{{Fail .Test}}
+DROP TABLE view_tbl;
+
+{{Fail .Test}}
+DROP TABLE IF EXISTS view_big;
+
+{{Fail .Test}}
+ALTER TABLE view_tbl RENAME TO view_renamed;
+
+{{Fail .Test}}
+DROP VIEW view_names;
+
+{{Fail .Test}}
+DROP VIEW view_missing;
+
+DROP VIEW IF EXISTS view_missing;
+
+DROP VIEW view_top;
+
+DROP VIEW view_names RESTRICT;
+
+SELECT table_name, table_type FROM metadata.tables
+    WHERE table_name LIKE 'view_%' ORDER BY table_name;
+
+CREATE VIEW view_star AS SELECT * FROM view_tbl WHERE id = 1;
+
+SELECT * FROM view_star;
+
+ALTER TABLE view_tbl ADD COLUMN extra int DEFAULT 0;
+
+SELECT * FROM view_star;
+
+CREATE OR REPLACE VIEW view_star AS SELECT * FROM view_tbl WHERE id = 1;
+
+SELECT * FROM view_star;
+
+{{Fail .Test}}
+ALTER TABLE view_tbl DROP COLUMN extra;
+
+{{Fail .Test}}
+ALTER TABLE view_tbl DROP COLUMN extra RESTRICT;
+
+{{Fail .Test}}
+ALTER TABLE view_tbl RENAME COLUMN name TO title;
+
+SELECT * FROM view_star;
+
+ALTER TABLE view_tbl DROP COLUMN extra CASCADE;
+
+{{Fail .Test}}
+SELECT * FROM view_star;
+
+SELECT * FROM view_tbl ORDER BY id;
+
+CREATE VIEW view_star AS SELECT * FROM view_tbl WHERE id = 1;
+
+{{Fail .Test}}
+ALTER TABLE view_tbl ALTER amt TYPE bigint;
+
+ALTER TABLE view_tbl ALTER amt TYPE bigint CASCADE;
+
+{{Fail .Test}}
+SELECT * FROM view_star;
+
+CREATE VIEW view_star AS SELECT * FROM view_tbl WHERE id = 1;
+
+SELECT * FROM view_star;
+
+ALTER TABLE view_tbl RENAME COLUMN name TO title CASCADE;
+
+{{Fail .Test}}
+SELECT * FROM view_star;
+
+SELECT * FROM view_tbl ORDER BY id;
+
+CREATE SCHEMA view_sch;
+
+CREATE TABLE view_sch.tbl (c int PRIMARY KEY);
+
+INSERT INTO view_sch.tbl VALUES (1), (2);
+
+CREATE VIEW view_sch.vw AS SELECT c FROM view_sch.tbl;
+
+CREATE VIEW view_names AS SELECT c FROM view_sch.vw;
+
+SELECT * FROM view_names ORDER BY c;
+
+{{Fail .Test}}
+DROP SCHEMA view_sch;
+
+DROP SCHEMA view_sch CASCADE;
+
+{{Fail .Test}}
+SELECT * FROM view_names;
+
+DROP TABLE view_tbl CASCADE;
+
+CREATE TABLE view_a (id int PRIMARY KEY, a text);
+
+CREATE TABLE view_b (id int PRIMARY KEY, b text);
+
+INSERT INTO view_a VALUES (1, 'a1'), (2, 'a2');
+
+INSERT INTO view_b VALUES (1, 'b1'), (3, 'b3');
+
+CREATE VIEW view_join AS SELECT view_a.*, view_b.b FROM view_a JOIN view_b USING (id);
+
+CREATE VIEW view_union AS SELECT * FROM view_a UNION ALL SELECT * FROM view_b;
+
+ALTER TABLE view_a ADD COLUMN c int DEFAULT 0;
+
+ALTER TABLE view_b ADD COLUMN d int DEFAULT 0;
+
+SELECT * FROM view_join;
+
+SELECT * FROM view_union ORDER BY id, a;
+
+DROP TABLE view_a, view_b CASCADE;
+
+SELECT table_name, table_type FROM metadata.tables
+    WHERE table_name LIKE 'view_%' ORDER BY table_name;
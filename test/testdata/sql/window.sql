@@ -0,0 +1,130 @@
+--
+-- Test window functions
+--
+
+DROP TABLE IF EXISTS sales;
+
+CREATE TABLE sales (id int primary key, region text, amount int, score double precision);
+
+INSERT INTO sales VALUES
+    (1, 'east', 10, 1.5),
+    (2, 'east', 20, 2.5),
+    (3, 'east', 20, 3.5),
+    (4, 'west', 5, 1.0),
+    (5, 'west', 15, NULL),
+    (6, 'west', 30, 2.0),
+    (7, 'north', NULL, 4.0);
+
+SELECT id, row_number() OVER (ORDER BY id) FROM sales;
+
+SELECT id, region, row_number() OVER (PARTITION BY region ORDER BY id DESC) FROM sales;
+
+SELECT id, amount, rank() OVER (ORDER BY amount), dense_rank() OVER (ORDER BY amount)
+    FROM sales;
+
+SELECT id, region, amount, rank() OVER (PARTITION BY region ORDER BY amount DESC) AS r
+    FROM sales;
+
+SELECT id, amount, sum(amount) OVER (ORDER BY id) AS running FROM sales;
+
+SELECT id, amount, sum(amount) OVER (ORDER BY amount) AS running FROM sales;
+
+SELECT id, region, amount, sum(amount) OVER (PARTITION BY region) AS total,
+    count(*) OVER (PARTITION BY region) AS cnt FROM sales;
+
+SELECT id, amount, avg(amount) OVER () FROM sales;
+
+SELECT id, sum(amount) OVER (ORDER BY id ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) FROM sales;
+
+SELECT id, sum(amount) OVER (ORDER BY id ROWS 2 PRECEDING) FROM sales;
+
+SELECT id, sum(amount) OVER (ORDER BY id ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING)
+    FROM sales;
+
+SELECT id, count(*) OVER (ORDER BY id ROWS BETWEEN 2 FOLLOWING AND 3 FOLLOWING) FROM sales;
+
+SELECT id, amount, count(*) OVER (ORDER BY amount RANGE BETWEEN 5 PRECEDING AND 5 FOLLOWING)
+    FROM sales;
+
+SELECT id, amount, max(id) OVER (ORDER BY amount DESC RANGE BETWEEN CURRENT ROW AND 10 FOLLOWING)
+    FROM sales;
+
+SELECT id, score, sum(id) OVER (ORDER BY score RANGE BETWEEN 1.0 PRECEDING AND CURRENT ROW)
+    FROM sales;
+
+SELECT id, lag(amount) OVER (ORDER BY id), lead(amount) OVER (ORDER BY id) FROM sales;
+
+SELECT id, lag(id, 2, -1) OVER (ORDER BY id), lead(id, 3, 0) OVER (ORDER BY id) FROM sales;
+
+SELECT id, region, first_value(id) OVER (PARTITION BY region ORDER BY amount),
+    last_value(id) OVER (PARTITION BY region ORDER BY amount) FROM sales;
+
+SELECT id, last_value(id) OVER (ORDER BY id ROWS BETWEEN UNBOUNDED PRECEDING
+    AND UNBOUNDED FOLLOWING) FROM sales;
+
+SELECT id, ntile(3) OVER (ORDER BY id) FROM sales;
+
+SELECT id, ntile(10) OVER (ORDER BY id) FROM sales;
+
+SELECT id, row_number() OVER (ORDER BY id) + 100 AS n, amount * 2 FROM sales WHERE id > 3;
+
+-- {{Sort .Test false}}
+SELECT id, region, rank() OVER (PARTITION BY region ORDER BY amount DESC) AS r FROM sales
+    ORDER BY id;
+
+SELECT * FROM (SELECT id, region, rank() OVER (PARTITION BY region ORDER BY amount DESC) AS r
+    FROM sales) AS s WHERE r = 1;
+
+SELECT region, total, rank() OVER (ORDER BY total DESC)
+    FROM (SELECT region, sum(amount) AS total FROM sales GROUP BY region) AS s;
+
+SELECT region, sum(amount), rank() OVER (ORDER BY sum(amount)) FROM sales GROUP BY region;
+
+SELECT region, count(*), max(score), lag(region) OVER (ORDER BY max(score)),
+    sum(count(*)) OVER (), row_number() OVER (ORDER BY region DESC) FROM sales GROUP BY region;
+
+SELECT region, sum(amount) AS total, dense_rank() OVER (ORDER BY sum(amount) DESC) FROM sales
+    GROUP BY region HAVING count(*) > 1;
+
+SELECT count(*), sum(amount), row_number() OVER () FROM sales;
+
+-- {{Sort .Test false}}
+SELECT region, avg(score), rank() OVER (ORDER BY avg(score)) AS r FROM sales GROUP BY region
+    ORDER BY r;
+
+-- {{Fail .Test}}
+SELECT id, row_number() FROM sales;
+
+-- {{Fail .Test}}
+SELECT id, abs(id) OVER () FROM sales;
+
+-- {{Fail .Test}}
+SELECT id FROM sales WHERE row_number() OVER () > 1;
+
+-- {{Fail .Test}}
+SELECT region, rank() OVER (ORDER BY amount) FROM sales GROUP BY region;
+
+-- {{Fail .Test}}
+SELECT region FROM sales GROUP BY region HAVING rank() OVER (ORDER BY region) > 1;
+
+-- {{Fail .Test}}
+SELECT region, sum(rank() OVER (ORDER BY amount)) FROM sales GROUP BY region;
+
+-- {{Fail .Test}}
+SELECT id, count(DISTINCT amount) OVER () FROM sales;
+
+-- {{Fail .Test}}
+SELECT id, sum(amount) OVER (ORDER BY region RANGE BETWEEN 1 PRECEDING AND CURRENT ROW)
+    FROM sales;
+
+-- {{Fail .Test}}
+SELECT id, sum(amount) OVER (ORDER BY id ROWS BETWEEN CURRENT ROW AND 1 PRECEDING) FROM sales;
+
+-- {{Fail .Test}}
+SELECT id, sum(amount) OVER (ORDER BY id ROWS BETWEEN -1 PRECEDING AND CURRENT ROW) FROM sales;
+
+-- {{Fail .Test}}
+SELECT id, ntile(0) OVER (ORDER BY id) FROM sales;
+
+-- {{Fail .Test}}
+SELECT id, sum(row_number() OVER ()) OVER () FROM sales;
@@ -0,0 +1,134 @@
+--
+-- Test WITH and WITH RECURSIVE
+--
+
+DROP TABLE IF EXISTS emps;
+DROP TABLE IF EXISTS parts;
+DROP TABLE IF EXISTS totals;
+
+CREATE TABLE emps (id int primary key, name text, manager int);
+
+INSERT INTO emps VALUES
+    (1, 'ceo', NULL),
+    (2, 'cto', 1),
+    (3, 'cfo', 1),
+    (4, 'engineer1', 2),
+    (5, 'engineer2', 2),
+    (6, 'accountant', 3),
+    (7, 'intern', 4);
+
+CREATE TABLE parts (part text, sub_part text, quantity int, primary key (part, sub_part));
+
+INSERT INTO parts VALUES
+    ('car', 'engine', 1),
+    ('car', 'wheel', 4),
+    ('engine', 'piston', 6),
+    ('engine', 'spark plug', 6),
+    ('wheel', 'bolt', 5),
+    ('wheel', 'tire', 1);
+
+CREATE TABLE totals (name text primary key, cnt int);
+
+SELECT * FROM (WITH e AS (SELECT id, name FROM emps WHERE manager = 2) SELECT * FROM e) AS s;
+
+SELECT * FROM (WITH e AS (SELECT id, name FROM emps WHERE manager = 1)
+    SELECT e1.name, e2.name FROM e AS e1, e AS e2 WHERE e1.id < e2.id) AS s;
+
+SELECT * FROM (WITH e (i, n) AS (SELECT id, name FROM emps WHERE id < 3),
+    f AS (SELECT n FROM e WHERE i = 2) SELECT * FROM f) AS s;
+
+SELECT * FROM (WITH e AS (VALUES (1, 'a'), (2, 'b')) SELECT * FROM e) AS s;
+
+SELECT * FROM (WITH e AS (TABLE emps) SELECT count(*) FROM e) AS s;
+
+SELECT name, (WITH m AS (SELECT id FROM emps WHERE manager IS NULL) SELECT id FROM m) FROM emps
+    WHERE id = 2;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH e AS (SELECT * FROM e) SELECT * FROM e) AS s;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH e (a) AS (SELECT id, name FROM emps) SELECT * FROM e) AS s;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH e AS (SELECT * FROM emps), e AS (SELECT * FROM emps) SELECT * FROM e) AS s;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH e AS (SELECT * FROM missing) SELECT 1) AS s;
+
+-- {{Fail .Test}}
+SELECT * FROM e;
+
+WITH e AS (SELECT name, id FROM emps WHERE manager = 2) INSERT INTO totals VALUES
+    ('engineers', (SELECT count(*) FROM e)),
+    ('first', (SELECT min(id) FROM e));
+
+SELECT * FROM totals;
+
+WITH e AS (SELECT id FROM emps WHERE manager = 1)
+    UPDATE totals SET cnt = (SELECT count(*) FROM e) WHERE name = 'first';
+
+SELECT * FROM totals;
+
+WITH e AS (SELECT max(id) AS id FROM emps) DELETE FROM totals WHERE cnt < (SELECT id FROM e);
+
+SELECT * FROM totals;
+
+-- {{Sort .Test false}}
+SELECT * FROM (WITH RECURSIVE chain (id, name, depth) AS (
+    SELECT id, name, 0 FROM emps WHERE manager IS NULL
+    UNION ALL
+    SELECT emps.id, emps.name, chain.depth + 1 FROM emps, chain WHERE emps.manager = chain.id
+) SELECT * FROM chain) AS s ORDER BY depth, id;
+
+-- {{Sort .Test false}}
+SELECT * FROM (WITH RECURSIVE reports AS (
+    SELECT id, name FROM emps WHERE id = 2
+    UNION
+    SELECT emps.id, emps.name FROM emps JOIN reports ON emps.manager = reports.id
+) SELECT * FROM reports) AS s ORDER BY id;
+
+-- {{Sort .Test false}}
+SELECT * FROM (WITH RECURSIVE bom (part, quantity) AS (
+    SELECT sub_part, quantity FROM parts WHERE part = 'car'
+    UNION ALL
+    SELECT parts.sub_part, parts.quantity * bom.quantity FROM parts, bom
+        WHERE parts.part = bom.part
+) SELECT part, sum(quantity) FROM bom GROUP BY part) AS s ORDER BY part;
+
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1) UNION SELECT i + 1 FROM n WHERE i < 10)
+    SELECT count(*), sum(i) FROM n) AS s;
+
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1) UNION SELECT i FROM n)
+    SELECT * FROM n) AS s;
+
+SELECT * FROM (WITH RECURSIVE n AS (SELECT id FROM emps WHERE id = 1 UNION SELECT id FROM emps
+    WHERE id = 2) SELECT * FROM n) AS s;
+
+-- {{Sort .Test false}}
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1) UNION ALL SELECT i + 0.5 FROM n WHERE i < 3)
+    SELECT i FROM n) AS s ORDER BY i;
+
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1) UNION SELECT i * 1.0 FROM n)
+    SELECT i FROM n) AS s;
+
+-- {{Sort .Test false}}
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1)
+    UNION ALL SELECT i * 2::double precision FROM n WHERE i < 8)
+    SELECT i FROM n) AS s ORDER BY i;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1) UNION SELECT i, i FROM n)
+    SELECT * FROM n) AS s;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1) UNION SELECT i + 1 FROM n LIMIT 10)
+    SELECT * FROM n) AS s;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH RECURSIVE n (i) AS (VALUES (1) UNION SELECT 'abc' FROM n)
+    SELECT * FROM n) AS s;
+
+-- {{Fail .Test}}
+SELECT * FROM (WITH RECURSIVE n (d) AS (VALUES (date '2020-01-01') UNION SELECT 1 FROM n)
+    SELECT * FROM n) AS s;